@@ -0,0 +1,181 @@
+package admission
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/armosec/opa-utils/reporthandling"
+	"github.com/armosec/opa-utils/resources"
+)
+
+const (
+	gatekeeperTarget = "admission.k8s.gatekeeper.sh"
+
+	// gatekeeperLib the library modules of a template must be of the data.lib package
+	gatekeeperLib = "lib.kubescape"
+)
+
+var (
+	packagePattern         = regexp.MustCompile(`(?m)^package\s+\S+`)
+	dataRefPattern         = regexp.MustCompile(`(^|[^\w.])data\.([\w.]+)`)
+	nonAlphanumericPattern = regexp.MustCompile(`[^a-z0-9]`)
+	dependencyModule       = map[string]string{"cautils": resources.RegoCAUtils, "designators": resources.RegoDesignators}
+)
+
+// GatekeeperActions the enforcement actions of the constraints, by the export action
+var GatekeeperActions = map[string]string{"deny": "deny", "warn": "warn", "audit": "dryrun"}
+
+// GatekeeperPolicy translates the control to a ConstraintTemplate and a Constraint of the template.
+// The rules of the control are libraries of the template, evaluated on the admitted object. The controls inputs are part of the libraries
+func GatekeeperPolicy(control *reporthandling.Control, controlsInputs map[string][]string, action string) ([]map[string]interface{}, error) {
+	enforcementAction, ok := GatekeeperActions[action]
+	if !ok {
+		return nil, fmt.Errorf("unsupported action '%s'", action)
+	}
+	name := policyName(control.ControlID)
+	kind := "Kubescape" + strings.ToUpper(strings.TrimPrefix(name, "kubescape")) // the name of a template is the lower case kind
+
+	libs := []string{}
+	dependencies := map[string]bool{}
+	violations := []string{}
+	for i := range control.Rules {
+		rule := &control.Rules[i]
+		if rule.RuleLanguage != reporthandling.RegoLanguage && rule.RuleLanguage != reporthandling.RegoLanguage2 {
+			return nil, fmt.Errorf("%s: rule '%s', language '%v' not supported", control.ControlID, rule.Name, rule.RuleLanguage)
+		}
+		if rule.ResourceEnumerator != "" {
+			return nil, fmt.Errorf("%s: rule '%s' has a resource enumerator, not supported", control.ControlID, rule.Name)
+		}
+		rulePackage := fmt.Sprintf("%s.%s.rule%d", gatekeeperLib, strings.TrimPrefix(name, "kubescape"), i)
+		message, _ := json.Marshal(fmt.Sprintf("%s %s: %%v", control.ControlID, control.Name))
+		module, err := gatekeeperModule(rule.Rule, rulePackage, dependencies)
+		if err != nil {
+			return nil, fmt.Errorf("%s: rule '%s': %w", control.ControlID, rule.Name, err)
+		}
+		libs = append(libs, module)
+
+		inputs := (&resources.RegoDependenciesData{PostureControlInputs: controlsInputs}).GetFilteredPostureControlInputs(rule.ConfigInputs)
+		inputsJSON, err := json.Marshal(inputs)
+		if err != nil {
+			return nil, err
+		}
+		libs = append(libs, fmt.Sprintf("package %s.inputs\n\npostureControlInputs = %s\n", rulePackage, inputsJSON))
+
+		violations = append(violations, fmt.Sprintf(`violation[{"msg": msg}] {
+  msga := data.%s.deny[_] with input as [input.review.object]
+  msg := sprintf(%s, [msga.alertMessage])
+}`, rulePackage, message))
+	}
+	if len(violations) == 0 {
+		return nil, fmt.Errorf("%s: the control has no rules", control.ControlID)
+	}
+
+	// the dependencies may depend on other dependencies, e.g. designators imports cautils
+	for added := map[string]bool{}; len(added) < len(dependencies); {
+		dependencyNames := []string{}
+		for dependency := range dependencies {
+			if !added[dependency] {
+				dependencyNames = append(dependencyNames, dependency)
+			}
+		}
+		sort.Strings(dependencyNames)
+		for _, dependency := range dependencyNames {
+			module, err := gatekeeperModule(dependencyModule[dependency], gatekeeperLib+"."+dependency, dependencies)
+			if err != nil {
+				return nil, fmt.Errorf("%s: dependency '%s': %w", control.ControlID, dependency, err)
+			}
+			libs = append(libs, module)
+			added[dependency] = true
+		}
+	}
+
+	template := map[string]interface{}{
+		"apiVersion": "templates.gatekeeper.sh/v1",
+		"kind":       "ConstraintTemplate",
+		"metadata": map[string]interface{}{
+			"name":        name,
+			"annotations": map[string]interface{}{"description": policyDescription(control)},
+		},
+		"spec": map[string]interface{}{
+			"crd": map[string]interface{}{
+				"spec": map[string]interface{}{"names": map[string]interface{}{"kind": kind}},
+			},
+			"targets": []interface{}{
+				map[string]interface{}{
+					"target": gatekeeperTarget,
+					"rego":   fmt.Sprintf("package %s\n\n%s\n", name, strings.Join(violations, "\n\n")),
+					"libs":   toInterfaces(libs),
+				},
+			},
+		},
+	}
+	constraint := map[string]interface{}{
+		"apiVersion": "constraints.gatekeeper.sh/v1beta1",
+		"kind":       kind,
+		"metadata":   map[string]interface{}{"name": name},
+		"spec": map[string]interface{}{
+			"enforcementAction": enforcementAction,
+			"match":             map[string]interface{}{"kinds": gatekeeperKinds(control)},
+		},
+	}
+	return []map[string]interface{}{template, constraint}, nil
+}
+
+// gatekeeperModule moves the module to the package, and the references of the dependencies (e.g. data.cautils) to the library.
+// Gatekeeper allows data references of the libraries only, the rules querying the cluster (data.kubernetes.api.client) are not supported
+func gatekeeperModule(module, pkg string, dependencies map[string]bool) (string, error) {
+	module = packagePattern.ReplaceAllString(module, "package "+pkg)
+	module = strings.ReplaceAll(module, "data.postureControlInputs", "data."+pkg+".inputs.postureControlInputs")
+
+	var err error
+	module = dataRefPattern.ReplaceAllStringFunc(module, func(ref string) string {
+		match := dataRefPattern.FindStringSubmatch(ref)
+		path := match[2]
+		if strings.HasPrefix(path, "lib.") {
+			return ref
+		}
+		dependency := strings.Split(path, ".")[0]
+		if _, ok := dependencyModule[dependency]; !ok {
+			err = fmt.Errorf("references 'data.%s', not available in Gatekeeper", path)
+			return ref
+		}
+		dependencies[dependency] = true
+		return match[1] + "data." + gatekeeperLib + "." + path
+	})
+	return module, err
+}
+
+// gatekeeperKinds the kinds of the rules match, the kubescape rules match resources by kind or by resource name
+func gatekeeperKinds(control *reporthandling.Control) []interface{} {
+	kinds := []interface{}{}
+	for i := range control.Rules {
+		for _, match := range control.Rules[i].Match {
+			names := []interface{}{}
+			for _, resource := range match.Resources {
+				names = append(names, resourceKind(resource))
+			}
+			kinds = append(kinds, map[string]interface{}{"apiGroups": toInterfaces(match.APIGroups), "kinds": names})
+		}
+	}
+	return kinds
+}
+
+// policyName the name of the exported objects of the control, e.g. kubescapec0016
+func policyName(controlID string) string {
+	return "kubescape" + nonAlphanumericPattern.ReplaceAllString(strings.ToLower(controlID), "")
+}
+
+func policyDescription(control *reporthandling.Control) string {
+	return fmt.Sprintf("%s %s - generated by kubescape. %s", control.ControlID, control.Name, control.Description)
+}
+
+func toInterfaces(s []string) []interface{} {
+	l := make([]interface{}, 0, len(s))
+	for i := range s {
+		l = append(l, s[i])
+	}
+	return l
+}
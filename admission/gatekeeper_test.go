@@ -0,0 +1,92 @@
+package admission
+
+import (
+	"context"
+	"testing"
+
+	"github.com/armosec/kubescape/mocks"
+	"github.com/armosec/opa-utils/reporthandling"
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/stretchr/testify/assert"
+)
+
+// evalGatekeeperTemplate evaluates the violations of the template on the object, as gatekeeper does
+func evalGatekeeperTemplate(t *testing.T, template map[string]interface{}, object map[string]interface{}) []interface{} {
+	target := template["spec"].(map[string]interface{})["targets"].([]interface{})[0].(map[string]interface{})
+	modules := map[string]string{"template": target["rego"].(string)}
+	for i, lib := range target["libs"].([]interface{}) {
+		modules[string(rune('a'+i))] = lib.(string)
+	}
+	compiler, err := ast.CompileModules(modules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resultSet, err := rego.New(
+		rego.Query("data."+template["metadata"].(map[string]interface{})["name"].(string)+".violation"),
+		rego.Compiler(compiler),
+		rego.Input(map[string]interface{}{"review": map[string]interface{}{"object": object}}),
+	).Eval(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resultSet) == 0 {
+		return nil
+	}
+	return resultSet[0].Expressions[0].Value.([]interface{})
+}
+
+func TestGatekeeperPolicy(t *testing.T) {
+	control := mocks.MockFramework_0006_0013().Controls[0]
+	assert.Equal(t, "C-0006", control.ControlID)
+
+	objects, err := GatekeeperPolicy(&control, nil, "warn")
+	assert.NoError(t, err)
+	if !assert.Len(t, objects, 2) {
+		return
+	}
+	template, constraint := objects[0], objects[1]
+	assert.Equal(t, "kubescapec0006", template["metadata"].(map[string]interface{})["name"])
+	assert.Equal(t, "KubescapeC0006", constraint["kind"])
+	assert.Equal(t, "warn", constraint["spec"].(map[string]interface{})["enforcementAction"])
+
+	violations := evalGatekeeperTemplate(t, template, mocks.MockDevelopmentWithHostpath().GetObject())
+	if assert.Len(t, violations, 1) {
+		assert.Contains(t, violations[0].(map[string]interface{})["msg"], "C-0006 Allowed hostPath")
+	}
+	assert.Empty(t, evalGatekeeperTemplate(t, template, mocks.MockDevelopmentPrivileged().GetObject()))
+
+	_, err = GatekeeperPolicy(&control, nil, "block")
+	assert.Error(t, err)
+}
+
+func TestGatekeeperPolicyDependencies(t *testing.T) {
+	control := reporthandling.Control{ControlID: "C-0100", Rules: []reporthandling.PolicyRule{{
+		RuleLanguage: reporthandling.RegoLanguage,
+		ConfigInputs: []string{"settings.postureControlInputs.forbiddenNames"},
+		Match:        []reporthandling.RuleMatchObjects{{APIGroups: []string{""}, APIVersions: []string{"v1"}, Resources: []string{"configmaps"}}},
+		Rule: `package armo_builtins
+import data.cautils as cautils
+
+deny[msga] {
+	obj := input[_]
+	cautils.list_contains(data.postureControlInputs.forbiddenNames, obj.metadata.name)
+	msga := {"alertMessage": sprintf("forbidden name %v", [obj.metadata.name])}
+}`,
+	}}}
+	control.Name = "Forbidden names"
+
+	objects, err := GatekeeperPolicy(&control, map[string][]string{"forbiddenNames": {"bad"}}, "deny")
+	assert.NoError(t, err)
+	assert.Len(t, evalGatekeeperTemplate(t, objects[0], map[string]interface{}{"apiVersion": "v1", "kind": "ConfigMap", "metadata": map[string]interface{}{"name": "bad"}}), 1)
+	assert.Empty(t, evalGatekeeperTemplate(t, objects[0], map[string]interface{}{"apiVersion": "v1", "kind": "ConfigMap", "metadata": map[string]interface{}{"name": "good"}}))
+
+	// the resources are matched by kind
+	kinds := objects[1]["spec"].(map[string]interface{})["match"].(map[string]interface{})["kinds"].([]interface{})
+	assert.Equal(t, []interface{}{"ConfigMap"}, kinds[0].(map[string]interface{})["kinds"])
+
+	// rules querying the cluster are not supported
+	control.Rules[0].Rule = "package armo_builtins\n\ndeny[msga] {\n\tobj := data.kubernetes.api.client.resources[_]\n\tmsga := {}\n}"
+	_, err = GatekeeperPolicy(&control, nil, "deny")
+	assert.Error(t, err)
+}
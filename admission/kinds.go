@@ -0,0 +1,29 @@
+package admission
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+// resourceKinds map[<resource>]<kind> of the built-in kubernetes types, e.g. deployments -> Deployment
+var resourceKinds = func() map[string]string {
+	kinds := map[string]string{}
+	for gvk := range scheme.Scheme.AllKnownTypes() {
+		if strings.HasSuffix(gvk.Kind, "List") || strings.HasSuffix(gvk.Kind, "Options") {
+			continue
+		}
+		plural, _ := meta.UnsafeGuessKindToResource(gvk)
+		kinds[plural.Resource] = gvk.Kind
+	}
+	return kinds
+}()
+
+// resourceKind returns the kind of a resource of a rule match, the matches list either kinds or resource names
+func resourceKind(resource string) string {
+	if kind, ok := resourceKinds[resource]; ok {
+		return kind
+	}
+	return resource
+}
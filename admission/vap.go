@@ -0,0 +1,136 @@
+package admission
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/armosec/opa-utils/reporthandling"
+)
+
+// VAPActions the validation actions of the policy bindings, by the export action
+var VAPActions = map[string][]interface{}{"deny": {"Deny"}, "warn": {"Warn"}, "audit": {"Audit"}}
+
+// celValidation the CEL translation of a control, evaluated on the pod spec of the admitted workload
+type celValidation struct {
+	expression string
+	message    string
+}
+
+// celValidations the controls which have a ValidatingAdmissionPolicy translation, the rego rules of the controls are not translated,
+// the expressions are maintained with the controls
+var celValidations = map[string]celValidation{
+	"C-0013": {
+		expression: "(has(variables.podSpec.securityContext) && has(variables.podSpec.securityContext.runAsNonRoot) && variables.podSpec.securityContext.runAsNonRoot == true) || variables.containers.all(c, has(c.securityContext) && has(c.securityContext.runAsNonRoot) && c.securityContext.runAsNonRoot == true)",
+		message:    "the containers must set runAsNonRoot",
+	},
+	"C-0016": {
+		expression: "variables.containers.all(c, has(c.securityContext) && has(c.securityContext.allowPrivilegeEscalation) && c.securityContext.allowPrivilegeEscalation == false)",
+		message:    "the containers must set allowPrivilegeEscalation to false",
+	},
+	"C-0017": {
+		expression: "variables.containers.all(c, has(c.securityContext) && has(c.securityContext.readOnlyRootFilesystem) && c.securityContext.readOnlyRootFilesystem == true)",
+		message:    "the containers must set readOnlyRootFilesystem to true",
+	},
+	"C-0034": {
+		expression: "has(variables.podSpec.automountServiceAccountToken) && variables.podSpec.automountServiceAccountToken == false",
+		message:    "the pods must set automountServiceAccountToken to false",
+	},
+	"C-0038": {
+		expression: "(!has(variables.podSpec.hostPID) || variables.podSpec.hostPID == false) && (!has(variables.podSpec.hostIPC) || variables.podSpec.hostIPC == false)",
+		message:    "the pods must not share the host PID/IPC namespaces",
+	},
+	"C-0041": {
+		expression: "!has(variables.podSpec.hostNetwork) || variables.podSpec.hostNetwork == false",
+		message:    "the pods must not use the host network",
+	},
+	"C-0044": {
+		expression: "variables.containers.all(c, !has(c.ports) || c.ports.all(p, !has(p.hostPort) || p.hostPort == 0))",
+		message:    "the containers must not use host ports",
+	},
+	"C-0048": {
+		expression: "!has(variables.podSpec.volumes) || variables.podSpec.volumes.all(v, !has(v.hostPath))",
+		message:    "the pods must not mount hostPath volumes",
+	},
+	"C-0057": {
+		expression: "variables.containers.all(c, !has(c.securityContext) || !has(c.securityContext.privileged) || c.securityContext.privileged == false)",
+		message:    "the containers must not be privileged",
+	},
+}
+
+// VAPSupportedControls returns the IDs of the controls which have a ValidatingAdmissionPolicy translation
+func VAPSupportedControls() []string {
+	controlIDs := []string{}
+	for controlID := range celValidations {
+		controlIDs = append(controlIDs, controlID)
+	}
+	sort.Strings(controlIDs)
+	return controlIDs
+}
+
+// VAPPolicy translates the control to a ValidatingAdmissionPolicy of the workloads (pods, the pod templates of the controllers) and its binding
+func VAPPolicy(control *reporthandling.Control, action string) ([]map[string]interface{}, error) {
+	validationActions, ok := VAPActions[action]
+	if !ok {
+		return nil, fmt.Errorf("unsupported action '%s'", action)
+	}
+	validation, ok := celValidations[control.ControlID]
+	if !ok {
+		return nil, fmt.Errorf("%s: the control has no ValidatingAdmissionPolicy translation. Supported controls: %s", control.ControlID, strings.Join(VAPSupportedControls(), ", "))
+	}
+	name := policyName(control.ControlID)
+
+	policy := map[string]interface{}{
+		"apiVersion": "admissionregistration.k8s.io/v1",
+		"kind":       "ValidatingAdmissionPolicy",
+		"metadata": map[string]interface{}{
+			"name":        name,
+			"annotations": map[string]interface{}{"description": policyDescription(control)},
+		},
+		"spec": map[string]interface{}{
+			"failurePolicy": "Fail",
+			"matchConstraints": map[string]interface{}{
+				"resourceRules": []interface{}{
+					workloadRule("", "pods"),
+					workloadRule("apps", "deployments", "replicasets", "daemonsets", "statefulsets"),
+					workloadRule("batch", "jobs", "cronjobs"),
+				},
+			},
+			"variables": []interface{}{
+				map[string]interface{}{
+					"name":       "podSpec",
+					"expression": "object.kind == 'Pod' ? object.spec : (object.kind == 'CronJob' ? object.spec.jobTemplate.spec.template.spec : object.spec.template.spec)",
+				},
+				map[string]interface{}{
+					"name":       "containers",
+					"expression": "variables.podSpec.containers + (has(variables.podSpec.initContainers) ? variables.podSpec.initContainers : [])",
+				},
+			},
+			"validations": []interface{}{
+				map[string]interface{}{
+					"expression": validation.expression,
+					"message":    fmt.Sprintf("%s %s: %s", control.ControlID, control.Name, validation.message),
+				},
+			},
+		},
+	}
+	binding := map[string]interface{}{
+		"apiVersion": "admissionregistration.k8s.io/v1",
+		"kind":       "ValidatingAdmissionPolicyBinding",
+		"metadata":   map[string]interface{}{"name": name},
+		"spec": map[string]interface{}{
+			"policyName":        name,
+			"validationActions": validationActions,
+		},
+	}
+	return []map[string]interface{}{policy, binding}, nil
+}
+
+func workloadRule(apiGroup string, resources ...string) map[string]interface{} {
+	return map[string]interface{}{
+		"apiGroups":   []interface{}{apiGroup},
+		"apiVersions": []interface{}{"v1"},
+		"operations":  []interface{}{"CREATE", "UPDATE"},
+		"resources":   toInterfaces(resources),
+	}
+}
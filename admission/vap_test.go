@@ -0,0 +1,27 @@
+package admission
+
+import (
+	"testing"
+
+	"github.com/armosec/opa-utils/reporthandling"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVAPPolicy(t *testing.T) {
+	control := reporthandling.Control{ControlID: "C-0016"}
+	control.Name = "Allow privilege escalation"
+
+	objects, err := VAPPolicy(&control, "audit")
+	assert.NoError(t, err)
+	if assert.Len(t, objects, 2) {
+		policy, binding := objects[0], objects[1]
+		assert.Equal(t, "ValidatingAdmissionPolicy", policy["kind"])
+		assert.Equal(t, "kubescapec0016", binding["spec"].(map[string]interface{})["policyName"])
+		assert.Equal(t, []interface{}{"Audit"}, binding["spec"].(map[string]interface{})["validationActions"])
+		validation := policy["spec"].(map[string]interface{})["validations"].([]interface{})[0].(map[string]interface{})
+		assert.Contains(t, validation["message"], "C-0016 Allow privilege escalation")
+	}
+
+	_, err = VAPPolicy(&reporthandling.Control{ControlID: "C-0099"}, "deny")
+	assert.Error(t, err)
+}
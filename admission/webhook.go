@@ -0,0 +1,138 @@
+package admission
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/armosec/k8s-interface/k8sinterface"
+	"github.com/armosec/k8s-interface/workloadinterface"
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/cautils/logger/helpers"
+	"github.com/armosec/opa-utils/objectsenvelopes"
+	"github.com/armosec/opa-utils/reporthandling"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// maxRequestSize the API server limits the size of the objects to 3MB, the review holds the object and the old object
+const maxRequestSize = 7 * 1024 * 1024
+
+// IEvaluator evaluates the controls on a single resource, returns the failed controls
+type IEvaluator interface {
+	Evaluate(groupResource string, resource workloadinterface.IMetadata) []reporthandling.Control
+}
+
+// Webhook handles the AdmissionReview requests of a ValidatingWebhookConfiguration.
+// The requests of resources failing controls of the severity threshold and above are denied, or allowed with warnings in audit mode
+type Webhook struct {
+	evaluator         IEvaluator
+	severityThreshold int
+	audit             bool
+}
+
+// NewWebhook the severity threshold is one of cautils.Severities, all of the failed controls are violations when empty
+func NewWebhook(evaluator IEvaluator, severityThreshold string, audit bool) *Webhook {
+	return &Webhook{
+		evaluator:         evaluator,
+		severityThreshold: cautils.SeverityToInt(severityThreshold),
+		audit:             audit,
+	}
+}
+
+func (webhook *Webhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxRequestSize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	review := &admissionv1.AdmissionReview{}
+	if err := json.Unmarshal(body, review); err != nil {
+		http.Error(w, fmt.Sprintf("failed to decode AdmissionReview: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+	if review.Request == nil {
+		http.Error(w, "AdmissionReview without a request", http.StatusBadRequest)
+		return
+	}
+
+	review.Response = webhook.review(review.Request)
+	review.Response.UID = review.Request.UID
+	review.Request = nil
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(review); err != nil {
+		logger.L().Error("failed to write AdmissionReview response", helpers.Error(err))
+	}
+}
+
+func (webhook *Webhook) review(request *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	// e.g. a DELETE request, the resource is not admitted
+	if len(request.Object.Raw) == 0 {
+		return &admissionv1.AdmissionResponse{Allowed: true}
+	}
+	obj := map[string]interface{}{}
+	if err := json.Unmarshal(request.Object.Raw, &obj); err != nil {
+		return &admissionv1.AdmissionResponse{Allowed: true, Warnings: []string{fmt.Sprintf("kubescape: failed to decode the object: %s", err.Error())}}
+	}
+	resource := objectsenvelopes.NewObject(obj)
+	if resource == nil {
+		return &admissionv1.AdmissionResponse{Allowed: true}
+	}
+	// the namespace of created objects is set by the request
+	if resource.GetNamespace() == "" && request.Namespace != "" {
+		resource.SetNamespace(request.Namespace)
+	}
+
+	violations := webhook.violations(webhook.evaluator.Evaluate(k8sinterface.JoinResourceTriplets(request.Resource.Group, request.Resource.Version, request.Resource.Resource), resource))
+	if len(violations) == 0 {
+		return &admissionv1.AdmissionResponse{Allowed: true}
+	}
+
+	resourceName := fmt.Sprintf("%s/%s", resource.GetKind(), resource.GetName())
+	warnings := make([]string, 0, len(violations))
+	for i := range violations {
+		warnings = append(warnings, fmt.Sprintf("kubescape: %s %s (%s)", violations[i].ControlID, violations[i].Name, cautils.ControlSeverityToString(violations[i].BaseScore)))
+	}
+	logger.L().Info("admission request failed controls", helpers.String("resource", request.Namespace+"/"+resourceName), helpers.String("controls", strings.Join(controlIDs(violations), ",")), helpers.String("audit", fmt.Sprintf("%v", webhook.audit)))
+
+	if webhook.audit {
+		return &admissionv1.AdmissionResponse{Allowed: true, Warnings: warnings}
+	}
+	return &admissionv1.AdmissionResponse{
+		Allowed:  false,
+		Warnings: warnings,
+		Result: &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Code:    http.StatusForbidden,
+			Reason:  metav1.StatusReasonForbidden,
+			Message: fmt.Sprintf("%s failed kubescape controls: %s", resourceName, strings.Join(controlIDs(violations), ", ")),
+		},
+	}
+}
+
+// violations returns the failed controls of the severity threshold and above
+func (webhook *Webhook) violations(failed []reporthandling.Control) []reporthandling.Control {
+	violations := []reporthandling.Control{}
+	for i := range failed {
+		if cautils.SeverityToInt(cautils.ControlSeverityToString(failed[i].BaseScore)) >= webhook.severityThreshold {
+			violations = append(violations, failed[i])
+		}
+	}
+	return violations
+}
+
+func controlIDs(controls []reporthandling.Control) []string {
+	ids := make([]string, 0, len(controls))
+	for i := range controls {
+		ids = append(ids, controls[i].ControlID)
+	}
+	return ids
+}
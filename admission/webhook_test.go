@@ -0,0 +1,108 @@
+package admission
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/armosec/k8s-interface/workloadinterface"
+	"github.com/armosec/opa-utils/reporthandling"
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+type evaluatorMock struct {
+	failed        []reporthandling.Control
+	groupResource string
+	resource      workloadinterface.IMetadata
+}
+
+func (evaluator *evaluatorMock) Evaluate(groupResource string, resource workloadinterface.IMetadata) []reporthandling.Control {
+	evaluator.groupResource = groupResource
+	evaluator.resource = resource
+	return evaluator.failed
+}
+
+func newControl(controlID string, baseScore float32) reporthandling.Control {
+	control := reporthandling.Control{ControlID: controlID, BaseScore: baseScore}
+	control.Name = controlID + " name"
+	return control
+}
+
+func admissionReview(t *testing.T, webhook *Webhook, object string) *admissionv1.AdmissionReview {
+	var raw []byte
+	if object != "" {
+		raw = []byte(object)
+	}
+	review := admissionv1.AdmissionReview{
+		TypeMeta: metav1.TypeMeta{APIVersion: "admission.k8s.io/v1", Kind: "AdmissionReview"},
+		Request: &admissionv1.AdmissionRequest{
+			UID:       types.UID("1234"),
+			Resource:  metav1.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"},
+			Namespace: "default",
+			Operation: admissionv1.Create,
+			Object:    runtime.RawExtension{Raw: raw},
+		},
+	}
+	body, err := json.Marshal(review)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	webhook.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/validate", bytes.NewReader(body)))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	response := &admissionv1.AdmissionReview{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), response); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "AdmissionReview", response.Kind)
+	assert.Equal(t, types.UID("1234"), response.Response.UID)
+	return response
+}
+
+const deployment = `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"web"},"spec":{}}`
+
+func TestWebhook(t *testing.T) {
+	evaluator := &evaluatorMock{failed: []reporthandling.Control{newControl("C-0016", 7), newControl("C-0017", 3)}}
+
+	// denied by the high severity control
+	response := admissionReview(t, NewWebhook(evaluator, "medium", false), deployment)
+	assert.False(t, response.Response.Allowed)
+	assert.Contains(t, response.Response.Result.Message, "C-0016")
+	assert.NotContains(t, response.Response.Result.Message, "C-0017")
+	assert.Len(t, response.Response.Warnings, 1)
+	assert.Equal(t, "apps/v1/deployments", evaluator.groupResource)
+	assert.Equal(t, "default", evaluator.resource.GetNamespace())
+
+	// audit mode - allowed with warnings
+	response = admissionReview(t, NewWebhook(evaluator, "", true), deployment)
+	assert.True(t, response.Response.Allowed)
+	assert.Len(t, response.Response.Warnings, 2)
+
+	// below the threshold
+	response = admissionReview(t, NewWebhook(evaluator, "critical", false), deployment)
+	assert.True(t, response.Response.Allowed)
+	assert.Empty(t, response.Response.Warnings)
+
+	// no object, e.g. a delete request
+	response = admissionReview(t, NewWebhook(evaluator, "", false), "")
+	assert.True(t, response.Response.Allowed)
+}
+
+func TestWebhookBadRequest(t *testing.T) {
+	webhook := NewWebhook(&evaluatorMock{}, "", false)
+
+	recorder := httptest.NewRecorder()
+	webhook.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/validate", bytes.NewReader([]byte("{}"))))
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	recorder = httptest.NewRecorder()
+	webhook.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/validate", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+}
@@ -0,0 +1,392 @@
+package attacktracks
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/armosec/k8s-interface/workloadinterface"
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/rbacanalysis"
+	"github.com/armosec/opa-utils/reporthandling"
+	"github.com/armosec/opa-utils/reporthandling/results/v1/reportsummary"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// The stages of an attack on a workload, in the order of the attack
+const (
+	StageExposure            = "exposure"             // the workload is reachable from outside of the cluster
+	StageExecution           = "execution"            // code runs in the containers, e.g. by the vulnerabilities of the images
+	StagePrivilegeEscalation = "privilege-escalation" // the code gains privileges in the container
+	StageNodeAccess          = "node-access"          // the code reaches the node, by the host namespaces and the host paths
+	StageClusterAccess       = "cluster-access"       // the code uses the credentials of the workload, e.g. the permissions of its service account
+)
+
+// Stages the stages of the attacks, in order
+var Stages = []string{StageExposure, StageExecution, StagePrivilegeEscalation, StageNodeAccess, StageClusterAccess}
+
+// StageAttribute the attribute of a control setting the stage of its failures, for the controls missing from the built-in stages
+const StageAttribute = "attackTrackStage"
+
+// controlsStages the stages of the failures of the built-in controls
+var controlsStages = map[string]string{
+	"C-0021": StageExposure,            // exposed sensitive interfaces
+	"C-0030": StageExposure,            // ingress and egress blocked
+	"C-0044": StageExposure,            // container hostPort
+	"C-0083": StageExecution,           // vulnerabilities exposed to external traffic
+	"C-0084": StageExecution,           // remote code execution vulnerabilities
+	"C-0085": StageExecution,           // excessive amount of vulnerabilities
+	"C-0013": StagePrivilegeEscalation, // non-root containers
+	"C-0016": StagePrivilegeEscalation, // allow privilege escalation
+	"C-0046": StagePrivilegeEscalation, // insecure capabilities
+	"C-0055": StagePrivilegeEscalation, // linux hardening
+	"C-0057": StagePrivilegeEscalation, // privileged container
+	"C-0038": StageNodeAccess,          // host PID/IPC privileges
+	"C-0041": StageNodeAccess,          // host network access
+	"C-0045": StageNodeAccess,          // writable hostPath mount
+	"C-0048": StageNodeAccess,          // hostPath mount
+	"C-0034": StageClusterAccess,       // automatic mapping of the service account token
+}
+
+// workloadKinds the kinds running pods
+var workloadKinds = map[string]bool{"Pod": true, "Deployment": true, "ReplicaSet": true, "StatefulSet": true, "DaemonSet": true, "Job": true, "CronJob": true}
+
+// referringKinds the kinds of the objects kubescape computes per workload, their data refers to the workload (kind, name, namespace)
+var referringKinds = map[string]bool{"NetworkPolicyCoverage": true, "SecretExposure": true}
+
+// imageKinds the kinds of the objects kubescape computes per image, named after the image
+var imageKinds = map[string]bool{"ImageVulnerabilities": true, "ImageSignature": true, "ImageMetadata": true}
+
+// AttackTrack the steps of the attacks on a workload - its failed controls, the failed controls of its related objects, its exposing
+// services and the risky permissions of its service account, by stage
+type AttackTrack struct {
+	WorkloadID string   `json:"workloadID"`
+	Kind       string   `json:"kind"`
+	Name       string   `json:"name"`
+	Namespace  string   `json:"namespace,omitempty"`
+	Stages     []string `json:"stages"` // the stages of the steps, in the order of the attack
+	Steps      []Step   `json:"steps"`  // by the order of the stages
+	Score      int      `json:"score"`  // the exploitability of the track, see the score function
+}
+
+// Step a failed control or a risky property of the workload or of a related object
+type Step struct {
+	Stage       string `json:"stage"`
+	ControlID   string `json:"controlID,omitempty"`
+	Description string `json:"description"` // the name of the control, or the risky property
+	Severity    string `json:"severity"`
+	ResourceID  string `json:"resourceID"` // the failed object, the workload or a related object
+}
+
+// ListAttackTracks returns the attack tracks of the scanned workloads chaining two stages or more, the most exploitable first
+func ListAttackTracks(opaSessionObj *cautils.OPASessionObj) []AttackTrack {
+	workloads := map[string]workloadinterface.IMetadata{}
+	for resourceID, resource := range opaSessionObj.AllResources {
+		if workloadKinds[resource.GetKind()] && !isControlled(resource) {
+			workloads[resourceID] = resource
+		}
+	}
+	if len(workloads) == 0 {
+		return []AttackTrack{}
+	}
+
+	related := relatedWorkloads(opaSessionObj.AllResources, workloads)
+	steps := map[string][]Step{}
+	for _, step := range controlsSteps(opaSessionObj) {
+		for _, workloadID := range related[step.ResourceID] {
+			steps[workloadID] = append(steps[workloadID], step)
+		}
+	}
+	for workloadID, workloadSteps := range exposureSteps(opaSessionObj.AllResources, workloads) {
+		steps[workloadID] = append(steps[workloadID], workloadSteps...)
+	}
+	for workloadID, workloadSteps := range serviceAccountSteps(opaSessionObj.AllResources, workloads) {
+		steps[workloadID] = append(steps[workloadID], workloadSteps...)
+	}
+
+	tracks := []AttackTrack{}
+	for workloadID, workloadSteps := range steps {
+		workload := workloads[workloadID]
+		track := AttackTrack{WorkloadID: workloadID, Kind: workload.GetKind(), Name: workload.GetName(), Namespace: workload.GetNamespace(), Stages: []string{}, Steps: []Step{}}
+		sortSteps(workloadSteps)
+		stages := map[string]bool{}
+		for _, step := range workloadSteps {
+			stages[step.Stage] = true
+		}
+		for _, stage := range Stages {
+			if stages[stage] {
+				track.Stages = append(track.Stages, stage)
+			}
+		}
+		if len(track.Stages) < 2 {
+			continue
+		}
+		track.Steps = workloadSteps
+		track.Score = score(workloadSteps)
+		tracks = append(tracks, track)
+	}
+	sort.Slice(tracks, func(i, j int) bool {
+		if tracks[i].Score != tracks[j].Score {
+			return tracks[i].Score > tracks[j].Score
+		}
+		return tracks[i].WorkloadID < tracks[j].WorkloadID
+	})
+	return tracks
+}
+
+// score the exploitability of the steps, the sum of the highest severity (1 - Low, 4 - Critical) of each stage, doubled when the workload is
+// exposed - the exposure is the entry point of the other stages
+func score(steps []Step) int {
+	highest := map[string]int{}
+	for _, step := range steps {
+		if severity := cautils.SeverityToInt(step.Severity); severity > highest[step.Stage] {
+			highest[step.Stage] = severity
+		}
+	}
+	total := 0
+	for _, severity := range highest {
+		total += severity
+	}
+	if _, ok := highest[StageExposure]; ok {
+		total *= 2
+	}
+	return total
+}
+
+// ControlsStages returns the stages of the failures of the controls, map[<control ID>]<stage> - the built-in stages, and the stages
+// of the StageAttribute of the controls of the frameworks
+func ControlsStages(frameworks []reporthandling.Framework) map[string]string {
+	stages := map[string]string{}
+	for controlID, stage := range controlsStages {
+		stages[controlID] = stage
+	}
+	for i := range frameworks {
+		for j := range frameworks[i].Controls {
+			control := &frameworks[i].Controls[j]
+			if stage, ok := control.Attributes[StageAttribute].(string); ok && cautils.StringInSlice(Stages, stage) != cautils.ValueNotFound {
+				stages[control.ControlID] = stage
+			}
+		}
+	}
+	return stages
+}
+
+// controlsSteps returns the failures of the controls of a stage, a step per failed resource
+func controlsSteps(opaSessionObj *cautils.OPASessionObj) []Step {
+	stages := ControlsStages(opaSessionObj.Frameworks)
+	steps := []Step{}
+	controls := &opaSessionObj.Report.SummaryDetails.Controls
+	for _, controlID := range controls.ListControlsIDs().All() {
+		stage, ok := stages[controlID]
+		if !ok {
+			continue
+		}
+		control := controls.GetControl(reportsummary.EControlCriteriaID, controlID)
+		if control == nil {
+			continue
+		}
+		for _, resourceID := range control.ListResourcesIDs().Failed() {
+			steps = append(steps, Step{Stage: stage, ControlID: controlID, Description: control.GetName(), Severity: cautils.ControlSeverityToString(control.GetScoreFactor()), ResourceID: resourceID})
+		}
+	}
+	return steps
+}
+
+// relatedWorkloads returns the workloads of the failures of the resources, map[<resource ID>][]<workload ID> - the workload itself, the
+// objects computed for the workload and for its images
+func relatedWorkloads(allResources map[string]workloadinterface.IMetadata, workloads map[string]workloadinterface.IMetadata) map[string][]string {
+	related := map[string][]string{}
+	byReference := map[string]string{}
+	for workloadID, workload := range workloads {
+		related[workloadID] = []string{workloadID}
+		byReference[reference(workload.GetKind(), workload.GetNamespace(), workload.GetName())] = workloadID
+	}
+	imagesWorkloads := cautils.ImagesWorkloads(workloads)
+	for resourceID, resource := range allResources {
+		switch {
+		case referringKinds[resource.GetKind()]:
+			data := toMap(resource.GetObject()["data"])
+			kind, _ := data["kind"].(string)
+			namespace, _ := data["namespace"].(string)
+			name, _ := data["name"].(string)
+			if workloadID, ok := byReference[reference(kind, namespace, name)]; ok {
+				related[resourceID] = []string{workloadID}
+			}
+		case imageKinds[resource.GetKind()]:
+			related[resourceID] = imagesWorkloads[resource.GetName()]
+		}
+	}
+	return related
+}
+
+// exposureSteps returns the workloads exposed by the LoadBalancer and NodePort services, and by the ingresses of their services
+func exposureSteps(allResources map[string]workloadinterface.IMetadata, workloads map[string]workloadinterface.IMetadata) map[string][]Step {
+	services := map[string]workloadinterface.IMetadata{} // by <namespace>/<name>
+	ingresses := map[string][]string{}                   // the ingresses of the services, by <namespace>/<name> of the service
+	for resourceID, resource := range allResources {
+		switch resource.GetKind() {
+		case "Service":
+			services[resource.GetNamespace()+"/"+resource.GetName()] = resource
+		case "Ingress":
+			for _, service := range ingressServices(resource.GetObject()) {
+				key := resource.GetNamespace() + "/" + service
+				ingresses[key] = append(ingresses[key], resourceID)
+			}
+		}
+	}
+
+	steps := map[string][]Step{}
+	for key, service := range services {
+		selector, _ := toMap(service.GetObject()["spec"])["selector"].(map[string]interface{})
+		if len(selector) == 0 {
+			continue
+		}
+		serviceType, _ := toMap(service.GetObject()["spec"])["type"].(string)
+		for workloadID, workload := range workloads {
+			if workload.GetNamespace() != service.GetNamespace() || !selects(selector, workloadinterface.NewWorkloadObj(workload.GetObject()).GetPodLabels()) {
+				continue
+			}
+			switch serviceType {
+			case "LoadBalancer":
+				steps[workloadID] = append(steps[workloadID], Step{Stage: StageExposure, Description: fmt.Sprintf("exposed by the LoadBalancer service %s", service.GetName()), Severity: cautils.SeverityHigh, ResourceID: service.GetID()})
+			case "NodePort":
+				steps[workloadID] = append(steps[workloadID], Step{Stage: StageExposure, Description: fmt.Sprintf("exposed by the NodePort service %s", service.GetName()), Severity: cautils.SeverityMedium, ResourceID: service.GetID()})
+			}
+			for _, ingressID := range ingresses[key] {
+				steps[workloadID] = append(steps[workloadID], Step{Stage: StageExposure, Description: fmt.Sprintf("exposed by the ingress %s of the service %s", allResources[ingressID].GetName(), service.GetName()), Severity: cautils.SeverityHigh, ResourceID: ingressID})
+			}
+		}
+	}
+	return steps
+}
+
+// serviceAccountSteps returns the risky permissions of the service accounts of the workloads, by the scanned RBAC objects. The permissions
+// of the groups of the service accounts are the permissions of the service accounts
+func serviceAccountSteps(allResources map[string]workloadinterface.IMetadata, workloads map[string]workloadinterface.IMetadata) map[string][]Step {
+	resources := make([]workloadinterface.IMetadata, 0, len(allResources))
+	for _, resource := range allResources {
+		resources = append(resources, resource)
+	}
+	objects := rbacanalysis.NewObjects(resources)
+	if len(objects.RoleBindings) == 0 && len(objects.ClusterRoleBindings) == 0 {
+		return nil
+	}
+	risks := map[rbacanalysis.Subject][]rbacanalysis.Risk{}
+	for _, risk := range rbacanalysis.NewGraph(objects).Risks(true) {
+		risks[risk.Subject] = append(risks[risk.Subject], risk)
+	}
+
+	steps := map[string][]Step{}
+	for workloadID, workload := range workloads {
+		serviceAccount := workloadinterface.NewWorkloadObj(workload.GetObject()).GetServiceAccountName()
+		if serviceAccount == "" {
+			serviceAccount = "default"
+		}
+		namespace := workload.GetNamespace()
+		subjects := []rbacanalysis.Subject{
+			{Kind: "ServiceAccount", Name: serviceAccount, Namespace: namespace},
+			{Kind: "Group", Name: "system:serviceaccounts"},
+			{Kind: "Group", Name: "system:serviceaccounts:" + namespace},
+		}
+		for _, subject := range subjects {
+			for _, risk := range risks[subject] {
+				if risk.Namespace != "" && risk.Namespace != namespace {
+					continue // the permissions of the other namespaces
+				}
+				steps[workloadID] = append(steps[workloadID], Step{
+					Stage:       StageClusterAccess,
+					Description: fmt.Sprintf("the service account %s %s, granted to %s by the %s", serviceAccount, risk.Description, subject.String(), risk.Binding.String()),
+					Severity:    risk.Severity,
+					ResourceID:  bindingID(allResources, risk.Binding),
+				})
+			}
+		}
+	}
+	return steps
+}
+
+// sortSteps sorts the steps by stage, by severity and by description
+func sortSteps(steps []Step) {
+	sort.SliceStable(steps, func(i, j int) bool {
+		si, sj := cautils.StringInSlice(Stages, steps[i].Stage), cautils.StringInSlice(Stages, steps[j].Stage)
+		if si != sj {
+			return si < sj
+		}
+		if vi, vj := cautils.SeverityToInt(steps[i].Severity), cautils.SeverityToInt(steps[j].Severity); vi != vj {
+			return vi > vj
+		}
+		return steps[i].Description < steps[j].Description
+	})
+}
+
+func bindingID(allResources map[string]workloadinterface.IMetadata, binding rbacanalysis.ObjectRef) string {
+	for resourceID, resource := range allResources {
+		if resource.GetKind() == binding.Kind && resource.GetName() == binding.Name && resource.GetNamespace() == binding.Namespace {
+			return resourceID
+		}
+	}
+	return binding.String()
+}
+
+func ingressServices(ingress map[string]interface{}) []string {
+	services := []string{}
+	spec := toMap(ingress["spec"])
+	if name, ok := workloadinterface.InspectMap(spec, "defaultBackend", "service", "name"); ok {
+		if s, ok := name.(string); ok {
+			services = append(services, s)
+		}
+	}
+	rules, _ := spec["rules"].([]interface{})
+	for i := range rules {
+		paths, _ := workloadinterface.InspectMap(toMap(rules[i]), "http", "paths")
+		list, _ := paths.([]interface{})
+		for j := range list {
+			if name, ok := workloadinterface.InspectMap(toMap(list[j]), "backend", "service", "name"); ok {
+				if s, ok := name.(string); ok {
+					services = append(services, s)
+				}
+			}
+		}
+	}
+	return services
+}
+
+func selects(selector map[string]interface{}, podLabels map[string]string) bool {
+	set := labels.Set{}
+	for key, value := range selector {
+		if s, ok := value.(string); ok {
+			set[key] = s
+		}
+	}
+	return labels.SelectorFromSet(set).Matches(labels.Set(podLabels))
+}
+
+func reference(kind, namespace, name string) string {
+	return kind + "/" + namespace + "/" + name
+}
+
+// toMap returns the map of the value, converting the structs set by kubescape
+func toMap(value interface{}) map[string]interface{} {
+	if m, ok := value.(map[string]interface{}); ok {
+		return m
+	}
+	m := map[string]interface{}{}
+	if b, err := json.Marshal(value); err == nil {
+		json.Unmarshal(b, &m)
+	}
+	return m
+}
+
+// isControlled returns true for the objects created by a controller, their steps are the steps of the controller
+func isControlled(resource workloadinterface.IMetadata) bool {
+	owners, err := workloadinterface.NewWorkloadObj(resource.GetObject()).GetOwnerReferences()
+	if err != nil {
+		return false
+	}
+	for _, owner := range owners {
+		if owner.Controller != nil && *owner.Controller {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,131 @@
+package attacktracks
+
+import (
+	"testing"
+
+	"github.com/armosec/armoapi-go/armotypes"
+	"github.com/armosec/k8s-interface/workloadinterface"
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/opa-utils/reporthandling"
+	"github.com/armosec/opa-utils/reporthandling/apis"
+	"github.com/armosec/opa-utils/reporthandling/results/v1/reportsummary"
+	reporthandlingv2 "github.com/armosec/opa-utils/reporthandling/v2"
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/yaml"
+)
+
+const trackObjects = `
+- apiVersion: apps/v1
+  kind: Deployment
+  metadata: {name: web, namespace: prod}
+  spec:
+    template:
+      metadata: {labels: {app: web}}
+      spec:
+        serviceAccountName: web
+        containers: [{name: web, image: nginx}]
+- apiVersion: apps/v1
+  kind: Deployment
+  metadata: {name: db, namespace: prod}
+  spec:
+    template:
+      metadata: {labels: {app: db}}
+      spec:
+        containers: [{name: db, image: postgres}]
+- apiVersion: v1
+  kind: Service
+  metadata: {name: web, namespace: prod}
+  spec: {type: LoadBalancer, selector: {app: web}}
+- apiVersion: networkpolicy.kubescape.cloud/v1beta0
+  kind: NetworkPolicyCoverage
+  metadata: {name: deployment-web, namespace: prod}
+  data: {kind: Deployment, name: web, namespace: prod}
+- apiVersion: rbac.authorization.k8s.io/v1
+  kind: ClusterRole
+  metadata: {name: cluster-admin}
+  rules: [{apiGroups: ["*"], resources: ["*"], verbs: ["*"]}]
+- apiVersion: rbac.authorization.k8s.io/v1
+  kind: ClusterRoleBinding
+  metadata: {name: web-admin}
+  roleRef: {apiGroup: rbac.authorization.k8s.io, kind: ClusterRole, name: cluster-admin}
+  subjects: [{kind: ServiceAccount, name: web, namespace: prod}]
+`
+
+func newSession(t *testing.T) *cautils.OPASessionObj {
+	objects := []map[string]interface{}{}
+	if err := yaml.Unmarshal([]byte(trackObjects), &objects); err != nil {
+		t.Fatal(err)
+	}
+	allResources := map[string]workloadinterface.IMetadata{}
+	ids := map[string]string{}
+	for i := range objects {
+		resource := workloadinterface.NewWorkloadObj(objects[i])
+		allResources[resource.GetID()] = resource
+		ids[resource.GetKind()+"/"+resource.GetName()] = resource.GetID()
+	}
+
+	controls := reportsummary.ControlSummaries{}
+	fail := func(controlID, name string, scoreFactor float32, resourceIDs ...string) {
+		control := reportsummary.ControlSummary{ControlID: controlID, Name: name, ScoreFactor: scoreFactor}
+		control.ResourceIDs.Append(apis.StatusFailed, resourceIDs...)
+		controls[controlID] = control
+	}
+	fail("C-0057", "Privileged container", 8, ids["Deployment/web"], ids["Deployment/db"])
+	fail("C-0038", "Host PID/IPC privileges", 7, ids["Deployment/web"])
+	fail("C-0002", "Exec into container", 5, ids["Deployment/web"]) // not a stage of the tracks
+	fail("NET-0001", "Workloads must be covered by network policies", 6, ids["NetworkPolicyCoverage/deployment-web"])
+
+	custom := reporthandling.Control{ControlID: "NET-0001"}
+	custom.Attributes = map[string]interface{}{StageAttribute: StageExposure}
+	return &cautils.OPASessionObj{
+		AllResources: allResources,
+		Frameworks:   []reporthandling.Framework{{Controls: []reporthandling.Control{custom}}},
+		Report:       &reporthandlingv2.PostureReport{SummaryDetails: reportsummary.SummaryDetails{Controls: controls}},
+	}
+}
+
+func TestListAttackTracks(t *testing.T) {
+	tracks := ListAttackTracks(newSession(t))
+	if !assert.Len(t, tracks, 1, "the db deployment fails a single stage") {
+		return
+	}
+	track := tracks[0]
+	assert.Equal(t, "web", track.Name)
+	assert.Equal(t, []string{StageExposure, StagePrivilegeEscalation, StageNodeAccess, StageClusterAccess}, track.Stages)
+
+	steps := map[string][]string{}
+	for _, step := range track.Steps {
+		steps[step.Stage] = append(steps[step.Stage], step.ControlID+" "+step.Severity)
+	}
+	assert.Equal(t, []string{" High", "NET-0001 Medium"}, steps[StageExposure], "the LoadBalancer service and the custom control")
+	assert.Equal(t, []string{"C-0057 High"}, steps[StagePrivilegeEscalation])
+	assert.Equal(t, []string{"C-0038 High"}, steps[StageNodeAccess])
+	assert.Equal(t, []string{" Critical"}, steps[StageClusterAccess], "the service account is bound to cluster-admin")
+	assert.Equal(t, 2*(3+3+3+4), track.Score, "doubled by the exposure")
+}
+
+func TestListAttackTracksWithoutWorkloads(t *testing.T) {
+	assert.Empty(t, ListAttackTracks(&cautils.OPASessionObj{AllResources: map[string]workloadinterface.IMetadata{}, Report: &reporthandlingv2.PostureReport{}}))
+}
+
+func TestScore(t *testing.T) {
+	steps := []Step{
+		{Stage: StagePrivilegeEscalation, Severity: cautils.SeverityMedium},
+		{Stage: StagePrivilegeEscalation, Severity: cautils.SeverityHigh},
+		{Stage: StageClusterAccess, Severity: cautils.SeverityLow},
+	}
+	assert.Equal(t, 4, score(steps), "the highest severity of each stage")
+	assert.Equal(t, 10, score(append(steps, Step{Stage: StageExposure, Severity: cautils.SeverityLow})))
+}
+
+func TestControlsStages(t *testing.T) {
+	frameworks := []reporthandling.Framework{{Controls: []reporthandling.Control{
+		{ControlID: "C-1001", PortalBase: armotypes.PortalBase{Attributes: map[string]interface{}{StageAttribute: StageExposure}}},
+		{ControlID: "C-1002", PortalBase: armotypes.PortalBase{Attributes: map[string]interface{}{StageAttribute: "unknown"}}},
+	}}}
+	stages := ControlsStages(frameworks)
+	assert.Equal(t, StageExposure, stages["C-1001"])
+	assert.NotContains(t, stages, "C-1002")
+	assert.Equal(t, StagePrivilegeEscalation, stages["C-0013"])
+	assert.Len(t, stages, len(controlsStages)+1)
+}
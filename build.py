@@ -9,6 +9,7 @@ BE_SERVER_CONST   = BASE_GETTER_CONST + ".ArmoBEURL"
 ER_SERVER_CONST   = BASE_GETTER_CONST + ".ArmoERURL"
 WEBSITE_CONST     = BASE_GETTER_CONST + ".ArmoFEURL"
 AUTH_SERVER_CONST = BASE_GETTER_CONST + ".armoAUTHURL"
+RELEASE_PUBLIC_KEY_CONST = BASE_GETTER_CONST + ".ReleasePublicKey"
 
 def checkStatus(status, msg):
     if status != 0:
@@ -48,6 +49,7 @@ def main():
     ArmoERServer = os.getenv("ArmoERServer")
     ArmoWebsite = os.getenv("ArmoWebsite")
     ArmoAuthServer = os.getenv("ArmoAuthServer")
+    ReleasePublicKey = os.getenv("ReleasePublicKey") # base64, a single line
 
     # Create build directory
     buildDir = getBuildDir()
@@ -70,6 +72,8 @@ def main():
         ldflags += " -X {}={}".format(WEBSITE_CONST, ArmoWebsite)
     if ArmoAuthServer:
         ldflags += " -X {}={}".format(AUTH_SERVER_CONST, ArmoAuthServer)
+    if ReleasePublicKey:
+        ldflags += " -X {}={}".format(RELEASE_PUBLIC_KEY_CONST, ReleasePublicKey)
 
     build_command = ["go", "build", "-o", ks_file, "-ldflags" ,ldflags]
 
@@ -1,25 +1,94 @@
 package cautils
 
 import (
+	"time"
+
 	"github.com/armosec/armoapi-go/armotypes"
 	"github.com/armosec/k8s-interface/workloadinterface"
 	"github.com/armosec/opa-utils/reporthandling"
+	"github.com/armosec/opa-utils/reporthandling/apis"
 	"github.com/armosec/opa-utils/reporthandling/results/v1/resourcesresults"
 	reporthandlingv2 "github.com/armosec/opa-utils/reporthandling/v2"
 )
 
+// StatusMissingData the status of the controls which did not fail, evaluated without resources which could not be fetched
+const StatusMissingData apis.ScanningStatus = "missing-data"
+
 // K8SResources map[<api group>/<api version>/<resource>][]<resourceID>
 type K8SResources map[string][]string
 
 type OPASessionObj struct {
-	K8SResources    *K8SResources                          // input k8s objects
-	Frameworks      []reporthandling.Framework             // list of frameworks to scan
-	AllResources    map[string]workloadinterface.IMetadata // all scanned resources, map[<rtesource ID>]<resource>
-	ResourcesResult map[string]resourcesresults.Result     // resources scan results, map[<rtesource ID>]<resource result>
-	PostureReport   *reporthandling.PostureReport          // scan results v1
-	Report          *reporthandlingv2.PostureReport        // scan results v2
-	Exceptions      []armotypes.PostureExceptionPolicy     // list of exceptions to apply on scan results
-	RegoInputData   RegoInputData                          // input passed to rgo for scanning. map[<control name>][<input arguments>]
+	K8SResources      *K8SResources                          // input k8s objects
+	Frameworks        []reporthandling.Framework             // list of frameworks to scan
+	AllResources      map[string]workloadinterface.IMetadata // all scanned resources, map[<rtesource ID>]<resource>
+	ResourcesResult   map[string]resourcesresults.Result     // resources scan results, map[<rtesource ID>]<resource result>
+	PostureReport     *reporthandling.PostureReport          // scan results v1
+	Report            *reporthandlingv2.PostureReport        // scan results v2
+	Exceptions        []armotypes.PostureExceptionPolicy     // list of exceptions to apply on scan results
+	ExpiredExceptions []armotypes.PostureExceptionPolicy     // list of expired exceptions, not applied on the scan results
+	RegoInputData     RegoInputData                          // input passed to rgo for scanning. map[<control name>][<input arguments>]
+	ResourceSource    map[string]ResourceSource              // source file of resources loaded from files, map[<rtesource ID>]<resource source>
+	ScoringConfig     *ScoringConfig                         // overrides of the controls severities and weights, nil when not configured
+	EvaluationTime    map[string]time.Duration               // evaluation time of the controls, map[<control ID>]<duration>
+	Profile           ScanProfile                            // time spent in the phases of the scan
+	SBOMs             []SBOMReference                        // SBOMs of the images of the workloads, generated with --sbom
+	OwnerLabel        string                                 // label of the owners of the resources (e.g. team), in the scores breakdown
+	ScoresBreakdown   *ScoresBreakdown                       // risk-scores by namespace and by owner, set with the score
+	KeepOwnedResults  bool                                   // keep the results of the controlled resources (e.g. Pods of a Deployment) instead of attributing them to their controller
+	OwnedResources    map[string][]string                    // resources whose results were attributed to their top-level controller, map[<controller ID>][]<resource ID>
+	ScanTimes         ScanTimes                              // start and end of the scan, and the time zone of the timestamps of the reports
+	IncludePassed     bool                                   // list the passed and the skipped resources of the controls in the json, html and pdf outputs
+	Redact            *RedactOptions                         // sensitive data redacted from the resources embedded in the reports, nil when not redacted
+	DataGaps          map[string]string                      // resources which could not be fetched, map[<api group>/<api version>/<resource>]<reason>
+	ControlsDataGaps  map[string][]string                    // resources of the controls which could not be fetched, map[<control ID>][]<api group>/<api version>/<resource>
+}
+
+// ScanTimes the start and the end of the scan. The timestamps of the reports are formatted in RFC3339, in the location of the scan
+type ScanTimes struct {
+	Start    time.Time
+	End      time.Time
+	Location *time.Location // UTC when not set
+}
+
+// In returns the time in the location of the scan
+func (times *ScanTimes) In(t time.Time) time.Time {
+	if times.Location == nil {
+		return t.UTC()
+	}
+	return t.In(times.Location)
+}
+
+// Format returns the RFC3339 timestamp of the time in the location of the scan, empty for the zero time
+func (times *ScanTimes) Format(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return times.In(t).Format(time.RFC3339)
+}
+
+// ScoresBreakdown the risk-scores of the resources of each namespace and of each owner, for the posture goals of the tenants
+type ScoresBreakdown struct {
+	OwnerLabel string       `json:"ownerLabel,omitempty"`
+	Namespaces []GroupScore `json:"namespaces"`       // the riskiest first
+	Owners     []GroupScore `json:"owners,omitempty"` // the riskiest first
+}
+
+// GroupScore the risk-score of a group of resources, computed as the total score on the failures of the resources of the group
+type GroupScore struct {
+	Name            string  `json:"name"`
+	Score           float32 `json:"score"`
+	FailedControls  int     `json:"failedControls"`
+	FailedResources int     `json:"failedResources"`
+	Resources       int     `json:"resources"`
+}
+
+// SBOMReference an SBOM generated for an image of the scanned workloads, the digest of the file is the evidence of the SBOM in the report
+type SBOMReference struct {
+	Image     string   `json:"image"`
+	Format    string   `json:"format"` // spdx, cyclonedx
+	Path      string   `json:"path"`
+	SHA256    string   `json:"sha256"`
+	Workloads []string `json:"workloads"` // IDs of the workloads running the image
 }
 
 func NewOPASessionObj(frameworks []reporthandling.Framework, k8sResources *K8SResources) *OPASessionObj {
@@ -29,6 +98,7 @@ func NewOPASessionObj(frameworks []reporthandling.Framework, k8sResources *K8SRe
 		K8SResources:    k8sResources,
 		AllResources:    make(map[string]workloadinterface.IMetadata),
 		ResourcesResult: make(map[string]resourcesresults.Result),
+		ResourceSource:  make(map[string]ResourceSource),
 		PostureReport: &reporthandling.PostureReport{
 			ClusterName:  ClusterName,
 			CustomerGUID: CustomerGUID,
@@ -42,6 +112,7 @@ func NewOPASessionObjMock() *OPASessionObj {
 		K8SResources:    nil,
 		AllResources:    make(map[string]workloadinterface.IMetadata),
 		ResourcesResult: make(map[string]resourcesresults.Result),
+		ResourceSource:  make(map[string]ResourceSource),
 		Report:          &reporthandlingv2.PostureReport{},
 		PostureReport: &reporthandling.PostureReport{
 			ClusterName:  "",
@@ -52,6 +123,13 @@ func NewOPASessionObjMock() *OPASessionObj {
 	}
 }
 
+// ResourceChange a resource created, updated or deleted in the cluster while watching it
+type ResourceChange struct {
+	GroupVersionResource string // <api group>/<api version>/<resource>, same as the keys of K8SResources
+	Resource             workloadinterface.IMetadata
+	Deleted              bool
+}
+
 type ComponentConfig struct {
 	Exceptions Exception `json:"exceptions"`
 }
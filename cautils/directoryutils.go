@@ -0,0 +1,101 @@
+package cautils
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/cautils/logger/helpers"
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+)
+
+// KubescapeIgnoreFile the paths matching the patterns of the file (gitignore syntax) are not scanned.
+// The patterns apply to the directory of the file and its sub directories
+const KubescapeIgnoreFile = ".kubescapeignore"
+
+// WalkDirectory walks the directory recursively, same as filepath.Walk, skipping the hidden directories (e.g. .git)
+// and the paths ignored by the .kubescapeignore files of the directory and its sub directories
+func WalkDirectory(root string, walkFn filepath.WalkFunc) error {
+	patterns := []gitignore.Pattern{}
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return walkFn(path, info, err)
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return walkFn(path, info, err)
+		}
+		domain := []string{}
+		if rel != "." {
+			domain = strings.Split(filepath.ToSlash(rel), "/")
+			if info.IsDir() && strings.HasPrefix(info.Name(), ".") {
+				return filepath.SkipDir
+			}
+			if gitignore.NewMatcher(patterns).Match(domain, info.IsDir()) {
+				if info.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+		}
+		if info.IsDir() {
+			// a pattern applies only to the paths of its domain, the patterns of the sibling directories are not matched
+			patterns = append(patterns, readKubescapeIgnore(path, domain)...)
+		}
+		return walkFn(path, info, nil)
+	})
+}
+
+func readKubescapeIgnore(dir string, domain []string) []gitignore.Pattern {
+	f, err := os.Open(filepath.Join(dir, KubescapeIgnoreFile))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.L().Warning("failed to read ignore file", helpers.String("path", filepath.Join(dir, KubescapeIgnoreFile)), helpers.Error(err))
+		}
+		return nil
+	}
+	defer f.Close()
+
+	patterns := []gitignore.Pattern{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "#") || strings.TrimSpace(line) == "" {
+			continue
+		}
+		patterns = append(patterns, gitignore.ParsePattern(line, domain))
+	}
+	return patterns
+}
+
+// listDirectoryFiles returns the yaml/json files of the directory and its sub directories.
+// The templates of helm charts are not valid manifests, the charts are skipped and should be scanned by 'kubescape scan helm'
+func listDirectoryFiles(root string) ([]string, error) {
+	files := []string{}
+	err := WalkDirectory(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if IsFile(filepath.Join(path, "Chart.yaml")) {
+				logger.L().Info("skipping helm chart, scan it with 'kubescape scan helm'", helpers.String("chart", path))
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if IsYaml(path) || IsJson(path) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files, err
+}
+
+func IsDir(name string) bool {
+	if fi, err := os.Stat(name); err == nil {
+		return fi.IsDir()
+	}
+	return false
+}
@@ -0,0 +1,46 @@
+package cautils
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListDirectoryFiles(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"app/deployment.yaml":        "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web\n",
+		"app/generated/service.yaml": "apiVersion: v1\nkind: Service\nmetadata:\n  name: web\n",
+		"app/service.json":           `{"apiVersion": "v1", "kind": "Service", "metadata": {"name": "api"}}`,
+		"app/README.md":              "# app",
+		"app/.kubescapeignore":       "generated/\n# comment\n*.json\n",
+		"tests/fixture.yaml":         "apiVersion: v1\nkind: Pod\nmetadata:\n  name: fixture\n",
+		"chart/Chart.yaml":           "apiVersion: v2\nname: web\nversion: 0.1.0\n",
+		"chart/templates/pod.yaml":   "apiVersion: v1\nkind: Pod\nmetadata:\n  name: {{ .Release.Name }}\n",
+		".github/workflows/ci.yaml":  "on: push\n",
+		KubescapeIgnoreFile:          "/tests\n",
+	}
+	for name, content := range files {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		assert.NoError(t, os.MkdirAll(filepath.Dir(p), 0755))
+		assert.NoError(t, os.WriteFile(p, []byte(content), 0644))
+	}
+
+	listed, err := listDirectoryFiles(root)
+	assert.NoError(t, err)
+	sort.Strings(listed)
+	assert.Equal(t, []string{filepath.Join(root, "app", "deployment.yaml")}, listed)
+}
+
+func TestIsKubernetesObject(t *testing.T) {
+	workloads, errs := ReadFile([]byte("replicaCount: 1\nimage:\n  tag: latest\n---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: a\n"), YAML_FILE_FORMAT)
+	assert.Equal(t, 0, len(errs))
+	assert.Equal(t, 1, len(workloads))
+
+	workloads, errs = ReadFile([]byte(`[{"name": "kubescape", "version": "1.0.0"}, {"apiVersion": "v1", "kind": "Namespace", "metadata": {"name": "a"}}]`), JSON_FILE_FORMAT)
+	assert.Equal(t, 0, len(errs))
+	assert.Equal(t, 1, len(workloads))
+}
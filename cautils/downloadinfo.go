@@ -6,4 +6,11 @@ type DownloadInfo struct {
 	Target   string // type of artifact to download
 	Name     string // name of artifact to download
 	Account  string // customerGUID
+
+	From               string // policy bundle in an OCI registry to download from, e.g. oci://registry.internal/policies/nsa:v1
+	PolicyVersion      string // version of the released policies, the latest release by default
+	PublicKey          string // public key file verifying the signatures of the released policies, instead of the pinned key
+	InsecureSkipVerify bool   // skip the verification of the signatures of the released policies
+
+	Downloaded []string // the files of the downloaded artifacts
 }
@@ -26,24 +26,32 @@ const (
 	JSON_FILE_FORMAT FileFormat = "json"
 )
 
-func LoadResourcesFromFiles(inputPatterns []string) ([]workloadinterface.IMetadata, error) {
+// ResourceSource the file a resource was loaded from
+type ResourceSource struct {
+	Path string `json:"path"`
+	Line int    `json:"line,omitempty"` // first line of the resource in the file, 0 if unknown
+}
+
+// LoadResourcesFromFiles load the resources matching the input patterns. Returns the list of resources and a map[<resource ID>]<resource source>
+func LoadResourcesFromFiles(inputPatterns []string) ([]workloadinterface.IMetadata, map[string]ResourceSource, error) {
 	files, errs := listFiles(inputPatterns)
 	if len(errs) > 0 {
 		logger.L().Error(fmt.Sprintf("%v", errs))
 	}
 	if len(files) == 0 {
-		return nil, nil
+		return nil, nil, nil
 	}
 
-	workloads, errs := loadFiles(files)
+	workloads, sources, errs := loadFiles(files)
 	if len(errs) > 0 {
 		logger.L().Error(fmt.Sprintf("%v", errs))
 	}
-	return workloads, nil
+	return workloads, sources, nil
 }
 
-func loadFiles(filePaths []string) ([]workloadinterface.IMetadata, []error) {
+func loadFiles(filePaths []string) ([]workloadinterface.IMetadata, map[string]ResourceSource, []error) {
 	workloads := []workloadinterface.IMetadata{}
+	sources := map[string]ResourceSource{}
 	errs := []error{}
 	for i := range filePaths {
 		f, err := loadFile(filePaths[i])
@@ -51,13 +59,25 @@ func loadFiles(filePaths []string) ([]workloadinterface.IMetadata, []error) {
 			errs = append(errs, err)
 			continue
 		}
-		w, e := ReadFile(f, GetFileFormat(filePaths[i]))
+		var w []workloadinterface.IMetadata
+		var lines []int
+		var e []error
+		if GetFileFormat(filePaths[i]) == YAML_FILE_FORMAT {
+			w, lines, e = readYamlDocuments(f)
+		} else {
+			w, e = ReadFile(f, GetFileFormat(filePaths[i]))
+		}
 		errs = append(errs, e...)
-		if w != nil {
-			workloads = append(workloads, w...)
+		for j := range w {
+			source := ResourceSource{Path: filePaths[i]}
+			if j < len(lines) {
+				source.Line = lines[j]
+			}
+			sources[w[j].GetID()] = source
 		}
+		workloads = append(workloads, w...)
 	}
-	return workloads, errs
+	return workloads, sources, errs
 }
 
 func loadFile(filePath string) ([]byte, error) {
@@ -80,7 +100,7 @@ func listFiles(patterns []string) ([]string, []error) {
 	files := []string{}
 	errs := []error{}
 	for i := range patterns {
-		if strings.HasPrefix(patterns[i], "http") {
+		if IsURL(patterns[i]) {
 			continue
 		}
 		if !filepath.IsAbs(patterns[i]) {
@@ -89,6 +109,13 @@ func listFiles(patterns []string) ([]string, []error) {
 		}
 		if IsFile(patterns[i]) {
 			files = append(files, patterns[i])
+		} else if IsDir(patterns[i]) {
+			f, err := listDirectoryFiles(patterns[i])
+			if err != nil {
+				errs = append(errs, err)
+			} else {
+				files = append(files, f...)
+			}
 		} else {
 			f, err := glob(filepath.Split(patterns[i])) //filepath.Glob(patterns[i])
 			if err != nil {
@@ -102,24 +129,47 @@ func listFiles(patterns []string) ([]string, []error) {
 }
 
 func readYamlFile(yamlFile []byte) ([]workloadinterface.IMetadata, []error) {
+	yamlObjs, _, errs := readYamlDocuments(yamlFile)
+	return yamlObjs, errs
+}
+
+// readYamlDocuments decode all documents of a yaml file. Returns the objects and the line each object starts at
+func readYamlDocuments(yamlFile []byte) ([]workloadinterface.IMetadata, []int, []error) {
 	errs := []error{}
 
 	r := bytes.NewReader(yamlFile)
 	dec := yaml.NewDecoder(r)
 	yamlObjs := []workloadinterface.IMetadata{}
+	lines := []int{}
+	documentLines := yamlDocumentLines(yamlFile)
 
-	var t interface{}
-	for dec.Decode(&t) == nil {
+	for i := 0; ; i++ {
+		var t interface{}
+		if dec.Decode(&t) != nil {
+			break
+		}
+		line := 0
+		if i < len(documentLines) {
+			line = documentLines[i]
+		}
 		j := convertYamlToJson(t)
 		if j == nil {
 			continue
 		}
 		if obj, ok := j.(map[string]interface{}); ok {
+			if !isKubernetesObject(obj) {
+				continue // e.g. helm values, CI pipelines
+			}
 			if o := objectsenvelopes.NewObject(obj); o != nil {
 				if o.GetKind() == "List" {
-					yamlObjs = append(yamlObjs, handleListObject(o)...)
+					items := handleListObject(o)
+					for range items {
+						lines = append(lines, line)
+					}
+					yamlObjs = append(yamlObjs, items...)
 				} else {
 					yamlObjs = append(yamlObjs, o)
+					lines = append(lines, line)
 				}
 			}
 		} else {
@@ -127,7 +177,49 @@ func readYamlFile(yamlFile []byte) ([]workloadinterface.IMetadata, []error) {
 		}
 	}
 
-	return yamlObjs, errs
+	return yamlObjs, lines, errs
+}
+
+// yamlDocumentLines returns the first line of the content of each document of a yaml file, in the order the yaml decoder returns them
+func yamlDocumentLines(yamlFile []byte) []int {
+	lines := []int{}
+	explicit := false // the current document was started by a "---" marker
+	start := 0        // first line of the current document, 0 until the document has content
+	marker := 0
+	for i, l := range strings.Split(string(yamlFile), "\n") {
+		l = strings.TrimRight(l, "\r")
+		switch {
+		case l == "---" || strings.HasPrefix(l, "--- ") || strings.HasPrefix(l, "---\t"):
+			if explicit || start > 0 {
+				lines = append(lines, documentLine(start, marker))
+			}
+			explicit, start, marker = true, 0, i+1
+			if rest := strings.TrimSpace(l[3:]); rest != "" && !strings.HasPrefix(rest, "#") {
+				start = i + 1
+			}
+		case l == "..." || strings.HasPrefix(l, "... "):
+			if explicit || start > 0 {
+				lines = append(lines, documentLine(start, marker))
+			}
+			explicit, start = false, 0
+		case start == 0:
+			if trimmed := strings.TrimSpace(l); trimmed != "" && !strings.HasPrefix(trimmed, "#") && !strings.HasPrefix(l, "%") {
+				start = i + 1
+			}
+		}
+	}
+	if explicit || start > 0 {
+		lines = append(lines, documentLine(start, marker))
+	}
+	return lines
+}
+
+// documentLine the line of an empty document is the line of its "---" marker
+func documentLine(start, marker int) int {
+	if start > 0 {
+		return start
+	}
+	return marker
 }
 
 func readJsonFile(jsonFile []byte) ([]workloadinterface.IMetadata, []error) {
@@ -145,6 +237,9 @@ func convertJsonToWorkload(jsonObj interface{}, workloads *[]workloadinterface.I
 
 	switch x := jsonObj.(type) {
 	case map[string]interface{}:
+		if !isKubernetesObject(x) {
+			return
+		}
 		if o := objectsenvelopes.NewObject(x); o != nil {
 			(*workloads) = append(*workloads, o)
 		}
@@ -164,6 +259,10 @@ func convertYamlToJson(i interface{}) interface{} {
 			}
 		}
 		return m2
+	case map[string]interface{}:
+		for k, v := range x {
+			x[k] = convertYamlToJson(v)
+		}
 	case []interface{}:
 		for i, v := range x {
 			x[i] = convertYamlToJson(v)
@@ -172,6 +271,23 @@ func convertYamlToJson(i interface{}) interface{} {
 	return i
 }
 
+// isKubernetesObject returns true for the objects with an apiVersion and a kind, other yaml/json files (e.g. helm values, CI pipelines, package.json) are not scanned
+func isKubernetesObject(obj map[string]interface{}) bool {
+	apiVersion, _ := obj["apiVersion"].(string)
+	kind, _ := obj["kind"].(string)
+	return apiVersion != "" && kind != ""
+}
+
+// IsURL returns true for the URLs of remote files and git repositories, e.g. https://github.com/org/repo or git@github.com:org/repo.git
+func IsURL(pattern string) bool {
+	for _, prefix := range []string{"http://", "https://", "ssh://", "git@"} {
+		if strings.HasPrefix(pattern, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
 func IsYaml(filePath string) bool {
 	return StringInSlice(YAML_PREFIX, filepath.Ext(filePath)) != ValueNotFound
 }
@@ -183,7 +299,7 @@ func IsJson(filePath string) bool {
 func glob(root, pattern string) ([]string, error) {
 	var matches []string
 
-	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+	err := WalkDirectory(root, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
@@ -25,7 +25,7 @@ func TestListFiles(t *testing.T) {
 
 func TestLoadFiles(t *testing.T) {
 	files, _ := listFiles([]string{onlineBoutiquePath()})
-	_, err := loadFiles(files)
+	_, _, err := loadFiles(files)
 	assert.Equal(t, 0, len(err))
 }
 
@@ -36,6 +36,32 @@ func TestLoadFile(t *testing.T) {
 	_, err := loadFile(files[0])
 	assert.NoError(t, err)
 }
+func TestReadYamlDocuments(t *testing.T) {
+	yamlFile := []byte(`apiVersion: v1
+kind: Namespace
+metadata:
+  name: a
+---
+# comment
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: b
+`)
+	objs, lines, errs := readYamlDocuments(yamlFile)
+	assert.Equal(t, 0, len(errs))
+	assert.Equal(t, 2, len(objs))
+	assert.Equal(t, []int{1, 7}, lines)
+	assert.Equal(t, "b", objs[1].GetName())
+}
+
+func TestYamlDocumentLines(t *testing.T) {
+	assert.Equal(t, []int{2, 3, 5}, yamlDocumentLines([]byte("---\na: 1\n---\n---\nb: 2\n")))
+	assert.Equal(t, []int{3}, yamlDocumentLines([]byte("# comment\n---\na: 1\n...\n")))
+	assert.Equal(t, []int{1, 5}, yamlDocumentLines([]byte("a: |\n  x\n  ---\n---\nb: 1\n")))
+	assert.Equal(t, []int{}, yamlDocumentLines([]byte("# comment\n")))
+}
+
 func TestMapResources(t *testing.T) {
 	// policyHandler := &PolicyHandler{}
 	// k8sResources, err := policyHandler.loadResources(opaSessionObj.Frameworks, scanInfo)
@@ -0,0 +1,104 @@
+package getter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	ArtifactsManifestFileName  = "manifest.json" // the manifest of the artifacts downloaded by 'kubescape download artifacts'
+	ArtifactsChecksumsFileName = "SHA256SUMS"    // the checksums of the artifacts, verified by 'sha256sum -c SHA256SUMS'
+)
+
+// ArtifactsManifest the artifacts of a directory, for transferring the artifacts to disconnected environments
+type ArtifactsManifest struct {
+	KubescapeVersion string         `json:"kubescapeVersion"`
+	PolicyVersion    string         `json:"policyVersion"` // the version of the released policies, latest when not pinned
+	CreationTime     time.Time      `json:"creationTime"`
+	Artifacts        []ArtifactFile `json:"artifacts"`
+}
+
+// ArtifactFile a file of the artifacts, the path is relative to the artifacts directory
+type ArtifactFile struct {
+	Artifact string `json:"artifact"` // the type of the artifact, e.g. framework or controls-inputs
+	Path     string `json:"path"`
+	Size     int64  `json:"size"`
+	SHA256   string `json:"sha256"`
+}
+
+// WriteArtifactsManifest writes the manifest and the checksums of the artifacts to the artifacts directory.
+// The sizes and the checksums of the artifacts are computed from their files
+func WriteArtifactsManifest(dir string, manifest *ArtifactsManifest) error {
+	sort.Slice(manifest.Artifacts, func(i, j int) bool { return manifest.Artifacts[i].Path < manifest.Artifacts[j].Path })
+	checksums := &strings.Builder{}
+	for i := range manifest.Artifacts {
+		artifact := &manifest.Artifacts[i]
+		size, checksum, err := fileChecksum(filepath.Join(dir, artifact.Path))
+		if err != nil {
+			return err
+		}
+		artifact.Size, artifact.SHA256 = size, checksum
+		fmt.Fprintf(checksums, "%s  %s\n", checksum, filepath.ToSlash(artifact.Path))
+	}
+	if err := SaveInFile(manifest, filepath.Join(dir, ArtifactsManifestFileName)); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, ArtifactsChecksumsFileName), []byte(checksums.String()), 0644)
+}
+
+// LoadArtifactsManifest loads the manifest of the artifacts directory, returns an error of os.ErrNotExist when the directory has no manifest
+func LoadArtifactsManifest(dir string) (*ArtifactsManifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ArtifactsManifestFileName))
+	if err != nil {
+		return nil, err
+	}
+	manifest := &ArtifactsManifest{}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("failed to load the manifest of the artifacts '%s': %w", dir, err)
+	}
+	return manifest, nil
+}
+
+// VerifyArtifacts verifies the artifacts of the directory against the checksums of its manifest, nothing is verified when there is no manifest
+func VerifyArtifacts(dir string) error {
+	manifest, err := LoadArtifactsManifest(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	for _, artifact := range manifest.Artifacts {
+		_, checksum, err := fileChecksum(filepath.Join(dir, artifact.Path))
+		if err != nil {
+			return fmt.Errorf("missing artifact '%s' of the manifest: %w", artifact.Path, err)
+		}
+		if checksum != artifact.SHA256 {
+			return fmt.Errorf("the checksum of the artifact '%s' does not match the manifest, expected %s, received %s", artifact.Path, artifact.SHA256, checksum)
+		}
+	}
+	return nil
+}
+
+func fileChecksum(path string) (int64, string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, "", err
+	}
+	defer f.Close()
+	hash := sha256.New()
+	size, err := io.Copy(hash, f)
+	if err != nil {
+		return 0, "", err
+	}
+	return size, hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,41 @@
+package getter
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArtifactsManifest(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, VerifyArtifacts(dir), "a directory without a manifest is not verified")
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "nsa.json"), []byte(`{"name": "NSA"}`), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "controls-inputs.json"), []byte(`{}`), 0644))
+	manifest := &ArtifactsManifest{PolicyVersion: "v1.0.172", Artifacts: []ArtifactFile{
+		{Artifact: "framework", Path: "nsa.json"},
+		{Artifact: "controls-inputs", Path: "controls-inputs.json"},
+	}}
+	assert.NoError(t, WriteArtifactsManifest(dir, manifest))
+
+	loaded, err := LoadArtifactsManifest(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, "v1.0.172", loaded.PolicyVersion)
+	assert.Equal(t, "controls-inputs.json", loaded.Artifacts[0].Path)
+	assert.Equal(t, int64(2), loaded.Artifacts[0].Size)
+	assert.Equal(t, "44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a", loaded.Artifacts[0].SHA256)
+
+	checksums, err := os.ReadFile(filepath.Join(dir, ArtifactsChecksumsFileName))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, strings.Count(string(checksums), "\n"))
+	assert.True(t, strings.HasPrefix(string(checksums), "44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a  controls-inputs.json\n"))
+
+	assert.NoError(t, VerifyArtifacts(dir))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "nsa.json"), []byte(`{"name": "nsa"}`), 0644))
+	assert.Error(t, VerifyArtifacts(dir))
+	assert.NoError(t, os.Remove(filepath.Join(dir, "nsa.json")))
+	assert.Error(t, VerifyArtifacts(dir))
+}
@@ -0,0 +1,66 @@
+package getter
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// ControlsInputsFileName the default file name of the controls inputs, same as 'kubescape download controls-inputs'
+const ControlsInputsFileName = "controls-inputs.json"
+
+// SetControlInput sets the values of a control input (e.g. imageRepositoryAllowList), or appends the values missing from the input
+func SetControlInput(controlsInputs map[string][]string, key string, values []string, appendValues bool) {
+	if !appendValues {
+		controlsInputs[key] = []string{}
+	}
+	for _, value := range values {
+		if !contains(controlsInputs[key], value) {
+			controlsInputs[key] = append(controlsInputs[key], value)
+		}
+	}
+}
+
+// SaveControlsInputs saves the controls inputs in a YAML file, or in a JSON file by the file extension
+func SaveControlsInputs(filePath string, controlsInputs map[string][]string) error {
+	var data []byte
+	var err error
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".yaml", ".yml":
+		data, err = yaml.Marshal(controlsInputs)
+	default:
+		data, err = json.MarshalIndent(controlsInputs, "", "  ")
+	}
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(filePath); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(filePath, data, 0644)
+}
+
+// ListControlsInputsKeys returns the keys of the controls inputs, sorted
+func ListControlsInputsKeys(controlsInputs map[string][]string) []string {
+	keys := make([]string, 0, len(controlsInputs))
+	for key := range controlsInputs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ValidateControlInputKey validates the key of a control input, the keys are the names of the settings in the Rego data document
+func ValidateControlInputKey(key string) error {
+	if key == "" || strings.ContainsAny(key, " .=") {
+		return fmt.Errorf("invalid control input '%s', expected the name of the input, e.g. imageRepositoryAllowList", key)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package getter
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSetControlInput(t *testing.T) {
+	controlsInputs := map[string][]string{"imageRepositoryAllowList": {"quay.io/"}}
+
+	SetControlInput(controlsInputs, "imageRepositoryAllowList", []string{"docker.io/", "quay.io/"}, true)
+	if expected := []string{"quay.io/", "docker.io/"}; !reflect.DeepEqual(controlsInputs["imageRepositoryAllowList"], expected) {
+		t.Errorf("expected %v, received %v", expected, controlsInputs["imageRepositoryAllowList"])
+	}
+
+	SetControlInput(controlsInputs, "imageRepositoryAllowList", []string{"gcr.io/"}, false)
+	if expected := []string{"gcr.io/"}; !reflect.DeepEqual(controlsInputs["imageRepositoryAllowList"], expected) {
+		t.Errorf("expected %v, received %v", expected, controlsInputs["imageRepositoryAllowList"])
+	}
+
+	// no values - the input is cleared
+	SetControlInput(controlsInputs, "insecureCapabilities", nil, false)
+	if values, ok := controlsInputs["insecureCapabilities"]; !ok || len(values) != 0 {
+		t.Errorf("expected an empty input, received %v", values)
+	}
+
+	for _, key := range []string{"", "a b", "settings.postureControlInputs", "a=b"} {
+		if ValidateControlInputKey(key) == nil {
+			t.Errorf("expected an error for '%s'", key)
+		}
+	}
+}
+
+func TestSaveControlsInputs(t *testing.T) {
+	controlsInputs := map[string][]string{"imageRepositoryAllowList": {"quay.io/"}, "insecureCapabilities": {}}
+	for _, fileName := range []string{"controls-inputs.yaml", "controls-inputs.json"} {
+		filePath := filepath.Join(t.TempDir(), "config", fileName)
+		if err := SaveControlsInputs(filePath, controlsInputs); err != nil {
+			t.Fatal(err)
+		}
+		loaded, err := NewLoadPolicy([]string{filePath}).GetControlsInputs("")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(loaded, controlsInputs) {
+			t.Errorf("%s: expected %v, received %v", fileName, controlsInputs, loaded)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package getter
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/armosec/armoapi-go/armotypes"
+	"github.com/armosec/opa-utils/reporthandling"
+	"github.com/open-policy-agent/opa/ast"
+)
+
+// CustomControlsFramework the name of the framework of the user-authored controls, scanned alongside the scanned frameworks
+const CustomControlsFramework = "custom-controls"
+
+// customControl a user-authored control, a JSON/YAML file in the format of the regolibrary controls with the rules inlined
+type customControl struct {
+	reporthandling.Control `json:",inline"`
+	Rules                  []customRule `json:"rules"`
+}
+
+// customRule a rule of a user-authored control, the Rego policy is set inline ("rule") or by a file relative to the control file ("ruleFile")
+type customRule struct {
+	reporthandling.PolicyRule `json:",inline"`
+	RuleFile                  string `json:"ruleFile,omitempty"`
+}
+
+// LoadCustomControls loads the user-authored controls of the JSON/YAML files of a directory, sorted by control ID.
+// The subdirectories are not loaded, e.g. the tests of the controls
+func LoadCustomControls(dir string) ([]reporthandling.Control, error) {
+	filePaths, err := ListCustomControlsFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	controls := []reporthandling.Control{}
+	controlsIDs := map[string]string{}
+	for _, filePath := range filePaths {
+		control, err := LoadCustomControl(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load custom control '%s': %w", filePath, err)
+		}
+		if previous, ok := controlsIDs[strings.ToUpper(control.ControlID)]; ok {
+			return nil, fmt.Errorf("control ID '%s' of '%s' is already defined in '%s'", control.ControlID, filePath, previous)
+		}
+		controlsIDs[strings.ToUpper(control.ControlID)] = filePath
+		controls = append(controls, *control)
+	}
+	if len(controls) == 0 {
+		return nil, fmt.Errorf("no controls found in '%s'", dir)
+	}
+	sort.Slice(controls, func(i, j int) bool { return controls[i].ControlID < controls[j].ControlID })
+	return controls, nil
+}
+
+// ListCustomControlsFiles returns the JSON/YAML files of a directory of user-authored controls, without the subdirectories
+func ListCustomControlsFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	filePaths := []string{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		switch strings.ToLower(filepath.Ext(entry.Name())) {
+		case ".json", ".yaml", ".yml":
+			filePaths = append(filePaths, filepath.Join(dir, entry.Name()))
+		}
+	}
+	return filePaths, nil
+}
+
+// LoadCustomControl loads a user-authored control file, the Rego policies of the rules are parsed but not compiled
+func LoadCustomControl(filePath string) (*reporthandling.Control, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	custom := &customControl{}
+	if err := unmarshalPolicy(filePath, data, custom); err != nil {
+		return nil, err
+	}
+	if custom.ControlID == "" || custom.Name == "" {
+		return nil, fmt.Errorf("missing controlID/name")
+	}
+	if len(custom.Rules) == 0 {
+		return nil, fmt.Errorf("control '%s' has no rules", custom.ControlID)
+	}
+
+	control := custom.Control
+	control.Rules = []reporthandling.PolicyRule{}
+	control.FrameworkNames = []string{CustomControlsFramework}
+	for i := range custom.Rules {
+		rule, err := loadCustomRule(filepath.Dir(filePath), &custom.Rules[i])
+		if err != nil {
+			return nil, fmt.Errorf("control '%s': %w", custom.ControlID, err)
+		}
+		control.Rules = append(control.Rules, *rule)
+	}
+	return &control, nil
+}
+
+func loadCustomRule(dir string, custom *customRule) (*reporthandling.PolicyRule, error) {
+	rule := custom.PolicyRule
+	if rule.Name == "" {
+		return nil, fmt.Errorf("missing rule name")
+	}
+	if custom.RuleFile != "" {
+		filePath := custom.RuleFile
+		if !filepath.IsAbs(filePath) {
+			filePath = filepath.Join(dir, filePath)
+		}
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("rule '%s': %w", rule.Name, err)
+		}
+		rule.Rule = string(data)
+	}
+	if rule.Rule == "" {
+		return nil, fmt.Errorf("rule '%s': missing rule/ruleFile", rule.Name)
+	}
+	if _, err := ast.ParseModule(rule.Name, rule.Rule); err != nil {
+		return nil, fmt.Errorf("rule '%s': %w", rule.Name, err)
+	}
+	if len(rule.Match) == 0 {
+		return nil, fmt.Errorf("rule '%s': missing match", rule.Name)
+	}
+	if rule.RuleLanguage == "" {
+		rule.RuleLanguage = reporthandling.RegoLanguage
+	}
+	if rule.RuleQuery == "" {
+		rule.RuleQuery = "armo_builtins"
+	}
+	return &rule, nil
+}
+
+// NewCustomControlsFramework returns the framework of the user-authored controls
+func NewCustomControlsFramework(controls []reporthandling.Control) *reporthandling.Framework {
+	return &reporthandling.Framework{
+		PortalBase:  armotypes.PortalBase{Name: CustomControlsFramework},
+		Description: "User-authored controls",
+		Controls:    controls,
+	}
+}
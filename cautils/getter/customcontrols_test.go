@@ -0,0 +1,59 @@
+package getter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/armosec/opa-utils/reporthandling"
+)
+
+func TestLoadCustomControls(t *testing.T) {
+	controls, err := LoadCustomControls(filepath.Join("..", "..", "examples", "custom-controls"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(controls) != 3 || controls[0].ControlID != "CUSTOM-0001" || controls[0].BaseScore != 5 || controls[1].ControlID != "CUSTOM-0002" || controls[2].ControlID != "CUSTOM-0003" {
+		t.Fatalf("unexpected controls: %+v", controls)
+	}
+	rule := controls[0].Rules[0]
+	if rule.Name != "workload-owner-label" || rule.Rule == "" || rule.RuleLanguage != reporthandling.RegoLanguage || len(rule.Match) != 1 {
+		t.Errorf("unexpected rule: %+v", rule)
+	}
+	if rule := controls[1].Rules[0]; rule.Name != "unsigned-images" || len(rule.Match) != 4 {
+		t.Errorf("unexpected rule: %+v", rule)
+	}
+
+	invalid := map[string]string{
+		"missing ID":    `{"name": "a", "rules": [{"name": "r", "rule": "package armo_builtins", "match": [{"resources": ["pods"]}]}]}`,
+		"no rules":      `{"controlID": "CUSTOM-1", "name": "a"}`,
+		"no rego":       `{"controlID": "CUSTOM-1", "name": "a", "rules": [{"name": "r", "match": [{"resources": ["pods"]}]}]}`,
+		"invalid rego":  `{"controlID": "CUSTOM-1", "name": "a", "rules": [{"name": "r", "rule": "package armo_builtins\ndeny[msga] {", "match": [{"resources": ["pods"]}]}]}`,
+		"missing file":  `{"controlID": "CUSTOM-1", "name": "a", "rules": [{"name": "r", "ruleFile": "missing.rego", "match": [{"resources": ["pods"]}]}]}`,
+		"missing match": `{"controlID": "CUSTOM-1", "name": "a", "rules": [{"name": "r", "rule": "package armo_builtins"}]}`,
+	}
+	for name, content := range invalid {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "control.json"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadCustomControls(dir); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	// duplicated control IDs
+	dir := t.TempDir()
+	for _, name := range []string{"a.json", "b.json"} {
+		content := `{"controlID": "CUSTOM-1", "name": "a", "rules": [{"name": "r", "rule": "package armo_builtins", "match": [{"resources": ["pods"]}]}]}`
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := LoadCustomControls(dir); err == nil {
+		t.Error("expected an error for duplicated control IDs")
+	}
+	if _, err := LoadCustomControls(t.TempDir()); err == nil {
+		t.Error("expected an error for a directory without controls")
+	}
+}
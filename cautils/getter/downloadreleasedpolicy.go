@@ -1,19 +1,27 @@
 package getter
 
 import (
+	"fmt"
+	"net/http"
 	"strings"
 
+	"github.com/armosec/armoapi-go/armotypes"
 	"github.com/armosec/opa-utils/gitregostore"
 	"github.com/armosec/opa-utils/reporthandling"
+	"github.com/go-gota/gota/dataframe"
 )
 
+// the artifacts of the policies release, see gitregostore
+var releasedPolicyArtifacts = []string{"frameworks", "controls", "rules", "FWName_CID_CName", "ControlID_RuleName", "default_config_inputs"}
+
 // =======================================================================================================================
 // ======================================== DownloadReleasedPolicy =======================================================
 // =======================================================================================================================
 
 // Use gitregostore to get policies from github release
 type DownloadReleasedPolicy struct {
-	gs *gitregostore.GitRegoStore
+	gs       *gitregostore.GitRegoStore
+	verifier *SignatureVerifier
 }
 
 func NewDownloadReleasedPolicy() *DownloadReleasedPolicy {
@@ -22,6 +30,51 @@ func NewDownloadReleasedPolicy() *DownloadReleasedPolicy {
 	}
 }
 
+// NewVerifiedDownloadReleasedPolicy the artifacts of the release are verified against their detached signatures,
+// pulling the policies fails if an artifact is not signed by the key of the verifier
+func NewVerifiedDownloadReleasedPolicy(verifier *SignatureVerifier) *DownloadReleasedPolicy {
+	return &DownloadReleasedPolicy{
+		gs:       gitregostore.NewDefaultGitRegoStore(-1),
+		verifier: verifier,
+	}
+}
+
+// SetPolicyVersion pins the version of the released policies (the tag of the regolibrary release, e.g. v1.0.172), the latest release by default
+func (drp *DownloadReleasedPolicy) SetPolicyVersion(policyVersion string) {
+	tag := "latest/download"
+	if policyVersion = NormalizePolicyVersion(policyVersion); policyVersion != "" {
+		tag = "download/" + policyVersion
+	}
+	drp.gs = gitregostore.NewGitRegoStore(drp.gs.BaseUrl, drp.gs.Owner, drp.gs.Repository, drp.gs.Path, tag, "", -1)
+}
+
+// NormalizePolicyVersion returns the tag of a policies version, e.g. 1.0.172 -> v1.0.172
+func NormalizePolicyVersion(policyVersion string) string {
+	policyVersion = strings.TrimSpace(policyVersion)
+	if policyVersion != "" && policyVersion[0] >= '0' && policyVersion[0] <= '9' {
+		policyVersion = "v" + policyVersion
+	}
+	return policyVersion
+}
+
+// ResolveLatestPolicyVersion returns the tag of the latest release of the policies, by the redirect of the latest release
+func ResolveLatestPolicyVersion() (string, error) {
+	gs := gitregostore.NewDefaultGitRegoStore(-1)
+	httpClient := &http.Client{CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := httpClient.Get(fmt.Sprintf("%s/%s/%s/releases/latest", gs.BaseUrl, gs.Owner, gs.Repository))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	location := resp.Header.Get("Location")
+	if i := strings.LastIndex(location, "/tag/"); i != -1 && i+len("/tag/") < len(location) {
+		return location[i+len("/tag/"):], nil
+	}
+	return "", fmt.Errorf("failed to resolve the latest release of the policies, status: %s", resp.Status)
+}
+
 func (drp *DownloadReleasedPolicy) GetControl(policyName string) (*reporthandling.Control, error) {
 	var control *reporthandling.Control
 	var err error
@@ -33,6 +86,11 @@ func (drp *DownloadReleasedPolicy) GetControl(policyName string) (*reporthandlin
 	return control, nil
 }
 
+// GetControls returns all of the controls of the release
+func (drp *DownloadReleasedPolicy) GetControls() ([]reporthandling.Control, error) {
+	return drp.gs.GetOPAControls()
+}
+
 func (drp *DownloadReleasedPolicy) GetFramework(name string) (*reporthandling.Framework, error) {
 	framework, err := drp.gs.GetOPAFrameworkByName(name)
 	if err != nil {
@@ -75,9 +133,50 @@ func (drp *DownloadReleasedPolicy) SetRegoObjects() error {
 	if len(fwNames) != 0 && err == nil {
 		return nil
 	}
+	if drp.verifier != nil {
+		return drp.setVerifiedRegoObjects()
+	}
 	return drp.gs.SetRegoObjects()
 }
 
+// setVerifiedRegoObjects pulls the artifacts of the release and their signatures, and sets the store only if all of the artifacts are verified
+func (drp *DownloadReleasedPolicy) setVerifiedRegoObjects() error {
+	httpClient := &http.Client{}
+	artifacts := map[string]string{}
+	for _, artifact := range releasedPolicyArtifacts {
+		artifactURL := fmt.Sprintf("%s/%s", drp.gs.URL, artifact)
+		data, err := gitregostore.HttpGetter(httpClient, artifactURL)
+		if err != nil {
+			return fmt.Errorf("error getting: %s from: '%s', error: %w", artifact, drp.gs.URL, err)
+		}
+		signature, err := gitregostore.HttpGetter(httpClient, drp.verifier.SignatureName(artifactURL))
+		if err != nil {
+			return fmt.Errorf("error getting the signature of: %s from: '%s', error: %w", artifact, drp.gs.URL, err)
+		}
+		if err := drp.verifier.Verify([]byte(data), []byte(signature)); err != nil {
+			return fmt.Errorf("failed to verify the signature of '%s': %w", artifact, err)
+		}
+		artifacts[artifact] = data
+	}
+
+	frameworks := []reporthandling.Framework{}
+	controls := []reporthandling.Control{}
+	rules := []reporthandling.PolicyRule{}
+	defaultConfigInputs := armotypes.CustomerConfig{}
+	for artifact, v := range map[string]interface{}{"frameworks": &frameworks, "controls": &controls, "rules": &rules, "default_config_inputs": &defaultConfigInputs} {
+		if err := gitregostore.JSONDecoder(artifacts[artifact]).Decode(v); err != nil {
+			return fmt.Errorf("failed to decode '%s': %w", artifact, err)
+		}
+	}
+	drp.gs.Frameworks = frameworks
+	drp.gs.Controls = controls
+	drp.gs.Rules = rules
+	drp.gs.DefaultConfigInputs = defaultConfigInputs
+	drp.gs.FrameworkControlRelations = dataframe.ReadCSV(strings.NewReader(artifacts["FWName_CID_CName"]))
+	drp.gs.ControlRuleRelations = dataframe.ReadCSV(strings.NewReader(artifacts["ControlID_RuleName"]))
+	return nil
+}
+
 func isNativeFramework(framework string) bool {
 	return contains(NativeFrameworks, framework)
 }
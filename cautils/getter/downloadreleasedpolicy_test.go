@@ -0,0 +1,17 @@
+package getter
+
+import "testing"
+
+func TestSetPolicyVersion(t *testing.T) {
+	drp := NewDownloadReleasedPolicy()
+	for version, expected := range map[string]string{
+		"":         "https://github.com/armosec/regolibrary/releases/latest/download",
+		"v1.0.172": "https://github.com/armosec/regolibrary/releases/download/v1.0.172",
+		"1.0.172":  "https://github.com/armosec/regolibrary/releases/download/v1.0.172",
+	} {
+		drp.SetPolicyVersion(version)
+		if drp.gs.URL != expected {
+			t.Errorf("version '%s': expected '%s', received '%s'", version, expected, drp.gs.URL)
+		}
+	}
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/armosec/armoapi-go/armotypes"
 	"github.com/armosec/opa-utils/reporthandling"
+	"sigs.k8s.io/yaml"
 )
 
 // =======================================================================================================================
@@ -26,7 +27,9 @@ func getCacheDir() string {
 
 // Load policies from a local repository
 type LoadPolicy struct {
-	filePaths []string
+	filePaths         []string
+	customControlsDir string
+	controlsGetter    func() IPolicyGetter
 }
 
 func NewLoadPolicy(filePaths []string) *LoadPolicy {
@@ -35,6 +38,18 @@ func NewLoadPolicy(filePaths []string) *LoadPolicy {
 	}
 }
 
+// SetControlsGetter sets the getter of the controls of custom frameworks which are not found in the local files.
+// The getter is created only when a custom framework references such a control
+func (lp *LoadPolicy) SetControlsGetter(controlsGetter func() IPolicyGetter) {
+	lp.controlsGetter = controlsGetter
+}
+
+// SetCustomControlsDir sets the directory of the user-authored controls, the controls of custom frameworks are looked up in
+// the directory first - e.g. a benchmark framework of user-authored controls
+func (lp *LoadPolicy) SetCustomControlsDir(dir string) {
+	lp.customControlsDir = dir
+}
+
 // Return control from file
 func (lp *LoadPolicy) GetControl(controlName string) (*reporthandling.Control, error) {
 
@@ -73,7 +88,8 @@ func (lp *LoadPolicy) GetFramework(frameworkName string) (*reporthandling.Framew
 			return nil, err
 		}
 
-		if err = json.Unmarshal(f, framework); err != nil {
+		framework = &reporthandling.Framework{}
+		if err = unmarshalPolicy(filePath, f, framework); err != nil {
 			return framework, err
 		}
 		if strings.EqualFold(frameworkName, framework.Name) {
@@ -84,9 +100,92 @@ func (lp *LoadPolicy) GetFramework(frameworkName string) (*reporthandling.Framew
 
 		return nil, fmt.Errorf("framework from file not matching")
 	}
+	if isCustomFramework(framework) {
+		if err := lp.setCustomFrameworkControls(framework); err != nil {
+			return nil, err
+		}
+	}
 	return framework, err
 }
 
+// isCustomFramework returns true if the framework is a named set of existing controls IDs, without the controls
+func isCustomFramework(framework *reporthandling.Framework) bool {
+	return framework.ControlsIDs != nil && len(framework.Controls) == 0
+}
+
+// setCustomFrameworkControls sets the controls of a custom framework. The controls are loaded from the user-authored controls, the frameworks
+// of the local files and the cached frameworks (kubescape download), falling back to the controls getter
+func (lp *LoadPolicy) setCustomFrameworkControls(framework *reporthandling.Framework) error {
+	localControls, err := lp.listLocalControls()
+	if err != nil {
+		return err
+	}
+	var controlsGetter IPolicyGetter
+	for _, controlID := range *framework.ControlsIDs {
+		control, ok := localControls[strings.ToUpper(controlID)]
+		if !ok {
+			if lp.controlsGetter == nil {
+				return fmt.Errorf("control '%s' of framework '%s' not found", controlID, framework.Name)
+			}
+			if controlsGetter == nil {
+				controlsGetter = lp.controlsGetter()
+			}
+			c, err := controlsGetter.GetControl(controlID)
+			if err != nil {
+				return fmt.Errorf("failed to load control '%s' of framework '%s': %w", controlID, framework.Name, err)
+			}
+			control = *c
+		}
+		framework.Controls = append(framework.Controls, control)
+	}
+	return nil
+}
+
+// listLocalControls returns the user-authored controls and the controls of the frameworks of the local files and the cached frameworks,
+// map[<control ID>]<control>
+func (lp *LoadPolicy) listLocalControls() (map[string]reporthandling.Control, error) {
+	filePaths := append([]string{}, lp.filePaths...)
+	for i := range NativeFrameworks {
+		filePaths = append(filePaths, GetDefaultPath(NativeFrameworks[i]+".json"))
+	}
+	controls := map[string]reporthandling.Control{}
+	if lp.customControlsDir != "" {
+		customControls, err := LoadCustomControls(lp.customControlsDir)
+		if err != nil {
+			return nil, err
+		}
+		for _, control := range customControls {
+			controls[strings.ToUpper(control.ControlID)] = control
+		}
+	}
+	for _, filePath := range filePaths {
+		f, err := os.ReadFile(filePath)
+		if err != nil {
+			continue
+		}
+		framework := &reporthandling.Framework{}
+		if err := unmarshalPolicy(filePath, f, framework); err != nil {
+			continue
+		}
+		for _, control := range framework.Controls {
+			if _, ok := controls[strings.ToUpper(control.ControlID)]; !ok {
+				controls[strings.ToUpper(control.ControlID)] = control
+			}
+		}
+	}
+	return controls, nil
+}
+
+// unmarshalPolicy unmarshals a JSON file, or a YAML file by the file extension
+func unmarshalPolicy(filePath string, data []byte, policy interface{}) error {
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".yaml", ".yml":
+		return yaml.Unmarshal(data, policy)
+	default:
+		return json.Unmarshal(data, policy)
+	}
+}
+
 func (lp *LoadPolicy) GetFrameworks() ([]reporthandling.Framework, error) {
 	frameworks := []reporthandling.Framework{}
 	var err error
@@ -99,7 +198,7 @@ func (lp *LoadPolicy) ListFrameworks() ([]string, error) {
 	for _, f := range lp.filePaths {
 		file, err := os.ReadFile(f)
 		if err == nil {
-			if err := json.Unmarshal(file, framework); err == nil {
+			if err := unmarshalPolicy(f, file, framework); err == nil {
 				if !contains(fwNames, framework.Name) {
 					fwNames = append(fwNames, framework.Name)
 				}
@@ -134,7 +233,7 @@ func (lp *LoadPolicy) GetControlsInputs(clusterName string) (map[string][]string
 		return nil, err
 	}
 
-	if err = json.Unmarshal(f, &accountConfig.Settings.PostureControlInputs); err == nil {
+	if err = unmarshalPolicy(filePath, f, &accountConfig.Settings.PostureControlInputs); err == nil {
 		return accountConfig.Settings.PostureControlInputs, nil
 	}
 	return nil, err
@@ -1,7 +1,12 @@
 package getter
 
 import (
+	"fmt"
+	"os"
 	"path/filepath"
+	"testing"
+
+	"github.com/armosec/opa-utils/reporthandling"
 )
 
 var mockFrameworkBasePath = filepath.Join("examples", "mocks", "frameworks")
@@ -11,3 +16,81 @@ func MockNewLoadPolicy() *LoadPolicy {
 		filePaths: []string{""},
 	}
 }
+
+// controlsGetterMock returns the controls by ID
+type controlsGetterMock struct {
+	LoadPolicy
+	controls map[string]reporthandling.Control
+}
+
+func (getter *controlsGetterMock) GetControl(controlID string) (*reporthandling.Control, error) {
+	if control, ok := getter.controls[controlID]; ok {
+		return &control, nil
+	}
+	return nil, fmt.Errorf("control '%s' not found", controlID)
+}
+
+func TestGetCustomFramework(t *testing.T) {
+	defaultLocalStore := DefaultLocalStore
+	DefaultLocalStore = t.TempDir()
+	defer func() { DefaultLocalStore = defaultLocalStore }()
+
+	dir := t.TempDir()
+	customFramework := filepath.Join(dir, "my-framework.yaml")
+	if err := os.WriteFile(customFramework, []byte("name: my-framework\ndescription: internal baseline\nattributes:\n  owner: platform-team\ncontrolsIDs:\n- c-0016\n- C-0017\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	framework := filepath.Join(dir, "nsa.json")
+	if err := os.WriteFile(framework, []byte(`{"name": "NSA", "controls": [{"controlID": "C-0016", "name": "Allow privilege escalation", "baseScore": 6}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	loadPolicy := NewLoadPolicy([]string{customFramework, framework})
+	if _, err := loadPolicy.GetFramework("my-framework"); err == nil {
+		t.Error("expected an error for a control which is not found")
+	}
+
+	// C-0017 is not in the local files
+	loadPolicy.SetControlsGetter(func() IPolicyGetter {
+		return &controlsGetterMock{controls: map[string]reporthandling.Control{"C-0017": {ControlID: "C-0017", BaseScore: 7}}}
+	})
+	f, err := loadPolicy.GetFramework("my-framework")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Description != "internal baseline" || f.Attributes["owner"] != "platform-team" {
+		t.Errorf("unexpected framework metadata: %+v", f.PortalBase)
+	}
+	if len(f.Controls) != 2 || f.Controls[0].ControlID != "C-0016" || f.Controls[0].BaseScore != 6 || f.Controls[1].ControlID != "C-0017" {
+		t.Errorf("unexpected framework controls: %+v", f.Controls)
+	}
+
+	// the frameworks of the other files are not changed
+	if f, err := loadPolicy.GetFramework("nsa"); err != nil || len(f.Controls) != 1 || f.ControlsIDs != nil {
+		t.Errorf("unexpected framework: %+v, %v", f, err)
+	}
+	if names, _ := loadPolicy.ListFrameworks(); len(names) != 2 || names[0] != "my-framework" {
+		t.Errorf("unexpected frameworks names: %v", names)
+	}
+}
+
+func TestGetCustomFrameworkOfCustomControls(t *testing.T) {
+	defaultLocalStore := DefaultLocalStore
+	DefaultLocalStore = t.TempDir()
+	defer func() { DefaultLocalStore = defaultLocalStore }()
+
+	examples := filepath.Join("..", "..", "examples")
+	loadPolicy := NewLoadPolicy([]string{filepath.Join(examples, "frameworks", "cis-control-plane.yaml")})
+	if _, err := loadPolicy.GetFramework("cis-control-plane"); err == nil {
+		t.Error("expected an error for the user-authored controls without their directory")
+	}
+
+	loadPolicy.SetCustomControlsDir(filepath.Join(examples, "cis-controls"))
+	f, err := loadPolicy.GetFramework("cis-control-plane")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.Controls) != 16 || f.Controls[0].ControlID != "CIS-1.2.1" || len(f.Controls[0].Rules) != 1 || f.Controls[0].Rules[0].Rule == "" {
+		t.Errorf("unexpected framework controls: %+v", f.Controls)
+	}
+}
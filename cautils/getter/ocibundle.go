@@ -0,0 +1,386 @@
+package getter
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const (
+	// OCIScheme the scheme of the policy bundles references, e.g. oci://registry.internal/policies/nsa:v1
+	OCIScheme = "oci://"
+
+	// PolicyBundleConfigMediaType the media type of the config of a policy bundle, the artifact type of the bundle
+	PolicyBundleConfigMediaType = "application/vnd.kubescape.policy.config.v1+json"
+	// PolicyBundleLayerMediaType the media type of the artifacts of a policy bundle - frameworks, controls inputs, exceptions
+	PolicyBundleLayerMediaType = "application/vnd.kubescape.policy.layer.v1+json"
+)
+
+// IsOCIReference returns true if the path is a reference of a policy bundle in an OCI registry
+func IsOCIReference(path string) bool {
+	return strings.HasPrefix(path, OCIScheme)
+}
+
+// OCIReference a reference of an artifact in an OCI registry
+type OCIReference struct {
+	Registry   string // host[:port]
+	Repository string
+	Reference  string // tag or digest
+}
+
+// ParseOCIReference parses oci://<registry>/<repository>[:<tag>|@<digest>], the tag is 'latest' by default
+func ParseOCIReference(ref string) (*OCIReference, error) {
+	s := strings.TrimPrefix(ref, OCIScheme)
+	i := strings.Index(s, "/")
+	if i <= 0 || i == len(s)-1 {
+		return nil, fmt.Errorf("invalid OCI reference '%s', expected oci://<registry>/<repository>:<tag>", ref)
+	}
+	reference := &OCIReference{Registry: s[:i], Repository: s[i+1:], Reference: "latest"}
+	if j := strings.Index(reference.Repository, "@"); j != -1 {
+		reference.Repository, reference.Reference = reference.Repository[:j], reference.Repository[j+1:]
+		if _, err := digest.Parse(reference.Reference); err != nil {
+			return nil, fmt.Errorf("invalid digest of OCI reference '%s': %w", ref, err)
+		}
+	} else if j := strings.LastIndex(reference.Repository, ":"); j != -1 && !strings.Contains(reference.Repository[j:], "/") {
+		reference.Repository, reference.Reference = reference.Repository[:j], reference.Repository[j+1:]
+	}
+	if reference.Repository == "" || reference.Reference == "" || reference.Repository != strings.ToLower(reference.Repository) {
+		return nil, fmt.Errorf("invalid OCI reference '%s', expected oci://<registry>/<repository>:<tag>", ref)
+	}
+	return reference, nil
+}
+
+func (reference *OCIReference) String() string {
+	if strings.Contains(reference.Reference, ":") {
+		return fmt.Sprintf("%s%s/%s@%s", OCIScheme, reference.Registry, reference.Repository, reference.Reference)
+	}
+	return fmt.Sprintf("%s%s/%s:%s", OCIScheme, reference.Registry, reference.Repository, reference.Reference)
+}
+
+// OCIRegistryClient pushes and pulls policy bundles by the OCI distribution API.
+// The credentials are taken from the KS_REGISTRY_USERNAME/KS_REGISTRY_PASSWORD environment variables, otherwise from the docker config file
+type OCIRegistryClient struct {
+	httpClient *http.Client
+	plainHTTP  bool   // the registries of localhost are accessed over plain HTTP, same as docker
+	token      string // bearer token of the registry
+}
+
+func NewOCIRegistryClient() *OCIRegistryClient {
+	return &OCIRegistryClient{httpClient: &http.Client{}}
+}
+
+// Push pushes the files as a policy bundle, a layer per file. Returns the digest of the manifest of the bundle
+func (client *OCIRegistryClient) Push(ref string, filePaths []string) (string, error) {
+	reference, err := ParseOCIReference(ref)
+	if err != nil {
+		return "", err
+	}
+	manifest := ocispec.Manifest{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: ocispec.MediaTypeImageManifest,
+		Layers:    []ocispec.Descriptor{},
+	}
+	config := []byte("{}")
+	manifest.Config = ocispec.Descriptor{MediaType: PolicyBundleConfigMediaType, Digest: digest.FromBytes(config), Size: int64(len(config))}
+	if err := client.pushBlob(reference, config, manifest.Config.Digest); err != nil {
+		return "", err
+	}
+
+	titles := map[string]bool{}
+	for _, filePath := range filePaths {
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			return "", err
+		}
+		if !json.Valid(data) {
+			return "", fmt.Errorf("'%s' is not a JSON file, expected the artifacts of 'kubescape download'", filePath)
+		}
+		title := filepath.Base(filePath)
+		if titles[title] {
+			return "", fmt.Errorf("the file name '%s' is pushed twice, the artifacts of a bundle are named by their file names", title)
+		}
+		titles[title] = true
+		layer := ocispec.Descriptor{
+			MediaType:   PolicyBundleLayerMediaType,
+			Digest:      digest.FromBytes(data),
+			Size:        int64(len(data)),
+			Annotations: map[string]string{ocispec.AnnotationTitle: title},
+		}
+		if err := client.pushBlob(reference, data, layer.Digest); err != nil {
+			return "", err
+		}
+		manifest.Layers = append(manifest.Layers, layer)
+	}
+
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return "", err
+	}
+	resp, err := client.do(reference, http.MethodPut, client.url(reference, "manifests/"+reference.Reference), data, map[string]string{"Content-Type": ocispec.MediaTypeImageManifest})
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return "", registryError(resp, "failed to push the manifest")
+	}
+	return digest.FromBytes(data).String(), nil
+}
+
+// Pull pulls the artifacts of a policy bundle into the directory, named by their titles. Returns the paths of the pulled files
+func (client *OCIRegistryClient) Pull(ref, dir string) ([]string, error) {
+	reference, err := ParseOCIReference(ref)
+	if err != nil {
+		return nil, err
+	}
+	data, err := client.get(reference, "manifests/"+reference.Reference, ocispec.MediaTypeImageManifest)
+	if err != nil {
+		return nil, err
+	}
+	if expected, err := digest.Parse(reference.Reference); err == nil && expected != digest.FromBytes(data) {
+		return nil, fmt.Errorf("the digest of the manifest of '%s' does not match", ref)
+	}
+	manifest := ocispec.Manifest{}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to decode the manifest of '%s': %w", ref, err)
+	}
+	if manifest.Config.MediaType != PolicyBundleConfigMediaType {
+		return nil, fmt.Errorf("'%s' is not a kubescape policy bundle, config media type '%s'", ref, manifest.Config.MediaType)
+	}
+
+	filePaths := []string{}
+	for _, layer := range manifest.Layers {
+		title := layer.Annotations[ocispec.AnnotationTitle]
+		if title == "" || title != filepath.Base(title) || title == ".." {
+			return nil, fmt.Errorf("invalid title '%s' of layer '%s'", title, layer.Digest)
+		}
+		data, err := client.get(reference, "blobs/"+layer.Digest.String(), "")
+		if err != nil {
+			return nil, err
+		}
+		if layer.Digest.Validate() != nil || layer.Digest != digest.FromBytes(data) {
+			return nil, fmt.Errorf("the digest of layer '%s' does not match", title)
+		}
+		filePath := filepath.Join(dir, title)
+		if err := os.WriteFile(filePath, data, 0644); err != nil {
+			return nil, err
+		}
+		filePaths = append(filePaths, filePath)
+	}
+	return filePaths, nil
+}
+
+func (client *OCIRegistryClient) pushBlob(reference *OCIReference, data []byte, blobDigest digest.Digest) error {
+	resp, err := client.do(reference, http.MethodHead, client.url(reference, "blobs/"+blobDigest.String()), nil, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil // already pushed
+	}
+
+	resp, err = client.do(reference, http.MethodPost, client.url(reference, "blobs/uploads/"), nil, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		return registryError(resp, "failed to start the upload of a blob")
+	}
+	location, err := resp.Request.URL.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		return fmt.Errorf("invalid upload location: %w", err)
+	}
+	q := location.Query()
+	q.Set("digest", blobDigest.String())
+	location.RawQuery = q.Encode()
+
+	resp, err = client.do(reference, http.MethodPut, location.String(), data, map[string]string{"Content-Type": "application/octet-stream"})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return registryError(resp, "failed to upload a blob")
+	}
+	return nil
+}
+
+func (client *OCIRegistryClient) get(reference *OCIReference, path, accept string) ([]byte, error) {
+	header := map[string]string{}
+	if accept != "" {
+		header["Accept"] = accept
+	}
+	resp, err := client.do(reference, http.MethodGet, client.url(reference, path), nil, header)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, registryError(resp, fmt.Sprintf("failed to get '%s' of '%s'", path, reference.String()))
+	}
+	return io.ReadAll(resp.Body)
+}
+
+func (client *OCIRegistryClient) url(reference *OCIReference, path string) string {
+	scheme := "https"
+	if client.plainHTTP || isLocalhost(reference.Registry) {
+		scheme = "http"
+	}
+	return fmt.Sprintf("%s://%s/v2/%s/%s", scheme, reference.Registry, reference.Repository, path)
+}
+
+// do sends the request, authenticating to the registry when challenged - by a bearer token or basic authentication
+func (client *OCIRegistryClient) do(reference *OCIReference, method, u string, body []byte, header map[string]string) (*http.Response, error) {
+	send := func(authorization string) (*http.Response, error) {
+		req, err := http.NewRequest(method, u, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		return client.httpClient.Do(req)
+	}
+	authorization := ""
+	if client.token != "" {
+		authorization = "Bearer " + client.token
+	}
+	resp, err := send(authorization)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	resp.Body.Close()
+
+	username, password := registryCredentials(reference.Registry)
+	challenge := resp.Header.Get("WWW-Authenticate")
+	switch {
+	case strings.HasPrefix(strings.ToLower(challenge), "bearer "):
+		token, err := client.fetchToken(challenge, username, password)
+		if err != nil {
+			return nil, err
+		}
+		client.token = token
+		return send("Bearer " + token)
+	case username != "":
+		return send("Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password)))
+	}
+	return nil, fmt.Errorf("unauthorized to access '%s', set the KS_REGISTRY_USERNAME/KS_REGISTRY_PASSWORD environment variables or 'docker login'", reference.String())
+}
+
+var challengeParamRegexp = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+func (client *OCIRegistryClient) fetchToken(challenge, username, password string) (string, error) {
+	params := map[string]string{}
+	for _, match := range challengeParamRegexp.FindAllStringSubmatch(challenge, -1) {
+		params[strings.ToLower(match[1])] = match[2]
+	}
+	realm, err := url.Parse(params["realm"])
+	if err != nil || params["realm"] == "" {
+		return "", fmt.Errorf("invalid authentication challenge of the registry '%s'", challenge)
+	}
+	q := realm.Query()
+	for _, param := range []string{"service", "scope"} {
+		if params[param] != "" {
+			q.Set(param, params[param])
+		}
+	}
+	realm.RawQuery = q.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	if username != "" {
+		req.SetBasicAuth(username, password)
+	}
+	resp, err := client.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", registryError(resp, "failed to authenticate to the registry")
+	}
+	token := struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", err
+	}
+	if token.Token == "" {
+		token.Token = token.AccessToken
+	}
+	return token.Token, nil
+}
+
+// registryCredentials returns the credentials of the registry - the KS_REGISTRY_USERNAME/KS_REGISTRY_PASSWORD environment variables,
+// otherwise the 'auths' of the docker config file (the credentials helpers are not supported)
+func registryCredentials(registry string) (string, string) {
+	if username := os.Getenv("KS_REGISTRY_USERNAME"); username != "" {
+		return username, os.Getenv("KS_REGISTRY_PASSWORD")
+	}
+	configPath := filepath.Join(os.Getenv("DOCKER_CONFIG"), "config.json")
+	if os.Getenv("DOCKER_CONFIG") == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", ""
+		}
+		configPath = filepath.Join(home, ".docker", "config.json")
+	}
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return "", ""
+	}
+	config := struct {
+		Auths map[string]struct {
+			Auth string `json:"auth"`
+		} `json:"auths"`
+	}{}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return "", ""
+	}
+	for host, auth := range config.Auths {
+		if strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(host, "https://"), "http://"), "/") != registry {
+			continue
+		}
+		decoded, err := base64.StdEncoding.DecodeString(auth.Auth)
+		if err != nil {
+			return "", ""
+		}
+		if i := strings.Index(string(decoded), ":"); i != -1 {
+			return string(decoded[:i]), string(decoded[i+1:])
+		}
+	}
+	return "", ""
+}
+
+func isLocalhost(registry string) bool {
+	host := registry
+	if i := strings.LastIndex(registry, ":"); i != -1 {
+		host = registry[:i]
+	}
+	return host == "localhost" || host == "127.0.0.1"
+}
+
+func registryError(resp *http.Response, message string) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("%s, status: %s, %s", message, resp.Status, strings.TrimSpace(string(body)))
+}
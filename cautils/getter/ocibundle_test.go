@@ -0,0 +1,189 @@
+package getter
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+)
+
+func TestParseOCIReference(t *testing.T) {
+	tests := map[string]OCIReference{
+		"oci://registry.internal/policies/nsa:v1":    {Registry: "registry.internal", Repository: "policies/nsa", Reference: "v1"},
+		"oci://localhost:5000/policies/nsa":          {Registry: "localhost:5000", Repository: "policies/nsa", Reference: "latest"},
+		"oci://registry.internal/nsa@" + emptyDigest: {Registry: "registry.internal", Repository: "nsa", Reference: emptyDigest},
+	}
+	for ref, expected := range tests {
+		reference, err := ParseOCIReference(ref)
+		if err != nil {
+			t.Errorf("%s: %v", ref, err)
+			continue
+		}
+		if *reference != expected {
+			t.Errorf("%s: expected %+v, received %+v", ref, expected, *reference)
+		}
+		if reference.String() != ref && !strings.HasSuffix(ref, "/nsa") {
+			t.Errorf("expected '%s', received '%s'", ref, reference.String())
+		}
+	}
+	for _, ref := range []string{"oci://registry.internal", "oci://registry.internal/", "oci://registry.internal/Policies:v1", "oci://registry.internal/nsa@sha256:123"} {
+		if _, err := ParseOCIReference(ref); err == nil {
+			t.Errorf("expected an error for '%s'", ref)
+		}
+	}
+}
+
+var emptyDigest = digest.FromBytes(nil).String()
+
+// registryMock an in-memory OCI registry, authenticating by a bearer token
+type registryMock struct {
+	sync.Mutex
+	blobs     map[string][]byte
+	manifests map[string][]byte
+	server    *httptest.Server
+}
+
+func newRegistryMock() *registryMock {
+	registry := &registryMock{blobs: map[string][]byte{}, manifests: map[string][]byte{}}
+	registry.server = httptest.NewServer(http.HandlerFunc(registry.serveHTTP))
+	return registry
+}
+
+func (registry *registryMock) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	registry.Lock()
+	defer registry.Unlock()
+	if r.URL.Path == "/token" {
+		if username, password, ok := r.BasicAuth(); !ok || username != "user" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{"token": "t0ken"}`)
+		return
+	}
+	if r.Header.Get("Authorization") != "Bearer t0ken" {
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="registry",scope="repository:policies/nsa:pull,push"`, registry.server.URL))
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/v2/policies/nsa/")
+	body, _ := io.ReadAll(r.Body)
+	switch {
+	case r.Method == http.MethodPost && path == "blobs/uploads/":
+		w.Header().Set("Location", "/v2/policies/nsa/blobs/uploads/1?state=abc")
+		w.WriteHeader(http.StatusAccepted)
+	case r.Method == http.MethodPut && strings.HasPrefix(path, "blobs/uploads/"):
+		if r.URL.Query().Get("state") != "abc" || digest.FromBytes(body).String() != r.URL.Query().Get("digest") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		registry.blobs[r.URL.Query().Get("digest")] = body
+		w.WriteHeader(http.StatusCreated)
+	case strings.HasPrefix(path, "blobs/"):
+		data, ok := registry.blobs[strings.TrimPrefix(path, "blobs/")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(data)
+	case r.Method == http.MethodPut && strings.HasPrefix(path, "manifests/"):
+		registry.manifests[strings.TrimPrefix(path, "manifests/")] = body
+		registry.manifests[digest.FromBytes(body).String()] = body
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == http.MethodGet && strings.HasPrefix(path, "manifests/"):
+		data, ok := registry.manifests[strings.TrimPrefix(path, "manifests/")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(data)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestPushPullPolicyBundle(t *testing.T) {
+	registry := newRegistryMock()
+	defer registry.server.Close()
+	t.Setenv("KS_REGISTRY_USERNAME", "user")
+	t.Setenv("KS_REGISTRY_PASSWORD", "secret")
+	ref := fmt.Sprintf("oci://%s/policies/nsa:v1", strings.TrimPrefix(registry.server.URL, "http://"))
+
+	dir := t.TempDir()
+	artifacts := map[string]string{"nsa.json": `{"name": "nsa", "controls": []}`, "controls-inputs.json": `{"imageRepositoryAllowList": []}`}
+	filePaths := []string{}
+	for name, content := range artifacts {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		filePaths = append(filePaths, filepath.Join(dir, name))
+	}
+	manifestDigest, err := NewOCIRegistryClient().Push(ref, filePaths)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// by tag and by digest
+	for _, pullRef := range []string{ref, strings.TrimSuffix(ref, ":v1") + "@" + manifestDigest} {
+		pullDir := t.TempDir()
+		pulled, err := NewOCIRegistryClient().Pull(pullRef, pullDir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(pulled) != len(artifacts) {
+			t.Fatalf("expected %d artifacts, received %v", len(artifacts), pulled)
+		}
+		for _, filePath := range pulled {
+			data, err := os.ReadFile(filePath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != artifacts[filepath.Base(filePath)] {
+				t.Errorf("%s: unexpected content '%s'", filePath, data)
+			}
+		}
+	}
+
+	// a tampered layer fails the pull
+	for blobDigest, data := range registry.blobs {
+		if string(data) == artifacts["nsa.json"] {
+			registry.blobs[blobDigest] = []byte(`{"name": "nsa", "controls": [{}]}`)
+		}
+	}
+	if _, err := NewOCIRegistryClient().Pull(ref, t.TempDir()); err == nil || !strings.Contains(err.Error(), "digest") {
+		t.Errorf("expected a digest error, received %v", err)
+	}
+
+	t.Setenv("KS_REGISTRY_PASSWORD", "wrong")
+	if _, err := NewOCIRegistryClient().Pull(ref, t.TempDir()); err == nil {
+		t.Error("expected an authentication error")
+	}
+}
+
+func TestPushBlobError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"errors": [{"code": "DENIED"}]}`)
+	}))
+	defer server.Close()
+
+	filePath := filepath.Join(t.TempDir(), "nsa.json")
+	if err := os.WriteFile(filePath, []byte(`{"name": "nsa"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ref := fmt.Sprintf("oci://%s/policies/nsa:v1", strings.TrimPrefix(server.URL, "http://"))
+	if _, err := NewOCIRegistryClient().Push(ref, []string{filePath}); err == nil || !strings.Contains(err.Error(), "DENIED") {
+		t.Errorf("expected the registry error body, received %v", err)
+	}
+}
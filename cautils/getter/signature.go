@@ -0,0 +1,160 @@
+package getter
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// ReleasePublicKey the pinned public key of the released policies, set at build time (see build.py).
+// A minisign public key, or a cosign/PEM public key (PEM or the base64 of the DER)
+var ReleasePublicKey = ""
+
+const (
+	cosignSignatureSuffix   = ".sig"
+	minisignSignatureSuffix = ".minisig"
+)
+
+// SignatureVerifier verifies the detached signatures of the downloaded artifacts against a public key.
+// Supports the signatures of 'cosign sign-blob' (<artifact>.sig) and of minisign (<artifact>.minisig)
+type SignatureVerifier struct {
+	publicKey       crypto.PublicKey // cosign key
+	minisignKeyID   []byte
+	minisignKey     ed25519.PublicKey
+	isMinisign      bool
+	signatureSuffix string
+}
+
+// NewSignatureVerifier parses the public key, see ReleasePublicKey
+func NewSignatureVerifier(publicKey string) (*SignatureVerifier, error) {
+	publicKey = strings.TrimSpace(publicKey)
+	if publicKey == "" {
+		return nil, fmt.Errorf("empty public key")
+	}
+	if block, _ := pem.Decode([]byte(publicKey)); block != nil {
+		return newCosignVerifier(block.Bytes)
+	}
+
+	// minisign public key file - an untrusted comment followed by the key
+	lines := strings.Split(publicKey, "\n")
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[len(lines)-1]))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode the public key: %w", err)
+	}
+	if len(key) == 2+8+ed25519.PublicKeySize && string(key[:2]) == "Ed" {
+		return &SignatureVerifier{
+			isMinisign:      true,
+			minisignKeyID:   key[2:10],
+			minisignKey:     ed25519.PublicKey(key[10:]),
+			signatureSuffix: minisignSignatureSuffix,
+		}, nil
+	}
+	return newCosignVerifier(key)
+}
+
+// LoadSignatureVerifier loads the public key from a file
+func LoadSignatureVerifier(filePath string) (*SignatureVerifier, error) {
+	publicKey, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return NewSignatureVerifier(string(publicKey))
+}
+
+func newCosignVerifier(der []byte) (*SignatureVerifier, error) {
+	publicKey, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the public key: %w", err)
+	}
+	switch publicKey.(type) {
+	case *ecdsa.PublicKey, *rsa.PublicKey, ed25519.PublicKey:
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", publicKey)
+	}
+	return &SignatureVerifier{publicKey: publicKey, signatureSuffix: cosignSignatureSuffix}, nil
+}
+
+// SignatureName returns the name of the detached signature of an artifact
+func (verifier *SignatureVerifier) SignatureName(artifact string) string {
+	return artifact + verifier.signatureSuffix
+}
+
+// Verify verifies the detached signature of the artifact
+func (verifier *SignatureVerifier) Verify(artifact, signature []byte) error {
+	if verifier.isMinisign {
+		return verifier.verifyMinisign(artifact, signature)
+	}
+	return verifier.verifyCosign(artifact, signature)
+}
+
+// verifyCosign verifies a 'cosign sign-blob' signature - the base64 of the signature of the SHA256 digest of the artifact
+func (verifier *SignatureVerifier) verifyCosign(artifact, signature []byte) error {
+	if decoded, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(signature))); err == nil {
+		signature = decoded
+	}
+	digest := sha256.Sum256(artifact)
+	switch publicKey := verifier.publicKey.(type) {
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(publicKey, digest[:], signature) {
+			return fmt.Errorf("invalid signature")
+		}
+	case *rsa.PublicKey:
+		if err := rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, digest[:], signature); err != nil {
+			return fmt.Errorf("invalid signature")
+		}
+	case ed25519.PublicKey:
+		if !ed25519.Verify(publicKey, artifact, signature) {
+			return fmt.Errorf("invalid signature")
+		}
+	}
+	return nil
+}
+
+// verifyMinisign verifies a minisign signature file - the signature of the artifact (or of its BLAKE2b-512 digest, "ED")
+// followed by the global signature of the signature and the trusted comment
+func (verifier *SignatureVerifier) verifyMinisign(artifact, signature []byte) error {
+	lines := strings.Split(strings.TrimSpace(string(signature)), "\n")
+	if len(lines) < 4 || !strings.HasPrefix(lines[2], "trusted comment: ") {
+		return fmt.Errorf("invalid minisign signature file")
+	}
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[1]))
+	if err != nil || len(sig) != 2+8+ed25519.SignatureSize {
+		return fmt.Errorf("invalid minisign signature")
+	}
+	globalSig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[3]))
+	if err != nil || len(globalSig) != ed25519.SignatureSize {
+		return fmt.Errorf("invalid minisign global signature")
+	}
+	if !bytes.Equal(sig[2:10], verifier.minisignKeyID) {
+		return fmt.Errorf("the artifact is signed by another key")
+	}
+
+	message := artifact
+	switch string(sig[:2]) {
+	case "Ed":
+	case "ED":
+		digest := blake2b.Sum512(artifact)
+		message = digest[:]
+	default:
+		return fmt.Errorf("unsupported minisign signature algorithm '%s'", sig[:2])
+	}
+	if !ed25519.Verify(verifier.minisignKey, message, sig[10:]) {
+		return fmt.Errorf("invalid signature")
+	}
+	trustedComment := strings.TrimSuffix(strings.TrimPrefix(lines[2], "trusted comment: "), "\r")
+	if !ed25519.Verify(verifier.minisignKey, append(append([]byte{}, sig[10:]...), trustedComment...), globalSig) {
+		return fmt.Errorf("invalid signature of the trusted comment")
+	}
+	return nil
+}
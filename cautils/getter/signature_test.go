@@ -0,0 +1,181 @@
+package getter
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/armosec/opa-utils/gitregostore"
+	"golang.org/x/crypto/blake2b"
+)
+
+// minisignKey a minisign key pair, the public key in the format of the minisign public key file
+type minisignKey struct {
+	id         []byte
+	privateKey ed25519.PrivateKey
+	publicKey  string
+}
+
+func newMinisignKey(t *testing.T) *minisignKey {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := []byte("01234567")
+	key := append(append([]byte("Ed"), id...), publicKey...)
+	return &minisignKey{id: id, privateKey: privateKey, publicKey: "untrusted comment: minisign public key\n" + base64.StdEncoding.EncodeToString(key) + "\n"}
+}
+
+func (key *minisignKey) sign(artifact []byte, prehashed bool) []byte {
+	algorithm, message := "Ed", artifact
+	if prehashed {
+		digest := blake2b.Sum512(artifact)
+		algorithm, message = "ED", digest[:]
+	}
+	signature := ed25519.Sign(key.privateKey, message)
+	trustedComment := "timestamp:1700000000\tfile:frameworks"
+	globalSignature := ed25519.Sign(key.privateKey, append(append([]byte{}, signature...), trustedComment...))
+	return []byte(fmt.Sprintf("untrusted comment: signature from minisign secret key\n%s\ntrusted comment: %s\n%s\n",
+		base64.StdEncoding.EncodeToString(append(append([]byte(algorithm), key.id...), signature...)), trustedComment, base64.StdEncoding.EncodeToString(globalSignature)))
+}
+
+func TestMinisignVerifier(t *testing.T) {
+	key := newMinisignKey(t)
+	verifier, err := NewSignatureVerifier(key.publicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name := verifier.SignatureName("frameworks"); name != "frameworks.minisig" {
+		t.Errorf("unexpected signature name '%s'", name)
+	}
+	artifact := []byte(`[{"name": "nsa"}]`)
+	for _, prehashed := range []bool{false, true} {
+		signature := key.sign(artifact, prehashed)
+		if err := verifier.Verify(artifact, signature); err != nil {
+			t.Errorf("prehashed %v: %v", prehashed, err)
+		}
+		if err := verifier.Verify([]byte(`[{"name": "tampered"}]`), signature); err == nil {
+			t.Errorf("prehashed %v: expected an error for a tampered artifact", prehashed)
+		}
+	}
+
+	// signed by another key
+	if err := verifier.Verify(artifact, newMinisignKey(t).sign(artifact, true)); err == nil {
+		t.Error("expected an error for a signature of another key")
+	}
+	// the trusted comment is signed
+	signature := strings.Replace(string(key.sign(artifact, true)), "file:frameworks", "file:controls", 1)
+	if err := verifier.Verify(artifact, []byte(signature)); err == nil {
+		t.Error("expected an error for a tampered trusted comment")
+	}
+}
+
+func TestCosignVerifier(t *testing.T) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	artifact := []byte(`[{"name": "nsa"}]`)
+	digest := sha256.Sum256(artifact)
+	signature, err := ecdsa.SignASN1(rand.Reader, privateKey, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// PEM and the base64 of the DER (e.g. pinned by ldflags)
+	for _, publicKey := range []string{string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})), base64.StdEncoding.EncodeToString(der)} {
+		verifier, err := NewSignatureVerifier(publicKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if name := verifier.SignatureName("frameworks"); name != "frameworks.sig" {
+			t.Errorf("unexpected signature name '%s'", name)
+		}
+		if err := verifier.Verify(artifact, []byte(base64.StdEncoding.EncodeToString(signature)+"\n")); err != nil {
+			t.Error(err)
+		}
+		if err := verifier.Verify([]byte(`[{"name": "tampered"}]`), signature); err == nil {
+			t.Error("expected an error for a tampered artifact")
+		}
+	}
+
+	for _, publicKey := range []string{"", "not a key", base64.StdEncoding.EncodeToString([]byte("not a key"))} {
+		if _, err := NewSignatureVerifier(publicKey); err == nil {
+			t.Errorf("expected an error for the public key '%s'", publicKey)
+		}
+	}
+}
+
+func TestVerifiedDownloadReleasedPolicy(t *testing.T) {
+	key := newMinisignKey(t)
+	verifier, err := NewSignatureVerifier(key.publicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	artifacts := map[string][]byte{
+		"frameworks":            []byte(`[{"name": "nsa", "controlsNames": ["Privileged container"]}]`),
+		"controls":              []byte(`[{"name": "Privileged container", "controlID": "C-0057"}]`),
+		"rules":                 []byte(`[]`),
+		"FWName_CID_CName":      []byte("frameworkName,ControlID,ControlName\nnsa,C-0057,Privileged container\n"),
+		"ControlID_RuleName":    []byte("ControlID,RuleName\n"),
+		"default_config_inputs": []byte(`{"name": "default", "settings": {"postureControlInputs": {"imageRepositoryAllowList": []}}}`),
+	}
+	tampered := ""
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		if artifact, ok := artifacts[strings.TrimSuffix(name, ".minisig")]; ok {
+			if strings.HasSuffix(name, ".minisig") {
+				w.Write(key.sign(artifact, true))
+			} else if name == tampered {
+				w.Write(append(artifact, ' '))
+			} else {
+				w.Write(artifact)
+			}
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+	newPolicy := func() *DownloadReleasedPolicy {
+		return &DownloadReleasedPolicy{gs: gitregostore.NewGitRegoStore(server.URL, "armosec", "regolibrary", "releases", "latest/download", "", -1), verifier: verifier}
+	}
+
+	drp := newPolicy()
+	if err := drp.SetRegoObjects(); err != nil {
+		t.Fatal(err)
+	}
+	framework, err := drp.GetFramework("nsa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(framework.Controls) != 1 || framework.Controls[0].ControlID != "C-0057" {
+		t.Errorf("unexpected controls of the framework: %+v", framework.Controls)
+	}
+	if inputs, err := drp.GetControlsInputs(""); err != nil || inputs == nil {
+		t.Errorf("unexpected controls inputs: %v, %v", inputs, err)
+	}
+
+	// fails closed - nothing is set if an artifact is not verified
+	tampered = "controls"
+	drp = newPolicy()
+	if err := drp.SetRegoObjects(); err == nil || !strings.Contains(err.Error(), "controls") {
+		t.Errorf("expected a verification error of the controls, received %v", err)
+	}
+	if frameworks, _ := drp.ListFrameworks(); len(frameworks) != 0 {
+		t.Errorf("expected no frameworks, received %v", frameworks)
+	}
+}
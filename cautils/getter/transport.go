@@ -0,0 +1,44 @@
+package getter
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+)
+
+// SetTLSOptions sets the TLS verification of the outbound HTTPS requests - downloading the policies, submitting the reports,
+// uploading the results, the notifications... The requests are sent through the proxy of $HTTPS_PROXY/$HTTP_PROXY,
+// except to the hosts of $NO_PROXY.
+// caBundle is a PEM file of certificate authorities trusted in addition to the system ones, e.g. of a TLS intercepting proxy.
+// The requests to the Kubernetes API server are not affected, they are verified by the kubeconfig
+func SetTLSOptions(caBundle string, insecureSkipVerify bool) error {
+	if caBundle == "" && !insecureSkipVerify {
+		return nil
+	}
+	tlsConfig := &tls.Config{InsecureSkipVerify: insecureSkipVerify}
+	if caBundle != "" {
+		pem, err := ioutil.ReadFile(caBundle)
+		if err != nil {
+			return fmt.Errorf("failed to read the CA bundle: %w", err)
+		}
+		rootCAs, err := x509.SystemCertPool()
+		if err != nil || rootCAs == nil {
+			rootCAs = x509.NewCertPool()
+		}
+		if !rootCAs.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no PEM certificates found in the CA bundle '%s'", caBundle)
+		}
+		tlsConfig.RootCAs = rootCAs
+	}
+
+	// the HTTP clients of the requests use the default transport
+	transport, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return fmt.Errorf("unexpected default HTTP transport %T", http.DefaultTransport)
+	}
+	transport.Proxy = http.ProxyFromEnvironment
+	transport.TLSClientConfig = tlsConfig
+	return nil
+}
@@ -0,0 +1,44 @@
+package getter
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSetTLSOptions(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	transport := http.DefaultTransport.(*http.Transport)
+	tlsConfig := transport.TLSClientConfig
+	defer func() {
+		transport.TLSClientConfig = tlsConfig
+		transport.CloseIdleConnections()
+	}()
+
+	if _, err := (&http.Client{}).Get(server.URL); err == nil {
+		t.Fatal("expected an error of an unknown certificate authority")
+	}
+
+	caBundle := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caBundle, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetTLSOptions(caBundle, false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := (&http.Client{}).Get(server.URL); err != nil {
+		t.Errorf("expected the certificate of the CA bundle trusted: %v", err)
+	}
+
+	if err := os.WriteFile(caBundle, []byte("not a certificate"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetTLSOptions(caBundle, false); err == nil {
+		t.Error("expected an error of a CA bundle without certificates")
+	}
+}
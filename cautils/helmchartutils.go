@@ -0,0 +1,193 @@
+package cautils
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/armosec/k8s-interface/workloadinterface"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/engine"
+	"helm.sh/helm/v3/pkg/strvals"
+	"sigs.k8s.io/yaml"
+)
+
+// HelmChartOptions rendering of a local helm chart, same as 'helm template'
+type HelmChartOptions struct {
+	ChartPath    string   // Path to the chart directory or packaged chart
+	ValuesFiles  []string // Values files, the last file takes precedence
+	Values       []string // --set overrides, e.g. image.tag=1.2.3
+	StringValues []string // --set-string overrides
+	ReleaseName  string
+	Namespace    string
+}
+
+// LoadResourcesFromHelmChart renders the chart and loads the rendered resources.
+// The source of a resource is the template file it was rendered from, with the line of its 'kind' in the template when found
+func LoadResourcesFromHelmChart(options *HelmChartOptions) ([]workloadinterface.IMetadata, map[string]ResourceSource, error) {
+	helmChart, err := loader.Load(options.ChartPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load helm chart '%s': %w", options.ChartPath, err)
+	}
+	if helmChart.Metadata.Type == "library" {
+		return nil, nil, fmt.Errorf("helm chart '%s' is a library chart, library charts are not renderable", options.ChartPath)
+	}
+
+	rendered, err := renderHelmChart(helmChart, options)
+	if err != nil {
+		return nil, nil, err
+	}
+	templates := helmChartTemplates(helmChart)
+
+	// map the rendered paths to the files of the chart directory, the paths of a packaged chart are kept as is
+	basePath := ""
+	if fi, err := os.Stat(options.ChartPath); err == nil && fi.IsDir() {
+		basePath = options.ChartPath
+	}
+
+	workloads := []workloadinterface.IMetadata{}
+	sources := map[string]ResourceSource{}
+	var errs []error
+	for _, templatePath := range sortedKeys(rendered) {
+		if !isHelmManifest(templatePath) || strings.TrimSpace(rendered[templatePath]) == "" {
+			continue
+		}
+		w, _, e := readYamlDocuments([]byte(rendered[templatePath]))
+		errs = append(errs, e...)
+
+		sourcePath := templatePath
+		if basePath != "" {
+			sourcePath = filepath.Join(basePath, filepath.FromSlash(strings.TrimPrefix(templatePath, helmChart.Name()+"/")))
+		}
+		kinds := map[string]int{} // the number of rendered resources of the kind, to find the line of each in the template
+		for i := range w {
+			source := ResourceSource{Path: sourcePath}
+			source.Line = templateKindLine(templates[templatePath], w[i].GetKind(), kinds[w[i].GetKind()])
+			kinds[w[i].GetKind()]++
+			sources[w[i].GetID()] = source
+		}
+		workloads = append(workloads, w...)
+	}
+	if len(errs) > 0 {
+		return workloads, sources, fmt.Errorf("failed to parse the rendered helm chart: %v", errs)
+	}
+	return workloads, sources, nil
+}
+
+func renderHelmChart(helmChart *chart.Chart, options *HelmChartOptions) (map[string]string, error) {
+	values, err := helmChartValues(options)
+	if err != nil {
+		return nil, err
+	}
+	if err := chartutil.ProcessDependencies(helmChart, values); err != nil {
+		return nil, fmt.Errorf("failed to process the helm chart dependencies: %w", err)
+	}
+	releaseOptions := chartutil.ReleaseOptions{
+		Name:      options.ReleaseName,
+		Namespace: options.Namespace,
+		Revision:  1,
+		IsInstall: true,
+	}
+	renderValues, err := chartutil.ToRenderValues(helmChart, values, releaseOptions, chartutil.DefaultCapabilities)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute the helm chart values: %w", err)
+	}
+	rendered, err := engine.Render(helmChart, renderValues)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render helm chart: %w", err)
+	}
+	return rendered, nil
+}
+
+// helmChartValues merges the values files and the --set overrides, same precedence as helm
+func helmChartValues(options *HelmChartOptions) (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+	for _, valuesFile := range options.ValuesFiles {
+		data, err := os.ReadFile(valuesFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read values file '%s': %w", valuesFile, err)
+		}
+		current := map[string]interface{}{}
+		if err := yaml.Unmarshal(data, &current); err != nil {
+			return nil, fmt.Errorf("failed to parse values file '%s': %w", valuesFile, err)
+		}
+		values = mergeValues(values, current)
+	}
+	for _, value := range options.Values {
+		if err := strvals.ParseInto(value, values); err != nil {
+			return nil, fmt.Errorf("failed to parse --set value '%s': %w", value, err)
+		}
+	}
+	for _, value := range options.StringValues {
+		if err := strvals.ParseIntoString(value, values); err != nil {
+			return nil, fmt.Errorf("failed to parse --set-string value '%s': %w", value, err)
+		}
+	}
+	return values, nil
+}
+
+func mergeValues(a, b map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(a))
+	for k, v := range a {
+		out[k] = v
+	}
+	for k, v := range b {
+		if v, ok := v.(map[string]interface{}); ok {
+			if bv, ok := out[k].(map[string]interface{}); ok {
+				out[k] = mergeValues(bv, v)
+				continue
+			}
+		}
+		out[k] = v
+	}
+	return out
+}
+
+// helmChartTemplates returns the templates of the chart and its dependencies, by the rendered path
+func helmChartTemplates(helmChart *chart.Chart) map[string][]byte {
+	templates := map[string][]byte{}
+	for _, t := range helmChart.Templates {
+		templates[path.Join(helmChart.ChartFullPath(), t.Name)] = t.Data
+	}
+	for _, dependency := range helmChart.Dependencies() {
+		for k, v := range helmChartTemplates(dependency) {
+			templates[k] = v
+		}
+	}
+	return templates
+}
+
+// isHelmManifest returns false for the partials and the notes of the chart, which are not resources
+func isHelmManifest(templatePath string) bool {
+	base := path.Base(templatePath)
+	return !strings.HasPrefix(base, "_") && !strings.EqualFold(base, "NOTES.txt")
+}
+
+// templateKindLine returns the line of the nth 'kind: <kind>' of the template, 0 if not found
+func templateKindLine(template []byte, kind string, n int) int {
+	kindRegex := regexp.MustCompile(fmt.Sprintf(`^\s*kind:\s*["']?%s["']?\s*(#.*)?$`, regexp.QuoteMeta(kind)))
+	for i, line := range strings.Split(string(template), "\n") {
+		if kindRegex.MatchString(line) {
+			if n == 0 {
+				return i + 1
+			}
+			n--
+		}
+	}
+	return 0
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
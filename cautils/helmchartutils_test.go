@@ -0,0 +1,111 @@
+package cautils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeHelmChartMock(t *testing.T) string {
+	chartPath := t.TempDir()
+	files := map[string]string{
+		"Chart.yaml":             "apiVersion: v2\nname: web\nversion: 0.1.0\n",
+		"values.yaml":            "replicas: 1\nexposed: false\n",
+		"templates/_helpers.tpl": `{{- define "web.name" -}}{{ .Release.Name }}-web{{- end -}}`,
+		"templates/NOTES.txt":    "Installed {{ .Release.Name }}",
+		"templates/deployment.yaml": `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ include "web.name" . }}
+  namespace: {{ .Release.Namespace }}
+spec:
+  replicas: {{ .Values.replicas }}
+  template:
+    spec:
+      containers:
+      - name: web
+        image: nginx
+`,
+		"templates/service.yaml": `{{- if .Values.exposed }}
+apiVersion: v1
+kind: Service
+metadata:
+  name: public
+spec:
+  type: NodePort
+---
+{{- end }}
+apiVersion: v1
+kind: "Service"
+metadata:
+  name: internal
+`,
+	}
+	for name, content := range files {
+		p := filepath.Join(chartPath, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return chartPath
+}
+
+func TestLoadResourcesFromHelmChart(t *testing.T) {
+	chartPath := writeHelmChartMock(t)
+	valuesFile := filepath.Join(t.TempDir(), "values-prod.yaml")
+	if err := os.WriteFile(valuesFile, []byte("replicas: 3\nexposed: false\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	workloads, sources, err := LoadResourcesFromHelmChart(&HelmChartOptions{
+		ChartPath:   chartPath,
+		ValuesFiles: []string{valuesFile},
+		Values:      []string{"exposed=true"},
+		ReleaseName: "prod",
+		Namespace:   "web",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(workloads))
+
+	names := map[string]ResourceSource{}
+	for i := range workloads {
+		names[workloads[i].GetNamespace()+"/"+workloads[i].GetName()] = sources[workloads[i].GetID()]
+	}
+	assert.Equal(t, ResourceSource{Path: filepath.Join(chartPath, "templates", "deployment.yaml"), Line: 2}, names["web/prod-web"])
+	assert.Equal(t, ResourceSource{Path: filepath.Join(chartPath, "templates", "service.yaml"), Line: 3}, names["/public"])
+	assert.Equal(t, ResourceSource{Path: filepath.Join(chartPath, "templates", "service.yaml"), Line: 11}, names["/internal"])
+
+	// the chart values are overridden by the values files
+	for i := range workloads {
+		if workloads[i].GetKind() == "Deployment" {
+			assert.EqualValues(t, 3, workloads[i].GetObject()["spec"].(map[string]interface{})["replicas"])
+		}
+	}
+}
+
+func TestLoadResourcesFromHelmChartErrors(t *testing.T) {
+	_, _, err := LoadResourcesFromHelmChart(&HelmChartOptions{ChartPath: filepath.Join(t.TempDir(), "missing")})
+	assert.Error(t, err)
+
+	_, _, err = LoadResourcesFromHelmChart(&HelmChartOptions{ChartPath: writeHelmChartMock(t), Values: []string{"replicas"}})
+	assert.Error(t, err)
+}
+
+func TestMergeValues(t *testing.T) {
+	merged := mergeValues(map[string]interface{}{"image": map[string]interface{}{"repository": "nginx", "tag": "1.0"}, "replicas": 1},
+		map[string]interface{}{"image": map[string]interface{}{"tag": "2.0"}})
+	assert.Equal(t, map[string]interface{}{"image": map[string]interface{}{"repository": "nginx", "tag": "2.0"}, "replicas": 1}, merged)
+}
+
+func TestTemplateKindLine(t *testing.T) {
+	template := []byte("apiVersion: v1\nkind: Service\n---\napiVersion: v1\nkind: 'Service' # internal\n")
+	assert.Equal(t, 2, templateKindLine(template, "Service", 0))
+	assert.Equal(t, 5, templateKindLine(template, "Service", 1))
+	assert.Equal(t, 0, templateKindLine(template, "Service", 2))
+	assert.Equal(t, 0, templateKindLine(template, "Deployment", 0))
+}
@@ -0,0 +1,36 @@
+package cautils
+
+import (
+	"sort"
+
+	"github.com/armosec/k8s-interface/workloadinterface"
+)
+
+// ImagesWorkloads returns the IDs of the workloads by the images of their containers, map[<image>][]<workload ID>
+func ImagesWorkloads(allResources map[string]workloadinterface.IMetadata) map[string][]string {
+	workloads := map[string][]string{}
+	for resourceID, resource := range allResources {
+		if resource.GetObjectType() != workloadinterface.TypeWorkloadObject {
+			continue
+		}
+		workload := workloadinterface.NewWorkloadObj(resource.GetObject())
+		images := map[string]bool{}
+		if containers, err := workload.GetContainers(); err == nil {
+			for i := range containers {
+				images[containers[i].Image] = true
+			}
+		}
+		if containers, err := workload.GetInitContainers(); err == nil {
+			for i := range containers {
+				images[containers[i].Image] = true
+			}
+		}
+		for image := range images {
+			workloads[image] = append(workloads[image], resourceID)
+		}
+	}
+	for image := range workloads {
+		sort.Strings(workloads[image])
+	}
+	return workloads
+}
@@ -0,0 +1,137 @@
+package cautils
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"path"
+	"reflect"
+	"strings"
+	"time"
+)
+
+const jsonSchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	timeType          = reflect.TypeOf(time.Time{})
+)
+
+// GenerateJSONSchema returns the JSON schema (draft-07) of the JSON encoding of the value, generated from its Go type by the json tags.
+// The schema has no $id when the id is empty. The fields without omitempty are required, the named structs are definitions of the schema.
+// The types with custom marshaling are not described, they accept any value
+func GenerateJSONSchema(value interface{}, id, title string) ([]byte, error) {
+	generator := &jsonSchemaGenerator{definitions: map[string]interface{}{}, names: map[reflect.Type]string{}}
+	schema := generator.schema(reflect.TypeOf(value))
+	schema["$schema"] = jsonSchemaDraft
+	if id != "" {
+		schema["$id"] = id
+	}
+	schema["title"] = title
+	schema["definitions"] = generator.definitions
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+type jsonSchemaGenerator struct {
+	definitions map[string]interface{}
+	names       map[reflect.Type]string // the names of the definitions of the structs
+}
+
+func (generator *jsonSchemaGenerator) schema(t reflect.Type) map[string]interface{} {
+	if t.Kind() == reflect.Ptr {
+		return generator.schema(t.Elem())
+	}
+	switch {
+	case t == timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case t.Implements(jsonMarshalerType) || reflect.PtrTo(t).Implements(jsonMarshalerType):
+		return map[string]interface{}{}
+	case t.Implements(textMarshalerType) || reflect.PtrTo(t).Implements(textMarshalerType):
+		return map[string]interface{}{"type": "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string", "contentEncoding": "base64"}
+		}
+		return map[string]interface{}{"type": []string{"array", "null"}, "items": generator.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": []string{"object", "null"}, "additionalProperties": generator.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return generator.structSchema(t)
+		}
+		name, ok := generator.names[t]
+		if !ok {
+			name = path.Base(t.PkgPath()) + "." + t.Name()
+			for i := 2; generator.definitions[name] != nil; i++ { // the same name in packages of the same base name
+				name = fmt.Sprintf("%s.%s%d", path.Base(t.PkgPath()), t.Name(), i)
+			}
+			generator.names[t] = name
+			generator.definitions[name] = map[string]interface{}{} // recursive types
+			generator.definitions[name] = generator.structSchema(t)
+		}
+		return map[string]interface{}{"$ref": "#/definitions/" + name}
+	default: // interface
+		return map[string]interface{}{}
+	}
+}
+
+func (generator *jsonSchemaGenerator) structSchema(t reflect.Type) map[string]interface{} {
+	properties := map[string]interface{}{}
+	required := []string{}
+	generator.addFields(t, properties, &required)
+	schema := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// addFields adds the fields of the struct to the properties, the fields of the embedded structs are added as fields of the struct
+func (generator *jsonSchemaGenerator) addFields(t reflect.Type, properties map[string]interface{}, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options := tag, ""
+		if i := strings.Index(tag, ","); i >= 0 {
+			name, options = tag[:i], tag[i:]
+		}
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+			generator.addFields(fieldType, properties, required)
+			continue
+		}
+		if field.PkgPath != "" { // unexported
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		schema := generator.schema(field.Type)
+		if field.Type.Kind() == reflect.Ptr && len(schema) > 0 {
+			schema = map[string]interface{}{"anyOf": []interface{}{schema, map[string]interface{}{"type": "null"}}}
+		}
+		_, shadowed := properties[name]
+		properties[name] = schema
+		if !shadowed && !strings.Contains(options, ",omitempty") {
+			*required = append(*required, name)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package cautils
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type schemaNode struct {
+	Name     string            `json:"name"`
+	Created  time.Time         `json:"created"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Children []schemaNode      `json:"children"`
+	Parent   *schemaNode       `json:"parent,omitempty"`
+	Ignored  string            `json:"-"`
+	schemaEmbedded
+}
+
+type schemaEmbedded struct {
+	Score float32 `json:"score"`
+}
+
+func TestGenerateJSONSchema(t *testing.T) {
+	data, err := GenerateJSONSchema(schemaNode{}, "https://example.com/node.json", "node")
+	assert.NoError(t, err)
+
+	schema := map[string]interface{}{}
+	assert.NoError(t, json.Unmarshal(data, &schema))
+	assert.Equal(t, "#/definitions/cautils.schemaNode", schema["$ref"])
+	assert.Equal(t, "https://example.com/node.json", schema["$id"])
+
+	node := schema["definitions"].(map[string]interface{})["cautils.schemaNode"].(map[string]interface{})
+	assert.ElementsMatch(t, []interface{}{"name", "created", "children", "score"}, node["required"])
+	properties := node["properties"].(map[string]interface{})
+	assert.Len(t, properties, 6)
+	assert.Equal(t, map[string]interface{}{"type": "string", "format": "date-time"}, properties["created"])
+	assert.Equal(t, map[string]interface{}{"type": "number"}, properties["score"])
+	assert.Equal(t, map[string]interface{}{"$ref": "#/definitions/cautils.schemaNode"}, properties["children"].(map[string]interface{})["items"])
+}
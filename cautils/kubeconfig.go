@@ -0,0 +1,62 @@
+package cautils
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/armosec/k8s-interface/k8sinterface"
+	"github.com/armosec/kubescape/cautils/logger"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// SetKubeconfig selects the cluster of the scan, without changing the current context of the kubeconfig.
+// kubeconfig is the kubeconfig file, default $KUBECONFIG or ~/.kube/config. The context defaults to the current context,
+// and the cluster to the cluster of the context (the credentials of the context are used with the cluster).
+// When none is set, the cluster of the service account is scanned when running in-cluster, and the current context otherwise
+func SetKubeconfig(kubeconfig, kubeContext, cluster string) error {
+	k8sinterface.SetClusterContextName(kubeContext)
+	if kubeconfig == "" && kubeContext == "" && cluster == "" {
+		if _, err := rest.InClusterConfig(); err == nil && os.Getenv(clientcmd.RecommendedConfigPathEnvVar) == "" {
+			logger.L().Debug("running in-cluster, scanning the cluster of the service account")
+		}
+		return nil
+	}
+
+	if kubeconfig != "" {
+		if _, err := os.Stat(kubeconfig); err != nil {
+			return fmt.Errorf("bad argument: kubeconfig: %w", err)
+		}
+		// the cluster name and the default namespace are loaded from $KUBECONFIG as well
+		os.Setenv(clientcmd.RecommendedConfigPathEnvVar, kubeconfig)
+	}
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: kubeContext}
+	if cluster != "" {
+		rawConfig, err := loadingRules.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load the kubeconfig: %w", err)
+		}
+		if _, ok := rawConfig.Clusters[cluster]; !ok {
+			clusters := make([]string, 0, len(rawConfig.Clusters))
+			for name := range rawConfig.Clusters {
+				clusters = append(clusters, name)
+			}
+			sort.Strings(clusters)
+			return fmt.Errorf("bad argument: cluster '%s' not found in the kubeconfig. Clusters: %s", cluster, strings.Join(clusters, "/"))
+		}
+		overrides.Context = clientcmdapi.Context{Cluster: cluster}
+	}
+
+	// loaded from the kubeconfig even when running in-cluster
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, overrides).ClientConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load the kubeconfig: %w", err)
+	}
+	k8sinterface.K8SConfig = config
+	k8sinterface.RunningIncluster = false
+	return nil
+}
@@ -0,0 +1,60 @@
+package cautils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/armosec/k8s-interface/k8sinterface"
+	"github.com/stretchr/testify/assert"
+)
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: dev
+  cluster:
+    server: https://dev.example.com
+- name: prod
+  cluster:
+    server: https://prod.example.com
+users:
+- name: admin
+  user:
+    token: token
+contexts:
+- name: dev
+  context:
+    cluster: dev
+    user: admin
+- name: prod
+  context:
+    cluster: prod
+    user: admin
+current-context: dev
+`
+
+func TestSetKubeconfig(t *testing.T) {
+	kubeconfig := filepath.Join(t.TempDir(), "kubeconfig")
+	if err := os.WriteFile(kubeconfig, []byte(testKubeconfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("KUBECONFIG", "")
+	defer func() {
+		k8sinterface.K8SConfig = nil
+		k8sinterface.SetClusterContextName("")
+	}()
+
+	assert.NoError(t, SetKubeconfig(kubeconfig, "", ""))
+	assert.Equal(t, "https://dev.example.com", k8sinterface.K8SConfig.Host)
+
+	assert.NoError(t, SetKubeconfig(kubeconfig, "prod", ""))
+	assert.Equal(t, "https://prod.example.com", k8sinterface.K8SConfig.Host)
+
+	assert.NoError(t, SetKubeconfig(kubeconfig, "", "prod"))
+	assert.Equal(t, "https://prod.example.com", k8sinterface.K8SConfig.Host)
+	assert.Equal(t, "token", k8sinterface.K8SConfig.BearerToken)
+
+	assert.EqualError(t, SetKubeconfig(kubeconfig, "", "staging"), "bad argument: cluster 'staging' not found in the kubeconfig. Clusters: dev/prod")
+	assert.Error(t, SetKubeconfig(filepath.Join(t.TempDir(), "missing"), "", ""))
+}
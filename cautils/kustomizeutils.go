@@ -0,0 +1,125 @@
+package cautils
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/armosec/k8s-interface/workloadinterface"
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/yaml"
+)
+
+// kustomizeOriginAnnotation the source file of a resource, annotated by kustomize when the 'originAnnotations' build metadata is set
+const kustomizeOriginAnnotation = "config.kubernetes.io/origin"
+
+// LoadResourcesFromKustomize builds the kustomization directory, same as 'kustomize build'.
+// The source of a resource is the base/overlay file it was loaded from, with the line of the resource in the file when found
+func LoadResourcesFromKustomize(kustomizeDirectory string) ([]workloadinterface.IMetadata, map[string]ResourceSource, error) {
+	root, err := filepath.Abs(kustomizeDirectory)
+	if err != nil {
+		return nil, nil, err
+	}
+	fSys := &originAnnotationsFileSystem{FileSystem: filesys.MakeFsOnDisk(), root: root}
+	resMap, err := krusty.MakeKustomizer(krusty.MakeDefaultOptions()).Run(fSys, root)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to build kustomization '%s': %w", kustomizeDirectory, err)
+	}
+	manifests, err := resMap.AsYaml()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to build kustomization '%s': %w", kustomizeDirectory, err)
+	}
+	workloads, _, errs := readYamlDocuments(manifests)
+	if len(errs) > 0 {
+		return nil, nil, fmt.Errorf("failed to parse the kustomization '%s' resources: %v", kustomizeDirectory, errs)
+	}
+
+	sources := map[string]ResourceSource{}
+	for i := range workloads {
+		originPath := popKustomizeOrigin(workloads[i])
+		if originPath == "" {
+			continue // generated resources, e.g. ConfigMaps of a configMapGenerator
+		}
+		sourcePath := filepath.Join(root, filepath.FromSlash(originPath))
+		sources[workloads[i].GetID()] = ResourceSource{Path: sourcePath, Line: resourceLine(sourcePath, workloads[i])}
+	}
+	return workloads, sources, nil
+}
+
+// originAnnotationsFileSystem sets the 'originAnnotations' build metadata of the root kustomization, the build metadata is inherited by the bases
+type originAnnotationsFileSystem struct {
+	filesys.FileSystem
+	root string
+}
+
+func (fSys *originAnnotationsFileSystem) ReadFile(path string) ([]byte, error) {
+	data, err := fSys.FileSystem.ReadFile(path)
+	if err != nil || !fSys.isRootKustomization(path) {
+		return data, err
+	}
+	kustomization := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &kustomization); err != nil {
+		return data, nil // let kustomize report the error
+	}
+	buildMetadata, _ := kustomization["buildMetadata"].([]interface{})
+	kustomization["buildMetadata"] = append(buildMetadata, "originAnnotations")
+	return yaml.Marshal(kustomization)
+}
+
+func (fSys *originAnnotationsFileSystem) isRootKustomization(path string) bool {
+	abs, err := filepath.Abs(path)
+	if err != nil || filepath.Dir(abs) != fSys.root {
+		return false
+	}
+	return StringInSlice(konfig.RecognizedKustomizationFileNames(), filepath.Base(abs)) != ValueNotFound
+}
+
+// popKustomizeOrigin returns the origin path of the resource and removes the annotation, which is not part of the scanned manifest
+func popKustomizeOrigin(resource workloadinterface.IMetadata) string {
+	metadata, ok := resource.GetObject()["metadata"].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	annotations, ok := metadata["annotations"].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	origin, _ := annotations[kustomizeOriginAnnotation].(string)
+	delete(annotations, kustomizeOriginAnnotation)
+	if len(annotations) == 0 {
+		delete(metadata, "annotations")
+	}
+
+	o := struct {
+		Path string `json:"path"`
+	}{}
+	if err := yaml.Unmarshal([]byte(origin), &o); err != nil {
+		return ""
+	}
+	return o.Path
+}
+
+// resourceLine returns the first line of the resource in the source file, 0 if not found.
+// The name may be transformed by kustomize (e.g. namePrefix), so the resource is matched by kind and a name contained in the built name
+func resourceLine(sourcePath string, resource workloadinterface.IMetadata) int {
+	data, err := loadFile(sourcePath)
+	if err != nil {
+		return 0
+	}
+	workloads, lines, _ := readYamlDocuments(data)
+	line := 0
+	for i := range workloads {
+		if i >= len(lines) || workloads[i].GetKind() != resource.GetKind() {
+			continue
+		}
+		if workloads[i].GetName() == resource.GetName() {
+			return lines[i]
+		}
+		if line == 0 && strings.Contains(resource.GetName(), workloads[i].GetName()) {
+			line = lines[i]
+		}
+	}
+	return line
+}
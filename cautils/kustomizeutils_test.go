@@ -0,0 +1,94 @@
+package cautils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeKustomizationMock(t *testing.T) string {
+	root := t.TempDir()
+	files := map[string]string{
+		"base/kustomization.yaml": "resources:\n- app.yaml\n",
+		"base/app.yaml": `apiVersion: v1
+kind: Service
+metadata:
+  name: web
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  annotations:
+    team: payments
+spec:
+  template:
+    spec:
+      containers:
+      - name: web
+        image: nginx
+`,
+		"overlays/prod/kustomization.yaml": `namePrefix: prod-
+namespace: prod
+resources:
+- ../../base
+- netpol.yaml
+configMapGenerator:
+- name: config
+  literals:
+  - key=value
+`,
+		"overlays/prod/netpol.yaml": "apiVersion: networking.k8s.io/v1\nkind: NetworkPolicy\nmetadata:\n  name: deny\nspec:\n  podSelector: {}\n",
+	}
+	for name, content := range files {
+		p := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestLoadResourcesFromKustomize(t *testing.T) {
+	root := writeKustomizationMock(t)
+	overlay := filepath.Join(root, "overlays", "prod")
+
+	workloads, sources, err := LoadResourcesFromKustomize(overlay)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, len(workloads))
+
+	resources := map[string]ResourceSource{}
+	for i := range workloads {
+		assert.Equal(t, "prod", workloads[i].GetNamespace())
+		resources[workloads[i].GetKind()+"/"+workloads[i].GetName()] = sources[workloads[i].GetID()]
+		if workloads[i].GetKind() == "Deployment" {
+			// the origin annotation is removed, the other annotations are kept
+			assert.Equal(t, map[string]interface{}{"team": "payments"}, workloads[i].GetObject()["metadata"].(map[string]interface{})["annotations"])
+		}
+	}
+	assert.Equal(t, ResourceSource{Path: filepath.Join(root, "base", "app.yaml"), Line: 1}, resources["Service/prod-web"])
+	assert.Equal(t, ResourceSource{Path: filepath.Join(root, "base", "app.yaml"), Line: 6}, resources["Deployment/prod-web"])
+	assert.Equal(t, ResourceSource{Path: filepath.Join(overlay, "netpol.yaml"), Line: 1}, resources["NetworkPolicy/prod-deny"])
+
+	// the generated resources have no source file
+	for resource, source := range resources {
+		if resource != "Service/prod-web" && resource != "Deployment/prod-web" && resource != "NetworkPolicy/prod-deny" {
+			assert.Equal(t, ResourceSource{}, source)
+		}
+	}
+
+	// the kustomization file is not modified
+	data, err := os.ReadFile(filepath.Join(overlay, "kustomization.yaml"))
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "originAnnotations")
+}
+
+func TestLoadResourcesFromKustomizeErrors(t *testing.T) {
+	_, _, err := LoadResourcesFromKustomize(t.TempDir())
+	assert.Error(t, err)
+}
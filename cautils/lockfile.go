@@ -0,0 +1,137 @@
+package cautils
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/armosec/opa-utils/reporthandling"
+	"sigs.k8s.io/yaml"
+)
+
+// LockFileName the default name of the lockfile
+const LockFileName = "kubescape.lock"
+
+// LockFile the exact policies of a scan - the version of the released policies and a digest per control of the frameworks,
+// so the following scans fail instead of silently floating to other controls
+type LockFile struct {
+	PolicyVersion string            `json:"policyVersion,omitempty"` // the tag of the policies release, empty when the policies are not the released policies
+	Frameworks    []LockedFramework `json:"frameworks"`
+}
+
+// LockedFramework a framework of the lockfile
+type LockedFramework struct {
+	Name     string          `json:"name"`
+	Controls []LockedControl `json:"controls"`
+}
+
+// LockedControl a control of the lockfile, the digest covers the metadata of the control and its rules
+type LockedControl struct {
+	ControlID string `json:"controlID"`
+	Name      string `json:"name"`
+	Digest    string `json:"digest"`
+}
+
+// NewLockFile returns the lockfile of the frameworks, sorted by name and control ID
+func NewLockFile(policyVersion string, frameworks []reporthandling.Framework) (*LockFile, error) {
+	lockFile := &LockFile{PolicyVersion: policyVersion, Frameworks: []LockedFramework{}}
+	for i := range frameworks {
+		lockedFramework := LockedFramework{Name: frameworks[i].Name, Controls: []LockedControl{}}
+		for j := range frameworks[i].Controls {
+			digest, err := controlDigest(&frameworks[i].Controls[j])
+			if err != nil {
+				return nil, err
+			}
+			lockedFramework.Controls = append(lockedFramework.Controls, LockedControl{ControlID: frameworks[i].Controls[j].ControlID, Name: frameworks[i].Controls[j].Name, Digest: digest})
+		}
+		sort.Slice(lockedFramework.Controls, func(a, b int) bool {
+			return lockedFramework.Controls[a].ControlID < lockedFramework.Controls[b].ControlID
+		})
+		lockFile.Frameworks = append(lockFile.Frameworks, lockedFramework)
+	}
+	sort.Slice(lockFile.Frameworks, func(a, b int) bool { return lockFile.Frameworks[a].Name < lockFile.Frameworks[b].Name })
+	return lockFile, nil
+}
+
+func controlDigest(control *reporthandling.Control) (string, error) {
+	data, err := json.Marshal(control)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(data)), nil
+}
+
+// LoadLockFile loads a lockfile
+func LoadLockFile(filePath string) (*LockFile, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	lockFile := &LockFile{}
+	if err := yaml.Unmarshal(data, lockFile); err != nil {
+		return nil, fmt.Errorf("failed to parse lockfile '%s': %w", filePath, err)
+	}
+	return lockFile, nil
+}
+
+// Save saves the lockfile in YAML format
+func (lockFile *LockFile) Save(filePath string) error {
+	data, err := yaml.Marshal(lockFile)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filePath, data, 0644)
+}
+
+// Verify returns an error if the frameworks differ from the locked frameworks. The lockfile may lock frameworks which are not scanned
+func (lockFile *LockFile) Verify(policyVersion string, frameworks []reporthandling.Framework) error {
+	if lockFile.PolicyVersion != "" && policyVersion != "" && lockFile.PolicyVersion != policyVersion {
+		return fmt.Errorf("the lockfile locks the policies '%s', the policies '%s' are scanned", lockFile.PolicyVersion, policyVersion)
+	}
+	current, err := NewLockFile(policyVersion, frameworks)
+	if err != nil {
+		return err
+	}
+	locked := map[string]*LockedFramework{}
+	for i := range lockFile.Frameworks {
+		locked[lockFile.Frameworks[i].Name] = &lockFile.Frameworks[i]
+	}
+
+	diffs := []string{}
+	for i := range current.Frameworks {
+		lockedFramework, ok := locked[current.Frameworks[i].Name]
+		if !ok {
+			diffs = append(diffs, fmt.Sprintf("framework '%s' is not locked", current.Frameworks[i].Name))
+			continue
+		}
+		lockedControls := map[string]string{}
+		for _, control := range lockedFramework.Controls {
+			lockedControls[control.ControlID] = control.Digest
+		}
+		for _, control := range current.Frameworks[i].Controls {
+			digest, ok := lockedControls[control.ControlID]
+			switch {
+			case !ok:
+				diffs = append(diffs, fmt.Sprintf("control '%s' was added to framework '%s'", control.ControlID, lockedFramework.Name))
+			case digest != control.Digest:
+				diffs = append(diffs, fmt.Sprintf("control '%s' of framework '%s' changed", control.ControlID, lockedFramework.Name))
+			}
+			delete(lockedControls, control.ControlID)
+		}
+		removed := make([]string, 0, len(lockedControls))
+		for controlID := range lockedControls {
+			removed = append(removed, controlID)
+		}
+		sort.Strings(removed)
+		for _, controlID := range removed {
+			diffs = append(diffs, fmt.Sprintf("control '%s' was removed from framework '%s'", controlID, lockedFramework.Name))
+		}
+	}
+	if len(diffs) > 0 {
+		return fmt.Errorf("the policies differ from the lockfile: %s", strings.Join(diffs, ", "))
+	}
+	return nil
+}
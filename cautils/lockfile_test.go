@@ -0,0 +1,62 @@
+package cautils
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/armosec/opa-utils/reporthandling"
+)
+
+func TestLockFile(t *testing.T) {
+	newFrameworks := func() []reporthandling.Framework {
+		return []reporthandling.Framework{{Controls: []reporthandling.Control{
+			{ControlID: "C-0076", BaseScore: 7, Rules: []reporthandling.PolicyRule{{Rule: "package armo_builtins"}}},
+			{ControlID: "C-0004", BaseScore: 8},
+		}}}
+	}
+	frameworks := newFrameworks()
+	frameworks[0].Name = "nsa"
+	lockFile, err := NewLockFile("v1.0.172", frameworks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lockFile.Frameworks) != 1 || len(lockFile.Frameworks[0].Controls) != 2 || lockFile.Frameworks[0].Controls[0].ControlID != "C-0004" {
+		t.Fatalf("unexpected lockfile: %+v", lockFile)
+	}
+
+	filePath := filepath.Join(t.TempDir(), LockFileName)
+	if err := lockFile.Save(filePath); err != nil {
+		t.Fatal(err)
+	}
+	lockFile, err = LoadLockFile(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := lockFile.Verify("v1.0.172", frameworks); err != nil {
+		t.Errorf("expected the same policies, received %v", err)
+	}
+	if err := lockFile.Verify("v1.0.180", frameworks); err == nil {
+		t.Error("expected an error for another policy version")
+	}
+
+	changed := newFrameworks()
+	changed[0].Name = "nsa"
+	changed[0].Controls[0].Rules[0].Rule = "package armo_builtins\n\ndeny[msga] {}"
+	changed[0].Controls = append(changed[0].Controls[:1], reporthandling.Control{ControlID: "C-0013"})
+	err = lockFile.Verify("v1.0.172", changed)
+	if err == nil {
+		t.Fatal("expected an error for the changed policies")
+	}
+	for _, diff := range []string{"'C-0076' of framework 'nsa' changed", "'C-0013' was added", "'C-0004' was removed"} {
+		if !strings.Contains(err.Error(), diff) {
+			t.Errorf("expected '%s' in '%s'", diff, err.Error())
+		}
+	}
+
+	other := newFrameworks()
+	other[0].Name = "mitre"
+	if err := lockFile.Verify("v1.0.172", other); err == nil {
+		t.Error("expected an error for a framework which is not locked")
+	}
+}
@@ -25,6 +25,17 @@ type ILogger interface {
 	GetWriter() *os.File
 }
 
+// the formats of the logs
+const (
+	PrettyFormat = "pretty"
+	JSONFormat   = "json"
+)
+
+// SupportedFormats the formats of the logs, the JSON logs are machine-parsable, e.g. in CI or when running as an operator
+func SupportedFormats() []string {
+	return []string{PrettyFormat, JSONFormat}
+}
+
 var l ILogger
 
 func L() ILogger {
@@ -34,12 +45,14 @@ func L() ILogger {
 	return l
 }
 
+// InitializeLogger initializes the logger of the format, "zap" is an alias of the JSON format.
+// By default the logs are pretty in a terminal and JSON otherwise
 func InitializeLogger(loggerName string) {
 
 	switch strings.ToLower(loggerName) {
-	case "zap":
+	case JSONFormat, "zap":
 		l = zaplogger.NewZapLogger()
-	case "pretty":
+	case PrettyFormat:
 		l = prettylogger.NewPrettyLogger()
 	default:
 		if isatty.IsTerminal(os.Stdout.Fd()) {
@@ -1,6 +1,7 @@
 package zaplogger
 
 import (
+	"fmt"
 	"os"
 
 	"github.com/armosec/kubescape/cautils/logger/helpers"
@@ -37,14 +38,30 @@ func (zl *ZapLogger) SetWriter(w *os.File) {}
 func (zl *ZapLogger) GetWriter() *os.File  { return nil }
 func GetWriter() *os.File                  { return nil }
 
+// SetLevel sets the level of the logger by the kubescape levels, the success logs are info logs of zap
 func (zl *ZapLogger) SetLevel(level string) error {
-	l := zapcore.Level(1)
-	err := l.Set(level)
+	l, err := toZapLevel(level)
 	if err == nil {
 		zl.cfg.Level.SetLevel(l)
 	}
 	return err
 }
+
+func toZapLevel(level string) (zapcore.Level, error) {
+	switch helpers.ToLevel(level) {
+	case helpers.DebugLevel:
+		return zapcore.DebugLevel, nil
+	case helpers.InfoLevel, helpers.SuccessLevel:
+		return zapcore.InfoLevel, nil
+	case helpers.WarningLevel:
+		return zapcore.WarnLevel, nil
+	case helpers.ErrorLevel:
+		return zapcore.ErrorLevel, nil
+	case helpers.FatalLevel:
+		return zapcore.FatalLevel, nil
+	}
+	return zapcore.InfoLevel, fmt.Errorf("level '%s' unknown", level)
+}
 func (zl *ZapLogger) Fatal(msg string, details ...helpers.IDetails) {
 	zl.zapL.Fatal(msg, detailsToZapFields(details)...)
 }
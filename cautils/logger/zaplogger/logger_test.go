@@ -0,0 +1,30 @@
+package zaplogger
+
+import (
+	"testing"
+
+	"github.com/armosec/kubescape/cautils/logger/helpers"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestSetLevel(t *testing.T) {
+	zl := NewZapLogger()
+	expected := map[string]zapcore.Level{
+		"debug":   zapcore.DebugLevel,
+		"info":    zapcore.InfoLevel,
+		"success": zapcore.InfoLevel,
+		"warning": zapcore.WarnLevel,
+		"error":   zapcore.ErrorLevel,
+		"fatal":   zapcore.FatalLevel,
+	}
+	for _, level := range helpers.SupportedLevels() {
+		if err := zl.SetLevel(level); err != nil {
+			t.Errorf("level %s: %v", level, err)
+		} else if zl.cfg.Level.Level() != expected[level] {
+			t.Errorf("level %s: expected %s, received %s", level, expected[level], zl.cfg.Level.Level())
+		}
+	}
+	if err := zl.SetLevel("verbose"); err == nil {
+		t.Error("expected an error of an unknown level")
+	}
+}
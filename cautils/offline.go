@@ -0,0 +1,53 @@
+package cautils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/armosec/kubescape/cautils/getter"
+)
+
+// setOffline loads all of the artifacts - the frameworks, the controls inputs and the exceptions - from the artifacts directory
+// (the cache directory of 'kubescape download artifacts' by default). A missing artifact is an error, nothing is downloaded
+func (scanInfo *ScanInfo) setOffline() error {
+	if scanInfo.UseArtifactsFrom == "" {
+		scanInfo.UseArtifactsFrom = getter.GetDefaultPath("")
+	}
+	if _, err := os.Stat(scanInfo.UseArtifactsFrom); err != nil {
+		return fmt.Errorf("offline: missing artifacts directory '%s', %s", scanInfo.UseArtifactsFrom, scanInfo.downloadArtifactsHint())
+	}
+	// the explicit files take precedence over the artifacts
+	controlsInputs, exceptions := scanInfo.ControlsInputs, scanInfo.UseExceptions
+	scanInfo.setUseArtifactsFrom()
+	if controlsInputs != "" {
+		scanInfo.ControlsInputs = controlsInputs
+	}
+	if exceptions != "" {
+		scanInfo.UseExceptions = exceptions
+	}
+	for _, filePath := range []string{scanInfo.ControlsInputs, scanInfo.UseExceptions} {
+		if _, err := os.Stat(filePath); err != nil {
+			return fmt.Errorf("offline: missing artifact '%s', %s", filePath, scanInfo.downloadArtifactsHint())
+		}
+	}
+
+	if !scanInfo.FrameworkScan || scanInfo.ScanAll {
+		return nil
+	}
+	names, err := getter.NewLoadPolicy(scanInfo.UseFrom).ListFrameworks()
+	if err != nil {
+		return fmt.Errorf("offline: failed to list the frameworks of '%s': %w", scanInfo.UseArtifactsFrom, err)
+	}
+	for _, policy := range scanInfo.PolicyIdentifier {
+		if StringInSliceCaseInsensitive(names, policy.Name) == ValueNotFound {
+			return fmt.Errorf("offline: missing framework '%s' in '%s', download it with 'kubescape download framework %s --output %s'", policy.Name, scanInfo.UseArtifactsFrom, policy.Name, filepath.Join(scanInfo.UseArtifactsFrom, strings.ToLower(policy.Name)+".json"))
+		}
+	}
+	return nil
+}
+
+func (scanInfo *ScanInfo) downloadArtifactsHint() string {
+	return fmt.Sprintf("download the artifacts with 'kubescape download artifacts --output %s'", scanInfo.UseArtifactsFrom)
+}
@@ -0,0 +1,58 @@
+package cautils
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/armosec/opa-utils/reporthandling"
+)
+
+func TestSetOffline(t *testing.T) {
+	dir := t.TempDir()
+	newScanInfo := func(frameworks ...string) *ScanInfo {
+		scanInfo := &ScanInfo{UseArtifactsFrom: dir, FrameworkScan: true}
+		scanInfo.SetPolicyIdentifiers(frameworks, reporthandling.KindFramework)
+		return scanInfo
+	}
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := (&ScanInfo{UseArtifactsFrom: filepath.Join(dir, "missing")}).setOffline(); err == nil || !strings.Contains(err.Error(), "missing artifacts directory") {
+		t.Errorf("expected an error for a missing directory, received %v", err)
+	}
+
+	write("nsa.json", `{"name": "nsa", "controls": []}`)
+	write(localControlInputsFilename, `{}`)
+	if err := newScanInfo("nsa").setOffline(); err == nil || !strings.Contains(err.Error(), localExceptionsFilename) {
+		t.Errorf("expected an error for the missing exceptions, received %v", err)
+	}
+
+	write(localExceptionsFilename, `[]`)
+	scanInfo := newScanInfo("NSA")
+	if err := scanInfo.setOffline(); err != nil {
+		t.Fatal(err)
+	}
+	if scanInfo.ControlsInputs != filepath.Join(dir, localControlInputsFilename) || scanInfo.UseExceptions != filepath.Join(dir, localExceptionsFilename) {
+		t.Errorf("unexpected artifacts: %s, %s", scanInfo.ControlsInputs, scanInfo.UseExceptions)
+	}
+	// the other artifacts are not frameworks
+	if len(scanInfo.UseFrom) != 1 || scanInfo.UseFrom[0] != filepath.Join(dir, "nsa.json") {
+		t.Errorf("expected the framework of the artifacts, received %v", scanInfo.UseFrom)
+	}
+
+	// the explicit files take precedence
+	scanInfo = newScanInfo("nsa")
+	scanInfo.UseExceptions = filepath.Join(dir, "nsa.json")
+	if err := scanInfo.setOffline(); err != nil || scanInfo.UseExceptions != filepath.Join(dir, "nsa.json") {
+		t.Errorf("expected the explicit exceptions file, received %s, %v", scanInfo.UseExceptions, err)
+	}
+
+	if err := newScanInfo("nsa", "mitre").setOffline(); err == nil || !strings.Contains(err.Error(), "missing framework 'mitre'") {
+		t.Errorf("expected an error for the missing framework, received %v", err)
+	}
+}
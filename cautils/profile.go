@@ -0,0 +1,93 @@
+package cautils
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// The phases of a scan
+const (
+	PhasePoliciesDownload   = "policies download"
+	PhaseResourcesFetch     = "resources fetch"
+	PhaseSBOMGeneration     = "sbom generation"
+	PhaseControlsEvaluation = "controls evaluation"
+	PhaseResultsProcessing  = "results processing"
+	PhasePrinting           = "printing"
+	PhaseResultsSubmission  = "results submission"
+)
+
+// profiledControls the number of the slowest controls of the profile
+const profiledControls = 10
+
+// ScanProfile the time spent by a scan in each of its phases, in the order of the phases
+type ScanProfile struct {
+	Phases []ScanPhase
+}
+
+type ScanPhase struct {
+	Name     string
+	Duration time.Duration
+}
+
+// AddPhase adds a phase, started at start and done now
+func (profile *ScanProfile) AddPhase(name string, start time.Time) {
+	profile.Phases = append(profile.Phases, ScanPhase{Name: name, Duration: time.Since(start)})
+}
+
+// Print writes the time of the phases and of the slowest controls, map[<control ID>]<evaluation time>
+func (profile *ScanProfile) Print(w io.Writer, evaluationTime map[string]time.Duration) {
+	var total time.Duration
+	width := len("total")
+	for _, phase := range profile.Phases {
+		total += phase.Duration
+		if len(phase.Name) > width {
+			width = len(phase.Name)
+		}
+	}
+
+	fmt.Fprintln(w, "Scan profile:")
+	for _, phase := range profile.Phases {
+		fmt.Fprintf(w, "  %-*s %12s %7s\n", width, phase.Name, roundDuration(phase.Duration), percent(phase.Duration, total))
+	}
+	fmt.Fprintf(w, "  %-*s %12s\n", width, "total", roundDuration(total))
+
+	if len(evaluationTime) == 0 {
+		return
+	}
+	controlIDs := make([]string, 0, len(evaluationTime))
+	for controlID := range evaluationTime {
+		controlIDs = append(controlIDs, controlID)
+	}
+	sort.Slice(controlIDs, func(i, j int) bool {
+		if evaluationTime[controlIDs[i]] != evaluationTime[controlIDs[j]] {
+			return evaluationTime[controlIDs[i]] > evaluationTime[controlIDs[j]]
+		}
+		return controlIDs[i] < controlIDs[j]
+	})
+	if len(controlIDs) > profiledControls {
+		controlIDs = controlIDs[:profiledControls]
+	}
+	fmt.Fprintln(w, "Slowest controls:")
+	for _, controlID := range controlIDs {
+		fmt.Fprintf(w, "  %-*s %12s\n", width, controlID, roundDuration(evaluationTime[controlID]))
+	}
+}
+
+func roundDuration(d time.Duration) time.Duration {
+	switch {
+	case d >= time.Second:
+		return d.Round(10 * time.Millisecond)
+	case d >= time.Millisecond:
+		return d.Round(10 * time.Microsecond)
+	}
+	return d
+}
+
+func percent(d, total time.Duration) string {
+	if total == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", 100*float64(d)/float64(total))
+}
@@ -0,0 +1,39 @@
+package cautils
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScanProfile(t *testing.T) {
+	profile := ScanProfile{}
+	profile.AddPhase(PhasePoliciesDownload, time.Now())
+	assert.Len(t, profile.Phases, 1)
+
+	profile.Phases = []ScanPhase{
+		{Name: PhasePoliciesDownload, Duration: time.Second},
+		{Name: PhaseResourcesFetch, Duration: 3 * time.Second},
+	}
+	evaluationTime := map[string]time.Duration{}
+	for i := 0; i < 12; i++ {
+		evaluationTime[fmt.Sprintf("C-%04d", i)] = time.Duration(i) * time.Millisecond
+	}
+	out := bytes.Buffer{}
+	profile.Print(&out, evaluationTime)
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Equal(t, "Scan profile:", lines[0])
+	assert.Equal(t, []string{"policies", "download", "1s", "25.0%"}, strings.Fields(lines[1]))
+	assert.Equal(t, []string{"resources", "fetch", "3s", "75.0%"}, strings.Fields(lines[2]))
+	assert.Equal(t, []string{"total", "4s"}, strings.Fields(lines[3]))
+	assert.Equal(t, "Slowest controls:", lines[4])
+	if assert.Len(t, lines, 15) { // the 10 slowest controls
+		assert.Equal(t, []string{"C-0011", "11ms"}, strings.Fields(lines[5]))
+		assert.Equal(t, []string{"C-0002", "2ms"}, strings.Fields(lines[14]))
+	}
+}
@@ -0,0 +1,164 @@
+package cautils
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+const (
+	RedactSecrets     = "secrets"     // the data of the Secrets
+	RedactEnv         = "env"         // the values of the environment variables of the containers
+	RedactAnnotations = "annotations" // the values of the annotations, of all of the annotations unless selected
+
+	// RedactedValue replaces the redacted values
+	RedactedValue = "<redacted>"
+
+	lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+)
+
+var RedactSupported = []string{RedactSecrets, RedactEnv, RedactAnnotations}
+
+// RedactOptions the sensitive data redacted from the resources embedded in the reports - the v2 json, the html and the submitted reports.
+// The controls are evaluated on the resources before the redaction
+type RedactOptions struct {
+	Redact           []string // secrets/env/annotations
+	Annotations      []string // keys of the redacted annotations, supports globs. All of the annotations when none
+	OmitRawResources bool     // embed the references of the resources only - the apiVersion, the kind, the name and the namespace
+}
+
+func (options *RedactOptions) Validate() error {
+	for _, redact := range options.Redact {
+		if StringInSliceCaseInsensitive(RedactSupported, redact) == ValueNotFound {
+			return fmt.Errorf("bad argument: unsupported redaction '%s'. Supported: %s", redact, strings.Join(RedactSupported, "/"))
+		}
+	}
+	for _, annotation := range options.Annotations {
+		if _, err := path.Match(annotation, ""); err != nil {
+			return fmt.Errorf("bad argument: invalid annotation pattern '%s': %w", annotation, err)
+		}
+	}
+	if len(options.Annotations) > 0 && !options.redacts(RedactAnnotations) {
+		return fmt.Errorf("bad argument: '--redact-annotations' is used with '--redact annotations'")
+	}
+	return nil
+}
+
+func (options *RedactOptions) redacts(redact string) bool {
+	return StringInSliceCaseInsensitive(options.Redact, redact) != ValueNotFound
+}
+
+// RedactObject returns a copy of the object without the sensitive data, the object itself when nothing is redacted
+func (options *RedactOptions) RedactObject(object map[string]interface{}) map[string]interface{} {
+	if options != nil && options.OmitRawResources && object != nil {
+		return referenceObject(object)
+	}
+	if options == nil || len(options.Redact) == 0 || object == nil {
+		return object
+	}
+	redacted, _ := deepCopyValue(object).(map[string]interface{})
+
+	if options.redacts(RedactSecrets) && redacted["kind"] == "Secret" {
+		for _, field := range []string{"data", "stringData"} {
+			if data, ok := redacted[field].(map[string]interface{}); ok {
+				for key := range data {
+					data[key] = RedactedValue
+				}
+			}
+		}
+		// the annotation of kubectl apply holds the data of the Secret
+		if metadata, ok := redacted["metadata"].(map[string]interface{}); ok {
+			if annotations, ok := metadata["annotations"].(map[string]interface{}); ok {
+				delete(annotations, lastAppliedAnnotation)
+			}
+		}
+	}
+	options.redactValue(redacted)
+	return redacted
+}
+
+// redactValue redacts the environment variables and the annotations, of the pod templates as well
+func (options *RedactOptions) redactValue(value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			switch {
+			case key == "env" && options.redacts(RedactEnv):
+				if env, ok := field.([]interface{}); ok {
+					for i := range env {
+						if variable, ok := env[i].(map[string]interface{}); ok {
+							if _, ok := variable["value"]; ok {
+								variable["value"] = RedactedValue
+							}
+						}
+					}
+				}
+			case key == "annotations" && options.redacts(RedactAnnotations):
+				if annotations, ok := field.(map[string]interface{}); ok {
+					for annotation := range annotations {
+						if options.redactsAnnotation(annotation) {
+							annotations[annotation] = RedactedValue
+						}
+					}
+				}
+			default:
+				options.redactValue(field)
+			}
+		}
+	case []interface{}:
+		for i := range v {
+			options.redactValue(v[i])
+		}
+	}
+}
+
+func (options *RedactOptions) redactsAnnotation(annotation string) bool {
+	if len(options.Annotations) == 0 {
+		return true
+	}
+	for _, pattern := range options.Annotations {
+		if matched, _ := path.Match(pattern, annotation); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// referenceObject returns the reference of the object - the apiVersion, the kind, the name and the namespace
+func referenceObject(object map[string]interface{}) map[string]interface{} {
+	reference := map[string]interface{}{}
+	for _, field := range []string{"apiVersion", "kind"} {
+		if value, ok := object[field]; ok {
+			reference[field] = value
+		}
+	}
+	if metadata, ok := object["metadata"].(map[string]interface{}); ok {
+		referenceMetadata := map[string]interface{}{}
+		for _, field := range []string{"name", "namespace"} {
+			if value, ok := metadata[field]; ok {
+				referenceMetadata[field] = value
+			}
+		}
+		reference["metadata"] = referenceMetadata
+	}
+	return reference
+}
+
+func deepCopyValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		c := make(map[string]interface{}, len(v))
+		for key := range v {
+			c[key] = deepCopyValue(v[key])
+		}
+		return c
+	case []interface{}:
+		c := make([]interface{}, len(v))
+		for i := range v {
+			c[i] = deepCopyValue(v[i])
+		}
+		return c
+	default:
+		return value
+	}
+}
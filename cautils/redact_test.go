@@ -0,0 +1,40 @@
+package cautils
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactObject(t *testing.T) {
+	secret := map[string]interface{}{}
+	json.Unmarshal([]byte(`{"apiVersion": "v1", "kind": "Secret", "metadata": {"name": "db", "annotations": {"kubectl.kubernetes.io/last-applied-configuration": "{\"data\": {\"password\": \"cGFzcw==\"}}", "owner": "team-a"}}, "data": {"password": "cGFzcw=="}}`), &secret)
+	deployment := map[string]interface{}{}
+	json.Unmarshal([]byte(`{"apiVersion": "apps/v1", "kind": "Deployment", "metadata": {"name": "app", "annotations": {"owner": "team-a", "vault.io/token": "token"}}, "spec": {"template": {"spec": {"containers": [{"name": "app", "env": [{"name": "TOKEN", "value": "token"}, {"name": "FROM_SECRET", "valueFrom": {"secretKeyRef": {"name": "db", "key": "password"}}}]}]}}}}`), &deployment)
+
+	options := &RedactOptions{Redact: []string{RedactSecrets, RedactEnv}}
+	assert.NoError(t, options.Validate())
+
+	redacted := options.RedactObject(secret)
+	assert.Equal(t, RedactedValue, redacted["data"].(map[string]interface{})["password"])
+	assert.Equal(t, map[string]interface{}{"owner": "team-a"}, redacted["metadata"].(map[string]interface{})["annotations"])
+	assert.Equal(t, "cGFzcw==", secret["data"].(map[string]interface{})["password"], "expected the object unchanged")
+
+	redacted = options.RedactObject(deployment)
+	env := redacted["spec"].(map[string]interface{})["template"].(map[string]interface{})["spec"].(map[string]interface{})["containers"].([]interface{})[0].(map[string]interface{})["env"].([]interface{})
+	assert.Equal(t, RedactedValue, env[0].(map[string]interface{})["value"])
+	assert.NotContains(t, env[1].(map[string]interface{}), "value")
+	assert.Equal(t, "token", redacted["metadata"].(map[string]interface{})["annotations"].(map[string]interface{})["vault.io/token"])
+
+	options = &RedactOptions{Redact: []string{RedactAnnotations}, Annotations: []string{"vault.io/*"}}
+	assert.NoError(t, options.Validate())
+	annotations := options.RedactObject(deployment)["metadata"].(map[string]interface{})["annotations"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"owner": "team-a", "vault.io/token": RedactedValue}, annotations)
+
+	reference := (&RedactOptions{OmitRawResources: true}).RedactObject(deployment)
+	assert.Equal(t, map[string]interface{}{"apiVersion": "apps/v1", "kind": "Deployment", "metadata": map[string]interface{}{"name": "app"}}, reference)
+
+	assert.Error(t, (&RedactOptions{Redact: []string{"configmaps"}}).Validate())
+	assert.Error(t, (&RedactOptions{Annotations: []string{"owner"}}).Validate())
+}
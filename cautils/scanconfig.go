@@ -0,0 +1,144 @@
+package cautils
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/armosec/kubescape/cautils/getter"
+	"sigs.k8s.io/yaml"
+)
+
+const scanConfigFileName = "config.yaml"
+
+// ScanConfigFileFullPath the default config file of the scans, ~/.kubescape/config.yaml
+func ScanConfigFileFullPath() string { return getter.GetDefaultPath(scanConfigFileName) }
+
+// ScanDefaults the defaults of the flags of the scans. The flags set in the command line take precedence
+type ScanDefaults struct {
+	Frameworks          []string `json:"frameworks,omitempty"`          // scanned by 'kubescape scan' without a framework, instead of all of the frameworks
+	Format              string   `json:"format,omitempty"`              // --format
+	Output              string   `json:"output,omitempty"`              // --output
+	IncludeNamespaces   []string `json:"includeNamespaces,omitempty"`   // --include-namespaces
+	ExcludeNamespaces   []string `json:"excludeNamespaces,omitempty"`   // --exclude-namespaces
+	FailThreshold       *float32 `json:"failThreshold,omitempty"`       // --fail-threshold
+	SeverityThreshold   string   `json:"severityThreshold,omitempty"`   // --severity-threshold
+	ComplianceThreshold string   `json:"complianceThreshold,omitempty"` // --compliance-threshold
+	FailOn              string   `json:"failOn,omitempty"`              // --fail-on
+	Exceptions          string   `json:"exceptions,omitempty"`          // --exceptions
+	ControlsConfig      string   `json:"controlsConfig,omitempty"`      // --controls-config
+}
+
+// ScanConfig the config file of the scans - the defaults of all of the scans, and the named profiles overriding them.
+// e.g.
+//
+//	frameworks: [nsa, mitre]
+//	excludeNamespaces: [kube-system, kube-public]
+//	profiles:
+//	  ci:
+//	    format: junit
+//	    output: results.xml
+//	    severityThreshold: high
+type ScanConfig struct {
+	ScanDefaults
+	DefaultProfile string                  `json:"defaultProfile,omitempty"` // the profile of the scans without a selected profile
+	Profiles       map[string]ScanDefaults `json:"profiles,omitempty"`
+}
+
+// LoadScanConfig loads the YAML/JSON config file of the scans. The unknown fields are rejected, catching misspelled fields
+func LoadScanConfig(filePath string) (*ScanConfig, error) {
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	config := &ScanConfig{}
+	if err := yaml.UnmarshalStrict(data, config); err != nil {
+		return nil, fmt.Errorf("failed to parse config file '%s': %w", filePath, err)
+	}
+	if config.DefaultProfile != "" {
+		if _, ok := config.Profiles[config.DefaultProfile]; !ok {
+			return nil, fmt.Errorf("invalid config file '%s': default profile '%s' not found", filePath, config.DefaultProfile)
+		}
+	}
+	return config, nil
+}
+
+// Defaults returns the defaults of the profile, merged over the defaults of all of the scans.
+// The default profile is used when the name is empty
+func (config *ScanConfig) Defaults(profile string) (*ScanDefaults, error) {
+	defaults := config.ScanDefaults
+	if profile == "" {
+		profile = config.DefaultProfile
+	}
+	if profile == "" {
+		return &defaults, nil
+	}
+	overrides, ok := config.Profiles[profile]
+	if !ok {
+		return nil, fmt.Errorf("profile '%s' not found in the config file. Profiles: %s", profile, strings.Join(config.profileNames(), "/"))
+	}
+	defaults.merge(&overrides)
+	return &defaults, nil
+}
+
+func (config *ScanConfig) profileNames() []string {
+	names := make([]string, 0, len(config.Profiles))
+	for name := range config.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// merge sets the fields set in the overrides. The namespaces of the overrides replace both of the included and the excluded namespaces
+func (defaults *ScanDefaults) merge(overrides *ScanDefaults) {
+	if len(overrides.Frameworks) > 0 {
+		defaults.Frameworks = overrides.Frameworks
+	}
+	if len(overrides.IncludeNamespaces) > 0 || len(overrides.ExcludeNamespaces) > 0 {
+		defaults.IncludeNamespaces = overrides.IncludeNamespaces
+		defaults.ExcludeNamespaces = overrides.ExcludeNamespaces
+	}
+	if overrides.FailThreshold != nil {
+		defaults.FailThreshold = overrides.FailThreshold
+	}
+	for _, field := range []struct{ value, override *string }{
+		{&defaults.Format, &overrides.Format},
+		{&defaults.Output, &overrides.Output},
+		{&defaults.SeverityThreshold, &overrides.SeverityThreshold},
+		{&defaults.ComplianceThreshold, &overrides.ComplianceThreshold},
+		{&defaults.FailOn, &overrides.FailOn},
+		{&defaults.Exceptions, &overrides.Exceptions},
+		{&defaults.ControlsConfig, &overrides.ControlsConfig},
+	} {
+		if *field.override != "" {
+			*field.value = *field.override
+		}
+	}
+}
+
+// Flags returns the values of the flags of the scan set by the defaults, by the names of the flags
+func (defaults *ScanDefaults) Flags() map[string]string {
+	flags := map[string]string{
+		"format":               defaults.Format,
+		"output":               defaults.Output,
+		"include-namespaces":   strings.Join(defaults.IncludeNamespaces, ","),
+		"exclude-namespaces":   strings.Join(defaults.ExcludeNamespaces, ","),
+		"severity-threshold":   defaults.SeverityThreshold,
+		"compliance-threshold": defaults.ComplianceThreshold,
+		"fail-on":              defaults.FailOn,
+		"exceptions":           defaults.Exceptions,
+		"controls-config":      defaults.ControlsConfig,
+	}
+	if defaults.FailThreshold != nil {
+		flags["fail-threshold"] = strconv.FormatFloat(float64(*defaults.FailThreshold), 'f', -1, 32)
+	}
+	for name, value := range flags {
+		if value == "" {
+			delete(flags, name)
+		}
+	}
+	return flags
+}
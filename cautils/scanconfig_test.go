@@ -0,0 +1,77 @@
+package cautils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadScanConfig(t *testing.T) {
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "config.yaml")
+	content := `
+frameworks: [nsa, mitre]
+excludeNamespaces: [kube-system, kube-public]
+failThreshold: 50
+profiles:
+  ci:
+    format: junit
+    output: results.xml
+    severityThreshold: high
+  prod:
+    frameworks: [nsa]
+    includeNamespaces: ["prod-*"]
+    failThreshold: 0
+`
+	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config, err := LoadScanConfig(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defaults, err := config.Defaults("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	flags := defaults.Flags()
+	if len(defaults.Frameworks) != 2 || flags["exclude-namespaces"] != "kube-system,kube-public" || flags["fail-threshold"] != "50" || flags["format"] != "" {
+		t.Errorf("unexpected defaults: %v %v", defaults.Frameworks, flags)
+	}
+
+	defaults, err = config.Defaults("ci")
+	if err != nil {
+		t.Fatal(err)
+	}
+	flags = defaults.Flags()
+	if len(defaults.Frameworks) != 2 || flags["format"] != "junit" || flags["severity-threshold"] != "high" || flags["exclude-namespaces"] != "kube-system,kube-public" {
+		t.Errorf("unexpected defaults of the ci profile: %v %v", defaults.Frameworks, flags)
+	}
+
+	defaults, err = config.Defaults("prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	flags = defaults.Flags()
+	if len(defaults.Frameworks) != 1 || flags["include-namespaces"] != "prod-*" || flags["exclude-namespaces"] != "" || flags["fail-threshold"] != "0" {
+		t.Errorf("unexpected defaults of the prod profile: %v %v", defaults.Frameworks, flags)
+	}
+
+	if _, err := config.Defaults("staging"); err == nil {
+		t.Error("expected an error of a missing profile")
+	}
+
+	invalid := []string{
+		"format: json\nfailTreshold: 50\n",
+		"defaultProfile: ci\n",
+	}
+	for _, content := range invalid {
+		if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadScanConfig(filePath); err == nil {
+			t.Errorf("expected an error for %s", content)
+		}
+	}
+}
@@ -6,12 +6,20 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/armosec/kubescape/cautils/getter"
 	"github.com/armosec/kubescape/cautils/logger"
 	"github.com/armosec/kubescape/cautils/logger/helpers"
 	"github.com/armosec/opa-utils/reporthandling"
+	"github.com/armosec/opa-utils/reporthandling/results/v1/reportsummary"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
 )
 
 const (
@@ -54,34 +62,442 @@ func (bpf *BoolPtrFlag) Set(val string) error {
 }
 
 type RootInfo struct {
-	Logger   string // logger level
-	CacheDir string // cached dir
+	Logger      string // logger level
+	LogFormat   string // the format of the logs, pretty or json
+	CacheDir    string // cached dir
+	CABundle    string // PEM file of the certificate authorities of the outbound HTTPS requests, trusted in addition to the system ones
+	InsecureTLS bool   // Skip the TLS verification of the outbound HTTPS requests
 }
 type ScanInfo struct {
 	Getters
 	PolicyIdentifier   []reporthandling.PolicyIdentifier
-	UseExceptions      string      // Load file with exceptions configuration
-	ControlsInputs     string      // Load file with inputs for controls
-	UseFrom            []string    // Load framework from local file (instead of download). Use when running offline
-	UseDefault         bool        // Load framework from cached file (instead of download). Use when running offline
-	UseArtifactsFrom   string      // Load artifacts from local path. Use when running offline
-	VerboseMode        bool        // Display all of the input resources and not only failed resources
-	Format             string      // Format results (table, json, junit ...)
-	Output             string      // Store results in an output file, Output file name
-	FormatVersion      string      // Output object can be differnet between versions, this is for testing and backward compatibility
-	ExcludedNamespaces string      // used for host sensor namespace
-	IncludeNamespaces  string      // DEPRECATED?
-	InputPatterns      []string    // Yaml files input patterns
-	Silent             bool        // Silent mode - Do not print progress logs
-	FailThreshold      float32     // Failure score threshold
-	Submit             bool        // Submit results to Armo BE
-	HostSensorEnabled  BoolPtrFlag // Deploy ARMO K8s host sensor to collect data from certain controls
-	HostSensorYamlPath string      // Path to hostsensor file
-	Local              bool        // Do not submit results
-	Account            string      // account ID
-	KubeContext        string      // context name
-	FrameworkScan      bool        // false if scanning control
-	ScanAll            bool        // true if scan all frameworks
+	UseExceptions      string              // Load file with exceptions configuration
+	GenerateExceptions string              // Write an exceptions file covering the failures of the scan, the baseline of the failures
+	Fix                bool                // Fix the scanned files in place by the deterministic fixes of the failed controls
+	Interactive        bool                // Browse the results in the terminal after the scan
+	Open               bool                // Serve the html report of the results in a local web server and open it in the browser after the scan
+	ControlsInputs     string              // Load file with inputs for controls
+	CustomControls     string              // Load user-authored controls (Rego rules and control metadata) from a directory, scanned alongside the built-in controls
+	ScoringConfig      string              // Load file with overrides of the controls severities and weights in the score calculation
+	OwnerLabel         string              // Label of the owners of the resources (or of their namespaces), the risk-scores are broken down by owner
+	KeepOwnedResults   bool                // Keep the results of the controlled resources instead of attributing them to their top-level controller
+	PolicyVersion      string              // The version of the released policies (the tag of the regolibrary release), the latest release by default
+	LockFile           string              // Lockfile of the scanned policies - created when missing, otherwise the scan fails if the policies differ from the lockfile
+	UseFrom            []string            // Load framework from local file (instead of download). Use when running offline
+	UseDefault         bool                // Load framework from cached file (instead of download). Use when running offline
+	UseArtifactsFrom   string              // Load artifacts from local path. Use when running offline
+	Offline            bool                // No network calls - all of the artifacts are loaded from the artifacts directory, a missing artifact fails the scan
+	VerboseMode        bool                // Display all of the input resources and not only failed resources
+	IncludePassed      bool                // Include the passed and the skipped resources of the controls in the json, html and pdf outputs
+	RedactOptions      RedactOptions       // Sensitive data redacted from the resources embedded in the reports
+	Preflight          bool                // Check the permissions of the scan on the required resources, without scanning
+	Format             string              // Format results (table, json, junit ...)
+	Output             string              // Store results in an output file, Output file name
+	FormatVersion      string              // Output object can be differnet between versions, this is for testing and backward compatibility
+	ExcludedNamespaces string              // used for host sensor namespace
+	IncludeNamespaces  string              // DEPRECATED?
+	InputPatterns      []string            // Yaml files input patterns
+	Silent             bool                // Silent mode - Do not print progress logs
+	FailThreshold      float32             // Failure score threshold
+	Submit             bool                // Submit results to Armo BE
+	SubmitUncompressed bool                // Submit the reports uncompressed, gzipped by default
+	SpoolDir           string              // Spool the reports which could not be submitted to the directory, submitted by the next runs
+	HostSensorEnabled  BoolPtrFlag         // Deploy ARMO K8s host sensor to collect data from certain controls
+	HostSensorYamlPath string              // Path to hostsensor file
+	HostSensorOptions  HostSensorOptions   // Deployment of the host sensor DaemonSet
+	Local              bool                // Do not submit results
+	Account            string              // account ID
+	KubeContext        string              // context name
+	Kubeconfig         string              // kubeconfig file, default $KUBECONFIG or ~/.kube/config
+	KubeCluster        string              // cluster name of the kubeconfig, default the cluster of the context
+	FrameworkScan      bool                // false if scanning control
+	ScanAll            bool                // true if scan all frameworks
+	ServeMetrics       string              // Address to expose the metrics on, scan periodically instead of a single scan
+	MetricsInterval    time.Duration       // Interval between scans when exposing metrics
+	Watch              bool                // Watch the cluster after the scan and re-evaluate the controls affected by the changed resources
+	FetchConcurrency   int                 // The number of the API resources listed concurrently
+	FetchQPS           float32             // The requests per second to the API server
+	FetchPageSize      int64               // The objects of a list request to the API server, the lists are paginated
+	EvalConcurrency    int                 // The number of the controls evaluated concurrently
+	ImageScan          bool                // Scan the images of the workloads for vulnerabilities by trivy
+	ImageScannerPath   string              // Path of the trivy binary
+	ImageSignatures    bool                // Verify the signatures of the images of the workloads by cosign
+	SignatureKeys      []string            // The public keys trusted for signing the images
+	TrustedIdentities  []string            // The keyless identities trusted for signing the images, <issuer>=<subject regexp>
+	AttestationTypes   []string            // The attestations required from the images, e.g. slsaprovenance
+	CosignPath         string              // Path of the cosign binary
+	SBOMFormat         string              // Generate the SBOMs of the images of the workloads in the format (spdx, cyclonedx), referenced by the report
+	SBOMDir            string              // Directory of the generated SBOMs
+	ImageMetadata      bool                // Look up the configurations of the images of the workloads in their registries
+	DockerConfig       string              // Directory of the docker config.json with the registries credentials, ~/.docker by default
+	Cached             bool                // Load the Kubernetes objects from the cache of the previous scans, listing and caching the missing ones
+	CacheMaxAge        time.Duration       // The age of the cached objects, the older objects are listed again
+	Profile            bool                // Print the time spent in the phases of the scan
+	ProfileDir         string              // Directory to write the cpu and heap pprof profiles of the scan to
+	PdfOptions         PdfOptions          // Customization of the pdf report
+	TimestampOptions   TimestampOptions    // Time zone of the timestamps of the reports
+	OutputTemplate     string              // Path to a go template file, used by the gotemplate format
+	Forward            string              // Syslog server to forward the results to as CEF events, e.g. syslog://host:514
+	Notify             []string            // Slack/Microsoft Teams webhooks to post a summary of the results to
+	NotifyReportURL    string              // Link to the full report, part of the notification
+	EmailOptions       EmailOptions        // Email the results over SMTP
+	FailOptions        FailOptions         // Conditions which fail the scan, on top of the risk-score threshold
+	Store              bool                // Persist the summary of the scan in the local results store
+	StorePath          string              // Path to the results store database, default is in the cache directory
+	Selectors          SelectorOptions     // Label/field selectors of the scanned Kubernetes resources
+	Workload           *WorkloadIdentifier // Scan a single workload and its related objects, scan the cluster when nil
+	HelmChart          *HelmChartOptions   // Scan the rendered manifests of a local helm chart
+	KustomizeDirectory string              // Scan the built manifests of a kustomization directory
+	GitOptions         GitOptions          // Authentication to the scanned remote git repositories
+	Timeouts           ScanTimeouts        // Timeouts of the scan and of its phases
+}
+
+// PdfOptions customization of the pdf report
+type PdfOptions struct {
+	Logo        string // Path to a png/jpg logo, replaces the kubescape logo
+	Title       string // Report title
+	Footer      string // Footer text of every page
+	Orientation string // Page orientation - portrait/landscape
+	PageSize    string // Page size - A3/A4/A5/Letter/Legal
+}
+
+var (
+	PdfOrientations = []string{"portrait", "landscape"}
+	PdfPageSizes    = []string{"A3", "A4", "A5", "Letter", "Legal"}
+)
+
+// Validate the pdf page options, the values are case insensitive
+func (options *PdfOptions) Validate() error {
+	if options.Orientation != "" && StringInSliceCaseInsensitive(PdfOrientations, options.Orientation) == ValueNotFound {
+		return fmt.Errorf("bad argument: unsupported pdf orientation '%s'. Supported: %s", options.Orientation, strings.Join(PdfOrientations, "/"))
+	}
+	if options.PageSize != "" && StringInSliceCaseInsensitive(PdfPageSizes, options.PageSize) == ValueNotFound {
+		return fmt.Errorf("bad argument: unsupported pdf page size '%s'. Supported: %s", options.PageSize, strings.Join(PdfPageSizes, "/"))
+	}
+	return nil
+}
+
+// TimestampOptions the time zone of the timestamps of the reports, formatted in RFC3339. UTC by default
+type TimestampOptions struct {
+	Timezone string // IANA time zone, e.g. Europe/Berlin, or Local
+	UTC      bool   // Format the timestamps in UTC
+}
+
+func (options *TimestampOptions) Validate() error {
+	_, err := options.Location()
+	return err
+}
+
+// Location returns the location the timestamps of the reports are formatted in
+func (options *TimestampOptions) Location() (*time.Location, error) {
+	if options.UTC && options.Timezone != "" {
+		return nil, fmt.Errorf("you can use `utc` or `timezone`, but not both")
+	}
+	if options.Timezone == "" {
+		return time.UTC, nil
+	}
+	location, err := time.LoadLocation(options.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("bad argument: unknown time zone '%s'", options.Timezone)
+	}
+	return location, nil
+}
+
+// SelectorOptions filter the namespaced Kubernetes resources pulled from the cluster, same syntax as kubectl
+type SelectorOptions struct {
+	LabelSelector string // e.g. app.kubernetes.io/part-of=payments
+	FieldSelector string // e.g. metadata.name=nginx
+}
+
+func (options *SelectorOptions) Validate() error {
+	if _, err := labels.Parse(options.LabelSelector); err != nil {
+		return fmt.Errorf("bad argument: invalid label selector '%s': %w", options.LabelSelector, err)
+	}
+	if _, err := fields.ParseSelector(options.FieldSelector); err != nil {
+		return fmt.Errorf("bad argument: invalid field selector '%s': %w", options.FieldSelector, err)
+	}
+	return nil
+}
+
+// WorkloadIdentifier a single workload of the cluster, e.g. deployment/nginx in the web namespace
+type WorkloadIdentifier struct {
+	Kind      string // case insensitive
+	Name      string
+	Namespace string
+}
+
+// NewWorkloadIdentifier parses a <kind>/<name> workload of the namespace
+func NewWorkloadIdentifier(workload, namespace string) (*WorkloadIdentifier, error) {
+	kindName := strings.Split(workload, "/")
+	if len(kindName) != 2 || kindName[0] == "" || kindName[1] == "" {
+		return nil, fmt.Errorf("bad argument: invalid workload '%s', expected <kind>/<name>, e.g. deployment/nginx", workload)
+	}
+	if namespace == "" {
+		namespace = "default"
+	}
+	return &WorkloadIdentifier{Kind: kindName[0], Name: kindName[1], Namespace: namespace}, nil
+}
+
+func (workload *WorkloadIdentifier) String() string {
+	return fmt.Sprintf("%s/%s in namespace '%s'", workload.Kind, workload.Name, workload.Namespace)
+}
+
+// HostSensorOptions the deployment of the host sensor DaemonSet, overriding the host sensor YAML
+type HostSensorOptions struct {
+	Namespace    string   // Namespace of the DaemonSet, created and deleted by the scan when missing
+	Image        string   // Image of the host sensor
+	Tolerations  []string // Tolerations of the pods, <key>[=<value>][:<effect>], any effect when empty
+	NodeSelector []string // Node labels of the pods, <label>=<value>
+	CPULimit     string   // CPU limit of the pods, e.g. 100m
+	MemoryLimit  string   // Memory limit of the pods, e.g. 200Mi
+	Keep         bool     // Leave the host sensor installed after the scan, reused by the next scans
+}
+
+func (options *HostSensorOptions) Validate() error {
+	if _, err := options.GetTolerations(); err != nil {
+		return err
+	}
+	if _, err := options.GetNodeSelector(); err != nil {
+		return err
+	}
+	for _, quantity := range []string{options.CPULimit, options.MemoryLimit} {
+		if _, err := parseQuantity(quantity); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetTolerations parses the tolerations, a toleration of a key without a value tolerates any value of the key
+func (options *HostSensorOptions) GetTolerations() ([]corev1.Toleration, error) {
+	tolerations := make([]corev1.Toleration, 0, len(options.Tolerations))
+	for _, s := range options.Tolerations {
+		toleration := corev1.Toleration{Operator: corev1.TolerationOpExists}
+		keyValue := s
+		if i := strings.LastIndex(s, ":"); i != -1 {
+			keyValue, toleration.Effect = s[:i], corev1.TaintEffect(s[i+1:])
+			switch toleration.Effect {
+			case corev1.TaintEffectNoSchedule, corev1.TaintEffectPreferNoSchedule, corev1.TaintEffectNoExecute:
+			default:
+				return nil, fmt.Errorf("bad argument: unsupported effect of the toleration '%s'. Supported: NoSchedule/PreferNoSchedule/NoExecute", s)
+			}
+		}
+		if i := strings.Index(keyValue, "="); i != -1 {
+			toleration.Key, toleration.Value, toleration.Operator = keyValue[:i], keyValue[i+1:], corev1.TolerationOpEqual
+		} else {
+			toleration.Key = keyValue
+		}
+		if toleration.Key == "" && toleration.Operator == corev1.TolerationOpEqual {
+			return nil, fmt.Errorf("bad argument: invalid toleration '%s', expected <key>[=<value>][:<effect>]", s)
+		}
+		tolerations = append(tolerations, toleration)
+	}
+	return tolerations, nil
+}
+
+func (options *HostSensorOptions) GetNodeSelector() (map[string]string, error) {
+	nodeSelector := map[string]string{}
+	for _, s := range options.NodeSelector {
+		labelValue := strings.SplitN(s, "=", 2)
+		if len(labelValue) != 2 || labelValue[0] == "" {
+			return nil, fmt.Errorf("bad argument: invalid node selector '%s', expected <label>=<value>", s)
+		}
+		nodeSelector[labelValue[0]] = labelValue[1]
+	}
+	return nodeSelector, nil
+}
+
+// GetLimits returns the resources limits of the pods, the limits which are not set are missing
+func (options *HostSensorOptions) GetLimits() (corev1.ResourceList, error) {
+	limits := corev1.ResourceList{}
+	for name, s := range map[corev1.ResourceName]string{corev1.ResourceCPU: options.CPULimit, corev1.ResourceMemory: options.MemoryLimit} {
+		quantity, err := parseQuantity(s)
+		if err != nil {
+			return nil, err
+		}
+		if quantity != nil {
+			limits[name] = *quantity
+		}
+	}
+	return limits, nil
+}
+
+func parseQuantity(s string) (*resource.Quantity, error) {
+	if s == "" {
+		return nil, nil
+	}
+	quantity, err := resource.ParseQuantity(s)
+	if err != nil {
+		return nil, fmt.Errorf("bad argument: invalid quantity '%s': %w", s, err)
+	}
+	return &quantity, nil
+}
+
+// GitOptions authentication to the remote git repositories, scanned by their URL
+type GitOptions struct {
+	Token      string // Token of the https:// URLs, e.g. a GitHub personal access token
+	SSHKeyPath string // Private key of the ssh:// and git@ URLs, the ssh agent is used when empty
+}
+
+// EmailOptions delivery of the results by email
+type EmailOptions struct {
+	To         []string // Recipients, the results are emailed when set
+	From       string   // Sender address
+	Subject    string   // Email subject
+	SMTPServer string   // SMTP server address - host:port
+	Username   string   // SMTP username, no authentication when empty
+	Password   string   // SMTP password
+	TLS        string   // starttls/tls/none
+}
+
+var EmailTLSModes = []string{"starttls", "tls", "none"}
+
+func (options *EmailOptions) Validate() error {
+	if len(options.To) == 0 {
+		return nil
+	}
+	if options.SMTPServer == "" || options.From == "" {
+		return fmt.Errorf("bad argument: '--smtp-server' and '--email-from' are required when using '--email-to'")
+	}
+	if StringInSliceCaseInsensitive(EmailTLSModes, options.TLS) == ValueNotFound {
+		return fmt.Errorf("bad argument: unsupported smtp tls mode '%s'. Supported: %s", options.TLS, strings.Join(EmailTLSModes, "/"))
+	}
+	return nil
+}
+
+// FailOptions conditions which fail the scan (non-zero exit code) by the failed controls and the compliance score
+type FailOptions struct {
+	SeverityThreshold   string // Fail when controls of this severity or above failed - low/medium/high/critical
+	FailOn              string // Fail when the number of failed controls is above the count, e.g. count:5
+	ComplianceThreshold string // Fail when the compliance score (100 - risk-score) of a framework is below the threshold, e.g. 80 or nsa=90,mitre=75
+}
+
+func (options *FailOptions) Validate() error {
+	if options.SeverityThreshold != "" && SeverityToInt(options.SeverityThreshold) == 0 {
+		return fmt.Errorf("bad argument: unsupported severity threshold '%s'. Supported: %s", options.SeverityThreshold, strings.Join(Severities, "/"))
+	}
+	if _, err := options.failOnCount(); err != nil {
+		return err
+	}
+	if _, _, err := options.complianceThresholds(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// failOnCount returns the permitted number of failed controls, 0 when not set
+func (options *FailOptions) failOnCount() (int, error) {
+	if options.FailOn == "" {
+		return 0, nil
+	}
+	condition := strings.SplitN(options.FailOn, ":", 2)
+	if len(condition) != 2 || condition[0] != "count" {
+		return 0, fmt.Errorf("bad argument: unsupported fail-on condition '%s'. Supported: count:<number of failed controls>", options.FailOn)
+	}
+	count, err := strconv.Atoi(condition[1])
+	if err != nil || count < 0 {
+		return 0, fmt.Errorf("bad argument: unsupported fail-on condition '%s'. Supported: count:<number of failed controls>", options.FailOn)
+	}
+	return count, nil
+}
+
+// complianceThresholds returns the default threshold (-1 when not set) and the thresholds per framework name (lower case)
+func (options *FailOptions) complianceThresholds() (float32, map[string]float32, error) {
+	defaultThreshold, thresholds := float32(-1), map[string]float32{}
+	if options.ComplianceThreshold == "" {
+		return defaultThreshold, thresholds, nil
+	}
+	for _, threshold := range strings.Split(options.ComplianceThreshold, ",") {
+		name, value := "", strings.TrimSpace(threshold)
+		if i := strings.Index(value, "="); i >= 0 {
+			name, value = strings.ToLower(strings.TrimSpace(value[:i])), strings.TrimSpace(value[i+1:])
+		}
+		score, err := strconv.ParseFloat(value, 32)
+		if err != nil || score < 0 || score > 100 || (name == "" && strings.Contains(threshold, "=")) {
+			return defaultThreshold, thresholds, fmt.Errorf("bad argument: unsupported compliance threshold '%s'. Supported: a score between 0 and 100, optionally per framework, e.g. 80 or nsa=90,mitre=75", threshold)
+		}
+		if name == "" {
+			defaultThreshold = float32(score)
+		} else {
+			thresholds[name] = float32(score)
+		}
+	}
+	return defaultThreshold, thresholds, nil
+}
+
+// Check returns an error when the results of the scan break one of the fail conditions
+func (options *FailOptions) Check(summaryDetails *reportsummary.SummaryDetails) error {
+	if err := options.checkFailedControls(summaryDetails); err != nil {
+		return err
+	}
+	return options.checkCompliance(summaryDetails)
+}
+
+// checkFailedControls returns an error when the failed controls (of the severity threshold and above) exceed the permitted count
+func (options *FailOptions) checkFailedControls(summaryDetails *reportsummary.SummaryDetails) error {
+	if options.SeverityThreshold == "" && options.FailOn == "" {
+		return nil
+	}
+	count, err := options.failOnCount()
+	if err != nil {
+		return err
+	}
+	minSeverity := SeverityToInt(options.SeverityThreshold)
+
+	failed := []string{}
+	for controlID, control := range summaryDetails.Controls {
+		if control.GetStatus().IsFailed() && SeverityToInt(ControlSeverityToString(control.GetScoreFactor())) >= minSeverity {
+			failed = append(failed, controlID)
+		}
+	}
+	if len(failed) <= count {
+		return nil
+	}
+	sort.Strings(failed)
+
+	severity := ""
+	if options.SeverityThreshold != "" {
+		severity = fmt.Sprintf(" of severity %s and above", options.SeverityThreshold)
+	}
+	return fmt.Errorf("%d controls%s failed (%s), permitted: %d", len(failed), severity, strings.Join(failed, ","), count)
+}
+
+// checkCompliance returns an error when the compliance score of a framework is below its threshold.
+// When scanning controls (no frameworks) the default threshold is compared to the overall compliance score
+func (options *FailOptions) checkCompliance(summaryDetails *reportsummary.SummaryDetails) error {
+	defaultThreshold, thresholds, err := options.complianceThresholds()
+	if err != nil {
+		return err
+	}
+
+	below := []string{}
+	scanned := map[string]bool{}
+	for _, framework := range summaryDetails.Frameworks {
+		name := strings.ToLower(framework.GetName())
+		scanned[name] = true
+		threshold, ok := thresholds[name]
+		if !ok {
+			threshold = defaultThreshold
+		}
+		if compliance := 100 - framework.GetScore(); threshold >= 0 && compliance < threshold {
+			below = append(below, fmt.Sprintf("%s %.2f%% (threshold %.2f%%)", framework.GetName(), compliance, threshold))
+		}
+	}
+	if len(summaryDetails.Frameworks) == 0 && defaultThreshold >= 0 {
+		if compliance := 100 - summaryDetails.Score; compliance < defaultThreshold {
+			below = append(below, fmt.Sprintf("%.2f%% (threshold %.2f%%)", compliance, defaultThreshold))
+		}
+	}
+	for name := range thresholds {
+		if !scanned[name] {
+			logger.L().Warning("compliance threshold of a framework which was not scanned", helpers.String("framework", name))
+		}
+	}
+
+	if len(below) == 0 {
+		return nil
+	}
+	return fmt.Errorf("compliance score is below the permitted threshold: %s", strings.Join(below, ", "))
 }
 
 type Getters struct {
@@ -92,7 +508,12 @@ type Getters struct {
 
 func (scanInfo *ScanInfo) Init() {
 	scanInfo.setUseFrom()
-	scanInfo.setOutputFile()
+	if scanInfo.Offline {
+		if err := scanInfo.setOffline(); err != nil {
+			logger.L().Fatal(err.Error())
+		}
+		return
+	}
 	scanInfo.setUseArtifactsFrom()
 }
 
@@ -112,12 +533,18 @@ func (scanInfo *ScanInfo) setUseArtifactsFrom() {
 	if err != nil {
 		logger.L().Fatal("failed to read files from directory", helpers.String("dir", scanInfo.UseArtifactsFrom), helpers.Error(err))
 	}
-	framework := &reporthandling.Framework{}
+	// e.g. the artifacts were corrupted in the transfer to a disconnected environment, or downloaded again one by one.
+	// artifacts without a manifest (e.g. copied by hand) are not verified
+	if err := getter.VerifyArtifacts(scanInfo.UseArtifactsFrom); err != nil {
+		logger.L().Fatal("the artifacts do not match their manifest", helpers.String("dir", scanInfo.UseArtifactsFrom), helpers.Error(err))
+	}
 	for _, f := range files {
 		filePath := filepath.Join(scanInfo.UseArtifactsFrom, f.Name())
 		file, err := os.ReadFile(filePath)
 		if err == nil {
-			if err := json.Unmarshal(file, framework); err == nil {
+			// the other artifacts (e.g. the controls inputs and the manifest) are JSON objects too, the frameworks are named
+			framework := &reporthandling.Framework{}
+			if err := json.Unmarshal(file, framework); err == nil && framework.Name != "" {
 				scanInfo.UseFrom = append(scanInfo.UseFrom, filepath.Join(scanInfo.UseArtifactsFrom, f.Name()))
 			}
 		}
@@ -145,29 +572,69 @@ func (scanInfo *ScanInfo) setUseFrom() {
 	}
 }
 
-func (scanInfo *ScanInfo) setOutputFile() {
+// formatsExtensions the file extension of the output file, by format. The json formats have distinct extensions,
+// so the output files of several formats do not overwrite each other
+var formatsExtensions = map[string]string{
+	"json":               ".json",
+	"junit":              ".xml",
+	"pdf":                ".pdf",
+	"sarif":              ".sarif",
+	"csv":                ".csv",
+	"xlsx":               ".xlsx",
+	"markdown":           ".md",
+	"html":               ".html",
+	"gitlab-codequality": ".codequality.json",
+	"oscal":              ".oscal.json",
+	"cef":                ".cef",
+	"ndjson":             ".ndjson",
+	"dot":                ".dot",
+}
+
+// GetFormats returns the list of output formats, several formats are separated by ","
+func (scanInfo *ScanInfo) GetFormats() []string {
+	formats := []string{}
+	for _, format := range strings.Split(scanInfo.Format, ",") {
+		if format = strings.TrimSpace(format); format != "" && StringInSlice(formats, format) == ValueNotFound {
+			formats = append(formats, format)
+		}
+	}
+	return formats
+}
+
+// GetOutputFile returns the output file of the format - the output file with the format extension.
+// The output file of a single format is kept when it has the file extension of the format, e.g. report.json of oscal
+func (scanInfo *ScanInfo) GetOutputFile(format string) string {
 	if scanInfo.Output == "" {
-		return
+		return ""
 	}
-	if scanInfo.Format == "json" {
-		if filepath.Ext(scanInfo.Output) != ".json" {
-			scanInfo.Output += ".json"
-		}
+	ext, ok := formatsExtensions[format]
+	if !ok || strings.HasSuffix(scanInfo.Output, ext) {
+		return scanInfo.Output
 	}
-	if scanInfo.Format == "junit" {
-		if filepath.Ext(scanInfo.Output) != ".xml" {
-			scanInfo.Output += ".xml"
-		}
+	if len(scanInfo.GetFormats()) == 1 && filepath.Ext(scanInfo.Output) == filepath.Ext(ext) {
+		return scanInfo.Output
 	}
-	if scanInfo.Format == "pdf" {
-		if filepath.Ext(scanInfo.Output) != ".pdf" {
-			scanInfo.Output += ".pdf"
+	return scanInfo.Output + ext
+}
+
+// ValidateOutputFiles returns an error when the output files of several formats are the same file, e.g. of the formats without a file extension
+func (scanInfo *ScanInfo) ValidateOutputFiles() error {
+	formatsByFile := map[string]string{}
+	for _, format := range scanInfo.GetFormats() {
+		outputFile := scanInfo.GetOutputFile(format)
+		if outputFile == "" {
+			continue
 		}
+		if other, ok := formatsByFile[outputFile]; ok {
+			return fmt.Errorf("bad argument: the formats '%s' and '%s' are written to the same output file '%s'", other, format, outputFile)
+		}
+		formatsByFile[outputFile] = format
 	}
+	return nil
 }
 
 func (scanInfo *ScanInfo) GetScanningEnvironment() string {
-	if len(scanInfo.InputPatterns) != 0 {
+	if len(scanInfo.InputPatterns) != 0 || scanInfo.HelmChart != nil || scanInfo.KustomizeDirectory != "" {
 		return ScanLocalFiles
 	}
 	return ScanCluster
@@ -0,0 +1,245 @@
+package cautils
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/armosec/opa-utils/reporthandling/apis"
+	"github.com/armosec/opa-utils/reporthandling/results/v1/reportsummary"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestGetFormats(t *testing.T) {
+	tests := map[string][]string{
+		"":                  {},
+		"json":              {"json"},
+		"json,pdf, junit":   {"json", "pdf", "junit"},
+		"json,,json,sarif,": {"json", "sarif"},
+	}
+	for format, expected := range tests {
+		scanInfo := ScanInfo{Format: format}
+		if formats := scanInfo.GetFormats(); !reflect.DeepEqual(formats, expected) {
+			t.Errorf("format '%s': expected %v, received %v", format, expected, formats)
+		}
+	}
+}
+
+func TestGetOutputFile(t *testing.T) {
+	scanInfo := ScanInfo{Output: "report"}
+	tests := map[string]string{
+		"json":           "report.json",
+		"junit":          "report.xml",
+		"pdf":            "report.pdf",
+		"oscal":          "report.oscal.json",
+		"pretty-printer": "report",
+	}
+	for format, expected := range tests {
+		if output := scanInfo.GetOutputFile(format); output != expected {
+			t.Errorf("format '%s': expected %s, received %s", format, expected, output)
+		}
+	}
+
+	scanInfo.Output = "report.json"
+	if output := scanInfo.GetOutputFile("json"); output != "report.json" {
+		t.Errorf("expected report.json, received %s", output)
+	}
+
+	scanInfo.Format = "oscal"
+	if output := scanInfo.GetOutputFile("oscal"); output != "report.json" {
+		t.Errorf("expected report.json, received %s", output)
+	}
+	scanInfo.Format = "json,oscal"
+	if output := scanInfo.GetOutputFile("oscal"); output != "report.json.oscal.json" {
+		t.Errorf("expected report.json.oscal.json, received %s", output)
+	}
+
+	scanInfo.Output = ""
+	if output := scanInfo.GetOutputFile("json"); output != "" {
+		t.Errorf("expected stdout, received %s", output)
+	}
+}
+
+func TestValidateOutputFiles(t *testing.T) {
+	valid := []ScanInfo{{Format: "json,pretty-printer"}, {Output: "report", Format: "json,gitlab-codequality,oscal,sarif"},
+		{Output: "report.json", Format: "json,oscal"}, {Output: "report", Format: "pretty-printer"}}
+	for i := range valid {
+		if err := valid[i].ValidateOutputFiles(); err != nil {
+			t.Errorf("unexpected error for %s %s: %v", valid[i].Output, valid[i].Format, err)
+		}
+	}
+	invalid := []ScanInfo{{Output: "report", Format: "pretty-printer,prometheus"}, {Output: "report.json", Format: "json,pretty-printer"}}
+	for i := range invalid {
+		if err := invalid[i].ValidateOutputFiles(); err == nil {
+			t.Errorf("expected an error for %s %s", invalid[i].Output, invalid[i].Format)
+		}
+	}
+}
+
+func TestFailOptionsValidate(t *testing.T) {
+	valid := []FailOptions{{}, {SeverityThreshold: "high"}, {SeverityThreshold: "Critical", FailOn: "count:0"}, {FailOn: "count:5"},
+		{ComplianceThreshold: "80"}, {ComplianceThreshold: "nsa=90, mitre=75.5"}, {ComplianceThreshold: "80,nsa=90"}}
+	for i := range valid {
+		if err := valid[i].Validate(); err != nil {
+			t.Errorf("unexpected error for %v: %v", valid[i], err)
+		}
+	}
+	invalid := []FailOptions{{SeverityThreshold: "severe"}, {FailOn: "5"}, {FailOn: "count:"}, {FailOn: "count:-1"}, {FailOn: "resources:5"},
+		{ComplianceThreshold: "101"}, {ComplianceThreshold: "nsa="}, {ComplianceThreshold: "=80"}, {ComplianceThreshold: "nsa:80"}}
+	for i := range invalid {
+		if err := invalid[i].Validate(); err == nil {
+			t.Errorf("expected an error for %v", invalid[i])
+		}
+	}
+}
+
+func TestFailOptionsCheck(t *testing.T) {
+	summaryDetails := &reportsummary.SummaryDetails{Controls: reportsummary.ControlSummaries{
+		"C-0001": {Status: apis.StatusFailed, ScoreFactor: 9},  // critical
+		"C-0002": {Status: apis.StatusFailed, ScoreFactor: 7},  // high
+		"C-0003": {Status: apis.StatusFailed, ScoreFactor: 2},  // low
+		"C-0004": {Status: apis.StatusPassed, ScoreFactor: 10}, // critical
+	}}
+	tests := []struct {
+		options FailOptions
+		fail    bool
+	}{
+		{FailOptions{}, false},
+		{FailOptions{SeverityThreshold: "low"}, true},
+		{FailOptions{SeverityThreshold: "critical"}, true},
+		{FailOptions{SeverityThreshold: "critical", FailOn: "count:1"}, false},
+		{FailOptions{SeverityThreshold: "high", FailOn: "count:1"}, true},
+		{FailOptions{FailOn: "count:3"}, false},
+		{FailOptions{FailOn: "count:2"}, true},
+	}
+	for _, test := range tests {
+		if err := test.options.Check(summaryDetails); (err != nil) != test.fail {
+			t.Errorf("%v: expected fail %v, received %v", test.options, test.fail, err)
+		}
+	}
+}
+
+func TestFailOptionsCheckCompliance(t *testing.T) {
+	summaryDetails := &reportsummary.SummaryDetails{
+		Score:      30,
+		Frameworks: []reportsummary.FrameworkSummary{{Name: "NSA", Score: 15}, {Name: "MITRE", Score: 30}},
+	}
+	tests := map[string]bool{
+		"":               false,
+		"80":             true,
+		"70":             false,
+		"nsa=85":         false,
+		"nsa=86":         true,
+		"70,mitre=75":    true,
+		"90,mitre=0":     true, // nsa is below the default threshold
+		"nsa=80,mitre=0": false,
+	}
+	for threshold, fail := range tests {
+		options := FailOptions{ComplianceThreshold: threshold}
+		if err := options.Check(summaryDetails); (err != nil) != fail {
+			t.Errorf("'%s': expected fail %v, received %v", threshold, fail, err)
+		}
+	}
+
+	// control scan, the overall score is compared
+	options := FailOptions{ComplianceThreshold: "75"}
+	if err := options.Check(&reportsummary.SummaryDetails{Score: 30}); err == nil {
+		t.Errorf("expected an error below the overall threshold")
+	}
+}
+
+func TestSelectorOptionsValidate(t *testing.T) {
+	valid := []SelectorOptions{{}, {LabelSelector: "app.kubernetes.io/part-of=payments"}, {LabelSelector: "app in (web,api),tier!=db"}, {FieldSelector: "metadata.name=nginx,metadata.namespace!=default"}}
+	for i := range valid {
+		if err := valid[i].Validate(); err != nil {
+			t.Errorf("unexpected error for %v: %v", valid[i], err)
+		}
+	}
+	invalid := []SelectorOptions{{LabelSelector: "=web"}, {LabelSelector: "app in web"}, {FieldSelector: "metadata.name"}}
+	for i := range invalid {
+		if err := invalid[i].Validate(); err == nil {
+			t.Errorf("expected an error for %v", invalid[i])
+		}
+	}
+}
+
+func TestTimestampOptions(t *testing.T) {
+	valid := []TimestampOptions{{}, {UTC: true}, {Timezone: "Local"}, {Timezone: "UTC"}}
+	for i := range valid {
+		if err := valid[i].Validate(); err != nil {
+			t.Errorf("unexpected error for %v: %v", valid[i], err)
+		}
+	}
+	invalid := []TimestampOptions{{Timezone: "Mars/Olympus"}, {Timezone: "UTC", UTC: true}}
+	for i := range invalid {
+		if err := invalid[i].Validate(); err == nil {
+			t.Errorf("expected an error for %v", invalid[i])
+		}
+	}
+
+	// the timestamps are RFC3339 in UTC by default
+	generated := time.Date(2022, 3, 1, 22, 30, 0, 0, time.FixedZone("PST", -8*3600))
+	times := ScanTimes{}
+	if timestamp := times.Format(generated); timestamp != "2022-03-02T06:30:00Z" {
+		t.Errorf("expected a UTC timestamp, received %s", timestamp)
+	}
+	times.Location = time.FixedZone("CET", 3600)
+	if timestamp := times.Format(generated); timestamp != "2022-03-02T07:30:00+01:00" {
+		t.Errorf("expected a CET timestamp, received %s", timestamp)
+	}
+	if timestamp := times.Format(time.Time{}); timestamp != "" {
+		t.Errorf("expected no timestamp for the zero time, received %s", timestamp)
+	}
+}
+
+func TestHostSensorOptions(t *testing.T) {
+	options := HostSensorOptions{
+		Tolerations:  []string{"dedicated=gpu:NoSchedule", "node.kubernetes.io/unreachable:NoExecute", "spot"},
+		NodeSelector: []string{"kubernetes.io/os=linux"},
+		MemoryLimit:  "300Mi",
+	}
+	if err := options.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	tolerations, _ := options.GetTolerations()
+	expected := []corev1.Toleration{
+		{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "gpu", Effect: corev1.TaintEffectNoSchedule},
+		{Key: "node.kubernetes.io/unreachable", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoExecute},
+		{Key: "spot", Operator: corev1.TolerationOpExists},
+	}
+	if !reflect.DeepEqual(tolerations, expected) {
+		t.Errorf("expected the tolerations %v, received %v", expected, tolerations)
+	}
+	if nodeSelector, _ := options.GetNodeSelector(); !reflect.DeepEqual(nodeSelector, map[string]string{"kubernetes.io/os": "linux"}) {
+		t.Errorf("unexpected node selector %v", nodeSelector)
+	}
+	limits, _ := options.GetLimits()
+	if _, ok := limits[corev1.ResourceCPU]; ok || limits.Memory().String() != "300Mi" {
+		t.Errorf("unexpected limits %v", limits)
+	}
+
+	invalid := []HostSensorOptions{{Tolerations: []string{"dedicated=gpu:Never"}}, {Tolerations: []string{"=gpu"}}, {NodeSelector: []string{"linux"}}, {CPULimit: "a lot"}}
+	for i := range invalid {
+		if err := invalid[i].Validate(); err == nil {
+			t.Errorf("expected an error for %v", invalid[i])
+		}
+	}
+}
+
+func TestNewWorkloadIdentifier(t *testing.T) {
+	workload, err := NewWorkloadIdentifier("deployment/nginx", "web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if workload.Kind != "deployment" || workload.Name != "nginx" || workload.Namespace != "web" {
+		t.Errorf("unexpected workload %v", workload)
+	}
+	if workload, _ = NewWorkloadIdentifier("pod/debug", ""); workload.Namespace != "default" {
+		t.Errorf("expected the default namespace, received '%s'", workload.Namespace)
+	}
+	for _, invalid := range []string{"nginx", "deployment/", "/nginx", "apps/deployment/nginx"} {
+		if _, err := NewWorkloadIdentifier(invalid, "web"); err == nil {
+			t.Errorf("expected an error for '%s'", invalid)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package cautils
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/armosec/opa-utils/reporthandling"
+	"sigs.k8s.io/yaml"
+)
+
+// ScoringConfig overrides the severity and the weight in the score calculation of controls
+type ScoringConfig struct {
+	Controls []ControlScoring `json:"controls"`
+}
+
+// ControlScoring overrides the scoring of the controls matching the control ID (a glob, e.g. "C-00*") or the control name (a regular expression).
+// The overrides are applied in order, a later override of the same control wins
+type ControlScoring struct {
+	ControlID   string  `json:"controlID,omitempty"`
+	ControlName string  `json:"controlName,omitempty"`
+	Severity    string  `json:"severity,omitempty"` // Low/Medium/High/Critical, replaces the base score of the control
+	Weight      float32 `json:"weight,omitempty"`   // multiplies the weight of the control in the frameworks and the total scores
+}
+
+// LoadScoringConfig loads a JSON/YAML scoring configuration file
+func LoadScoringConfig(filePath string) (*ScoringConfig, error) {
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	config := &ScoringConfig{}
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("failed to parse scoring configuration '%s': %w", filePath, err)
+	}
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid scoring configuration '%s': %w", filePath, err)
+	}
+	return config, nil
+}
+
+// Validate the control overrides
+func (config *ScoringConfig) Validate() error {
+	for i, control := range config.Controls {
+		if control.ControlID == "" && control.ControlName == "" {
+			return fmt.Errorf("control override %d: missing controlID/controlName", i)
+		}
+		if _, err := path.Match(control.ControlID, ""); err != nil {
+			return fmt.Errorf("control override %d: invalid controlID '%s': %w", i, control.ControlID, err)
+		}
+		if _, err := regexp.Compile(control.ControlName); err != nil {
+			return fmt.Errorf("control override %d: invalid controlName '%s': %w", i, control.ControlName, err)
+		}
+		if control.Severity == "" && control.Weight == 0 {
+			return fmt.Errorf("control override %d: missing severity/weight", i)
+		}
+		if control.Severity != "" && SeverityToBaseScore(control.Severity) == 0 {
+			return fmt.Errorf("control override %d: unknown severity '%s'. Supported: %s", i, control.Severity, strings.Join(Severities, "/"))
+		}
+		if control.Weight < 0 {
+			return fmt.Errorf("control override %d: negative weight", i)
+		}
+	}
+	return nil
+}
+
+// matches returns true if the control matches the control ID and the control name of the override
+func (control *ControlScoring) matches(controlID, controlName string) bool {
+	if control.ControlID != "" {
+		if matched, _ := path.Match(strings.ToUpper(control.ControlID), strings.ToUpper(controlID)); !matched {
+			return false
+		}
+	}
+	if control.ControlName != "" {
+		if matched, _ := regexp.MatchString(control.ControlName, controlName); !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// Severity returns the overridden severity of the control, empty if the severity is not overridden
+func (config *ScoringConfig) Severity(controlID, controlName string) string {
+	severity := ""
+	if config == nil {
+		return severity
+	}
+	for i := range config.Controls {
+		if config.Controls[i].Severity != "" && config.Controls[i].matches(controlID, controlName) {
+			severity = config.Controls[i].Severity
+		}
+	}
+	return severity
+}
+
+// Weight returns the weight of the control in the score calculation, 1 if the weight is not overridden
+func (config *ScoringConfig) Weight(controlID, controlName string) float32 {
+	var weight float32 = 1
+	if config == nil {
+		return weight
+	}
+	for i := range config.Controls {
+		if config.Controls[i].Weight != 0 && config.Controls[i].matches(controlID, controlName) {
+			weight = config.Controls[i].Weight
+		}
+	}
+	return weight
+}
+
+// ApplySeverities replaces the base score of the framework controls with overridden severities
+func (config *ScoringConfig) ApplySeverities(frameworks []reporthandling.Framework) {
+	for i := range frameworks {
+		for j := range frameworks[i].Controls {
+			control := &frameworks[i].Controls[j]
+			if severity := config.Severity(control.ControlID, control.Name); severity != "" {
+				control.BaseScore = SeverityToBaseScore(severity)
+			}
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package cautils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/armosec/opa-utils/reporthandling"
+)
+
+func TestLoadScoringConfig(t *testing.T) {
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "scoring.yaml")
+	if err := os.WriteFile(filePath, []byte("controls:\n- controlID: C-0076\n  severity: low\n- controlName: (?i)image\n  weight: 2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config, err := LoadScoringConfig(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(config.Controls) != 2 || config.Controls[0].Severity != "low" || config.Controls[1].Weight != 2 {
+		t.Errorf("unexpected scoring configuration: %+v", config)
+	}
+
+	invalid := []string{
+		`{"controls": [{"severity": "low"}]}`,
+		`{"controls": [{"controlID": "C-0076"}]}`,
+		`{"controls": [{"controlID": "C-0076", "severity": "urgent"}]}`,
+		`{"controls": [{"controlName": "image(", "weight": 2}]}`,
+		`{"controls": [{"controlID": "C-0076", "weight": -1}]}`,
+	}
+	for _, content := range invalid {
+		if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadScoringConfig(filePath); err == nil {
+			t.Errorf("expected an error for %s", content)
+		}
+	}
+}
+
+func TestScoringConfigOverrides(t *testing.T) {
+	config := &ScoringConfig{Controls: []ControlScoring{
+		{ControlID: "c-00*", Weight: 3},
+		{ControlName: "(?i)image", Weight: 2},
+		{ControlID: "C-0076", Severity: SeverityLow},
+		{ControlID: "C-0076", Severity: SeverityCritical, ControlName: "resources"},
+	}}
+	tests := []struct {
+		controlID, controlName string
+		severity               string
+		weight                 float32
+	}{
+		{controlID: "C-0076", controlName: "Label usage for resources", severity: SeverityCritical, weight: 3},
+		{controlID: "C-0076", controlName: "Label usage", severity: SeverityLow, weight: 3},
+		{controlID: "C-0078", controlName: "Images from allowed registry", weight: 2},
+		{controlID: "C-1000", controlName: "Privileged container", weight: 1},
+	}
+	for _, test := range tests {
+		if severity := config.Severity(test.controlID, test.controlName); severity != test.severity {
+			t.Errorf("%s: expected severity '%s', received '%s'", test.controlID, test.severity, severity)
+		}
+		if weight := config.Weight(test.controlID, test.controlName); weight != test.weight {
+			t.Errorf("%s: expected weight %v, received %v", test.controlID, test.weight, weight)
+		}
+	}
+
+	frameworks := []reporthandling.Framework{{Controls: []reporthandling.Control{
+		{ControlID: "C-0076", BaseScore: 7},
+		{ControlID: "C-0078", BaseScore: 7},
+	}}}
+	config.ApplySeverities(frameworks)
+	if frameworks[0].Controls[0].BaseScore != SeverityToBaseScore(SeverityLow) || frameworks[0].Controls[1].BaseScore != 7 {
+		t.Errorf("unexpected base scores: %v, %v", frameworks[0].Controls[0].BaseScore, frameworks[0].Controls[1].BaseScore)
+	}
+
+	// no configuration - no overrides
+	var empty *ScoringConfig
+	if empty.Severity("C-0076", "") != "" || empty.Weight("C-0076", "") != 1 {
+		t.Error("expected no overrides without a scoring configuration")
+	}
+}
@@ -0,0 +1,44 @@
+package cautils
+
+const (
+	SeverityCritical = "Critical"
+	SeverityHigh     = "High"
+	SeverityMedium   = "Medium"
+	SeverityLow      = "Low"
+	SeverityUnknown  = "Unknown"
+)
+
+// Severities the severity levels, from the lowest to the highest
+var Severities = []string{SeverityLow, SeverityMedium, SeverityHigh, SeverityCritical}
+
+// ControlSeverityToString converts the control base score (scoreFactor) to a severity level
+func ControlSeverityToString(baseScore float32) string {
+	switch {
+	case baseScore >= 9:
+		return SeverityCritical
+	case baseScore >= 7:
+		return SeverityHigh
+	case baseScore >= 4:
+		return SeverityMedium
+	case baseScore >= 1:
+		return SeverityLow
+	default:
+		return SeverityUnknown
+	}
+}
+
+// SeverityToInt returns the rank of the severity level (case insensitive), higher is more severe. Unknown severities are 0
+func SeverityToInt(severity string) int {
+	return StringInSliceCaseInsensitive(Severities, severity) + 1
+}
+
+// severitiesBaseScores the base score of a control overridden with a severity level
+var severitiesBaseScores = map[string]float32{SeverityLow: 3, SeverityMedium: 6, SeverityHigh: 8, SeverityCritical: 10}
+
+// SeverityToBaseScore returns the control base score of the severity level (case insensitive), 0 for an unknown severity
+func SeverityToBaseScore(severity string) float32 {
+	if i := StringInSliceCaseInsensitive(Severities, severity); i != ValueNotFound {
+		return severitiesBaseScores[Severities[i]]
+	}
+	return 0
+}
@@ -42,3 +42,12 @@ func StringInSlice(strSlice []string, str string) int {
 	}
 	return ValueNotFound
 }
+
+func StringInSliceCaseInsensitive(strSlice []string, str string) int {
+	for i := range strSlice {
+		if strings.EqualFold(strSlice[i], str) {
+			return i
+		}
+	}
+	return ValueNotFound
+}
@@ -0,0 +1,57 @@
+package cautils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ScanTimeouts the timeouts of the scan and of its phases, no timeout when zero
+type ScanTimeouts struct {
+	Scan     time.Duration // The whole scan, from the download of the policies to the printing of the results
+	Download time.Duration // Downloading/loading the policies
+	Fetch    time.Duration // Fetching the resources, the data of the host sensor and of the images included
+	Eval     time.Duration // Evaluating the controls
+}
+
+func (timeouts *ScanTimeouts) Validate() error {
+	for flag, timeout := range map[string]time.Duration{"timeout": timeouts.Scan, "download-timeout": timeouts.Download, "fetch-timeout": timeouts.Fetch, "eval-timeout": timeouts.Eval} {
+		if timeout < 0 {
+			return fmt.Errorf("bad argument: negative '--%s' %s", flag, timeout)
+		}
+	}
+	return nil
+}
+
+// PhaseContext returns the context of a phase of the scan, done after the timeout of the phase when set
+func PhaseContext(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// PhaseError names the phase of an error of a phase timed out by its own timeout, ctx is the context of the scan.
+// The other errors, e.g. of the scan timed out or cancelled, are returned as is
+func PhaseError(ctx context.Context, phase string, timeout time.Duration, err error) error {
+	if err != nil && ctx.Err() == nil && errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%s timed out after %s: %w", phase, timeout, err)
+	}
+	return err
+}
+
+// RunWithContext runs f and returns its error, or the error of the context when the context is done first.
+// f is abandoned then, for the work which can not be cancelled (e.g. the download of the policies)
+func RunWithContext(ctx context.Context, f func() error) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- f()
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,47 @@
+package cautils
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPhaseError(t *testing.T) {
+	ctx := context.Background()
+	phaseCtx, cancel := PhaseContext(ctx, time.Millisecond)
+	defer cancel()
+	<-phaseCtx.Done()
+
+	err := PhaseError(ctx, PhaseResourcesFetch, time.Millisecond, phaseCtx.Err())
+	assert.EqualError(t, err, "resources fetch timed out after 1ms: context deadline exceeded")
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	// the scan timed out, not the phase
+	scanCtx, cancelScan := context.WithTimeout(ctx, time.Millisecond)
+	defer cancelScan()
+	<-scanCtx.Done()
+	assert.Equal(t, context.DeadlineExceeded, PhaseError(scanCtx, PhaseResourcesFetch, time.Minute, scanCtx.Err()))
+
+	assert.NoError(t, PhaseError(ctx, PhaseResourcesFetch, time.Minute, nil))
+}
+
+func TestRunWithContext(t *testing.T) {
+	assert.EqualError(t, RunWithContext(context.Background(), func() error { return errors.New("failed") }), "failed")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	blocked := make(chan struct{})
+	defer close(blocked)
+	assert.Equal(t, context.Canceled, RunWithContext(ctx, func() error {
+		<-blocked
+		return nil
+	}))
+}
+
+func TestScanTimeoutsValidate(t *testing.T) {
+	assert.NoError(t, (&ScanTimeouts{Scan: time.Minute}).Validate())
+	assert.EqualError(t, (&ScanTimeouts{Eval: -time.Second}).Validate(), "bad argument: negative '--eval-timeout' -1s")
+}
@@ -0,0 +1,52 @@
+package clihandler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/armosec/k8s-interface/k8sinterface"
+	"github.com/armosec/kubescape/admission"
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/cautils/logger/helpers"
+	"github.com/armosec/kubescape/clihandler/cliobjects"
+	"github.com/armosec/kubescape/opaprocessor"
+	"github.com/armosec/kubescape/policyhandler"
+	"github.com/armosec/opa-utils/reporthandling"
+	"github.com/armosec/opa-utils/resources"
+)
+
+// CliAdmissionServer serves a validating admission webhook, evaluating the admitted resources against the controls of the policies
+func CliAdmissionServer(admissionInfo *cliobjects.AdmissionServer) error {
+	if admissionInfo.TLSCertFile == "" || admissionInfo.TLSKeyFile == "" {
+		return fmt.Errorf("bad argument: the API server calls admission webhooks over TLS, '--tls-cert-file' and '--tls-key-file' are required")
+	}
+	if admissionInfo.SeverityThreshold != "" && cautils.SeverityToInt(admissionInfo.SeverityThreshold) == 0 {
+		return fmt.Errorf("bad argument: unsupported severity threshold '%s'", admissionInfo.SeverityThreshold)
+	}
+	scanInfo := &admissionInfo.ScanInfo
+	if len(scanInfo.PolicyIdentifier) == 0 {
+		return fmt.Errorf("bad argument: no controls or frameworks to evaluate")
+	}
+
+	tenantConfig := getTenantConfig(scanInfo.Account, scanInfo.KubeContext, getKubernetesApi())
+	cautils.ClusterName = tenantConfig.GetClusterName() // TODO - Deprecated
+	cautils.CustomerGUID = tenantConfig.GetAccountID()  // TODO - Deprecated
+	setPolicyGetters(scanInfo, tenantConfig.GetAccountID())
+
+	// the policies are loaded once, restart the server for loading updated policies/exceptions
+	opaSessionObj, err := policyhandler.LoadPolicies(&reporthandling.PolicyNotification{Rules: scanInfo.PolicyIdentifier}, &scanInfo.Getters)
+	if err != nil {
+		return err
+	}
+	evaluator := opaprocessor.NewResourceEvaluator(opaSessionObj, resources.NewRegoDependenciesData(k8sinterface.GetK8sConfig(), cautils.ClusterName))
+
+	mux := http.NewServeMux()
+	mux.Handle("/validate", admission.NewWebhook(evaluator, admissionInfo.SeverityThreshold, admissionInfo.Audit))
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	logger.L().Info("ARMO security scanner starting in admission webhook mode", helpers.String("address", admissionInfo.Address), helpers.String("severity-threshold", admissionInfo.SeverityThreshold), helpers.String("audit", fmt.Sprintf("%v", admissionInfo.Audit)))
+	return http.ListenAndServeTLS(admissionInfo.Address, admissionInfo.TLSCertFile, admissionInfo.TLSKeyFile, mux)
+}
@@ -0,0 +1,108 @@
+package clihandler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/armosec/k8s-interface/k8sinterface"
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/cautils/logger/helpers"
+	"github.com/armosec/kubescape/clihandler/cliobjects"
+	"github.com/armosec/kubescape/opaprocessor"
+	"github.com/armosec/kubescape/resourcehandler"
+	"github.com/armosec/kubescape/resultshandling/diff"
+	"github.com/armosec/opa-utils/reporthandling"
+	"github.com/armosec/opa-utils/reporthandling/results/v1/resourcesresults"
+	reporthandlingv2 "github.com/armosec/opa-utils/reporthandling/v2"
+)
+
+// CliCompare compares the posture of two clusters - scanning the clusters of two kube contexts, or loading two results files
+func CliCompare(compareInfo *cliobjects.Compare) error {
+	comparePrinter, err := diff.NewComparePrinter(compareInfo.Format)
+	if err != nil {
+		return err
+	}
+
+	clusters := [2]string{}
+	reports := [2]*reporthandlingv2.PostureReport{}
+	for i := range reports {
+		if len(compareInfo.Contexts) > 0 {
+			clusters[i] = compareInfo.Contexts[i]
+			reports[i], err = scanContext(compareInfo, compareInfo.Contexts[i])
+		} else {
+			clusters[i] = compareInfo.ResultsFiles[i]
+			if reports[i], err = diff.LoadReport(compareInfo.ResultsFiles[i]); err == nil && reports[i].ClusterName != "" {
+				clusters[i] = reports[i].ClusterName
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if clusters[0] == clusters[1] { // e.g. the results files of the same cluster
+		clusters[0], clusters[1] = clusters[0]+" (1)", clusters[1]+" (2)"
+	}
+
+	comparePrinter.SetWriter(compareInfo.Output)
+	return comparePrinter.ActionPrint(diff.NewCompareReport(clusters, reports))
+}
+
+// scanContext scans the cluster of the kube context, not printing nor submitting the results
+func scanContext(compareInfo *cliobjects.Compare, kubeContext string) (*reporthandlingv2.PostureReport, error) {
+	logger.L().Info("Scanning cluster", helpers.String("context", kubeContext))
+
+	k8sinterface.SetClusterContextName(kubeContext)
+	if err := k8sinterface.LoadK8sConfig(); err != nil {
+		return nil, fmt.Errorf("context '%s': %w", kubeContext, err)
+	}
+
+	scanInfo := &cautils.ScanInfo{
+		KubeContext:      kubeContext,
+		UseArtifactsFrom: compareInfo.UseArtifactsFrom,
+		Local:            true,
+		FrameworkScan:    true,
+		FailThreshold:    100,
+		FetchConcurrency: resourcehandler.DefaultFetchConcurrency,
+		FetchQPS:         resourcehandler.DefaultFetchQPS,
+		FetchPageSize:    resourcehandler.DefaultFetchPageSize,
+		EvalConcurrency:  opaprocessor.DefaultEvalConcurrency,
+	}
+	frameworks := []string{}
+	if compareInfo.Frameworks == "" || compareInfo.Frameworks == "all" {
+		scanInfo.ScanAll = true
+	} else {
+		frameworks = strings.Split(compareInfo.Frameworks, ",")
+	}
+	scanInfo.SetPolicyIdentifiers(frameworks, reporthandling.KindFramework)
+	scanInfo.Init()
+
+	ctx := context.Background()
+	interfaces := getInterfaces(ctx, scanInfo)
+	collector := &resultsCollector{}
+	interfaces.printerHandlers = append(interfaces.printerHandlers, collector)
+	if _, err := runScan(ctx, scanInfo, interfaces); err != nil {
+		return nil, fmt.Errorf("context '%s': %w", kubeContext, err)
+	}
+	return collector.report, nil
+}
+
+// resultsCollector is a printer that keeps the results of the scan, the report of a results file
+type resultsCollector struct {
+	report *reporthandlingv2.PostureReport
+}
+
+func (collector *resultsCollector) ActionPrint(opaSessionObj *cautils.OPASessionObj) error {
+	report := *opaSessionObj.Report
+	report.Results = make([]resourcesresults.Result, 0, len(opaSessionObj.ResourcesResult))
+	for resourceID := range opaSessionObj.ResourcesResult {
+		report.Results = append(report.Results, opaSessionObj.ResourcesResult[resourceID])
+	}
+	collector.report = &report
+	return nil
+}
+
+func (collector *resultsCollector) SetWriter(outputFile string) {}
+
+func (collector *resultsCollector) Score(score float32) {}
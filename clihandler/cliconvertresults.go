@@ -0,0 +1,42 @@
+package clihandler
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/cautils/logger/helpers"
+	"github.com/armosec/kubescape/clihandler/cliobjects"
+	"github.com/armosec/kubescape/resultshandling"
+	printerv2 "github.com/armosec/kubescape/resultshandling/printer/v2"
+)
+
+// CliConvertResults renders a results file in another output format, the session of the scan is restored from the results file without scanning
+func CliConvertResults(convertInfo *cliobjects.ConvertResults) error {
+	if convertInfo.Format == "" {
+		return fmt.Errorf("bad argument: no output format, set the format with '--to'")
+	}
+	encoder, err := printerv2.GetEncoder(convertInfo.Format)
+	if err != nil {
+		return err
+	}
+	report, sources, err := resultshandling.LoadResults(convertInfo.ResultsFile)
+	if err != nil {
+		return err
+	}
+
+	output := &bytes.Buffer{}
+	if err := encoder(output, printerv2.NewReportSession(report, sources)); err != nil {
+		return err
+	}
+	if convertInfo.Output == "" {
+		_, err := os.Stdout.Write(output.Bytes())
+		return err
+	}
+	if err := os.WriteFile(convertInfo.Output, output.Bytes(), 0664); err != nil {
+		return err
+	}
+	logger.L().Success("Results converted", helpers.String("format", convertInfo.Format), helpers.String("path", convertInfo.Output))
+	return nil
+}
@@ -0,0 +1,36 @@
+package clihandler
+
+import (
+	"fmt"
+
+	"github.com/armosec/kubescape/clihandler/cliobjects"
+	"github.com/armosec/kubescape/resultshandling/diff"
+)
+
+func CliDiff(diffInfo *cliobjects.Diff) error {
+	diffPrinter, err := diff.NewDiffPrinter(diffInfo.Format)
+	if err != nil {
+		return err
+	}
+
+	oldReport, err := diff.LoadReport(diffInfo.OldResults)
+	if err != nil {
+		return err
+	}
+	newReport, err := diff.LoadReport(diffInfo.NewResults)
+	if err != nil {
+		return err
+	}
+
+	diffReport := diff.NewDiffReport(oldReport, newReport)
+
+	diffPrinter.SetWriter(diffInfo.Output)
+	if err := diffPrinter.ActionPrint(diffReport); err != nil {
+		return err
+	}
+
+	if diffInfo.FailOnRegression && diffReport.IsRegression() {
+		return fmt.Errorf("the security posture degraded: %d new failed controls, risk-score delta %+.2f%%", len(diffReport.NewFailures), diffReport.ScoreDelta)
+	}
+	return nil
+}
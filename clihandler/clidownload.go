@@ -1,11 +1,16 @@
 package clihandler
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/armosec/armoapi-go/armotypes"
+	"github.com/armosec/kubescape/attacktracks"
 	"github.com/armosec/kubescape/cautils"
 	"github.com/armosec/kubescape/cautils/getter"
 	"github.com/armosec/kubescape/cautils/logger"
@@ -30,6 +35,9 @@ func DownloadSupportCommands() []string {
 
 func CliDownload(downloadInfo *cautils.DownloadInfo) error {
 	setPathandFilename(downloadInfo)
+	if downloadInfo.From != "" {
+		return downloadFromPolicyBundle(downloadInfo)
+	}
 	if err := downloadArtifact(downloadInfo, downloadFunc); err != nil {
 		return err
 	}
@@ -60,25 +68,119 @@ func setPathandFilename(downloadInfo *cautils.DownloadInfo) {
 	}
 }
 
+// downloadArtifacts downloads all of the artifacts of the scans to a directory, with the manifest and the checksums of the artifacts - for
+// transferring the artifacts to disconnected environments, see 'kubescape scan --offline'
 func downloadArtifacts(downloadInfo *cautils.DownloadInfo) error {
 	downloadInfo.FileName = ""
 	var artifacts = map[string]func(*cautils.DownloadInfo) error{
-		"controls-inputs": downloadConfigInputs,
-		"exceptions":      downloadExceptions,
-		"framework":       downloadFramework,
+		"controls-inputs":   downloadConfigInputs,
+		"exceptions":        downloadExceptions,
+		"exceptions-schema": downloadExceptionsSchema,
+		"framework":         downloadFramework,
+		"controls":          downloadControls,
+		"attack-tracks":     downloadAttackTracks,
+	}
+	if err := os.MkdirAll(downloadInfo.Path, 0755); err != nil {
+		return err
+	}
+
+	// all of the artifacts are of the same release of the policies
+	policyVersion := getter.NormalizePolicyVersion(downloadInfo.PolicyVersion)
+	if policyVersion == "" {
+		var err error
+		if policyVersion, err = getter.ResolveLatestPolicyVersion(); err != nil {
+			logger.L().Warning("failed to resolve the latest version of the policies", helpers.Error(err))
+		}
 	}
+
+	manifest := &getter.ArtifactsManifest{KubescapeVersion: cautils.BuildNumber, PolicyVersion: policyVersion, CreationTime: time.Now().UTC(), Artifacts: []getter.ArtifactFile{}}
+	failed := []string{}
 	for artifact := range artifacts {
-		if err := downloadArtifact(&cautils.DownloadInfo{Target: artifact, Path: downloadInfo.Path, FileName: fmt.Sprintf("%s.json", artifact)}, artifacts); err != nil {
+		artifactInfo := &cautils.DownloadInfo{Target: artifact, Path: downloadInfo.Path, FileName: fmt.Sprintf("%s.json", artifact), Account: downloadInfo.Account, From: downloadInfo.From, PolicyVersion: policyVersion, PublicKey: downloadInfo.PublicKey, InsecureSkipVerify: downloadInfo.InsecureSkipVerify}
+		if err := downloadArtifact(artifactInfo, artifacts); err != nil {
 			logger.L().Error("error downloading", helpers.String("artifact", artifact), helpers.Error(err))
+			failed = append(failed, artifact)
+		}
+		for _, downloaded := range artifactInfo.Downloaded {
+			path, err := filepath.Rel(downloadInfo.Path, downloaded)
+			if err != nil {
+				return err
+			}
+			manifest.Artifacts = append(manifest.Artifacts, getter.ArtifactFile{Artifact: artifact, Path: path})
 		}
 	}
+	if err := getter.WriteArtifactsManifest(downloadInfo.Path, manifest); err != nil {
+		return fmt.Errorf("failed to write the manifest of the artifacts: %w", err)
+	}
+	logger.L().Success("Downloaded the artifacts", helpers.Int("artifacts", len(manifest.Artifacts)), helpers.String("manifest", filepath.Join(downloadInfo.Path, getter.ArtifactsManifestFileName)))
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		return fmt.Errorf("failed to download the artifacts: %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// downloadControls downloads all of the controls of the released policies to a single file
+func downloadControls(downloadInfo *cautils.DownloadInfo) error {
+	g, err := getVerifiedReleasedPolicy(downloadInfo)
+	if err != nil {
+		return err
+	}
+	controls, err := g.GetControls()
+	if err != nil {
+		return err
+	}
+	return saveArtifact(downloadInfo, controls)
+}
+
+// downloadExceptionsSchema saves the JSON schema of the exceptions files, of the exceptions of this version
+func downloadExceptionsSchema(downloadInfo *cautils.DownloadInfo) error {
+	schema, err := cautils.GenerateJSONSchema([]armotypes.PostureExceptionPolicy{}, "", "Kubescape exceptions (--exceptions)")
+	if err != nil {
+		return err
+	}
+	return saveArtifact(downloadInfo, json.RawMessage(schema))
+}
+
+// downloadAttackTracks saves the stages of the attack tracks - the stages of the built-in controls and of the controls of the frameworks
+func downloadAttackTracks(downloadInfo *cautils.DownloadInfo) error {
+	g, err := getVerifiedReleasedPolicy(downloadInfo)
+	if err != nil {
+		return err
+	}
+	frameworks, err := g.GetFrameworks()
+	if err != nil {
+		return err
+	}
+	return saveArtifact(downloadInfo, map[string]interface{}{
+		"stages":         attacktracks.Stages,
+		"controlsStages": attacktracks.ControlsStages(frameworks),
+	})
+}
+
+func saveArtifact(downloadInfo *cautils.DownloadInfo, artifact interface{}) error {
+	downloadTo := filepath.Join(downloadInfo.Path, downloadInfo.FileName)
+	if err := getter.SaveInFile(artifact, downloadTo); err != nil {
+		return err
+	}
+	downloadInfo.Downloaded = append(downloadInfo.Downloaded, downloadTo)
+	logger.L().Success("Downloaded", helpers.String("artifact", downloadInfo.Target), helpers.String("path", downloadTo))
 	return nil
 }
 
 func downloadConfigInputs(downloadInfo *cautils.DownloadInfo) error {
 	tenant := getTenantConfig(downloadInfo.Account, "", getKubernetesApi())
 
-	controlsInputsGetter := getConfigInputsGetter(downloadInfo.Name, tenant.GetAccountID(), nil)
+	var controlsInputsGetter getter.IControlsInputsGetter
+	if tenant.GetAccountID() != "" {
+		controlsInputsGetter = getConfigInputsGetter(downloadInfo.Name, tenant.GetAccountID(), nil)
+	} else {
+		downloadReleasedPolicy, err := getVerifiedReleasedPolicy(downloadInfo)
+		if err != nil {
+			return err
+		}
+		controlsInputsGetter = downloadReleasedPolicy
+	}
 	controlInputs, err := controlsInputsGetter.GetControlsInputs(tenant.GetClusterName())
 	if err != nil {
 		return err
@@ -91,6 +193,7 @@ func downloadConfigInputs(downloadInfo *cautils.DownloadInfo) error {
 	if err != nil {
 		return err
 	}
+	downloadInfo.Downloaded = append(downloadInfo.Downloaded, filepath.Join(downloadInfo.Path, downloadInfo.FileName))
 	logger.L().Success("Downloaded", helpers.String("artifact", downloadInfo.Target), helpers.String("path", filepath.Join(downloadInfo.Path, downloadInfo.FileName)))
 	return nil
 }
@@ -115,6 +218,7 @@ func downloadExceptions(downloadInfo *cautils.DownloadInfo) error {
 	if err != nil {
 		return err
 	}
+	downloadInfo.Downloaded = append(downloadInfo.Downloaded, filepath.Join(downloadInfo.Path, downloadInfo.FileName))
 	logger.L().Success("Downloaded", helpers.String("artifact", downloadInfo.Target), helpers.String("path", filepath.Join(downloadInfo.Path, downloadInfo.FileName)))
 	return nil
 }
@@ -123,7 +227,14 @@ func downloadFramework(downloadInfo *cautils.DownloadInfo) error {
 
 	tenant := getTenantConfig(downloadInfo.Account, "", getKubernetesApi())
 
-	g := getPolicyGetter(nil, tenant.GetAccountID(), true, nil)
+	var g getter.IPolicyGetter = getter.GetArmoAPIConnector() // download policy from ARMO backend
+	if tenant.GetAccountID() == "" {
+		downloadReleasedPolicy, err := getVerifiedReleasedPolicy(downloadInfo)
+		if err != nil {
+			return err
+		}
+		g = downloadReleasedPolicy
+	}
 
 	if downloadInfo.Name == "" {
 		// if framework name not specified - download all frameworks
@@ -137,6 +248,7 @@ func downloadFramework(downloadInfo *cautils.DownloadInfo) error {
 			if err != nil {
 				return err
 			}
+			downloadInfo.Downloaded = append(downloadInfo.Downloaded, downloadTo)
 			logger.L().Success("Downloaded", helpers.String("artifact", downloadInfo.Target), helpers.String("name", fw.Name), helpers.String("path", downloadTo))
 		}
 		// return fmt.Errorf("missing framework name")
@@ -153,6 +265,7 @@ func downloadFramework(downloadInfo *cautils.DownloadInfo) error {
 		if err != nil {
 			return err
 		}
+		downloadInfo.Downloaded = append(downloadInfo.Downloaded, downloadTo)
 		logger.L().Success("Downloaded", helpers.String("artifact", downloadInfo.Target), helpers.String("name", framework.Name), helpers.String("path", downloadTo))
 	}
 	return nil
@@ -160,9 +273,10 @@ func downloadFramework(downloadInfo *cautils.DownloadInfo) error {
 
 func downloadControl(downloadInfo *cautils.DownloadInfo) error {
 
-	tenant := getTenantConfig(downloadInfo.Account, "", getKubernetesApi())
-
-	g := getPolicyGetter(nil, tenant.GetAccountID(), false, nil)
+	g, err := getVerifiedReleasedPolicy(downloadInfo)
+	if err != nil {
+		return err
+	}
 
 	if downloadInfo.Name == "" {
 		// TODO - support
@@ -180,6 +294,38 @@ func downloadControl(downloadInfo *cautils.DownloadInfo) error {
 	if err != nil {
 		return err
 	}
+	downloadInfo.Downloaded = append(downloadInfo.Downloaded, downloadTo)
 	logger.L().Success("Downloaded", helpers.String("artifact", downloadInfo.Target), helpers.String("name", downloadInfo.Name), helpers.String("path", downloadTo))
 	return nil
 }
+
+// getVerifiedReleasedPolicy pulls the released policies, verified against the pinned public key (or the --public-key file).
+// Fails closed when there is a key - the downloaded policies are not saved if an artifact is not verified, unless --insecure-skip-verify.
+// The builds without a pinned key (e.g. of the releases of unsigned policies) download the policies unverified, with a warning
+func getVerifiedReleasedPolicy(downloadInfo *cautils.DownloadInfo) (*getter.DownloadReleasedPolicy, error) {
+	var downloadReleasedPolicy *getter.DownloadReleasedPolicy
+	if downloadInfo.InsecureSkipVerify {
+		logger.L().Warning("skipping the verification of the signatures of the released policies")
+		downloadReleasedPolicy = getter.NewDownloadReleasedPolicy()
+	} else if downloadInfo.PublicKey == "" && getter.ReleasePublicKey == "" {
+		logger.L().Warning("no public key is pinned in this build, the released policies are not verified. Set '--public-key' for verifying them")
+		downloadReleasedPolicy = getter.NewDownloadReleasedPolicy()
+	} else {
+		var verifier *getter.SignatureVerifier
+		var err error
+		if downloadInfo.PublicKey != "" {
+			verifier, err = getter.LoadSignatureVerifier(downloadInfo.PublicKey)
+		} else {
+			verifier, err = getter.NewSignatureVerifier(getter.ReleasePublicKey)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the public key: %w", err)
+		}
+		downloadReleasedPolicy = getter.NewVerifiedDownloadReleasedPolicy(verifier)
+	}
+	downloadReleasedPolicy.SetPolicyVersion(downloadInfo.PolicyVersion)
+	if err := downloadReleasedPolicy.SetRegoObjects(); err != nil {
+		return nil, fmt.Errorf("failed to get the policies from github release: %w", err)
+	}
+	return downloadReleasedPolicy, nil
+}
@@ -0,0 +1,193 @@
+package clihandler
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/armosec/armoapi-go/armotypes"
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/cautils/logger/helpers"
+	"github.com/armosec/kubescape/clihandler/cliobjects"
+	"github.com/armosec/kubescape/exceptionshandler"
+	"github.com/armosec/kubescape/resultshandling/diff"
+	reporthandlingv2 "github.com/armosec/opa-utils/reporthandling/v2"
+	"github.com/mattn/go-isatty"
+	"github.com/olekukonko/tablewriter"
+)
+
+// CliExceptionsAdd adds an exception to the exceptions file, the missing fields are prompted for when running in a terminal
+func CliExceptionsAdd(exceptionsInfo *cliobjects.Exceptions) error {
+	exceptions, report, err := loadExceptionsAndResults(exceptionsInfo)
+	if err != nil {
+		return err
+	}
+	if isatty.IsTerminal(os.Stdin.Fd()) {
+		if err := exceptionshandler.NewPrompter(os.Stdin, os.Stderr).PromptMissing(&exceptionsInfo.Options); err != nil {
+			return err
+		}
+	}
+	exception, err := exceptionshandler.NewException(&exceptionsInfo.Options, time.Now())
+	if err != nil {
+		return err
+	}
+	if exceptions, err = exceptionshandler.AddException(exceptions, exception); err != nil {
+		return err
+	}
+	if err := exceptionshandler.SaveExceptions(exceptionsInfo.File, exceptions); err != nil {
+		return err
+	}
+	logger.L().Success("Added exception", helpers.String("name", exception.Name), helpers.String("path", exceptionsInfo.File))
+
+	if report != nil {
+		prettyPrintSuppressedFindings(fmt.Sprintf("Failed controls suppressed by '%s'", exception.Name), exceptionshandler.SuppressedFindings(exception, report))
+	}
+	return nil
+}
+
+// CliExceptionsRemove removes an exception from the exceptions file
+func CliExceptionsRemove(exceptionsInfo *cliobjects.Exceptions) error {
+	exceptions, report, err := loadExceptionsAndResults(exceptionsInfo)
+	if err != nil {
+		return err
+	}
+	exception := exceptionshandler.FindException(exceptions, exceptionsInfo.Name)
+	if exception == nil {
+		return fmt.Errorf("exception '%s' not found in '%s'", exceptionsInfo.Name, exceptionsInfo.File)
+	}
+	var findings []exceptionshandler.Finding
+	if report != nil {
+		findings = exceptionshandler.SuppressedFindings(exception, report)
+	}
+	if exceptions, err = exceptionshandler.RemoveException(exceptions, exceptionsInfo.Name); err != nil {
+		return err
+	}
+	if err := exceptionshandler.SaveExceptions(exceptionsInfo.File, exceptions); err != nil {
+		return err
+	}
+	logger.L().Success("Removed exception", helpers.String("name", exceptionsInfo.Name), helpers.String("path", exceptionsInfo.File))
+
+	if report != nil {
+		prettyPrintSuppressedFindings(fmt.Sprintf("Failed controls no longer suppressed by '%s'", exceptionsInfo.Name), findings)
+	}
+	return nil
+}
+
+// CliExceptionsList prints the exceptions of the exceptions file, and the failed controls each exception suppresses when a results file is set
+func CliExceptionsList(exceptionsInfo *cliobjects.Exceptions) error {
+	exceptions, report, err := loadExceptionsAndResults(exceptionsInfo)
+	if err != nil {
+		return err
+	}
+	if len(exceptions) == 0 {
+		fmt.Fprintf(os.Stderr, "No exceptions in '%s'\n", exceptionsInfo.File)
+		return nil
+	}
+
+	suppressed := map[string][]exceptionshandler.Finding{}
+	header := []string{"Name", "Controls", "Resources", "Expiration", "Owner", "Reason"}
+	if report != nil {
+		header = append(header, "Suppressed")
+	}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader(header)
+	table.SetHeaderLine(true)
+	table.SetRowLine(true)
+	now := time.Now()
+	for i := range exceptions {
+		row := []string{exceptions[i].Name, exceptionPolicies(&exceptions[i]), exceptionResources(&exceptions[i]), exceptionExpiration(&exceptions[i], now), exceptionshandler.GetOwner(&exceptions[i]), exceptionshandler.GetReason(&exceptions[i])}
+		if report != nil {
+			suppressed[exceptions[i].Name] = exceptionshandler.SuppressedFindings(&exceptions[i], report)
+			row = append(row, fmt.Sprintf("%d", len(suppressed[exceptions[i].Name])))
+		}
+		table.Append(row)
+	}
+	table.Render()
+
+	for i := range exceptions {
+		if findings := suppressed[exceptions[i].Name]; len(findings) > 0 {
+			prettyPrintSuppressedFindings(fmt.Sprintf("Failed controls suppressed by '%s'", exceptions[i].Name), findings)
+		}
+	}
+	return nil
+}
+
+// loadExceptionsAndResults loads and validates the exceptions file, and the results file if set
+func loadExceptionsAndResults(exceptionsInfo *cliobjects.Exceptions) ([]armotypes.PostureExceptionPolicy, *reporthandlingv2.PostureReport, error) {
+	exceptions, err := exceptionshandler.LoadExceptions(exceptionsInfo.File)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := exceptionshandler.ValidateExceptions(exceptions); err != nil {
+		return nil, nil, fmt.Errorf("'%s': %w", exceptionsInfo.File, err)
+	}
+	if exceptionsInfo.Results == "" {
+		return exceptions, nil, nil
+	}
+	report, err := diff.LoadReport(exceptionsInfo.Results)
+	if err != nil {
+		return nil, nil, err
+	}
+	return exceptions, report, nil
+}
+
+func prettyPrintSuppressedFindings(title string, findings []exceptionshandler.Finding) {
+	fmt.Printf("\n%s: %d\n", title, len(findings))
+	if len(findings) == 0 {
+		return
+	}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"Control", "Resource"})
+	table.SetHeaderLine(true)
+	for i := range findings {
+		table.Append([]string{fmt.Sprintf("%s %s", findings[i].ControlID, findings[i].ControlName), findings[i].ResourceID})
+	}
+	table.Render()
+}
+
+func exceptionPolicies(exception *armotypes.PostureExceptionPolicy) string {
+	policies := []string{}
+	for _, policy := range exception.PosturePolicies {
+		fields := []string{}
+		for _, field := range [][2]string{{"framework", policy.FrameworkName}, {"control", policy.ControlName}, {"controlID", policy.ControlID}, {"rule", policy.RuleName}} {
+			if field[1] != "" {
+				fields = append(fields, fmt.Sprintf("%s=%s", field[0], field[1]))
+			}
+		}
+		if len(fields) > 0 {
+			policies = append(policies, strings.Join(fields, ","))
+		}
+	}
+	if len(policies) == 0 {
+		return "all"
+	}
+	return strings.Join(policies, "\n")
+}
+
+func exceptionResources(exception *armotypes.PostureExceptionPolicy) string {
+	resources := []string{}
+	for i := range exception.Resources {
+		attributes := []string{}
+		for key, value := range exception.Resources[i].Attributes {
+			attributes = append(attributes, fmt.Sprintf("%s=%s", key, value))
+		}
+		sort.Strings(attributes)
+		resources = append(resources, strings.Join(attributes, ","))
+	}
+	return strings.Join(resources, "\n")
+}
+
+func exceptionExpiration(exception *armotypes.PostureExceptionPolicy, now time.Time) string {
+	expiration, _ := exceptionshandler.GetExpiration(exception)
+	if expiration == nil {
+		return ""
+	}
+	if exceptionshandler.IsExpired(exception, now) {
+		return fmt.Sprintf("%s (expired)", expiration.Local().Format("2006-01-02 15:04"))
+	}
+	return expiration.Local().Format("2006-01-02 15:04")
+}
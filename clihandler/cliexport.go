@@ -0,0 +1,72 @@
+package clihandler
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/armosec/kubescape/admission"
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/cautils/logger/helpers"
+	"github.com/armosec/kubescape/clihandler/cliobjects"
+	"sigs.k8s.io/yaml"
+)
+
+// CliExportPolicy translates the controls to admission policies (Gatekeeper ConstraintTemplates or ValidatingAdmissionPolicies) and prints them as YAML
+func CliExportPolicy(exportInfo *cliobjects.ExportPolicy) error {
+	if len(exportInfo.Controls) == 0 {
+		return fmt.Errorf("bad argument: no controls to export")
+	}
+	if exportInfo.Format != "gatekeeper" && exportInfo.Format != "vap" {
+		return fmt.Errorf("bad argument: unsupported format '%s'. Supported: gatekeeper/vap", exportInfo.Format)
+	}
+	tenant := getTenantConfig(exportInfo.Account, "", getKubernetesApi())
+	policyGetter := getPolicyGetter(exportInfo.UseFrom, tenant.GetAccountID(), false, nil)
+
+	controlsInputs := map[string][]string{}
+	if exportInfo.Format == "gatekeeper" {
+		var err error
+		if controlsInputs, err = getConfigInputsGetter(exportInfo.ControlsInputs, tenant.GetAccountID(), nil).GetControlsInputs(tenant.GetClusterName()); err != nil {
+			return fmt.Errorf("failed to get the controls inputs: %w", err)
+		}
+	}
+
+	output := &bytes.Buffer{}
+	for _, controlID := range exportInfo.Controls {
+		control, err := policyGetter.GetControl(controlID)
+		if err != nil {
+			return fmt.Errorf("failed to get control '%s': %w", controlID, err)
+		}
+		if control == nil {
+			return fmt.Errorf("control '%s' not found", controlID)
+		}
+
+		var objects []map[string]interface{}
+		if exportInfo.Format == "gatekeeper" {
+			objects, err = admission.GatekeeperPolicy(control, controlsInputs, exportInfo.Action)
+		} else {
+			objects, err = admission.VAPPolicy(control, exportInfo.Action)
+		}
+		if err != nil {
+			return err
+		}
+		for i := range objects {
+			data, err := yaml.Marshal(objects[i])
+			if err != nil {
+				return err
+			}
+			output.WriteString("---\n")
+			output.Write(data)
+		}
+	}
+
+	if exportInfo.Output == "" {
+		_, err := os.Stdout.Write(output.Bytes())
+		return err
+	}
+	if err := os.WriteFile(exportInfo.Output, output.Bytes(), 0664); err != nil {
+		return err
+	}
+	logger.L().Success("Policies exported", helpers.String("path", exportInfo.Output))
+	return nil
+}
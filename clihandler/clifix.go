@@ -0,0 +1,74 @@
+package clihandler
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/cautils/logger/helpers"
+	"github.com/armosec/kubescape/clihandler/cliobjects"
+	"github.com/armosec/kubescape/fixhandler"
+	"github.com/armosec/kubescape/resultshandling"
+)
+
+// CliFix generates the fixes of the failed resources of a results file. The fixes are printed as JSON patches and strategic merge patches,
+// or applied - the source files of the resources loaded from files are fixed, the other resources are patched in the cluster
+func CliFix(fixInfo *cliobjects.Fix) error {
+	report, sources, err := resultshandling.LoadResults(fixInfo.ResultsFile)
+	if err != nil {
+		return err
+	}
+	fixes, err := fixhandler.NewResourceFixes(report, sources, &fixhandler.Options{Values: fixInfo.Values})
+	if err != nil {
+		return err
+	}
+	for i := range fixes {
+		for _, path := range fixes[i].Unfixed {
+			logger.L().Warning("the fix requires a value, set it by '--value'", helpers.String("resource", fixes[i].ResourceID), helpers.String("path", path))
+		}
+	}
+
+	if !fixInfo.Apply {
+		data, err := json.MarshalIndent(fixes, "", "  ")
+		if err != nil {
+			return err
+		}
+		if fixInfo.Output == "" {
+			_, err := fmt.Fprintln(os.Stdout, string(data))
+			return err
+		}
+		if err := os.WriteFile(fixInfo.Output, data, 0664); err != nil {
+			return err
+		}
+		logger.L().Success("Fixes generated", helpers.String("path", fixInfo.Output), helpers.Int("resources", len(fixes)))
+		return nil
+	}
+
+	fixedFiles := fixhandler.FixFiles(fixes)
+	for i := range fixedFiles {
+		if err := fixedFiles[i].Write(); err != nil {
+			return err
+		}
+		logger.L().Success("Fixed file", helpers.String("path", fixedFiles[i].Path))
+	}
+
+	clusterFixes := 0
+	for i := range fixes {
+		if fixes[i].Source == nil && len(fixes[i].JSONPatch) > 0 {
+			clusterFixes++
+		}
+	}
+	if clusterFixes == 0 {
+		return nil
+	}
+	k8s := getKubernetesApi()
+	if k8s == nil {
+		return fmt.Errorf("failed to patch %d resources: not connected to a cluster", clusterFixes)
+	}
+	if err := fixhandler.FixCluster(k8s.DynamicClient, fixes); err != nil {
+		return err
+	}
+	logger.L().Success("Fixed resources in the cluster", helpers.Int("resources", clusterFixes))
+	return nil
+}
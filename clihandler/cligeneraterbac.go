@@ -0,0 +1,78 @@
+package clihandler
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/cautils/logger/helpers"
+	"github.com/armosec/kubescape/clihandler/cliobjects"
+	"github.com/armosec/kubescape/resourcehandler"
+	"sigs.k8s.io/yaml"
+)
+
+// CliGenerateRBAC prints the least-privilege role of scanning the frameworks, derived from the match rules of the controls, and
+// its binding to the service account of the scanner
+func CliGenerateRBAC(generateInfo *cliobjects.GenerateRBAC) error {
+	serviceAccount := strings.SplitN(generateInfo.ServiceAccount, "/", 2)
+	if len(serviceAccount) != 2 || serviceAccount[0] == "" || serviceAccount[1] == "" {
+		return fmt.Errorf("bad argument: service account '%s', expected <namespace>/<name>", generateInfo.ServiceAccount)
+	}
+	tenant := getTenantConfig(generateInfo.Account, "", getKubernetesApi())
+	frameworks, err := getFrameworks(getPolicyGetter(generateInfo.UseFrom, tenant.GetAccountID(), true, nil), generateInfo.Frameworks)
+	if err != nil {
+		return err
+	}
+
+	resources := resourcehandler.ListRequiredResources(frameworks)
+	rules := resourcehandler.ScannerPolicyRules(resources, generateInfo.Namespace != "", generateInfo.HostSensor)
+	for i := range resources {
+		if resources[i].Source == resourcehandler.ResourceSourceCloudAPI {
+			logger.L().Warning("The controls of the cloud provider require the credentials of the cloud provider, not granted by the role", helpers.String("resource", resources[i].GroupResource))
+		}
+	}
+
+	roleKind, bindingKind := "ClusterRole", "ClusterRoleBinding"
+	metadata := map[string]interface{}{"name": generateInfo.Name}
+	if generateInfo.Namespace != "" {
+		roleKind, bindingKind = "Role", "RoleBinding"
+		metadata["namespace"] = generateInfo.Namespace
+	}
+	objects := []map[string]interface{}{
+		{
+			"apiVersion": "rbac.authorization.k8s.io/v1",
+			"kind":       roleKind,
+			"metadata":   metadata,
+			"rules":      rules,
+		},
+		{
+			"apiVersion": "rbac.authorization.k8s.io/v1",
+			"kind":       bindingKind,
+			"metadata":   metadata,
+			"roleRef":    map[string]interface{}{"apiGroup": "rbac.authorization.k8s.io", "kind": roleKind, "name": generateInfo.Name},
+			"subjects":   []interface{}{map[string]interface{}{"kind": "ServiceAccount", "name": serviceAccount[1], "namespace": serviceAccount[0]}},
+		},
+	}
+
+	output := &bytes.Buffer{}
+	for i := range objects {
+		data, err := yaml.Marshal(objects[i])
+		if err != nil {
+			return err
+		}
+		output.WriteString("---\n")
+		output.Write(data)
+	}
+
+	if generateInfo.Output == "" {
+		_, err := os.Stdout.Write(output.Bytes())
+		return err
+	}
+	if err := os.WriteFile(generateInfo.Output, output.Bytes(), 0664); err != nil {
+		return err
+	}
+	logger.L().Success("RBAC generated", helpers.String("path", generateInfo.Output))
+	return nil
+}
@@ -0,0 +1,124 @@
+package clihandler
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/clihandler/cliobjects"
+	"github.com/armosec/kubescape/resultshandling/printer"
+	"github.com/armosec/kubescape/resultshandling/store"
+	"github.com/olekukonko/tablewriter"
+)
+
+type historyObject struct {
+	Scans    []store.ScanSummary             `json:"scans"`
+	Controls map[string][]store.ControlTrend `json:"controls,omitempty"`
+}
+
+func CliHistory(historyInfo *cliobjects.History) error {
+	if historyInfo.Format != printer.PrettyFormat && historyInfo.Format != printer.JsonFormat {
+		return fmt.Errorf("format '%s' is not supported, supported formats: %s/%s", historyInfo.Format, printer.PrettyFormat, printer.JsonFormat)
+	}
+
+	path := historyInfo.StorePath
+	if path == "" {
+		path = store.DefaultStorePath()
+	}
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("results store '%s' not found, run the scan with the '--store' flag first", path)
+	}
+	resultsStore, err := store.NewResultsStore(path)
+	if err != nil {
+		return err
+	}
+	defer resultsStore.Close()
+
+	history := historyObject{Controls: map[string][]store.ControlTrend{}}
+	if history.Scans, err = resultsStore.ListScans(historyInfo.ClusterName, historyInfo.Limit); err != nil {
+		return err
+	}
+	for _, controlID := range historyInfo.Controls {
+		if history.Controls[controlID], err = resultsStore.ControlTrend(controlID, historyInfo.ClusterName, historyInfo.Limit); err != nil {
+			return err
+		}
+	}
+
+	switch historyInfo.Format {
+	case printer.JsonFormat:
+		j, _ := json.MarshalIndent(history, "", "  ")
+		fmt.Printf("%s\n", j)
+	default:
+		prettyPrintScansTrend(history.Scans)
+		for _, controlID := range historyInfo.Controls {
+			prettyPrintControlTrend(controlID, history.Controls[controlID])
+		}
+	}
+	return nil
+}
+
+// prettyPrintScansTrend prints a row per scan with the overall and framework scores, and the change from the previous scan
+func prettyPrintScansTrend(scans []store.ScanSummary) {
+	frameworks := []string{}
+	for i := range scans {
+		for _, framework := range scans[i].Frameworks {
+			if cautils.StringInSlice(frameworks, framework.Name) == cautils.ValueNotFound {
+				frameworks = append(frameworks, framework.Name)
+			}
+		}
+	}
+	sort.Strings(frameworks)
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader(append([]string{"Date", "Cluster", "Risk-score"}, frameworks...))
+	table.SetHeaderLine(true)
+
+	previous := map[string]float32{}
+	for i := range scans {
+		scores := map[string]float32{}
+		for _, framework := range scans[i].Frameworks {
+			scores[framework.Name] = framework.Score
+		}
+		row := []string{scans[i].GeneratedAt.Local().Format("2006-01-02 15:04:05"), scans[i].ClusterName, scoreTrend(scans[i].Score, previous, "")}
+		for _, framework := range frameworks {
+			score, ok := scores[framework]
+			if !ok {
+				row = append(row, "")
+				continue
+			}
+			row = append(row, scoreTrend(score, previous, framework))
+		}
+		table.Append(row)
+	}
+	table.Render()
+}
+
+func prettyPrintControlTrend(controlID string, trend []store.ControlTrend) {
+	if len(trend) == 0 {
+		fmt.Printf("\nControl %s: no results\n", controlID)
+		return
+	}
+	fmt.Printf("\nControl %s - %s\n", controlID, trend[len(trend)-1].Name)
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"Date", "Cluster", "Status", "Failed resources", "Risk-score"})
+	table.SetHeaderLine(true)
+	previous := map[string]float32{}
+	for i := range trend {
+		table.Append([]string{trend[i].GeneratedAt.Local().Format("2006-01-02 15:04:05"), trend[i].ClusterName, trend[i].Status, fmt.Sprintf("%d/%d", trend[i].FailedResources, trend[i].TotalResources), scoreTrend(trend[i].Score, previous, controlID)})
+	}
+	table.Render()
+}
+
+// scoreTrend returns the score with the change from the previous score of the key, and updates the previous score
+func scoreTrend(score float32, previous map[string]float32, key string) string {
+	s := fmt.Sprintf("%.2f%%", score)
+	if p, ok := previous[key]; ok && p != score {
+		s += fmt.Sprintf(" (%+.2f)", score-p)
+	}
+	previous[key] = score
+	return s
+}
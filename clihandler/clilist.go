@@ -8,6 +8,8 @@ import (
 
 	"github.com/armosec/kubescape/cautils/getter"
 	"github.com/armosec/kubescape/clihandler/cliobjects"
+	"github.com/armosec/kubescape/resourcehandler"
+	"github.com/armosec/opa-utils/reporthandling"
 )
 
 var listFunc = map[string]func(*cliobjects.ListPolicies) ([]string, error){
@@ -22,13 +24,16 @@ var listFormatFunc = map[string]func(*cliobjects.ListPolicies, []string){
 }
 
 func ListSupportCommands() []string {
-	commands := []string{}
+	commands := []string{"resources"}
 	for k := range listFunc {
 		commands = append(commands, k)
 	}
 	return commands
 }
 func CliList(listPolicies *cliobjects.ListPolicies) error {
+	if listPolicies.Target == "resources" {
+		return listResources(listPolicies)
+	}
 	if f, ok := listFunc[listPolicies.Target]; ok {
 		policies, err := f(listPolicies)
 		if err != nil {
@@ -77,6 +82,66 @@ func listExceptions(listPolicies *cliobjects.ListPolicies) ([]string, error) {
 	return exceptionsNames, nil
 }
 
+// listResources prints the resources which would be fetched for scanning the frameworks, by the source they are fetched from,
+// for predicting the load on the API server and the permissions required for the scan
+func listResources(listPolicies *cliobjects.ListPolicies) error {
+	k8s := getKubernetesApi()
+	tenant := getTenantConfig(listPolicies.Account, "", k8s)
+	frameworks, err := getFrameworks(getPolicyGetter(listPolicies.UseFrom, tenant.GetAccountID(), true, nil), listPolicies.Frameworks)
+	if err != nil {
+		return err
+	}
+
+	resources := resourcehandler.ListRequiredResources(frameworks)
+	if listPolicies.Count {
+		if k8s == nil {
+			return fmt.Errorf("bad argument: counting the objects requires access to a cluster")
+		}
+		resourcehandler.CountObjects(k8s, resources)
+	}
+
+	if listPolicies.Format == "json" {
+		j, _ := json.MarshalIndent(resources, "", "  ")
+		fmt.Printf("%s\n", j)
+		return nil
+	}
+	source := ""
+	for i := range resources {
+		if resources[i].Source != source {
+			source = resources[i].Source
+			fmt.Printf("Resources fetched from %s:\n", source)
+		}
+		line := fmt.Sprintf("  * %s (%d controls)", resources[i].GroupResource, len(resources[i].Controls))
+		if resources[i].Objects != nil {
+			line = fmt.Sprintf("%s - %d objects", line, *resources[i].Objects)
+		}
+		fmt.Println(line)
+	}
+	return nil
+}
+
+// getFrameworks returns the frameworks by their comma separated names, all of the frameworks when empty or "all"
+func getFrameworks(policyGetter getter.IPolicyGetter, frameworksNames string) ([]reporthandling.Framework, error) {
+	var names []string
+	if frameworksNames != "" && frameworksNames != "all" {
+		names = strings.Split(frameworksNames, ",")
+	} else {
+		names = listFrameworksNames(policyGetter)
+	}
+	frameworks := []reporthandling.Framework{}
+	for _, name := range names {
+		framework, err := policyGetter.GetFramework(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get framework '%s': %w", name, err)
+		}
+		if framework == nil {
+			return nil, fmt.Errorf("framework '%s' not found", name)
+		}
+		frameworks = append(frameworks, *framework)
+	}
+	return frameworks, nil
+}
+
 func prettyPrintListFormat(listPolicies *cliobjects.ListPolicies, policies []string) {
 	sep := "\n  * "
 	fmt.Printf("Supported %s:%s%s\n", listPolicies.Target, sep, strings.Join(policies, sep))
@@ -0,0 +1,139 @@
+package clihandler
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/armosec/k8s-interface/workloadinterface"
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/cautils/logger/helpers"
+	"github.com/armosec/kubescape/clihandler/cliobjects"
+	"github.com/armosec/kubescape/networkpolicyanalysis"
+	"github.com/armosec/kubescape/resultshandling/printer"
+	"github.com/olekukonko/tablewriter"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// networkPoliciesResources the workloads and the network policies listed from the cluster
+var networkPoliciesResources = append([]schema.GroupVersionResource{
+	{Group: "networking.k8s.io", Version: "v1", Resource: "networkpolicies"},
+}, sbomWorkloadResources...)
+
+// CliNetworkPolicies prints the coverage of the namespaces and of the workloads by the network policies
+func CliNetworkPolicies(networkPoliciesInfo *cliobjects.NetworkPolicies) error {
+	if networkPoliciesInfo.Format != printer.PrettyFormat && networkPoliciesInfo.Format != printer.JsonFormat {
+		return fmt.Errorf("format '%s' is not supported, supported formats: %s/%s", networkPoliciesInfo.Format, printer.PrettyFormat, printer.JsonFormat)
+	}
+	objects, err := listNetworkPoliciesObjects(networkPoliciesInfo)
+	if err != nil {
+		return err
+	}
+	coverage := networkpolicyanalysis.Analyze(objects)
+
+	switch networkPoliciesInfo.Format {
+	case printer.JsonFormat:
+		j, _ := json.MarshalIndent(coverage, "", "  ")
+		fmt.Printf("%s\n", j)
+	default:
+		prettyPrintNamespacesCoverage(coverage.Namespaces)
+		fmt.Println()
+		prettyPrintWorkloadsCoverage(coverage.Workloads)
+	}
+	return nil
+}
+
+// listNetworkPoliciesObjects loads the objects of the files, or lists the workloads, the network policies and the namespaces of the cluster
+func listNetworkPoliciesObjects(networkPoliciesInfo *cliobjects.NetworkPolicies) ([]workloadinterface.IMetadata, error) {
+	if len(networkPoliciesInfo.InputPatterns) > 0 {
+		objects, _, err := cautils.LoadResourcesFromFiles(networkPoliciesInfo.InputPatterns)
+		return objects, err
+	}
+
+	k8s := getKubernetesApi()
+	if k8s == nil {
+		return nil, fmt.Errorf("not connected to a cluster, set the files of the workloads and of the network policies")
+	}
+	resources := networkPoliciesResources
+	if networkPoliciesInfo.Namespace == "" {
+		resources = append([]schema.GroupVersionResource{{Version: "v1", Resource: "namespaces"}}, resources...)
+	}
+	objects := []workloadinterface.IMetadata{}
+	for i := range resources {
+		listed, err := k8s.ListWorkloads(&resources[i], networkPoliciesInfo.Namespace, nil, nil)
+		if err != nil {
+			logger.L().Warning("failed to list objects", helpers.String("resource", resources[i].Resource), helpers.Error(err))
+			continue
+		}
+		for j := range listed {
+			objects = append(objects, listed[j])
+		}
+	}
+	return objects, nil
+}
+
+func prettyPrintNamespacesCoverage(namespaces []networkpolicyanalysis.NamespaceCoverage) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"Namespace", "Workloads", "Ingress covered", "Egress covered", "Allows all ingress", "Allows all egress", "Default deny"})
+	table.SetHeaderLine(true)
+	for i := range namespaces {
+		namespace := &namespaces[i]
+		defaultDeny := []string{}
+		if namespace.DefaultDenyIngress {
+			defaultDeny = append(defaultDeny, "ingress")
+		}
+		if namespace.DefaultDenyEgress {
+			defaultDeny = append(defaultDeny, "egress")
+		}
+		table.Append([]string{
+			namespace.Namespace,
+			fmt.Sprintf("%d", namespace.Workloads),
+			fmt.Sprintf("%d/%d", namespace.IngressCovered, namespace.Workloads),
+			fmt.Sprintf("%d/%d", namespace.EgressCovered, namespace.Workloads),
+			allowsAll(namespace.AllowAllIngress, namespace.AllowAllIngressPolicies),
+			allowsAll(namespace.AllowAllEgress, namespace.AllowAllEgressPolicies),
+			strings.Join(defaultDeny, ", "),
+		})
+	}
+	table.Render()
+}
+
+// prettyPrintWorkloadsCoverage prints a row per workload, with the ports open to any source
+func prettyPrintWorkloadsCoverage(workloads []networkpolicyanalysis.WorkloadCoverage) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"Namespace", "Workload", "Ingress", "Egress", "Policies", "Ports open to any source"})
+	table.SetHeaderLine(true)
+	for i := range workloads {
+		workload := &workloads[i]
+		policies := map[string]bool{}
+		names := []string{}
+		for _, name := range append(append([]string{}, workload.IngressPolicies...), workload.EgressPolicies...) {
+			if !policies[name] {
+				policies[name] = true
+				names = append(names, name)
+			}
+		}
+		openPorts := []string{}
+		for j := range workload.Ports {
+			if status := workload.Ports[j].Status; status == networkpolicyanalysis.StatusUncovered || status == networkpolicyanalysis.StatusOpen {
+				openPorts = append(openPorts, workload.Ports[j].String())
+			}
+		}
+		table.Append([]string{workload.Namespace, workload.Kind + "/" + workload.Name, workload.Ingress, workload.Egress, strings.Join(names, ", "), strings.Join(openPorts, ", ")})
+	}
+	table.Render()
+}
+
+func allowsAll(allowed bool, policies []string) string {
+	switch {
+	case !allowed:
+		return "no"
+	case len(policies) == 0:
+		return "yes (no policy)"
+	}
+	return fmt.Sprintf("yes (%s)", strings.Join(policies, ", "))
+}
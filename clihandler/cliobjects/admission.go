@@ -0,0 +1,12 @@
+package cliobjects
+
+import "github.com/armosec/kubescape/cautils"
+
+type AdmissionServer struct {
+	Address           string
+	TLSCertFile       string
+	TLSKeyFile        string
+	Audit             bool   // allow the violating requests with warnings instead of denying them
+	SeverityThreshold string // deny the requests failing controls of this severity or above
+	ScanInfo          cautils.ScanInfo
+}
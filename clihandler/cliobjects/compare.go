@@ -0,0 +1,10 @@
+package cliobjects
+
+type Compare struct {
+	ResultsFiles     []string // the results files of the two clusters, when not scanning the clusters
+	Contexts         []string // the kube contexts of the two scanned clusters
+	Frameworks       string   // the frameworks the clusters are scanned by, all of the frameworks by default
+	UseArtifactsFrom string
+	Format           string
+	Output           string
+}
@@ -0,0 +1,7 @@
+package cliobjects
+
+type ConvertResults struct {
+	ResultsFile string
+	Format      string // one of the formats of the encoders, e.g. sarif/junit/html/pdf/csv
+	Output      string
+}
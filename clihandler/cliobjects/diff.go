@@ -0,0 +1,9 @@
+package cliobjects
+
+type Diff struct {
+	OldResults       string
+	NewResults       string
+	Format           string
+	Output           string
+	FailOnRegression bool
+}
@@ -0,0 +1,10 @@
+package cliobjects
+
+import "github.com/armosec/kubescape/exceptionshandler"
+
+type Exceptions struct {
+	File    string // the exceptions file, created when adding the first exception
+	Results string // a results file, for showing the failed controls suppressed by the exceptions
+	Name    string // the exception to remove
+	Options exceptionshandler.ExceptionOptions
+}
@@ -0,0 +1,11 @@
+package cliobjects
+
+type ExportPolicy struct {
+	Controls       []string
+	Format         string // gatekeeper/vap
+	Action         string // deny/warn/audit
+	Output         string
+	Account        string
+	UseFrom        []string
+	ControlsInputs string
+}
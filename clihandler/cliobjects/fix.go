@@ -0,0 +1,8 @@
+package cliobjects
+
+type Fix struct {
+	ResultsFile string
+	Values      map[string]string // the values of the fix paths which need a value, e.g. resources.limits.cpu=500m
+	Apply       bool
+	Output      string
+}
@@ -0,0 +1,12 @@
+package cliobjects
+
+type GenerateRBAC struct {
+	Frameworks     string   // the scanned frameworks, all of the frameworks by default
+	Name           string   // the name of the role and of the binding
+	Namespace      string   // generate a Role of scanning the namespace instead of a ClusterRole
+	ServiceAccount string   // <namespace>/<name> of the bound service account
+	HostSensor     bool     // include the permissions of deploying the host sensor
+	UseFrom        []string // load the frameworks from local files
+	Account        string
+	Output         string
+}
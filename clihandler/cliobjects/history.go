@@ -0,0 +1,9 @@
+package cliobjects
+
+type History struct {
+	StorePath   string
+	ClusterName string
+	Controls    []string
+	Limit       int
+	Format      string
+}
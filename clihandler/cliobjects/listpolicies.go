@@ -1,8 +1,11 @@
 package cliobjects
 
 type ListPolicies struct {
-	Target  string
-	ListIDs bool
-	Account string
-	Format  string
+	Target     string
+	ListIDs    bool
+	Account    string
+	Format     string
+	Frameworks string   // the frameworks of the listed resources, all of the frameworks by default
+	UseFrom    []string // load the frameworks of the listed resources from local files
+	Count      bool     // count the objects of the listed resources in the cluster
 }
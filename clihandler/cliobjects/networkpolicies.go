@@ -0,0 +1,7 @@
+package cliobjects
+
+type NetworkPolicies struct {
+	InputPatterns []string // scanned files, the objects of the cluster when empty
+	Namespace     string   // namespace of the objects of the cluster, all of the namespaces when empty
+	Format        string
+}
@@ -0,0 +1,14 @@
+package cliobjects
+
+import (
+	"time"
+
+	"github.com/armosec/kubescape/cautils"
+)
+
+type Operator struct {
+	Namespace    string // namespace of the watched schedules, all of the namespaces when empty
+	InstallCRDs  bool
+	SyncInterval time.Duration
+	ScanInfo     cautils.ScanInfo
+}
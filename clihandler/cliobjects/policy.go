@@ -0,0 +1,7 @@
+package cliobjects
+
+type PolicyTest struct {
+	Tests          []string // test files, or directories of test files
+	CustomControls string   // directory of user-authored controls, see 'kubescape scan --custom-controls'
+	UseFrom        []string // framework files, the built-in controls to test
+}
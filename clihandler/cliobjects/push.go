@@ -0,0 +1,6 @@
+package cliobjects
+
+type Push struct {
+	Reference string   // the policy bundle reference, oci://<registry>/<repository>:<tag>
+	Files     []string // the artifacts files, the JSON files of the directories
+}
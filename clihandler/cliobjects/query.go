@@ -0,0 +1,8 @@
+package cliobjects
+
+type Query struct {
+	ResultsFile string
+	Filter      string
+	Compact     bool
+	RawOutput   bool
+}
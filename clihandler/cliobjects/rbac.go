@@ -0,0 +1,14 @@
+package cliobjects
+
+type RBAC struct {
+	Format        string
+	IncludeSystem bool // report the grants to the system subjects and by the system bindings
+}
+
+type RBACWhoCan struct {
+	Verb      string
+	Resource  string // <resource>[.<group>][/<subresource>], or a non-resource URL
+	Name      string // name of the resource object
+	Namespace string // all of the namespaces when empty
+	Format    string
+}
@@ -0,0 +1,10 @@
+package cliobjects
+
+type SBOM struct {
+	InputPatterns []string // scanned files, the workloads of the cluster when empty
+	Namespace     string   // namespace of the workloads of the cluster, all of the namespaces when empty
+	Format        string   // spdx, cyclonedx
+	OutputDir     string
+	ScannerPath   string // path of the trivy binary
+	Offline       bool
+}
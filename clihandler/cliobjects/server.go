@@ -0,0 +1,15 @@
+package cliobjects
+
+import "github.com/armosec/kubescape/cautils"
+
+type Server struct {
+	Address      string
+	GRPCAddress  string // the gRPC API is served when set
+	HistoryLimit int
+
+	Token           string // the bearer token of the requests, the requests are not authenticated when empty
+	TLSCertFile     string // the APIs are served over TLS when set, with the TLS key file
+	TLSKeyFile      string
+	TLSClientCAFile string           // the client certificates are required and verified by the certificate authorities of the file (mTLS) when set
+	ScanInfo        cautils.ScanInfo // the base configuration of the scans, the scan requests set the frameworks/controls and namespaces
+}
@@ -5,3 +5,10 @@ type SetConfig struct {
 	ClientID  string
 	SecretKey string
 }
+
+type SetControlInput struct {
+	File   string   // the controls inputs file, initialized with the default inputs when missing
+	Key    string   // the name of the input, e.g. imageRepositoryAllowList
+	Values []string // the values of the input
+	Append bool     // append the values to the input instead of replacing them
+}
@@ -0,0 +1,6 @@
+package cliobjects
+
+type TUI struct {
+	ResultsFile      string
+	ExceptionsOutput string // the exceptions of the marked resources are added to the file
+}
@@ -0,0 +1,6 @@
+package cliobjects
+
+type ValidateResults struct {
+	ResultsFile string
+	PrintSchema bool // print the JSON schema of the results instead of validating a results file
+}
@@ -0,0 +1,6 @@
+package cliobjects
+
+type ViewResults struct {
+	ResultsFile string
+	Address     string // the address the results are served on
+}
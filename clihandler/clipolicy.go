@@ -0,0 +1,110 @@
+package clihandler
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/cautils/getter"
+	"github.com/armosec/kubescape/clihandler/cliobjects"
+	"github.com/armosec/kubescape/policylint"
+	"github.com/armosec/kubescape/policytest"
+	"github.com/armosec/opa-utils/reporthandling"
+)
+
+// CliPolicyTest runs the tests of the controls on their fixtures, returns an error when a test fails
+func CliPolicyTest(policyTestInfo *cliobjects.PolicyTest) error {
+	controls, err := loadTestedControls(policyTestInfo)
+	if err != nil {
+		return err
+	}
+	testFiles, err := policytest.LoadTestFiles(policyTestInfo.Tests)
+	if err != nil {
+		return err
+	}
+	results := policytest.Run(testFiles, controls)
+	if failed := printTestResults(os.Stdout, results); failed > 0 {
+		return fmt.Errorf("%d of %d tests failed", failed, len(results))
+	}
+	return nil
+}
+
+func loadTestedControls(policyTestInfo *cliobjects.PolicyTest) ([]reporthandling.Control, error) {
+	if policyTestInfo.CustomControls == "" && len(policyTestInfo.UseFrom) == 0 {
+		return nil, fmt.Errorf("missing the tested controls, set --custom-controls and/or --use-from")
+	}
+	controls := []reporthandling.Control{}
+	if policyTestInfo.CustomControls != "" {
+		customControls, err := getter.LoadCustomControls(policyTestInfo.CustomControls)
+		if err != nil {
+			return nil, err
+		}
+		controls = append(controls, customControls...)
+	}
+	for _, filePath := range policyTestInfo.UseFrom {
+		framework, err := getter.NewLoadPolicy([]string{filePath}).GetFramework("")
+		if err != nil {
+			return nil, fmt.Errorf("failed to load framework '%s': %w", filePath, err)
+		}
+		controls = append(controls, framework.Controls...)
+	}
+	return controls, nil
+}
+
+// printTestResults prints a line per test, returns the number of failed tests
+func printTestResults(writer io.Writer, results []policytest.TestResult) int {
+	failed := 0
+	for i := range results {
+		result := &results[i]
+		if result.Passed() {
+			cautils.SuccessDisplay(writer, "PASS ")
+			cautils.SimpleDisplay(writer, "%s %s\n", result.Control, result.Name)
+			continue
+		}
+		failed++
+		cautils.FailureDisplay(writer, "FAIL ")
+		cautils.SimpleDisplay(writer, "%s %s (%s)\n", result.Control, result.Name, result.File)
+		switch {
+		case result.Error != nil:
+			cautils.FailureTextDisplay(writer, "     %s\n", result.Error.Error())
+		default:
+			message := fmt.Sprintf("expected %s, received %s", result.Expected, result.Status)
+			if len(result.FailedResources) > 0 {
+				message += fmt.Sprintf(" (failed resources: %s)", strings.Join(result.FailedResources, ", "))
+			}
+			cautils.FailureTextDisplay(writer, "     %s\n", message)
+		}
+	}
+	cautils.SimpleDisplay(writer, "\n%d tests, %d passed, %d failed\n", len(results), len(results)-failed, failed)
+	return failed
+}
+
+// CliPolicyLint lints the user-authored controls of the directories, returns an error when a control has errors
+func CliPolicyLint(dirs []string) error {
+	findings := []policylint.Finding{}
+	for _, dir := range dirs {
+		f, err := policylint.LintDirectory(dir)
+		if err != nil {
+			return err
+		}
+		findings = append(findings, f...)
+	}
+	errors := 0
+	for i := range findings {
+		switch findings[i].Level {
+		case policylint.LevelError:
+			errors++
+			cautils.FailureDisplay(os.Stdout, "ERROR ")
+		default:
+			cautils.WarningDisplay(os.Stdout, "WARNING ")
+		}
+		cautils.SimpleDisplay(os.Stdout, "%s\n", findings[i].String())
+	}
+	cautils.SimpleDisplay(os.Stdout, "\n%d errors, %d warnings\n", errors, len(findings)-errors)
+	if errors > 0 {
+		return fmt.Errorf("the controls have %d errors", errors)
+	}
+	return nil
+}
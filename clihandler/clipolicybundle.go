@@ -0,0 +1,165 @@
+package clihandler
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/cautils/getter"
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/cautils/logger/helpers"
+	"github.com/armosec/kubescape/clihandler/cliobjects"
+	"github.com/armosec/opa-utils/reporthandling"
+)
+
+// CliPush pushes the artifacts files (e.g. of 'kubescape download artifacts') as a policy bundle to an OCI registry
+func CliPush(pushInfo *cliobjects.Push) error {
+	filePaths := []string{}
+	for _, p := range pushInfo.Files {
+		info, err := os.Stat(p)
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			filePaths = append(filePaths, p)
+			continue
+		}
+		entries, err := os.ReadDir(p)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() && strings.EqualFold(filepath.Ext(entry.Name()), ".json") {
+				filePaths = append(filePaths, filepath.Join(p, entry.Name()))
+			}
+		}
+	}
+	if len(filePaths) == 0 {
+		return fmt.Errorf("no artifacts to push")
+	}
+	manifestDigest, err := getter.NewOCIRegistryClient().Push(pushInfo.Reference, filePaths)
+	if err != nil {
+		return err
+	}
+	logger.L().Success("Pushed policy bundle", helpers.String("reference", pushInfo.Reference), helpers.String("digest", manifestDigest), helpers.Int("artifacts", len(filePaths)))
+	return nil
+}
+
+// downloadFromPolicyBundle downloads the artifacts of a policy bundle pulled from an OCI registry
+func downloadFromPolicyBundle(downloadInfo *cautils.DownloadInfo) error {
+	dir, err := os.MkdirTemp("", "kubescape-bundle")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	filePaths, err := getter.NewOCIRegistryClient().Pull(downloadInfo.From, dir)
+	if err != nil {
+		return err
+	}
+
+	switch downloadInfo.Target {
+	case "artifacts":
+		for _, filePath := range filePaths {
+			if err := copyBundleFile(downloadInfo, filePath, filepath.Base(filePath)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "controls-inputs", "exceptions":
+		for _, filePath := range filePaths {
+			if filepath.Base(filePath) == downloadInfo.Target+".json" {
+				fileName := downloadInfo.FileName
+				if fileName == "" {
+					fileName = filepath.Base(filePath)
+				}
+				return copyBundleFile(downloadInfo, filePath, fileName)
+			}
+		}
+		return fmt.Errorf("the policy bundle '%s' has no %s.json", downloadInfo.From, downloadInfo.Target)
+	}
+
+	frameworks := loadBundleFrameworks(filePaths)
+	switch downloadInfo.Target {
+	case "framework":
+		found := false
+		for i := range frameworks {
+			if downloadInfo.Name != "" && !strings.EqualFold(frameworks[i].Name, downloadInfo.Name) {
+				continue
+			}
+			found = true
+			fileName := strings.ToLower(frameworks[i].Name) + ".json"
+			if downloadInfo.Name != "" && downloadInfo.FileName != "" {
+				fileName = downloadInfo.FileName
+			}
+			if err := saveBundleArtifact(downloadInfo, frameworks[i].Name, &frameworks[i], fileName); err != nil {
+				return err
+			}
+		}
+		if !found {
+			return fmt.Errorf("framework '%s' not found in the policy bundle '%s'", downloadInfo.Name, downloadInfo.From)
+		}
+		return nil
+	case "control":
+		if downloadInfo.Name == "" {
+			return fmt.Errorf("missing control name")
+		}
+		for i := range frameworks {
+			for j := range frameworks[i].Controls {
+				control := &frameworks[i].Controls[j]
+				if strings.EqualFold(control.ControlID, downloadInfo.Name) || strings.EqualFold(control.Name, downloadInfo.Name) {
+					fileName := downloadInfo.FileName
+					if fileName == "" {
+						fileName = fmt.Sprintf("%s.json", downloadInfo.Name)
+					}
+					return saveBundleArtifact(downloadInfo, downloadInfo.Name, control, fileName)
+				}
+			}
+		}
+		return fmt.Errorf("control '%s' not found in the frameworks of the policy bundle '%s'", downloadInfo.Name, downloadInfo.From)
+	}
+	return fmt.Errorf("unknown command to download")
+}
+
+// loadBundleFrameworks returns the frameworks of the files of a policy bundle, the other artifacts are skipped
+func loadBundleFrameworks(filePaths []string) []reporthandling.Framework {
+	frameworks := []reporthandling.Framework{}
+	for _, filePath := range filePaths {
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			continue
+		}
+		framework := reporthandling.Framework{}
+		if err := json.Unmarshal(data, &framework); err == nil && framework.Name != "" && (len(framework.Controls) > 0 || framework.ControlsIDs != nil) {
+			frameworks = append(frameworks, framework)
+		}
+	}
+	return frameworks
+}
+
+func saveBundleArtifact(downloadInfo *cautils.DownloadInfo, name string, artifact interface{}, fileName string) error {
+	downloadTo := filepath.Join(downloadInfo.Path, fileName)
+	if err := getter.SaveInFile(artifact, downloadTo); err != nil {
+		return err
+	}
+	logger.L().Success("Downloaded", helpers.String("artifact", downloadInfo.Target), helpers.String("name", name), helpers.String("path", downloadTo))
+	return nil
+}
+
+func copyBundleFile(downloadInfo *cautils.DownloadInfo, filePath, fileName string) error {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(downloadInfo.Path, 0755); err != nil {
+		return err
+	}
+	downloadTo := filepath.Join(downloadInfo.Path, fileName)
+	if err := os.WriteFile(downloadTo, data, 0644); err != nil {
+		return err
+	}
+	logger.L().Success("Downloaded", helpers.String("artifact", downloadInfo.Target), helpers.String("name", fileName), helpers.String("path", downloadTo))
+	return nil
+}
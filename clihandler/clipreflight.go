@@ -0,0 +1,98 @@
+package clihandler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/cautils/getter"
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/cautils/logger/helpers"
+	"github.com/armosec/kubescape/resourcehandler"
+	"github.com/armosec/opa-utils/reporthandling"
+)
+
+// preflight checks the permissions of the scan on the resources required by the controls, without scanning. Returns an error
+// when permissions are missing, instead of failing to list the resources midway of the scan
+func preflight(ctx context.Context, scanInfo *cautils.ScanInfo) error {
+	if scanInfo.GetScanningEnvironment() != cautils.ScanCluster {
+		return fmt.Errorf("bad argument: '--preflight' checks the permissions of the cluster scans only")
+	}
+	k8s := getKubernetesApi()
+	if k8s == nil {
+		return fmt.Errorf("failed connecting to Kubernetes cluster")
+	}
+	tenantConfig := getTenantConfig(scanInfo.Account, scanInfo.KubeContext, k8s)
+	setPolicyGetters(scanInfo, tenantConfig.GetAccountID())
+	if scanInfo.ScanAll {
+		scanInfo.SetPolicyIdentifiers(listFrameworksNames(scanInfo.Getters.PolicyGetter), reporthandling.KindFramework)
+	}
+	frameworks, err := getScanFrameworks(scanInfo)
+	if err != nil {
+		return err
+	}
+
+	resources := resourcehandler.ListRequiredResources(frameworks)
+	hostSensor := scanInfo.HostSensorEnabled.Get() != nil && *scanInfo.HostSensorEnabled.Get()
+	rules := resourcehandler.ScannerPolicyRules(resources, false, hostSensor)
+	namespaces := []string{}
+	for _, namespace := range strings.Split(scanInfo.IncludeNamespaces, ",") {
+		if namespace = strings.TrimSpace(namespace); namespace != "" && !strings.Contains(namespace, "*") {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+	missing, err := resourcehandler.CheckPermissions(ctx, k8s.KubernetesClient.AuthorizationV1().SelfSubjectAccessReviews(), rules, namespaces)
+	if err != nil {
+		return err
+	}
+	if len(missing) == 0 {
+		logger.L().Success("All of the permissions of the scan are granted", helpers.Int("resources", len(resources)))
+		return nil
+	}
+	for i := range missing {
+		logger.L().Error("missing permission", helpers.String("permission", missing[i].String()))
+	}
+	return fmt.Errorf("%d permissions of the scan are missing. Generate the role of the scan by 'kubescape config generate-rbac'", len(missing))
+}
+
+// getScanFrameworks returns the frameworks of the policy identifiers of the scan, the scanned controls as a single framework,
+// with the user-authored controls
+func getScanFrameworks(scanInfo *cautils.ScanInfo) ([]reporthandling.Framework, error) {
+	frameworks := []reporthandling.Framework{}
+	controls := reporthandling.Framework{}
+	for _, policy := range scanInfo.PolicyIdentifier {
+		switch policy.Kind {
+		case reporthandling.KindFramework:
+			framework, err := scanInfo.Getters.PolicyGetter.GetFramework(policy.Name)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get framework '%s': %w", policy.Name, err)
+			}
+			if framework != nil {
+				frameworks = append(frameworks, *framework)
+			}
+		case reporthandling.KindControl:
+			control, err := scanInfo.Getters.PolicyGetter.GetControl(policy.Name)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get control '%s': %w", policy.Name, err)
+			}
+			if control != nil {
+				controls.Controls = append(controls.Controls, *control)
+			}
+		}
+	}
+	if len(controls.Controls) > 0 {
+		frameworks = append(frameworks, controls)
+	}
+	if scanInfo.CustomControls != "" {
+		customControls, err := getter.LoadCustomControls(scanInfo.CustomControls)
+		if err != nil {
+			return nil, err
+		}
+		frameworks = append(frameworks, *getter.NewCustomControlsFramework(customControls))
+	}
+	if len(frameworks) == 0 {
+		return nil, fmt.Errorf("no policies to check the permissions of")
+	}
+	return frameworks, nil
+}
@@ -0,0 +1,50 @@
+package clihandler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/armosec/kubescape/clihandler/cliobjects"
+	"github.com/armosec/kubescape/resultshandling/query"
+)
+
+func CliQuery(queryInfo *cliobjects.Query) error {
+	var data []byte
+	var err error
+	if queryInfo.ResultsFile == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(queryInfo.ResultsFile)
+	}
+	if err != nil {
+		return err
+	}
+
+	document, err := query.NewDocument(data)
+	if err != nil {
+		return err
+	}
+	results, err := query.Query(document, queryInfo.Filter)
+	// print the results of the filter, even when it failed in the middle
+	for _, result := range results {
+		printQueryResult(os.Stdout, result, queryInfo)
+	}
+	return err
+}
+
+// printQueryResult prints the result in json, strings are printed as is when using the raw output (as 'jq -r')
+func printQueryResult(w io.Writer, result interface{}, queryInfo *cliobjects.Query) {
+	if s, ok := result.(string); ok && queryInfo.RawOutput {
+		fmt.Fprintln(w, s)
+		return
+	}
+	var j []byte
+	if queryInfo.Compact {
+		j, _ = json.Marshal(result)
+	} else {
+		j, _ = json.MarshalIndent(result, "", "  ")
+	}
+	fmt.Fprintf(w, "%s\n", j)
+}
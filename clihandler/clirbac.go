@@ -0,0 +1,121 @@
+package clihandler
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/armosec/k8s-interface/k8sinterface"
+	"github.com/armosec/kubescape/clihandler/cliobjects"
+	"github.com/armosec/kubescape/rbacanalysis"
+	"github.com/armosec/kubescape/resultshandling/printer"
+	"github.com/olekukonko/tablewriter"
+)
+
+type rbacObject struct {
+	Subjects []rbacSubject       `json:"subjects"`
+	Risks    []rbacanalysis.Risk `json:"risks"`
+}
+
+type rbacSubject struct {
+	rbacanalysis.Subject
+	Permissions []rbacanalysis.Permission `json:"permissions"`
+}
+
+// CliRBAC prints the risky grants of the RBAC of the cluster, and the permissions of the subjects in json
+func CliRBAC(rbacInfo *cliobjects.RBAC) error {
+	if err := validateRBACFormat(rbacInfo.Format); err != nil {
+		return err
+	}
+	graph, err := loadRBACGraph()
+	if err != nil {
+		return err
+	}
+	risks := graph.Risks(rbacInfo.IncludeSystem)
+
+	switch rbacInfo.Format {
+	case printer.JsonFormat:
+		rbac := rbacObject{Subjects: []rbacSubject{}, Risks: risks}
+		for _, subject := range graph.Subjects() {
+			rbac.Subjects = append(rbac.Subjects, rbacSubject{Subject: subject, Permissions: graph.Permissions(subject)})
+		}
+		j, _ := json.MarshalIndent(rbac, "", "  ")
+		fmt.Printf("%s\n", j)
+	default:
+		if len(risks) == 0 {
+			fmt.Println("No risky grants found")
+			return nil
+		}
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetAutoWrapText(false)
+		table.SetHeader([]string{"Severity", "Risk", "Subject", "Role", "Binding", "Description"})
+		table.SetHeaderLine(true)
+		for i := range risks {
+			table.Append([]string{risks[i].Severity, risks[i].Type, risks[i].Subject.String(), risks[i].Role.String(), risks[i].Binding.String(), risks[i].Description})
+		}
+		table.Render()
+	}
+	return nil
+}
+
+// CliRBACWhoCan prints the subjects allowed to perform the action, with the roles and the bindings granting it
+func CliRBACWhoCan(whoCanInfo *cliobjects.RBACWhoCan) error {
+	if err := validateRBACFormat(whoCanInfo.Format); err != nil {
+		return err
+	}
+	query, err := rbacanalysis.NewQuery(whoCanInfo.Verb, whoCanInfo.Resource, whoCanInfo.Name, whoCanInfo.Namespace)
+	if err != nil {
+		return err
+	}
+	graph, err := loadRBACGraph()
+	if err != nil {
+		return err
+	}
+	permissions := graph.WhoCan(query)
+
+	switch whoCanInfo.Format {
+	case printer.JsonFormat:
+		j, _ := json.MarshalIndent(permissions, "", "  ")
+		fmt.Printf("%s\n", j)
+	default:
+		if len(permissions) == 0 {
+			fmt.Printf("No subject can %s %s\n", whoCanInfo.Verb, whoCanInfo.Resource)
+			return nil
+		}
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetAutoWrapText(false)
+		table.SetHeader([]string{"Subject", "Scope", "Role", "Binding"})
+		table.SetHeaderLine(true)
+		for i := range permissions {
+			scope := "cluster"
+			if permissions[i].Namespace != "" {
+				scope = "namespace " + permissions[i].Namespace
+			}
+			if len(permissions[i].Rule.ResourceNames) != 0 {
+				scope += " (" + strings.Join(permissions[i].Rule.ResourceNames, ", ") + ")"
+			}
+			table.Append([]string{permissions[i].Subject.String(), scope, permissions[i].Role.String(), permissions[i].Binding.String()})
+		}
+		table.Render()
+	}
+	return nil
+}
+
+func validateRBACFormat(format string) error {
+	if format != printer.PrettyFormat && format != printer.JsonFormat {
+		return fmt.Errorf("format '%s' is not supported, supported formats: %s/%s", format, printer.PrettyFormat, printer.JsonFormat)
+	}
+	return nil
+}
+
+func loadRBACGraph() (*rbacanalysis.Graph, error) {
+	if !k8sinterface.IsConnectedToCluster() {
+		return nil, fmt.Errorf("failed to connect to the cluster, the RBAC analysis lists the roles and the bindings of the cluster")
+	}
+	objects, err := rbacanalysis.ListObjects(k8sinterface.NewKubernetesApi().KubernetesClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the RBAC objects: %w", err)
+	}
+	return rbacanalysis.NewGraph(objects), nil
+}
@@ -0,0 +1,99 @@
+package clihandler
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/armosec/k8s-interface/workloadinterface"
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/cautils/logger/helpers"
+	"github.com/armosec/kubescape/clihandler/cliobjects"
+	"github.com/armosec/kubescape/sbom"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// sbomIndexFile the references of the generated SBOMs, written in the output directory
+const sbomIndexFile = "index.json"
+
+// sbomWorkloadResources the workloads listed from the cluster, the owned workloads (e.g. the pods of a deployment) are dropped
+var sbomWorkloadResources = []schema.GroupVersionResource{
+	{Version: "v1", Resource: "pods"},
+	{Group: "apps", Version: "v1", Resource: "deployments"},
+	{Group: "apps", Version: "v1", Resource: "statefulsets"},
+	{Group: "apps", Version: "v1", Resource: "daemonsets"},
+	{Group: "apps", Version: "v1", Resource: "replicasets"},
+	{Group: "batch", Version: "v1", Resource: "jobs"},
+	{Group: "batch", Version: "v1", Resource: "cronjobs"},
+}
+
+func CliSBOM(sbomInfo *cliobjects.SBOM) error {
+	if err := sbom.ValidateFormat(sbomInfo.Format); err != nil {
+		return err
+	}
+	resources, err := listSBOMWorkloads(sbomInfo)
+	if err != nil {
+		return err
+	}
+	imagesWorkloads := cautils.ImagesWorkloads(resources)
+	if len(imagesWorkloads) == 0 {
+		return fmt.Errorf("no images found")
+	}
+
+	generator := sbom.NewTrivyGenerator(sbomInfo.ScannerPath, sbomInfo.Offline)
+	references, err := sbom.GenerateSBOMs(generator, imagesWorkloads, sbomInfo.Format, sbomInfo.OutputDir)
+	if err != nil {
+		return err
+	}
+	index, err := json.MarshalIndent(references, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(sbomInfo.OutputDir, sbomIndexFile), index, 0644); err != nil {
+		return err
+	}
+	for i := range references {
+		fmt.Printf("%s\t%s\n", references[i].Image, references[i].Path)
+	}
+	logger.L().Success("Generated the SBOMs", helpers.Int("sboms", len(references)), helpers.Int("images", len(imagesWorkloads)), helpers.String("directory", sbomInfo.OutputDir))
+	if len(references) < len(imagesWorkloads) {
+		return fmt.Errorf("failed to generate %d of the %d SBOMs", len(imagesWorkloads)-len(references), len(imagesWorkloads))
+	}
+	return nil
+}
+
+// listSBOMWorkloads loads the workloads of the files, or lists the workloads of the cluster, map[<resource ID>]<workload>
+func listSBOMWorkloads(sbomInfo *cliobjects.SBOM) (map[string]workloadinterface.IMetadata, error) {
+	resources := map[string]workloadinterface.IMetadata{}
+	if len(sbomInfo.InputPatterns) > 0 {
+		workloads, _, err := cautils.LoadResourcesFromFiles(sbomInfo.InputPatterns)
+		if err != nil {
+			return nil, err
+		}
+		for i := range workloads {
+			resources[workloads[i].GetID()] = workloads[i]
+		}
+		return resources, nil
+	}
+
+	k8s := getKubernetesApi()
+	if k8s == nil {
+		return nil, fmt.Errorf("not connected to a cluster, set the files of the workloads")
+	}
+	for i := range sbomWorkloadResources {
+		workloads, err := k8s.ListWorkloads(&sbomWorkloadResources[i], sbomInfo.Namespace, nil, nil)
+		if err != nil {
+			logger.L().Warning("failed to list workloads", helpers.String("resource", sbomWorkloadResources[i].Resource), helpers.Error(err))
+			continue
+		}
+		for j := range workloads {
+			if owners, err := workloads[j].GetOwnerReferences(); err == nil && len(owners) > 0 {
+				continue
+			}
+			resources[workloads[j].GetID()] = workloads[j]
+		}
+	}
+	return resources, nil
+}
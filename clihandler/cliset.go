@@ -1,6 +1,13 @@
 package clihandler
 
 import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/armosec/kubescape/cautils/getter"
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/cautils/logger/helpers"
 	"github.com/armosec/kubescape/clihandler/cliobjects"
 )
 
@@ -20,3 +27,34 @@ func CliSetConfig(setConfig *cliobjects.SetConfig) error {
 
 	return tenant.UpdateCachedConfig()
 }
+
+// CliSetControlInput sets a control input in the controls inputs file, the file is initialized with the default inputs when missing
+func CliSetControlInput(setControlInput *cliobjects.SetControlInput) error {
+	if err := getter.ValidateControlInputKey(setControlInput.Key); err != nil {
+		return err
+	}
+	controlsInputs := map[string][]string{}
+	if _, err := os.Stat(setControlInput.File); err == nil {
+		if controlsInputs, err = getter.NewLoadPolicy([]string{setControlInput.File}).GetControlsInputs(""); err != nil {
+			return fmt.Errorf("failed to load the controls inputs from '%s': %w", setControlInput.File, err)
+		}
+	} else {
+		defaultInputs, err := getConfigInputsGetter("", "", nil).GetControlsInputs("")
+		if err != nil {
+			logger.L().Warning("failed to get the default controls inputs, the file is created with the set input only", helpers.Error(err))
+		} else if defaultInputs != nil {
+			controlsInputs = defaultInputs
+		}
+	}
+	if _, ok := controlsInputs[setControlInput.Key]; !ok && len(controlsInputs) > 0 {
+		logger.L().Warning("the input is not one of the inputs of the controls", helpers.String("input", setControlInput.Key), helpers.String("inputs", strings.Join(getter.ListControlsInputsKeys(controlsInputs), ", ")))
+	}
+
+	getter.SetControlInput(controlsInputs, setControlInput.Key, setControlInput.Values, setControlInput.Append)
+	if err := getter.SaveControlsInputs(setControlInput.File, controlsInputs); err != nil {
+		return err
+	}
+	logger.L().Success("Control input set", helpers.String("input", setControlInput.Key), helpers.String("values", strings.Join(controlsInputs[setControlInput.Key], ", ")), helpers.String("path", setControlInput.File))
+	logger.L().Info(fmt.Sprintf("Scan with the inputs: kubescape scan --controls-config %s", setControlInput.File))
+	return nil
+}
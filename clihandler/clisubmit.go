@@ -6,9 +6,15 @@ import (
 	"github.com/armosec/kubescape/cautils/logger"
 	"github.com/armosec/kubescape/cautils/logger/helpers"
 	"github.com/armosec/kubescape/clihandler/cliinterfaces"
+	"github.com/armosec/kubescape/resultshandling/reporter"
 )
 
 func Submit(submitInterfaces cliinterfaces.SubmitInterfaces) error {
+	ctx, stop := interruptContext()
+	defer stop()
+	if r, ok := submitInterfaces.Reporter.(reporter.IContextReport); ok {
+		r.SetContext(ctx)
+	}
 
 	// list resources
 	postureReport, err := submitInterfaces.SubmitObjects.SetResourcesReport()
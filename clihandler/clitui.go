@@ -0,0 +1,16 @@
+package clihandler
+
+import (
+	"github.com/armosec/kubescape/clihandler/cliobjects"
+	"github.com/armosec/kubescape/resultshandling"
+	"github.com/armosec/kubescape/tuihandler"
+)
+
+// CliTUI browses the results of a results file in the terminal - frameworks, controls, failed resources and their objects
+func CliTUI(tuiInfo *cliobjects.TUI) error {
+	report, sources, err := resultshandling.LoadResults(tuiInfo.ResultsFile)
+	if err != nil {
+		return err
+	}
+	return tuihandler.Browse(tuihandler.NewReportBrowser(report, sources), tuiInfo.ExceptionsOutput)
+}
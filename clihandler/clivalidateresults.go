@@ -0,0 +1,33 @@
+package clihandler
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/clihandler/cliobjects"
+	printerv2 "github.com/armosec/kubescape/resultshandling/printer/v2"
+)
+
+// CliValidateResults validates a results file against the JSON schema of the json format (v2), or prints the schema
+func CliValidateResults(validateResultsInfo *cliobjects.ValidateResults) error {
+	if validateResultsInfo.PrintSchema {
+		_, err := os.Stdout.Write(printerv2.ResultsSchema())
+		return err
+	}
+
+	results, err := os.ReadFile(validateResultsInfo.ResultsFile)
+	if err != nil {
+		return fmt.Errorf("failed to read the results file: %w", err)
+	}
+	violations, err := printerv2.ValidateResults(results)
+	if err != nil {
+		return err
+	}
+	if len(violations) > 0 {
+		return fmt.Errorf("%s does not match the schema of the results (%d errors):\n  %s", validateResultsInfo.ResultsFile, len(violations), strings.Join(violations, "\n  "))
+	}
+	logger.L().Success(fmt.Sprintf("%s matches the schema of the results", validateResultsInfo.ResultsFile))
+	return nil
+}
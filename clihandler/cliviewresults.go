@@ -0,0 +1,16 @@
+package clihandler
+
+import (
+	"github.com/armosec/kubescape/clihandler/cliobjects"
+	"github.com/armosec/kubescape/resultshandling"
+	printerv2 "github.com/armosec/kubescape/resultshandling/printer/v2"
+)
+
+// CliViewResults serves the html report of a results file in a local web server and opens it in the browser
+func CliViewResults(viewResultsInfo *cliobjects.ViewResults) error {
+	report, sources, err := resultshandling.LoadResults(viewResultsInfo.ResultsFile)
+	if err != nil {
+		return err
+	}
+	return serveReport(printerv2.NewReportSession(report, sources), viewResultsInfo.Address)
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/clihandler"
+	"github.com/armosec/kubescape/clihandler/cliobjects"
+	"github.com/armosec/opa-utils/reporthandling"
+	"github.com/spf13/cobra"
+)
+
+var (
+	admissionServerExample = `
+  # Deny the workloads failing high and critical controls of the NSA framework
+  kubescape admission-server --frameworks nsa --severity-threshold high --tls-cert-file tls.crt --tls-key-file tls.key
+
+  # Allow the workloads failing specific controls with warnings (audit mode)
+  kubescape admission-server --controls C-0016,C-0017 --audit --tls-cert-file tls.crt --tls-key-file tls.key
+
+  The ValidatingWebhookConfiguration should call the '/validate' path of the server
+`
+)
+var admissionServerInfo = cliobjects.AdmissionServer{}
+var admissionControls, admissionFrameworks []string
+
+var admissionServerCmd = &cobra.Command{
+	Use:     "admission-server [flags]",
+	Short:   "Serve a validating admission webhook denying (or warning about) the resources failing controls",
+	Long:    ``,
+	Example: admissionServerExample,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(admissionControls) > 0 && len(admissionFrameworks) > 0 {
+			logger.L().Fatal("you can use `controls` or `frameworks`, but not both")
+		}
+		scanInfo := &admissionServerInfo.ScanInfo
+		if len(admissionFrameworks) > 0 {
+			scanInfo.FrameworkScan = true
+			scanInfo.SetPolicyIdentifiers(admissionFrameworks, reporthandling.KindFramework)
+		} else {
+			scanInfo.SetPolicyIdentifiers(admissionControls, reporthandling.KindControl)
+		}
+		scanInfo.Init()
+		cautils.SetSilentMode(true) // in-cluster, no terminal for the progress bars
+		if err := clihandler.CliAdmissionServer(&admissionServerInfo); err != nil {
+			logger.L().Fatal(err.Error())
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(admissionServerCmd)
+	admissionServerCmd.PersistentFlags().StringVar(&admissionServerInfo.Address, "address", ":8443", "Address of the webhook server")
+	admissionServerCmd.PersistentFlags().StringVar(&admissionServerInfo.TLSCertFile, "tls-cert-file", "", "Path to the TLS certificate of the webhook server")
+	admissionServerCmd.PersistentFlags().StringVar(&admissionServerInfo.TLSKeyFile, "tls-key-file", "", "Path to the TLS private key of the webhook server")
+	admissionServerCmd.PersistentFlags().BoolVar(&admissionServerInfo.Audit, "audit", false, "Allow the violating resources with warnings instead of denying them")
+	admissionServerCmd.PersistentFlags().StringVar(&admissionServerInfo.SeverityThreshold, "severity-threshold", "", "Deny the resources failing controls of this severity or above. Supported: low/medium/high/critical. Default: all of the failed controls")
+	admissionServerCmd.PersistentFlags().StringSliceVar(&admissionControls, "controls", []string{}, "Control IDs to evaluate, e.g. --controls C-0016,C-0017")
+	admissionServerCmd.PersistentFlags().StringSliceVar(&admissionFrameworks, "frameworks", []string{}, "Frameworks to evaluate, e.g. --frameworks nsa")
+	admissionServerCmd.PersistentFlags().StringVarP(&admissionServerInfo.ScanInfo.Account, "account", "", "", "Armo portal account ID. Default will load account ID from configMap or config file")
+	admissionServerCmd.PersistentFlags().StringVar(&admissionServerInfo.ScanInfo.ControlsInputs, "controls-config", "", "Path to an controls-config obj. If not set will download controls-config from ARMO management portal")
+	admissionServerCmd.PersistentFlags().StringVar(&admissionServerInfo.ScanInfo.UseExceptions, "exceptions", "", "Path to an exceptions obj. If not set will download exceptions from ARMO management portal")
+	admissionServerCmd.PersistentFlags().StringSliceVar(&admissionServerInfo.ScanInfo.UseFrom, "use-from", nil, "Load local policy object from specified path. If not used will download latest")
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/clihandler"
+	"github.com/armosec/kubescape/clihandler/cliobjects"
+	"github.com/spf13/cobra"
+)
+
+var (
+	compareExample = `
+  # Scan and compare two clusters
+  kubescape compare --context staging --context production
+
+  # Compare the clusters by the NSA framework
+  kubescape compare --context staging --context production --framework nsa
+
+  # Compare the results files of two clusters
+  kubescape compare staging.json production.json --format markdown --output compare.md
+`
+)
+var compareInfo = cliobjects.Compare{}
+
+var compareCmd = &cobra.Command{
+	Use:     "compare [<results file> <results file>] [flags]",
+	Short:   "Compare the posture of two clusters side-by-side, highlighting the controls passing in one cluster and failing in the other",
+	Long:    `Scans the clusters of two kube contexts (--context), or compares two results files generated by 'kubescape scan --format json --format-version v2'`,
+	Example: compareExample,
+	Args: func(cmd *cobra.Command, args []string) error {
+		switch {
+		case len(compareInfo.Contexts) > 0 && len(args) > 0:
+			return fmt.Errorf("expected either two contexts or two results files, not both")
+		case len(compareInfo.Contexts) > 0 && len(compareInfo.Contexts) != 2:
+			return fmt.Errorf("expected two contexts, received %d", len(compareInfo.Contexts))
+		case len(compareInfo.Contexts) == 0 && len(args) != 2:
+			return fmt.Errorf("expected two results files, received %d", len(args))
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		compareInfo.ResultsFiles = args
+
+		if err := clihandler.CliCompare(&compareInfo); err != nil {
+			logger.L().Fatal(err.Error())
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(compareCmd)
+	compareCmd.PersistentFlags().StringArrayVar(&compareInfo.Contexts, "context", []string{}, "Kube context of a scanned cluster, set twice")
+	compareCmd.PersistentFlags().StringVar(&compareInfo.Frameworks, "framework", "", "Frameworks the clusters are scanned by, e.g. nsa,mitre. Default is all of the frameworks")
+	compareCmd.PersistentFlags().StringVar(&compareInfo.UseArtifactsFrom, "use-artifacts-from", "", "Load artifacts from local directory. If not used will download them")
+	compareCmd.PersistentFlags().StringVarP(&compareInfo.Format, "format", "f", "pretty-printer", "Output format. Supported formats: 'pretty-printer'/'json'/'markdown'")
+	compareCmd.PersistentFlags().StringVarP(&compareInfo.Output, "output", "o", "", "Output file. Print output to file and not stdout")
+}
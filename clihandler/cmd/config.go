@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/armosec/kubescape/cautils/getter"
 	"github.com/armosec/kubescape/cautils/logger"
 	"github.com/armosec/kubescape/clihandler"
 	"github.com/armosec/kubescape/clihandler/cliobjects"
@@ -20,6 +21,12 @@ var (
 
   # Set cached configurations
   kubescape config set --help
+
+  # Set the inputs of configurable controls, e.g. the allowed image registries
+  kubescape config set-control-input imageRepositoryAllowList quay.io/myorg/ docker.io/myorg/
+
+  # Generate the least-privilege ClusterRole of scanning the nsa framework
+  kubescape config generate-rbac --framework nsa
 `
 	setConfigExample = `
   # Set account id
@@ -93,6 +100,54 @@ func parseSetArgs(args []string) error {
 	return nil
 }
 
+var setControlInput = cliobjects.SetControlInput{}
+
+var configSetControlInputCmd = &cobra.Command{
+	Use:   "set-control-input <input> [values...]",
+	Short: "Set an input of the configurable controls in the controls inputs file, e.g. the allowed registries or the trusted capabilities",
+	Long:  `The file is initialized with the default inputs when missing. Scan with the inputs by 'kubescape scan --controls-config <file>'`,
+	Example: `
+  # Allow the images of the organization registries only
+  kubescape config set-control-input imageRepositoryAllowList quay.io/myorg/ docker.io/myorg/
+
+  # Add a trusted capability to a YAML inputs file
+  kubescape config set-control-input insecureCapabilities SYS_ADMIN --append --file controls-inputs.yaml`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			return fmt.Errorf("expected the name of the input")
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		setControlInput.Key = args[0]
+		setControlInput.Values = args[1:]
+		if err := clihandler.CliSetControlInput(&setControlInput); err != nil {
+			logger.L().Fatal(err.Error())
+		}
+		return nil
+	},
+}
+
+var generateRBAC = cliobjects.GenerateRBAC{}
+
+var configGenerateRBACCmd = &cobra.Command{
+	Use:   "generate-rbac",
+	Short: "Generate the least-privilege ClusterRole/Role of scanning the frameworks, and its binding to the service account of the scanner",
+	Long:  `The role is derived from the resources matched by the rules of the controls of the frameworks, with the get/list verbs only`,
+	Example: `
+  # Grant the CI scanner the ClusterRole of scanning the nsa and the mitre frameworks
+  kubescape config generate-rbac --framework nsa,mitre --service-account ci/scanner | kubectl apply -f -
+
+  # Role of scanning a single namespace (kubescape scan --include-namespaces team-a)
+  kubescape config generate-rbac --framework nsa --namespace team-a --service-account team-a/scanner`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := clihandler.CliGenerateRBAC(&generateRBAC); err != nil {
+			logger.L().Fatal(err.Error())
+		}
+		return nil
+	},
+}
+
 var configDeleteCmd = &cobra.Command{
 	Use:   "delete",
 	Short: "Delete cached configurations",
@@ -119,6 +174,19 @@ var configViewCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(configCmd)
 	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configSetControlInputCmd)
+
+	configSetControlInputCmd.Flags().StringVar(&setControlInput.File, "file", getter.GetDefaultPath(getter.ControlsInputsFileName), "Path to the controls inputs file, JSON or YAML by the file extension")
+	configSetControlInputCmd.Flags().BoolVar(&setControlInput.Append, "append", false, "Append the values to the input instead of replacing them")
+	configCmd.AddCommand(configGenerateRBACCmd)
+	configGenerateRBACCmd.Flags().StringVar(&generateRBAC.Frameworks, "framework", "all", "The scanned frameworks, comma separated")
+	configGenerateRBACCmd.Flags().StringVar(&generateRBAC.Name, "name", "kubescape-scanner", "Name of the role and of the binding")
+	configGenerateRBACCmd.Flags().StringVarP(&generateRBAC.Namespace, "namespace", "n", "", "Generate a Role of scanning the namespace instead of a ClusterRole, the cluster scoped resources are left out")
+	configGenerateRBACCmd.Flags().StringVar(&generateRBAC.ServiceAccount, "service-account", "default/kubescape", "The bound service account, <namespace>/<name>")
+	configGenerateRBACCmd.Flags().BoolVar(&generateRBAC.HostSensor, "enable-host-scan", false, "Include the permissions of deploying the host sensor, for the controls of the hosts")
+	configGenerateRBACCmd.Flags().StringSliceVar(&generateRBAC.UseFrom, "use-from", nil, "Load the frameworks from local files")
+	configGenerateRBACCmd.Flags().StringVar(&generateRBAC.Account, "account", "", "Armo portal account ID. Default will load account ID from configMap or config file")
+	configGenerateRBACCmd.Flags().StringVarP(&generateRBAC.Output, "output", "o", "", "Output file, stdout by default")
 	configCmd.AddCommand(configDeleteCmd)
 	configCmd.AddCommand(configViewCmd)
 }
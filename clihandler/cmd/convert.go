@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/clihandler"
+	"github.com/armosec/kubescape/clihandler/cliobjects"
+	printerv2 "github.com/armosec/kubescape/resultshandling/printer/v2"
+	"github.com/spf13/cobra"
+)
+
+var (
+	convertExample = `
+  # Convert the results of a scan to sarif, without scanning again
+  kubescape scan --format json --format-version v2 --output results.json
+  kubescape convert results.json --to sarif --output results.sarif
+
+  # Convert the results to a pdf report
+  kubescape convert results.json --to pdf --output report.pdf
+`
+)
+var convertResultsInfo = cliobjects.ConvertResults{}
+
+var convertCmd = &cobra.Command{
+	Use:     "convert <results file> --to <format> [flags]",
+	Short:   "Convert a results file to another output format, without scanning again",
+	Long:    `The results file is generated by 'kubescape scan --format json --format-version v2'. Supported formats: ` + strings.Join(printerv2.EncoderFormats(), ", "),
+	Example: convertExample,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return fmt.Errorf("expected a results file, received %d arguments", len(args))
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		convertResultsInfo.ResultsFile = args[0]
+
+		if err := clihandler.CliConvertResults(&convertResultsInfo); err != nil {
+			logger.L().Fatal(err.Error())
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(convertCmd)
+	convertCmd.PersistentFlags().StringVar(&convertResultsInfo.Format, "to", "", "Output format. Supported formats: "+strings.Join(printerv2.EncoderFormats(), "/"))
+	convertCmd.PersistentFlags().StringVarP(&convertResultsInfo.Output, "output", "o", "", "Output file. Print output to file and not stdout")
+}
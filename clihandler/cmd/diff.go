@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/clihandler"
+	"github.com/armosec/kubescape/clihandler/cliobjects"
+	"github.com/spf13/cobra"
+)
+
+var (
+	diffExample = `
+  # Compare two scan results
+  kubescape scan --format json --format-version v2 --output old.json
+  kubescape scan --format json --format-version v2 --output new.json
+  kubescape diff old.json new.json
+
+  # Comment the diff on a pull-request, fail when the posture degraded
+  kubescape diff old.json new.json --format markdown --output diff.md --fail-on-regression
+`
+)
+var diffInfo = cliobjects.Diff{}
+
+var diffCmd = &cobra.Command{
+	Use:     "diff <old results file> <new results file> [flags]",
+	Short:   "Compare two scan results, showing the new failures, fixed controls and the risk-score delta",
+	Long:    `The results files are generated by 'kubescape scan --format json --format-version v2'`,
+	Example: diffExample,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 2 {
+			return fmt.Errorf("expected two results files, received %d", len(args))
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		diffInfo.OldResults, diffInfo.NewResults = args[0], args[1]
+
+		if err := clihandler.CliDiff(&diffInfo); err != nil {
+			logger.L().Fatal(err.Error())
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+	diffCmd.PersistentFlags().StringVarP(&diffInfo.Format, "format", "f", "pretty-printer", "Output format. Supported formats: 'pretty-printer'/'json'/'markdown'")
+	diffCmd.PersistentFlags().StringVarP(&diffInfo.Output, "output", "o", "", "Output file. Print output to file and not stdout")
+	diffCmd.PersistentFlags().BoolVar(&diffInfo.FailOnRegression, "fail-on-regression", false, "Exit with an error when there are new failed controls or the risk-score increased")
+}
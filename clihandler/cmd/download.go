@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/cautils/getter"
 	"github.com/armosec/kubescape/cautils/logger"
 	"github.com/armosec/kubescape/clihandler"
 	"github.com/spf13/cobra"
@@ -18,8 +19,8 @@ var (
   # Download all artifacts and save them in the default path (~/.kubescape)
   kubescape download artifacts
   
-  # Download all artifacts and save them in /tmp path
-  kubescape download artifacts --output /tmp
+  # Download all artifacts and save them in ./artifacts, with a manifest and checksums for transferring them to an air-gapped environment
+  kubescape download artifacts --output ./artifacts
   
   # Download the NSA framework. Run 'kubescape list frameworks' for all frameworks names
   kubescape download framework nsa
@@ -36,6 +37,15 @@ var (
   # Download the configured controls-inputs 
   kubescape download controls-inputs 
 
+  # Download the NSA framework, verifying the signatures of the released policies with the key of a mirror
+  kubescape download framework nsa --public-key mirror.pub
+
+  # Download the NSA framework from a policy bundle of an OCI registry, see 'kubescape push'
+  kubescape download framework nsa --from oci://registry.internal/policies/nsa:v1
+
+  # Download the NSA framework of a specific release of the policies
+  kubescape download framework nsa --policy-version v1.0.172
+
 `
 )
 var downloadCmd = &cobra.Command{
@@ -54,10 +64,14 @@ var downloadCmd = &cobra.Command{
 		return nil
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
+		initDownload()
 		downloadInfo.Target = args[0]
 		if len(args) >= 2 {
 			downloadInfo.Name = args[1]
 		}
+		if downloadInfo.From != "" && !getter.IsOCIReference(downloadInfo.From) {
+			return fmt.Errorf("invalid '--from' reference '%s', expected oci://<registry>/<repository>:<tag>", downloadInfo.From)
+		}
 		if err := clihandler.CliDownload(&downloadInfo); err != nil {
 			logger.L().Fatal(err.Error())
 		}
@@ -66,14 +80,18 @@ var downloadCmd = &cobra.Command{
 }
 
 func init() {
-	cobra.OnInitialize(initDownload)
-
 	rootCmd.AddCommand(downloadCmd)
 	downloadCmd.PersistentFlags().StringVarP(&downloadInfo.Account, "account", "", "", "Armo portal account ID. Default will load account ID from configMap or config file")
 	downloadCmd.Flags().StringVarP(&downloadInfo.Path, "output", "o", "", "Output file. If not specified, will save in `~/.kubescape/<policy name>.json`")
+	downloadCmd.Flags().StringVarP(&downloadInfo.From, "from", "", "", "Download from a policy bundle of an OCI registry (oci://<registry>/<repository>:<tag>), instead of the released policies")
+	downloadCmd.Flags().StringVarP(&downloadInfo.PolicyVersion, "policy-version", "", "", "Version of the released policies (the tag of the regolibrary release, e.g. v1.0.172). Default is the latest release")
+	downloadCmd.Flags().StringVarP(&downloadInfo.PublicKey, "public-key", "", "", "Public key (cosign or minisign) verifying the detached signatures of the released policies. Default is the key pinned in the kubescape build")
+	downloadCmd.Flags().BoolVarP(&downloadInfo.InsecureSkipVerify, "insecure-skip-verify", "", false, "Download the released policies without verifying their signatures")
 
 }
 
+// initDownload splits the output file of a single policy to the directory and the file name. Called by the command, after
+// the flags are set by the environment variables (the initializers of the other files run before the ones of root.go)
 func initDownload() {
 	if filepath.Ext(downloadInfo.Path) == ".json" {
 		downloadInfo.Path, downloadInfo.FileName = filepath.Split(downloadInfo.Path)
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/armosec/kubescape/cautils/getter"
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/clihandler"
+	"github.com/armosec/kubescape/clihandler/cliobjects"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exceptionsExample = `
+  # Add an exception interactively, picking the controls, the resource selectors and the expiration
+  kubescape exceptions add
+
+  # Exclude the deployments of the dev namespace failing C-0016, for 30 days
+  kubescape exceptions add --name dev-privilege-escalation --control C-0016 --kind Deployment --namespace dev --expiration 30d --owner platform-team --reason "legacy workload"
+
+  # Show the failed controls each exception suppresses
+  kubescape scan --format json --format-version v2 --output results.json
+  kubescape exceptions list --results results.json
+
+  # Exclude all of the resources labeled with an exception ticket, in the team namespaces
+  kubescape exceptions add --name ticketed --namespace 'team-*' --label-selector security.exception/ticket
+
+  # Remove an exception
+  kubescape exceptions remove dev-privilege-escalation
+
+  Scan with the exceptions: kubescape scan --exceptions <exceptions file>
+`
+)
+var exceptionsInfo = cliobjects.Exceptions{}
+
+var exceptionsCmd = &cobra.Command{
+	Use:     "exceptions <command>",
+	Short:   "Manage the exceptions file - add, list and remove exceptions",
+	Long:    ``,
+	Example: exceptionsExample,
+}
+
+var exceptionsAddCmd = &cobra.Command{
+	Use:   "add [flags]",
+	Short: "Add an exception, the missing fields are prompted for when running in a terminal",
+	Long:  `The exception excludes the resources matching all of the selectors (globs e.g. 'dev-*' and regular expressions supported, label values support wildcards) from failing the controls/frameworks`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := clihandler.CliExceptionsAdd(&exceptionsInfo); err != nil {
+			logger.L().Fatal(err.Error())
+		}
+		return nil
+	},
+}
+
+var exceptionsListCmd = &cobra.Command{
+	Use:   "list [flags]",
+	Short: "List the exceptions, and the failed controls each exception suppresses when a results file is set",
+	Long:  ``,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := clihandler.CliExceptionsList(&exceptionsInfo); err != nil {
+			logger.L().Fatal(err.Error())
+		}
+		return nil
+	},
+}
+
+var exceptionsRemoveCmd = &cobra.Command{
+	Use:   "remove <exception name> [flags]",
+	Short: "Remove an exception",
+	Long:  ``,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return fmt.Errorf("expected an exception name, received %d arguments", len(args))
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		exceptionsInfo.Name = args[0]
+		if err := clihandler.CliExceptionsRemove(&exceptionsInfo); err != nil {
+			logger.L().Fatal(err.Error())
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(exceptionsCmd)
+	exceptionsCmd.AddCommand(exceptionsAddCmd)
+	exceptionsCmd.AddCommand(exceptionsListCmd)
+	exceptionsCmd.AddCommand(exceptionsRemoveCmd)
+
+	exceptionsCmd.PersistentFlags().StringVar(&exceptionsInfo.File, "file", getter.GetDefaultPath("exceptions.json"), "Path to the exceptions file")
+	exceptionsCmd.PersistentFlags().StringVar(&exceptionsInfo.Results, "results", "", "Results file generated by 'kubescape scan --format json --format-version v2', for showing the failed controls suppressed by the exceptions")
+
+	options := &exceptionsInfo.Options
+	exceptionsAddCmd.Flags().StringVar(&options.Name, "name", "", "Unique name of the exception")
+	exceptionsAddCmd.Flags().StringSliceVar(&options.Controls, "control", []string{}, "Control IDs of the exception, e.g. --control C-0016,C-0017")
+	exceptionsAddCmd.Flags().StringSliceVar(&options.Frameworks, "framework", []string{}, "Frameworks of the exception, e.g. --framework nsa")
+	exceptionsAddCmd.Flags().StringVar(&options.Kind, "kind", "", "Kind of the excluded resources, e.g. Deployment")
+	exceptionsAddCmd.Flags().StringVar(&options.Namespace, "namespace", "", "Namespace of the excluded resources")
+	exceptionsAddCmd.Flags().StringVar(&options.ResourceName, "resource-name", "", "Name of the excluded resources")
+	exceptionsAddCmd.Flags().StringVar(&options.Cluster, "cluster", "", "Cluster of the excluded resources")
+	exceptionsAddCmd.Flags().StringSliceVar(&options.Labels, "label", []string{}, "Labels of the excluded resources, e.g. --label app=nginx. Use '*' for any value, e.g. --label 'security.exception/ticket=*'")
+	exceptionsAddCmd.Flags().StringVar(&options.LabelSelector, "label-selector", "", "Label selector of the excluded resources, same syntax as kubectl, e.g. --label-selector 'env in (dev,test),!critical'")
+	exceptionsAddCmd.Flags().StringVar(&options.Expiration, "expiration", "", "Expiration of the exception - an RFC3339 time, a date (2006-01-02) or a duration (e.g. 30d). Expired exceptions are not applied")
+	exceptionsAddCmd.Flags().StringVar(&options.Owner, "owner", "", "Owner of the risk acceptance, e.g. a team or an e-mail")
+	exceptionsAddCmd.Flags().StringVar(&options.Reason, "reason", "", "Reason of the risk acceptance")
+}
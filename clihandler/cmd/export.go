@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/armosec/kubescape/admission"
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/clihandler"
+	"github.com/armosec/kubescape/clihandler/cliobjects"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportPolicyExample = `
+  # Export controls as OPA Gatekeeper ConstraintTemplates and Constraints
+  kubescape export policy --control C-0016,C-0017 --format gatekeeper
+
+  # Export controls as Kubernetes ValidatingAdmissionPolicies, warning about the violations
+  kubescape export policy --control C-0016 --format vap --action warn --output policies.yaml
+`
+)
+var exportPolicyInfo = cliobjects.ExportPolicy{}
+
+var exportCmd = &cobra.Command{
+	Use:   "export <command>",
+	Short: "Export kubescape objects in the formats of other tools",
+	Long:  ``,
+}
+
+var exportPolicyCmd = &cobra.Command{
+	Use:     "policy --control <control IDs> [flags]",
+	Short:   "Translate controls to admission policies, for enforcing the controls at admission time",
+	Long:    "Translate controls to OPA Gatekeeper ConstraintTemplates (the rego rules of the controls) or Kubernetes ValidatingAdmissionPolicies (CEL translations of the controls: " + strings.Join(admission.VAPSupportedControls(), ", ") + ")",
+	Example: exportPolicyExample,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := clihandler.CliExportPolicy(&exportPolicyInfo); err != nil {
+			logger.L().Fatal(err.Error())
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	exportCmd.AddCommand(exportPolicyCmd)
+	exportPolicyCmd.PersistentFlags().StringSliceVar(&exportPolicyInfo.Controls, "control", []string{}, "Control IDs to export, e.g. --control C-0016,C-0017")
+	exportPolicyCmd.PersistentFlags().StringVarP(&exportPolicyInfo.Format, "format", "f", "gatekeeper", "Output format. Supported formats: 'gatekeeper'/'vap'")
+	exportPolicyCmd.PersistentFlags().StringVar(&exportPolicyInfo.Action, "action", "deny", "Action on violations. Supported: deny/warn/audit")
+	exportPolicyCmd.PersistentFlags().StringVarP(&exportPolicyInfo.Output, "output", "o", "", "Output file. Print output to file and not stdout")
+	exportPolicyCmd.PersistentFlags().StringVarP(&exportPolicyInfo.Account, "account", "", "", "Armo portal account ID. Default will load account ID from configMap or config file")
+	exportPolicyCmd.PersistentFlags().StringSliceVar(&exportPolicyInfo.UseFrom, "use-from", nil, "Load local policy object from specified path. If not used will download latest")
+	exportPolicyCmd.PersistentFlags().StringVar(&exportPolicyInfo.ControlsInputs, "controls-config", "", "Path to an controls-config obj, the inputs of the gatekeeper policies. If not set will download controls-config from ARMO management portal")
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/clihandler"
+	"github.com/armosec/kubescape/clihandler/cliobjects"
+	"github.com/spf13/cobra"
+)
+
+var (
+	fixExample = `
+  # Generate the JSON patches and strategic merge patches of the failed resources
+  kubescape scan --format json --format-version v2 --output results.json
+  kubescape fix results.json
+
+  # Patch the failed resources in the cluster, setting the resource limits
+  kubescape fix results.json --apply --value resources.limits.cpu=500m --value resources.limits.memory=256Mi
+
+  # Fix the scanned manifests
+  kubescape scan *.yaml --format json --format-version v2 --output results.json
+  kubescape fix results.json --apply
+`
+)
+var fixInfo = cliobjects.Fix{}
+
+var fixCmd = &cobra.Command{
+	Use:     "fix <results file> [flags]",
+	Short:   "Fix the failed resources of a scan - add a securityContext, drop the added capabilities, set the resource limits, etc.",
+	Long:    `The results file is generated by 'kubescape scan --format json --format-version v2'. The fixes are generated from the fix paths of the failed controls`,
+	Example: fixExample,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return fmt.Errorf("expected a results file, received %d arguments", len(args))
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fixInfo.ResultsFile = args[0]
+
+		if err := clihandler.CliFix(&fixInfo); err != nil {
+			logger.L().Fatal(err.Error())
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(fixCmd)
+	fixCmd.PersistentFlags().BoolVar(&fixInfo.Apply, "apply", false, "Apply the fixes - fix the scanned files, and patch the scanned resources in the cluster")
+	fixCmd.PersistentFlags().StringToStringVar(&fixInfo.Values, "value", map[string]string{}, "Value of the fixes which require a value, by the path (or its suffix), e.g. --value resources.limits.cpu=500m")
+	fixCmd.PersistentFlags().StringVarP(&fixInfo.Output, "output", "o", "", "Output file. Print the fixes to file and not stdout")
+}
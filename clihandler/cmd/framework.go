@@ -9,6 +9,8 @@ import (
 	"github.com/armosec/kubescape/cautils"
 	"github.com/armosec/kubescape/cautils/logger"
 	"github.com/armosec/kubescape/clihandler"
+	"github.com/armosec/kubescape/resultshandling/printer"
+	"github.com/armosec/kubescape/sbom"
 	"github.com/armosec/opa-utils/reporthandling"
 	"github.com/spf13/cobra"
 )
@@ -70,6 +72,9 @@ var frameworkCmd = &cobra.Command{
 				if len(args[1:]) == 0 || args[1] != "-" {
 					scanInfo.InputPatterns = args[1:]
 				} else { // store stdin to file - do NOT move to separate function !!
+					if scanInfo.Fix {
+						logger.L().Fatal("bad argument: '--fix' fixes the scanned files in place, scanning stdin is not supported")
+					}
 					tempFile, err := os.CreateTemp(".", "tmp-kubescape*.yaml")
 					if err != nil {
 						return err
@@ -89,7 +94,14 @@ var frameworkCmd = &cobra.Command{
 
 		scanInfo.Init()
 		cautils.SetSilentMode(scanInfo.Silent)
-		err := clihandler.ScanCliSetup(&scanInfo)
+		var err error
+		if scanInfo.ServeMetrics != "" {
+			err = clihandler.ServeMetrics(&scanInfo)
+		} else if scanInfo.Watch {
+			err = clihandler.Watch(&scanInfo)
+		} else {
+			err = clihandler.ScanCliSetup(&scanInfo)
+		}
 		if err != nil {
 			logger.L().Fatal(err.Error())
 		}
@@ -112,10 +124,52 @@ func init() {
 // }
 
 func flagValidationFramework() {
+	applyScanConfig()
 	if scanInfo.Submit && scanInfo.Local {
 		logger.L().Fatal("you can use `keep-local` or `submit`, but not both")
 	}
 	if 100 < scanInfo.FailThreshold {
 		logger.L().Fatal("bad argument: out of range threshold")
 	}
+	if scanInfo.Watch && scanInfo.ServeMetrics != "" {
+		logger.L().Fatal("you can use `watch` or `serve-metrics`, but not both")
+	}
+	if err := scanInfo.PdfOptions.Validate(); err != nil {
+		logger.L().Fatal(err.Error())
+	}
+	if err := scanInfo.TimestampOptions.Validate(); err != nil {
+		logger.L().Fatal(err.Error())
+	}
+	if err := scanInfo.EmailOptions.Validate(); err != nil {
+		logger.L().Fatal(err.Error())
+	}
+	if err := scanInfo.FailOptions.Validate(); err != nil {
+		logger.L().Fatal(err.Error())
+	}
+	if err := scanInfo.Selectors.Validate(); err != nil {
+		logger.L().Fatal(err.Error())
+	}
+	if err := scanInfo.HostSensorOptions.Validate(); err != nil {
+		logger.L().Fatal(err.Error())
+	}
+	if err := scanInfo.Timeouts.Validate(); err != nil {
+		logger.L().Fatal(err.Error())
+	}
+	if err := scanInfo.RedactOptions.Validate(); err != nil {
+		logger.L().Fatal(err.Error())
+	}
+	if err := scanInfo.ValidateOutputFiles(); err != nil {
+		logger.L().Fatal(err.Error())
+	}
+	if cautils.StringInSlice(scanInfo.GetFormats(), printer.GoTemplateFormat) != cautils.ValueNotFound && scanInfo.OutputTemplate == "" {
+		logger.L().Fatal("bad argument: '--output-template' is required when using the gotemplate format")
+	}
+	if scanInfo.SBOMFormat != "" {
+		if err := sbom.ValidateFormat(scanInfo.SBOMFormat); err != nil {
+			logger.L().Fatal(err.Error())
+		}
+	}
+	if scanInfo.ImageMetadata && scanInfo.Offline {
+		logger.L().Fatal("you can use `enable-image-metadata` or `offline`, but not both")
+	}
 }
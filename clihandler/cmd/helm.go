@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/clihandler"
+	"github.com/armosec/opa-utils/reporthandling"
+	"github.com/spf13/cobra"
+)
+
+var (
+	helmChartOptions cautils.HelmChartOptions
+	helmExample      = `
+  # Scan the rendered manifests of a local chart
+  kubescape scan helm ./chart
+
+  # Render the chart with values files and overrides, same as 'helm template'
+  kubescape scan helm ./chart --values values.yaml --values values-prod.yaml --set image.tag=1.2.3
+
+  # Scan a packaged chart
+  kubescape scan helm mychart-0.1.0.tgz --release-name myrelease --namespace web
+
+  The failed resources are attributed to the template file of the chart they were rendered from
+`
+)
+
+var helmCmd = &cobra.Command{
+	Use:     "helm <chart path> [flags]",
+	Short:   "Render a local helm chart and scan the rendered manifests",
+	Example: helmExample,
+	Long:    "Execute a scan of all frameworks on the manifests of a local helm chart directory or packaged chart, rendered with the values files and the --set overrides",
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return fmt.Errorf("requires a single helm chart path")
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flagValidationFramework()
+		helmChartOptions.ChartPath = args[0]
+		scanInfo.HelmChart = &helmChartOptions
+
+		scanInfo.ScanAll = true
+		scanInfo.FrameworkScan = true
+		scanInfo.SetPolicyIdentifiers([]string{}, reporthandling.KindFramework)
+
+		scanInfo.Init()
+		cautils.SetSilentMode(scanInfo.Silent)
+		if err := clihandler.ScanCliSetup(&scanInfo); err != nil {
+			logger.L().Fatal(err.Error())
+		}
+		return nil
+	},
+}
+
+func init() {
+	scanCmd.AddCommand(helmCmd)
+	helmCmd.Flags().StringArrayVar(&helmChartOptions.ValuesFiles, "values", []string{}, "Values file of the chart, can be set several times. The last file takes precedence")
+	helmCmd.Flags().StringArrayVar(&helmChartOptions.Values, "set", []string{}, "Override values of the chart, e.g. --set image.tag=1.2.3,replicas=2")
+	helmCmd.Flags().StringArrayVar(&helmChartOptions.StringValues, "set-string", []string{}, "Override values of the chart with string values")
+	helmCmd.Flags().StringVar(&helmChartOptions.ReleaseName, "release-name", "release-name", "Release name the chart is rendered with")
+	helmCmd.Flags().StringVar(&helmChartOptions.Namespace, "namespace", "default", "Namespace the chart is rendered with")
+}
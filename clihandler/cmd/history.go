@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/clihandler"
+	"github.com/armosec/kubescape/clihandler/cliobjects"
+	"github.com/spf13/cobra"
+)
+
+var (
+	historyExample = `
+  # Store the results of the scan
+  kubescape scan --store
+
+  # Print the score trends of the stored scans
+  kubescape history
+
+  # Print the score trends of the last 10 scans of a cluster, and the results of specific controls
+  kubescape history --cluster my-cluster --limit 10 --control C-0005,C-0017
+`
+)
+var historyInfo = cliobjects.History{}
+
+var historyCmd = &cobra.Command{
+	Use:     "history [flags]",
+	Short:   "Print the score trends per framework and per control of the scans stored with 'kubescape scan --store'",
+	Long:    ``,
+	Example: historyExample,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := clihandler.CliHistory(&historyInfo); err != nil {
+			logger.L().Fatal(err.Error())
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+	historyCmd.PersistentFlags().StringVar(&historyInfo.StorePath, "store-path", "", "Path to the results store database. Default: results.db in the cache directory")
+	historyCmd.PersistentFlags().StringVar(&historyInfo.ClusterName, "cluster", "", "List the scans of a specific cluster only")
+	historyCmd.PersistentFlags().StringSliceVar(&historyInfo.Controls, "control", []string{}, "Print the results trend of the given control IDs, e.g. --control C-0005,C-0017")
+	historyCmd.PersistentFlags().IntVar(&historyInfo.Limit, "limit", 20, "Number of latest scans to list, 0 for all of the scans")
+	historyCmd.PersistentFlags().StringVarP(&historyInfo.Format, "format", "f", "pretty-printer", "Output format. Supported formats: 'pretty-printer'/'json'")
+}
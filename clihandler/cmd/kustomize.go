@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/clihandler"
+	"github.com/armosec/opa-utils/reporthandling"
+	"github.com/spf13/cobra"
+)
+
+var kustomizeExample = `
+  # Scan the built manifests of an overlay, same as 'kustomize build ./overlays/prod'
+  kubescape scan kustomize ./overlays/prod
+
+  The failed resources are attributed to the base/overlay file they were loaded from
+`
+
+var kustomizeCmd = &cobra.Command{
+	Use:     "kustomize <kustomization directory> [flags]",
+	Short:   "Build a kustomization directory and scan the built manifests",
+	Example: kustomizeExample,
+	Long:    "Execute a scan of all frameworks on the manifests of a kustomization directory, built with the bases, patches and generators of the kustomization",
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return fmt.Errorf("requires a single kustomization directory")
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flagValidationFramework()
+		scanInfo.KustomizeDirectory = args[0]
+
+		scanInfo.ScanAll = true
+		scanInfo.FrameworkScan = true
+		scanInfo.SetPolicyIdentifiers([]string{}, reporthandling.KindFramework)
+
+		scanInfo.Init()
+		cautils.SetSilentMode(scanInfo.Silent)
+		if err := clihandler.ScanCliSetup(&scanInfo); err != nil {
+			logger.L().Fatal(err.Error())
+		}
+		return nil
+	},
+}
+
+func init() {
+	scanCmd.AddCommand(kustomizeCmd)
+}
@@ -24,6 +24,9 @@ var (
 
   # List all supported controls ids
   kubescape list controls --id 
+
+  # List the resources fetched for scanning the nsa framework, with the number of objects in the cluster
+  kubescape list resources nsa --count
   
   Control documentation:
   https://hub.armo.cloud/docs/controls
@@ -49,6 +52,9 @@ var listCmd = &cobra.Command{
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		listPolicies.Target = args[0]
+		if len(args) > 1 {
+			listPolicies.Frameworks = args[1]
+		}
 
 		if err := clihandler.CliList(&listPolicies); err != nil {
 			logger.L().Fatal(err.Error())
@@ -64,4 +70,6 @@ func init() {
 	listCmd.PersistentFlags().StringVar(&listPolicies.Account, "account", "", "Armo portal account ID. Default will load account ID from configMap or config file")
 	listCmd.PersistentFlags().StringVar(&listPolicies.Format, "format", "pretty-print", "output format. supported: 'pretty-printer'/'json'")
 	listCmd.PersistentFlags().BoolVarP(&listPolicies.ListIDs, "id", "", false, "List control ID's instead of controls names")
+	listCmd.PersistentFlags().StringSliceVar(&listPolicies.UseFrom, "use-from", nil, "Load the frameworks of the listed resources from local files")
+	listCmd.PersistentFlags().BoolVar(&listPolicies.Count, "count", false, "Count the objects of the listed resources in the cluster, a single request per resource")
 }
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/clihandler"
+	"github.com/armosec/kubescape/clihandler/cliobjects"
+	"github.com/spf13/cobra"
+)
+
+var (
+	networkPoliciesExample = `
+  # Report the coverage of the workloads of the cluster by the network policies
+  kubescape network-policies
+
+  # Report the coverage of a namespace in json
+  kubescape network-policies --namespace prod --format json
+
+  # Report the coverage of the manifests
+  kubescape network-policies manifests/
+
+  # Fail the scan by the workloads without network policies, with the ports open to any source
+  kubescape scan --custom-controls examples/network-controls/
+`
+)
+var networkPoliciesInfo = cliobjects.NetworkPolicies{}
+
+var networkPoliciesCmd = &cobra.Command{
+	Use:     "network-policies [files...] [flags]",
+	Short:   "Report the coverage of the workloads by the network policies, and the namespaces allowing all of the ingress/egress traffic",
+	Long:    `The ports of the containers are listed with the status of their ingress traffic - uncovered (no ingress policy selects the workload), open (a rule allows any source), restricted (the rules allow selected sources only) or denied`,
+	Example: networkPoliciesExample,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		networkPoliciesInfo.InputPatterns = args
+
+		if err := clihandler.CliNetworkPolicies(&networkPoliciesInfo); err != nil {
+			logger.L().Fatal(err.Error())
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(networkPoliciesCmd)
+	networkPoliciesCmd.PersistentFlags().StringVarP(&networkPoliciesInfo.Namespace, "namespace", "n", "", "Namespace of the objects of the cluster, all of the namespaces by default")
+	networkPoliciesCmd.PersistentFlags().StringVarP(&networkPoliciesInfo.Format, "format", "f", "pretty-printer", "Output format. Supported formats: 'pretty-printer'/'json'")
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/clihandler"
+	"github.com/armosec/kubescape/clihandler/cliobjects"
+	"github.com/armosec/kubescape/opaprocessor"
+	"github.com/armosec/kubescape/resourcehandler"
+	"github.com/spf13/cobra"
+)
+
+var (
+	operatorExample = `
+  # Run in-cluster, install the CRDs and run the scans of the ScanSchedule objects of all of the namespaces
+  kubescape operator
+
+  # Run the scans of the ScanSchedule objects of the kubescape namespace only
+  kubescape operator --namespace kubescape
+
+  # Schedule a scan of the NSA framework every 6 hours, and list the reports
+  cat <<EOF | kubectl apply -f -
+  apiVersion: kubescape.cloud/v1alpha1
+  kind: ScanSchedule
+  metadata:
+    name: nsa
+    namespace: kubescape
+  spec:
+    schedule: "0 */6 * * *"
+    frameworks: ["nsa"]
+    excludeNamespaces: ["kube-system"]
+  EOF
+  kubectl get scanreports -n kubescape
+`
+)
+var operatorInfo = cliobjects.Operator{}
+
+var operatorCmd = &cobra.Command{
+	Use:     "operator [flags]",
+	Short:   "Run the scans of the ScanSchedule objects of the cluster, the results are persisted as ScanReport objects",
+	Long:    ``,
+	Example: operatorExample,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if operatorInfo.ScanInfo.Submit && operatorInfo.ScanInfo.Local {
+			logger.L().Fatal("you can use `keep-local` or `submit`, but not both")
+		}
+		operatorInfo.ScanInfo.Init()
+		cautils.SetSilentMode(true) // in-cluster, no terminal for the progress bars
+		if err := clihandler.CliOperator(&operatorInfo); err != nil {
+			logger.L().Fatal(err.Error())
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(operatorCmd)
+	operatorCmd.PersistentFlags().StringVar(&operatorInfo.Namespace, "namespace", "", "Run the ScanSchedule objects of the namespace only. Default: all of the namespaces")
+	operatorCmd.PersistentFlags().BoolVar(&operatorInfo.InstallCRDs, "install-crds", true, "Install the ScanSchedule and the ScanReport CRDs when starting")
+	operatorCmd.PersistentFlags().DurationVar(&operatorInfo.SyncInterval, "sync-interval", time.Minute, "Interval between the listings of the ScanSchedule objects")
+	operatorCmd.PersistentFlags().StringVarP(&operatorInfo.ScanInfo.Account, "account", "", "", "Armo portal account ID. Default will load account ID from configMap or config file")
+	operatorCmd.PersistentFlags().StringVar(&operatorInfo.ScanInfo.ControlsInputs, "controls-config", "", "Path to an controls-config obj. If not set will download controls-config from ARMO management portal")
+	operatorCmd.PersistentFlags().StringVar(&operatorInfo.ScanInfo.UseExceptions, "exceptions", "", "Path to an exceptions obj. If not set will download exceptions from ARMO management portal")
+	operatorCmd.PersistentFlags().StringSliceVar(&operatorInfo.ScanInfo.UseFrom, "use-from", nil, "Load local policy object from specified path. If not used will download latest")
+	operatorCmd.PersistentFlags().BoolVarP(&operatorInfo.ScanInfo.Submit, "submit", "", false, "Send the scan results to Armo management portal as well")
+	operatorCmd.PersistentFlags().StringVar(&operatorInfo.ScanInfo.SpoolDir, "spool-dir", "", "Spool the reports which could not be submitted to the ARMO portal (after the retries) to the directory, submitted by the next runs with the same directory")
+	operatorCmd.PersistentFlags().BoolVarP(&operatorInfo.ScanInfo.Local, "keep-local", "", false, "If you do not want your Kubescape results reported to Armo backend")
+	operatorCmd.PersistentFlags().StringVar(&operatorInfo.ScanInfo.Forward, "forward", "", "Forward the failed controls as CEF events to a syslog server. Supported: syslog://host:port (UDP), syslog+tcp://host:port")
+	operatorCmd.PersistentFlags().StringSliceVar(&operatorInfo.ScanInfo.Notify, "notify", []string{}, "Post a summary of the results to a Slack/Microsoft Teams incoming webhook")
+	operatorCmd.PersistentFlags().IntVar(&operatorInfo.ScanInfo.FetchConcurrency, "fetch-concurrency", resourcehandler.DefaultFetchConcurrency, "Number of the Kubernetes API resources listed concurrently")
+	operatorCmd.PersistentFlags().Float32Var(&operatorInfo.ScanInfo.FetchQPS, "fetch-qps", resourcehandler.DefaultFetchQPS, "Maximum requests per second to the Kubernetes API server, bursting to twice the rate")
+	operatorCmd.PersistentFlags().Int64Var(&operatorInfo.ScanInfo.FetchPageSize, "fetch-page-size", resourcehandler.DefaultFetchPageSize, "Number of the objects of a list request to the Kubernetes API server, the lists are paginated. 0 lists all of the objects of a resource at once")
+	operatorCmd.PersistentFlags().IntVar(&operatorInfo.ScanInfo.EvalConcurrency, "eval-concurrency", opaprocessor.DefaultEvalConcurrency, "Number of the controls evaluated concurrently")
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/clihandler"
+	"github.com/armosec/kubescape/clihandler/cliobjects"
+	"github.com/spf13/cobra"
+)
+
+var (
+	policyExample = `
+  # Test the user-authored controls on their fixtures
+  kubescape policy test --custom-controls ./controls/ ./tests/
+
+  # Validate the metadata and compile the Rego of the user-authored controls
+  kubescape policy lint ./controls/
+
+  # Test a built-in control of a downloaded framework
+  kubescape download framework nsa --output nsa.json
+  kubescape policy test --use-from nsa.json ./tests/privileged_test.yaml
+
+  A test file (the files of a directory ending with _test.yaml/_test.json) lists the test cases of a control:
+
+    control: CUSTOM-0001
+    tests:
+      - name: a deployment without the team label fails
+        inputs: [fixtures/unlabelled.yaml]
+        expected: failed
+        failedResources: [Deployment/api]
+      - name: a labelled deployment passes
+        inputs: [fixtures/labelled.yaml]
+        expected: passed
+`
+)
+var policyTestInfo = cliobjects.PolicyTest{}
+
+var policyCmd = &cobra.Command{
+	Use:     "policy <command>",
+	Short:   "Develop controls - lint controls and test them on fixture resources",
+	Long:    ``,
+	Example: policyExample,
+}
+
+var policyTestCmd = &cobra.Command{
+	Use:   "test <test files/directories> [flags]",
+	Short: "Run controls on fixture YAML resources and assert the expected passed/failed status, without scanning a cluster",
+	Long:  `Exits with an error code when a test fails`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			return fmt.Errorf("expected test files or directories")
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		policyTestInfo.Tests = args
+		if err := clihandler.CliPolicyTest(&policyTestInfo); err != nil {
+			logger.L().Fatal(err.Error())
+		}
+		return nil
+	},
+}
+
+var policyLintCmd = &cobra.Command{
+	Use:   "lint <controls directories>",
+	Short: "Validate the metadata of user-authored controls (IDs, severities, remediation), compile their Rego and warn about kinds missing from the match of the rules",
+	Long:  `Exits with an error code when a control has errors, the warnings do not fail`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			return fmt.Errorf("expected directories of controls")
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := clihandler.CliPolicyLint(args); err != nil {
+			logger.L().Fatal(err.Error())
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(policyCmd)
+	policyCmd.AddCommand(policyTestCmd)
+	policyCmd.AddCommand(policyLintCmd)
+
+	policyTestCmd.PersistentFlags().StringVar(&policyTestInfo.CustomControls, "custom-controls", "", "Path to a directory of user-authored controls, same as 'kubescape scan --custom-controls'")
+	policyTestCmd.PersistentFlags().StringSliceVar(&policyTestInfo.UseFrom, "use-from", nil, "Load the tested controls from framework files, e.g. downloaded by 'kubescape download framework'")
+}
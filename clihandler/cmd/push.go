@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/armosec/kubescape/cautils/getter"
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/clihandler"
+	"github.com/armosec/kubescape/clihandler/cliobjects"
+	"github.com/spf13/cobra"
+)
+
+var pushInfo = cliobjects.Push{}
+
+var pushCmd = &cobra.Command{
+	Use:   "push <oci://registry/repository:tag> <artifacts files/directories>",
+	Short: "Push artifacts (frameworks, controls inputs, exceptions) as a policy bundle to an OCI registry",
+	Long:  `The bundle is downloaded by 'kubescape download <policy> --from oci://registry/repository:tag'. The registry credentials are taken from the KS_REGISTRY_USERNAME/KS_REGISTRY_PASSWORD environment variables, otherwise from 'docker login'`,
+	Example: `
+  # Mirror the downloaded artifacts to an internal registry
+  kubescape download artifacts --output artifacts/
+  kubescape push oci://registry.internal/policies/kubescape:v1 artifacts/
+
+  # Push a single framework
+  kubescape push oci://registry.internal/policies/nsa:v1 nsa.json`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 2 {
+			return fmt.Errorf("expected the OCI reference of the bundle and the artifacts files")
+		}
+		if !getter.IsOCIReference(args[0]) {
+			return fmt.Errorf("invalid reference '%s', expected oci://<registry>/<repository>:<tag>", args[0])
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pushInfo.Reference = args[0]
+		pushInfo.Files = args[1:]
+		if err := clihandler.CliPush(&pushInfo); err != nil {
+			logger.L().Fatal(err.Error())
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(pushCmd)
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/clihandler"
+	"github.com/armosec/kubescape/clihandler/cliobjects"
+	"github.com/spf13/cobra"
+)
+
+var (
+	queryExample = `
+  # List the failed controls of high severity
+  kubescape query results.json --filter '.controls[] | select(.severity=="High" and .status=="failed")'
+
+  # List the IDs of the resources which failed a control
+  kubescape query results.json --filter '.controls[] | select(.controlID=="C-0017") | .failedResources[]' --raw-output
+
+  # Count the failed controls per severity
+  kubescape query results.json --filter '[.controls[] | select(.status=="failed")] | group_by(.severity) | map({(.[0].severity): length}) | add'
+
+  # Read the results from stdin
+  kubescape scan --format json --format-version v2 | kubescape query - --filter '.summaryDetails.score'
+`
+)
+var queryInfo = cliobjects.Query{}
+
+var queryCmd = &cobra.Command{
+	Use:   "query <results file> [flags]",
+	Short: "Filter the results of a scan using a jq filter",
+	Long: `Filter a results file generated by 'kubescape scan --format json --format-version v2' using a jq filter (https://stedolan.github.io/jq/manual/).
+In addition to the fields of the results file, the '.controls' list holds a control per item - controlID, name, severity, status, score, frameworks and the failed/excluded/passed resources`,
+	Example: queryExample,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return fmt.Errorf("expected a results file, use '-' to read from stdin")
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		queryInfo.ResultsFile = args[0]
+
+		if err := clihandler.CliQuery(&queryInfo); err != nil {
+			logger.L().Fatal(err.Error())
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(queryCmd)
+	queryCmd.PersistentFlags().StringVar(&queryInfo.Filter, "filter", ".", "jq filter to apply to the results")
+	queryCmd.PersistentFlags().BoolVarP(&queryInfo.Compact, "compact-output", "c", false, "Print each result in a single line")
+	queryCmd.PersistentFlags().BoolVarP(&queryInfo.RawOutput, "raw-output", "r", false, "Print strings without quotes")
+}
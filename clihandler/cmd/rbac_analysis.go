@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/clihandler"
+	"github.com/armosec/kubescape/clihandler/cliobjects"
+	"github.com/spf13/cobra"
+)
+
+var (
+	rbacAnalysisExample = `
+  # Report the risky grants of the cluster - cluster-admin, secrets read, pod exec and wildcard verbs
+  kubescape rbac
+
+  # Include the default grants to the system subjects, and print the permissions of every subject
+  kubescape rbac --include-system --format json
+
+  # List the subjects allowed to delete the pods of the kube-system namespace
+  kubescape rbac who-can delete pods -n kube-system
+
+  # Subresources, groups, resource names and non-resource URLs
+  kubescape rbac who-can create pods/exec
+  kubescape rbac who-can patch deployments.apps my-app -n default
+  kubescape rbac who-can get /metrics
+`
+)
+var rbacAnalysisInfo = cliobjects.RBAC{}
+var rbacWhoCanInfo = cliobjects.RBACWhoCan{}
+
+var rbacAnalysisCmd = &cobra.Command{
+	Use:     "rbac [flags]",
+	Short:   "Analyze the Role-Based Access Control(RBAC) of the cluster - report the risky grants per subject",
+	Long:    `Builds the permissions of the users, groups and service accounts from the Roles, ClusterRoles and their bindings`,
+	Example: rbacAnalysisExample,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := clihandler.CliRBAC(&rbacAnalysisInfo); err != nil {
+			logger.L().Fatal(err.Error())
+		}
+		return nil
+	},
+}
+
+var rbacWhoCanCmd = &cobra.Command{
+	Use:   "who-can <verb> <resource> [name] [flags]",
+	Short: "List the subjects allowed to perform an action, and the roles and bindings granting it",
+	Long:  ``,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 2 || len(args) > 3 {
+			return fmt.Errorf("expected a verb, a resource and optionally the name of the resource")
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rbacWhoCanInfo.Verb, rbacWhoCanInfo.Resource = args[0], args[1]
+		if len(args) == 3 {
+			rbacWhoCanInfo.Name = args[2]
+		}
+		if err := clihandler.CliRBACWhoCan(&rbacWhoCanInfo); err != nil {
+			logger.L().Fatal(err.Error())
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(rbacAnalysisCmd)
+	rbacAnalysisCmd.AddCommand(rbacWhoCanCmd)
+	rbacAnalysisCmd.Flags().StringVarP(&rbacAnalysisInfo.Format, "format", "f", "pretty-printer", "Output format. Supported formats: 'pretty-printer'/'json'")
+	rbacAnalysisCmd.Flags().BoolVar(&rbacAnalysisInfo.IncludeSystem, "include-system", false, "Report the grants to the system subjects and by the system bindings (system:*)")
+
+	rbacWhoCanCmd.PersistentFlags().StringVarP(&rbacWhoCanInfo.Namespace, "namespace", "n", "", "Namespace of the action, the subjects allowed in any namespace are listed by default")
+	rbacWhoCanCmd.PersistentFlags().StringVarP(&rbacWhoCanInfo.Format, "format", "f", "pretty-printer", "Output format. Supported formats: 'pretty-printer'/'json'")
+}
@@ -10,12 +10,17 @@ import (
 	"github.com/armosec/kubescape/cautils/logger"
 	"github.com/armosec/kubescape/cautils/logger/helpers"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 var armoBEURLs = ""
 var armoBEURLsDep = ""
+var loggerLevelDep = ""
 var rootInfo cautils.RootInfo
 
+// envFlagsPrefix the prefix of the environment variables of the flags, e.g. KUBESCAPE_FAIL_THRESHOLD of --fail-threshold
+const envFlagsPrefix = "KUBESCAPE_"
+
 const envFlagUsage = "Send report results to specific URL. Format:<ReportReceiver>,<Backend>,<Frontend>.\n\t\tExample:report.armo.cloud,api.armo.cloud,portal.armo.cloud"
 
 var ksExamples = `
@@ -36,17 +41,21 @@ var rootCmd = &cobra.Command{
 	Use:     "kubescape",
 	Version: cautils.BuildNumber,
 	Short:   "Kubescape is a tool for testing Kubernetes security posture",
-	Long:    `Based on NSA \ MITRE ATT&CK® and other frameworks specifications`,
+	Long:    "Based on NSA \\ MITRE ATT&CK® and other frameworks specifications.\n\nEvery flag can be set by an environment variable as well, " + envFlagsPrefix + "<FLAG NAME> in uppercase with '_' for '-', e.g. " + envFlagsPrefix + "FAIL_THRESHOLD=50 for '--fail-threshold 50'. The flags of the command line take precedence over the environment variables",
 	Example: ksExamples,
 }
 
+// executedCmd the command of the command line
+var executedCmd *cobra.Command
+
 func Execute() {
+	executedCmd, _, _ = rootCmd.Find(os.Args[1:])
 	rootCmd.Execute()
 }
 
 func init() {
 
-	cobra.OnInitialize(initLogger, initLoggerLevel, initEnvironment, initCacheDir)
+	cobra.OnInitialize(initFlagsFromEnv, initLogger, initLoggerLevel, initEnvironment, initCacheDir, initTLS)
 
 	rootCmd.PersistentFlags().StringVar(&armoBEURLsDep, "environment", "", envFlagUsage)
 	rootCmd.PersistentFlags().StringVar(&armoBEURLs, "env", "", envFlagUsage)
@@ -54,17 +63,67 @@ func init() {
 	rootCmd.PersistentFlags().MarkHidden("environment")
 	rootCmd.PersistentFlags().MarkHidden("env")
 
-	rootCmd.PersistentFlags().StringVarP(&rootInfo.Logger, "logger", "l", helpers.InfoLevel.String(), fmt.Sprintf("Logger level. Supported: %s [$KS_LOGGER]", strings.Join(helpers.SupportedLevels(), "/")))
+	rootCmd.PersistentFlags().StringVarP(&rootInfo.Logger, "log-level", "l", helpers.InfoLevel.String(), fmt.Sprintf("Log level. Supported: %s [$KS_LOG_LEVEL]", strings.Join(helpers.SupportedLevels(), "/")))
+	rootCmd.PersistentFlags().StringVar(&loggerLevelDep, "logger", "", "Logger level")
+	rootCmd.PersistentFlags().MarkDeprecated("logger", "use 'log-level' instead")
+	rootCmd.PersistentFlags().StringVar(&rootInfo.LogFormat, "log-format", "", fmt.Sprintf("Log format. Supported: %s, pretty in a terminal and json otherwise by default [$KS_LOG_FORMAT]", strings.Join(logger.SupportedFormats(), "/")))
 	rootCmd.PersistentFlags().StringVar(&rootInfo.CacheDir, "cache-dir", getter.DefaultLocalStore, "Cache directory [$KS_CACHE_DIR]")
+	rootCmd.PersistentFlags().StringVar(&rootInfo.CABundle, "ca-bundle", "", "PEM file of certificate authorities trusted by the downloads of the policies, the submission of the reports and the other outbound HTTPS requests, e.g. of a TLS intercepting proxy. The proxy is set by $HTTPS_PROXY and $NO_PROXY")
+	rootCmd.PersistentFlags().BoolVar(&rootInfo.InsecureTLS, "insecure-tls", false, "Skip the TLS verification of the outbound HTTPS requests. Not recommended, prefer '--ca-bundle'")
+}
+
+// envFlagName returns the environment variable of the flag
+func envFlagName(name string) string {
+	return envFlagsPrefix + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// initFlagsFromEnv sets the flags of the command which are not set in the command line by their environment variables.
+// The flags take precedence over the environment variables, and the environment variables over the config file of the scans
+func initFlagsFromEnv() {
+	if executedCmd == nil {
+		return
+	}
+	flags := executedCmd.Flags()
+	var err error
+	flags.VisitAll(func(flag *pflag.Flag) {
+		if err != nil || flag.Changed || flag.Name == "help" {
+			return
+		}
+		value, ok := os.LookupEnv(envFlagName(flag.Name))
+		if !ok {
+			return
+		}
+		if e := flags.Set(flag.Name, value); e != nil {
+			err = fmt.Errorf("bad argument: invalid %s: %w", envFlagName(flag.Name), e)
+		}
+	})
+	if err != nil {
+		logger.L().Fatal(err.Error())
+	}
 }
 
 func initLogger() {
-	if l := os.Getenv("KS_LOGGER_NAME"); l != "" {
-		logger.InitializeLogger(l)
+	if rootInfo.LogFormat != "" {
+	} else if l := os.Getenv("KS_LOG_FORMAT"); l != "" {
+		rootInfo.LogFormat = l
+	} else if l := os.Getenv("KS_LOGGER_NAME"); l != "" {
+		rootInfo.LogFormat = l
+	} else {
+		return // pretty in a terminal, json otherwise
+	}
+	switch strings.ToLower(rootInfo.LogFormat) {
+	case logger.PrettyFormat, logger.JSONFormat, "zap":
+		logger.InitializeLogger(rootInfo.LogFormat)
+	default:
+		logger.L().Fatal(fmt.Sprintf("supported log formats: %s", strings.Join(logger.SupportedFormats(), "/")), helpers.String("format", rootInfo.LogFormat))
 	}
 }
 func initLoggerLevel() {
 	if rootInfo.Logger != helpers.InfoLevel.String() {
+	} else if loggerLevelDep != "" {
+		rootInfo.Logger = loggerLevelDep
+	} else if l := os.Getenv("KS_LOG_LEVEL"); l != "" {
+		rootInfo.Logger = l
 	} else if l := os.Getenv("KS_LOGGER"); l != "" {
 		rootInfo.Logger = l
 	}
@@ -84,6 +143,15 @@ func initCacheDir() {
 
 	logger.L().Debug("cache dir updated", helpers.String("path", getter.DefaultLocalStore))
 }
+func initTLS() {
+	if rootInfo.InsecureTLS {
+		logger.L().Warning("the TLS verification of the outbound HTTPS requests is disabled")
+	}
+	if err := getter.SetTLSOptions(rootInfo.CABundle, rootInfo.InsecureTLS); err != nil {
+		logger.L().Fatal(err.Error())
+	}
+}
+
 func initEnvironment() {
 	if armoBEURLsDep != "" {
 		armoBEURLs = armoBEURLsDep
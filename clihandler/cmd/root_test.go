@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnvFlagName(t *testing.T) {
+	tests := map[string]string{
+		"format":         "KUBESCAPE_FORMAT",
+		"fail-threshold": "KUBESCAPE_FAIL_THRESHOLD",
+		"tls-cert-file":  "KUBESCAPE_TLS_CERT_FILE",
+	}
+	for name, expected := range tests {
+		assert.Equal(t, expected, envFlagName(name))
+	}
+}
+
+func TestInitFlagsFromEnv(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		env       map[string]string
+		format    string
+		threshold int
+		exclude   []string
+	}{
+		{name: "defaults", format: "pretty-printer", threshold: 100, exclude: []string{"kube-system"}},
+		{name: "env over defaults", env: map[string]string{"KUBESCAPE_FORMAT": "json", "KUBESCAPE_FAIL_THRESHOLD": "50"}, format: "json", threshold: 50, exclude: []string{"kube-system"}},
+		{name: "flags over env", args: []string{"--format", "sarif", "--fail-threshold", "10"}, env: map[string]string{"KUBESCAPE_FORMAT": "json", "KUBESCAPE_FAIL_THRESHOLD": "50"}, format: "sarif", threshold: 10, exclude: []string{"kube-system"}},
+		{name: "slice from env", env: map[string]string{"KUBESCAPE_EXCLUDE_NAMESPACES": "kube-system,kube-public"}, format: "pretty-printer", threshold: 100, exclude: []string{"kube-system", "kube-public"}},
+		{name: "slice flags over env", args: []string{"--exclude-namespaces", "dev"}, env: map[string]string{"KUBESCAPE_EXCLUDE_NAMESPACES": "kube-system,kube-public"}, format: "pretty-printer", threshold: 100, exclude: []string{"dev"}},
+	}
+	defer func(cmd *cobra.Command) { executedCmd = cmd }(executedCmd)
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var format string
+			var threshold int
+			var exclude []string
+			cmd := &cobra.Command{Use: "scan"}
+			cmd.Flags().StringVar(&format, "format", "pretty-printer", "")
+			cmd.Flags().IntVar(&threshold, "fail-threshold", 100, "")
+			cmd.Flags().StringSliceVar(&exclude, "exclude-namespaces", []string{"kube-system"}, "")
+			assert.NoError(t, cmd.ParseFlags(test.args))
+			for k, v := range test.env {
+				t.Setenv(k, v)
+			}
+
+			executedCmd = cmd
+			initFlagsFromEnv()
+
+			assert.Equal(t, test.format, format)
+			assert.Equal(t, test.threshold, threshold)
+			assert.Equal(t, test.exclude, exclude)
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/clihandler"
+	"github.com/armosec/kubescape/clihandler/cliobjects"
+	trivyv1 "github.com/armosec/kubescape/registryadaptors/trivy/v1"
+	"github.com/armosec/kubescape/sbom"
+	"github.com/spf13/cobra"
+)
+
+var (
+	sbomExample = `
+  # Generate the SPDX SBOMs of the images of the workloads of the cluster
+  kubescape sbom
+
+  # Generate the CycloneDX SBOMs of the images of the workloads of a namespace
+  kubescape sbom --namespace prod --format cyclonedx --output-dir sboms/prod
+
+  # Generate the SBOMs of the images of the manifests
+  kubescape sbom *.yaml
+
+  # Reference the SBOMs in the results of a scan
+  kubescape scan --sbom spdx --format json --format-version v2 --output results.json
+`
+)
+var sbomInfo = cliobjects.SBOM{}
+
+var sbomCmd = &cobra.Command{
+	Use:   "sbom [files...] [flags]",
+	Short: "Generate the SBOMs of the images of the workloads",
+	Long: `Generate the SPDX or CycloneDX SBOMs of the images of the workloads of the cluster, or of the files, by trivy (https://github.com/aquasecurity/trivy).
+The references of the SBOMs (the image, the path and the SHA256 of the SBOM, and the workloads running the image) are written to the index.json file of the output directory`,
+	Example: sbomExample,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sbomInfo.InputPatterns = args
+
+		if err := clihandler.CliSBOM(&sbomInfo); err != nil {
+			logger.L().Fatal(err.Error())
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(sbomCmd)
+	sbomCmd.PersistentFlags().StringVar(&sbomInfo.Format, "format", sbom.FormatSPDX, "Format of the SBOMs. Supported: spdx, cyclonedx")
+	sbomCmd.PersistentFlags().StringVarP(&sbomInfo.Namespace, "namespace", "n", "", "Namespace of the workloads of the cluster, all of the namespaces by default")
+	sbomCmd.PersistentFlags().StringVar(&sbomInfo.OutputDir, "output-dir", sbom.DefaultOutputDir, "Directory of the generated SBOMs")
+	sbomCmd.PersistentFlags().StringVar(&sbomInfo.ScannerPath, "image-scanner-path", trivyv1.DefaultTrivyPath, "Path of the trivy binary")
+	sbomCmd.PersistentFlags().BoolVar(&sbomInfo.Offline, "offline", false, "Generate the SBOMs without updating the vulnerability database of trivy")
+}
@@ -1,8 +1,17 @@
 package cmd
 
 import (
-	"github.com/armosec/k8s-interface/k8sinterface"
+	"fmt"
+	"time"
+
 	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/opaprocessor"
+	cosignv1 "github.com/armosec/kubescape/registryadaptors/cosign/v1"
+	trivyv1 "github.com/armosec/kubescape/registryadaptors/trivy/v1"
+	"github.com/armosec/kubescape/resourcehandler"
+	"github.com/armosec/kubescape/sbom"
+	"github.com/armosec/kubescape/tuihandler"
 	"github.com/spf13/cobra"
 )
 
@@ -24,7 +33,13 @@ var scanCmdExamples = `
   kubescape scan --verbose
 
   # Scan different clusters from the kubectl context 
-  kubescape scan --kube-context <kubernetes context>
+  kubescape scan --context <kubernetes context>
+
+  # Scan a cluster of another kubeconfig file
+  kubescape scan --kubeconfig ~/.kube/prod.yaml --cluster <cluster name>
+
+  # Scan with the defaults of the 'ci' profile of ~/.kubescape/config.yaml
+  kubescape scan --config-profile ci
   
 `
 
@@ -36,48 +51,132 @@ var scanCmd = &cobra.Command{
 	Example: scanCmdExamples,
 	Args: func(cmd *cobra.Command, args []string) error {
 		if len(args) > 0 {
-			if args[0] != "framework" && args[0] != "control" {
-				scanInfo.ScanAll = true
-				return frameworkCmd.RunE(cmd, append([]string{"all"}, args...))
+			if args[0] != "framework" && args[0] != "control" && args[0] != "workload" && args[0] != "helm" && args[0] != "kustomize" {
+				return frameworkCmd.RunE(cmd, append([]string{defaultFrameworks()}, args...))
 			}
 		}
 		return nil
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if len(args) == 0 {
-			scanInfo.ScanAll = true
-			return frameworkCmd.RunE(cmd, []string{"all"})
+			return frameworkCmd.RunE(cmd, []string{defaultFrameworks()})
 		}
 		return nil
 	},
 }
 
 func frameworkInitConfig() {
-	k8sinterface.SetClusterContextName(scanInfo.KubeContext)
+	if err := cautils.SetKubeconfig(scanInfo.Kubeconfig, scanInfo.KubeContext, scanInfo.KubeCluster); err != nil {
+		logger.L().Fatal(err.Error())
+	}
 }
 func init() {
 
 	cobra.OnInitialize(frameworkInitConfig)
 
 	rootCmd.AddCommand(scanCmd)
+	scanFlags = scanCmd.PersistentFlags()
 
 	scanCmd.PersistentFlags().StringVarP(&scanInfo.Account, "account", "", "", "Armo portal account ID. Default will load account ID from configMap or config file")
 	scanCmd.PersistentFlags().StringVarP(&scanInfo.KubeContext, "kube-context", "", "", "Kube context. Default will use the current-context")
+	scanCmd.PersistentFlags().StringVar(&scanInfo.KubeContext, "context", "", "Context of the kubeconfig of the scanned cluster, the current-context is not changed. Default will use the current-context")
+	scanCmd.PersistentFlags().StringVar(&scanInfo.Kubeconfig, "kubeconfig", "", "Path to the kubeconfig file. Default: $KUBECONFIG or ~/.kube/config, or the service account when running in-cluster")
+	scanCmd.PersistentFlags().StringVar(&scanInfo.KubeCluster, "cluster", "", "Cluster of the kubeconfig to scan, with the credentials of the context. Default will use the cluster of the context")
+	scanCmd.PersistentFlags().StringVar(&scanConfigPath, "config", "", "Path to the config file of the defaults of the scans (frameworks, formats, namespaces, thresholds, exceptions...), overridden by the flags of the command line. Default: ~/.kubescape/config.yaml when found [$KS_CONFIG]")
+	scanCmd.PersistentFlags().StringVar(&scanConfigProfile, "config-profile", "", "Profile of the config file, overriding the defaults of the config file. Default: the 'defaultProfile' of the config file [$KS_CONFIG_PROFILE]")
 	scanCmd.PersistentFlags().StringVar(&scanInfo.ControlsInputs, "controls-config", "", "Path to an controls-config obj. If not set will download controls-config from ARMO management portal")
 	scanCmd.PersistentFlags().StringVar(&scanInfo.UseExceptions, "exceptions", "", "Path to an exceptions obj. If not set will download exceptions from ARMO management portal")
+	scanCmd.PersistentFlags().StringVar(&scanInfo.CustomControls, "custom-controls", "", "Path to a directory of user-authored controls (JSON/YAML control files with Rego rules), scanned alongside the built-in controls")
+	scanCmd.PersistentFlags().StringVar(&scanInfo.PolicyVersion, "policy-version", "", "Version of the released policies (the tag of the regolibrary release, e.g. v1.0.172). Default is the latest release")
+	scanCmd.PersistentFlags().StringVar(&scanInfo.LockFile, "lockfile", "", fmt.Sprintf("Path to a lockfile (e.g. %s) of the scanned policies - created when missing, otherwise the scan fails if the policies differ from the lockfile", cautils.LockFileName))
+	scanCmd.PersistentFlags().StringVar(&scanInfo.ScoringConfig, "scoring-config", "", "Path to a JSON/YAML file overriding the severity and the score weight of controls, e.g. downgrading a control to Low or doubling the weight of the image controls")
+	scanCmd.PersistentFlags().StringVar(&scanInfo.OwnerLabel, "owner-label", "team", "Label of the owners of the resources, or of their namespaces. The risk-score is broken down by namespace and by the values of the label, e.g. --owner-label app.kubernetes.io/part-of")
+	scanCmd.PersistentFlags().BoolVar(&scanInfo.KeepOwnedResults, "keep-owned-results", false, "Report the failures of the controlled resources (e.g. the Pods of a Deployment) on their own. By default the failures are attributed to the top-level controller (Deployment, StatefulSet, CronJob...) and deduplicated")
+	scanCmd.PersistentFlags().StringVar(&scanInfo.GenerateExceptions, "generate-exceptions", "", "Write an exceptions file covering every failure of the scan, e.g. --generate-exceptions baseline.json. Scanning with '--exceptions baseline.json' fails only on new failures")
+	scanCmd.PersistentFlags().BoolVar(&scanInfo.Fix, "fix", false, "Fix the scanned files in place by the controls with deterministic fixes (e.g. add a securityContext, drop the added capabilities), keeping the comments and the formatting. Prints the diff of the fixed files")
+	scanCmd.PersistentFlags().BoolVar(&scanInfo.Interactive, "interactive", false, fmt.Sprintf("Browse the results in the terminal after the scan - frameworks, controls, resources and the failed paths of the resources. The exceptions of the marked resources are added to %s", tuihandler.DefaultExceptionsFile))
+	scanCmd.PersistentFlags().BoolVar(&scanInfo.Open, "open", false, "View the results in the browser after the scan - the html report with search and filters, served in a local web server until interrupted (ctrl+c)")
 	scanCmd.PersistentFlags().StringVar(&scanInfo.UseArtifactsFrom, "use-artifacts-from", "", "Load artifacts from local directory. If not used will download them")
-	scanCmd.PersistentFlags().StringVarP(&scanInfo.ExcludedNamespaces, "exclude-namespaces", "e", "", "Namespaces to exclude from scanning. Recommended: kube-system,kube-public")
+	scanCmd.PersistentFlags().BoolVar(&scanInfo.Offline, "offline", false, "No network calls (other than to the scanned cluster) - the artifacts are loaded from the '--use-artifacts-from' directory, default is the cache directory. A missing artifact fails the scan")
+	scanCmd.PersistentFlags().StringVarP(&scanInfo.ExcludedNamespaces, "exclude-namespaces", "e", "", "Namespaces to exclude from scanning, supports globs and /regex/ patterns. Recommended: kube-system,kube-public")
 	scanCmd.PersistentFlags().Float32VarP(&scanInfo.FailThreshold, "fail-threshold", "t", 100, "Failure threshold is the percent above which the command fails and returns exit code 1")
-	scanCmd.PersistentFlags().StringVarP(&scanInfo.Format, "format", "f", "pretty-printer", `Output format. Supported formats: "pretty-printer","json","junit","prometheus","pdf"`)
-	scanCmd.PersistentFlags().StringVar(&scanInfo.IncludeNamespaces, "include-namespaces", "", "scan specific namespaces. e.g: --include-namespaces ns-a,ns-b")
+	scanCmd.PersistentFlags().StringVar(&scanInfo.FailOptions.SeverityThreshold, "severity-threshold", "", "Fail (exit code 1) when controls of this severity or above failed. Supported: low/medium/high/critical")
+	scanCmd.PersistentFlags().StringVar(&scanInfo.FailOptions.FailOn, "fail-on", "", "Fail (exit code 1) when the number of failed controls is above the count, e.g. --fail-on count:5. Counts only the controls of '--severity-threshold' and above, when set")
+	scanCmd.PersistentFlags().StringVar(&scanInfo.FailOptions.ComplianceThreshold, "compliance-threshold", "", "Fail (exit code 1) when the compliance score (100 - risk-score) of a framework is below the threshold. Supported: a threshold for all frameworks and/or per framework, e.g. --compliance-threshold 80 or nsa=90,mitre=75")
+	scanCmd.PersistentFlags().StringVarP(&scanInfo.Format, "format", "f", "pretty-printer", `Output format. Supported formats: "pretty-printer","json","junit","prometheus","pdf","sarif","csv","xlsx","markdown","html","gitlab-codequality","github-annotations","oscal","cef","ndjson","dot","gotemplate". Use "," for several formats, e.g. --format json,pdf --output report`)
+	scanCmd.PersistentFlags().StringVar(&scanInfo.IncludeNamespaces, "include-namespaces", "", "scan specific namespaces, supports globs and /regex/ patterns. e.g: --include-namespaces ns-a,ns-b or --include-namespaces 'prod-*'")
+	scanCmd.PersistentFlags().StringVar(&scanInfo.Selectors.LabelSelector, "selector", "", "Label selector of the scanned namespaced resources, same syntax as kubectl. e.g: --selector app.kubernetes.io/part-of=payments")
+	scanCmd.PersistentFlags().StringVar(&scanInfo.Selectors.FieldSelector, "field-selector", "", "Field selector of the scanned namespaced resources, same syntax as kubectl. e.g: --field-selector metadata.name=nginx")
+	scanCmd.PersistentFlags().StringVar(&scanInfo.GitOptions.Token, "git-token", "", "Token of the scanned private git repositories, used with https:// URLs. Can be set by the KS_GIT_TOKEN environment variable as well")
+	scanCmd.PersistentFlags().StringVar(&scanInfo.GitOptions.SSHKeyPath, "git-ssh-key", "", "Path to the private key of the scanned private git repositories, used with ssh:// and git@ URLs. Default: the ssh agent")
 	scanCmd.PersistentFlags().BoolVarP(&scanInfo.Local, "keep-local", "", false, "If you do not want your Kubescape results reported to Armo backend. Use this flag if you ran with the '--submit' flag in the past and you do not want to submit your current scan results")
-	scanCmd.PersistentFlags().StringVarP(&scanInfo.Output, "output", "o", "", "Output file. Print output to file and not stdout")
-	scanCmd.PersistentFlags().BoolVar(&scanInfo.VerboseMode, "verbose", false, "Display all of the input resources and not only failed resources")
+	scanCmd.PersistentFlags().StringVarP(&scanInfo.Output, "output", "o", "", "Output file. Print output to file and not stdout. Use s3://, gs:// or az:// to upload to object storage, e.g. s3://bucket/path/report.json")
+	scanCmd.PersistentFlags().BoolVar(&scanInfo.VerboseMode, "verbose", false, "Display all of the input resources and not only failed resources. Implies '--include-passed'")
+	scanCmd.PersistentFlags().BoolVar(&scanInfo.IncludePassed, "include-passed", false, "Include the passed and the skipped resources of every control in the json, html and pdf outputs, the evidence of what was checked for audits")
+	scanCmd.PersistentFlags().StringSliceVar(&scanInfo.RedactOptions.Redact, "redact", []string{}, "Redact the sensitive data of the resources embedded in the json (v2), html and submitted reports, the controls are evaluated before the redaction. Supported: secrets (the data of the Secrets), env (the values of the environment variables), annotations. e.g. --redact secrets,env,annotations")
+	scanCmd.PersistentFlags().StringSliceVar(&scanInfo.RedactOptions.Annotations, "redact-annotations", []string{}, "Keys of the annotations redacted with '--redact annotations', supports globs, e.g. --redact-annotations 'vault.hashicorp.com/*'. Default: all of the annotations")
+	scanCmd.PersistentFlags().BoolVar(&scanInfo.RedactOptions.OmitRawResources, "omit-raw-resources", false, "Keep only the references of the resources (apiVersion, kind, name, namespace) in the json (v2), html and submitted reports instead of the full objects, shrinking the results of large clusters")
+	scanCmd.PersistentFlags().BoolVar(&scanInfo.Preflight, "preflight", false, "Check the permissions of the scan on the resources required by the controls by access reviews, and report the missing permissions without scanning")
 	scanCmd.PersistentFlags().BoolVar(&scanInfo.UseDefault, "use-default", false, "Load local policy object from default path. If not used will download latest")
 	scanCmd.PersistentFlags().StringSliceVar(&scanInfo.UseFrom, "use-from", nil, "Load local policy object from specified path. If not used will download latest")
 	scanCmd.PersistentFlags().BoolVarP(&scanInfo.Silent, "silent", "s", false, "Silent progress messages")
 	scanCmd.PersistentFlags().BoolVarP(&scanInfo.Submit, "submit", "", false, "Send the scan results to Armo management portal where you can see the results in a user-friendly UI, choose your preferred compliance framework, check risk results history and trends, manage exceptions, get remediation recommendations and much more. By default the results are not submitted")
+	scanCmd.PersistentFlags().StringVar(&scanInfo.SpoolDir, "spool-dir", "", "Spool the reports which could not be submitted to the ARMO portal (after the retries) to the directory, submitted by the next runs with the same directory")
+	scanCmd.PersistentFlags().BoolVar(&scanInfo.SubmitUncompressed, "submit-uncompressed", false, "Submit the reports to the ARMO portal uncompressed. By default the pages of the reports are gzipped")
 	scanCmd.PersistentFlags().StringVar(&scanInfo.HostSensorYamlPath, "host-scan-yaml", "", "Override default host sensor DaemonSet. Use this flag cautiously")
+	scanCmd.PersistentFlags().StringVar(&scanInfo.HostSensorOptions.Namespace, "host-sensor-namespace", "", "Namespace of the host sensor DaemonSet, created and deleted by the scan when missing. Default: kubescape-host-scanner")
+	scanCmd.PersistentFlags().StringVar(&scanInfo.HostSensorOptions.Image, "host-sensor-image", "", "Image of the host sensor, e.g. a mirror of quay.io/armosec/kube-host-sensor in a private registry")
+	scanCmd.PersistentFlags().StringArrayVar(&scanInfo.HostSensorOptions.Tolerations, "host-sensor-toleration", []string{}, "Toleration of the host sensor pods, <key>[=<value>][:<effect>], e.g. dedicated=gpu:NoSchedule. Can be repeated")
+	scanCmd.PersistentFlags().StringSliceVar(&scanInfo.HostSensorOptions.NodeSelector, "host-sensor-node-selector", []string{}, "Node labels of the host sensor pods, <label>=<value>, e.g. kubernetes.io/os=linux")
+	scanCmd.PersistentFlags().StringVar(&scanInfo.HostSensorOptions.CPULimit, "host-sensor-cpu-limit", "", "CPU limit of the host sensor pods, e.g. 100m")
+	scanCmd.PersistentFlags().StringVar(&scanInfo.HostSensorOptions.MemoryLimit, "host-sensor-memory-limit", "", "Memory limit of the host sensor pods, e.g. 300Mi")
+	scanCmd.PersistentFlags().BoolVar(&scanInfo.HostSensorOptions.Keep, "keep-host-sensor", false, "Leave the host sensor installed after the scan, the next scans reuse it when its options are unchanged")
+	scanCmd.PersistentFlags().StringVar(&scanInfo.ServeMetrics, "serve-metrics", "", "Scan periodically and expose the results in the prometheus format on the /metrics endpoint of the given address. e.g: --serve-metrics :8080")
+	scanCmd.PersistentFlags().DurationVar(&scanInfo.MetricsInterval, "metrics-interval", time.Hour, "Interval between scans when running with '--serve-metrics'")
+	scanCmd.PersistentFlags().IntVar(&scanInfo.FetchConcurrency, "fetch-concurrency", resourcehandler.DefaultFetchConcurrency, "Number of the Kubernetes API resources listed concurrently")
+	scanCmd.PersistentFlags().Float32Var(&scanInfo.FetchQPS, "fetch-qps", resourcehandler.DefaultFetchQPS, "Maximum requests per second to the Kubernetes API server, bursting to twice the rate")
+	scanCmd.PersistentFlags().Int64Var(&scanInfo.FetchPageSize, "fetch-page-size", resourcehandler.DefaultFetchPageSize, "Number of the objects of a list request to the Kubernetes API server, the lists are paginated. 0 lists all of the objects of a resource at once")
+	scanCmd.PersistentFlags().IntVar(&scanInfo.EvalConcurrency, "eval-concurrency", opaprocessor.DefaultEvalConcurrency, "Number of the controls evaluated concurrently")
+	scanCmd.PersistentFlags().BoolVar(&scanInfo.ImageScan, "enable-image-scan", false, "Scan the images of the workloads for vulnerabilities by trivy, the vulnerabilities are evaluated by the image controls and listed by the json, html and pdf formats")
+	scanCmd.PersistentFlags().StringVar(&scanInfo.ImageScannerPath, "image-scanner-path", trivyv1.DefaultTrivyPath, "Path of the trivy binary scanning the images with '--enable-image-scan'")
+	scanCmd.PersistentFlags().BoolVar(&scanInfo.ImageSignatures, "verify-image-signatures", false, "Verify the signatures of the images of the workloads by cosign, the results are evaluated by the controls of the images signatures and listed by the json, html and pdf formats")
+	scanCmd.PersistentFlags().StringSliceVar(&scanInfo.SignatureKeys, "image-signature-key", []string{}, "Public key trusted for signing the images, a path or a KMS URI (e.g. awskms:///alias/cosign)")
+	scanCmd.PersistentFlags().StringArrayVar(&scanInfo.TrustedIdentities, "image-signature-identity", []string{}, "Keyless identity trusted for signing the images, <OIDC issuer>=<subject regexp>, e.g. https://token.actions.githubusercontent.com=^https://github.com/my-org/")
+	scanCmd.PersistentFlags().StringSliceVar(&scanInfo.AttestationTypes, "image-attestation-type", []string{}, "Attestation required from the images, signed by a trusted key or identity, e.g. slsaprovenance, spdxjson")
+	scanCmd.PersistentFlags().StringVar(&scanInfo.CosignPath, "cosign-path", cosignv1.DefaultCosignPath, "Path of the cosign binary verifying the images with '--verify-image-signatures'")
+	scanCmd.PersistentFlags().StringVar(&scanInfo.SBOMFormat, "sbom", "", "Generate the SBOMs of the images of the workloads by trivy, referenced by the json and html formats. Supported: spdx, cyclonedx")
+	scanCmd.PersistentFlags().StringVar(&scanInfo.SBOMDir, "sbom-dir", sbom.DefaultOutputDir, "Directory of the SBOMs generated with '--sbom'")
+	scanCmd.PersistentFlags().BoolVar(&scanInfo.ImageMetadata, "enable-image-metadata", false, "Look up the configurations of the images of the workloads (user, exposed ports, base image) in their registries, evaluated by the controls of the images configurations. The registries are authenticated by the docker credentials and the ECR, GCR and ACR token helpers")
+	scanCmd.PersistentFlags().StringVar(&scanInfo.DockerConfig, "docker-config", "", "Directory of the docker config.json with the credentials of the registries, used by '--enable-image-metadata', '--enable-image-scan', '--verify-image-signatures' and '--sbom'. Default: $DOCKER_CONFIG or ~/.docker")
+	scanCmd.PersistentFlags().BoolVar(&scanInfo.Cached, "cached", false, "Load the Kubernetes objects from the cache of the previous '--cached' scans of the cluster, e.g. to try other frameworks or exceptions. The resources missing from the cache are listed and added to it, the secrets are never cached")
+	scanCmd.PersistentFlags().DurationVar(&scanInfo.CacheMaxAge, "cache-max-age", resourcehandler.DefaultCacheMaxAge, "Age of the cached objects when running with '--cached', the older objects are listed again")
+	scanCmd.PersistentFlags().DurationVar(&scanInfo.Timeouts.Scan, "timeout", 0, "Timeout of the whole scan, e.g. 10m. The in-flight requests are cancelled and the host sensor is removed, as when interrupted (ctrl+c). Default: no timeout")
+	scanCmd.PersistentFlags().DurationVar(&scanInfo.Timeouts.Download, "download-timeout", 0, "Timeout of downloading/loading the policies, e.g. 1m. Default: no timeout")
+	scanCmd.PersistentFlags().DurationVar(&scanInfo.Timeouts.Fetch, "fetch-timeout", 0, "Timeout of fetching the resources from the cluster, the data of the host sensor and of the images included, e.g. 5m. Default: no timeout")
+	scanCmd.PersistentFlags().DurationVar(&scanInfo.Timeouts.Eval, "eval-timeout", 0, "Timeout of evaluating the controls, e.g. 5m. Default: no timeout")
+	scanCmd.PersistentFlags().BoolVar(&scanInfo.Profile, "profile", false, "Print the time spent in each phase of the scan (policies download, resources fetch, controls evaluation, results processing, printing) and the slowest controls")
+	scanCmd.PersistentFlags().StringVar(&scanInfo.ProfileDir, "profile-dir", "", "Write the cpu and heap pprof profiles of the scan to the directory, analyzed by 'go tool pprof'")
+	scanCmd.PersistentFlags().BoolVar(&scanInfo.Watch, "watch", false, "Keep watching the cluster after the scan, and print the new and the fixed failures as resources are created/updated/deleted. Only the controls affected by the changed resources are re-evaluated")
+	scanCmd.PersistentFlags().StringVar(&scanInfo.Forward, "forward", "", "Forward the failed controls as CEF events to a syslog server. Supported: syslog://host:port (UDP), syslog+tcp://host:port")
+	scanCmd.PersistentFlags().StringSliceVar(&scanInfo.Notify, "notify", []string{}, "Post a summary of the results to a Slack/Microsoft Teams incoming webhook. Use a 'slack+'/'teams+' prefix when the webhook kind can not be detected from the URL")
+	scanCmd.PersistentFlags().StringVar(&scanInfo.NotifyReportURL, "notify-report-url", "", "Link to the full report, part of the notification. Default: the ARMO portal, when submitting the results")
+	scanCmd.PersistentFlags().StringSliceVar(&scanInfo.EmailOptions.To, "email-to", []string{}, "Email the results and the output files to the given addresses")
+	scanCmd.PersistentFlags().StringVar(&scanInfo.EmailOptions.From, "email-from", "", "Sender address of the results email")
+	scanCmd.PersistentFlags().StringVar(&scanInfo.EmailOptions.Subject, "email-subject", "Kubescape scan results", "Subject of the results email")
+	scanCmd.PersistentFlags().StringVar(&scanInfo.EmailOptions.SMTPServer, "smtp-server", "", "SMTP server address to send the results email with, e.g. smtp.example.com:587")
+	scanCmd.PersistentFlags().StringVar(&scanInfo.EmailOptions.Username, "smtp-username", "", "SMTP username")
+	scanCmd.PersistentFlags().StringVar(&scanInfo.EmailOptions.Password, "smtp-password", "", "SMTP password. Can be set by the KS_SMTP_PASSWORD environment variable as well")
+	scanCmd.PersistentFlags().StringVar(&scanInfo.EmailOptions.TLS, "smtp-tls", "starttls", "SMTP connection security. Supported: starttls/tls/none")
+	scanCmd.PersistentFlags().BoolVar(&scanInfo.Store, "store", false, "Persist the summary of the scan in a local SQLite database. Use 'kubescape history' to view the score trends")
+	scanCmd.PersistentFlags().StringVar(&scanInfo.StorePath, "store-path", "", "Path to the results store database. Default: results.db in the cache directory")
+	scanCmd.PersistentFlags().StringVar(&scanInfo.OutputTemplate, "output-template", "", "Path to a go template file to render the results with. Used with '--format gotemplate'")
+	scanCmd.PersistentFlags().StringVar(&scanInfo.PdfOptions.Logo, "pdf-logo", "", "Path to a png/jpg logo to replace the kubescape logo in the pdf report")
+	scanCmd.PersistentFlags().StringVar(&scanInfo.PdfOptions.Title, "pdf-title", "", "Title of the pdf report")
+	scanCmd.PersistentFlags().StringVar(&scanInfo.PdfOptions.Footer, "pdf-footer", "", "Footer text of every page of the pdf report")
+	scanCmd.PersistentFlags().StringVar(&scanInfo.PdfOptions.Orientation, "pdf-orientation", "portrait", "Page orientation of the pdf report. Supported: portrait/landscape")
+	scanCmd.PersistentFlags().StringVar(&scanInfo.PdfOptions.PageSize, "pdf-page-size", "A4", "Page size of the pdf report. Supported: A3/A4/A5/Letter/Legal")
+	scanCmd.PersistentFlags().StringVar(&scanInfo.TimestampOptions.Timezone, "timezone", "", "Time zone of the timestamps of the reports (RFC3339), e.g. Europe/Berlin or Local. Default is UTC")
+	scanCmd.PersistentFlags().BoolVar(&scanInfo.TimestampOptions.UTC, "utc", false, "Format the timestamps of the reports in UTC (RFC3339), the default when no '--timezone' is set")
 	scanCmd.PersistentFlags().StringVar(&scanInfo.FormatVersion, "format-version", "v1", "Output object can be differnet between versions, this is for maintaining backward and forward compatibility. Supported:'v1'/'v2'")
 
 	// hidden flags
@@ -85,7 +184,7 @@ func init() {
 	scanCmd.PersistentFlags().MarkHidden("silent")         // this flag should be deprecated since we added the --logger support
 	// scanCmd.PersistentFlags().MarkHidden("format-version") // meant for testing different output approaches and not for common use
 
-	hostF := scanCmd.PersistentFlags().VarPF(&scanInfo.HostSensorEnabled, "enable-host-scan", "", "Deploy ARMO K8s host-sensor daemonset in the scanned cluster. Deleting it right after we collecting the data. Required to collect valueable data from cluster nodes for certain controls. Yaml file: https://raw.githubusercontent.com/armosec/kubescape/master/hostsensorutils/hostsensor.yaml")
+	hostF := scanCmd.PersistentFlags().VarPF(&scanInfo.HostSensorEnabled, "enable-host-scan", "", "Deploy ARMO K8s host-sensor daemonset in the scanned cluster. Deleting it right after we collecting the data, unless '--keep-host-sensor' is set. Required to collect valueable data from cluster nodes for certain controls. Yaml file: https://raw.githubusercontent.com/armosec/kubescape/master/hostsensorutils/hostsensor.yaml")
 	hostF.NoOptDefVal = "true"
 	hostF.DefValue = "false, for no TTY in stdin"
 
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/cautils/logger/helpers"
+	"github.com/spf13/pflag"
+)
+
+var scanConfigPath = ""
+var scanConfigProfile = ""
+
+// scanFlags the persistent flags of the scan command, set by the config file
+var scanFlags *pflag.FlagSet
+
+// scanDefaults the defaults of the config file of the scans, loaded once
+var scanDefaults *cautils.ScanDefaults
+
+// loadScanDefaults loads the defaults of the selected profile of the config file. A missing default config file is ignored
+func loadScanDefaults() *cautils.ScanDefaults {
+	if scanDefaults != nil {
+		return scanDefaults
+	}
+	scanDefaults = &cautils.ScanDefaults{}
+
+	configPath := scanConfigPath
+	if configPath == "" {
+		configPath = os.Getenv("KS_CONFIG")
+	}
+	explicit := configPath != ""
+	if !explicit {
+		configPath = cautils.ScanConfigFileFullPath()
+	}
+	profile := scanConfigProfile
+	if profile == "" {
+		profile = os.Getenv("KS_CONFIG_PROFILE")
+	}
+
+	config, err := cautils.LoadScanConfig(configPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) && !explicit && profile == "" {
+			return scanDefaults
+		}
+		logger.L().Fatal(err.Error())
+	}
+	defaults, err := config.Defaults(profile)
+	if err != nil {
+		logger.L().Fatal(fmt.Sprintf("%s: %s", configPath, err.Error()))
+	}
+	logger.L().Debug("loaded the config file", helpers.String("path", configPath), helpers.String("profile", profile))
+	scanDefaults = defaults
+	return scanDefaults
+}
+
+// applyScanConfig sets the flags of the scan to the defaults of the config file, unless set in the command line
+func applyScanConfig() {
+	for name, value := range loadScanDefaults().Flags() {
+		if scanFlags.Changed(name) {
+			continue
+		}
+		if (name == "include-namespaces" || name == "exclude-namespaces") && (scanFlags.Changed("include-namespaces") || scanFlags.Changed("exclude-namespaces")) {
+			continue // the namespaces of the command line replace the namespaces of the config file
+		}
+		if err := scanFlags.Set(name, value); err != nil {
+			logger.L().Fatal(fmt.Sprintf("bad argument: invalid '%s' in the config file: %s", name, err.Error()))
+		}
+	}
+}
+
+// defaultFrameworks returns the frameworks of the scans without a framework, all of the frameworks unless set in the config file
+func defaultFrameworks() string {
+	if frameworks := loadScanDefaults().Frameworks; len(frameworks) > 0 {
+		return strings.Join(frameworks, ",")
+	}
+	return "all"
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/clihandler"
+	"github.com/armosec/kubescape/clihandler/cliobjects"
+	"github.com/armosec/kubescape/opaprocessor"
+	"github.com/armosec/kubescape/resourcehandler"
+	"github.com/spf13/cobra"
+)
+
+var (
+	serverExample = `
+  # Serve the API on port 8080 of the local host
+  kubescape server
+
+  # Trigger a scan of the NSA framework, and fetch the results when completed
+  curl -X POST localhost:8080/v1/scan -d '{"frameworks": ["nsa"], "excludeNamespaces": ["kube-system"]}'
+  curl localhost:8080/v1/scans/<scan ID>/results
+
+  # Stream the progress of a scan
+  curl -N localhost:8080/v1/scans/<scan ID>/progress
+
+  # Serve the APIs on all of the interfaces over TLS, authenticated by a bearer token
+  KUBESCAPE_TOKEN=<token> kubescape server --address :8080 --grpc-address :9090 --tls-cert-file tls.crt --tls-key-file tls.key
+  curl -H "Authorization: Bearer <token>" https://<host>:8080/v1/scans
+`
+)
+var serverInfo = cliobjects.Server{}
+
+var serverCmd = &cobra.Command{
+	Use:     "server [flags]",
+	Short:   "Serve a REST API for triggering scans, fetching their results and streaming their progress",
+	Long:    ``,
+	Example: serverExample,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if serverInfo.ScanInfo.Submit && serverInfo.ScanInfo.Local {
+			logger.L().Fatal("you can use `keep-local` or `submit`, but not both")
+		}
+		if err := serverInfo.ScanInfo.RedactOptions.Validate(); err != nil {
+			logger.L().Fatal(err.Error())
+		}
+		serverInfo.ScanInfo.Init()
+		cautils.SetSilentMode(true) // no terminal for the progress bars, the progress is streamed by the API
+		if err := clihandler.CliServer(&serverInfo); err != nil {
+			logger.L().Fatal(err.Error())
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(serverCmd)
+	serverCmd.PersistentFlags().StringVar(&serverInfo.Address, "address", "localhost:8080", "Address of the API server. The non-local addresses require '--token' or '--tls-client-ca-file'")
+	serverCmd.PersistentFlags().StringVar(&serverInfo.GRPCAddress, "grpc-address", "", "Address of the gRPC API server (the Scanner service of server/scannerpb/scanner.proto). Default: the gRPC API is not served")
+	serverCmd.PersistentFlags().StringVar(&serverInfo.Token, "token", "", "Bearer token authenticating the requests of the APIs ('Authorization: Bearer <token>'), prefer $KUBESCAPE_TOKEN. Default: the requests are not authenticated")
+	serverCmd.PersistentFlags().StringVar(&serverInfo.TLSCertFile, "tls-cert-file", "", "Path to the TLS certificate of the APIs. Default: the APIs are served without TLS")
+	serverCmd.PersistentFlags().StringVar(&serverInfo.TLSKeyFile, "tls-key-file", "", "Path to the TLS private key of the APIs")
+	serverCmd.PersistentFlags().StringVar(&serverInfo.TLSClientCAFile, "tls-client-ca-file", "", "Path to the certificate authorities verifying the client certificates (mTLS), the client certificates are required when set")
+	serverCmd.PersistentFlags().IntVar(&serverInfo.HistoryLimit, "history-limit", 20, "Number of completed scans to keep, the oldest are removed first")
+	serverCmd.PersistentFlags().StringVarP(&serverInfo.ScanInfo.Account, "account", "", "", "Armo portal account ID. Default will load account ID from configMap or config file")
+	serverCmd.PersistentFlags().StringVar(&serverInfo.ScanInfo.ControlsInputs, "controls-config", "", "Path to an controls-config obj. If not set will download controls-config from ARMO management portal")
+	serverCmd.PersistentFlags().StringVar(&serverInfo.ScanInfo.UseExceptions, "exceptions", "", "Path to an exceptions obj. If not set will download exceptions from ARMO management portal")
+	serverCmd.PersistentFlags().StringSliceVar(&serverInfo.ScanInfo.UseFrom, "use-from", nil, "Load local policy object from specified path. If not used will download latest")
+	serverCmd.PersistentFlags().BoolVarP(&serverInfo.ScanInfo.Submit, "submit", "", false, "Send the results of all of the scans to Armo management portal")
+	serverCmd.PersistentFlags().BoolVarP(&serverInfo.ScanInfo.Local, "keep-local", "", false, "If you do not want your Kubescape results reported to Armo backend")
+	serverCmd.PersistentFlags().StringVar(&serverInfo.ScanInfo.Forward, "forward", "", "Forward the failed controls as CEF events to a syslog server. Supported: syslog://host:port (UDP), syslog+tcp://host:port")
+	serverCmd.PersistentFlags().StringSliceVar(&serverInfo.ScanInfo.Notify, "notify", []string{}, "Post a summary of the results to a Slack/Microsoft Teams incoming webhook")
+	serverCmd.PersistentFlags().StringSliceVar(&serverInfo.ScanInfo.RedactOptions.Redact, "redact", []string{cautils.RedactSecrets, cautils.RedactEnv}, "Redact the sensitive data of the resources of the results, the scan requests can redact more. Supported: secrets, env, annotations. Use --redact '' for the unredacted resources")
+	serverCmd.PersistentFlags().StringSliceVar(&serverInfo.ScanInfo.RedactOptions.Annotations, "redact-annotations", []string{}, "Keys of the annotations redacted with '--redact annotations', supports globs. Default: all of the annotations")
+	serverCmd.PersistentFlags().BoolVar(&serverInfo.ScanInfo.RedactOptions.OmitRawResources, "omit-raw-resources", false, "Keep only the references of the resources (apiVersion, kind, name, namespace) in the results instead of the full objects")
+	serverCmd.PersistentFlags().IntVar(&serverInfo.ScanInfo.FetchConcurrency, "fetch-concurrency", resourcehandler.DefaultFetchConcurrency, "Number of the Kubernetes API resources listed concurrently")
+	serverCmd.PersistentFlags().Float32Var(&serverInfo.ScanInfo.FetchQPS, "fetch-qps", resourcehandler.DefaultFetchQPS, "Maximum requests per second to the Kubernetes API server, bursting to twice the rate")
+	serverCmd.PersistentFlags().Int64Var(&serverInfo.ScanInfo.FetchPageSize, "fetch-page-size", resourcehandler.DefaultFetchPageSize, "Number of the objects of a list request to the Kubernetes API server, the lists are paginated. 0 lists all of the objects of a resource at once")
+	serverCmd.PersistentFlags().IntVar(&serverInfo.ScanInfo.EvalConcurrency, "eval-concurrency", opaprocessor.DefaultEvalConcurrency, "Number of the controls evaluated concurrently")
+}
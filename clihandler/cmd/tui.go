@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/clihandler"
+	"github.com/armosec/kubescape/clihandler/cliobjects"
+	"github.com/armosec/kubescape/tuihandler"
+	"github.com/spf13/cobra"
+)
+
+var (
+	tuiExample = `
+  # Browse the results of a scan
+  kubescape scan --format json --format-version v2 --output results.json
+  kubescape tui results.json
+
+  # Add the exceptions of the marked resources to the exceptions of the scans
+  kubescape tui results.json --exceptions-output exceptions.json
+  kubescape scan --exceptions exceptions.json
+`
+)
+var tuiInfo = cliobjects.TUI{}
+
+var tuiCmd = &cobra.Command{
+	Use:     "tui <results file> [flags]",
+	Short:   "Browse the results of a scan in the terminal - frameworks, controls, resources and the failed paths of the resources",
+	Long:    `The results file is generated by 'kubescape scan --format json --format-version v2'. Keys: up/down (j/k) move, enter (right) open, esc (left) back, space (x) mark the failed resource for an exception, q quit`,
+	Example: tuiExample,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return fmt.Errorf("expected a results file, received %d arguments", len(args))
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		tuiInfo.ResultsFile = args[0]
+
+		if err := clihandler.CliTUI(&tuiInfo); err != nil {
+			logger.L().Fatal(err.Error())
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(tuiCmd)
+	tuiCmd.PersistentFlags().StringVar(&tuiInfo.ExceptionsOutput, "exceptions-output", tuihandler.DefaultExceptionsFile, "Exceptions file the exceptions of the marked resources are added to, created when missing")
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/clihandler"
+	"github.com/armosec/kubescape/clihandler/cliobjects"
+	"github.com/spf13/cobra"
+)
+
+var (
+	validateExample = `
+  # Validate the results of a scan against the schema of the results
+  kubescape scan --format json --format-version v2 --output results.json
+  kubescape validate results.json
+
+  # Print the JSON schema of the results, e.g. for generating the parsers of the results
+  kubescape validate --print-schema > results-v2.json
+`
+)
+var validateResultsInfo = cliobjects.ValidateResults{}
+
+var validateCmd = &cobra.Command{
+	Use:     "validate <results file> [flags]",
+	Short:   "Validate a results file against the JSON schema of the results",
+	Long:    `The results file is generated by 'kubescape scan --format json --format-version v2'. Exits with an error listing the mismatches of the schema when the results file does not match it`,
+	Example: validateExample,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if validateResultsInfo.PrintSchema {
+			if len(args) != 0 {
+				return fmt.Errorf("expected no arguments with '--print-schema', received %d arguments", len(args))
+			}
+			return nil
+		}
+		if len(args) != 1 {
+			return fmt.Errorf("expected a results file, received %d arguments", len(args))
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) > 0 {
+			validateResultsInfo.ResultsFile = args[0]
+		}
+
+		if err := clihandler.CliValidateResults(&validateResultsInfo); err != nil {
+			logger.L().Fatal(err.Error())
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+	validateCmd.PersistentFlags().BoolVar(&validateResultsInfo.PrintSchema, "print-schema", false, "Print the JSON schema of the results instead of validating a results file")
+}
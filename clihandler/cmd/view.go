@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/clihandler"
+	"github.com/armosec/kubescape/clihandler/cliobjects"
+	"github.com/spf13/cobra"
+)
+
+var (
+	viewExample = `
+  # View the results of a scan in the browser
+  kubescape scan --format json --format-version v2 --output results.json
+  kubescape view results.json
+
+  # Serve the results on a fixed port, e.g. from a remote machine
+  kubescape view results.json --address 0.0.0.0:8080
+`
+)
+var viewResultsInfo = cliobjects.ViewResults{}
+
+var viewCmd = &cobra.Command{
+	Use:     "view <results file> [flags]",
+	Short:   "View the results of a scan in the browser - the html report with search and filters, served in a local web server",
+	Long:    `The results file is generated by 'kubescape scan --format json --format-version v2'. The results are served until interrupted (ctrl+c)`,
+	Example: viewExample,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return fmt.Errorf("expected a results file, received %d arguments", len(args))
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		viewResultsInfo.ResultsFile = args[0]
+
+		if err := clihandler.CliViewResults(&viewResultsInfo); err != nil {
+			logger.L().Fatal(err.Error())
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(viewCmd)
+	viewCmd.PersistentFlags().StringVar(&viewResultsInfo.Address, "address", clihandler.DefaultViewAddress, "Address the results are served on, a random port of the local host by default")
+}
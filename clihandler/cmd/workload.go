@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/clihandler"
+	"github.com/armosec/opa-utils/reporthandling"
+	"github.com/spf13/cobra"
+)
+
+var (
+	workloadNamespace string
+	workloadExample   = `
+  # Scan the nginx deployment of the web namespace
+  kubescape scan workload deployment/nginx -n web
+
+  # Scan a pod of the default namespace, kubectl short names are supported
+  kubescape scan workload po/debug
+
+  The workload is scanned with its ServiceAccount, the RBAC bindings of the ServiceAccount, the NetworkPolicies and the Namespace
+`
+)
+
+var workloadCmd = &cobra.Command{
+	Use:     "workload <kind>/<name> [flags]",
+	Short:   "Scan a single workload of the cluster and its related objects",
+	Example: workloadExample,
+	Long:    "Execute a scan of all frameworks on a single workload of the running Kubernetes cluster, for quick developer feedback",
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return fmt.Errorf("requires a single workload, e.g. deployment/nginx")
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flagValidationFramework()
+		workload, err := cautils.NewWorkloadIdentifier(args[0], workloadNamespace)
+		if err != nil {
+			logger.L().Fatal(err.Error())
+		}
+		scanInfo.Workload = workload
+		// pull the namespaced resources of the workload namespace only, the host sensor data is not related to the workload
+		scanInfo.IncludeNamespaces = workload.Namespace
+		scanInfo.ExcludedNamespaces = ""
+		scanInfo.HostSensorEnabled.SetBool(false)
+
+		scanInfo.ScanAll = true
+		scanInfo.FrameworkScan = true
+		scanInfo.SetPolicyIdentifiers([]string{}, reporthandling.KindFramework)
+
+		scanInfo.Init()
+		cautils.SetSilentMode(scanInfo.Silent)
+		if err := clihandler.ScanCliSetup(&scanInfo); err != nil {
+			logger.L().Fatal(err.Error())
+		}
+		return nil
+	},
+}
+
+func init() {
+	scanCmd.AddCommand(workloadCmd)
+	workloadCmd.Flags().StringVarP(&workloadNamespace, "namespace", "n", "default", "Namespace of the workload")
+}
@@ -0,0 +1,37 @@
+package clihandler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/opa-utils/reporthandling"
+)
+
+// ScanSession runs the scan without printing its results, and returns the session of the results, for the programs embedding the scans.
+// The scan is cancelled when the context is done, the host sensor is removed then
+func ScanSession(ctx context.Context, scanInfo *cautils.ScanInfo) (*cautils.OPASessionObj, error) {
+	recorder := &sessionRecorder{}
+	interfaces := getInterfaces(ctx, scanInfo)
+	interfaces.printerHandlers = append(interfaces.printerHandlers, recorder)
+
+	if _, err := runScan(ctx, scanInfo, interfaces); err != nil {
+		return nil, err
+	}
+	if recorder.sessionObj == nil {
+		return nil, fmt.Errorf("failed to scan, no results")
+	}
+	return recorder.sessionObj, nil
+}
+
+// LoadFrameworks returns the frameworks of the names, all of the frameworks when none.
+// The frameworks are loaded from the files of useFrom, downloaded from the released policies otherwise
+func LoadFrameworks(names []string, useFrom []string) ([]reporthandling.Framework, error) {
+	return getFrameworks(getPolicyGetter(useFrom, "", true, nil), strings.Join(names, ","))
+}
+
+// ListFrameworks returns the names of the frameworks, of the files of useFrom or of the released policies
+func ListFrameworks(useFrom []string) []string {
+	return listFrameworksNames(getPolicyGetter(useFrom, "", true, nil))
+}
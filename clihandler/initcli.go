@@ -1,25 +1,30 @@
 package clihandler
 
 import (
+	"context"
 	"fmt"
 	"io/fs"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/armosec/armoapi-go/armotypes"
 	"github.com/armosec/k8s-interface/k8sinterface"
 	"github.com/armosec/kubescape/resultshandling/printer"
 
 	"github.com/armosec/kubescape/cautils"
-	"github.com/armosec/kubescape/cautils/getter"
 	"github.com/armosec/kubescape/cautils/logger"
 	"github.com/armosec/kubescape/cautils/logger/helpers"
 	"github.com/armosec/kubescape/hostsensorutils"
 	"github.com/armosec/kubescape/opaprocessor"
 	"github.com/armosec/kubescape/policyhandler"
+	trivyv1 "github.com/armosec/kubescape/registryadaptors/trivy/v1"
 	"github.com/armosec/kubescape/resourcehandler"
 	"github.com/armosec/kubescape/resultshandling"
 	"github.com/armosec/kubescape/resultshandling/reporter"
+	"github.com/armosec/kubescape/resultshandling/uploader"
 	"github.com/armosec/opa-utils/reporthandling"
+	"github.com/armosec/opa-utils/reporthandling/results/v1/reportsummary"
 	"github.com/mattn/go-isatty"
 )
 
@@ -27,15 +32,25 @@ type componentInterfaces struct {
 	tenantConfig      cautils.ITenantConfig
 	resourceHandler   resourcehandler.IResourceHandler
 	report            reporter.IReport
-	printerHandler    printer.IPrinter
+	printerHandlers   []printer.IPrinter
 	hostSensorHandler hostsensorutils.IHostSensor
 }
 
-func getInterfaces(scanInfo *cautils.ScanInfo) componentInterfaces {
+func getInterfaces(ctx context.Context, scanInfo *cautils.ScanInfo) componentInterfaces {
+	if scanInfo.Offline {
+		if err := validateOffline(scanInfo); err != nil {
+			logger.L().Fatal(err.Error())
+		}
+	}
+
+	if scanInfo.Fix && scanInfo.GetScanningEnvironment() == cautils.ScanCluster {
+		logger.L().Fatal("bad argument: '--fix' fixes the scanned files, use 'kubescape fix' for fixing the resources of the cluster")
+	}
 
 	// ================== setup k8s interface object ======================================
 	var k8s *k8sinterface.KubernetesApi
 	if scanInfo.GetScanningEnvironment() == cautils.ScanCluster {
+		setKubernetesRateLimits(scanInfo.FetchQPS)
 		k8s = getKubernetesApi()
 		if k8s == nil {
 			logger.L().Fatal("failed connecting to Kubernetes cluster")
@@ -58,13 +73,15 @@ func getInterfaces(scanInfo *cautils.ScanInfo) componentInterfaces {
 
 	// ================== version testing ======================================
 
-	v := cautils.NewIVersionCheckHandler()
-	v.CheckLatestVersion(cautils.NewVersionCheckRequest(cautils.BuildNumber, policyIdentifierNames(scanInfo.PolicyIdentifier), "", scanInfo.GetScanningEnvironment()))
+	if !scanInfo.Offline {
+		v := cautils.NewIVersionCheckHandler()
+		v.CheckLatestVersion(cautils.NewVersionCheckRequest(cautils.BuildNumber, policyIdentifierNames(scanInfo.PolicyIdentifier), "", scanInfo.GetScanningEnvironment()))
+	}
 
 	// ================== setup host sensor object ======================================
 
 	hostSensorHandler := getHostSensorHandler(scanInfo, k8s)
-	if err := hostSensorHandler.Init(); err != nil {
+	if err := hostSensorHandler.Init(ctx); err != nil {
 		logger.L().Error("failed to init host sensor", helpers.Error(err))
 		hostSensorHandler = &hostsensorutils.HostSensorHandlerMock{}
 	}
@@ -75,9 +92,24 @@ func getInterfaces(scanInfo *cautils.ScanInfo) componentInterfaces {
 
 	// ================== setup registry adaptors ======================================
 
-	registryAdaptors, err := resourcehandler.NewRegistryAdaptors()
-	if err != nil {
-		logger.L().Error("failed to initialize registry adaptors", helpers.Error(err))
+	if scanInfo.DockerConfig != "" { // the registries credentials of trivy and cosign too
+		os.Setenv("DOCKER_CONFIG", scanInfo.DockerConfig)
+	}
+	registryAdaptors := &resourcehandler.RegistryAdaptors{} // no images vulnerabilities when offline
+	if !scanInfo.Offline {
+		var err error
+		if registryAdaptors, err = resourcehandler.NewRegistryAdaptors(); err != nil {
+			logger.L().Error("failed to initialize registry adaptors", helpers.Error(err))
+		}
+	}
+	if scanInfo.ImageScan {
+		registryAdaptors.AddAdaptor(trivyv1.NewTrivyAdaptor(scanInfo.ImageScannerPath, scanInfo.Offline))
+	}
+	if scanInfo.ImageSignatures {
+		registryAdaptors.SetSignatureVerifier(getSignatureVerifier(scanInfo))
+	}
+	if scanInfo.ImageMetadata {
+		registryAdaptors.SetMetadataClient(getMetadataClient())
 	}
 
 	// ================== setup resource collector object ======================================
@@ -87,11 +119,25 @@ func getInterfaces(scanInfo *cautils.ScanInfo) componentInterfaces {
 	// ================== setup reporter & printer objects ======================================
 
 	// reporting behavior - setup reporter
-	reportHandler := getReporter(tenantConfig, scanInfo.Submit, scanInfo.FrameworkScan, len(scanInfo.InputPatterns) == 0)
-
-	// setup printer
-	printerHandler := resultshandling.NewPrinter(scanInfo.Format, scanInfo.FormatVersion, scanInfo.VerboseMode)
-	printerHandler.SetWriter(scanInfo.Output)
+	reportHandler := getReporter(ctx, tenantConfig, scanInfo)
+
+	// setup printers - a printer per output format
+	printerHandlers := []printer.IPrinter{}
+	for _, format := range scanInfo.GetFormats() {
+		printerHandler := resultshandling.NewPrinter(format, scanInfo)
+		outputFile := scanInfo.GetOutputFile(format)
+		if uploader.IsRemoteOutput(outputFile) {
+			// the printer writes to a local file, which is uploaded to the object storage
+			u, err := uploader.NewUploader(outputFile)
+			if err != nil {
+				logger.L().Fatal(err.Error())
+			}
+			printerHandler = uploader.NewUploadPrinter(printerHandler, u)
+		}
+		printerHandler.SetWriter(outputFile)
+		printerHandlers = append(printerHandlers, printerHandler)
+	}
+	printerHandlers = append(printerHandlers, getForwarders(scanInfo, tenantConfig)...)
 
 	// ================== return interface ======================================
 
@@ -99,7 +145,7 @@ func getInterfaces(scanInfo *cautils.ScanInfo) componentInterfaces {
 		tenantConfig:      tenantConfig,
 		resourceHandler:   resourceHandler,
 		report:            reportHandler,
-		printerHandler:    printerHandler,
+		printerHandlers:   printerHandlers,
 		hostSensorHandler: hostSensorHandler,
 	}
 }
@@ -107,23 +153,62 @@ func getInterfaces(scanInfo *cautils.ScanInfo) componentInterfaces {
 func ScanCliSetup(scanInfo *cautils.ScanInfo) error {
 	logger.L().Info("ARMO security scanner starting")
 
-	interfaces := getInterfaces(scanInfo)
+	if scanInfo.ProfileDir != "" {
+		stopProfiling, err := startProfiling(scanInfo.ProfileDir)
+		if err != nil {
+			return err
+		}
+		defer stopProfiling()
+	}
+
+	// the scan is cancelled when interrupted (ctrl+c) or timed out - the in-flight requests are cancelled and the host sensor is removed
+	ctx, stop := interruptContext()
+	defer stop()
+
+	if scanInfo.Preflight {
+		return preflight(ctx, scanInfo)
+	}
+
+	interfaces := getInterfaces(ctx, scanInfo)
 	// setPolicyGetter(scanInfo, interfaces.clusterConfig.GetCustomerGUID())
 
-	processNotification := make(chan *cautils.OPASessionObj)
-	reportResults := make(chan *cautils.OPASessionObj)
+	summaryDetails, err := runScan(ctx, scanInfo, interfaces)
+	if err != nil {
+		return err
+	}
+
+	if summaryDetails.Score > float32(scanInfo.FailThreshold) {
+		return fmt.Errorf("scan risk-score %.2f is above permitted threshold %.2f", summaryDetails.Score, scanInfo.FailThreshold)
+	}
+
+	return scanInfo.FailOptions.Check(summaryDetails)
+}
+
+// interruptContext returns a context done when the process is interrupted (ctrl+c) or terminated
+func interruptContext() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+}
+
+// runScan runs the scanning pipeline (policies -> resources -> opa -> results) and returns the summary of the results.
+// Returns an error when the policies or the resources failed to load, the results are not handled then.
+// When the results failed to print, the summary is returned with the error.
+// The scan is stopped when the context is done or after the timeout of the scan, the host sensor is removed then. Once the results
+// are received the scan is done, the results are printed and reported regardless of the context
+func runScan(ctx context.Context, scanInfo *cautils.ScanInfo, interfaces componentInterfaces) (*reportsummary.SummaryDetails, error) {
+	ctx, cancel := cautils.PhaseContext(ctx, scanInfo.Timeouts.Scan)
+	defer cancel()
+
+	// buffered, a single session is sent on each - the senders never block on the goroutines stopped by a failed scan
+	processNotification := make(chan *cautils.OPASessionObj, 1)
+	reportResults := make(chan *cautils.OPASessionObj, 1)
 
 	cautils.ClusterName = interfaces.tenantConfig.GetClusterName() // TODO - Deprecated
 	cautils.CustomerGUID = interfaces.tenantConfig.GetAccountID()  // TODO - Deprecated
 	interfaces.report.SetClusterName(interfaces.tenantConfig.GetClusterName())
 	interfaces.report.SetCustomerGUID(interfaces.tenantConfig.GetAccountID())
 
-	downloadReleasedPolicy := getter.NewDownloadReleasedPolicy() // download config inputs from github release
-
 	// set policy getter only after setting the customerGUID
-	scanInfo.Getters.PolicyGetter = getPolicyGetter(scanInfo.UseFrom, interfaces.tenantConfig.GetAccountID(), scanInfo.FrameworkScan, downloadReleasedPolicy)
-	scanInfo.Getters.ControlsInputsGetter = getConfigInputsGetter(scanInfo.ControlsInputs, interfaces.tenantConfig.GetAccountID(), downloadReleasedPolicy)
-	scanInfo.Getters.ExceptionsGetter = getExceptionsGetter(scanInfo.UseExceptions)
+	setPolicyGetters(scanInfo, interfaces.tenantConfig.GetAccountID())
 
 	// TODO - list supported frameworks/controls
 	if scanInfo.ScanAll {
@@ -137,36 +222,65 @@ func ScanCliSetup(scanInfo *cautils.ScanInfo) error {
 		}
 	}()
 
-	// cli handler setup
+	// cli handler setup - the errors of the policy handler and of the processor. The goroutines end when the scan is done, the context of
+	// the scan is cancelled when runScan returns
+	scanErr := make(chan error, 2)
 	go func() {
 		// policy handler setup
 		policyHandler := policyhandler.NewPolicyHandler(&processNotification, interfaces.resourceHandler)
 
-		if err := Scan(policyHandler, scanInfo); err != nil {
-			logger.L().Fatal(err.Error())
+		if err := Scan(ctx, policyHandler, scanInfo); err != nil {
+			scanErr <- err
 		}
 	}()
 
 	// processor setup - rego run
 	go func() {
-		opaprocessorObj := opaprocessor.NewOPAProcessorHandler(&processNotification, &reportResults)
-		opaprocessorObj.ProcessRulesListenner()
+		opaprocessorObj := opaprocessor.NewOPAProcessorHandler(ctx, &processNotification, &reportResults, scanInfo.EvalConcurrency, scanInfo.Timeouts.Eval)
+		// printers that stream the results while scanning
+		for _, printerHandler := range interfaces.printerHandlers {
+			if listener, ok := printerHandler.(opaprocessor.IResultsListener); ok {
+				opaprocessorObj.AddResultsListener(listener)
+			}
+		}
+		if err := opaprocessorObj.ProcessRulesListenner(); err != nil {
+			scanErr <- err
+		}
 	}()
 
-	resultsHandling := resultshandling.NewResultsHandler(&reportResults, interfaces.report, interfaces.printerHandler)
-	score := resultsHandling.HandleResults(scanInfo)
+	var opaSessionObj *cautils.OPASessionObj
+	select {
+	case err := <-scanErr:
+		return nil, scanContextError(ctx, err)
+	case <-ctx.Done():
+		return nil, scanContextError(ctx, ctx.Err())
+	case opaSessionObj = <-reportResults:
+	}
+
+	// the scan is done, the printers (e.g. the report served by '--open' until ctrl+c) and the reporter are not stopped by the timeout
+	// nor the interruption of the scan
+	printResults := make(chan *cautils.OPASessionObj, 1)
+	printResults <- opaSessionObj
+	resultsHandling := resultshandling.NewResultsHandler(&printResults, interfaces.report, interfaces.printerHandlers)
+	summaryDetails, err := resultsHandling.HandleResults(context.Background(), scanInfo)
 
 	// print report url
 	interfaces.report.DisplayReportURL()
+	return summaryDetails, err
+}
 
-	if score > float32(scanInfo.FailThreshold) {
-		return fmt.Errorf("scan risk-score %.2f is above permitted threshold %.2f", score, scanInfo.FailThreshold)
+// scanContextError returns the error of a scan stopped by its context, interrupted or timed out. The other errors are returned as is
+func scanContextError(ctx context.Context, err error) error {
+	switch ctx.Err() {
+	case context.DeadlineExceeded:
+		return fmt.Errorf("the scan timed out: %w", err)
+	case context.Canceled:
+		return fmt.Errorf("the scan was cancelled: %w", err)
 	}
-
-	return nil
+	return err
 }
 
-func Scan(policyHandler *policyhandler.PolicyHandler, scanInfo *cautils.ScanInfo) error {
+func Scan(ctx context.Context, policyHandler *policyhandler.PolicyHandler, scanInfo *cautils.ScanInfo) error {
 	policyNotification := &reporthandling.PolicyNotification{
 		Rules: scanInfo.PolicyIdentifier,
 		KubescapeNotification: reporthandling.KubescapeNotification{
@@ -176,7 +290,7 @@ func Scan(policyHandler *policyhandler.PolicyHandler, scanInfo *cautils.ScanInfo
 	}
 	switch policyNotification.KubescapeNotification.NotificationType {
 	case reporthandling.TypeExecPostureScan:
-		if err := policyHandler.HandleNotificationRequest(policyNotification, scanInfo); err != nil {
+		if err := policyHandler.HandleNotificationRequest(ctx, policyNotification, scanInfo); err != nil {
 			return err
 		}
 
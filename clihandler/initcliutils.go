@@ -1,18 +1,31 @@
 package clihandler
 
 import (
+	"context"
 	"fmt"
+	"net/url"
 	"os"
+	"strings"
 
 	"github.com/armosec/k8s-interface/k8sinterface"
 	"github.com/armosec/kubescape/cautils"
 	"github.com/armosec/kubescape/cautils/getter"
 	"github.com/armosec/kubescape/cautils/logger"
 	"github.com/armosec/kubescape/cautils/logger/helpers"
+	"github.com/armosec/kubescape/exceptionshandler"
+	"github.com/armosec/kubescape/fixhandler"
 	"github.com/armosec/kubescape/hostsensorutils"
+	cosignv1 "github.com/armosec/kubescape/registryadaptors/cosign/v1"
+	"github.com/armosec/kubescape/registryadaptors/imageregistry"
+	"github.com/armosec/kubescape/registryadaptors/imagesignatures"
 	"github.com/armosec/kubescape/resourcehandler"
+	"github.com/armosec/kubescape/resultshandling/printer"
+	printerv2 "github.com/armosec/kubescape/resultshandling/printer/v2"
 	"github.com/armosec/kubescape/resultshandling/reporter"
 	reporterv2 "github.com/armosec/kubescape/resultshandling/reporter/v2"
+	"github.com/armosec/kubescape/resultshandling/store"
+	"github.com/armosec/kubescape/resultshandling/uploader"
+	"github.com/armosec/kubescape/tuihandler"
 
 	"github.com/armosec/opa-utils/reporthandling"
 	"github.com/armosec/rbac-utils/rbacscanner"
@@ -25,6 +38,17 @@ func getKubernetesApi() *k8sinterface.KubernetesApi {
 	}
 	return k8sinterface.NewKubernetesApi()
 }
+
+// setKubernetesRateLimits sets the client side rate limit of the requests to the API server, the clients default to 5 requests per second
+func setKubernetesRateLimits(qps float32) {
+	if qps <= 0 || !k8sinterface.IsConnectedToCluster() {
+		return
+	}
+	config := k8sinterface.GetK8sConfig()
+	config.QPS = qps
+	config.Burst = int(2 * qps)
+}
+
 func getTenantConfig(Account, clusterName string, k8s *k8sinterface.KubernetesApi) cautils.ITenantConfig {
 	if !k8sinterface.IsConnectedToCluster() || k8s == nil {
 		return cautils.NewLocalConfig(getter.GetArmoAPIConnector(), Account, clusterName)
@@ -48,9 +72,14 @@ func getRBACHandler(tenantConfig cautils.ITenantConfig, k8s *k8sinterface.Kubern
 	return nil
 }
 
-func getReporter(tenantConfig cautils.ITenantConfig, submit, fwScan, clusterScan bool) reporter.IReport {
+func getReporter(ctx context.Context, tenantConfig cautils.ITenantConfig, scanInfo *cautils.ScanInfo) reporter.IReport {
+	submit, fwScan, clusterScan := scanInfo.Submit, scanInfo.FrameworkScan, scanInfo.GetScanningEnvironment() == cautils.ScanCluster
 	if submit && clusterScan {
-		return reporterv2.NewReportEventReceiver(tenantConfig.GetConfigObj())
+		r := reporterv2.NewReportEventReceiver(tenantConfig.GetConfigObj())
+		r.SetSpoolDir(scanInfo.SpoolDir)
+		r.SetCompression(!scanInfo.SubmitUncompressed)
+		r.SetContext(ctx)
+		return r
 	}
 	if tenantConfig.GetAccountID() == "" && fwScan && clusterScan {
 		// Add link only when scanning a cluster using a framework
@@ -67,13 +96,35 @@ func getReporter(tenantConfig cautils.ITenantConfig, submit, fwScan, clusterScan
 }
 
 func getResourceHandler(scanInfo *cautils.ScanInfo, tenantConfig cautils.ITenantConfig, k8s *k8sinterface.KubernetesApi, hostSensorHandler hostsensorutils.IHostSensor, registryAdaptors *resourcehandler.RegistryAdaptors) resourcehandler.IResourceHandler {
+	if scanInfo.HelmChart != nil {
+		return resourcehandler.NewHelmChartResourceHandler(scanInfo.HelmChart, registryAdaptors)
+	}
+	if scanInfo.KustomizeDirectory != "" {
+		return resourcehandler.NewKustomizeResourceHandler(scanInfo.KustomizeDirectory, registryAdaptors)
+	}
 	if len(scanInfo.InputPatterns) > 0 || k8s == nil {
 		// scanInfo.HostSensor.SetBool(false)
-		return resourcehandler.NewFileResourceHandler(scanInfo.InputPatterns, registryAdaptors)
+		gitOptions := scanInfo.GitOptions
+		if gitOptions.Token == "" {
+			gitOptions.Token = os.Getenv("KS_GIT_TOKEN")
+		}
+		return resourcehandler.NewFileResourceHandler(scanInfo.InputPatterns, &gitOptions, registryAdaptors)
 	}
 	getter.GetArmoAPIConnector()
 	rbacObjects := getRBACHandler(tenantConfig, k8s, scanInfo.Submit)
-	return resourcehandler.NewK8sResourceHandler(k8s, getFieldSelector(scanInfo), hostSensorHandler, rbacObjects, registryAdaptors)
+	k8sResourceHandler := resourcehandler.NewK8sResourceHandler(k8s, getFieldSelector(scanInfo, k8s), &scanInfo.Selectors, hostSensorHandler, rbacObjects, registryAdaptors, scanInfo.FetchConcurrency, scanInfo.FetchPageSize)
+	k8sResourceHandler.SetOffline(scanInfo.Offline)
+	if scanInfo.Cached {
+		cache, err := resourcehandler.NewResourcesCache(resourcehandler.DefaultResourcesCachePath(tenantConfig.GetClusterName()), scanInfo.CacheMaxAge)
+		if err != nil {
+			logger.L().Warning("failed to load the resources cache, listing all of the resources", helpers.Error(err))
+		}
+		k8sResourceHandler.SetResourcesCache(cache)
+	}
+	if scanInfo.Workload != nil {
+		return resourcehandler.NewWorkloadResourceHandler(k8sResourceHandler, scanInfo.Workload)
+	}
+	return k8sResourceHandler
 }
 
 func getHostSensorHandler(scanInfo *cautils.ScanInfo, k8s *k8sinterface.KubernetesApi) hostsensorutils.IHostSensor {
@@ -88,7 +139,7 @@ func getHostSensorHandler(scanInfo *cautils.ScanInfo, k8s *k8sinterface.Kubernet
 		logger.L().Warning("Kubernetes cluster nodes scanning is disabled. This is required to collect valuable data for certain controls. You can enable it using  the --enable-host-scan flag")
 	}
 	if hostSensorVal := scanInfo.HostSensorEnabled.Get(); hostSensorVal != nil && *hostSensorVal {
-		hostSensorHandler, err := hostsensorutils.NewHostSensorHandler(k8s, scanInfo.HostSensorYamlPath)
+		hostSensorHandler, err := hostsensorutils.NewHostSensorHandler(k8s, scanInfo.HostSensorYamlPath, &scanInfo.HostSensorOptions)
 		if err != nil {
 			logger.L().Warning(fmt.Sprintf("failed to create host sensor: %s", err.Error()))
 			return &hostsensorutils.HostSensorHandlerMock{}
@@ -97,7 +148,41 @@ func getHostSensorHandler(scanInfo *cautils.ScanInfo, k8s *k8sinterface.Kubernet
 	}
 	return &hostsensorutils.HostSensorHandlerMock{}
 }
-func getFieldSelector(scanInfo *cautils.ScanInfo) resourcehandler.IFieldSelector {
+
+// getSignatureVerifier returns the verifier of the images signatures, by the trusted keys and identities of the flags
+func getSignatureVerifier(scanInfo *cautils.ScanInfo) imagesignatures.IImageSignatureVerifier {
+	policy := &imagesignatures.TrustPolicy{Keys: scanInfo.SignatureKeys, AttestationTypes: scanInfo.AttestationTypes}
+	for _, s := range scanInfo.TrustedIdentities {
+		identity, err := imagesignatures.ParseTrustedIdentity(s)
+		if err != nil {
+			logger.L().Fatal(err.Error())
+		}
+		policy.Identities = append(policy.Identities, identity)
+	}
+	return cosignv1.NewCosignVerifier(scanInfo.CosignPath, policy, scanInfo.Offline)
+}
+
+// getMetadataClient returns the client looking up the images configurations, authenticated by the credentials of the docker config
+func getMetadataClient() imageregistry.IImageMetadataClient {
+	keychain, err := imageregistry.NewKeychain(imageregistry.DockerConfigDir())
+	if err != nil {
+		logger.L().Warning("failed to load the docker credentials, the registries are accessed anonymously", helpers.Error(err))
+	}
+	return imageregistry.NewClient(keychain, imageregistry.DefaultPlatform)
+}
+
+func getFieldSelector(scanInfo *cautils.ScanInfo, k8s *k8sinterface.KubernetesApi) resourcehandler.IFieldSelector {
+	if resourcehandler.IsNamespacePattern(scanInfo.IncludeNamespaces) || resourcehandler.IsNamespacePattern(scanInfo.ExcludedNamespaces) {
+		namespaces, err := resourcehandler.ListNamespaces(k8s)
+		if err != nil {
+			logger.L().Fatal("failed to resolve the namespace patterns", helpers.Error(err))
+		}
+		fieldSelector, err := resourcehandler.NewNamespacePatternSelector(scanInfo.IncludeNamespaces, scanInfo.ExcludedNamespaces, namespaces)
+		if err != nil {
+			logger.L().Fatal(err.Error())
+		}
+		return fieldSelector
+	}
 	if scanInfo.IncludeNamespaces != "" {
 		return resourcehandler.NewIncludeSelector(scanInfo.IncludeNamespaces)
 	}
@@ -137,8 +222,8 @@ func setSubmitBehavior(scanInfo *cautils.ScanInfo, tenantConfig cautils.ITenantC
 
 	*/
 
-	// do not submit control scanning
-	if !scanInfo.FrameworkScan {
+	// do not submit control scanning, nor offline
+	if !scanInfo.FrameworkScan || scanInfo.Offline {
 		scanInfo.Submit = false
 		return
 	}
@@ -152,10 +237,160 @@ func setSubmitBehavior(scanInfo *cautils.ScanInfo, tenantConfig cautils.ITenantC
 
 }
 
+// getForwarders returns the printers that send the results to external services - syslog server, notification webhooks and email, and to the local results store
+func getForwarders(scanInfo *cautils.ScanInfo, tenantConfig cautils.ITenantConfig) []printer.IPrinter {
+	forwarders := []printer.IPrinter{}
+	if scanInfo.Forward != "" {
+		syslogForwarder, err := printerv2.NewSyslogForwarder(scanInfo.Forward)
+		if err != nil {
+			logger.L().Fatal(err.Error())
+		}
+		forwarders = append(forwarders, syslogForwarder)
+	}
+	for _, webhook := range scanInfo.Notify {
+		webhookNotifier, err := printerv2.NewWebhookNotifier(webhook, getNotifyReportURL(scanInfo, tenantConfig))
+		if err != nil {
+			logger.L().Fatal(err.Error())
+		}
+		forwarders = append(forwarders, webhookNotifier)
+	}
+	if len(scanInfo.EmailOptions.To) > 0 {
+		emailOptions := scanInfo.EmailOptions
+		if emailOptions.Password == "" {
+			emailOptions.Password = os.Getenv("KS_SMTP_PASSWORD")
+		}
+		// the output files of the scan are attached to the email
+		attachments := []string{}
+		for _, format := range scanInfo.GetFormats() {
+			if outputFile := printerv2.GetOutputFile(format, scanInfo.GetOutputFile(format)); outputFile != "" && !uploader.IsRemoteOutput(outputFile) {
+				attachments = append(attachments, outputFile)
+			}
+		}
+		forwarders = append(forwarders, printerv2.NewEmailSender(emailOptions, attachments))
+	}
+	if scanInfo.Store {
+		forwarders = append(forwarders, store.NewStorePrinter(scanInfo.StorePath))
+	}
+	if scanInfo.GenerateExceptions != "" {
+		forwarders = append(forwarders, exceptionshandler.NewBaselinePrinter(scanInfo.GenerateExceptions))
+	}
+	if scanInfo.Fix {
+		forwarders = append(forwarders, fixhandler.NewFixPrinter())
+	}
+	if scanInfo.Interactive {
+		forwarders = append(forwarders, tuihandler.NewInteractivePrinter(tuihandler.DefaultExceptionsFile))
+	}
+	if scanInfo.Open {
+		forwarders = append(forwarders, newReportViewer(DefaultViewAddress))
+	}
+	return forwarders
+}
+
+// getNotifyReportURL returns the link to the full report of the notifications - the user defined URL, or the ARMO portal when submitting the results
+func getNotifyReportURL(scanInfo *cautils.ScanInfo, tenantConfig cautils.ITenantConfig) string {
+	if scanInfo.NotifyReportURL != "" || !scanInfo.Submit {
+		return scanInfo.NotifyReportURL
+	}
+	u := url.URL{
+		Scheme: "https",
+		Host:   getter.GetArmoAPIConnector().GetFrontendURL(),
+		Path:   fmt.Sprintf("configuration-scanning/%s", tenantConfig.GetClusterName()),
+	}
+	return u.String()
+}
+
+// setPolicyGetters sets the getters of the policies, the controls inputs and the exceptions of the account
+func setPolicyGetters(scanInfo *cautils.ScanInfo, accountID string) {
+	if scanInfo.Offline {
+		// the artifacts were validated by ScanInfo.Init, the local getters do not fall back to the released policies
+		scanInfo.Getters.PolicyGetter = getter.NewLoadPolicy(scanInfo.UseFrom)
+		scanInfo.Getters.ControlsInputsGetter = getter.NewLoadPolicy([]string{scanInfo.ControlsInputs})
+		scanInfo.Getters.ExceptionsGetter = getter.NewLoadPolicy([]string{scanInfo.UseExceptions})
+		return
+	}
+	downloadReleasedPolicy := getter.NewDownloadReleasedPolicy() // download config inputs from github release
+	if len(scanInfo.UseFrom) == 0 && !(accountID != "" && scanInfo.FrameworkScan) {
+		setPolicyVersion(scanInfo)
+		downloadReleasedPolicy.SetPolicyVersion(scanInfo.PolicyVersion)
+	} else if scanInfo.PolicyVersion != "" {
+		logger.L().Warning("the policy version applies to the released policies only, ignoring", helpers.String("version", scanInfo.PolicyVersion))
+	}
+
+	scanInfo.Getters.PolicyGetter = getPolicyGetter(scanInfo.UseFrom, accountID, scanInfo.FrameworkScan, downloadReleasedPolicy)
+	if loadPolicy, ok := scanInfo.Getters.PolicyGetter.(*getter.LoadPolicy); ok && scanInfo.CustomControls != "" {
+		loadPolicy.SetCustomControlsDir(scanInfo.CustomControls) // the custom frameworks of the user-authored controls
+	}
+	scanInfo.Getters.ControlsInputsGetter = getConfigInputsGetter(scanInfo.ControlsInputs, accountID, downloadReleasedPolicy)
+	scanInfo.Getters.ExceptionsGetter = getExceptionsGetter(scanInfo.UseExceptions)
+}
+
+// validateOffline returns an error for the options which require network calls, other than to the scanned cluster.
+// The lookups of the cloud provider of the cluster are skipped when offline, see K8sResourceHandler.SetOffline
+func validateOffline(scanInfo *cautils.ScanInfo) error {
+	unsupported := []string{}
+	if scanInfo.Submit {
+		unsupported = append(unsupported, "--submit")
+	}
+	if scanInfo.PolicyVersion != "" {
+		unsupported = append(unsupported, "--policy-version")
+	}
+	for _, pattern := range scanInfo.InputPatterns {
+		if resourcehandler.IsGitRepositoryURL(pattern) {
+			unsupported = append(unsupported, fmt.Sprintf("scanning the git repository '%s'", pattern))
+		}
+	}
+	for _, format := range scanInfo.GetFormats() {
+		if outputFile := scanInfo.GetOutputFile(format); uploader.IsRemoteOutput(outputFile) {
+			unsupported = append(unsupported, fmt.Sprintf("uploading the results to '%s'", outputFile))
+		}
+	}
+	if scanInfo.Forward != "" {
+		unsupported = append(unsupported, "--forward")
+	}
+	if len(scanInfo.Notify) > 0 {
+		unsupported = append(unsupported, "--notify")
+	}
+	if len(scanInfo.EmailOptions.To) > 0 {
+		unsupported = append(unsupported, "--email-to")
+	}
+	if len(unsupported) > 0 {
+		return fmt.Errorf("offline: network calls are required by %s", strings.Join(unsupported, ", "))
+	}
+	return nil
+}
+
+// setPolicyVersion pins the version of the released policies by the lockfile, a new lockfile records the latest release
+func setPolicyVersion(scanInfo *cautils.ScanInfo) {
+	scanInfo.PolicyVersion = getter.NormalizePolicyVersion(scanInfo.PolicyVersion)
+	if scanInfo.LockFile == "" || scanInfo.PolicyVersion != "" {
+		return
+	}
+	if _, err := os.Stat(scanInfo.LockFile); err == nil {
+		if lockFile, err := cautils.LoadLockFile(scanInfo.LockFile); err == nil {
+			scanInfo.PolicyVersion = lockFile.PolicyVersion
+		}
+		return
+	}
+	policyVersion, err := getter.ResolveLatestPolicyVersion()
+	if err != nil {
+		logger.L().Warning("failed to resolve the latest version of the policies, the lockfile does not lock the version", helpers.Error(err))
+		return
+	}
+	scanInfo.PolicyVersion = policyVersion
+}
+
 // setPolicyGetter set the policy getter - local file/github release/ArmoAPI
 func getPolicyGetter(loadPoliciesFromFile []string, accountID string, frameworkScope bool, downloadReleasedPolicy *getter.DownloadReleasedPolicy) getter.IPolicyGetter {
 	if len(loadPoliciesFromFile) > 0 {
-		return getter.NewLoadPolicy(loadPoliciesFromFile)
+		loadPolicy := getter.NewLoadPolicy(loadPoliciesFromFile)
+		// the controls of custom frameworks which are not in the local files are pulled from the released policies
+		loadPolicy.SetControlsGetter(func() getter.IPolicyGetter {
+			if downloadReleasedPolicy == nil {
+				downloadReleasedPolicy = getter.NewDownloadReleasedPolicy()
+			}
+			return getDownloadReleasedPolicy(downloadReleasedPolicy)
+		})
+		return loadPolicy
 	}
 	if accountID != "" && frameworkScope {
 		g := getter.GetArmoAPIConnector() // download policy from ARMO backend
@@ -195,7 +430,7 @@ func getConfigInputsGetter(ControlsInputs string, accountID string, downloadRele
 		downloadReleasedPolicy = getter.NewDownloadReleasedPolicy()
 	}
 	if err := downloadReleasedPolicy.SetRegoObjects(); err != nil { // if failed to pull config inputs, fallback to BE
-		cautils.WarningDisplay(os.Stderr, "Warning: failed to get config inputs from github release, this may affect the scanning results\n")
+		logger.L().Warning("failed to get config inputs from github release, this may affect the scanning results", helpers.Error(err))
 	}
 	return downloadReleasedPolicy
 }
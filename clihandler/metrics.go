@@ -0,0 +1,98 @@
+package clihandler
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/cautils/logger/helpers"
+	"github.com/armosec/kubescape/resultshandling/printer"
+	printerv2 "github.com/armosec/kubescape/resultshandling/printer/v2"
+)
+
+// metricsExporter is a printer that keeps the metrics of the latest scan in memory and serves them over http
+type metricsExporter struct {
+	mutex   sync.RWMutex
+	metrics []byte
+}
+
+func newMetricsExporter() *metricsExporter {
+	return &metricsExporter{}
+}
+
+func (exporter *metricsExporter) ActionPrint(opaSessionObj *cautils.OPASessionObj) error {
+	metrics := printerv2.GenerateMetrics(opaSessionObj)
+
+	exporter.mutex.Lock()
+	defer exporter.mutex.Unlock()
+	exporter.metrics = metrics
+	return nil
+}
+
+func (exporter *metricsExporter) SetWriter(outputFile string) {}
+
+func (exporter *metricsExporter) Score(score float32) {}
+
+func (exporter *metricsExporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	exporter.mutex.RLock()
+	defer exporter.mutex.RUnlock()
+
+	if exporter.metrics == nil {
+		http.Error(w, "first scan did not complete yet", http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Write(exporter.metrics)
+}
+
+// ServeMetrics scans periodically and exposes the results of the latest scan on the /metrics endpoint, until interrupted
+func ServeMetrics(scanInfo *cautils.ScanInfo) error {
+	if scanInfo.MetricsInterval <= 0 {
+		return fmt.Errorf("bad argument: metrics interval must be positive")
+	}
+	logger.L().Info("ARMO security scanner starting in metrics mode", helpers.String("address", scanInfo.ServeMetrics), helpers.String("interval", scanInfo.MetricsInterval.String()))
+
+	exporter := newMetricsExporter()
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", exporter)
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- http.ListenAndServe(scanInfo.ServeMetrics, mux)
+	}()
+
+	ticker := time.NewTicker(scanInfo.MetricsInterval)
+	defer ticker.Stop()
+
+	// an interrupted scan is cancelled, the host sensor is removed before exiting
+	ctx, stop := interruptContext()
+	defer stop()
+
+	for {
+		// each scan works on a copy so values set while scanning (e.g. the host sensor namespace) do not accumulate
+		currentScanInfo := *scanInfo
+		interfaces := getInterfaces(ctx, &currentScanInfo)
+		interfaces.printerHandlers = append([]printer.IPrinter{exporter}, getForwarders(&currentScanInfo, interfaces.tenantConfig)...)
+
+		if summaryDetails, err := runScan(ctx, &currentScanInfo, interfaces); err != nil {
+			logger.L().Error("scan failed", helpers.Error(err))
+		} else {
+			logger.L().Info("scan completed", helpers.String("risk-score", fmt.Sprintf("%.2f", summaryDetails.Score)))
+		}
+
+		select {
+		case err := <-serverErr:
+			return fmt.Errorf("metrics server stopped: %w", err)
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package clihandler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/armosec/kubescape/cautils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsExporter(t *testing.T) {
+	exporter := newMetricsExporter()
+
+	// no scan completed yet
+	w := httptest.NewRecorder()
+	exporter.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	opaSessionObj := cautils.NewOPASessionObjMock()
+	opaSessionObj.Report.SummaryDetails.Score = 42.5
+	assert.NoError(t, exporter.ActionPrint(opaSessionObj))
+
+	w = httptest.NewRecorder()
+	exporter.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.True(t, strings.HasPrefix(w.Body.String(), "# HELP kubescape_score Overall risk-score (0- Excellent, 100- All failed)\n# TYPE kubescape_score gauge\nkubescape_score 42.5\n"), w.Body.String())
+
+	// the metrics of the latest scan are served
+	opaSessionObj.Report.SummaryDetails.Score = 10
+	assert.NoError(t, exporter.ActionPrint(opaSessionObj))
+	w = httptest.NewRecorder()
+	exporter.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Contains(t, w.Body.String(), "\nkubescape_score 10\n")
+}
+
+func TestServeMetricsInterval(t *testing.T) {
+	assert.Error(t, ServeMetrics(&cautils.ScanInfo{ServeMetrics: "localhost:0"}))
+}
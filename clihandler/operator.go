@@ -0,0 +1,155 @@
+package clihandler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/cautils/logger/helpers"
+	"github.com/armosec/kubescape/clihandler/cliobjects"
+	"github.com/armosec/kubescape/operator"
+	"github.com/armosec/kubescape/resultshandling/printer"
+	"github.com/armosec/opa-utils/reporthandling"
+	"github.com/robfig/cron/v3"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+)
+
+// scheduledScan a registered cron entry, re-registered when the generation of the schedule changes
+type scheduledScan struct {
+	generation int64
+	entryID    cron.EntryID
+}
+
+type scanScheduler struct {
+	client    dynamic.Interface
+	scanInfo  *cautils.ScanInfo
+	cron      *cron.Cron
+	scheduled map[types.UID]scheduledScan
+	scanMutex sync.Mutex      // the scans run one at a time, the scan pipeline is not safe for concurrent scans
+	ctx       context.Context // the scans are cancelled when the operator is interrupted
+}
+
+// CliOperator installs the CRDs and runs the scans of the ScanSchedule objects, the results are persisted as ScanReport objects
+func CliOperator(operatorInfo *cliobjects.Operator) error {
+	if operatorInfo.SyncInterval <= 0 {
+		return fmt.Errorf("bad argument: sync interval must be positive")
+	}
+	k8s := getKubernetesApi()
+	if k8s == nil {
+		return fmt.Errorf("failed connecting to Kubernetes cluster")
+	}
+	if operatorInfo.InstallCRDs {
+		if err := operator.InstallCRDs(k8s.DynamicClient); err != nil {
+			return err
+		}
+	}
+	logger.L().Info("ARMO security scanner starting in operator mode", helpers.String("namespace", operatorInfo.Namespace), helpers.String("sync-interval", operatorInfo.SyncInterval.String()))
+
+	ctx, stop := interruptContext()
+	defer stop()
+	scheduler := &scanScheduler{
+		ctx:       ctx,
+		client:    k8s.DynamicClient,
+		scanInfo:  &operatorInfo.ScanInfo,
+		cron:      cron.New(),
+		scheduled: map[types.UID]scheduledScan{},
+	}
+	scheduler.cron.Start()
+	defer scheduler.cron.Stop()
+
+	ticker := time.NewTicker(operatorInfo.SyncInterval)
+	defer ticker.Stop()
+	for {
+		if err := scheduler.sync(operatorInfo.Namespace); err != nil {
+			logger.L().Error("failed to list ScanSchedules", helpers.Error(err))
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			<-scheduler.cron.Stop().Done() // the scan in progress is cancelled
+			return nil
+		}
+	}
+}
+
+// sync registers the new and the changed schedules, and removes the deleted and the suspended schedules
+func (scheduler *scanScheduler) sync(namespace string) error {
+	schedules, errs, err := operator.ListScanSchedules(scheduler.client, namespace)
+	if err != nil {
+		return err
+	}
+	for i := range errs {
+		logger.L().Warning(errs[i].Error())
+	}
+
+	active := map[types.UID]bool{}
+	for i := range schedules {
+		schedule := schedules[i]
+		if schedule.Spec.Suspend {
+			continue
+		}
+		active[schedule.UID] = true
+		if scheduled, ok := scheduler.scheduled[schedule.UID]; ok {
+			if scheduled.generation == schedule.Generation {
+				continue
+			}
+			scheduler.cron.Remove(scheduled.entryID)
+		}
+		entryID, err := scheduler.cron.AddFunc(schedule.Spec.Schedule, func() { scheduler.scan(&schedule) })
+		if err != nil {
+			logger.L().Warning("failed to register ScanSchedule", helpers.String("name", schedule.Namespace+"/"+schedule.Name), helpers.Error(err))
+			delete(scheduler.scheduled, schedule.UID)
+			continue
+		}
+		scheduler.scheduled[schedule.UID] = scheduledScan{generation: schedule.Generation, entryID: entryID}
+		logger.L().Info("ScanSchedule registered", helpers.String("name", schedule.Namespace+"/"+schedule.Name), helpers.String("schedule", schedule.Spec.Schedule))
+	}
+	for uid, scheduled := range scheduler.scheduled {
+		if !active[uid] {
+			scheduler.cron.Remove(scheduled.entryID)
+			delete(scheduler.scheduled, uid)
+		}
+	}
+	return nil
+}
+
+// scan runs the scan of the schedule and updates the status of the schedule
+func (scheduler *scanScheduler) scan(schedule *operator.ScanSchedule) {
+	scheduler.scanMutex.Lock()
+	defer scheduler.scanMutex.Unlock()
+
+	scheduleTime := time.Now()
+	logger.L().Info("scheduled scan starting", helpers.String("name", schedule.Namespace+"/"+schedule.Name))
+
+	// each scan works on a copy so values set while scanning (e.g. the host sensor namespace) do not accumulate
+	currentScanInfo := *scheduler.scanInfo
+	setScheduleScanInfo(&currentScanInfo, schedule)
+	interfaces := getInterfaces(scheduler.ctx, &currentScanInfo)
+	reportPrinter := operator.NewScanReportPrinter(scheduler.client, schedule)
+	interfaces.printerHandlers = append([]printer.IPrinter{reportPrinter}, getForwarders(&currentScanInfo, interfaces.tenantConfig)...)
+
+	_, scanErr := runScan(scheduler.ctx, &currentScanInfo, interfaces)
+	if scanErr != nil {
+		logger.L().Error("scheduled scan failed", helpers.String("name", schedule.Namespace+"/"+schedule.Name), helpers.Error(scanErr))
+	} else if scanErr = reportPrinter.Err(); scanErr != nil {
+		logger.L().Error("failed to persist the scan results", helpers.String("name", schedule.Namespace+"/"+schedule.Name), helpers.Error(scanErr))
+	}
+	if err := operator.UpdateScanScheduleStatus(scheduler.client, schedule, scheduleTime, reportPrinter.Report(), scanErr); err != nil {
+		logger.L().Warning("failed to update ScanSchedule status", helpers.String("name", schedule.Namespace+"/"+schedule.Name), helpers.Error(err))
+	}
+}
+
+// setScheduleScanInfo sets the frameworks and the namespaces of the schedule
+func setScheduleScanInfo(scanInfo *cautils.ScanInfo, schedule *operator.ScanSchedule) {
+	scanInfo.FrameworkScan = true
+	scanInfo.PolicyIdentifier = nil
+	scanInfo.ScanAll = len(schedule.Spec.Frameworks) == 0
+	scanInfo.SetPolicyIdentifiers(schedule.Spec.Frameworks, reporthandling.KindFramework)
+	scanInfo.IncludeNamespaces = strings.Join(schedule.Spec.Namespaces, ",")
+	scanInfo.ExcludedNamespaces = strings.Join(schedule.Spec.ExcludeNamespaces, ",")
+}
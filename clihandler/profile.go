@@ -0,0 +1,51 @@
+package clihandler
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/cautils/logger/helpers"
+)
+
+const (
+	cpuProfileFile  = "cpu.pprof"
+	heapProfileFile = "heap.pprof"
+)
+
+// startProfiling starts the cpu profile of the scan, the returned function stops it and writes the heap profile
+func startProfiling(dir string) (func(), error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create the profiles directory '%s': %w", dir, err)
+	}
+	cpuFile, err := os.Create(filepath.Join(dir, cpuProfileFile))
+	if err != nil {
+		return nil, err
+	}
+	if err := pprof.StartCPUProfile(cpuFile); err != nil {
+		cpuFile.Close()
+		return nil, err
+	}
+
+	return func() {
+		pprof.StopCPUProfile()
+		cpuFile.Close()
+
+		heapPath := filepath.Join(dir, heapProfileFile)
+		heapFile, err := os.Create(heapPath)
+		if err != nil {
+			logger.L().Error("failed to write the heap profile", helpers.Error(err))
+			return
+		}
+		defer heapFile.Close()
+		runtime.GC() // the up-to-date statistics of the allocations
+		if err := pprof.WriteHeapProfile(heapFile); err != nil {
+			logger.L().Error("failed to write the heap profile", helpers.Error(err))
+			return
+		}
+		logger.L().Success("Profiles of the scan written", helpers.String("cpu", cpuFile.Name()), helpers.String("heap", heapPath))
+	}, nil
+}
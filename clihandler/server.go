@@ -0,0 +1,221 @@
+package clihandler
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/cautils/getter"
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/cautils/logger/helpers"
+	"github.com/armosec/kubescape/clihandler/cliobjects"
+	"github.com/armosec/kubescape/resultshandling/printer"
+	printerv2 "github.com/armosec/kubescape/resultshandling/printer/v2"
+	"github.com/armosec/kubescape/server"
+	"github.com/armosec/opa-utils/reporthandling"
+	"github.com/armosec/opa-utils/reporthandling/results/v1/resourcesresults"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// serverResultsPrinter is a printer that keeps the json report of the scan and sends the results of the controls as progress events
+type serverResultsPrinter struct {
+	progress func(event server.ProgressEvent)
+	report   []byte
+}
+
+func (resultsPrinter *serverResultsPrinter) ActionPrint(opaSessionObj *cautils.OPASessionObj) error {
+	report, err := printerv2.GenerateJson(opaSessionObj)
+	if err != nil {
+		return fmt.Errorf("failed to generate the report: %w", err)
+	}
+	resultsPrinter.report = report
+	return nil
+}
+
+func (resultsPrinter *serverResultsPrinter) SetWriter(outputFile string) {}
+
+func (resultsPrinter *serverResultsPrinter) Score(score float32) {}
+
+func (resultsPrinter *serverResultsPrinter) StreamResult(opaSessionObj *cautils.OPASessionObj, control *reporthandling.Control, resourceID string, result *resourcesresults.ResourceAssociatedControl) {
+	resultsPrinter.progress(server.ProgressEvent{ControlID: control.ControlID, ResourceID: resourceID, Result: string(result.GetStatus(nil).Status())})
+}
+
+// apiScanner runs the scans requested over the API, on copies of the base configuration of the server
+type apiScanner struct {
+	ctx       context.Context // the scans are cancelled when the server is interrupted
+	scanInfo  *cautils.ScanInfo
+	scanMutex sync.Mutex // held while scanning, the interrupted server waits for the cancelled scan to remove the host sensor
+}
+
+func (scanner *apiScanner) Scan(request *server.ScanRequest, progress func(event server.ProgressEvent)) (*server.ScanResults, error) {
+	if request.Submit && scanner.scanInfo.Local {
+		return nil, fmt.Errorf("the server keeps the results local, the results can not be submitted")
+	}
+	scanner.scanMutex.Lock()
+	defer scanner.scanMutex.Unlock()
+	scanInfo := *scanner.scanInfo
+	setRequestScanInfo(&scanInfo, request)
+
+	resultsPrinter := &serverResultsPrinter{progress: progress}
+	interfaces := getInterfaces(scanner.ctx, &scanInfo)
+	interfaces.printerHandlers = append([]printer.IPrinter{resultsPrinter}, getForwarders(&scanInfo, interfaces.tenantConfig)...)
+
+	summaryDetails, err := runScan(scanner.ctx, &scanInfo, interfaces)
+	if err != nil {
+		return nil, err
+	}
+	return &server.ScanResults{RiskScore: summaryDetails.Score, Report: resultsPrinter.report}, nil
+}
+
+func (scanner *apiScanner) ListFrameworks() ([]string, error) {
+	tenant := getTenantConfig(scanner.scanInfo.Account, scanner.scanInfo.KubeContext, getKubernetesApi())
+	return listFrameworksNames(getPolicyGetter(scanner.scanInfo.UseFrom, tenant.GetAccountID(), true, nil)), nil
+}
+
+func (scanner *apiScanner) ListControls() ([]string, error) {
+	tenant := getTenantConfig(scanner.scanInfo.Account, scanner.scanInfo.KubeContext, getKubernetesApi())
+	return getPolicyGetter(scanner.scanInfo.UseFrom, tenant.GetAccountID(), false, nil).ListControls(getter.ListID)
+}
+
+// setRequestScanInfo sets the policies and the namespaces of the request, the same way the scan commands set them
+func setRequestScanInfo(scanInfo *cautils.ScanInfo, request *server.ScanRequest) {
+	scanInfo.PolicyIdentifier = nil
+	scanInfo.ScanAll = len(request.Frameworks) == 0 && len(request.Controls) == 0
+	if len(request.Controls) > 0 {
+		scanInfo.FrameworkScan = false
+		scanInfo.SetPolicyIdentifiers(request.Controls, reporthandling.KindControl)
+	} else {
+		scanInfo.FrameworkScan = true
+		scanInfo.SetPolicyIdentifiers(request.Frameworks, reporthandling.KindFramework)
+	}
+	scanInfo.IncludeNamespaces = strings.Join(request.IncludeNamespaces, ",")
+	scanInfo.ExcludedNamespaces = strings.Join(request.ExcludeNamespaces, ",")
+	scanInfo.Submit = scanInfo.Submit || request.Submit
+
+	// the requests redact in addition to the server, never less
+	redact := append([]string{}, scanInfo.RedactOptions.Redact...)
+	for _, r := range request.Redact {
+		if cautils.StringInSliceCaseInsensitive(redact, r) == cautils.ValueNotFound {
+			redact = append(redact, r)
+		}
+	}
+	scanInfo.RedactOptions.Redact = redact
+	scanInfo.RedactOptions.OmitRawResources = scanInfo.RedactOptions.OmitRawResources || request.OmitRawResources
+}
+
+// validateServerAuth rejects serving the APIs on a non-loopback address without authentication - the scans run with the credentials of the cluster
+func validateServerAuth(serverInfo *cliobjects.Server) error {
+	if (serverInfo.TLSCertFile == "") != (serverInfo.TLSKeyFile == "") {
+		return fmt.Errorf("bad argument: '--tls-cert-file' and '--tls-key-file' are set together")
+	}
+	if serverInfo.TLSClientCAFile != "" && serverInfo.TLSCertFile == "" {
+		return fmt.Errorf("bad argument: '--tls-client-ca-file' requires '--tls-cert-file' and '--tls-key-file'")
+	}
+	if serverInfo.Token != "" || serverInfo.TLSClientCAFile != "" {
+		return nil
+	}
+	for _, address := range []string{serverInfo.Address, serverInfo.GRPCAddress} {
+		if address != "" && !isLoopbackAddress(address) {
+			return fmt.Errorf("bad argument: serving on '%s' requires authentication, set '--token' (or $KUBESCAPE_TOKEN) or '--tls-client-ca-file'", address)
+		}
+	}
+	return nil
+}
+
+func isLoopbackAddress(address string) bool {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// serverTLSConfig returns the TLS configuration of the APIs, nil when served without TLS
+func serverTLSConfig(serverInfo *cliobjects.Server) (*tls.Config, error) {
+	if serverInfo.TLSCertFile == "" {
+		return nil, nil
+	}
+	certificate, err := tls.LoadX509KeyPair(serverInfo.TLSCertFile, serverInfo.TLSKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the TLS certificate: %w", err)
+	}
+	tlsConfig := &tls.Config{Certificates: []tls.Certificate{certificate}, MinVersion: tls.VersionTLS12}
+	if serverInfo.TLSClientCAFile != "" {
+		data, err := os.ReadFile(serverInfo.TLSClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the client certificate authorities: %w", err)
+		}
+		clientCAs := x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("no certificates in '%s'", serverInfo.TLSClientCAFile)
+		}
+		tlsConfig.ClientCAs = clientCAs
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return tlsConfig, nil
+}
+
+// CliServer serves the REST API for triggering scans and fetching their results, and the gRPC API when its address is set
+func CliServer(serverInfo *cliobjects.Server) error {
+	if serverInfo.HistoryLimit <= 0 {
+		return fmt.Errorf("bad argument: history limit must be positive")
+	}
+	if err := validateServerAuth(serverInfo); err != nil {
+		return err
+	}
+	tlsConfig, err := serverTLSConfig(serverInfo)
+	if err != nil {
+		return err
+	}
+	ctx, stop := interruptContext()
+	defer stop()
+	scanner := &apiScanner{ctx: ctx, scanInfo: &serverInfo.ScanInfo}
+	handler := server.NewServer(scanner, serverInfo.HistoryLimit)
+	handler.SetToken(serverInfo.Token)
+
+	serverErr := make(chan error, 2)
+	if serverInfo.GRPCAddress != "" {
+		listener, err := net.Listen("tcp", serverInfo.GRPCAddress)
+		if err != nil {
+			return err
+		}
+		grpcOptions := handler.GRPCServerOptions()
+		if tlsConfig != nil {
+			grpcOptions = append(grpcOptions, grpc.Creds(credentials.NewTLS(tlsConfig)))
+		}
+		grpcServer := grpc.NewServer(grpcOptions...)
+		handler.RegisterGRPC(grpcServer)
+		go func() {
+			serverErr <- fmt.Errorf("gRPC server stopped: %w", grpcServer.Serve(listener))
+		}()
+	}
+	go func() {
+		httpServer := &http.Server{Addr: serverInfo.Address, Handler: handler, TLSConfig: tlsConfig}
+		if tlsConfig != nil {
+			serverErr <- fmt.Errorf("server stopped: %w", httpServer.ListenAndServeTLS("", ""))
+			return
+		}
+		serverErr <- fmt.Errorf("server stopped: %w", httpServer.ListenAndServe())
+	}()
+
+	logger.L().Info("ARMO security scanner starting in server mode", helpers.String("address", serverInfo.Address), helpers.String("grpc-address", serverInfo.GRPCAddress), helpers.Int("history-limit", serverInfo.HistoryLimit), helpers.String("tls", fmt.Sprintf("%v", tlsConfig != nil)), helpers.String("token", fmt.Sprintf("%v", serverInfo.Token != "")))
+	select {
+	case err := <-serverErr:
+		return err
+	case <-ctx.Done():
+		scanner.scanMutex.Lock() // the scan in progress is cancelled
+		return nil
+	}
+}
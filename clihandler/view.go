@@ -0,0 +1,99 @@
+package clihandler
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/signal"
+	"runtime"
+
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/cautils/logger/helpers"
+	printerv2 "github.com/armosec/kubescape/resultshandling/printer/v2"
+)
+
+// DefaultViewAddress the viewer listens on a random port of the local host
+const DefaultViewAddress = "localhost:0"
+
+// reportViewer is a printer that serves the html report of the scan in a local web server, after the other printers
+type reportViewer struct {
+	address string
+}
+
+func newReportViewer(address string) *reportViewer {
+	return &reportViewer{address: address}
+}
+
+func (viewer *reportViewer) ActionPrint(opaSessionObj *cautils.OPASessionObj) error {
+	if err := serveReport(opaSessionObj, viewer.address); err != nil {
+		return fmt.Errorf("failed to serve the results: %w", err)
+	}
+	return nil
+}
+
+func (viewer *reportViewer) SetWriter(outputFile string) {}
+
+func (viewer *reportViewer) Score(score float32) {}
+
+// serveReport serves the html report until interrupted
+func serveReport(opaSessionObj *cautils.OPASessionObj, address string) error {
+	report := &bytes.Buffer{}
+	if err := printerv2.GenerateHtml(report, opaSessionObj); err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(report.Bytes())
+	})
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+	server := &http.Server{Handler: mux}
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- server.Serve(listener)
+	}()
+
+	url := fmt.Sprintf("http://%s/", listener.Addr().String())
+	logger.L().Success("Serving the results, press ctrl+c to stop", helpers.String("url", url))
+	if err := openBrowser(url); err != nil {
+		logger.L().Warning("failed to open the browser, open the url manually", helpers.String("url", url), helpers.Error(err))
+	}
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+
+	select {
+	case err := <-serverErr:
+		return fmt.Errorf("results server stopped: %w", err)
+	case <-interrupt:
+	}
+	return server.Shutdown(context.Background())
+}
+
+func openBrowser(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	return cmd.Start()
+}
@@ -0,0 +1,111 @@
+package clihandler
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/armosec/k8s-interface/k8sinterface"
+	"github.com/armosec/k8s-interface/workloadinterface"
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/opaprocessor"
+	"github.com/armosec/kubescape/resourcehandler"
+	"github.com/armosec/opa-utils/reporthandling/apis"
+	"github.com/armosec/opa-utils/resources"
+)
+
+// watchBatchPeriod the changes received during the period are evaluated together, a rollout changes several resources at once
+const watchBatchPeriod = 2 * time.Second
+
+// sessionRecorder is a printer that keeps the session of the scan, the baseline of the watch
+type sessionRecorder struct {
+	sessionObj *cautils.OPASessionObj
+}
+
+func (recorder *sessionRecorder) ActionPrint(opaSessionObj *cautils.OPASessionObj) error {
+	recorder.sessionObj = opaSessionObj
+	return nil
+}
+
+func (recorder *sessionRecorder) SetWriter(outputFile string) {}
+
+func (recorder *sessionRecorder) Score(score float32) {}
+
+// Watch scans the cluster, then watches the scanned resources and re-evaluates only the controls affected by the changed resources.
+// The changes of the results are printed as the resources are created/updated/deleted, until interrupted
+func Watch(scanInfo *cautils.ScanInfo) error {
+	if scanInfo.GetScanningEnvironment() != cautils.ScanCluster {
+		return fmt.Errorf("bad argument: '--watch' is supported only when scanning a cluster")
+	}
+	ctx, cancel := interruptContext()
+	defer cancel()
+
+	recorder := &sessionRecorder{}
+	interfaces := getInterfaces(ctx, scanInfo)
+	interfaces.printerHandlers = append(interfaces.printerHandlers, recorder)
+	if _, err := runScan(ctx, scanInfo, interfaces); err != nil {
+		return err
+	}
+	if recorder.sessionObj == nil || recorder.sessionObj.K8SResources == nil {
+		return fmt.Errorf("failed to scan the cluster")
+	}
+
+	k8s := getKubernetesApi()
+	watcher := resourcehandler.NewK8sResourceWatcher(k8s, getFieldSelector(scanInfo, k8s), &scanInfo.Selectors)
+	processor := opaprocessor.NewIncrementalProcessor(recorder.sessionObj, resources.NewRegoDependenciesData(k8sinterface.GetK8sConfig(), cautils.ClusterName))
+
+	changes := make(chan cautils.ResourceChange, 100)
+	stop := make(chan struct{})
+	defer close(stop)
+	if err := watcher.Watch(recorder.sessionObj.K8SResources, changes, stop); err != nil {
+		return err
+	}
+	logger.L().Info("Watching the cluster resources, press Ctrl+C to stop")
+
+	for {
+		var batch []cautils.ResourceChange
+		select {
+		case change := <-changes:
+			batch = []cautils.ResourceChange{change}
+		case <-ctx.Done():
+			return nil
+		}
+		timeout := time.After(watchBatchPeriod)
+	collect:
+		for {
+			select {
+			case change := <-changes:
+				batch = append(batch, change)
+			case <-timeout:
+				break collect
+			}
+		}
+		printStatusChanges(os.Stdout, processor.Process(batch))
+	}
+}
+
+// printStatusChanges prints the new failures and the fixed failures, the other changes (e.g. a created resource which passed) are not findings
+func printStatusChanges(writer io.Writer, statusChanges []opaprocessor.ControlStatusChange) {
+	timestamp := time.Now().Format(time.RFC3339)
+	for i := range statusChanges {
+		change := &statusChanges[i]
+		control := fmt.Sprintf("%s %s", change.Control.ControlID, change.Control.Name)
+		switch {
+		case change.Status == apis.StatusFailed:
+			cautils.FailureDisplay(writer, "%s failed: %s - %s\n", timestamp, control, watchedResourceName(change.Resource))
+		case change.PreviousStatus == apis.StatusFailed && change.Status == "":
+			cautils.SuccessDisplay(writer, "%s resolved (resource removed): %s - %s\n", timestamp, control, watchedResourceName(change.Resource))
+		case change.PreviousStatus == apis.StatusFailed:
+			cautils.SuccessDisplay(writer, "%s %s: %s - %s\n", timestamp, change.Status, control, watchedResourceName(change.Resource))
+		}
+	}
+}
+
+func watchedResourceName(resource workloadinterface.IMetadata) string {
+	if ns := resource.GetNamespace(); ns != "" {
+		return fmt.Sprintf("%s/%s/%s", ns, resource.GetKind(), resource.GetName())
+	}
+	return fmt.Sprintf("%s/%s", resource.GetKind(), resource.GetName())
+}
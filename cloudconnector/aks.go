@@ -0,0 +1,166 @@
+package cloudconnector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+const (
+	// AzureManagementURL the endpoint of the Azure Resource Manager API
+	AzureManagementURL = "https://management.azure.com"
+
+	managedClustersAPIVersion    = "2022-09-01"
+	diagnosticSettingsAPIVersion = "2021-05-01-preview"
+
+	maxResponseSize = 10 << 20
+	requestTimeout  = 30 * time.Second
+)
+
+// aksAuditLogCategories the categories of the diagnostic settings of AKS writing the audit logs of the API server
+var aksAuditLogCategories = map[string]bool{"kube-audit": true, "kube-audit-admin": true}
+
+// aksAuditLogCategoryGroups the category groups of the diagnostic settings including the audit logs
+var aksAuditLogCategoryGroups = map[string]bool{"audit": true, "allLogs": true}
+
+// AKSClient reads the managed clusters of AKS and their diagnostic settings from the Azure Resource Manager API
+type AKSClient struct {
+	baseURL     string
+	token       func() (string, error)
+	accessToken string // the token of the requests, got once
+	http        *http.Client
+}
+
+// NewAKSClient returns a client authenticated by the access token of the Azure CLI (az login)
+func NewAKSClient() *AKSClient {
+	return &AKSClient{baseURL: AzureManagementURL, token: azureCLIToken, http: &http.Client{Timeout: requestTimeout}}
+}
+
+type aksManagedCluster struct {
+	ID         string `json:"id"`
+	Properties struct {
+		APIServerAccessProfile *struct {
+			EnablePrivateCluster bool     `json:"enablePrivateCluster"`
+			AuthorizedIPRanges   []string `json:"authorizedIPRanges"`
+		} `json:"apiServerAccessProfile"`
+		AutoUpgradeProfile *struct {
+			UpgradeChannel string `json:"upgradeChannel"`
+		} `json:"autoUpgradeProfile"`
+		SecurityProfile *struct {
+			AzureKeyVaultKms *struct {
+				Enabled bool `json:"enabled"`
+			} `json:"azureKeyVaultKms"`
+		} `json:"securityProfile"`
+		AgentPoolProfiles []struct {
+			Name string `json:"name"`
+		} `json:"agentPoolProfiles"`
+	} `json:"properties"`
+}
+
+type aksDiagnosticSettings struct {
+	Value []struct {
+		Properties struct {
+			Logs []struct {
+				Category      string `json:"category"`
+				CategoryGroup string `json:"categoryGroup"`
+				Enabled       bool   `json:"enabled"`
+			} `json:"logs"`
+		} `json:"properties"`
+	} `json:"value"`
+}
+
+// GetClusterPosture reads the posture of the managed cluster of the subscription and the resource group
+func (client *AKSClient) GetClusterPosture(subscription, resourceGroup, cluster string) (*ClusterPosture, error) {
+	if subscription == "" || resourceGroup == "" {
+		return nil, fmt.Errorf("the subscription and the resource group of the aks cluster '%s' are required", cluster)
+	}
+	managedCluster := aksManagedCluster{}
+	clusterPath := fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.ContainerService/managedClusters/%s", subscription, resourceGroup, cluster)
+	if err := client.get(clusterPath, managedClustersAPIVersion, &managedCluster); err != nil {
+		return nil, err
+	}
+	settings := aksDiagnosticSettings{}
+	if err := client.get(clusterPath+"/providers/Microsoft.Insights/diagnosticSettings", diagnosticSettingsAPIVersion, &settings); err != nil {
+		return nil, err
+	}
+	return aksPosture(&managedCluster, &settings), nil
+}
+
+// aksPosture the secrets of AKS are encrypted by the KMS plugin of Azure Key Vault, the audit logs are written by the diagnostic settings
+func aksPosture(managedCluster *aksManagedCluster, settings *aksDiagnosticSettings) *ClusterPosture {
+	properties := &managedCluster.Properties
+	posture := &ClusterPosture{Provider: "aks", PublicEndpoint: boolPtr(true), AuditLogging: boolPtr(false)}
+	if profile := properties.APIServerAccessProfile; profile != nil {
+		posture.PublicEndpoint = boolPtr(!profile.EnablePrivateCluster)
+		if !profile.EnablePrivateCluster {
+			posture.PublicAccessCIDRs = profile.AuthorizedIPRanges
+		}
+	}
+	posture.NodeAutoUpgrade = boolPtr(properties.AutoUpgradeProfile != nil && properties.AutoUpgradeProfile.UpgradeChannel != "" &&
+		properties.AutoUpgradeProfile.UpgradeChannel != "none")
+	if !*posture.NodeAutoUpgrade {
+		for _, pool := range properties.AgentPoolProfiles {
+			posture.NodePoolsWithoutAutoUpgrade = append(posture.NodePoolsWithoutAutoUpgrade, pool.Name)
+		}
+	}
+	posture.SecretsEncryption = boolPtr(properties.SecurityProfile != nil && properties.SecurityProfile.AzureKeyVaultKms != nil &&
+		properties.SecurityProfile.AzureKeyVaultKms.Enabled)
+	for _, setting := range settings.Value {
+		for _, log := range setting.Properties.Logs {
+			if log.Enabled && (aksAuditLogCategories[log.Category] || aksAuditLogCategoryGroups[log.CategoryGroup]) {
+				posture.AuditLogging = boolPtr(true)
+			}
+		}
+	}
+	return posture
+}
+
+func (client *AKSClient) get(path, apiVersion string, v interface{}) error {
+	if client.accessToken == "" {
+		token, err := client.token()
+		if err != nil {
+			return err
+		}
+		client.accessToken = token
+	}
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s%s?api-version=%s", client.baseURL, path, apiVersion), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+client.accessToken)
+	resp, err := client.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to get '%s', status code: %d, %s", path, resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	return json.Unmarshal(data, v)
+}
+
+// azureCLIToken returns the access token of the Azure Resource Manager of the account logged in by the Azure CLI
+func azureCLIToken() (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, "az", "account", "get-access-token", "--resource", AzureManagementURL+"/", "--output", "json").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get an azure access token by the azure cli (az login): %w", err)
+	}
+	token := struct {
+		AccessToken string `json:"accessToken"`
+	}{}
+	if err := json.Unmarshal(out, &token); err != nil {
+		return "", fmt.Errorf("failed to read the azure access token: %w", err)
+	}
+	return token.AccessToken, nil
+}
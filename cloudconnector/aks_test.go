@@ -0,0 +1,70 @@
+package cloudconnector
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const clusterPath = "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.ContainerService/managedClusters/demo"
+
+func TestAKSClusterPosture(t *testing.T) {
+	responses := map[string]string{
+		clusterPath: `{"id": "` + clusterPath + `", "properties": {
+			"apiServerAccessProfile": {"enablePrivateCluster": false, "authorizedIPRanges": ["192.168.0.0/16"]},
+			"autoUpgradeProfile": {"upgradeChannel": "none"},
+			"agentPoolProfiles": [{"name": "system"}, {"name": "user"}]}}`,
+		clusterPath + "/providers/Microsoft.Insights/diagnosticSettings": `{"value": [{"properties": {"logs": [
+			{"category": "kube-apiserver", "enabled": true}, {"category": "kube-audit-admin", "enabled": true}]}}]}`,
+	}
+	tokens := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" || r.URL.Query().Get("api-version") == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		response, ok := responses[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error": {"code": "ResourceNotFound"}}`)
+			return
+		}
+		fmt.Fprint(w, response)
+	}))
+	defer server.Close()
+
+	client := NewAKSClient()
+	client.baseURL = server.URL
+	client.token = func() (string, error) { tokens++; return "token", nil }
+
+	posture, err := client.GetClusterPosture("sub", "rg", "demo")
+	assert.NoError(t, err)
+	assert.Equal(t, &ClusterPosture{
+		Provider:                    "aks",
+		PublicEndpoint:              boolPtr(true),
+		PublicAccessCIDRs:           []string{"192.168.0.0/16"},
+		AuditLogging:                boolPtr(true),
+		NodeAutoUpgrade:             boolPtr(false),
+		NodePoolsWithoutAutoUpgrade: []string{"system", "user"},
+		SecretsEncryption:           boolPtr(false),
+	}, posture)
+	assert.Equal(t, 1, tokens, "the token is got once")
+
+	_, err = client.GetClusterPosture("sub", "rg", "missing")
+	assert.Error(t, err)
+	_, err = client.GetClusterPosture("", "rg", "demo")
+	assert.Error(t, err)
+}
+
+func TestAKSPosture(t *testing.T) {
+	managedCluster := &aksManagedCluster{}
+	managedCluster.Properties.AutoUpgradeProfile = &struct {
+		UpgradeChannel string `json:"upgradeChannel"`
+	}{UpgradeChannel: "stable"}
+	posture := aksPosture(managedCluster, &aksDiagnosticSettings{})
+	assert.Equal(t, &ClusterPosture{Provider: "aks", PublicEndpoint: boolPtr(true), AuditLogging: boolPtr(false), NodeAutoUpgrade: boolPtr(true),
+		SecretsEncryption: boolPtr(false)}, posture, "the clusters are public by default")
+}
@@ -0,0 +1,25 @@
+package cloudconnector
+
+// ClusterPosture the security settings of a managed cluster, read from the control plane of its cloud provider. The settings the cloud
+// provider has no equivalent of are nil, the controls skip them
+type ClusterPosture struct {
+	Provider string `json:"provider"`
+
+	// PublicEndpoint the API server is reachable from the internet, PublicAccessCIDRs the networks allowed to reach it (empty when any network is)
+	PublicEndpoint    *bool    `json:"publicEndpoint,omitempty"`
+	PublicAccessCIDRs []string `json:"publicAccessCIDRs,omitempty"`
+
+	// AuditLogging the audit logs of the API server are written to the logging service of the cloud provider
+	AuditLogging *bool `json:"auditLogging,omitempty"`
+
+	// NodeAutoUpgrade the nodes are upgraded by the cloud provider, NodePoolsWithoutAutoUpgrade the node pools which are not
+	NodeAutoUpgrade             *bool    `json:"nodeAutoUpgrade,omitempty"`
+	NodePoolsWithoutAutoUpgrade []string `json:"nodePoolsWithoutAutoUpgrade,omitempty"`
+
+	// SecretsEncryption the secrets are encrypted in etcd by a key of the key management service of the cloud provider
+	SecretsEncryption *bool `json:"secretsEncryption,omitempty"`
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
@@ -0,0 +1,131 @@
+package cloudconnector
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// gkeDatabaseEncrypted the ENCRYPTED state of the database encryption of a GKE cluster
+const gkeDatabaseEncrypted = 1
+
+// eksDescribe the settings of the describe-cluster output of EKS, as serialized by the ClusterDescribe objects
+type eksDescribe struct {
+	Cluster struct {
+		ResourcesVpcConfig *struct {
+			EndpointPublicAccess bool     `json:"EndpointPublicAccess"`
+			PublicAccessCidrs    []string `json:"PublicAccessCidrs"`
+		} `json:"ResourcesVpcConfig"`
+		Logging *struct {
+			ClusterLogging []struct {
+				Enabled *bool    `json:"Enabled"`
+				Types   []string `json:"Types"`
+			} `json:"ClusterLogging"`
+		} `json:"Logging"`
+		EncryptionConfig []struct {
+			Resources []string `json:"Resources"`
+		} `json:"EncryptionConfig"`
+	} `json:"Cluster"`
+}
+
+// gkeDescribe the settings of the cluster of GKE, as serialized by the ClusterDescribe objects
+type gkeDescribe struct {
+	LoggingService                 string `json:"logging_service"`
+	MasterAuthorizedNetworksConfig *struct {
+		Enabled    bool `json:"enabled"`
+		CidrBlocks []struct {
+			CidrBlock string `json:"cidr_block"`
+		} `json:"cidr_blocks"`
+	} `json:"master_authorized_networks_config"`
+	PrivateClusterConfig *struct {
+		EnablePrivateEndpoint bool `json:"enable_private_endpoint"`
+	} `json:"private_cluster_config"`
+	DatabaseEncryption *struct {
+		State int `json:"state"`
+	} `json:"database_encryption"`
+	NodePools []struct {
+		Name       string `json:"name"`
+		Management *struct {
+			AutoUpgrade bool `json:"auto_upgrade"`
+		} `json:"management"`
+	} `json:"node_pools"`
+}
+
+// PostureFromDescribe reads the posture of the cluster out of the data of the ClusterDescribe object of the cloud provider (eks, gke)
+func PostureFromDescribe(provider string, data map[string]interface{}) (*ClusterPosture, error) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	switch provider {
+	case "eks":
+		describe := eksDescribe{}
+		if err := json.Unmarshal(b, &describe); err != nil {
+			return nil, fmt.Errorf("failed to read the eks cluster description: %w", err)
+		}
+		return eksPosture(&describe), nil
+	case "gke":
+		describe := gkeDescribe{}
+		if err := json.Unmarshal(b, &describe); err != nil {
+			return nil, fmt.Errorf("failed to read the gke cluster description: %w", err)
+		}
+		return gkePosture(&describe), nil
+	}
+	return nil, fmt.Errorf("cloud provider '%s' has no cluster description", provider)
+}
+
+// eksPosture the node groups of EKS are upgraded by the node groups API, the node auto-upgrade is not a setting of the cluster
+func eksPosture(describe *eksDescribe) *ClusterPosture {
+	posture := &ClusterPosture{Provider: "eks", AuditLogging: boolPtr(false), SecretsEncryption: boolPtr(false)}
+	if vpc := describe.Cluster.ResourcesVpcConfig; vpc != nil {
+		posture.PublicEndpoint = boolPtr(vpc.EndpointPublicAccess)
+		if vpc.EndpointPublicAccess {
+			posture.PublicAccessCIDRs = vpc.PublicAccessCidrs
+		}
+	}
+	if logging := describe.Cluster.Logging; logging != nil {
+		for _, setup := range logging.ClusterLogging {
+			if setup.Enabled != nil && *setup.Enabled && contains(setup.Types, "audit") {
+				posture.AuditLogging = boolPtr(true)
+			}
+		}
+	}
+	for _, encryption := range describe.Cluster.EncryptionConfig {
+		if contains(encryption.Resources, "secrets") {
+			posture.SecretsEncryption = boolPtr(true)
+		}
+	}
+	return posture
+}
+
+// gkePosture the admin activity audit logs of GKE are always written to the cloud audit logs, unless the logging of the cluster is disabled
+func gkePosture(describe *gkeDescribe) *ClusterPosture {
+	posture := &ClusterPosture{
+		Provider:          "gke",
+		PublicEndpoint:    boolPtr(describe.PrivateClusterConfig == nil || !describe.PrivateClusterConfig.EnablePrivateEndpoint),
+		AuditLogging:      boolPtr(describe.LoggingService != "none"),
+		SecretsEncryption: boolPtr(describe.DatabaseEncryption != nil && describe.DatabaseEncryption.State == gkeDatabaseEncrypted),
+	}
+	if networks := describe.MasterAuthorizedNetworksConfig; networks != nil && networks.Enabled && *posture.PublicEndpoint {
+		for _, block := range networks.CidrBlocks {
+			posture.PublicAccessCIDRs = append(posture.PublicAccessCIDRs, block.CidrBlock)
+		}
+	}
+	if len(describe.NodePools) > 0 {
+		for _, pool := range describe.NodePools {
+			if pool.Management == nil || !pool.Management.AutoUpgrade {
+				posture.NodePoolsWithoutAutoUpgrade = append(posture.NodePoolsWithoutAutoUpgrade, pool.Name)
+			}
+		}
+		posture.NodeAutoUpgrade = boolPtr(len(posture.NodePoolsWithoutAutoUpgrade) == 0)
+	}
+	return posture
+}
+
+func contains(values []string, value string) bool {
+	for i := range values {
+		if values[i] == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,67 @@
+package cloudconnector
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func toMap(t *testing.T, s string) map[string]interface{} {
+	data := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(s), &data); err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestPostureFromDescribeEKS(t *testing.T) {
+	data := toMap(t, `{"Cluster": {"Name": "demo",
+		"ResourcesVpcConfig": {"EndpointPublicAccess": true, "EndpointPrivateAccess": false, "PublicAccessCidrs": ["0.0.0.0/0"]},
+		"Logging": {"ClusterLogging": [{"Enabled": true, "Types": ["api", "audit"]}, {"Enabled": false, "Types": ["scheduler"]}]},
+		"EncryptionConfig": [{"Resources": ["secrets"], "Provider": {"KeyArn": "arn:aws:kms:us-east-1:123:key/abc"}}]}}`)
+	posture, err := PostureFromDescribe("eks", data)
+	assert.NoError(t, err)
+	assert.Equal(t, &ClusterPosture{
+		Provider:          "eks",
+		PublicEndpoint:    boolPtr(true),
+		PublicAccessCIDRs: []string{"0.0.0.0/0"},
+		AuditLogging:      boolPtr(true),
+		SecretsEncryption: boolPtr(true),
+	}, posture, "the node auto-upgrade is not a setting of the eks clusters")
+
+	data = toMap(t, `{"Cluster": {"ResourcesVpcConfig": {"EndpointPublicAccess": false, "PublicAccessCidrs": ["0.0.0.0/0"]},
+		"Logging": {"ClusterLogging": [{"Enabled": false, "Types": ["audit"]}]}}}`)
+	posture, err = PostureFromDescribe("eks", data)
+	assert.NoError(t, err)
+	assert.Equal(t, &ClusterPosture{Provider: "eks", PublicEndpoint: boolPtr(false), AuditLogging: boolPtr(false), SecretsEncryption: boolPtr(false)}, posture)
+}
+
+func TestPostureFromDescribeGKE(t *testing.T) {
+	data := toMap(t, `{"name": "demo", "logging_service": "logging.googleapis.com/kubernetes",
+		"master_authorized_networks_config": {"enabled": true, "cidr_blocks": [{"cidr_block": "10.0.0.0/8"}]},
+		"database_encryption": {"state": 2},
+		"node_pools": [{"name": "default", "management": {"auto_upgrade": true}}, {"name": "gpu", "management": {"auto_repair": true}}]}`)
+	posture, err := PostureFromDescribe("gke", data)
+	assert.NoError(t, err)
+	assert.Equal(t, &ClusterPosture{
+		Provider:                    "gke",
+		PublicEndpoint:              boolPtr(true),
+		PublicAccessCIDRs:           []string{"10.0.0.0/8"},
+		AuditLogging:                boolPtr(true),
+		NodeAutoUpgrade:             boolPtr(false),
+		NodePoolsWithoutAutoUpgrade: []string{"gpu"},
+		SecretsEncryption:           boolPtr(false),
+	}, posture)
+
+	data = toMap(t, `{"logging_service": "none", "private_cluster_config": {"enable_private_endpoint": true},
+		"database_encryption": {"state": 1, "key_name": "projects/p/locations/l/keyRings/r/cryptoKeys/k"},
+		"node_pools": [{"name": "default", "management": {"auto_upgrade": true}}]}`)
+	posture, err = PostureFromDescribe("gke", data)
+	assert.NoError(t, err)
+	assert.Equal(t, &ClusterPosture{Provider: "gke", PublicEndpoint: boolPtr(false), AuditLogging: boolPtr(false), NodeAutoUpgrade: boolPtr(true),
+		SecretsEncryption: boolPtr(true)}, posture)
+
+	_, err = PostureFromDescribe("aks", data)
+	assert.Error(t, err)
+}
@@ -0,0 +1,17 @@
+package armo_builtins
+
+# the flag is true by default
+deny[msga] {
+	obj := input[_]
+	obj.kind == "ControlPlaneComponent"
+	obj.data.component == "kube-apiserver"
+	object.get(obj.data.flags, "anonymous-auth", "true") != "false"
+	msga := {
+		"alertMessage": sprintf("the API server %v allows the anonymous requests", [obj.metadata.name]),
+		"packagename": "armo_builtins",
+		"alertScore": 8,
+		"failedPaths": ["data.flags"],
+		"fixPaths": [{"path": "data.flags.anonymous-auth", "value": "false"}],
+		"alertObject": {"k8sApiObjects": [obj]}
+	}
+}
@@ -0,0 +1,16 @@
+package armo_builtins
+
+deny[msga] {
+	obj := input[_]
+	obj.kind == "ControlPlaneComponent"
+	obj.data.component == "kube-apiserver"
+	not obj.data.flags["audit-log-path"]
+	msga := {
+		"alertMessage": sprintf("the API server %v does not write audit logs", [obj.metadata.name]),
+		"packagename": "armo_builtins",
+		"alertScore": 5,
+		"failedPaths": ["data.flags"],
+		"fixPaths": [{"path": "data.flags.audit-log-path", "value": "/var/log/kubernetes/audit.log"}],
+		"alertObject": {"k8sApiObjects": [obj]}
+	}
+}
@@ -0,0 +1,18 @@
+package armo_builtins
+
+# AlwaysAllow is the default mode
+deny[msga] {
+	obj := input[_]
+	obj.kind == "ControlPlaneComponent"
+	obj.data.component == "kube-apiserver"
+	mode := split(object.get(obj.data.flags, "authorization-mode", "AlwaysAllow"), ",")
+	mode[_] == "AlwaysAllow"
+	msga := {
+		"alertMessage": sprintf("the API server %v authorizes all the requests", [obj.metadata.name]),
+		"packagename": "armo_builtins",
+		"alertScore": 9,
+		"failedPaths": ["data.flags"],
+		"fixPaths": [{"path": "data.flags.authorization-mode", "value": "Node,RBAC"}],
+		"alertObject": {"k8sApiObjects": [obj]}
+	}
+}
@@ -0,0 +1,16 @@
+package armo_builtins
+
+deny[msga] {
+	obj := input[_]
+	obj.kind == "ControlPlaneComponent"
+	obj.data.component == "kube-apiserver"
+	not obj.data.flags["encryption-provider-config"]
+	msga := {
+		"alertMessage": sprintf("the API server %v does not encrypt the secrets in etcd", [obj.metadata.name]),
+		"packagename": "armo_builtins",
+		"alertScore": 6,
+		"failedPaths": ["data.flags"],
+		"fixPaths": [],
+		"alertObject": {"k8sApiObjects": [obj]}
+	}
+}
@@ -0,0 +1,21 @@
+package armo_builtins
+
+deny[msga] {
+	obj := input[_]
+	obj.kind == "ControlPlaneComponent"
+	obj.data.component == "kube-apiserver"
+	plugins := split(object.get(obj.data.flags, "enable-admission-plugins", ""), ",")
+	not contains_value(plugins, "NodeRestriction")
+	msga := {
+		"alertMessage": sprintf("the API server %v does not enable the NodeRestriction admission plugin", [obj.metadata.name]),
+		"packagename": "armo_builtins",
+		"alertScore": 6,
+		"failedPaths": ["data.flags"],
+		"fixPaths": [{"path": "data.flags.enable-admission-plugins", "value": "NodeRestriction"}],
+		"alertObject": {"k8sApiObjects": [obj]}
+	}
+}
+
+contains_value(values, value) {
+	values[_] == value
+}
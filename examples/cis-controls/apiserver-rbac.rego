@@ -0,0 +1,21 @@
+package armo_builtins
+
+deny[msga] {
+	obj := input[_]
+	obj.kind == "ControlPlaneComponent"
+	obj.data.component == "kube-apiserver"
+	mode := split(object.get(obj.data.flags, "authorization-mode", "AlwaysAllow"), ",")
+	not contains_value(mode, "RBAC")
+	msga := {
+		"alertMessage": sprintf("the API server %v does not authorize the requests by RBAC", [obj.metadata.name]),
+		"packagename": "armo_builtins",
+		"alertScore": 7,
+		"failedPaths": ["data.flags"],
+		"fixPaths": [{"path": "data.flags.authorization-mode", "value": "Node,RBAC"}],
+		"alertObject": {"k8sApiObjects": [obj]}
+	}
+}
+
+contains_value(values, value) {
+	values[_] == value
+}
@@ -0,0 +1,16 @@
+package armo_builtins
+
+deny[msga] {
+	obj := input[_]
+	obj.kind == "ControlPlaneComponent"
+	obj.data.component == "kube-apiserver"
+	obj.data.flags["token-auth-file"]
+	msga := {
+		"alertMessage": sprintf("the API server %v authenticates by the static tokens of --token-auth-file", [obj.metadata.name]),
+		"packagename": "armo_builtins",
+		"alertScore": 7,
+		"failedPaths": ["data.flags"],
+		"fixPaths": [],
+		"alertObject": {"k8sApiObjects": [obj]}
+	}
+}
@@ -0,0 +1,16 @@
+package armo_builtins
+
+deny[msga] {
+	obj := input[_]
+	obj.kind == "ControlPlaneComponent"
+	obj.data.component == "kube-controller-manager"
+	object.get(obj.data.flags, "use-service-account-credentials", "false") != "true"
+	msga := {
+		"alertMessage": sprintf("the controllers of %v share the credentials of the controller manager", [obj.metadata.name]),
+		"packagename": "armo_builtins",
+		"alertScore": 4,
+		"failedPaths": ["data.flags"],
+		"fixPaths": [{"path": "data.flags.use-service-account-credentials", "value": "true"}],
+		"alertObject": {"k8sApiObjects": [obj]}
+	}
+}
@@ -0,0 +1,16 @@
+package armo_builtins
+
+deny[msga] {
+	obj := input[_]
+	obj.kind == "ControlPlaneComponent"
+	obj.data.component == "etcd"
+	obj.data.flags["auto-tls"] == "true"
+	msga := {
+		"alertMessage": sprintf("etcd %v serves its clients by self-signed certificates", [obj.metadata.name]),
+		"packagename": "armo_builtins",
+		"alertScore": 6,
+		"failedPaths": ["data.flags"],
+		"fixPaths": [{"path": "data.flags.auto-tls", "value": "false"}],
+		"alertObject": {"k8sApiObjects": [obj]}
+	}
+}
@@ -0,0 +1,16 @@
+package armo_builtins
+
+deny[msga] {
+	obj := input[_]
+	obj.kind == "ControlPlaneComponent"
+	obj.data.component == "etcd"
+	object.get(obj.data.flags, "client-cert-auth", "false") != "true"
+	msga := {
+		"alertMessage": sprintf("etcd %v does not authenticate its clients", [obj.metadata.name]),
+		"packagename": "armo_builtins",
+		"alertScore": 8,
+		"failedPaths": ["data.flags"],
+		"fixPaths": [{"path": "data.flags.client-cert-auth", "value": "true"}],
+		"alertObject": {"k8sApiObjects": [obj]}
+	}
+}
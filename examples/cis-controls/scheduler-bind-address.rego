@@ -0,0 +1,17 @@
+package armo_builtins
+
+# all the interfaces by default
+deny[msga] {
+	obj := input[_]
+	obj.kind == "ControlPlaneComponent"
+	obj.data.component == "kube-scheduler"
+	object.get(obj.data.flags, "bind-address", "0.0.0.0") != "127.0.0.1"
+	msga := {
+		"alertMessage": sprintf("the scheduler %v listens on the network", [obj.metadata.name]),
+		"packagename": "armo_builtins",
+		"alertScore": 4,
+		"failedPaths": ["data.flags"],
+		"fixPaths": [{"path": "data.flags.bind-address", "value": "127.0.0.1"}],
+		"alertObject": {"k8sApiObjects": [obj]}
+	}
+}
@@ -0,0 +1,17 @@
+package armo_builtins
+
+# the flag is true by default
+deny[msga] {
+	obj := input[_]
+	obj.kind == "ControlPlaneComponent"
+	obj.data.component == "kube-scheduler"
+	object.get(obj.data.flags, "profiling", "true") != "false"
+	msga := {
+		"alertMessage": sprintf("the profiling of the scheduler %v is enabled", [obj.metadata.name]),
+		"packagename": "armo_builtins",
+		"alertScore": 3,
+		"failedPaths": ["data.flags"],
+		"fixPaths": [{"path": "data.flags.profiling", "value": "false"}],
+		"alertObject": {"k8sApiObjects": [obj]}
+	}
+}
@@ -0,0 +1,15 @@
+package armo_builtins
+
+deny[msga] {
+	obj := input[_]
+	obj.kind == "ClusterPosture"
+	obj.data.auditLogging == false
+	msga := {
+		"alertMessage": sprintf("the audit logs of cluster %v are not collected", [obj.metadata.name]),
+		"packagename": "armo_builtins",
+		"alertScore": 5,
+		"failedPaths": ["data.auditLogging"],
+		"fixPaths": [],
+		"alertObject": {"k8sApiObjects": [obj]}
+	}
+}
@@ -0,0 +1,16 @@
+package armo_builtins
+
+deny[msga] {
+	obj := input[_]
+	obj.kind == "ClusterPosture"
+	obj.data.nodeAutoUpgrade == false
+	pools := object.get(obj.data, "nodePoolsWithoutAutoUpgrade", [])
+	msga := {
+		"alertMessage": sprintf("the node pools %v of cluster %v are not upgraded automatically", [pools, obj.metadata.name]),
+		"packagename": "armo_builtins",
+		"alertScore": 4,
+		"failedPaths": ["data.nodeAutoUpgrade"],
+		"fixPaths": [],
+		"alertObject": {"k8sApiObjects": [obj]}
+	}
+}
@@ -0,0 +1,25 @@
+package armo_builtins
+
+# the public endpoints any network can reach
+deny[msga] {
+	obj := input[_]
+	obj.kind == "ClusterPosture"
+	obj.data.publicEndpoint == true
+	open_to_any_network(obj.data)
+	msga := {
+		"alertMessage": sprintf("the API server of cluster %v is reachable from any network", [obj.metadata.name]),
+		"packagename": "armo_builtins",
+		"alertScore": 7,
+		"failedPaths": ["data.publicEndpoint"],
+		"fixPaths": [],
+		"alertObject": {"k8sApiObjects": [obj]}
+	}
+}
+
+open_to_any_network(posture) {
+	not posture.publicAccessCIDRs
+}
+
+open_to_any_network(posture) {
+	posture.publicAccessCIDRs[_] == "0.0.0.0/0"
+}
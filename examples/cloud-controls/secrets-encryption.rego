@@ -0,0 +1,15 @@
+package armo_builtins
+
+deny[msga] {
+	obj := input[_]
+	obj.kind == "ClusterPosture"
+	obj.data.secretsEncryption == false
+	msga := {
+		"alertMessage": sprintf("the secrets of cluster %v are not encrypted by a key management service", [obj.metadata.name]),
+		"packagename": "armo_builtins",
+		"alertScore": 6,
+		"failedPaths": ["data.secretsEncryption"],
+		"fixPaths": [],
+		"alertObject": {"k8sApiObjects": [obj]}
+	}
+}
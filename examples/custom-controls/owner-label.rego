@@ -0,0 +1,14 @@
+package armo_builtins
+
+deny[msga] {
+	wl := input[_]
+	not wl.metadata.labels.team
+	msga := {
+		"alertMessage": sprintf("%v: %v has no team label", [wl.kind, wl.metadata.name]),
+		"packagename": "armo_builtins",
+		"alertScore": 5,
+		"failedPaths": ["metadata.labels"],
+		"fixPaths": [{"path": "metadata.labels.team", "value": "YOUR_VALUE"}],
+		"alertObject": {"k8sApiObjects": [wl]}
+	}
+}
@@ -0,0 +1,65 @@
+package armo_builtins
+
+# the workloads running an image as its root user, the images configurations are looked up by 'kubescape scan --enable-image-metadata'
+deny[msga] {
+	wl := input[_]
+	container := containers(wl)[i]
+	metadata := input[_]
+	metadata.kind == "ImageMetadata"
+	metadata.metadata.name == container.image
+	root_user(metadata.data.user)
+	not non_root(pod_spec(wl))
+	not non_root(container)
+	msga := {
+		"alertMessage": sprintf("%v: %v runs the image %v as root", [wl.kind, wl.metadata.name, container.image]),
+		"packagename": "armo_builtins",
+		"alertScore": 6,
+		"failedPaths": [sprintf("%v[%v].image", [containers_path(wl), i])],
+		"fixPaths": [{"path": sprintf("%v[%v].securityContext.runAsNonRoot", [containers_path(wl), i]), "value": "true"}],
+		"alertObject": {"k8sApiObjects": [wl]}
+	}
+}
+
+# the user of the image is root when unset, root or uid 0, with or without a group
+root_user(user) {
+	{"", "root", "0"}[user]
+}
+
+root_user(user) {
+	{"root", "0"}[split(user, ":")[0]]
+}
+
+# the securityContext of the pod or of the container overrides the user of the image
+non_root(spec) {
+	spec.securityContext.runAsNonRoot == true
+}
+
+non_root(spec) {
+	spec.securityContext.runAsUser > 0
+}
+
+pod_spec(wl) = wl.spec {
+	wl.kind == "Pod"
+}
+
+pod_spec(wl) = wl.spec.template.spec {
+	{"Deployment", "StatefulSet", "DaemonSet", "ReplicaSet", "Job"}[wl.kind]
+}
+
+pod_spec(wl) = wl.spec.jobTemplate.spec.template.spec {
+	wl.kind == "CronJob"
+}
+
+containers(wl) = pod_spec(wl).containers
+
+containers_path(wl) = "spec.containers" {
+	wl.kind == "Pod"
+}
+
+containers_path(wl) = "spec.template.spec.containers" {
+	{"Deployment", "StatefulSet", "DaemonSet", "ReplicaSet", "Job"}[wl.kind]
+}
+
+containers_path(wl) = "spec.jobTemplate.spec.template.spec.containers" {
+	wl.kind == "CronJob"
+}
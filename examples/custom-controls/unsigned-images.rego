@@ -0,0 +1,43 @@
+package armo_builtins
+
+# the workloads running an image which is not verified, the images are verified by 'kubescape scan --verify-image-signatures'
+deny[msga] {
+	wl := input[_]
+	container := containers(wl)[i]
+	signature := input[_]
+	signature.kind == "ImageSignature"
+	signature.metadata.name == container.image
+	not signature.data.verified
+	msga := {
+		"alertMessage": sprintf("%v: %v runs the image %v which is not verified: %v", [wl.kind, wl.metadata.name, container.image, signature.data.error]),
+		"packagename": "armo_builtins",
+		"alertScore": 7,
+		"failedPaths": [sprintf("%v[%v].image", [containers_path(wl), i])],
+		"fixPaths": [],
+		"alertObject": {"k8sApiObjects": [wl]}
+	}
+}
+
+containers(wl) = wl.spec.containers {
+	wl.kind == "Pod"
+}
+
+containers(wl) = wl.spec.template.spec.containers {
+	{"Deployment", "StatefulSet", "DaemonSet", "ReplicaSet", "Job"}[wl.kind]
+}
+
+containers(wl) = wl.spec.jobTemplate.spec.template.spec.containers {
+	wl.kind == "CronJob"
+}
+
+containers_path(wl) = "spec.containers" {
+	wl.kind == "Pod"
+}
+
+containers_path(wl) = "spec.template.spec.containers" {
+	{"Deployment", "StatefulSet", "DaemonSet", "ReplicaSet", "Job"}[wl.kind]
+}
+
+containers_path(wl) = "spec.jobTemplate.spec.template.spec.containers" {
+	wl.kind == "CronJob"
+}
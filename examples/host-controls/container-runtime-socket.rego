@@ -0,0 +1,33 @@
+package armo_builtins
+
+# the sockets of the container runtimes more permissive than 660, collected by the host sensor
+deny[msga] {
+	obj := input[_]
+	obj.kind == "ContainerRuntimeInfo"
+	socket := obj.data.socket
+	bits.and(socket.permissions, bits.negate(432)) != 0 # 0660
+	msga := {
+		"alertMessage": sprintf("the %v socket %v of node %v is more permissive than 660", [obj.data.name, socket.path, obj.metadata.name]),
+		"packagename": "armo_builtins",
+		"alertScore": 8,
+		"failedPaths": ["data.socket.permissions"],
+		"fixPaths": [],
+		"alertObject": {"k8sApiObjects": [obj]}
+	}
+}
+
+# the sockets of the container runtimes not owned by root, the group may be the group of the runtime (e.g. docker)
+deny[msga] {
+	obj := input[_]
+	obj.kind == "ContainerRuntimeInfo"
+	socket := obj.data.socket
+	socket.ownership.uid != 0
+	msga := {
+		"alertMessage": sprintf("the %v socket %v of node %v is not owned by root", [obj.data.name, socket.path, obj.metadata.name]),
+		"packagename": "armo_builtins",
+		"alertScore": 8,
+		"failedPaths": ["data.socket.ownership"],
+		"fixPaths": [],
+		"alertObject": {"k8sApiObjects": [obj]}
+	}
+}
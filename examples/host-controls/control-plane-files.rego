@@ -0,0 +1,54 @@
+package armo_builtins
+
+# the files of the control plane more permissive than allowed, collected by the host sensor
+deny[msga] {
+	obj := input[_]
+	obj.kind == "ControlPlaneInfo"
+	file_key := {"manifestFiles", "kubeConfigFiles", "PKIFiles"}[_]
+	file := obj.data[file_key][i]
+	allowed := allowed_permissions(file_key, file.path)
+	bits.and(file.permissions, bits.negate(allowed.mode)) != 0
+	msga := {
+		"alertMessage": sprintf("%v of node %v is more permissive than %v", [file.path, obj.metadata.name, allowed.octal]),
+		"packagename": "armo_builtins",
+		"alertScore": 7,
+		"failedPaths": [sprintf("data.%v[%v].permissions", [file_key, i])],
+		"fixPaths": [],
+		"alertObject": {"k8sApiObjects": [obj]}
+	}
+}
+
+# the files of the control plane not owned by root:root
+deny[msga] {
+	obj := input[_]
+	obj.kind == "ControlPlaneInfo"
+	file_key := {"manifestFiles", "kubeConfigFiles", "PKIFiles"}[_]
+	file := obj.data[file_key][i]
+	not root_owned(file.ownership)
+	msga := {
+		"alertMessage": sprintf("%v of node %v is not owned by root:root", [file.path, obj.metadata.name]),
+		"packagename": "armo_builtins",
+		"alertScore": 7,
+		"failedPaths": [sprintf("data.%v[%v].ownership", [file_key, i])],
+		"fixPaths": [],
+		"alertObject": {"k8sApiObjects": [obj]}
+	}
+}
+
+# the manifests 644, the kubeconfigs 600, the certificates of the PKI 644 and its keys 600
+allowed_permissions("manifestFiles", _) = {"mode": 420, "octal": "644"}
+
+allowed_permissions("kubeConfigFiles", _) = {"mode": 384, "octal": "600"}
+
+allowed_permissions("PKIFiles", path) = {"mode": 420, "octal": "644"} {
+	endswith(path, ".crt")
+}
+
+allowed_permissions("PKIFiles", path) = {"mode": 384, "octal": "600"} {
+	endswith(path, ".key")
+}
+
+root_owned(ownership) {
+	ownership.uid == 0
+	ownership.gid == 0
+}
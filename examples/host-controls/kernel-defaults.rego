@@ -0,0 +1,28 @@
+package armo_builtins
+
+# the kernel parameters of the kubelet, the kubelet fails to start when the parameters differ and protectKernelDefaults is set
+kernel_defaults := {
+	"vm.overcommit_memory": "1",
+	"vm.panic_on_oom": "0",
+	"kernel.panic": "10",
+	"kernel.panic_on_oops": "1",
+	"kernel.keys.root_maxkeys": "1000000",
+	"kernel.keys.root_maxbytes": "25000000",
+}
+
+# the nodes with kernel parameters differing from the defaults of the kubelet, the parameters are read by the host sensor (/proc/sys)
+deny[msga] {
+	obj := input[_]
+	obj.kind == "LinuxKernelVariables"
+	variable := obj.data[i]
+	expected := kernel_defaults[variable.key]
+	trim_space(variable.value) != expected
+	msga := {
+		"alertMessage": sprintf("the kernel parameter %v of node %v is %v instead of %v", [variable.key, obj.metadata.name, trim_space(variable.value), expected]),
+		"packagename": "armo_builtins",
+		"alertScore": 4,
+		"failedPaths": [sprintf("data[%v].value", [i])],
+		"fixPaths": [],
+		"alertObject": {"k8sApiObjects": [obj]}
+	}
+}
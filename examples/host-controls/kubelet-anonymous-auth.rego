@@ -0,0 +1,38 @@
+package armo_builtins
+
+# the kubelets enabling the anonymous requests by their config, the flag overrides the config
+deny[msga] {
+	obj := input[_]
+	obj.kind == "KubeletConfiguration"
+	obj.data.authentication.anonymous.enabled == true
+	not command_line_flag(input, obj.metadata.name, "--anonymous-auth=false")
+	msga := {
+		"alertMessage": sprintf("the kubelet of node %v allows the anonymous requests", [obj.metadata.name]),
+		"packagename": "armo_builtins",
+		"alertScore": 7,
+		"failedPaths": ["data.authentication.anonymous.enabled"],
+		"fixPaths": [{"path": "data.authentication.anonymous.enabled", "value": "false"}],
+		"alertObject": {"k8sApiObjects": [obj]}
+	}
+}
+
+deny[msga] {
+	obj := input[_]
+	obj.kind == "KubeletCommandLine"
+	contains(obj.data.fullCommand, "--anonymous-auth=true")
+	msga := {
+		"alertMessage": sprintf("the kubelet of node %v allows the anonymous requests", [obj.metadata.name]),
+		"packagename": "armo_builtins",
+		"alertScore": 7,
+		"failedPaths": ["data.fullCommand"],
+		"fixPaths": [],
+		"alertObject": {"k8sApiObjects": [obj]}
+	}
+}
+
+command_line_flag(objs, node, flag) {
+	obj := objs[_]
+	obj.kind == "KubeletCommandLine"
+	obj.metadata.name == node
+	contains(obj.data.fullCommand, flag)
+}
@@ -0,0 +1,38 @@
+package armo_builtins
+
+# the kubelets authorizing all of the requests by their config, the flag overrides the config
+deny[msga] {
+	obj := input[_]
+	obj.kind == "KubeletConfiguration"
+	obj.data.authorization.mode == "AlwaysAllow"
+	not command_line_flag(input, obj.metadata.name, "--authorization-mode=Webhook")
+	msga := {
+		"alertMessage": sprintf("the kubelet of node %v authorizes all of the requests", [obj.metadata.name]),
+		"packagename": "armo_builtins",
+		"alertScore": 7,
+		"failedPaths": ["data.authorization.mode"],
+		"fixPaths": [{"path": "data.authorization.mode", "value": "Webhook"}],
+		"alertObject": {"k8sApiObjects": [obj]}
+	}
+}
+
+deny[msga] {
+	obj := input[_]
+	obj.kind == "KubeletCommandLine"
+	contains(obj.data.fullCommand, "--authorization-mode=AlwaysAllow")
+	msga := {
+		"alertMessage": sprintf("the kubelet of node %v authorizes all of the requests", [obj.metadata.name]),
+		"packagename": "armo_builtins",
+		"alertScore": 7,
+		"failedPaths": ["data.fullCommand"],
+		"fixPaths": [],
+		"alertObject": {"k8sApiObjects": [obj]}
+	}
+}
+
+command_line_flag(objs, node, flag) {
+	obj := objs[_]
+	obj.kind == "KubeletCommandLine"
+	obj.metadata.name == node
+	contains(obj.data.fullCommand, flag)
+}
@@ -0,0 +1,40 @@
+package armo_builtins
+
+# the files of the kubelet more permissive than 644, collected by the host sensor
+deny[msga] {
+	obj := input[_]
+	obj.kind == "KubeletInfo"
+	file_key := {"configFile", "kubeConfigFile", "clientCAFile"}[_]
+	file := obj.data[file_key]
+	bits.and(file.permissions, bits.negate(420)) != 0 # 0644
+	msga := {
+		"alertMessage": sprintf("%v of node %v is more permissive than 644", [file.path, obj.metadata.name]),
+		"packagename": "armo_builtins",
+		"alertScore": 6,
+		"failedPaths": [sprintf("data.%v.permissions", [file_key])],
+		"fixPaths": [],
+		"alertObject": {"k8sApiObjects": [obj]}
+	}
+}
+
+# the files of the kubelet not owned by root:root
+deny[msga] {
+	obj := input[_]
+	obj.kind == "KubeletInfo"
+	file_key := {"configFile", "kubeConfigFile", "clientCAFile"}[_]
+	file := obj.data[file_key]
+	not root_owned(file.ownership)
+	msga := {
+		"alertMessage": sprintf("%v of node %v is not owned by root:root", [file.path, obj.metadata.name]),
+		"packagename": "armo_builtins",
+		"alertScore": 6,
+		"failedPaths": [sprintf("data.%v.ownership", [file_key])],
+		"fixPaths": [],
+		"alertObject": {"k8sApiObjects": [obj]}
+	}
+}
+
+root_owned(ownership) {
+	ownership.uid == 0
+	ownership.gid == 0
+}
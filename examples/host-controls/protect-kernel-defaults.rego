@@ -0,0 +1,16 @@
+package armo_builtins
+
+# the kubelets ignoring the kernel parameters differing from the defaults of the kubelet
+deny[msga] {
+	obj := input[_]
+	obj.kind == "KubeletConfiguration"
+	not obj.data.protectKernelDefaults == true
+	msga := {
+		"alertMessage": sprintf("the kubelet of node %v does not protect the kernel defaults", [obj.metadata.name]),
+		"packagename": "armo_builtins",
+		"alertScore": 4,
+		"failedPaths": [],
+		"fixPaths": [{"path": "data.protectKernelDefaults", "value": "true"}],
+		"alertObject": {"k8sApiObjects": [obj]}
+	}
+}
@@ -0,0 +1,57 @@
+package armo_builtins
+
+# the workloads no ingress policy selects, any source reaches their ports
+deny[msga] {
+	obj := input[_]
+	obj.kind == "NetworkPolicyCoverage"
+	obj.data.ingress == "uncovered"
+	msga := {
+		"alertMessage": sprintf("%v %v/%v is not selected by an ingress network policy, %v accept traffic from any source", [obj.data.kind, obj.data.namespace, obj.data.name, ports_description(obj.data.ports)]),
+		"packagename": "armo_builtins",
+		"alertScore": 6,
+		"failedPaths": ["data.ingressPolicies"],
+		"fixPaths": [],
+		"alertObject": {"k8sApiObjects": [obj]}
+	}
+}
+
+# the ports a policy opens to any source
+deny[msga] {
+	obj := input[_]
+	obj.kind == "NetworkPolicyCoverage"
+	obj.data.ingress != "uncovered"
+	open_ports := [port | port := obj.data.ports[_]; port.status == "open"]
+	count(open_ports) > 0
+	policies := {policy | policy := open_ports[_].policies[_]}
+	msga := {
+		"alertMessage": sprintf("%v %v/%v accepts traffic from any source on %v, allowed by the network policies %v", [obj.data.kind, obj.data.namespace, obj.data.name, ports_description(open_ports), concat(", ", sort(policies))]),
+		"packagename": "armo_builtins",
+		"alertScore": 6,
+		"failedPaths": [sprintf("data.ports[%v]", [i]) | obj.data.ports[i].status == "open"],
+		"fixPaths": [],
+		"alertObject": {"k8sApiObjects": [obj]}
+	}
+}
+
+# the workloads no egress policy selects
+deny[msga] {
+	obj := input[_]
+	obj.kind == "NetworkPolicyCoverage"
+	obj.data.egress == "uncovered"
+	msga := {
+		"alertMessage": sprintf("%v %v/%v is not selected by an egress network policy, it can connect to any destination", [obj.data.kind, obj.data.namespace, obj.data.name]),
+		"packagename": "armo_builtins",
+		"alertScore": 6,
+		"failedPaths": ["data.egressPolicies"],
+		"fixPaths": [],
+		"alertObject": {"k8sApiObjects": [obj]}
+	}
+}
+
+ports_description(ports) = "all of its ports" {
+	count(ports) == 0
+}
+
+ports_description(ports) = sprintf("the ports %v", [concat(", ", [sprintf("%v/%v (%v)", [port.port, port.protocol, port.container]) | port := ports[_]])]) {
+	count(ports) > 0
+}
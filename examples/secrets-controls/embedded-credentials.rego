@@ -0,0 +1,16 @@
+package armo_builtins
+
+deny[msga] {
+	obj := input[_]
+	obj.kind == "SecretExposure"
+	count(obj.data.findings) > 0
+	findings := [sprintf("%v (%v: %v)", [finding.path, finding.type, finding.evidence]) | finding := obj.data.findings[_]]
+	msga := {
+		"alertMessage": sprintf("%v %v/%v embeds credentials: %v", [obj.data.kind, obj.data.namespace, obj.data.name, concat(", ", findings)]),
+		"packagename": "armo_builtins",
+		"alertScore": 8,
+		"failedPaths": [sprintf("data.findings[%v]", [i]) | obj.data.findings[i]],
+		"fixPaths": [],
+		"alertObject": {"k8sApiObjects": [obj]}
+	}
+}
@@ -0,0 +1,136 @@
+package exceptionshandler
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/armosec/armoapi-go/armotypes"
+	"github.com/armosec/k8s-interface/workloadinterface"
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/cautils/logger/helpers"
+	"github.com/armosec/opa-utils/reporthandling/results/v1/resourcesresults"
+)
+
+const baselineReason = "baseline of the failures when adopting kubescape"
+
+// NewBaseline returns an exception per resource which failed controls, covering the failed controls of the resource.
+// The controls excluded by exceptions are covered as well, a baseline replaces the exceptions it was generated with
+func NewBaseline(results []resourcesresults.Result, resources map[string]workloadinterface.IMetadata, now time.Time) []armotypes.PostureExceptionPolicy {
+	results = append([]resourcesresults.Result{}, results...)
+	sort.Slice(results, func(i, j int) bool { return results[i].ResourceID < results[j].ResourceID })
+
+	exceptions := []armotypes.PostureExceptionPolicy{}
+	names := map[string]int{}
+	for i := range results {
+		resource, ok := resources[results[i].ResourceID]
+		if !ok {
+			continue // the exceptions match the attributes of the resource object
+		}
+		controlIDs := []string{}
+		for _, control := range results[i].AssociatedControls {
+			if status := control.GetStatus(nil); status.IsFailed() || status.IsExcluded() {
+				controlIDs = append(controlIDs, control.GetID())
+			}
+		}
+		if len(controlIDs) == 0 {
+			continue
+		}
+		sort.Strings(controlIDs)
+		exceptions = append(exceptions, newResourceException(baselineExceptionName(resource, names), baselineReason, resource, controlIDs, now))
+	}
+	return exceptions
+}
+
+// NewResourcesExceptions returns an exception per resource, covering the given controls of the resource - map[<resource ID>][]<control ID>
+func NewResourcesExceptions(controls map[string][]string, resources map[string]workloadinterface.IMetadata, reason string, now time.Time) []armotypes.PostureExceptionPolicy {
+	resourcesIDs := make([]string, 0, len(controls))
+	for resourceID := range controls {
+		resourcesIDs = append(resourcesIDs, resourceID)
+	}
+	sort.Strings(resourcesIDs)
+
+	exceptions := []armotypes.PostureExceptionPolicy{}
+	names := map[string]int{}
+	for _, resourceID := range resourcesIDs {
+		resource, ok := resources[resourceID]
+		if !ok || len(controls[resourceID]) == 0 {
+			continue
+		}
+		controlIDs := append([]string{}, controls[resourceID]...)
+		sort.Strings(controlIDs)
+		exceptions = append(exceptions, newResourceException(resourceExceptionName("exception", resource, names), reason, resource, controlIDs, now))
+	}
+	return exceptions
+}
+
+// newResourceException returns an alertOnly exception of the resource, matching its kind, namespace and name, covering the controls
+func newResourceException(name, reason string, resource workloadinterface.IMetadata, controlIDs []string, now time.Time) armotypes.PostureExceptionPolicy {
+	// the attributes are regular expressions (matching the whole value), the special characters of the names are escaped
+	attributes := map[string]string{"kind": regexp.QuoteMeta(resource.GetKind()), "name": regexp.QuoteMeta(resource.GetName())}
+	if namespace := resource.GetNamespace(); namespace != "" {
+		attributes["namespace"] = regexp.QuoteMeta(namespace)
+	}
+	exception := armotypes.PostureExceptionPolicy{
+		PortalBase: armotypes.PortalBase{
+			Name:       name,
+			Attributes: map[string]interface{}{ReasonAttribute: reason},
+		},
+		PolicyType:   PolicyType,
+		CreationTime: now.UTC().Format(time.RFC3339),
+		Actions:      []armotypes.PostureExceptionPolicyActions{armotypes.AlertOnly},
+		Resources:    []armotypes.PortalDesignator{{DesignatorType: armotypes.DesignatorAttributes, Attributes: attributes}},
+	}
+	for _, controlID := range controlIDs {
+		exception.PosturePolicies = append(exception.PosturePolicies, armotypes.PosturePolicy{ControlID: controlID})
+	}
+	return exception
+}
+
+// baselineExceptionName returns a unique name of the baseline exception of the resource
+func baselineExceptionName(resource workloadinterface.IMetadata, names map[string]int) string {
+	return resourceExceptionName("baseline", resource, names)
+}
+
+// resourceExceptionName returns a unique name of the resource exception, resources of different API versions may have the same kind and name
+func resourceExceptionName(prefix string, resource workloadinterface.IMetadata, names map[string]int) string {
+	parts := []string{prefix, resource.GetKind()}
+	if namespace := resource.GetNamespace(); namespace != "" {
+		parts = append(parts, namespace)
+	}
+	name := strings.ToLower(strings.Join(append(parts, resource.GetName()), "-"))
+	names[name]++
+	if names[name] > 1 {
+		name = fmt.Sprintf("%s-%d", name, names[name])
+	}
+	return name
+}
+
+// BaselinePrinter is a printer that writes the baseline exceptions of the scan results to a file
+type BaselinePrinter struct {
+	path string
+}
+
+func NewBaselinePrinter(path string) *BaselinePrinter {
+	return &BaselinePrinter{path: path}
+}
+
+func (baselinePrinter *BaselinePrinter) SetWriter(outputFile string) {}
+
+func (baselinePrinter *BaselinePrinter) Score(score float32) {}
+
+func (baselinePrinter *BaselinePrinter) ActionPrint(opaSessionObj *cautils.OPASessionObj) error {
+	results := make([]resourcesresults.Result, 0, len(opaSessionObj.ResourcesResult))
+	for resourceID := range opaSessionObj.ResourcesResult {
+		results = append(results, opaSessionObj.ResourcesResult[resourceID])
+	}
+	exceptions := NewBaseline(results, opaSessionObj.AllResources, time.Now())
+	if err := SaveExceptions(baselinePrinter.path, exceptions); err != nil {
+		return fmt.Errorf("failed to save the exceptions baseline '%s': %w", baselinePrinter.path, err)
+	}
+	logger.L().Success("Exceptions baseline generated", helpers.String("path", baselinePrinter.path), helpers.Int("exceptions", len(exceptions)))
+	return nil
+}
@@ -0,0 +1,72 @@
+package exceptionshandler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/armosec/armoapi-go/armotypes"
+	"github.com/armosec/k8s-interface/workloadinterface"
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/opa-utils/objectsenvelopes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewBaseline(t *testing.T) {
+	report := reportMock()
+	resources := map[string]workloadinterface.IMetadata{}
+	for i := range report.Resources {
+		resources[report.Resources[i].ResourceID] = objectsenvelopes.NewObject(report.Resources[i].Object.(map[string]interface{}))
+	}
+	now := time.Date(2022, 1, 10, 0, 0, 0, 0, time.UTC)
+
+	baseline := NewBaseline(report.Results, resources, now)
+	if assert.Len(t, baseline, 3) {
+		assert.Equal(t, "baseline-deployment-dev-nginx", baseline[0].Name)
+		assert.Equal(t, "baseline-deployment-dev-redis", baseline[1].Name)
+		assert.Equal(t, "baseline-deployment-prod-nginx", baseline[2].Name)
+		assert.Equal(t, map[string]string{"kind": "Deployment", "namespace": "dev", "name": "nginx"}, baseline[0].Resources[0].Attributes)
+		assert.Equal(t, []armotypes.PosturePolicy{{ControlID: "C-0016"}, {ControlID: "C-0017"}}, baseline[0].PosturePolicies)
+		assert.Equal(t, "2022-01-10T00:00:00Z", baseline[0].CreationTime)
+		assert.NotEmpty(t, GetReason(&baseline[0]))
+	}
+	assert.NoError(t, ValidateExceptions(baseline))
+
+	// the baseline covers all of the failures, and only the failures of the resource
+	for i := range baseline {
+		assert.Len(t, SuppressedFindings(&baseline[i], report), 2, baseline[i].Name)
+	}
+
+	// a resource without failures has no exception
+	assert.Empty(t, NewBaseline(report.Results[:1], map[string]workloadinterface.IMetadata{}, now))
+}
+
+func TestNewResourcesExceptions(t *testing.T) {
+	report := reportMock()
+	resources := map[string]workloadinterface.IMetadata{}
+	for i := range report.Resources {
+		resources[report.Resources[i].ResourceID] = objectsenvelopes.NewObject(report.Resources[i].Object.(map[string]interface{}))
+	}
+	resourceID := report.Resources[0].ResourceID
+	now := time.Date(2022, 1, 10, 0, 0, 0, 0, time.UTC)
+
+	exceptions := NewResourcesExceptions(map[string][]string{resourceID: {"C-0017", "C-0016"}, "unknown": {"C-0016"}}, resources, "accepted risk", now)
+	if assert.Len(t, exceptions, 1) {
+		assert.Equal(t, resourceExceptionName("exception", resources[resourceID], map[string]int{}), exceptions[0].Name)
+		assert.Equal(t, []armotypes.PosturePolicy{{ControlID: "C-0016"}, {ControlID: "C-0017"}}, exceptions[0].PosturePolicies)
+		assert.Equal(t, "accepted risk", GetReason(&exceptions[0]))
+	}
+	assert.NoError(t, ValidateExceptions(exceptions))
+}
+
+func TestBaselineExceptionName(t *testing.T) {
+	names := map[string]int{}
+	resource := objectsenvelopes.NewObject(map[string]interface{}{"apiVersion": "v1", "kind": "ClusterRole", "metadata": map[string]interface{}{"name": "Admin"}})
+	assert.Equal(t, "baseline-clusterrole-admin", baselineExceptionName(resource, names))
+	assert.Equal(t, "baseline-clusterrole-admin-2", baselineExceptionName(resource, names))
+}
+
+func TestBaselinePrinterError(t *testing.T) {
+	// the baseline can not be written over a directory
+	err := NewBaselinePrinter(t.TempDir()).ActionPrint(cautils.NewOPASessionObjMock())
+	assert.Error(t, err)
+}
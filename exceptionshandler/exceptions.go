@@ -0,0 +1,228 @@
+package exceptionshandler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/armosec/armoapi-go/armotypes"
+	"github.com/armosec/kubescape/cautils/getter"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	PolicyType = "postureExceptionPolicy"
+
+	// ExpirationAttribute the attribute of the exception holding its expiration time (RFC3339 or a date), the expired exceptions are not applied
+	ExpirationAttribute = "expiration"
+	// ExpirationDateAttribute an alias of ExpirationAttribute, used when the exception has no ExpirationAttribute
+	ExpirationDateAttribute = "expirationDate"
+	// OwnerAttribute the attribute of the exception holding the owner of the risk acceptance
+	OwnerAttribute = "owner"
+	// ReasonAttribute the attribute of the exception holding the reason of the risk acceptance
+	ReasonAttribute = "reason"
+)
+
+// patternAttributes the attributes of the resource designators matched as globs or regular expressions, the other attributes are labels
+var patternAttributes = []string{"name", "kind", "namespace", "cluster"}
+
+// LoadExceptions loads the exceptions file, a missing file has no exceptions
+func LoadExceptions(path string) ([]armotypes.PostureExceptionPolicy, error) {
+	exceptions := []armotypes.PostureExceptionPolicy{}
+	f, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return exceptions, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(f, &exceptions); err != nil {
+		return nil, fmt.Errorf("failed to load exceptions file '%s': %w", path, err)
+	}
+	return exceptions, nil
+}
+
+// SaveExceptions validates the exceptions before saving them
+func SaveExceptions(path string, exceptions []armotypes.PostureExceptionPolicy) error {
+	if err := ValidateExceptions(exceptions); err != nil {
+		return err
+	}
+	return getter.SaveInFile(exceptions, path)
+}
+
+// ValidateExceptions validates the exceptions are applicable - unique names, resources to apply on, and valid regular expressions
+func ValidateExceptions(exceptions []armotypes.PostureExceptionPolicy) error {
+	errs := []string{}
+	names := map[string]bool{}
+	for i := range exceptions {
+		if err := validateException(&exceptions[i]); err != nil {
+			errs = append(errs, fmt.Sprintf("exception '%s': %s", exceptions[i].Name, err.Error()))
+		}
+		if names[exceptions[i].Name] {
+			errs = append(errs, fmt.Sprintf("exception '%s': the name is not unique", exceptions[i].Name))
+		}
+		names[exceptions[i].Name] = true
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid exceptions:\n  %s", strings.Join(errs, "\n  "))
+	}
+	return nil
+}
+
+func validateException(exception *armotypes.PostureExceptionPolicy) error {
+	if exception.Name == "" {
+		return fmt.Errorf("missing name")
+	}
+	if exception.PolicyType != PolicyType {
+		return fmt.Errorf("unsupported policyType '%s', expected '%s'", exception.PolicyType, PolicyType)
+	}
+	if len(exception.Actions) == 0 {
+		return fmt.Errorf("missing actions")
+	}
+	for _, action := range exception.Actions {
+		if action != armotypes.AlertOnly && action != armotypes.Disable {
+			return fmt.Errorf("unsupported action '%s', supported actions: '%s'/'%s'", action, armotypes.AlertOnly, armotypes.Disable)
+		}
+	}
+	if len(exception.Resources) == 0 {
+		return fmt.Errorf("missing resources, declare at least one resource")
+	}
+	for i := range exception.Resources {
+		if exception.Resources[i].DesignatorType != armotypes.DesignatorAttributes {
+			return fmt.Errorf("unsupported designatorType '%s', expected '%s'", exception.Resources[i].DesignatorType, armotypes.DesignatorAttributes)
+		}
+		if len(exception.Resources[i].Attributes) == 0 {
+			return fmt.Errorf("missing attributes of resource %d", i)
+		}
+		for _, attribute := range patternAttributes {
+			if err := validatePattern(exception.Resources[i].Attributes[attribute]); err != nil {
+				return fmt.Errorf("resource '%s' attribute: %s", attribute, err.Error())
+			}
+		}
+		if selector := exception.Resources[i].Attributes[LabelSelectorAttribute]; selector != "" {
+			if _, err := labels.Parse(selector); err != nil {
+				return fmt.Errorf("resource '%s' attribute: invalid label selector '%s': %w", LabelSelectorAttribute, selector, err)
+			}
+		}
+	}
+	// no posturePolicies - the exception applies on all of the controls
+	for _, policy := range exception.PosturePolicies {
+		for _, value := range []string{policy.FrameworkName, policy.ControlName, policy.ControlID, policy.RuleName} {
+			if err := validateRegex(value); err != nil {
+				return err
+			}
+		}
+	}
+	if _, err := GetExpiration(exception); err != nil {
+		return err
+	}
+	for _, attribute := range []string{OwnerAttribute, ReasonAttribute} {
+		if value, ok := exception.Attributes[attribute]; ok {
+			if _, ok := value.(string); !ok {
+				return fmt.Errorf("invalid '%s' attribute, expected a string", attribute)
+			}
+		}
+	}
+	return nil
+}
+
+func validateRegex(value string) error {
+	if value == "" {
+		return nil
+	}
+	if _, err := regexp.Compile(value); err != nil {
+		return fmt.Errorf("invalid regular expression '%s': %w", value, err)
+	}
+	return nil
+}
+
+// GetExpiration returns the expiration time of the exception, nil if the exception does not expire
+func GetExpiration(exception *armotypes.PostureExceptionPolicy) (*time.Time, error) {
+	attribute := ExpirationAttribute
+	value, ok := exception.Attributes[attribute]
+	if !ok {
+		attribute = ExpirationDateAttribute
+		if value, ok = exception.Attributes[attribute]; !ok {
+			return nil, nil
+		}
+	}
+	s, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("invalid '%s' attribute, expected an RFC3339 time or a date (2006-01-02)", attribute)
+	}
+	expiration, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		if expiration, err = time.Parse("2006-01-02", s); err != nil {
+			return nil, fmt.Errorf("invalid '%s' attribute '%s', expected an RFC3339 time or a date (2006-01-02)", attribute, s)
+		}
+	}
+	return &expiration, nil
+}
+
+// GetOwner returns the owner of the exception, empty if not set
+func GetOwner(exception *armotypes.PostureExceptionPolicy) string {
+	return getStringAttribute(exception, OwnerAttribute)
+}
+
+// GetReason returns the reason of the exception, empty if not set
+func GetReason(exception *armotypes.PostureExceptionPolicy) string {
+	return getStringAttribute(exception, ReasonAttribute)
+}
+
+func getStringAttribute(exception *armotypes.PostureExceptionPolicy, attribute string) string {
+	if s, ok := exception.Attributes[attribute].(string); ok {
+		return s
+	}
+	return ""
+}
+
+// IsExpired returns true if the exception expired before the time
+func IsExpired(exception *armotypes.PostureExceptionPolicy, now time.Time) bool {
+	expiration, err := GetExpiration(exception)
+	return err == nil && expiration != nil && !now.Before(*expiration)
+}
+
+// RemoveExpired returns the exceptions which did not expire, and the expired exceptions
+func RemoveExpired(exceptions []armotypes.PostureExceptionPolicy, now time.Time) ([]armotypes.PostureExceptionPolicy, []armotypes.PostureExceptionPolicy) {
+	valid := []armotypes.PostureExceptionPolicy{}
+	expired := []armotypes.PostureExceptionPolicy{}
+	for i := range exceptions {
+		if IsExpired(&exceptions[i], now) {
+			expired = append(expired, exceptions[i])
+			continue
+		}
+		valid = append(valid, exceptions[i])
+	}
+	return valid, expired
+}
+
+// AddException appends the exception, the name of the exception must be unique
+func AddException(exceptions []armotypes.PostureExceptionPolicy, exception *armotypes.PostureExceptionPolicy) ([]armotypes.PostureExceptionPolicy, error) {
+	if FindException(exceptions, exception.Name) != nil {
+		return nil, fmt.Errorf("exception '%s' already exists", exception.Name)
+	}
+	return append(exceptions, *exception), nil
+}
+
+// RemoveException removes the exception by name
+func RemoveException(exceptions []armotypes.PostureExceptionPolicy, name string) ([]armotypes.PostureExceptionPolicy, error) {
+	for i := range exceptions {
+		if exceptions[i].Name == name {
+			return append(exceptions[:i:i], exceptions[i+1:]...), nil
+		}
+	}
+	return nil, fmt.Errorf("exception '%s' not found", name)
+}
+
+func FindException(exceptions []armotypes.PostureExceptionPolicy, name string) *armotypes.PostureExceptionPolicy {
+	for i := range exceptions {
+		if exceptions[i].Name == name {
+			return &exceptions[i]
+		}
+	}
+	return nil
+}
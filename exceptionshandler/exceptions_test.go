@@ -0,0 +1,126 @@
+package exceptionshandler
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/armosec/armoapi-go/armotypes"
+	"github.com/stretchr/testify/assert"
+)
+
+func exceptionMock(name string, attributes map[string]string, policies ...armotypes.PosturePolicy) armotypes.PostureExceptionPolicy {
+	return armotypes.PostureExceptionPolicy{
+		PortalBase:      armotypes.PortalBase{Name: name},
+		PolicyType:      PolicyType,
+		Actions:         []armotypes.PostureExceptionPolicyActions{armotypes.AlertOnly},
+		Resources:       []armotypes.PortalDesignator{{DesignatorType: armotypes.DesignatorAttributes, Attributes: attributes}},
+		PosturePolicies: policies,
+	}
+}
+
+func TestValidateExamples(t *testing.T) {
+	files, err := filepath.Glob("../examples/exceptions/*.json")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, files)
+	for _, file := range files {
+		exceptions, err := LoadExceptions(file)
+		assert.NoError(t, err, file)
+		assert.NoError(t, ValidateExceptions(exceptions), file)
+	}
+}
+
+func TestValidateExceptions(t *testing.T) {
+	valid := exceptionMock("valid", map[string]string{"namespace": "kube-.*"}, armotypes.PosturePolicy{ControlID: "C-0016"})
+	assert.NoError(t, ValidateExceptions([]armotypes.PostureExceptionPolicy{valid}))
+
+	tests := []struct {
+		name   string
+		modify func(exception *armotypes.PostureExceptionPolicy)
+	}{
+		{name: "missing name", modify: func(exception *armotypes.PostureExceptionPolicy) { exception.Name = "" }},
+		{name: "policy type", modify: func(exception *armotypes.PostureExceptionPolicy) { exception.PolicyType = "exception" }},
+		{name: "action", modify: func(exception *armotypes.PostureExceptionPolicy) {
+			exception.Actions = []armotypes.PostureExceptionPolicyActions{"ignore"}
+		}},
+		{name: "missing resources", modify: func(exception *armotypes.PostureExceptionPolicy) { exception.Resources = nil }},
+		{name: "empty attributes", modify: func(exception *armotypes.PostureExceptionPolicy) { exception.Resources[0].Attributes = nil }},
+		{name: "resource regex", modify: func(exception *armotypes.PostureExceptionPolicy) {
+			exception.Resources[0].Attributes = map[string]string{"name": "nginx-("}
+		}},
+		{name: "label selector", modify: func(exception *armotypes.PostureExceptionPolicy) {
+			exception.Resources[0].Attributes = map[string]string{LabelSelectorAttribute: "env in (dev"}
+		}},
+		{name: "policy regex", modify: func(exception *armotypes.PostureExceptionPolicy) {
+			exception.PosturePolicies = []armotypes.PosturePolicy{{ControlName: "[a-"}}
+		}},
+		{name: "expiration", modify: func(exception *armotypes.PostureExceptionPolicy) {
+			exception.Attributes = map[string]interface{}{ExpirationAttribute: "next week"}
+		}},
+		{name: "expiration date", modify: func(exception *armotypes.PostureExceptionPolicy) {
+			exception.Attributes = map[string]interface{}{ExpirationDateAttribute: 20220601}
+		}},
+		{name: "owner", modify: func(exception *armotypes.PostureExceptionPolicy) {
+			exception.Attributes = map[string]interface{}{OwnerAttribute: 1}
+		}},
+	}
+	for _, test := range tests {
+		exception := exceptionMock("invalid", map[string]string{"namespace": "kube-.*"}, armotypes.PosturePolicy{ControlID: "C-0016"})
+		test.modify(&exception)
+		assert.Error(t, ValidateExceptions([]armotypes.PostureExceptionPolicy{exception}), test.name)
+	}
+
+	assert.Error(t, ValidateExceptions([]armotypes.PostureExceptionPolicy{valid, valid}), "duplicate names")
+}
+
+func TestRemoveExpired(t *testing.T) {
+	now := time.Date(2022, 1, 10, 0, 0, 0, 0, time.UTC)
+	expired := exceptionMock("expired", map[string]string{"kind": "Pod"})
+	expired.Attributes = map[string]interface{}{ExpirationAttribute: "2022-01-01T00:00:00Z", OwnerAttribute: "platform-team"}
+	active := exceptionMock("active", map[string]string{"kind": "Pod"})
+	active.Attributes = map[string]interface{}{ExpirationAttribute: "2022-02-01"}
+	permanent := exceptionMock("permanent", map[string]string{"kind": "Pod"})
+	expiredDate := exceptionMock("expired-date", map[string]string{"kind": "Pod"})
+	expiredDate.Attributes = map[string]interface{}{ExpirationDateAttribute: "2022-01-09"}
+
+	valid, expiredExceptions := RemoveExpired([]armotypes.PostureExceptionPolicy{expired, active, permanent, expiredDate}, now)
+	if assert.Len(t, expiredExceptions, 2) {
+		assert.Equal(t, "expired", expiredExceptions[0].Name)
+		assert.Equal(t, "platform-team", GetOwner(&expiredExceptions[0]))
+		assert.Equal(t, "expired-date", expiredExceptions[1].Name)
+	}
+	if assert.Len(t, valid, 2) {
+		assert.Equal(t, "active", valid[0].Name)
+		assert.Equal(t, "permanent", valid[1].Name)
+	}
+}
+
+func TestAddRemoveException(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "exceptions.json")
+
+	exceptions, err := LoadExceptions(path)
+	assert.NoError(t, err)
+	assert.Empty(t, exceptions)
+
+	exception := exceptionMock("dev", map[string]string{"namespace": "dev"})
+	exceptions, err = AddException(exceptions, &exception)
+	assert.NoError(t, err)
+	_, err = AddException(exceptions, &exception)
+	assert.Error(t, err)
+	assert.NoError(t, SaveExceptions(path, exceptions))
+
+	exceptions, err = LoadExceptions(path)
+	assert.NoError(t, err)
+	assert.NotNil(t, FindException(exceptions, "dev"))
+
+	_, err = RemoveException(exceptions, "prod")
+	assert.Error(t, err)
+	exceptions, err = RemoveException(exceptions, "dev")
+	assert.NoError(t, err)
+	assert.Empty(t, exceptions)
+
+	assert.NoError(t, os.WriteFile(path, []byte("{"), 0644))
+	_, err = LoadExceptions(path)
+	assert.Error(t, err)
+}
@@ -0,0 +1,153 @@
+package exceptionshandler
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/armosec/armoapi-go/armotypes"
+	"github.com/armosec/k8s-interface/workloadinterface"
+	"github.com/armosec/opa-utils/exceptions"
+	"github.com/armosec/opa-utils/reporthandling/results/v1/resourcesresults"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// LabelSelectorAttribute the attribute of a resource designator holding a label selector (same syntax as kubectl),
+// e.g. "security.exception/ticket" matches the resources with the label, whatever its value
+const LabelSelectorAttribute = "labelSelector"
+
+// the regular expressions characters, a pattern with wildcards and without these characters is a glob
+const regexCharacters = `.+()[]{}|^$\`
+
+// SetExceptions sets the exceptions matching the resource on the failed rules of the result.
+// Used instead of Result.SetExceptions of opa-utils - the resource attributes support globs (e.g. "dev-*") as well as regular expressions,
+// and the labels support wildcard values ("*" - any value) and label selectors
+func SetExceptions(result *resourcesresults.Result, resource workloadinterface.IMetadata, exceptionPolicies []armotypes.PostureExceptionPolicy, clusterName string) {
+	if len(exceptionPolicies) == 0 {
+		return
+	}
+	for i := range result.AssociatedControls {
+		control := &result.AssociatedControls[i]
+		if !control.GetStatus(nil).IsFailed() {
+			continue
+		}
+		controlExceptions := exceptions.ListRuleExceptions(exceptionPolicies, "", control.GetName(), control.GetID(), "")
+		for j := range control.ResourceAssociatedRules {
+			rule := &control.ResourceAssociatedRules[j]
+			if !rule.GetStatus(nil).IsFailed() {
+				continue
+			}
+			rule.Exception = GetResourceExceptions(exceptions.ListRuleExceptions(controlExceptions, "", "", "", rule.GetName()), resource, clusterName)
+		}
+	}
+}
+
+// GetResourceExceptions returns the exceptions with a resource designator matching the resource
+func GetResourceExceptions(exceptionPolicies []armotypes.PostureExceptionPolicy, resource workloadinterface.IMetadata, clusterName string) []armotypes.PostureExceptionPolicy {
+	resourceExceptions := []armotypes.PostureExceptionPolicy{}
+	for i := range exceptionPolicies {
+		for j := range exceptionPolicies[i].Resources {
+			if ResourceMatches(&exceptionPolicies[i].Resources[j], resource, clusterName) {
+				resourceExceptions = append(resourceExceptions, exceptionPolicies[i])
+				break
+			}
+		}
+	}
+	return resourceExceptions
+}
+
+// ResourceMatches returns true if the resource matches all of the attributes of the designator. An empty designator matches no resource
+func ResourceMatches(designator *armotypes.PortalDesignator, resource workloadinterface.IMetadata, clusterName string) bool {
+	cluster, namespace, kind, name, labelAttributes := designator.DigestPortalDesignator()
+	selector := labelAttributes[LabelSelectorAttribute]
+	delete(labelAttributes, LabelSelectorAttribute)
+
+	if cluster == "" && namespace == "" && kind == "" && name == "" && len(labelAttributes) == 0 && selector == "" {
+		return false
+	}
+	if cluster != "" && !matchPattern(cluster, clusterName) {
+		return false
+	}
+	if namespace != "" {
+		resourceNamespace := resource.GetNamespace()
+		if resource.GetKind() == "Namespace" {
+			resourceNamespace = resource.GetName()
+		}
+		if !matchPattern(namespace, resourceNamespace) {
+			return false
+		}
+	}
+	if kind != "" && !matchPattern(kind, resource.GetKind()) {
+		return false
+	}
+	if name != "" && !matchPattern(name, resource.GetName()) {
+		return false
+	}
+	if len(labelAttributes) == 0 && selector == "" {
+		return true
+	}
+
+	resourceLabels := getResourceLabels(resource)
+	for key, value := range labelAttributes {
+		resourceValue, ok := resourceLabels[key]
+		if !ok || !matchLabelValue(value, resourceValue) {
+			return false
+		}
+	}
+	if selector != "" {
+		parsed, err := labels.Parse(selector)
+		if err != nil || !parsed.Matches(labels.Set(resourceLabels)) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchPattern matches the whole value - globs (e.g. "dev-*") are matched as globs, the other patterns as regular expressions
+func matchPattern(pattern, value string) bool {
+	if isGlob(pattern) {
+		matched, _ := path.Match(pattern, value)
+		return matched
+	}
+	matched, _ := regexp.MatchString(fmt.Sprintf("^(?:%s)$", pattern), value)
+	return matched
+}
+
+// matchLabelValue matches a label value exactly, or as a glob when it has wildcards ("*" matches any value)
+func matchLabelValue(pattern, value string) bool {
+	if isGlob(pattern) {
+		matched, _ := path.Match(pattern, value)
+		return matched
+	}
+	return pattern == value
+}
+
+func isGlob(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?") && !strings.ContainsAny(pattern, regexCharacters)
+}
+
+// validatePattern validates a glob or a regular expression
+func validatePattern(pattern string) error {
+	if isGlob(pattern) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid pattern '%s': %w", pattern, err)
+		}
+		return nil
+	}
+	return validateRegex(pattern)
+}
+
+func getResourceLabels(resource workloadinterface.IMetadata) map[string]string {
+	resourceLabels := map[string]string{}
+	if v, ok := workloadinterface.InspectMap(resource.GetObject(), "metadata", "labels"); ok {
+		if m, ok := v.(map[string]interface{}); ok {
+			for key, value := range m {
+				if s, ok := value.(string); ok {
+					resourceLabels[key] = s
+				}
+			}
+		}
+	}
+	return resourceLabels
+}
@@ -0,0 +1,100 @@
+package exceptionshandler
+
+import (
+	"testing"
+
+	"github.com/armosec/armoapi-go/armotypes"
+	"github.com/armosec/opa-utils/objectsenvelopes"
+	"github.com/armosec/opa-utils/reporthandling/apis"
+	"github.com/armosec/opa-utils/reporthandling/results/v1/resourcesresults"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResourceMatches(t *testing.T) {
+	resource := objectsenvelopes.NewObject(map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name":      "nginx-7d4f",
+			"namespace": "team-a",
+			"labels":    map[string]interface{}{"app": "nginx", "env": "dev", "security.exception/ticket": "SEC-123"},
+		},
+	})
+	namespace := objectsenvelopes.NewObject(map[string]interface{}{"apiVersion": "v1", "kind": "Namespace", "metadata": map[string]interface{}{"name": "team-a"}})
+
+	tests := []struct {
+		attributes map[string]string
+		expected   bool
+	}{
+		{attributes: map[string]string{"namespace": "team-a"}, expected: true},
+		{attributes: map[string]string{"namespace": "team"}, expected: false},
+		{attributes: map[string]string{"namespace": "team-*"}, expected: true},
+		{attributes: map[string]string{"namespace": "team-?"}, expected: true},
+		{attributes: map[string]string{"namespace": "prod-*"}, expected: false},
+		{attributes: map[string]string{"namespace": "team-(a|b)"}, expected: true},
+		{attributes: map[string]string{"namespace": ".*"}, expected: true},
+		{attributes: map[string]string{"name": "nginx-.*", "kind": "Deployment"}, expected: true},
+		{attributes: map[string]string{"name": "nginx-*", "kind": "Pod"}, expected: false},
+		{attributes: map[string]string{"cluster": "minikube"}, expected: true},
+		{attributes: map[string]string{"cluster": "prod-*"}, expected: false},
+		{attributes: map[string]string{"app": "nginx"}, expected: true},
+		{attributes: map[string]string{"app": "redis"}, expected: false},
+		{attributes: map[string]string{"app": "ngi*"}, expected: true},
+		{attributes: map[string]string{"security.exception/ticket": "*"}, expected: true},
+		{attributes: map[string]string{"security.exception/approved": "*"}, expected: false},
+		{attributes: map[string]string{LabelSelectorAttribute: "security.exception/ticket"}, expected: true},
+		{attributes: map[string]string{LabelSelectorAttribute: "!security.exception/ticket"}, expected: false},
+		{attributes: map[string]string{LabelSelectorAttribute: "env in (dev,test)", "namespace": "team-*"}, expected: true},
+		{attributes: map[string]string{LabelSelectorAttribute: "env notin (dev,test)"}, expected: false},
+		{attributes: map[string]string{}, expected: false},
+	}
+	for _, test := range tests {
+		designator := armotypes.PortalDesignator{DesignatorType: armotypes.DesignatorAttributes, Attributes: test.attributes}
+		assert.Equal(t, test.expected, ResourceMatches(&designator, resource, "minikube"), test.attributes)
+	}
+
+	// the namespace attribute of a Namespace resource matches its name
+	designator := armotypes.PortalDesignator{DesignatorType: armotypes.DesignatorAttributes, Attributes: map[string]string{"namespace": "team-*"}}
+	assert.True(t, ResourceMatches(&designator, namespace, "minikube"))
+	// a resource without labels matches no label attribute
+	designator = armotypes.PortalDesignator{DesignatorType: armotypes.DesignatorAttributes, Attributes: map[string]string{"security.exception/ticket": "*"}}
+	assert.False(t, ResourceMatches(&designator, namespace, "minikube"))
+}
+
+func TestSetExceptions(t *testing.T) {
+	resource := objectsenvelopes.NewObject(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata":   map[string]interface{}{"name": "nginx", "namespace": "dev", "labels": map[string]interface{}{"security.exception/ticket": "SEC-1"}},
+	})
+	newResult := func() resourcesresults.Result {
+		return resourcesresults.Result{ResourceID: "/v1/dev/Pod/nginx", AssociatedControls: []resourcesresults.ResourceAssociatedControl{
+			{ControlID: "C-0016", Name: "a", ResourceAssociatedRules: []resourcesresults.ResourceAssociatedRule{{Name: "rule-a", Status: apis.StatusFailed}}},
+			{ControlID: "C-0017", Name: "b", ResourceAssociatedRules: []resourcesresults.ResourceAssociatedRule{{Name: "rule-b", Status: apis.StatusFailed}}},
+			{ControlID: "C-0034", Name: "c", ResourceAssociatedRules: []resourcesresults.ResourceAssociatedRule{{Name: "rule-c", Status: apis.StatusPassed}}},
+		}}
+	}
+
+	result := newResult()
+	exception := exceptionMock("ticketed", map[string]string{LabelSelectorAttribute: "security.exception/ticket", "namespace": "d*"}, armotypes.PosturePolicy{ControlID: "C-0016"})
+	SetExceptions(&result, resource, []armotypes.PostureExceptionPolicy{exception}, "minikube")
+	assert.True(t, result.AssociatedControls[0].GetStatus(nil).IsExcluded())
+	assert.True(t, result.AssociatedControls[1].GetStatus(nil).IsFailed())
+	assert.True(t, result.AssociatedControls[2].GetStatus(nil).IsPassed())
+
+	// not matching resource
+	result = newResult()
+	exception = exceptionMock("prod", map[string]string{"namespace": "prod-*"})
+	SetExceptions(&result, resource, []armotypes.PostureExceptionPolicy{exception}, "minikube")
+	assert.True(t, result.AssociatedControls[0].GetStatus(nil).IsFailed())
+	assert.True(t, result.AssociatedControls[1].GetStatus(nil).IsFailed())
+}
+
+func TestValidatePattern(t *testing.T) {
+	for _, pattern := range []string{"", "dev", "dev-*", "*", "team-?", ".*", "nginx-[0-9]+", "team-(a|b)"} {
+		assert.NoError(t, validatePattern(pattern), pattern)
+	}
+	for _, pattern := range []string{"nginx-(", "*-(a"} {
+		assert.Error(t, validatePattern(pattern), pattern)
+	}
+}
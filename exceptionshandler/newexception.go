@@ -0,0 +1,122 @@
+package exceptionshandler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/armosec/armoapi-go/armotypes"
+)
+
+// ExceptionOptions the fields of a new exception
+type ExceptionOptions struct {
+	Name          string
+	Controls      []string // control IDs
+	Frameworks    []string
+	Kind          string
+	Namespace     string
+	ResourceName  string
+	Cluster       string
+	Labels        []string // key=value, the value supports wildcards ("*" - any value)
+	LabelSelector string   // same syntax as kubectl, e.g. "security.exception/ticket" or "env in (dev,test)"
+	Expiration    string   // RFC3339 time, a date (2006-01-02) or a duration from now (e.g. 30d, 12h)
+	Owner         string
+	Reason        string
+}
+
+// HasResourceSelectors returns true if a resource attribute is set, an exception applies on the resources matching all of the attributes
+func (options *ExceptionOptions) HasResourceSelectors() bool {
+	return options.Kind != "" || options.Namespace != "" || options.ResourceName != "" || options.Cluster != "" || len(options.Labels) > 0 || options.LabelSelector != ""
+}
+
+// NewException returns an alertOnly exception of the resources matching the selectors, failing the controls/frameworks of the options
+func NewException(options *ExceptionOptions, now time.Time) (*armotypes.PostureExceptionPolicy, error) {
+	if !options.HasResourceSelectors() {
+		return nil, fmt.Errorf("missing resource selectors, set at least one of kind/namespace/name/cluster/labels/label selector")
+	}
+	attributes := map[string]string{}
+	for key, value := range map[string]string{"kind": options.Kind, "namespace": options.Namespace, "name": options.ResourceName, "cluster": options.Cluster} {
+		if value != "" {
+			attributes[key] = value
+		}
+	}
+	for _, label := range options.Labels {
+		key, value, ok := splitLabel(label)
+		if !ok {
+			return nil, fmt.Errorf("invalid label '%s', expected key=value", label)
+		}
+		attributes[key] = value
+	}
+	if options.LabelSelector != "" {
+		attributes[LabelSelectorAttribute] = options.LabelSelector
+	}
+
+	exception := &armotypes.PostureExceptionPolicy{
+		PortalBase:   armotypes.PortalBase{Name: options.Name},
+		PolicyType:   PolicyType,
+		CreationTime: now.UTC().Format(time.RFC3339),
+		Actions:      []armotypes.PostureExceptionPolicyActions{armotypes.AlertOnly},
+		Resources:    []armotypes.PortalDesignator{{DesignatorType: armotypes.DesignatorAttributes, Attributes: attributes}},
+	}
+	for _, controlID := range options.Controls {
+		exception.PosturePolicies = append(exception.PosturePolicies, armotypes.PosturePolicy{ControlID: controlID})
+	}
+	for _, framework := range options.Frameworks {
+		exception.PosturePolicies = append(exception.PosturePolicies, armotypes.PosturePolicy{FrameworkName: framework})
+	}
+	if options.Expiration != "" {
+		expiration, err := ParseExpiration(options.Expiration, now)
+		if err != nil {
+			return nil, err
+		}
+		if !expiration.After(now) {
+			return nil, fmt.Errorf("the expiration '%s' has passed", options.Expiration)
+		}
+		setAttribute(exception, ExpirationAttribute, expiration.UTC().Format(time.RFC3339))
+	}
+	if options.Owner != "" {
+		setAttribute(exception, OwnerAttribute, options.Owner)
+	}
+	if options.Reason != "" {
+		setAttribute(exception, ReasonAttribute, options.Reason)
+	}
+	if err := validateException(exception); err != nil {
+		return nil, err
+	}
+	return exception, nil
+}
+
+// ParseExpiration parses an RFC3339 time, a date or a duration from now. Durations support days, e.g. 30d
+func ParseExpiration(expiration string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, expiration); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", expiration, now.Location()); err == nil {
+		return t, nil
+	}
+	if strings.HasSuffix(expiration, "d") {
+		if days, err := strconv.Atoi(strings.TrimSuffix(expiration, "d")); err == nil && days > 0 {
+			return now.AddDate(0, 0, days), nil
+		}
+	}
+	if d, err := time.ParseDuration(expiration); err == nil && d > 0 {
+		return now.Add(d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid expiration '%s', expected an RFC3339 time, a date (2006-01-02) or a duration (e.g. 30d, 12h)", expiration)
+}
+
+func setAttribute(exception *armotypes.PostureExceptionPolicy, attribute string, value interface{}) {
+	if exception.Attributes == nil {
+		exception.Attributes = map[string]interface{}{}
+	}
+	exception.Attributes[attribute] = value
+}
+
+func splitLabel(label string) (string, string, bool) {
+	i := strings.Index(label, "=")
+	if i <= 0 {
+		return "", "", false
+	}
+	return label[:i], label[i+1:], true
+}
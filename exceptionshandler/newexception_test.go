@@ -0,0 +1,89 @@
+package exceptionshandler
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/armosec/armoapi-go/armotypes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewException(t *testing.T) {
+	now := time.Date(2022, 1, 10, 12, 0, 0, 0, time.UTC)
+	exception, err := NewException(&ExceptionOptions{
+		Name:       "dev",
+		Controls:   []string{"C-0016", "C-0017"},
+		Kind:       "Deployment",
+		Namespace:  "dev",
+		Labels:     []string{"app=nginx"},
+		Expiration: "30d",
+		Owner:      "platform-team",
+		Reason:     "legacy workload",
+	}, now)
+	assert.NoError(t, err)
+	assert.Equal(t, "dev", exception.Name)
+	assert.Equal(t, PolicyType, exception.PolicyType)
+	assert.Equal(t, []armotypes.PostureExceptionPolicyActions{armotypes.AlertOnly}, exception.Actions)
+	assert.Equal(t, map[string]string{"kind": "Deployment", "namespace": "dev", "app": "nginx"}, exception.Resources[0].Attributes)
+	assert.Equal(t, []armotypes.PosturePolicy{{ControlID: "C-0016"}, {ControlID: "C-0017"}}, exception.PosturePolicies)
+
+	expiration, err := GetExpiration(exception)
+	assert.NoError(t, err)
+	assert.Equal(t, now.AddDate(0, 0, 30), *expiration)
+	assert.Equal(t, "platform-team", GetOwner(exception))
+	assert.Equal(t, "legacy workload", GetReason(exception))
+
+	_, err = NewException(&ExceptionOptions{Name: "dev", Controls: []string{"C-0016"}}, now)
+	assert.Error(t, err, "missing resource selectors")
+	_, err = NewException(&ExceptionOptions{Name: "dev", Labels: []string{"app"}}, now)
+	assert.Error(t, err, "invalid label")
+	_, err = NewException(&ExceptionOptions{Kind: "Pod"}, now)
+	assert.Error(t, err, "missing name")
+	_, err = NewException(&ExceptionOptions{Name: "dev", Kind: "Pod", Expiration: "2022-01-01"}, now)
+	assert.Error(t, err, "expiration passed")
+}
+
+func TestParseExpiration(t *testing.T) {
+	now := time.Date(2022, 1, 10, 12, 0, 0, 0, time.UTC)
+	tests := map[string]time.Time{
+		"2022-02-01T10:00:00Z": time.Date(2022, 2, 1, 10, 0, 0, 0, time.UTC),
+		"2022-02-01":           time.Date(2022, 2, 1, 0, 0, 0, 0, time.UTC),
+		"7d":                   time.Date(2022, 1, 17, 12, 0, 0, 0, time.UTC),
+		"12h":                  time.Date(2022, 1, 11, 0, 0, 0, 0, time.UTC),
+	}
+	for expiration, expected := range tests {
+		parsed, err := ParseExpiration(expiration, now)
+		assert.NoError(t, err, expiration)
+		assert.True(t, expected.Equal(parsed), expiration)
+	}
+	for _, expiration := range []string{"", "0d", "-1h", "next week"} {
+		_, err := ParseExpiration(expiration, now)
+		assert.Error(t, err, expiration)
+	}
+}
+
+func TestPromptMissing(t *testing.T) {
+	options := &ExceptionOptions{}
+	output := &strings.Builder{}
+	// the resource selectors are prompted again when no selector is set
+	input := "dev\nC-0016, C-0017\n\n\n\n\nDeployment\ndev\n\napp=nginx\n30d\nplatform-team\nlegacy workload\n"
+	assert.NoError(t, NewPrompter(strings.NewReader(input), output).PromptMissing(options))
+	assert.Equal(t, &ExceptionOptions{
+		Name:       "dev",
+		Controls:   []string{"C-0016", "C-0017"},
+		Kind:       "Deployment",
+		Namespace:  "dev",
+		Labels:     []string{"app=nginx"},
+		Expiration: "30d",
+		Owner:      "platform-team",
+		Reason:     "legacy workload",
+	}, options)
+	assert.Contains(t, output.String(), "Exception name: ")
+
+	// set fields are not prompted for
+	options = &ExceptionOptions{Name: "dev", Frameworks: []string{"nsa"}, Kind: "Pod", Expiration: "7d", Owner: "platform-team", Reason: "legacy workload"}
+	assert.NoError(t, NewPrompter(strings.NewReader(""), output).PromptMissing(options))
+
+	assert.Error(t, NewPrompter(strings.NewReader(""), output).PromptMissing(&ExceptionOptions{}))
+}
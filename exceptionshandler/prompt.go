@@ -0,0 +1,87 @@
+package exceptionshandler
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Prompter asks for the missing fields of a new exception
+type Prompter struct {
+	reader *bufio.Reader
+	writer io.Writer
+}
+
+func NewPrompter(reader io.Reader, writer io.Writer) *Prompter {
+	return &Prompter{reader: bufio.NewReader(reader), writer: writer}
+}
+
+// PromptMissing asks for the name, the controls, the resource selectors, the expiration, the owner and the reason, when not set
+func (prompter *Prompter) PromptMissing(options *ExceptionOptions) error {
+	var err error
+	for options.Name == "" {
+		if options.Name, err = prompter.ask("Exception name"); err != nil {
+			return err
+		}
+	}
+	if len(options.Controls) == 0 && len(options.Frameworks) == 0 {
+		controls, err := prompter.ask("Control IDs, comma separated (e.g. C-0016,C-0017). Empty for all of the controls")
+		if err != nil {
+			return err
+		}
+		options.Controls = splitList(controls)
+	}
+	for !options.HasResourceSelectors() {
+		fmt.Fprintln(prompter.writer, "Select the resources of the exception, the resources should match all of the selectors (globs e.g. dev-* and regular expressions supported, label values support wildcards)")
+		if options.Kind, err = prompter.ask("Kind (e.g. Deployment)"); err != nil {
+			return err
+		}
+		if options.Namespace, err = prompter.ask("Namespace"); err != nil {
+			return err
+		}
+		if options.ResourceName, err = prompter.ask("Resource name"); err != nil {
+			return err
+		}
+		labels, err := prompter.ask("Labels, comma separated key=value pairs")
+		if err != nil {
+			return err
+		}
+		options.Labels = splitList(labels)
+	}
+	if options.Expiration == "" {
+		if options.Expiration, err = prompter.ask("Expiration (e.g. 30d, 2006-01-02). Empty for no expiration"); err != nil {
+			return err
+		}
+	}
+	if options.Owner == "" {
+		if options.Owner, err = prompter.ask("Owner of the risk acceptance (e.g. team or e-mail)"); err != nil {
+			return err
+		}
+	}
+	if options.Reason == "" {
+		if options.Reason, err = prompter.ask("Reason"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (prompter *Prompter) ask(question string) (string, error) {
+	fmt.Fprintf(prompter.writer, "%s: ", question)
+	answer, err := prompter.reader.ReadString('\n')
+	if err != nil && (err != io.EOF || answer == "") {
+		return "", fmt.Errorf("failed to read the answer: %w", err)
+	}
+	return strings.TrimSpace(answer), nil
+}
+
+func splitList(s string) []string {
+	list := []string{}
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
@@ -0,0 +1,59 @@
+package exceptionshandler
+
+import (
+	"sort"
+
+	"github.com/armosec/armoapi-go/armotypes"
+	"github.com/armosec/k8s-interface/workloadinterface"
+	"github.com/armosec/opa-utils/objectsenvelopes"
+	"github.com/armosec/opa-utils/reporthandling/results/v1/resourcesresults"
+	reporthandlingv2 "github.com/armosec/opa-utils/reporthandling/v2"
+)
+
+// Finding a failed control of a resource
+type Finding struct {
+	ControlID   string
+	ControlName string
+	ResourceID  string
+}
+
+// SuppressedFindings returns the failed controls of the results which would be excluded by the exception, sorted by control and resource.
+// The results should be generated by 'kubescape scan --format json --format-version v2'
+func SuppressedFindings(exception *armotypes.PostureExceptionPolicy, report *reporthandlingv2.PostureReport) []Finding {
+	resources := map[string]workloadinterface.IMetadata{}
+	for i := range report.Resources {
+		if obj, ok := report.Resources[i].Object.(map[string]interface{}); ok {
+			if resource := objectsenvelopes.NewObject(obj); resource != nil {
+				resources[report.Resources[i].ResourceID] = resource
+			}
+		}
+	}
+
+	findings := []Finding{}
+	exceptions := []armotypes.PostureExceptionPolicy{*exception}
+	for i := range report.Results {
+		resource, ok := resources[report.Results[i].ResourceID]
+		if !ok {
+			continue // the exceptions match the attributes of the resource object
+		}
+		for _, control := range report.Results[i].AssociatedControls {
+			if !control.GetStatus(nil).IsFailed() {
+				continue
+			}
+			// the exceptions are set on the rules of the control, the rules of the report are not modified
+			control.ResourceAssociatedRules = append([]resourcesresults.ResourceAssociatedRule{}, control.ResourceAssociatedRules...)
+			result := resourcesresults.Result{ResourceID: report.Results[i].ResourceID, AssociatedControls: []resourcesresults.ResourceAssociatedControl{control}}
+			SetExceptions(&result, resource, exceptions, report.ClusterName)
+			if result.AssociatedControls[0].GetStatus(nil).IsExcluded() {
+				findings = append(findings, Finding{ControlID: control.GetID(), ControlName: control.GetName(), ResourceID: result.ResourceID})
+			}
+		}
+	}
+	sort.Slice(findings, func(i, j int) bool {
+		if findings[i].ControlID != findings[j].ControlID {
+			return findings[i].ControlID < findings[j].ControlID
+		}
+		return findings[i].ResourceID < findings[j].ResourceID
+	})
+	return findings
+}
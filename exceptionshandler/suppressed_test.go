@@ -0,0 +1,71 @@
+package exceptionshandler
+
+import (
+	"testing"
+
+	"github.com/armosec/armoapi-go/armotypes"
+	"github.com/armosec/opa-utils/reporthandling"
+	"github.com/armosec/opa-utils/reporthandling/apis"
+	"github.com/armosec/opa-utils/reporthandling/results/v1/resourcesresults"
+	reporthandlingv2 "github.com/armosec/opa-utils/reporthandling/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func reportMock() *reporthandlingv2.PostureReport {
+	report := &reporthandlingv2.PostureReport{ClusterName: "minikube"}
+	for _, resource := range []struct {
+		namespace, name string
+		labels          map[string]interface{}
+	}{
+		{namespace: "dev", name: "nginx", labels: map[string]interface{}{"app": "nginx"}},
+		{namespace: "prod", name: "nginx", labels: map[string]interface{}{"app": "nginx"}},
+		{namespace: "dev", name: "redis", labels: map[string]interface{}{"app": "redis"}},
+	} {
+		resourceID := "apps/v1/" + resource.namespace + "/Deployment/" + resource.name
+		report.Resources = append(report.Resources, reporthandling.Resource{
+			ResourceID: resourceID,
+			Object: map[string]interface{}{
+				"apiVersion": "apps/v1",
+				"kind":       "Deployment",
+				"metadata":   map[string]interface{}{"name": resource.name, "namespace": resource.namespace, "labels": resource.labels},
+			},
+		})
+		result := resourcesresults.Result{ResourceID: resourceID}
+		for controlID, status := range map[string]apis.ScanningStatus{"C-0016": apis.StatusFailed, "C-0017": apis.StatusFailed, "C-0034": apis.StatusPassed} {
+			result.AssociatedControls = append(result.AssociatedControls, resourcesresults.ResourceAssociatedControl{
+				ControlID:               controlID,
+				Name:                    controlID + " name",
+				ResourceAssociatedRules: []resourcesresults.ResourceAssociatedRule{{Name: "rule", Status: status}},
+			})
+		}
+		report.Results = append(report.Results, result)
+	}
+	return report
+}
+
+func TestSuppressedFindings(t *testing.T) {
+	report := reportMock()
+
+	exception := exceptionMock("dev", map[string]string{"namespace": "dev"}, armotypes.PosturePolicy{ControlID: "C-0016"})
+	assert.Equal(t, []Finding{
+		{ControlID: "C-0016", ControlName: "C-0016 name", ResourceID: "apps/v1/dev/Deployment/nginx"},
+		{ControlID: "C-0016", ControlName: "C-0016 name", ResourceID: "apps/v1/dev/Deployment/redis"},
+	}, SuppressedFindings(&exception, report))
+
+	// labels, all of the controls
+	exception = exceptionMock("nginx", map[string]string{"app": "nginx", "namespace": "prod"})
+	assert.Equal(t, []Finding{
+		{ControlID: "C-0016", ControlName: "C-0016 name", ResourceID: "apps/v1/prod/Deployment/nginx"},
+		{ControlID: "C-0017", ControlName: "C-0017 name", ResourceID: "apps/v1/prod/Deployment/nginx"},
+	}, SuppressedFindings(&exception, report))
+
+	exception = exceptionMock("other-cluster", map[string]string{"cluster": "production"})
+	assert.Empty(t, SuppressedFindings(&exception, report))
+
+	// the report is not modified
+	for i := range report.Results {
+		for _, control := range report.Results[i].AssociatedControls {
+			assert.NotEqual(t, apis.StatusExcluded, control.GetStatus(nil).Status())
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package fixhandler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/armosec/k8s-interface/k8sinterface"
+	"github.com/armosec/k8s-interface/workloadinterface"
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/cautils/logger/helpers"
+	"github.com/pmezard/go-difflib/difflib"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// FixedFile a source file of the fixed resources
+type FixedFile struct {
+	Path     string
+	Original []byte
+	Fixed    []byte
+}
+
+// Write writes the fixed file
+func (fixedFile *FixedFile) Write() error {
+	info, err := os.Stat(fixedFile.Path)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(fixedFile.Path, fixedFile.Fixed, info.Mode())
+}
+
+// Diff returns the unified diff of the fixed file
+func (fixedFile *FixedFile) Diff() (string, error) {
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(fixedFile.Original)),
+		B:        difflib.SplitLines(string(fixedFile.Fixed)),
+		FromFile: fixedFile.Path,
+		ToFile:   fixedFile.Path,
+		Context:  3,
+	})
+}
+
+// FixFiles applies the fixes of the resources loaded from files on their source files, preserving the comments and the sequences indentation
+// of the files. Returns the fixed files, sorted by path. The files are not written. A file which is not fixed - e.g. a helm template, which is not
+// a valid YAML, or changed since the scan - is skipped with a warning
+func FixFiles(fixes []ResourceFix) []FixedFile {
+	filesFixes := map[string]map[string]*ResourceFix{}
+	for i := range fixes {
+		if fixes[i].Source == nil || len(fixes[i].JSONPatch) == 0 {
+			continue
+		}
+		if _, ok := filesFixes[fixes[i].Source.Path]; !ok {
+			filesFixes[fixes[i].Source.Path] = map[string]*ResourceFix{}
+		}
+		filesFixes[fixes[i].Source.Path][fixes[i].ResourceID] = &fixes[i]
+	}
+	paths := make([]string, 0, len(filesFixes))
+	for path := range filesFixes {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	fixedFiles := []FixedFile{}
+	for _, path := range paths {
+		fixedFile, err := fixFile(path, filesFixes[path])
+		if err != nil {
+			logger.L().Warning("failed to fix file", helpers.String("path", path), helpers.Error(err))
+			continue
+		}
+		if !bytes.Equal(fixedFile.Original, fixedFile.Fixed) {
+			fixedFiles = append(fixedFiles, *fixedFile)
+		}
+	}
+	return fixedFiles
+}
+
+func fixFile(path string, fixes map[string]*ResourceFix) (*FixedFile, error) {
+	original, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	fixed := &bytes.Buffer{}
+	readWriter := &kio.ByteReadWriter{Reader: bytes.NewReader(original), Writer: fixed, PreserveSeqIndent: true}
+	nodes, err := readWriter.Read()
+	if err != nil {
+		return nil, err
+	}
+	unmatched := map[string]bool{}
+	for resourceID := range fixes {
+		unmatched[resourceID] = true
+	}
+	for _, node := range nodes {
+		object, err := node.Map()
+		if err != nil {
+			return nil, err
+		}
+		fix, ok := fixes[workloadinterface.NewWorkloadObj(object).GetID()]
+		if !ok {
+			continue
+		}
+		for _, op := range fix.JSONPatch {
+			if err := applyOperation(node.YNode(), &op); err != nil {
+				return nil, fmt.Errorf("failed to apply '%s %s' on '%s': %w", op.Op, op.Path, fix.ResourceID, err)
+			}
+		}
+		delete(unmatched, fix.ResourceID)
+	}
+	for resourceID := range unmatched {
+		// e.g. the name is set by a helm template, or a kustomize name prefix
+		logger.L().Warning("the resource was not found in its file, it is not fixed", helpers.String("resource", resourceID), helpers.String("path", path))
+	}
+	if len(unmatched) == len(fixes) {
+		return &FixedFile{Path: path, Original: original, Fixed: original}, nil
+	}
+	if err := readWriter.Write(nodes); err != nil {
+		return nil, err
+	}
+	return &FixedFile{Path: path, Original: original, Fixed: fixed.Bytes()}, nil
+}
+
+// applyOperation applies a JSON patch operation on a YAML node, the comments of the node are kept
+func applyOperation(node *yaml.Node, op *PatchOperation) error {
+	keys := strings.Split(strings.TrimPrefix(op.Path, "/"), "/")
+	for i := range keys {
+		keys[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(keys[i])
+	}
+	for _, key := range keys[:len(keys)-1] {
+		child, _, err := childNode(node, key)
+		if err != nil {
+			return err
+		}
+		node = child
+	}
+
+	key := keys[len(keys)-1]
+	value := &yaml.Node{}
+	if op.Op != "remove" {
+		if err := value.Encode(op.Value); err != nil {
+			return err
+		}
+	}
+	child, index, err := childNode(node, key)
+	switch {
+	case op.Op == "add" && node.Kind == yaml.MappingNode && err != nil:
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+	case op.Op == "add" && node.Kind == yaml.SequenceNode && key == "-":
+		node.Content = append(node.Content, value)
+	case err != nil:
+		return err
+	case op.Op == "add" && node.Kind == yaml.SequenceNode:
+		node.Content = append(node.Content[:index], append([]*yaml.Node{value}, node.Content[index:]...)...)
+	case op.Op == "add" || op.Op == "replace":
+		value.HeadComment, value.LineComment, value.FootComment = child.HeadComment, child.LineComment, child.FootComment
+		*child = *value
+	case op.Op == "remove" && node.Kind == yaml.MappingNode:
+		node.Content = append(node.Content[:index-1], node.Content[index+1:]...)
+	case op.Op == "remove":
+		node.Content = append(node.Content[:index], node.Content[index+1:]...)
+	default:
+		return fmt.Errorf("unsupported operation '%s'", op.Op)
+	}
+	return nil
+}
+
+// childNode returns the node of the key of a mapping node, or of the index of a sequence node, and its index in the content of the node
+func childNode(node *yaml.Node, key string) (*yaml.Node, int, error) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == key {
+				return node.Content[i+1], i + 1, nil
+			}
+		}
+	case yaml.SequenceNode:
+		if index, err := strconv.Atoi(key); err == nil && index >= 0 && index < len(node.Content) {
+			return node.Content[index], index, nil
+		}
+	}
+	return nil, 0, fmt.Errorf("'%s' not found", key)
+}
+
+// FixCluster patches the resources in the cluster by the JSON patches of the fixes, the resources loaded from files are skipped. The patches
+// address the lists by index, so they are applied only if the resource version did not change since the scan
+func FixCluster(client dynamic.Interface, fixes []ResourceFix) error {
+	k8sinterface.InitializeMapResourcesMock() // initialize the resource map
+	for i := range fixes {
+		if fixes[i].Source != nil || len(fixes[i].JSONPatch) == 0 {
+			continue
+		}
+		resource := workloadinterface.NewWorkloadObj(fixes[i].object)
+		groupVersionResource, err := k8sinterface.GetGroupVersionResource(resource.GetKind())
+		if err != nil {
+			return fmt.Errorf("failed to patch '%s': %w", fixes[i].ResourceID, err)
+		}
+		if resource.GetResourceVersion() == "" {
+			return fmt.Errorf("failed to patch '%s': the resource version of the scanned resource is unknown", fixes[i].ResourceID)
+		}
+		resourceVersionTest := PatchOperation{Op: "test", Path: "/metadata/resourceVersion", Value: resource.GetResourceVersion()}
+		patch, err := json.Marshal(append([]PatchOperation{resourceVersionTest}, fixes[i].JSONPatch...))
+		if err != nil {
+			return err
+		}
+		var resourceClient dynamic.ResourceInterface = client.Resource(groupVersionResource)
+		if resource.GetNamespace() != "" {
+			resourceClient = client.Resource(groupVersionResource).Namespace(resource.GetNamespace())
+		}
+		if _, err := resourceClient.Patch(context.Background(), resource.GetName(), types.JSONPatchType, patch, metav1.PatchOptions{}); err != nil {
+			return fmt.Errorf("failed to patch '%s', rescan if the resource changed since the scan: %w", fixes[i].ResourceID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package fixhandler
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/opa-utils/reporthandling/apis"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+const manifestsMock = `# the web application
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: prod
+spec:
+  template:
+    spec:
+      containers:
+      - name: web # the application
+        image: nginx
+        securityContext:
+          capabilities:
+            add: [NET_ADMIN, NET_BIND_SERVICE, SYS_ADMIN]
+      - name: sidecar
+        image: envoy
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: prod
+spec:
+  type: NodePort
+`
+
+const fixedManifestsMock = `# the web application
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: prod
+spec:
+  template:
+    spec:
+      containers:
+      - name: web # the application
+        image: nginx
+        securityContext:
+          capabilities:
+            add: [NET_BIND_SERVICE]
+      - name: sidecar
+        image: envoy
+        securityContext:
+          readOnlyRootFilesystem: true
+          runAsNonRoot: true
+        resources:
+          limits:
+            cpu: 500m
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: prod
+spec:
+  type: NodePort
+`
+
+func TestFixFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "web.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte(manifestsMock), 0644))
+	fixes := []ResourceFix{{ResourceID: "apps/v1/prod/Deployment/web", Source: &cautils.ResourceSource{Path: path}, JSONPatch: []PatchOperation{
+		{Op: "add", Path: "/spec/template/spec/containers/1/securityContext", Value: map[string]interface{}{"readOnlyRootFilesystem": true}},
+		{Op: "add", Path: "/spec/template/spec/containers/1/securityContext/runAsNonRoot", Value: true},
+		{Op: "add", Path: "/spec/template/spec/containers/1/resources", Value: map[string]interface{}{"limits": map[string]interface{}{"cpu": "500m"}}},
+		{Op: "remove", Path: "/spec/template/spec/containers/0/securityContext/capabilities/add/2"},
+		{Op: "remove", Path: "/spec/template/spec/containers/0/securityContext/capabilities/add/0"},
+	}}}
+
+	fixedFiles := FixFiles(fixes)
+	assert.Len(t, fixedFiles, 1)
+	assert.Equal(t, manifestsMock, string(fixedFiles[0].Original))
+	assert.Equal(t, fixedManifestsMock, string(fixedFiles[0].Fixed))
+
+	// the files are written explicitly
+	data, _ := os.ReadFile(path)
+	assert.Equal(t, manifestsMock, string(data))
+	assert.NoError(t, fixedFiles[0].Write())
+	data, _ = os.ReadFile(path)
+	assert.Equal(t, fixedManifestsMock, string(data))
+
+	diff, err := fixedFiles[0].Diff()
+	assert.NoError(t, err)
+	assert.Contains(t, diff, "-            add: [NET_ADMIN, NET_BIND_SERVICE, SYS_ADMIN]\n+            add: [NET_BIND_SERVICE]\n")
+	assert.Contains(t, diff, "+          runAsNonRoot: true\n")
+
+	// the files which are not fixed are skipped - the file changed since the scan, a resource which is not in the file (e.g. a helm template)
+	fixes[0].JSONPatch = []PatchOperation{{Op: "remove", Path: "/spec/template/spec/volumes"}}
+	assert.Empty(t, FixFiles(fixes))
+	fixes[0].ResourceID = "apps/v1/prod/Deployment/api"
+	assert.Empty(t, FixFiles(fixes))
+}
+
+func TestFixCluster(t *testing.T) {
+	deployment := &unstructured.Unstructured{}
+	assert.NoError(t, deployment.UnmarshalJSON([]byte(deploymentMock)))
+	deployment.SetResourceVersion("2")
+	gvr := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{gvr: "DeploymentList"}, deployment)
+
+	fixes, err := NewResourceFixes(reportMock(t, failedControl("C-0017", apis.StatusFailed, fixPath("spec.template.spec.containers[1].securityContext.readOnlyRootFilesystem", "true"))), nil, nil)
+	assert.NoError(t, err)
+
+	// the patches are applied only on the scanned version of the resource
+	assert.Error(t, FixCluster(client, fixes))
+	unstructured.SetNestedField(fixes[0].object, "1", "metadata", "resourceVersion")
+	assert.Error(t, FixCluster(client, fixes))
+	unstructured.SetNestedField(fixes[0].object, "2", "metadata", "resourceVersion")
+	assert.NoError(t, FixCluster(client, fixes))
+
+	patched, err := client.Resource(gvr).Namespace("prod").Get(context.Background(), "web", metav1.GetOptions{})
+	assert.NoError(t, err)
+	containers, _, _ := unstructured.NestedSlice(patched.Object, "spec", "template", "spec", "containers")
+	assert.Equal(t, map[string]interface{}{"readOnlyRootFilesystem": true}, containers[1].(map[string]interface{})["securityContext"])
+}
@@ -0,0 +1,282 @@
+package fixhandler
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/opa-utils/reporthandling/results/v1/resourcesresults"
+	reporthandlingv2 "github.com/armosec/opa-utils/reporthandling/v2"
+)
+
+// placeholderValue the value of the fix paths which need a value of the user, e.g. the resource limits
+const placeholderValue = "YOUR_VALUE"
+
+// addedCapabilityPath the failed path of an added capability, fixed by removing the capability
+var addedCapabilityPath = regexp.MustCompile(`securityContext\.capabilities\.add\[\d+\]$`)
+
+// mergeKeyName the lists which are merged by the name of the elements in a strategic merge patch
+var mergeKeyName = map[string]bool{"containers": true, "initContainers": true, "ephemeralContainers": true, "volumes": true, "env": true}
+
+// Options the options of generating the fixes
+type Options struct {
+	Values map[string]string // the values of the fix paths which need a value, map[<path without indexes or its suffix, e.g. resources.limits.cpu>]<value>
+}
+
+// PatchOperation a JSON patch (RFC 6902) operation
+type PatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// ResourceFix the fixes of the failed controls of a resource
+type ResourceFix struct {
+	ResourceID          string                  `json:"resourceID"`
+	Source              *cautils.ResourceSource `json:"source,omitempty"` // the file of a resource loaded from files
+	Controls            []string                `json:"controls"`         // the IDs of the fixed controls
+	JSONPatch           []PatchOperation        `json:"jsonPatch"`
+	StrategicMergePatch map[string]interface{}  `json:"strategicMergePatch,omitempty"` // not set when a list of the fixed paths has no merge key
+	Unfixed             []string                `json:"unfixed,omitempty"`             // the fix paths which need a value, set by the options
+	object              map[string]interface{}  // the fixed resource
+}
+
+// NewResourceFixes returns the fixes of the failed resources of the report, sorted by resource ID. The fixes are generated from the fix paths
+// of the failed rules (e.g. add a securityContext, set readOnlyRootFilesystem or the resource limits) and the added capabilities, which are dropped
+func NewResourceFixes(report *reporthandlingv2.PostureReport, sources map[string]cautils.ResourceSource, options *Options) ([]ResourceFix, error) {
+	objects := map[string]map[string]interface{}{}
+	for i := range report.Resources {
+		if object, ok := report.Resources[i].Object.(map[string]interface{}); ok {
+			objects[report.Resources[i].ResourceID] = object
+		}
+	}
+	return newResourceFixes(report.Results, objects, sources, options)
+}
+
+// NewSessionFixes returns the fixes of the failed resources of a scan, same as NewResourceFixes
+func NewSessionFixes(opaSessionObj *cautils.OPASessionObj, options *Options) ([]ResourceFix, error) {
+	objects := map[string]map[string]interface{}{}
+	for resourceID, resource := range opaSessionObj.AllResources {
+		// the objects loaded from files are not necessarily of the JSON types, e.g. []map[string]interface{} lists
+		data, err := json.Marshal(resource.GetObject())
+		if err != nil {
+			return nil, err
+		}
+		object := map[string]interface{}{}
+		if err := json.Unmarshal(data, &object); err != nil {
+			return nil, err
+		}
+		objects[resourceID] = object
+	}
+	results := make([]resourcesresults.Result, 0, len(opaSessionObj.ResourcesResult))
+	for resourceID := range opaSessionObj.ResourcesResult {
+		results = append(results, opaSessionObj.ResourcesResult[resourceID])
+	}
+	return newResourceFixes(results, objects, opaSessionObj.ResourceSource, options)
+}
+
+func newResourceFixes(results []resourcesresults.Result, objects map[string]map[string]interface{}, sources map[string]cautils.ResourceSource, options *Options) ([]ResourceFix, error) {
+	results = append([]resourcesresults.Result{}, results...)
+	sort.Slice(results, func(i, j int) bool { return results[i].ResourceID < results[j].ResourceID })
+
+	fixes := []ResourceFix{}
+	for i := range results {
+		object, ok := objects[results[i].ResourceID]
+		if !ok {
+			continue // the results file was generated without the resources
+		}
+		fix, err := newResourceFix(&results[i], object, options)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fix '%s': %w", results[i].ResourceID, err)
+		}
+		if len(fix.JSONPatch) == 0 && len(fix.Unfixed) == 0 {
+			continue
+		}
+		if source, ok := sources[results[i].ResourceID]; ok {
+			fix.Source = &source
+		}
+		fixes = append(fixes, *fix)
+	}
+	return fixes, nil
+}
+
+func newResourceFix(result *resourcesresults.Result, object map[string]interface{}, options *Options) (*ResourceFix, error) {
+	fix := &ResourceFix{ResourceID: result.ResourceID, Controls: []string{}, JSONPatch: []PatchOperation{}, object: copyValue(object).(map[string]interface{})}
+	touched := [][]pathSegment{}
+	removed := [][]pathSegment{}
+	for _, control := range result.AssociatedControls {
+		if !control.GetStatus(nil).IsFailed() {
+			continue // passed, or excluded by an exception
+		}
+		fixed := false
+		for _, rule := range control.ResourceAssociatedRules {
+			if !rule.GetStatus(nil).IsFailed() {
+				continue
+			}
+			for _, path := range rule.Paths {
+				switch {
+				case path.FixPath.Path != "":
+					segments, err := parsePath(path.FixPath.Path)
+					if err != nil {
+						return nil, err
+					}
+					value, ok := fixValue(segments, path.FixPath.Value, options)
+					if !ok {
+						fix.Unfixed = appendUnique(fix.Unfixed, path.FixPath.Path)
+						continue
+					}
+					op, err := setValue(fix.object, segments, value)
+					if err != nil {
+						return nil, err
+					}
+					fix.JSONPatch = append(fix.JSONPatch, *op)
+					touched = append(touched, segments)
+					fixed = true
+				case addedCapabilityPath.MatchString(path.FailedPath):
+					segments, err := parsePath(path.FailedPath)
+					if err != nil {
+						return nil, err
+					}
+					removed = append(removed, segments)
+					fixed = true
+				}
+			}
+		}
+		if fixed {
+			fix.Controls = appendUnique(fix.Controls, control.ControlID)
+		}
+	}
+
+	// remove the last elements of the lists first, so the indexes of the other elements do not change
+	sort.Slice(removed, func(i, j int) bool { return comparePaths(removed[i], removed[j]) > 0 })
+	for i := range removed {
+		if i > 0 && comparePaths(removed[i], removed[i-1]) == 0 {
+			continue
+		}
+		op, err := removeValue(fix.object, removed[i])
+		if err != nil {
+			return nil, err
+		}
+		fix.JSONPatch = append(fix.JSONPatch, *op)
+		touched = append(touched, removed[i][:len(removed[i])-1])
+	}
+	fix.StrategicMergePatch = strategicMergePatch(fix.object, touched)
+	return fix, nil
+}
+
+// fixValue returns the value of a fix path, the placeholders are replaced by the values of the options
+func fixValue(segments []pathSegment, value string, options *Options) (interface{}, bool) {
+	if value != placeholderValue {
+		return parseValue(value), true
+	}
+	if options == nil {
+		return nil, false
+	}
+	path, match := keysPath(segments), ""
+	for key := range options.Values {
+		if (path == key || strings.HasSuffix(path, "."+key)) && len(key) > len(match) {
+			match = key
+		}
+	}
+	if match == "" {
+		return nil, false
+	}
+	return parseValue(options.Values[match]), true
+}
+
+// strategicMergePatch returns the strategic merge patch of the fixed paths of the object, nil if a list of the paths has no merge key
+func strategicMergePatch(object map[string]interface{}, paths [][]pathSegment) map[string]interface{} {
+	if len(paths) == 0 {
+		return nil
+	}
+	patch := map[string]interface{}{}
+	for _, path := range paths {
+		value, err := getValue(object, path)
+		if err != nil || !addToPatch(patch, object, path, copyValue(value)) {
+			return nil
+		}
+	}
+	return patch
+}
+
+func addToPatch(patch map[string]interface{}, object interface{}, segments []pathSegment, value interface{}) bool {
+	segment := segments[0]
+	if len(segments) == 1 {
+		patch[segment.key] = value
+		return true
+	}
+	child := object.(map[string]interface{})[segment.key]
+	if !segments[1].isIndex {
+		subPatch, ok := patch[segment.key].(map[string]interface{})
+		if !ok {
+			subPatch = map[string]interface{}{}
+			patch[segment.key] = subPatch
+		}
+		return addToPatch(subPatch, child, segments[1:], value)
+	}
+
+	element, ok := child.([]interface{})[segments[1].index].(map[string]interface{})
+	if !ok || !mergeKeyName[segment.key] || len(segments) == 2 {
+		return false
+	}
+	name, ok := element["name"].(string)
+	if !ok {
+		return false
+	}
+	patchList, _ := patch[segment.key].([]interface{})
+	for i := range patchList {
+		if patchElement := patchList[i].(map[string]interface{}); patchElement["name"] == name {
+			return addToPatch(patchElement, element, segments[2:], value)
+		}
+	}
+	patchElement := map[string]interface{}{"name": name}
+	patch[segment.key] = append(patchList, patchElement)
+	return addToPatch(patchElement, element, segments[2:], value)
+}
+
+// comparePaths compares the paths by their segments, the indexes are compared by value
+func comparePaths(a, b []pathSegment) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		switch {
+		case a[i].isIndex && b[i].isIndex && a[i].index != b[i].index:
+			if a[i].index < b[i].index {
+				return -1
+			}
+			return 1
+		case a[i].key != b[i].key:
+			return strings.Compare(a[i].key, b[i].key)
+		}
+	}
+	return len(a) - len(b)
+}
+
+func copyValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		c := make(map[string]interface{}, len(v))
+		for key := range v {
+			c[key] = copyValue(v[key])
+		}
+		return c
+	case []interface{}:
+		c := make([]interface{}, len(v))
+		for i := range v {
+			c[i] = copyValue(v[i])
+		}
+		return c
+	default:
+		return value
+	}
+}
+
+func appendUnique(values []string, value string) []string {
+	for i := range values {
+		if values[i] == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
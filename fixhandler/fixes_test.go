@@ -0,0 +1,123 @@
+package fixhandler
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/armosec/armoapi-go/armotypes"
+	"github.com/armosec/k8s-interface/workloadinterface"
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/opa-utils/reporthandling"
+	"github.com/armosec/opa-utils/reporthandling/apis"
+	"github.com/armosec/opa-utils/reporthandling/results/v1/resourcesresults"
+	reporthandlingv2 "github.com/armosec/opa-utils/reporthandling/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+const deploymentMock = `{
+	"apiVersion": "apps/v1", "kind": "Deployment", "metadata": {"name": "web", "namespace": "prod"},
+	"spec": {"template": {"spec": {"containers": [
+		{"name": "web", "image": "nginx", "securityContext": {"capabilities": {"add": ["NET_ADMIN", "NET_BIND_SERVICE", "SYS_ADMIN"]}}},
+		{"name": "sidecar", "image": "envoy"}
+	]}}}}`
+
+func failedControl(controlID string, status apis.ScanningStatus, paths ...armotypes.PosturePaths) resourcesresults.ResourceAssociatedControl {
+	return resourcesresults.ResourceAssociatedControl{
+		ControlID:               controlID,
+		ResourceAssociatedRules: []resourcesresults.ResourceAssociatedRule{{Name: "rule", Status: status, Paths: paths}},
+	}
+}
+
+func fixPath(path, value string) armotypes.PosturePaths {
+	return armotypes.PosturePaths{FixPath: armotypes.FixPath{Path: path, Value: value}}
+}
+
+func reportMock(t *testing.T, controls ...resourcesresults.ResourceAssociatedControl) *reporthandlingv2.PostureReport {
+	object := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(deploymentMock), &object); err != nil {
+		t.Fatal(err)
+	}
+	resourceID := "apps/v1/prod/Deployment/web"
+	return &reporthandlingv2.PostureReport{
+		Results:   []resourcesresults.Result{{ResourceID: resourceID, AssociatedControls: controls}},
+		Resources: []reporthandling.Resource{{ResourceID: resourceID, Object: object}},
+	}
+}
+
+func TestNewResourceFixes(t *testing.T) {
+	report := reportMock(t,
+		failedControl("C-0017", apis.StatusFailed, fixPath("spec.template.spec.containers[1].securityContext.readOnlyRootFilesystem", "true")),
+		failedControl("C-0013", apis.StatusFailed, fixPath("spec.template.spec.containers[1].securityContext.runAsNonRoot", "true")),
+		failedControl("C-0046", apis.StatusFailed,
+			armotypes.PosturePaths{FailedPath: "spec.template.spec.containers[0].securityContext.capabilities.add[0]"},
+			armotypes.PosturePaths{FailedPath: "spec.template.spec.containers[0].securityContext.capabilities.add[2]"}),
+		failedControl("C-0009", apis.StatusFailed,
+			fixPath("spec.template.spec.containers[1].resources.limits.cpu", placeholderValue),
+			fixPath("spec.template.spec.containers[1].resources.limits.memory", placeholderValue)),
+		failedControl("C-0016", apis.StatusPassed, fixPath("spec.template.spec.containers[1].securityContext.allowPrivilegeEscalation", "false")),
+		failedControl("C-0044", apis.StatusFailed, armotypes.PosturePaths{FailedPath: "spec.type"}),
+	)
+	sources := map[string]cautils.ResourceSource{"apps/v1/prod/Deployment/web": {Path: "web.yaml", Line: 1}}
+
+	fixes, err := NewResourceFixes(report, sources, &Options{Values: map[string]string{"limits.cpu": "500m", "cpu": "1"}})
+	assert.NoError(t, err)
+	assert.Len(t, fixes, 1)
+	fix := fixes[0]
+	assert.Equal(t, "web.yaml", fix.Source.Path)
+	assert.Equal(t, []string{"C-0017", "C-0013", "C-0046", "C-0009"}, fix.Controls)
+	assert.Equal(t, []string{"spec.template.spec.containers[1].resources.limits.memory"}, fix.Unfixed)
+	assert.Equal(t, []PatchOperation{
+		{Op: "add", Path: "/spec/template/spec/containers/1/securityContext", Value: map[string]interface{}{"readOnlyRootFilesystem": true}},
+		{Op: "add", Path: "/spec/template/spec/containers/1/securityContext/runAsNonRoot", Value: true},
+		{Op: "add", Path: "/spec/template/spec/containers/1/resources", Value: map[string]interface{}{"limits": map[string]interface{}{"cpu": "500m"}}},
+		{Op: "remove", Path: "/spec/template/spec/containers/0/securityContext/capabilities/add/2"},
+		{Op: "remove", Path: "/spec/template/spec/containers/0/securityContext/capabilities/add/0"},
+	}, fix.JSONPatch)
+	assert.Equal(t, map[string]interface{}{"spec": map[string]interface{}{"template": map[string]interface{}{"spec": map[string]interface{}{"containers": []interface{}{
+		map[string]interface{}{"name": "sidecar", "securityContext": map[string]interface{}{"readOnlyRootFilesystem": true, "runAsNonRoot": true}, "resources": map[string]interface{}{"limits": map[string]interface{}{"cpu": "500m"}}},
+		map[string]interface{}{"name": "web", "securityContext": map[string]interface{}{"capabilities": map[string]interface{}{"add": []interface{}{"NET_BIND_SERVICE"}}}},
+	}}}}}, fix.StrategicMergePatch)
+
+	// the excluded controls are not fixed
+	report = reportMock(t, resourcesresults.ResourceAssociatedControl{
+		ControlID: "C-0017",
+		ResourceAssociatedRules: []resourcesresults.ResourceAssociatedRule{{Name: "rule", Status: apis.StatusFailed,
+			Paths:     []armotypes.PosturePaths{fixPath("spec.template.spec.containers[1].securityContext.readOnlyRootFilesystem", "true")},
+			Exception: []armotypes.PostureExceptionPolicy{{PortalBase: armotypes.PortalBase{Name: "exception"}}}}},
+	})
+	fixes, err = NewResourceFixes(report, nil, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, fixes)
+}
+
+func TestParsePath(t *testing.T) {
+	segments, err := parsePath("spec.containers[0].args[12]")
+	assert.NoError(t, err)
+	assert.Equal(t, []pathSegment{{key: "spec"}, {key: "containers"}, {index: 0, isIndex: true}, {key: "args"}, {index: 12, isIndex: true}}, segments)
+	assert.Equal(t, "/spec/containers/0/args/12", jsonPointer(segments))
+	assert.Equal(t, "spec.containers.args", keysPath(segments))
+
+	for _, path := range []string{"", "spec..containers", "spec.containers[a]", "spec.containers[0", "[0]"} {
+		_, err := parsePath(path)
+		assert.Error(t, err, path)
+	}
+}
+
+func TestNewSessionFixes(t *testing.T) {
+	opaSessionObj := cautils.NewOPASessionObjMock()
+	resourceID := "apps/v1/prod/Deployment/web"
+	opaSessionObj.AllResources[resourceID] = workloadinterface.NewWorkloadObj(map[string]interface{}{
+		"apiVersion": "apps/v1", "kind": "Deployment", "metadata": map[string]interface{}{"name": "web", "namespace": "prod"},
+		"spec": map[string]interface{}{"template": map[string]interface{}{"spec": map[string]interface{}{"containers": []map[string]interface{}{{"name": "web"}}}}},
+	})
+	opaSessionObj.ResourcesResult[resourceID] = resourcesresults.Result{ResourceID: resourceID, AssociatedControls: []resourcesresults.ResourceAssociatedControl{
+		failedControl("C-0013", apis.StatusFailed, fixPath("spec.template.spec.containers[0].securityContext.runAsNonRoot", "true")),
+	}}
+	opaSessionObj.ResourceSource[resourceID] = cautils.ResourceSource{Path: "web.yaml"}
+
+	fixes, err := NewSessionFixes(opaSessionObj, nil)
+	assert.NoError(t, err)
+	assert.Len(t, fixes, 1)
+	assert.Equal(t, "web.yaml", fixes[0].Source.Path)
+	assert.Equal(t, []PatchOperation{{Op: "add", Path: "/spec/template/spec/containers/0/securityContext", Value: map[string]interface{}{"runAsNonRoot": true}}}, fixes[0].JSONPatch)
+}
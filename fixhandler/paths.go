@@ -0,0 +1,201 @@
+package fixhandler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// pathSegment a segment of a failed/fix path of the rules - a key of an object or an index of a list
+type pathSegment struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// parsePath parses a path of the rules, e.g. spec.template.spec.containers[0].securityContext
+func parsePath(path string) ([]pathSegment, error) {
+	segments := []pathSegment{}
+	for _, part := range strings.Split(path, ".") {
+		key := part
+		if i := strings.Index(part, "["); i >= 0 {
+			key = part[:i]
+		}
+		if key == "" {
+			return nil, fmt.Errorf("invalid path '%s'", path)
+		}
+		segments = append(segments, pathSegment{key: key})
+		for rest := part[len(key):]; rest != ""; {
+			end := strings.Index(rest, "]")
+			if !strings.HasPrefix(rest, "[") || end < 0 {
+				return nil, fmt.Errorf("invalid path '%s'", path)
+			}
+			index, err := strconv.Atoi(rest[1:end])
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("invalid index in path '%s'", path)
+			}
+			segments = append(segments, pathSegment{index: index, isIndex: true})
+			rest = rest[end+1:]
+		}
+	}
+	return segments, nil
+}
+
+// jsonPointer returns the JSON pointer (RFC 6901) of the path segments
+func jsonPointer(segments []pathSegment) string {
+	pointer := ""
+	for _, segment := range segments {
+		if segment.isIndex {
+			pointer += "/" + strconv.Itoa(segment.index)
+		} else {
+			pointer += "/" + strings.NewReplacer("~", "~0", "/", "~1").Replace(segment.key)
+		}
+	}
+	return pointer
+}
+
+// keysPath returns the keys of the path without the indexes, e.g. spec.template.spec.containers.resources.limits.cpu
+func keysPath(segments []pathSegment) string {
+	keys := []string{}
+	for _, segment := range segments {
+		if !segment.isIndex {
+			keys = append(keys, segment.key)
+		}
+	}
+	return strings.Join(keys, ".")
+}
+
+// newValue returns the value of the segments, creating the objects and the lists of the segments, e.g. {"securityContext": {"runAsNonRoot": true}}
+func newValue(segments []pathSegment, value interface{}) interface{} {
+	for i := len(segments) - 1; i >= 0; i-- {
+		if segments[i].isIndex {
+			value = []interface{}{value}
+		} else {
+			value = map[string]interface{}{segments[i].key: value}
+		}
+	}
+	return value
+}
+
+// parseValue converts the value of a fix path to a boolean or a number when possible, e.g. runAsNonRoot: "true"
+func parseValue(value string) interface{} {
+	if b, err := strconv.ParseBool(value); err == nil && strings.ToLower(value) == value {
+		return b
+	}
+	if i, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return i
+	}
+	return value
+}
+
+// setValue sets the value in the object, creating the missing parents. Returns the JSON patch operation of the change
+func setValue(object map[string]interface{}, segments []pathSegment, value interface{}) (*PatchOperation, error) {
+	var current interface{} = object
+	for i, segment := range segments {
+		last := i == len(segments)-1
+		if !segment.isIndex {
+			parent, ok := current.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("'%s' is not an object", jsonPointer(segments[:i]))
+			}
+			child, exists := parent[segment.key]
+			if last || !exists {
+				op := "add"
+				if exists {
+					op = "replace"
+				}
+				parent[segment.key] = newValue(segments[i+1:], value)
+				return &PatchOperation{Op: op, Path: jsonPointer(segments[:i+1]), Value: newValue(segments[i+1:], value)}, nil
+			}
+			current = child
+			continue
+		}
+
+		list, ok := current.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("'%s' is not a list", jsonPointer(segments[:i]))
+		}
+		switch {
+		case segment.index < len(list) && last:
+			list[segment.index] = value
+			return &PatchOperation{Op: "replace", Path: jsonPointer(segments[:i+1]), Value: value}, nil
+		case segment.index < len(list):
+			current = list[segment.index]
+		case segment.index == len(list):
+			// the lists of the objects are set by their parents, append through the parent
+			if err := setList(object, segments[:i], append(list, newValue(segments[i+1:], value))); err != nil {
+				return nil, err
+			}
+			return &PatchOperation{Op: "add", Path: jsonPointer(segments[:i]) + "/-", Value: newValue(segments[i+1:], value)}, nil
+		default:
+			return nil, fmt.Errorf("index %d of '%s' is out of range", segment.index, jsonPointer(segments[:i]))
+		}
+	}
+	return nil, fmt.Errorf("empty path")
+}
+
+// setList replaces the list of the path in the object
+func setList(object map[string]interface{}, segments []pathSegment, list []interface{}) error {
+	parent, err := getValue(object, segments[:len(segments)-1])
+	if err != nil {
+		return err
+	}
+	if segment := segments[len(segments)-1]; segment.isIndex {
+		parent.([]interface{})[segment.index] = list
+	} else {
+		parent.(map[string]interface{})[segment.key] = list
+	}
+	return nil
+}
+
+// removeValue removes the value of the path from the object. Returns the JSON patch operation of the change
+func removeValue(object map[string]interface{}, segments []pathSegment) (*PatchOperation, error) {
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("empty path")
+	}
+	parent, err := getValue(object, segments[:len(segments)-1])
+	if err != nil {
+		return nil, err
+	}
+	segment := segments[len(segments)-1]
+	switch p := parent.(type) {
+	case map[string]interface{}:
+		if _, ok := p[segment.key]; segment.isIndex || !ok {
+			return nil, fmt.Errorf("'%s' not found", jsonPointer(segments))
+		}
+		delete(p, segment.key)
+	case []interface{}:
+		if !segment.isIndex || segment.index >= len(p) {
+			return nil, fmt.Errorf("'%s' not found", jsonPointer(segments))
+		}
+		if err := setList(object, segments[:len(segments)-1], append(p[:segment.index:segment.index], p[segment.index+1:]...)); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("'%s' not found", jsonPointer(segments))
+	}
+	return &PatchOperation{Op: "remove", Path: jsonPointer(segments)}, nil
+}
+
+// getValue returns the value of the path in the object
+func getValue(object map[string]interface{}, segments []pathSegment) (interface{}, error) {
+	var current interface{} = object
+	for i, segment := range segments {
+		switch c := current.(type) {
+		case map[string]interface{}:
+			value, ok := c[segment.key]
+			if segment.isIndex || !ok {
+				return nil, fmt.Errorf("'%s' not found", jsonPointer(segments[:i+1]))
+			}
+			current = value
+		case []interface{}:
+			if !segment.isIndex || segment.index >= len(c) {
+				return nil, fmt.Errorf("'%s' not found", jsonPointer(segments[:i+1]))
+			}
+			current = c[segment.index]
+		default:
+			return nil, fmt.Errorf("'%s' not found", jsonPointer(segments[:i+1]))
+		}
+	}
+	return current, nil
+}
@@ -0,0 +1,61 @@
+package fixhandler
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/cautils/logger/helpers"
+)
+
+// FixPrinter fixes the scanned files in place by the deterministic fixes of the failed controls, and prints the diff of the fixed files
+type FixPrinter struct {
+	writer *os.File
+}
+
+func NewFixPrinter() *FixPrinter {
+	return &FixPrinter{writer: os.Stderr} // the results of the scan may be printed to stdout
+}
+
+func (fixPrinter *FixPrinter) SetWriter(outputFile string) {}
+
+func (fixPrinter *FixPrinter) Score(score float32) {}
+
+func (fixPrinter *FixPrinter) ActionPrint(opaSessionObj *cautils.OPASessionObj) error {
+	fixes, err := NewSessionFixes(opaSessionObj, nil)
+	if err != nil {
+		return fmt.Errorf("failed to fix the scanned files: %w", err)
+	}
+	unfixed := 0
+	for i := range fixes {
+		unfixed += len(fixes[i].Unfixed)
+	}
+
+	// a file which failed is skipped, the other files are fixed
+	fixedFiles := FixFiles(fixes)
+	written := 0
+	errs := []string{}
+	for i := range fixedFiles {
+		diff, err := fixedFiles[i].Diff()
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("failed to diff the fixed file '%s': %s", fixedFiles[i].Path, err.Error()))
+			continue
+		}
+		if err := fixedFiles[i].Write(); err != nil {
+			errs = append(errs, fmt.Sprintf("failed to write the fixed file '%s': %s", fixedFiles[i].Path, err.Error()))
+			continue
+		}
+		fmt.Fprintf(fixPrinter.writer, "\n%s", diff)
+		written++
+	}
+	logger.L().Success("Fixed the scanned files", helpers.Int("files", written))
+	if unfixed > 0 {
+		logger.L().Info(fmt.Sprintf("%d fixes require a value (e.g. the resource limits), use 'kubescape fix' with '--value'", unfixed))
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
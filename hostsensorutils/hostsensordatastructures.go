@@ -0,0 +1,106 @@
+package hostsensorutils
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"sigs.k8s.io/yaml"
+)
+
+// The kinds of the data of the host sensor, evaluated by the node controls
+const (
+	KindKubeletInfo          = "KubeletInfo"
+	KindControlPlaneInfo     = "ControlPlaneInfo"
+	KindContainerRuntimeInfo = "ContainerRuntimeInfo"
+)
+
+// FileInfo a file of the node, the content is sent for the config files only
+type FileInfo struct {
+	Path        string                 `json:"path"`
+	Permissions int                    `json:"permissions"` // the permission bits of the mode, e.g. 420 (0644)
+	Ownership   *FileOwnership         `json:"ownership,omitempty"`
+	Content     []byte                 `json:"content,omitempty"`
+	Config      map[string]interface{} `json:"config,omitempty"` // the parsed content
+}
+
+type FileOwnership struct {
+	UID       int64  `json:"uid"`
+	GID       int64  `json:"gid"`
+	Username  string `json:"username"`
+	Groupname string `json:"groupname"`
+}
+
+// KubeletInfo the files of the kubelet
+type KubeletInfo struct {
+	ServiceFiles   []FileInfo `json:"serviceFiles,omitempty"`
+	ConfigFile     *FileInfo  `json:"configFile,omitempty"`
+	KubeConfigFile *FileInfo  `json:"kubeConfigFile,omitempty"`
+	ClientCAFile   *FileInfo  `json:"clientCAFile,omitempty"`
+}
+
+// ControlPlaneInfo the files of the control plane, on the control plane nodes
+type ControlPlaneInfo struct {
+	ManifestFiles   []FileInfo `json:"manifestFiles,omitempty"` // the static pods of the API server, the controller manager, the scheduler and etcd
+	KubeConfigFiles []FileInfo `json:"kubeConfigFiles,omitempty"`
+	PKIDir          *FileInfo  `json:"PKIDir,omitempty"`
+	PKIFiles        []FileInfo `json:"PKIFiles,omitempty"`
+	EtcdDataDir     *FileInfo  `json:"etcdDataDir,omitempty"`
+}
+
+// ContainerRuntimeInfo the container runtime of the node
+type ContainerRuntimeInfo struct {
+	Name       string    `json:"name"` // containerd, cri-o, docker
+	Version    string    `json:"version,omitempty"`
+	ConfigFile *FileInfo `json:"configFile,omitempty"`
+	Socket     *FileInfo `json:"socket,omitempty"`
+}
+
+// parseContainerRuntimeInfo parses the config file of the container runtime (TOML of containerd and cri-o, JSON of docker),
+// the controls evaluate the parsed config instead of the content
+func parseContainerRuntimeInfo(data []byte) ([]byte, error) {
+	info := ContainerRuntimeInfo{}
+	if err := json.Unmarshal(data, &info); err != nil {
+		return nil, err
+	}
+	if info.ConfigFile != nil && len(info.ConfigFile.Content) != 0 {
+		config := map[string]interface{}{}
+		var err error
+		switch strings.ToLower(filepath.Ext(info.ConfigFile.Path)) {
+		case ".toml", ".conf":
+			_, err = toml.Decode(string(info.ConfigFile.Content), &config)
+		default:
+			err = yaml.Unmarshal(info.ConfigFile.Content, &config)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse '%s': %w", info.ConfigFile.Path, err)
+		}
+		info.ConfigFile.Config = config
+		info.ConfigFile.Content = nil
+	}
+	return json.Marshal(info)
+}
+
+// parseControlPlaneInfo parses the static pod manifests of the control plane, the command lines of the components are evaluated by the
+// controls of the control plane instead of the content
+func parseControlPlaneInfo(data []byte) ([]byte, error) {
+	info := ControlPlaneInfo{}
+	if err := json.Unmarshal(data, &info); err != nil {
+		return nil, err
+	}
+	for i := range info.ManifestFiles {
+		file := &info.ManifestFiles[i]
+		if len(file.Content) == 0 {
+			continue
+		}
+		config := map[string]interface{}{}
+		if err := yaml.Unmarshal(file.Content, &config); err != nil {
+			return nil, fmt.Errorf("failed to parse '%s': %w", file.Path, err)
+		}
+		file.Config = config
+		file.Content = nil
+	}
+	return json.Marshal(info)
+}
@@ -0,0 +1,56 @@
+package hostsensorutils
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseContainerRuntimeInfo(t *testing.T) {
+	containerd := `{"name": "containerd", "configFile": {"path": "/etc/containerd/config.toml", "permissions": 420, "content": "` +
+		toBase64("version = 2\n[plugins.\"io.containerd.grpc.v1.cri\"]\n  enable_selinux = true\n") + `"}, "socket": {"path": "/run/containerd/containerd.sock", "permissions": 432}}`
+	data, err := parseContainerRuntimeInfo([]byte(containerd))
+	if assert.NoError(t, err) {
+		info := ContainerRuntimeInfo{}
+		assert.NoError(t, json.Unmarshal(data, &info))
+		assert.Empty(t, info.ConfigFile.Content, "the content is replaced by the parsed config")
+		assert.Equal(t, map[string]interface{}{"enable_selinux": true}, info.ConfigFile.Config["plugins"].(map[string]interface{})["io.containerd.grpc.v1.cri"])
+		assert.Equal(t, 432, info.Socket.Permissions)
+	}
+
+	docker := `{"name": "docker", "configFile": {"path": "/etc/docker/daemon.json", "permissions": 420, "content": "` + toBase64(`{"icc": false}`) + `"}}`
+	data, err = parseContainerRuntimeInfo([]byte(docker))
+	if assert.NoError(t, err) {
+		assert.JSONEq(t, `{"name": "docker", "configFile": {"path": "/etc/docker/daemon.json", "permissions": 420, "config": {"icc": false}}}`, string(data))
+	}
+
+	// no config file
+	data, err = parseContainerRuntimeInfo([]byte(`{"name": "cri-o"}`))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"name": "cri-o"}`, string(data))
+
+	_, err = parseContainerRuntimeInfo([]byte(`{"name": "containerd", "configFile": {"path": "config.toml", "content": "` + toBase64("version = ") + `"}}`))
+	assert.Error(t, err)
+}
+
+func toBase64(s string) string {
+	b, _ := json.Marshal([]byte(s))
+	return string(b[1 : len(b)-1])
+}
+
+func TestParseControlPlaneInfo(t *testing.T) {
+	manifest := "apiVersion: v1\nkind: Pod\nmetadata:\n  name: kube-apiserver\nspec:\n  containers:\n  - name: kube-apiserver\n    command: [kube-apiserver, --anonymous-auth=false]\n"
+	data, err := parseControlPlaneInfo([]byte(`{"manifestFiles": [{"path": "/etc/kubernetes/manifests/kube-apiserver.yaml", "permissions": 384, "content": "` +
+		toBase64(manifest) + `"}, {"path": "/etc/kubernetes/manifests/etcd.yaml", "permissions": 384}]}`))
+	if assert.NoError(t, err) {
+		info := ControlPlaneInfo{}
+		assert.NoError(t, json.Unmarshal(data, &info))
+		assert.Empty(t, info.ManifestFiles[0].Content, "the content is replaced by the parsed manifest")
+		assert.Equal(t, "Pod", info.ManifestFiles[0].Config["kind"])
+		assert.Nil(t, info.ManifestFiles[1].Config)
+	}
+
+	_, err = parseControlPlaneInfo([]byte(`{"manifestFiles": [{"path": "kube-apiserver.yaml", "content": "` + toBase64("kind: [Pod") + `"}]}`))
+	assert.Error(t, err)
+}
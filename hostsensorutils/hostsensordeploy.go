@@ -1,6 +1,7 @@
 package hostsensorutils
 
 import (
+	"context"
 	_ "embed"
 	"encoding/json"
 	"fmt"
@@ -35,9 +36,13 @@ type HostSensorHandler struct {
 	DaemonSet                     *appsv1.DaemonSet
 	podListLock                   sync.RWMutex
 	gracePeriod                   int64
+	options                       *cautils.HostSensorOptions
+	namespace                     string    // the namespace of the host sensor, set before the DaemonSet is applied
+	ownedNamespace                bool      // the namespace was created by the host sensor, deleted on tear down
+	tearDownOnce                  sync.Once // torn down by the scan or by the interrupted installation, once
 }
 
-func NewHostSensorHandler(k8sObj *k8sinterface.KubernetesApi, hostSensorYAMLFile string) (*HostSensorHandler, error) {
+func NewHostSensorHandler(k8sObj *k8sinterface.KubernetesApi, hostSensorYAMLFile string, options *cautils.HostSensorOptions) (*HostSensorHandler, error) {
 
 	if k8sObj == nil {
 		return nil, fmt.Errorf("nil k8s interface received")
@@ -54,6 +59,10 @@ func NewHostSensorHandler(k8sObj *k8sinterface.KubernetesApi, hostSensorYAMLFile
 		HostSensorPodNames:            map[string]string{},
 		HostSensorUnscheduledPodNames: map[string]string{},
 		gracePeriod:                   int64(15),
+		options:                       options,
+	}
+	if hsh.options == nil {
+		hsh.options = &cautils.HostSensorOptions{}
 	}
 	// Don't deploy on cluster with no nodes. Some cloud providers prevents termination of K8s objects for cluster with no nodes!!!
 	if nodeList, err := k8sObj.KubernetesClient.CoreV1().Nodes().List(k8sObj.Context, metav1.ListOptions{}); err != nil || len(nodeList.Items) == 0 {
@@ -66,7 +75,9 @@ func NewHostSensorHandler(k8sObj *k8sinterface.KubernetesApi, hostSensorYAMLFile
 	return hsh, nil
 }
 
-func (hsh *HostSensorHandler) Init() error {
+// Init installs the host sensor and waits for its pods. When the context is done during the installation (e.g. interrupted), the
+// host sensor is removed and the context error is returned
+func (hsh *HostSensorHandler) Init(ctx context.Context) error {
 	// deploy the YAML
 	// store namespace + port
 	// store pod names
@@ -76,20 +87,31 @@ func (hsh *HostSensorHandler) Init() error {
 	cautils.StartSpinner()
 	defer cautils.StopSpinner()
 
-	if err := hsh.applyYAML(); err != nil {
+	if err := hsh.applyYAML(ctx); err != nil {
+		hsh.TearDown() // the objects applied before the failure
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
 		return fmt.Errorf("failed to apply host sensor YAML, reason: %v", err)
 	}
 	hsh.populatePodNamesToNodeNames()
-	if err := hsh.checkPodForEachNode(); err != nil {
+	if err := hsh.checkPodForEachNode(ctx); err != nil {
+		if ctx.Err() != nil {
+			hsh.TearDown()
+			return ctx.Err()
+		}
 		logger.L().Error("failed to validate host-sensor pods status", helpers.Error(err))
 	}
 	return nil
 }
 
-func (hsh *HostSensorHandler) applyYAML() error {
-	workloads, err := cautils.ReadFile([]byte(hostSensorYAML), cautils.YAML_FILE_FORMAT)
-	if len(err) != 0 {
-		return fmt.Errorf("failed to read YAML files, reason: %v", err)
+func (hsh *HostSensorHandler) applyYAML(ctx context.Context) error {
+	workloads, errs := cautils.ReadFile([]byte(hostSensorYAML), cautils.YAML_FILE_FORMAT)
+	if len(errs) != 0 {
+		return fmt.Errorf("failed to read YAML files, reason: %v", errs)
+	}
+	if err := applyOptions(workloads, hsh.options); err != nil {
+		return err
 	}
 
 	// Get namespace name
@@ -100,9 +122,13 @@ func (hsh *HostSensorHandler) applyYAML() error {
 			break
 		}
 	}
+	hsh.namespace = namespaceName
 
 	// Update workload data before applying
 	for i := range workloads {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
 		w := workloadinterface.NewWorkloadObj(workloads[i].GetObject())
 		if w == nil {
 			return fmt.Errorf("invalid workload: %v", workloads[i].GetObject())
@@ -134,9 +160,22 @@ func (hsh *HostSensorHandler) applyYAML() error {
 		var newWorkload k8sinterface.IWorkload
 		var e error
 
-		if g, err := hsh.k8sObj.GetWorkload(w.GetNamespace(), w.GetKind(), w.GetName()); err == nil && g != nil {
+		g, err := hsh.k8sObj.GetWorkload(w.GetNamespace(), w.GetKind(), w.GetName())
+		installed := err == nil && g != nil
+		switch {
+		case w.GetKind() == "Namespace" && installed:
+			// an existing namespace is not modified, it is deleted on tear down when it was created by a kept host sensor
+			hsh.ownedNamespace = isHostSensorNamespace(g, w)
+			newWorkload = g
+		case w.GetKind() == "Namespace":
+			hsh.ownedNamespace = true
+			newWorkload, e = hsh.k8sObj.CreateWorkload(w)
+		case w.GetKind() == "DaemonSet" && installed && sameSpecHash(g, w):
+			logger.L().Info("Reusing the installed host sensor", helpers.String("namespace", namespaceName))
+			newWorkload = g
+		case installed:
 			newWorkload, e = hsh.k8sObj.UpdateWorkload(w)
-		} else {
+		default:
 			newWorkload, e = hsh.k8sObj.CreateWorkload(w)
 		}
 		if e != nil {
@@ -168,7 +207,7 @@ func (hsh *HostSensorHandler) applyYAML() error {
 	return nil
 }
 
-func (hsh *HostSensorHandler) checkPodForEachNode() error {
+func (hsh *HostSensorHandler) checkPodForEachNode(ctx context.Context) error {
 	deadline := time.Now().Add(time.Second * 100)
 	for {
 		nodesList, err := hsh.k8sObj.KubernetesClient.CoreV1().Nodes().List(hsh.k8sObj.Context, metav1.ListOptions{})
@@ -189,7 +228,11 @@ func (hsh *HostSensorHandler) checkPodForEachNode() error {
 			return fmt.Errorf("host-sensor pods number (%d) differ than nodes number (%d) after deadline exceeded. Kubescape will take data only from the pods below: %v",
 				podsNum, len(nodesList.Items), podsMap)
 		}
-		time.Sleep(100 * time.Millisecond)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(100 * time.Millisecond):
+		}
 	}
 	return nil
 }
@@ -258,19 +301,40 @@ func (hsh *HostSensorHandler) updatePodInListAtomic(eventType watch.EventType, p
 }
 
 func (hsh *HostSensorHandler) tearDownNamespace(namespace string) error {
-
+	if !hsh.ownedNamespace {
+		return nil
+	}
 	if err := hsh.k8sObj.KubernetesClient.CoreV1().Namespaces().Delete(hsh.k8sObj.Context, namespace, metav1.DeleteOptions{GracePeriodSeconds: &hsh.gracePeriod}); err != nil {
 		return fmt.Errorf("failed to delete host-sensor namespace: %v", err)
 	}
 	return nil
 }
 
+// TearDown removes the host sensor, once - the host sensor is left installed for the next scans with the Keep option
 func (hsh *HostSensorHandler) TearDown() error {
-	namespace := hsh.GetNamespace()
-	if err := hsh.k8sObj.KubernetesClient.AppsV1().DaemonSets(hsh.GetNamespace()).Delete(hsh.k8sObj.Context, hsh.DaemonSet.Name, metav1.DeleteOptions{GracePeriodSeconds: &hsh.gracePeriod}); err != nil {
-		return fmt.Errorf("failed to delete host-sensor daemonset: %v", err)
+	var err error
+	hsh.tearDownOnce.Do(func() {
+		err = hsh.tearDown()
+	})
+	return err
+}
+
+func (hsh *HostSensorHandler) tearDown() error {
+	if hsh.options.Keep {
+		if hsh.DaemonSet != nil {
+			logger.L().Info("The host sensor is kept installed for the next scans", helpers.String("namespace", hsh.namespace))
+		}
+		return nil
+	}
+	if hsh.DaemonSet != nil {
+		if err := hsh.k8sObj.KubernetesClient.AppsV1().DaemonSets(hsh.namespace).Delete(hsh.k8sObj.Context, hsh.DaemonSet.Name, metav1.DeleteOptions{GracePeriodSeconds: &hsh.gracePeriod}); err != nil {
+			return fmt.Errorf("failed to delete host-sensor daemonset: %v", err)
+		}
 	}
-	if err := hsh.tearDownNamespace(namespace); err != nil {
+	if hsh.namespace == "" {
+		return nil
+	}
+	if err := hsh.tearDownNamespace(hsh.namespace); err != nil {
 		return fmt.Errorf("failed to delete host-sensor daemonset: %v", err)
 	}
 	// TODO: wait for termination? may take up to 120 seconds!!!
@@ -278,6 +342,19 @@ func (hsh *HostSensorHandler) TearDown() error {
 	return nil
 }
 
+// isHostSensorNamespace returns true for a namespace created by the host sensor, labelled by the host sensor YAML
+func isHostSensorNamespace(installed k8sinterface.IWorkload, applied workloadinterface.IWorkload) bool {
+	label, ok := applied.GetLabel("k8s-app")
+	installedLabel, _ := installed.GetLabel("k8s-app")
+	return ok && installedLabel == label
+}
+
+func sameSpecHash(installed k8sinterface.IWorkload, applied workloadinterface.IWorkload) bool {
+	hash, ok := applied.GetAnnotation(specHashAnnotation)
+	installedHash, _ := installed.GetAnnotation(specHashAnnotation)
+	return ok && installedHash == hash
+}
+
 func (hsh *HostSensorHandler) GetNamespace() string {
 	if hsh.DaemonSet == nil {
 		return ""
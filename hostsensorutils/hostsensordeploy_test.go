@@ -0,0 +1,29 @@
+package hostsensorutils
+
+import (
+	"context"
+	"testing"
+
+	"github.com/armosec/k8s-interface/k8sinterface"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestCheckPodForEachNodeCancelled(t *testing.T) {
+	client := fake.NewSimpleClientset(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node"}})
+	hsh := &HostSensorHandler{
+		k8sObj:                        &k8sinterface.KubernetesApi{KubernetesClient: client, Context: context.Background()},
+		HostSensorPodNames:            map[string]string{},
+		HostSensorUnscheduledPodNames: map[string]string{},
+	}
+
+	// the wait for the pods stops when the installation is interrupted
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Equal(t, context.Canceled, hsh.checkPodForEachNode(ctx))
+
+	hsh.HostSensorPodNames["host-scanner-1"] = "node"
+	assert.NoError(t, hsh.checkPodForEachNode(context.Background()))
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/armosec/kubescape/cautils/logger"
 	"github.com/armosec/kubescape/cautils/logger/helpers"
 	"github.com/armosec/opa-utils/objectsenvelopes/hostsensor"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"sigs.k8s.io/yaml"
 )
 
@@ -72,7 +73,10 @@ func (hsh *HostSensorHandler) sendAllPodsHTTPGETRequest(path, requestKind string
 		go func(podName, path string) {
 			defer wg.Done()
 			resBytes, err := hsh.HTTPGetToPod(podName, path)
-			if err != nil {
+			if k8serrors.IsNotFound(err) {
+				// the data of the newer versions of the host sensor, the controls of the data are skipped
+				logger.L().Warning("the host sensor does not support the request, update the host sensor image", helpers.String("path", path), helpers.String("podName", podName))
+			} else if err != nil {
 				logger.L().Error("failed to get data", helpers.String("path", path), helpers.String("podName", podName), helpers.Error(err))
 			} else {
 				resLock.Lock()
@@ -157,6 +161,43 @@ func (hsh *HostSensorHandler) GetKubeletConfigurations() ([]hostsensor.HostSenso
 	return res, err
 }
 
+// return list of KubeletInfo - the permissions and the ownership of the kubelet config, kubeconfig and client CA files
+func (hsh *HostSensorHandler) GetKubeletInfo() ([]hostsensor.HostSensorDataEnvelope, error) {
+	// loop over pods and port-forward it to each of them
+	return hsh.sendAllPodsHTTPGETRequest("/kubeletInfo", KindKubeletInfo)
+}
+
+// return list of ControlPlaneInfo - the permissions and the ownership of the manifests, the kubeconfigs and the PKI files of the control plane,
+// and the static pods of the manifests
+func (hsh *HostSensorHandler) GetControlPlaneInfo() ([]hostsensor.HostSensorDataEnvelope, error) {
+	// loop over pods and port-forward it to each of them
+	res, err := hsh.sendAllPodsHTTPGETRequest("/controlPlaneInfo", KindControlPlaneInfo)
+	for resIdx := range res {
+		jsonBytes, err := parseControlPlaneInfo(res[resIdx].Data)
+		if err != nil {
+			logger.L().Error("failed to parse the control plane manifests", helpers.String("node", res[resIdx].GetName()), helpers.Error(err))
+			continue
+		}
+		res[resIdx].SetData(jsonBytes)
+	}
+	return res, err
+}
+
+// return list of ContainerRuntimeInfo - the container runtime, its config and the permissions of its socket
+func (hsh *HostSensorHandler) GetContainerRuntimeInfo() ([]hostsensor.HostSensorDataEnvelope, error) {
+	// loop over pods and port-forward it to each of them
+	res, err := hsh.sendAllPodsHTTPGETRequest("/containerRuntimeInfo", KindContainerRuntimeInfo)
+	for resIdx := range res {
+		jsonBytes, err := parseContainerRuntimeInfo(res[resIdx].Data)
+		if err != nil {
+			logger.L().Error("failed to parse the container runtime config", helpers.String("node", res[resIdx].GetName()), helpers.Error(err))
+			continue
+		}
+		res[resIdx].SetData(jsonBytes)
+	}
+	return res, err
+}
+
 func (hsh *HostSensorHandler) CollectResources() ([]hostsensor.HostSensorDataEnvelope, error) {
 	res := make([]hostsensor.HostSensorDataEnvelope, 0)
 	if hsh.DaemonSet == nil {
@@ -207,6 +248,24 @@ func (hsh *HostSensorHandler) CollectResources() ([]hostsensor.HostSensorDataEnv
 		return kcData, err
 	}
 	res = append(res, kcData...)
+	//
+	kcData, err = hsh.GetKubeletInfo()
+	if err != nil {
+		return kcData, err
+	}
+	res = append(res, kcData...)
+	//
+	kcData, err = hsh.GetControlPlaneInfo()
+	if err != nil {
+		return kcData, err
+	}
+	res = append(res, kcData...)
+	//
+	kcData, err = hsh.GetContainerRuntimeInfo()
+	if err != nil {
+		return kcData, err
+	}
+	res = append(res, kcData...)
 	// finish
 
 	logger.L().Debug("Done reading information from host sensor")
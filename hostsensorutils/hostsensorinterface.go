@@ -1,9 +1,13 @@
 package hostsensorutils
 
-import "github.com/armosec/opa-utils/objectsenvelopes/hostsensor"
+import (
+	"context"
+
+	"github.com/armosec/opa-utils/objectsenvelopes/hostsensor"
+)
 
 type IHostSensor interface {
-	Init() error
+	Init(ctx context.Context) error
 	TearDown() error
 	CollectResources() ([]hostsensor.HostSensorDataEnvelope, error)
 	GetNamespace() string
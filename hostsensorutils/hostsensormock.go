@@ -1,13 +1,15 @@
 package hostsensorutils
 
 import (
+	"context"
+
 	"github.com/armosec/opa-utils/objectsenvelopes/hostsensor"
 )
 
 type HostSensorHandlerMock struct {
 }
 
-func (hshm *HostSensorHandlerMock) Init() error {
+func (hshm *HostSensorHandlerMock) Init(ctx context.Context) error {
 	return nil
 }
 
@@ -0,0 +1,113 @@
+package hostsensorutils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/armosec/k8s-interface/workloadinterface"
+	"github.com/armosec/kubescape/cautils"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// specHashAnnotation the hash of the spec of the DaemonSet, an installed host sensor of the same hash is reused
+	specHashAnnotation = "kubescape.io/host-sensor-spec-hash"
+
+	// namespaceNameLabel the label of the name of the namespaces, set by the API server
+	namespaceNameLabel = "kubernetes.io/metadata.name"
+)
+
+// applyOptions overrides the namespace and the pods of the DaemonSet of the host sensor YAML by the options, and sets the hash of the spec
+// of the DaemonSet
+func applyOptions(workloads []workloadinterface.IMetadata, options *cautils.HostSensorOptions) error {
+	for i := range workloads {
+		switch workloads[i].GetKind() {
+		case "Namespace":
+			if options.Namespace != "" {
+				workloads[i].SetName(options.Namespace)
+				if w := workloadinterface.NewWorkloadObj(workloads[i].GetObject()); w != nil {
+					w.SetLabel(namespaceNameLabel, options.Namespace)
+				}
+			}
+		case "DaemonSet":
+			object, err := applyDaemonSetOptions(workloads[i].GetObject(), options)
+			if err != nil {
+				return err
+			}
+			workloads[i].SetObject(object)
+		}
+	}
+	return nil
+}
+
+func applyDaemonSetOptions(object map[string]interface{}, options *cautils.HostSensorOptions) (map[string]interface{}, error) {
+	ds := appsv1.DaemonSet{}
+	if err := convert(object, &ds); err != nil {
+		return nil, fmt.Errorf("failed to Unmarshal YAML of DaemonSet, reason: %v", err)
+	}
+
+	tolerations, err := options.GetTolerations()
+	if err != nil {
+		return nil, err
+	}
+	nodeSelector, err := options.GetNodeSelector()
+	if err != nil {
+		return nil, err
+	}
+	limits, err := options.GetLimits()
+	if err != nil {
+		return nil, err
+	}
+
+	podSpec := &ds.Spec.Template.Spec
+	podSpec.Tolerations = append(podSpec.Tolerations, tolerations...)
+	if len(nodeSelector) != 0 && podSpec.NodeSelector == nil {
+		podSpec.NodeSelector = map[string]string{}
+	}
+	for label, value := range nodeSelector {
+		podSpec.NodeSelector[label] = value
+	}
+	for i := range podSpec.Containers {
+		container := &podSpec.Containers[i]
+		if options.Image != "" {
+			container.Image = options.Image
+		}
+		for name, limit := range limits {
+			if container.Resources.Limits == nil {
+				container.Resources.Limits = corev1.ResourceList{}
+			}
+			container.Resources.Limits[name] = limit
+			// the requests can not exceed the limits
+			if request, ok := container.Resources.Requests[name]; ok && request.Cmp(limit) > 0 {
+				container.Resources.Requests[name] = limit
+			}
+		}
+	}
+
+	spec, err := json.Marshal(ds.Spec)
+	if err != nil {
+		return nil, err
+	}
+	hash := sha256.Sum256(spec)
+	if ds.Annotations == nil {
+		ds.Annotations = map[string]string{}
+	}
+	ds.Annotations[specHashAnnotation] = hex.EncodeToString(hash[:])
+
+	object = map[string]interface{}{}
+	if err := convert(&ds, &object); err != nil {
+		return nil, err
+	}
+	return object, nil
+}
+
+func convert(from, to interface{}) error {
+	b, err := json.Marshal(from)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, to)
+}
@@ -0,0 +1,74 @@
+package hostsensorutils
+
+import (
+	"testing"
+
+	"github.com/armosec/k8s-interface/workloadinterface"
+	"github.com/armosec/kubescape/cautils"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func loadHostSensorYAML(t *testing.T) []workloadinterface.IMetadata {
+	workloads, errs := cautils.ReadFile([]byte(hostSensorYAML), cautils.YAML_FILE_FORMAT)
+	if len(errs) != 0 {
+		t.Fatal(errs)
+	}
+	return workloads
+}
+
+func TestApplyOptions(t *testing.T) {
+	workloads := loadHostSensorYAML(t)
+	options := &cautils.HostSensorOptions{
+		Namespace:    "kubescape",
+		Image:        "registry.example.com/kube-host-sensor:v1.0.0",
+		Tolerations:  []string{"dedicated=gpu:NoSchedule"},
+		NodeSelector: []string{"kubernetes.io/os=linux"},
+		CPULimit:     "100m",
+		MemoryLimit:  "100Mi",
+	}
+	assert.NoError(t, applyOptions(workloads, options))
+
+	var ds appsv1.DaemonSet
+	for i := range workloads {
+		switch workloads[i].GetKind() {
+		case "Namespace":
+			assert.Equal(t, "kubescape", workloads[i].GetName())
+			labels := workloadinterface.NewWorkloadObj(workloads[i].GetObject()).GetLabels()
+			assert.Equal(t, "kubescape", labels[namespaceNameLabel])
+		case "DaemonSet":
+			assert.NoError(t, convert(workloads[i].GetObject(), &ds))
+		}
+	}
+	podSpec := ds.Spec.Template.Spec
+	assert.Equal(t, "registry.example.com/kube-host-sensor:v1.0.0", podSpec.Containers[0].Image)
+	assert.Contains(t, podSpec.Tolerations, corev1.Toleration{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "gpu", Effect: corev1.TaintEffectNoSchedule})
+	assert.Contains(t, podSpec.Tolerations, corev1.Toleration{Key: "node-role.kubernetes.io/master", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule}, "the tolerations of the YAML are kept")
+	assert.Equal(t, map[string]string{"kubernetes.io/os": "linux"}, podSpec.NodeSelector)
+	resources := podSpec.Containers[0].Resources
+	assert.Equal(t, "100m", resources.Limits.Cpu().String())
+	assert.Equal(t, "100Mi", resources.Limits.Memory().String())
+	assert.Equal(t, "100Mi", resources.Requests.Memory().String(), "the requests can not exceed the limits")
+	assert.Equal(t, "1m", resources.Requests.Cpu().String())
+
+	// the hash of the spec changes by the options only
+	hash := ds.Annotations[specHashAnnotation]
+	assert.NotEmpty(t, hash)
+	assert.Equal(t, hash, specHash(t, options))
+	assert.NotEqual(t, hash, specHash(t, &cautils.HostSensorOptions{}))
+
+	assert.Error(t, applyOptions(loadHostSensorYAML(t), &cautils.HostSensorOptions{CPULimit: "a lot"}))
+}
+
+func specHash(t *testing.T, options *cautils.HostSensorOptions) string {
+	workloads := loadHostSensorYAML(t)
+	assert.NoError(t, applyOptions(workloads, options))
+	for i := range workloads {
+		if workloads[i].GetKind() == "DaemonSet" {
+			hash, _ := workloadinterface.NewWorkloadObj(workloads[i].GetObject()).GetAnnotation(specHashAnnotation)
+			return hash
+		}
+	}
+	return ""
+}
@@ -0,0 +1,358 @@
+package networkpolicyanalysis
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/armosec/k8s-interface/workloadinterface"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// The statuses of the traffic of a workload, and of its ports
+const (
+	StatusUncovered  = "uncovered"  // no policy of the direction selects the workload, all of the traffic is allowed
+	StatusOpen       = "open"       // a rule allows any peer
+	StatusRestricted = "restricted" // the rules allow selected peers only
+	StatusDenied     = "denied"     // no rule allows the traffic
+)
+
+// workloadKinds the kinds running pods, the pods of the controllers are covered by the policies of their controllers
+var workloadKinds = map[string]bool{"Pod": true, "Deployment": true, "ReplicaSet": true, "StatefulSet": true, "DaemonSet": true, "Job": true, "CronJob": true}
+
+// anyIPBlocks the CIDRs of all of the addresses
+var anyIPBlocks = map[string]bool{"0.0.0.0/0": true, "::/0": true}
+
+// Coverage the coverage of the workloads and of the namespaces by the network policies
+type Coverage struct {
+	Workloads  []WorkloadCoverage  `json:"workloads"`
+	Namespaces []NamespaceCoverage `json:"namespaces"`
+}
+
+// WorkloadCoverage the network policies selecting the pods of a workload, and the traffic they allow
+type WorkloadCoverage struct {
+	Kind            string   `json:"kind"`
+	Name            string   `json:"name"`
+	Namespace       string   `json:"namespace"`
+	IngressPolicies []string `json:"ingressPolicies"`
+	EgressPolicies  []string `json:"egressPolicies"`
+	Ingress         string   `json:"ingress"` // the most permissive status of the ingress traffic, of any of the ports
+	Egress          string   `json:"egress"`  // the most permissive status of the egress traffic
+	Ports           []Port   `json:"ports"`   // the ingress traffic of the ports of the containers
+}
+
+// Port a port of a container of a workload, and the status of its ingress traffic
+type Port struct {
+	Container string   `json:"container"`
+	Name      string   `json:"name,omitempty"`
+	Port      int32    `json:"port"`
+	Protocol  string   `json:"protocol"`
+	Status    string   `json:"status"`
+	Policies  []string `json:"policies,omitempty"` // the policies allowing the traffic of the port
+}
+
+func (port *Port) String() string {
+	return fmt.Sprintf("%d/%s", port.Port, port.Protocol)
+}
+
+// NamespaceCoverage the coverage of the workloads of a namespace. A namespace allows all of the ingress (egress) traffic when none of its
+// policies restricts the ingress (egress), or when a policy allows any peer on any port to all of its pods
+type NamespaceCoverage struct {
+	Namespace               string   `json:"namespace"`
+	Workloads               int      `json:"workloads"`
+	IngressCovered          int      `json:"ingressCovered"` // the number of the workloads selected by an ingress policy
+	EgressCovered           int      `json:"egressCovered"`
+	AllowAllIngress         bool     `json:"allowAllIngress"`
+	AllowAllEgress          bool     `json:"allowAllEgress"`
+	AllowAllIngressPolicies []string `json:"allowAllIngressPolicies,omitempty"`
+	AllowAllEgressPolicies  []string `json:"allowAllEgressPolicies,omitempty"`
+	DefaultDenyIngress      bool     `json:"defaultDenyIngress"` // a policy selects all of the pods and allows no ingress
+	DefaultDenyEgress       bool     `json:"defaultDenyEgress"`
+}
+
+// policy a network policy, with the rules of each direction
+type policy struct {
+	name     string
+	selector labels.Selector
+	allPods  bool // the empty pod selector
+	ingress  *[]rule
+	egress   *[]rule // nil when the policy does not restrict the direction
+}
+
+// rule the peers and the ports of a rule
+type rule struct {
+	anyPeer bool
+	ports   []networkingv1.NetworkPolicyPort
+}
+
+// Analyze computes the coverage of the workloads of the objects by the NetworkPolicy objects
+func Analyze(objects []workloadinterface.IMetadata) *Coverage {
+	policies := map[string][]policy{}
+	namespaces := map[string]*NamespaceCoverage{}
+	workloads := []workloadinterface.IMetadata{}
+	for _, obj := range objects {
+		switch kind := obj.GetKind(); {
+		case kind == "NetworkPolicy":
+			if p, err := toPolicy(obj); err == nil {
+				policies[obj.GetNamespace()] = append(policies[obj.GetNamespace()], *p)
+			}
+			namespaceCoverage(namespaces, obj.GetNamespace())
+		case kind == "Namespace":
+			namespaceCoverage(namespaces, obj.GetName())
+		case workloadKinds[kind] && !isControlled(obj):
+			workloads = append(workloads, obj)
+		}
+	}
+
+	coverage := &Coverage{Workloads: []WorkloadCoverage{}, Namespaces: []NamespaceCoverage{}}
+	for _, obj := range workloads {
+		workload := workloadCoverage(workloadinterface.NewWorkloadObj(obj.GetObject()), policies[obj.GetNamespace()])
+		coverage.Workloads = append(coverage.Workloads, *workload)
+
+		namespace := namespaceCoverage(namespaces, workload.Namespace)
+		namespace.Workloads++
+		if len(workload.IngressPolicies) > 0 {
+			namespace.IngressCovered++
+		}
+		if len(workload.EgressPolicies) > 0 {
+			namespace.EgressCovered++
+		}
+	}
+	sort.Slice(coverage.Workloads, func(i, j int) bool {
+		a, b := &coverage.Workloads[i], &coverage.Workloads[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Name < b.Name
+	})
+
+	for name, namespace := range namespaces {
+		setNamespaceRules(namespace, policies[name])
+		coverage.Namespaces = append(coverage.Namespaces, *namespace)
+	}
+	sort.Slice(coverage.Namespaces, func(i, j int) bool { return coverage.Namespaces[i].Namespace < coverage.Namespaces[j].Namespace })
+	return coverage
+}
+
+func namespaceCoverage(namespaces map[string]*NamespaceCoverage, name string) *NamespaceCoverage {
+	if _, ok := namespaces[name]; !ok {
+		namespaces[name] = &NamespaceCoverage{Namespace: name}
+	}
+	return namespaces[name]
+}
+
+// setNamespaceRules sets the allow-all and the default deny policies of the namespace
+func setNamespaceRules(namespace *NamespaceCoverage, policies []policy) {
+	restrictsIngress, restrictsEgress := false, false
+	for i := range policies {
+		p := &policies[i]
+		restrictsIngress = restrictsIngress || p.ingress != nil
+		restrictsEgress = restrictsEgress || p.egress != nil
+		if !p.allPods {
+			continue
+		}
+		if p.ingress != nil {
+			if allowsAll(*p.ingress) {
+				namespace.AllowAllIngressPolicies = append(namespace.AllowAllIngressPolicies, p.name)
+			}
+			namespace.DefaultDenyIngress = namespace.DefaultDenyIngress || len(*p.ingress) == 0
+		}
+		if p.egress != nil {
+			if allowsAll(*p.egress) {
+				namespace.AllowAllEgressPolicies = append(namespace.AllowAllEgressPolicies, p.name)
+			}
+			namespace.DefaultDenyEgress = namespace.DefaultDenyEgress || len(*p.egress) == 0
+		}
+	}
+	namespace.AllowAllIngress = !restrictsIngress || len(namespace.AllowAllIngressPolicies) > 0
+	namespace.AllowAllEgress = !restrictsEgress || len(namespace.AllowAllEgressPolicies) > 0
+}
+
+// workloadCoverage returns the coverage of the workload by the policies of its namespace
+func workloadCoverage(workload *workloadinterface.Workload, policies []policy) *WorkloadCoverage {
+	coverage := &WorkloadCoverage{
+		Kind:            workload.GetKind(),
+		Name:            workload.GetName(),
+		Namespace:       workload.GetNamespace(),
+		IngressPolicies: []string{},
+		EgressPolicies:  []string{},
+		Ports:           []Port{},
+	}
+	podLabels := labels.Set(workload.GetPodLabels())
+	ingress, egress := map[string][]rule{}, map[string][]rule{}
+	for i := range policies {
+		if !policies[i].selector.Matches(podLabels) {
+			continue
+		}
+		if policies[i].ingress != nil {
+			coverage.IngressPolicies = append(coverage.IngressPolicies, policies[i].name)
+			ingress[policies[i].name] = *policies[i].ingress
+		}
+		if policies[i].egress != nil {
+			coverage.EgressPolicies = append(coverage.EgressPolicies, policies[i].name)
+			egress[policies[i].name] = *policies[i].egress
+		}
+	}
+	coverage.Ingress, _ = trafficStatus(ingress, nil)
+	coverage.Egress, _ = trafficStatus(egress, nil)
+
+	if containers, err := workload.GetContainers(); err == nil {
+		for i := range containers {
+			for _, containerPort := range containers[i].Ports {
+				port := Port{Container: containers[i].Name, Name: containerPort.Name, Port: containerPort.ContainerPort, Protocol: string(containerPort.Protocol)}
+				if port.Protocol == "" {
+					port.Protocol = string(corev1.ProtocolTCP)
+				}
+				port.Status, port.Policies = trafficStatus(ingress, &port)
+				coverage.Ports = append(coverage.Ports, port)
+			}
+		}
+	}
+	return coverage
+}
+
+// trafficStatus returns the status of the traffic allowed by the rules of the policies to the port, of the traffic to some port when nil,
+// and the policies allowing it
+func trafficStatus(policies map[string][]rule, port *Port) (string, []string) {
+	if len(policies) == 0 {
+		return StatusUncovered, nil
+	}
+	status := StatusDenied
+	allowing := []string{}
+	for name, rules := range policies {
+		allowed := false
+		for i := range rules {
+			if !rules[i].allowsPort(port) {
+				continue
+			}
+			allowed = true
+			if rules[i].anyPeer {
+				status = StatusOpen
+			} else if status == StatusDenied {
+				status = StatusRestricted
+			}
+		}
+		if allowed {
+			allowing = append(allowing, name)
+		}
+	}
+	sort.Strings(allowing)
+	return status, allowing
+}
+
+// allowsPort returns true when the rule allows the port, any rule allows some port when nil
+func (r *rule) allowsPort(port *Port) bool {
+	if len(r.ports) == 0 || port == nil {
+		return true
+	}
+	for _, p := range r.ports {
+		protocol := string(corev1.ProtocolTCP)
+		if p.Protocol != nil {
+			protocol = string(*p.Protocol)
+		}
+		if protocol != port.Protocol {
+			continue
+		}
+		switch {
+		case p.Port == nil:
+			return true
+		case p.Port.Type == intstr.String:
+			if p.Port.StrVal == port.Name {
+				return true
+			}
+		default:
+			end := p.Port.IntVal
+			if p.EndPort != nil {
+				end = *p.EndPort
+			}
+			if port.Port >= p.Port.IntVal && port.Port <= end {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// allowsAll returns true when a rule allows any peer on any port
+func allowsAll(rules []rule) bool {
+	for i := range rules {
+		if rules[i].anyPeer && len(rules[i].ports) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func toPolicy(obj workloadinterface.IMetadata) (*policy, error) {
+	networkPolicy := networkingv1.NetworkPolicy{}
+	b, err := json.Marshal(obj.GetObject())
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &networkPolicy); err != nil {
+		return nil, err
+	}
+	selector, err := metav1.LabelSelectorAsSelector(&networkPolicy.Spec.PodSelector)
+	if err != nil {
+		return nil, err
+	}
+	p := &policy{name: networkPolicy.Name, selector: selector, allPods: selector.Empty()}
+
+	policyTypes := networkPolicy.Spec.PolicyTypes
+	if len(policyTypes) == 0 { // the default types, ingress and egress when the policy has egress rules
+		policyTypes = []networkingv1.PolicyType{networkingv1.PolicyTypeIngress}
+		if len(networkPolicy.Spec.Egress) > 0 {
+			policyTypes = append(policyTypes, networkingv1.PolicyTypeEgress)
+		}
+	}
+	for _, policyType := range policyTypes {
+		rules := []rule{}
+		switch policyType {
+		case networkingv1.PolicyTypeIngress:
+			for _, ingress := range networkPolicy.Spec.Ingress {
+				rules = append(rules, rule{anyPeer: anyPeer(ingress.From), ports: ingress.Ports})
+			}
+			p.ingress = &rules
+		case networkingv1.PolicyTypeEgress:
+			for _, egress := range networkPolicy.Spec.Egress {
+				rules = append(rules, rule{anyPeer: anyPeer(egress.To), ports: egress.Ports})
+			}
+			p.egress = &rules
+		}
+	}
+	return p, nil
+}
+
+// anyPeer returns true for the rules without peers, and for the peers of all of the addresses
+func anyPeer(peers []networkingv1.NetworkPolicyPeer) bool {
+	if len(peers) == 0 {
+		return true
+	}
+	for _, peer := range peers {
+		if peer.IPBlock != nil && anyIPBlocks[peer.IPBlock.CIDR] && len(peer.IPBlock.Except) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// isControlled returns true for the objects created by a controller, except the mirror pods of the static pods (controlled by their node)
+func isControlled(obj workloadinterface.IMetadata) bool {
+	owners, err := workloadinterface.NewWorkloadObj(obj.GetObject()).GetOwnerReferences()
+	if err != nil {
+		return false
+	}
+	for _, owner := range owners {
+		if owner.Controller != nil && *owner.Controller && owner.Kind != "Node" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,134 @@
+package networkpolicyanalysis
+
+import (
+	"testing"
+
+	"github.com/armosec/k8s-interface/workloadinterface"
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/yaml"
+)
+
+const testObjects = `
+- apiVersion: apps/v1
+  kind: Deployment
+  metadata: {name: api, namespace: web}
+  spec:
+    template:
+      metadata: {labels: {app: api}}
+      spec:
+        containers:
+          - name: api
+            ports: [{name: http, containerPort: 8080}, {containerPort: 9090}, {containerPort: 5353, protocol: UDP}]
+- apiVersion: apps/v1
+  kind: Deployment
+  metadata: {name: db, namespace: web}
+  spec:
+    template:
+      metadata: {labels: {app: db}}
+      spec:
+        containers: [{name: db, ports: [{containerPort: 5432}]}]
+- apiVersion: apps/v1
+  kind: ReplicaSet
+  metadata:
+    name: db-1234
+    namespace: web
+    ownerReferences: [{apiVersion: apps/v1, kind: Deployment, name: db, uid: "1", controller: true}]
+  spec:
+    template:
+      metadata: {labels: {app: db}}
+      spec:
+        containers: [{name: db}]
+- apiVersion: v1
+  kind: Pod
+  metadata: {name: debug, namespace: open}
+  spec:
+    containers: [{name: debug, ports: [{containerPort: 80}]}]
+- apiVersion: v1
+  kind: Namespace
+  metadata: {name: empty}
+- apiVersion: networking.k8s.io/v1
+  kind: NetworkPolicy
+  metadata: {name: api-http, namespace: web}
+  spec:
+    podSelector: {matchLabels: {app: api}}
+    ingress:
+      - ports: [{port: http}]
+      - from: [{podSelector: {matchLabels: {app: monitoring}}}]
+        ports: [{port: 9000, endPort: 9100}]
+- apiVersion: networking.k8s.io/v1
+  kind: NetworkPolicy
+  metadata: {name: default-deny, namespace: web}
+  spec:
+    podSelector: {}
+    policyTypes: [Ingress, Egress]
+- apiVersion: networking.k8s.io/v1
+  kind: NetworkPolicy
+  metadata: {name: allow-all, namespace: open}
+  spec:
+    podSelector: {}
+    ingress: [{}]
+    egress: [{to: [{ipBlock: {cidr: 0.0.0.0/0}}]}]
+`
+
+func loadTestObjects(t *testing.T) []workloadinterface.IMetadata {
+	list := []map[string]interface{}{}
+	if err := yaml.Unmarshal([]byte(testObjects), &list); err != nil {
+		t.Fatal(err)
+	}
+	objects := []workloadinterface.IMetadata{}
+	for i := range list {
+		objects = append(objects, workloadinterface.NewWorkloadObj(list[i]))
+	}
+	return objects
+}
+
+func TestAnalyze(t *testing.T) {
+	coverage := Analyze(loadTestObjects(t))
+
+	if assert.Len(t, coverage.Workloads, 3) {
+		debug := coverage.Workloads[0]
+		assert.Equal(t, "debug", debug.Name)
+		assert.Equal(t, StatusOpen, debug.Ingress)
+		assert.Equal(t, StatusOpen, debug.Egress)
+
+		api := coverage.Workloads[1]
+		assert.Equal(t, "api", api.Name)
+		assert.Equal(t, []string{"api-http", "default-deny"}, api.IngressPolicies)
+		assert.Equal(t, []string{"default-deny"}, api.EgressPolicies)
+		assert.Equal(t, StatusOpen, api.Ingress)
+		assert.Equal(t, StatusDenied, api.Egress)
+		assert.Equal(t, []Port{
+			{Container: "api", Name: "http", Port: 8080, Protocol: "TCP", Status: StatusOpen, Policies: []string{"api-http"}},
+			{Container: "api", Port: 9090, Protocol: "TCP", Status: StatusRestricted, Policies: []string{"api-http"}},
+			{Container: "api", Port: 5353, Protocol: "UDP", Status: StatusDenied, Policies: []string{}},
+		}, api.Ports)
+
+		db := coverage.Workloads[2]
+		assert.Equal(t, "db", db.Name)
+		assert.Equal(t, StatusDenied, db.Ports[0].Status)
+	}
+
+	assert.Equal(t, []NamespaceCoverage{
+		{Namespace: "empty", AllowAllIngress: true, AllowAllEgress: true},
+		{Namespace: "open", Workloads: 1, IngressCovered: 1, EgressCovered: 1, AllowAllIngress: true, AllowAllEgress: true, AllowAllIngressPolicies: []string{"allow-all"}, AllowAllEgressPolicies: []string{"allow-all"}},
+		{Namespace: "web", Workloads: 2, IngressCovered: 2, EgressCovered: 2, DefaultDenyIngress: true, DefaultDenyEgress: true},
+	}, coverage.Namespaces)
+}
+
+func TestAnalyzeUncovered(t *testing.T) {
+	objects := []workloadinterface.IMetadata{}
+	for _, obj := range loadTestObjects(t) {
+		if obj.GetKind() != "NetworkPolicy" {
+			objects = append(objects, obj)
+		}
+	}
+	coverage := Analyze(objects)
+	for _, workload := range coverage.Workloads {
+		assert.Equal(t, StatusUncovered, workload.Ingress, workload.Name)
+		assert.Equal(t, StatusUncovered, workload.Egress, workload.Name)
+		assert.Empty(t, workload.IngressPolicies, workload.Name)
+		for _, port := range workload.Ports {
+			assert.Equal(t, StatusUncovered, port.Status, workload.Name)
+		}
+	}
+}
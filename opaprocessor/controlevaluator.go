@@ -0,0 +1,51 @@
+package opaprocessor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/armosec/k8s-interface/k8sinterface"
+	"github.com/armosec/k8s-interface/workloadinterface"
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/opa-utils/reporthandling"
+	"github.com/armosec/opa-utils/reporthandling/results/v1/resourcesresults"
+	"github.com/armosec/opa-utils/resources"
+)
+
+// EvaluateControl evaluates a control on a set of resources which are not pulled from a cluster, e.g. the fixtures of the control tests.
+// Returns the result of the control per resource ID, the resources not matched by the rules of the control have no result
+func EvaluateControl(control *reporthandling.Control, inputResources []workloadinterface.IMetadata, controlsInputs map[string][]string) (map[string]resourcesresults.ResourceAssociatedControl, error) {
+	k8sinterface.InitializeMapResourcesMock() // initialize the resource map
+
+	k8sResources := cautils.K8SResources{}
+	sessionObj := cautils.NewOPASessionObj(nil, &k8sResources)
+	for i := range inputResources {
+		resourceTriplets, err := resourceTriplets(inputResources[i])
+		if err != nil {
+			return nil, fmt.Errorf("resource '%s': %w", inputResources[i].GetID(), err)
+		}
+		k8sResources[resourceTriplets] = append(k8sResources[resourceTriplets], inputResources[i].GetID())
+		sessionObj.AllResources[inputResources[i].GetID()] = inputResources[i]
+	}
+	sessionObj.RegoInputData.PostureControlInputs = controlsInputs
+
+	opap := &OPAProcessor{ctx: context.Background(), OPASessionObj: sessionObj, regoDependenciesData: &resources.RegoDependenciesData{PostureControlInputs: controlsInputs}}
+	return opap.processControl(control)
+}
+
+// resourceTriplets returns the group/version/resource of a resource. The kubescape data objects (e.g. ImageSignature) are not Kubernetes
+// kinds, they are matched by the group/version of their apiVersion and their kind
+func resourceTriplets(resource workloadinterface.IMetadata) (string, error) {
+	if !k8sinterface.IsKindKubernetes(resource.GetKind()) {
+		if apiVersion, ok := resource.GetObject()["apiVersion"].(string); ok && strings.Contains(apiVersion, "/") {
+			groupVersion := strings.SplitN(apiVersion, "/", 2)
+			return k8sinterface.JoinResourceTriplets(groupVersion[0], groupVersion[1], resource.GetKind()), nil
+		}
+	}
+	groupVersionResource, err := k8sinterface.GetGroupVersionResource(resource.GetKind())
+	if err != nil {
+		return "", err
+	}
+	return k8sinterface.JoinResourceTriplets(groupVersionResource.Group, groupVersionResource.Version, groupVersionResource.Resource), nil
+}
@@ -0,0 +1,64 @@
+package opaprocessor
+
+import (
+	"sort"
+
+	"github.com/armosec/k8s-interface/k8sinterface"
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/opa-utils/reporthandling"
+)
+
+// markDataGaps lists the resources of the controls which could not be fetched. The controls which did not fail were evaluated
+// without these resources, their status is set to missing data instead of counting them as passed
+func (opap *OPAProcessor) markDataGaps(policies *cautils.Policies) {
+	if len(opap.DataGaps) == 0 {
+		return
+	}
+	opap.ControlsDataGaps = map[string][]string{}
+	for controlID := range policies.Controls {
+		control := policies.Controls[controlID]
+		if gaps := controlDataGaps(&control, opap.DataGaps); len(gaps) > 0 {
+			opap.ControlsDataGaps[controlID] = gaps
+		}
+	}
+
+	summaryDetails := &opap.Report.SummaryDetails
+	for controlID := range opap.ControlsDataGaps {
+		if controlSummary, ok := summaryDetails.Controls[controlID]; ok && !controlSummary.GetStatus().IsFailed() {
+			controlSummary.Status = cautils.StatusMissingData
+			summaryDetails.Controls[controlID] = controlSummary
+		}
+		for i := range summaryDetails.Frameworks {
+			if controlSummary, ok := summaryDetails.Frameworks[i].Controls[controlID]; ok && !controlSummary.GetStatus().IsFailed() {
+				controlSummary.Status = cautils.StatusMissingData
+				summaryDetails.Frameworks[i].Controls[controlID] = controlSummary
+			}
+		}
+	}
+}
+
+// controlDataGaps returns the sorted resources matched by the rules of the control which could not be fetched
+func controlDataGaps(control *reporthandling.Control, dataGaps map[string]string) []string {
+	gaps := map[string]bool{}
+	for i := range control.Rules {
+		for _, match := range append(append([]reporthandling.RuleMatchObjects{}, control.Rules[i].Match...), control.Rules[i].DynamicMatch...) {
+			for _, group := range match.APIGroups {
+				for _, version := range match.APIVersions {
+					for _, resource := range match.Resources {
+						for _, groupResource := range k8sinterface.ResourceGroupToString(group, version, resource) {
+							if _, ok := dataGaps[groupResource]; ok {
+								gaps[groupResource] = true
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+	l := make([]string, 0, len(gaps))
+	for groupResource := range gaps {
+		l = append(l, groupResource)
+	}
+	sort.Strings(l)
+	return l
+}
@@ -0,0 +1,48 @@
+package opaprocessor
+
+import (
+	"testing"
+
+	"github.com/armosec/k8s-interface/k8sinterface"
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/opa-utils/reporthandling"
+	"github.com/armosec/opa-utils/reporthandling/apis"
+	"github.com/armosec/opa-utils/reporthandling/results/v1/reportsummary"
+	"github.com/stretchr/testify/assert"
+)
+
+func matchControl(controlID string, resources ...string) reporthandling.Control {
+	control := reporthandling.Control{ControlID: controlID}
+	control.Rules = []reporthandling.PolicyRule{{Match: []reporthandling.RuleMatchObjects{{APIGroups: []string{""}, APIVersions: []string{"v1"}, Resources: resources}}}}
+	return control
+}
+
+func TestMarkDataGaps(t *testing.T) {
+	k8sinterface.InitializeMapResourcesMock()
+	sessionObj := cautils.NewOPASessionObj(nil, nil)
+	sessionObj.DataGaps = map[string]string{"/v1/secrets": "forbidden"}
+	sessionObj.Report.SummaryDetails.Controls = reportsummary.ControlSummaries{
+		"C-0012": {ControlID: "C-0012", Status: apis.StatusPassed},
+		"C-0015": {ControlID: "C-0015", Status: apis.StatusFailed},
+		"C-0057": {ControlID: "C-0057", Status: apis.StatusPassed},
+	}
+	sessionObj.Report.SummaryDetails.Frameworks = []reportsummary.FrameworkSummary{
+		{Name: "nsa", Controls: reportsummary.ControlSummaries{"C-0012": {ControlID: "C-0012", Status: apis.StatusPassed}}},
+	}
+	policies := &cautils.Policies{Controls: map[string]reporthandling.Control{
+		"C-0012": matchControl("C-0012", "Secret", "ConfigMap"),
+		"C-0015": matchControl("C-0015", "Secret"),
+		"C-0057": matchControl("C-0057", "Pod"),
+	}}
+
+	opap := NewOPAProcessor(sessionObj, nil)
+	opap.markDataGaps(policies)
+
+	assert.Equal(t, map[string][]string{"C-0012": {"/v1/secrets"}, "C-0015": {"/v1/secrets"}}, sessionObj.ControlsDataGaps)
+	// the passed control evaluated without the secrets is not counted as passed, the failed control stays failed
+	controls := sessionObj.Report.SummaryDetails.Controls
+	assert.Equal(t, cautils.StatusMissingData, controls["C-0012"].Status)
+	assert.Equal(t, apis.StatusFailed, controls["C-0015"].Status)
+	assert.Equal(t, apis.StatusPassed, controls["C-0057"].Status)
+	assert.Equal(t, cautils.StatusMissingData, sessionObj.Report.SummaryDetails.Frameworks[0].Controls["C-0012"].Status)
+}
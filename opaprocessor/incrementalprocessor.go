@@ -0,0 +1,192 @@
+package opaprocessor
+
+import (
+	"sort"
+
+	"github.com/armosec/k8s-interface/k8sinterface"
+	"github.com/armosec/k8s-interface/workloadinterface"
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/cautils/logger/helpers"
+	"github.com/armosec/kubescape/exceptionshandler"
+	"github.com/armosec/opa-utils/reporthandling"
+	"github.com/armosec/opa-utils/reporthandling/apis"
+	"github.com/armosec/opa-utils/reporthandling/results/v1/resourcesresults"
+	"github.com/armosec/opa-utils/resources"
+)
+
+// ControlStatusChange the status of a resource in a control, changed by the re-evaluation of the control
+type ControlStatusChange struct {
+	Control        *reporthandling.Control
+	Resource       workloadinterface.IMetadata
+	PreviousStatus apis.ScanningStatus // empty if the resource was not evaluated by the control, e.g. a created resource
+	Status         apis.ScanningStatus // empty if the resource is not evaluated by the control anymore, e.g. a deleted resource
+}
+
+// IncrementalProcessor re-evaluates only the controls affected by the changed resources of a scanned session
+type IncrementalProcessor struct {
+	opap     *OPAProcessor
+	controls []reporthandling.Control
+	statuses map[string]map[string]apis.ScanningStatus // map[<control ID>]map[<resource ID>]<status>
+}
+
+// NewIncrementalProcessor the session should be the result of a full scan, the statuses of its results are the baseline of the changes
+func NewIncrementalProcessor(sessionObj *cautils.OPASessionObj, regoDependenciesData *resources.RegoDependenciesData) *IncrementalProcessor {
+	opap := NewOPAProcessor(sessionObj, regoDependenciesData)
+
+	policies := ConvertFrameworksToPolicies(sessionObj.Frameworks, cautils.BuildNumber)
+	controls := make([]reporthandling.Control, 0, len(policies.Controls))
+	for _, control := range policies.Controls {
+		controls = append(controls, control)
+	}
+	sort.Slice(controls, func(i, j int) bool { return controls[i].ControlID < controls[j].ControlID })
+
+	statuses := map[string]map[string]apis.ScanningStatus{}
+	for resourceID, result := range sessionObj.ResourcesResult {
+		for i := range result.AssociatedControls {
+			controlID := result.AssociatedControls[i].GetID()
+			if _, ok := statuses[controlID]; !ok {
+				statuses[controlID] = map[string]apis.ScanningStatus{}
+			}
+			statuses[controlID][resourceID] = result.AssociatedControls[i].GetStatus(nil).Status()
+		}
+	}
+
+	return &IncrementalProcessor{
+		opap:     opap,
+		controls: controls,
+		statuses: statuses,
+	}
+}
+
+// Process applies the changes to the resources of the session and re-evaluates the controls matching the changed resources.
+// Returns the resources whose status was changed, sorted by control and resource
+func (processor *IncrementalProcessor) Process(changes []cautils.ResourceChange) []ControlStatusChange {
+	changedResources := processor.applyChanges(changes)
+	if len(changedResources) == 0 {
+		return nil
+	}
+
+	// the deleted resources are not part of the session anymore, they are kept for reporting their fixed controls
+	removed := map[string]workloadinterface.IMetadata{}
+	for i := range changes {
+		if changes[i].Deleted {
+			removed[changes[i].Resource.GetID()] = changes[i].Resource
+		}
+	}
+
+	statusChanges := []ControlStatusChange{}
+	for i := range processor.controls {
+		control := &processor.controls[i]
+		if !controlMatchesResources(control, changedResources) {
+			continue
+		}
+		resourcesAssociatedControl, err := processor.opap.processControl(control)
+		if err != nil {
+			logger.L().Error("failed to evaluate control", helpers.String("control", control.ControlID), helpers.Error(err))
+			continue
+		}
+
+		statuses := map[string]apis.ScanningStatus{}
+		for resourceID, controlResult := range resourcesAssociatedControl {
+			result := resourcesresults.Result{ResourceID: resourceID, AssociatedControls: []resourcesresults.ResourceAssociatedControl{controlResult}}
+			if resource, ok := processor.opap.AllResources[resourceID]; ok {
+				exceptionshandler.SetExceptions(&result, resource, processor.opap.Exceptions, cautils.ClusterName)
+			}
+			statuses[resourceID] = result.AssociatedControls[0].GetStatus(nil).Status()
+		}
+
+		previousStatuses := processor.statuses[control.ControlID]
+		for resourceID, status := range statuses {
+			if previousStatuses[resourceID] != status {
+				statusChanges = append(statusChanges, ControlStatusChange{Control: control, Resource: processor.opap.AllResources[resourceID], PreviousStatus: previousStatuses[resourceID], Status: status})
+			}
+		}
+		for resourceID, previousStatus := range previousStatuses {
+			if _, ok := statuses[resourceID]; ok {
+				continue
+			}
+			resource, ok := processor.opap.AllResources[resourceID]
+			if !ok {
+				resource = removed[resourceID]
+			}
+			statusChanges = append(statusChanges, ControlStatusChange{Control: control, Resource: resource, PreviousStatus: previousStatus})
+		}
+		processor.statuses[control.ControlID] = statuses
+	}
+
+	// resources of a RBAC related objects vector may not be part of the session
+	filtered := statusChanges[:0]
+	for i := range statusChanges {
+		if statusChanges[i].Resource != nil {
+			filtered = append(filtered, statusChanges[i])
+		}
+	}
+	sort.Slice(filtered, func(i, j int) bool {
+		if filtered[i].Control.ControlID != filtered[j].Control.ControlID {
+			return filtered[i].Control.ControlID < filtered[j].Control.ControlID
+		}
+		return filtered[i].Resource.GetID() < filtered[j].Resource.GetID()
+	})
+	return filtered
+}
+
+// applyChanges updates the resources of the session, returns the resource triplets with changed resources.
+// The unchanged resources (same resource version, e.g. the initial list of an informer) are ignored
+func (processor *IncrementalProcessor) applyChanges(changes []cautils.ResourceChange) map[string]bool {
+	k8sResources := *processor.opap.K8SResources
+	allResources := processor.opap.AllResources
+
+	changedResources := map[string]bool{}
+	for i := range changes {
+		resourceID := changes[i].Resource.GetID()
+		ids := k8sResources[changes[i].GroupVersionResource]
+		index := cautils.StringInSlice(ids, resourceID)
+		if changes[i].Deleted {
+			if index == cautils.ValueNotFound {
+				continue
+			}
+			k8sResources[changes[i].GroupVersionResource] = append(ids[:index:index], ids[index+1:]...)
+			delete(allResources, resourceID)
+		} else {
+			if resource, ok := allResources[resourceID]; ok && index != cautils.ValueNotFound && resourceVersion(resource) != "" && resourceVersion(resource) == resourceVersion(changes[i].Resource) {
+				continue
+			}
+			if index == cautils.ValueNotFound {
+				k8sResources[changes[i].GroupVersionResource] = append(ids, resourceID)
+			}
+			allResources[resourceID] = changes[i].Resource
+		}
+		changedResources[changes[i].GroupVersionResource] = true
+	}
+	return changedResources
+}
+
+// controlMatchesResources returns true if a rule of the control is evaluated on one of the resource triplets
+func controlMatchesResources(control *reporthandling.Control, groupResources map[string]bool) bool {
+	for i := range control.Rules {
+		for _, match := range append(append([]reporthandling.RuleMatchObjects{}, control.Rules[i].Match...), control.Rules[i].DynamicMatch...) {
+			for _, group := range match.APIGroups {
+				for _, version := range match.APIVersions {
+					for _, resource := range match.Resources {
+						for _, groupResource := range k8sinterface.ResourceGroupToString(group, version, resource) {
+							if groupResources[groupResource] {
+								return true
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+	return false
+}
+
+func resourceVersion(resource workloadinterface.IMetadata) string {
+	if v, ok := workloadinterface.InspectMap(resource.GetObject(), "metadata", "resourceVersion"); ok {
+		if s, ok := v.(string); ok {
+			return s
+		}
+	}
+	return ""
+}
@@ -0,0 +1,61 @@
+package opaprocessor
+
+import (
+	"testing"
+
+	"github.com/armosec/k8s-interface/workloadinterface"
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/mocks"
+	"github.com/armosec/opa-utils/reporthandling"
+	"github.com/armosec/opa-utils/reporthandling/apis"
+	"github.com/armosec/opa-utils/resources"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIncrementalProcessor(t *testing.T) {
+	deployment := mocks.MockDevelopmentWithHostpath()
+	workloadinterface.SetInMap(deployment.GetObject(), []string{"metadata"}, "resourceVersion", "1")
+
+	k8sResources := cautils.K8SResources{"apps/v1/deployments": []string{deployment.GetID()}, "/v1/services": []string{}}
+	opaSessionObj := cautils.NewOPASessionObjMock()
+	opaSessionObj.Frameworks = []reporthandling.Framework{*mocks.MockFramework_0006_0013()}
+	opaSessionObj.K8SResources = &k8sResources
+	opaSessionObj.AllResources[deployment.GetID()] = deployment
+
+	// full scan - C-0006 (hostPath) fails, C-0013 (non-root) passes
+	opap := NewOPAProcessor(opaSessionObj, resources.NewRegoDependenciesDataMock())
+	opap.Process(ConvertFrameworksToPolicies(opaSessionObj.Frameworks, ""))
+	opap.updateResults()
+
+	processor := NewIncrementalProcessor(opaSessionObj, resources.NewRegoDependenciesDataMock())
+
+	// the initial list of the informers - unchanged resources are ignored
+	unchanged := mocks.MockDevelopmentWithHostpath()
+	workloadinterface.SetInMap(unchanged.GetObject(), []string{"metadata"}, "resourceVersion", "1")
+	assert.Empty(t, processor.Process([]cautils.ResourceChange{{GroupVersionResource: "apps/v1/deployments", Resource: unchanged}}))
+
+	// the hostPath volume is removed - only C-0006 changes
+	fixed := mocks.MockDevelopmentWithHostpath()
+	workloadinterface.SetInMap(fixed.GetObject(), []string{"metadata"}, "resourceVersion", "2")
+	workloadinterface.RemoveFromMap(fixed.GetObject(), "spec", "template", "spec", "volumes")
+	changes := processor.Process([]cautils.ResourceChange{{GroupVersionResource: "apps/v1/deployments", Resource: fixed}})
+	assert.Equal(t, 1, len(changes))
+	assert.Equal(t, "C-0006", changes[0].Control.ControlID)
+	assert.Equal(t, apis.StatusFailed, changes[0].PreviousStatus)
+	assert.Equal(t, apis.StatusPassed, changes[0].Status)
+
+	// a change of a resource which is not evaluated by the controls
+	service := workloadinterface.NewWorkloadObj(map[string]interface{}{"apiVersion": "v1", "kind": "Service", "metadata": map[string]interface{}{"name": "web", "resourceVersion": "3"}})
+	assert.Empty(t, processor.Process([]cautils.ResourceChange{{GroupVersionResource: "/v1/services", Resource: service}}))
+	assert.Equal(t, []string{service.GetID()}, k8sResources["/v1/services"])
+
+	// the deployment is deleted - the resource is not evaluated anymore
+	changes = processor.Process([]cautils.ResourceChange{{GroupVersionResource: "apps/v1/deployments", Resource: fixed, Deleted: true}})
+	assert.Equal(t, 2, len(changes))
+	for i := range changes {
+		assert.Equal(t, apis.StatusPassed, changes[i].PreviousStatus)
+		assert.Equal(t, apis.ScanningStatus(""), changes[i].Status)
+		assert.Equal(t, deployment.GetID(), changes[i].Resource.GetID())
+	}
+	assert.Empty(t, k8sResources["apps/v1/deployments"])
+}
@@ -0,0 +1,189 @@
+package opaprocessor
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/armosec/armoapi-go/armotypes"
+	"github.com/armosec/k8s-interface/workloadinterface"
+	"github.com/armosec/opa-utils/reporthandling/apis"
+	"github.com/armosec/opa-utils/reporthandling/results/v1/resourcesresults"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// controllerTemplate the template of the objects created by a controller, e.g. the Pods of a Deployment
+type controllerTemplate struct {
+	kind string // kind of the created objects
+	path string // path of the template of the created objects in the controller
+}
+
+// controllersTemplates the templates of the controllers, map[<controller kind>]<template>. The objects of other kinds owned by
+// a controller have the spec of the controller, e.g. the ReplicaSets of a Deployment
+var controllersTemplates = map[string]controllerTemplate{
+	"Deployment":            {kind: "Pod", path: "spec.template."},
+	"ReplicaSet":            {kind: "Pod", path: "spec.template."},
+	"StatefulSet":           {kind: "Pod", path: "spec.template."},
+	"DaemonSet":             {kind: "Pod", path: "spec.template."},
+	"Job":                   {kind: "Pod", path: "spec.template."},
+	"ReplicationController": {kind: "Pod", path: "spec.template."},
+	"CronJob":               {kind: "Job", path: "spec.jobTemplate."},
+}
+
+// attributeToOwners attributes the results of the controlled resources (e.g. the Pods of a ReplicaSet) to the top-level controller of
+// their ownerReferences chain (e.g. the Deployment), so a failure of the template is reported once. The failed paths are translated
+// to the template in the controller, and a rule evaluated on both the controller and its resources keeps the result of the controller.
+// The resources whose controller was not scanned keep their results
+func (opap *OPAProcessor) attributeToOwners() {
+	opap.OwnedResources = map[string][]string{}
+	for _, resourceID := range sortedResourceIDs(opap.ResourcesResult) {
+		resource, ok := opap.AllResources[resourceID]
+		if !ok {
+			continue
+		}
+		ownerID, prefix := opap.topLevelOwner(resource)
+		if ownerID == "" {
+			continue
+		}
+		owner, ok := opap.ResourcesResult[ownerID]
+		if !ok {
+			owner = resourcesresults.Result{ResourceID: ownerID}
+		}
+		mergeResults(&owner, opap.ResourcesResult[resourceID].AssociatedControls, prefix)
+		opap.ResourcesResult[ownerID] = owner
+		delete(opap.ResourcesResult, resourceID)
+		opap.OwnedResources[ownerID] = append(opap.OwnedResources[ownerID], resourceID)
+	}
+}
+
+// attributeControlToOwners returns the results of a single control attributed to the top-level controllers, as attributeToOwners
+// attributes the results of the scan. The results are copied, used for the results streamed before the scan is done
+func (opap *OPAProcessor) attributeControlToOwners(results map[string]resourcesresults.ResourceAssociatedControl) map[string]resourcesresults.ResourceAssociatedControl {
+	type ownedResult struct {
+		resourceID, ownerID, prefix string
+	}
+	attributed := make(map[string]resourcesresults.ResourceAssociatedControl, len(results))
+	owned := []ownedResult{}
+	opap.resourcesLock.RLock()
+	for resourceID, result := range results {
+		ownerID, prefix := "", ""
+		if resource, ok := opap.AllResources[resourceID]; ok {
+			ownerID, prefix = opap.topLevelOwner(resource)
+		}
+		if ownerID == "" {
+			result.ResourceAssociatedRules = append([]resourcesresults.ResourceAssociatedRule{}, result.ResourceAssociatedRules...)
+			attributed[resourceID] = result
+			continue
+		}
+		owned = append(owned, ownedResult{resourceID: resourceID, ownerID: ownerID, prefix: prefix})
+	}
+	opap.resourcesLock.RUnlock()
+
+	sort.Slice(owned, func(i, j int) bool { return owned[i].resourceID < owned[j].resourceID })
+	for i := range owned {
+		owner := resourcesresults.Result{ResourceID: owned[i].ownerID}
+		if result, ok := attributed[owned[i].ownerID]; ok {
+			owner.AssociatedControls = []resourcesresults.ResourceAssociatedControl{result}
+		}
+		mergeResults(&owner, []resourcesresults.ResourceAssociatedControl{results[owned[i].resourceID]}, owned[i].prefix)
+		attributed[owned[i].ownerID] = owner.AssociatedControls[0]
+	}
+	return attributed
+}
+
+// topLevelOwner returns the ID of the top-level scanned controller of the resource and the prefix of the paths of the resource in the
+// controller, an empty ID when the resource has no scanned controller
+func (opap *OPAProcessor) topLevelOwner(resource workloadinterface.IMetadata) (string, string) {
+	ownerID, prefix := "", ""
+	visited := map[string]bool{}
+	for {
+		controller, ok := controllerOf(resource)
+		if !ok {
+			break
+		}
+		id := controllerID(resource.GetNamespace(), controller)
+		owner, ok := opap.AllResources[id]
+		if !ok || visited[id] {
+			break
+		}
+		visited[id] = true
+		if template := controllersTemplates[owner.GetKind()]; template.kind == resource.GetKind() {
+			prefix = template.path + prefix
+		}
+		ownerID, resource = id, owner
+	}
+	return ownerID, prefix
+}
+
+// controllerOf returns the owner reference of the controller of the resource
+func controllerOf(resource workloadinterface.IMetadata) (metav1.OwnerReference, bool) {
+	owners, err := workloadinterface.NewWorkloadObj(resource.GetObject()).GetOwnerReferences()
+	if err != nil {
+		return metav1.OwnerReference{}, false
+	}
+	for _, owner := range owners {
+		if _, ok := controllersTemplates[owner.Kind]; ok && owner.Controller != nil && *owner.Controller {
+			return owner, true
+		}
+	}
+	return metav1.OwnerReference{}, false
+}
+
+// controllerID returns the resource ID of the controller, <group>/<version>/<namespace>/<kind>/<name>
+func controllerID(namespace string, controller metav1.OwnerReference) string {
+	group, version := "", controller.APIVersion
+	if i := strings.LastIndex(controller.APIVersion, "/"); i >= 0 {
+		group, version = controller.APIVersion[:i], controller.APIVersion[i+1:]
+	}
+	return fmt.Sprintf("%s/%s/%s/%s/%s", group, version, namespace, controller.Kind, controller.Name)
+}
+
+// mergeResults merges the controls results of an owned resource into the results of its controller. A rule already evaluated on the
+// controller is kept, unless it passed and the rule failed on the owned resource
+func mergeResults(owner *resourcesresults.Result, controls []resourcesresults.ResourceAssociatedControl, prefix string) {
+	for _, control := range controls {
+		i := 0
+		for ; i < len(owner.AssociatedControls); i++ {
+			if owner.AssociatedControls[i].ControlID == control.ControlID {
+				break
+			}
+		}
+		if i == len(owner.AssociatedControls) {
+			owner.AssociatedControls = append(owner.AssociatedControls, resourcesresults.ResourceAssociatedControl{ControlID: control.ControlID, Name: control.Name})
+		}
+		ownerControl := &owner.AssociatedControls[i]
+		for _, rule := range control.ResourceAssociatedRules {
+			rule = templateRule(rule, prefix)
+			j := 0
+			for ; j < len(ownerControl.ResourceAssociatedRules); j++ {
+				if ownerControl.ResourceAssociatedRules[j].Name == rule.Name {
+					break
+				}
+			}
+			if j == len(ownerControl.ResourceAssociatedRules) {
+				ownerControl.ResourceAssociatedRules = append(ownerControl.ResourceAssociatedRules, rule)
+			} else if ownerControl.ResourceAssociatedRules[j].Status != apis.StatusFailed && rule.Status == apis.StatusFailed {
+				ownerControl.ResourceAssociatedRules[j] = rule
+			}
+		}
+	}
+}
+
+// templateRule returns the rule with its paths translated to the template of the controller
+func templateRule(rule resourcesresults.ResourceAssociatedRule, prefix string) resourcesresults.ResourceAssociatedRule {
+	if prefix == "" || len(rule.Paths) == 0 {
+		return rule
+	}
+	paths := make([]armotypes.PosturePaths, len(rule.Paths))
+	for i := range rule.Paths {
+		paths[i] = rule.Paths[i]
+		if paths[i].FailedPath != "" {
+			paths[i].FailedPath = prefix + paths[i].FailedPath
+		}
+		if paths[i].FixPath.Path != "" {
+			paths[i].FixPath.Path = prefix + paths[i].FixPath.Path
+		}
+	}
+	rule.Paths = paths
+	return rule
+}
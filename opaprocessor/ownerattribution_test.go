@@ -0,0 +1,132 @@
+package opaprocessor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/armosec/armoapi-go/armotypes"
+	"github.com/armosec/k8s-interface/workloadinterface"
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/opa-utils/objectsenvelopes"
+	"github.com/armosec/opa-utils/reporthandling"
+	"github.com/armosec/opa-utils/reporthandling/apis"
+	"github.com/armosec/opa-utils/reporthandling/results/v1/resourcesresults"
+)
+
+func ownedObject(apiVersion, kind, name string, owner map[string]interface{}) workloadinterface.IMetadata {
+	metadata := map[string]interface{}{"name": name, "namespace": "default"}
+	if owner != nil {
+		metadata["ownerReferences"] = []interface{}{owner}
+	}
+	return objectsenvelopes.NewObject(map[string]interface{}{"apiVersion": apiVersion, "kind": kind, "metadata": metadata})
+}
+
+func controllerReference(apiVersion, kind, name string) map[string]interface{} {
+	return map[string]interface{}{"apiVersion": apiVersion, "kind": kind, "name": name, "uid": name, "controller": true}
+}
+
+func failedControl(controlID, rule, path string) resourcesresults.ResourceAssociatedControl {
+	return resourcesresults.ResourceAssociatedControl{ControlID: controlID, ResourceAssociatedRules: []resourcesresults.ResourceAssociatedRule{
+		{Name: rule, Status: apis.StatusFailed, Paths: []armotypes.PosturePaths{{FailedPath: path}}},
+	}}
+}
+
+func TestAttributeToOwners(t *testing.T) {
+	const (
+		deployment = "apps/v1/default/Deployment/web"
+		replicaSet = "apps/v1/default/ReplicaSet/web-5d8f"
+		pod1       = "/v1/default/Pod/web-5d8f-a"
+		pod2       = "/v1/default/Pod/web-5d8f-b"
+		orphan     = "/v1/default/Pod/debug"
+		cronJob    = "batch/v1/default/CronJob/backup"
+		job        = "batch/v1/default/Job/backup-1"
+		jobPod     = "/v1/default/Pod/backup-1-x"
+	)
+	sessionObj := cautils.NewOPASessionObj(nil, nil)
+	sessionObj.AllResources = map[string]workloadinterface.IMetadata{
+		deployment: ownedObject("apps/v1", "Deployment", "web", nil),
+		replicaSet: ownedObject("apps/v1", "ReplicaSet", "web-5d8f", controllerReference("apps/v1", "Deployment", "web")),
+		pod1:       ownedObject("v1", "Pod", "web-5d8f-a", controllerReference("apps/v1", "ReplicaSet", "web-5d8f")),
+		pod2:       ownedObject("v1", "Pod", "web-5d8f-b", controllerReference("apps/v1", "ReplicaSet", "web-5d8f")),
+		orphan:     ownedObject("v1", "Pod", "debug", controllerReference("apps/v1", "ReplicaSet", "deleted")),
+		cronJob:    ownedObject("batch/v1", "CronJob", "backup", nil),
+		job:        ownedObject("batch/v1", "Job", "backup-1", controllerReference("batch/v1", "CronJob", "backup")),
+		jobPod:     ownedObject("v1", "Pod", "backup-1-x", controllerReference("batch/v1", "Job", "backup-1")),
+	}
+	sessionObj.ResourcesResult = map[string]resourcesresults.Result{
+		deployment: {ResourceID: deployment, AssociatedControls: []resourcesresults.ResourceAssociatedControl{failedControl("C-0016", "rule-allow-privilege-escalation", "spec.template.spec.containers[0].securityContext")}},
+		pod1:       {ResourceID: pod1, AssociatedControls: []resourcesresults.ResourceAssociatedControl{failedControl("C-0016", "rule-allow-privilege-escalation", "spec.containers[1].securityContext"), failedControl("C-0057", "rule-privileged-container", "spec.containers[0].securityContext.privileged")}},
+		pod2:       {ResourceID: pod2, AssociatedControls: []resourcesresults.ResourceAssociatedControl{failedControl("C-0057", "rule-privileged-container", "spec.containers[0].securityContext.privileged")}},
+		orphan:     {ResourceID: orphan, AssociatedControls: []resourcesresults.ResourceAssociatedControl{failedControl("C-0057", "rule-privileged-container", "spec.containers[0].securityContext.privileged")}},
+		jobPod:     {ResourceID: jobPod, AssociatedControls: []resourcesresults.ResourceAssociatedControl{failedControl("C-0057", "rule-privileged-container", "spec.containers[0].securityContext.privileged")}},
+	}
+
+	opap := NewOPAProcessor(sessionObj, nil)
+	opap.attributeToOwners()
+
+	// the pods of the controllers are deduplicated into the controllers, the pod without a scanned controller is kept
+	assert.ElementsMatch(t, []string{deployment, orphan, cronJob}, keys(sessionObj.ResourcesResult))
+	assert.Equal(t, []string{pod1, pod2}, sessionObj.OwnedResources[deployment])
+	assert.Equal(t, []string{jobPod}, sessionObj.OwnedResources[cronJob])
+
+	// the rule evaluated on the deployment keeps the result of the deployment
+	controls := sessionObj.ResourcesResult[deployment].AssociatedControls
+	assert.Len(t, controls, 2)
+	assert.Equal(t, "spec.template.spec.containers[0].securityContext", controls[0].ResourceAssociatedRules[0].Paths[0].FailedPath)
+	// the paths of the pods are translated to the template of the controller
+	assert.Equal(t, "C-0057", controls[1].ControlID)
+	assert.Len(t, controls[1].ResourceAssociatedRules, 1)
+	assert.Equal(t, "spec.template.spec.containers[0].securityContext.privileged", controls[1].ResourceAssociatedRules[0].Paths[0].FailedPath)
+	assert.Equal(t, "spec.jobTemplate.spec.template.spec.containers[0].securityContext.privileged", sessionObj.ResourcesResult[cronJob].AssociatedControls[0].ResourceAssociatedRules[0].Paths[0].FailedPath)
+}
+
+func keys(results map[string]resourcesresults.Result) []string {
+	resourceIDs := []string{}
+	for resourceID := range results {
+		resourceIDs = append(resourceIDs, resourceID)
+	}
+	return resourceIDs
+}
+
+func TestStreamResultsAttributedToOwners(t *testing.T) {
+	const (
+		deployment = "apps/v1/default/Deployment/web"
+		replicaSet = "apps/v1/default/ReplicaSet/web-5d8f"
+		pod1       = "/v1/default/Pod/web-5d8f-a"
+		pod2       = "/v1/default/Pod/web-5d8f-b"
+		orphan     = "/v1/default/Pod/debug"
+	)
+	sessionObj := cautils.NewOPASessionObj(nil, nil)
+	sessionObj.AllResources = map[string]workloadinterface.IMetadata{
+		deployment: ownedObject("apps/v1", "Deployment", "web", nil),
+		replicaSet: ownedObject("apps/v1", "ReplicaSet", "web-5d8f", controllerReference("apps/v1", "Deployment", "web")),
+		pod1:       ownedObject("v1", "Pod", "web-5d8f-a", controllerReference("apps/v1", "ReplicaSet", "web-5d8f")),
+		pod2:       ownedObject("v1", "Pod", "web-5d8f-b", controllerReference("apps/v1", "ReplicaSet", "web-5d8f")),
+		orphan:     ownedObject("v1", "Pod", "debug", nil),
+	}
+	passed := resourcesresults.ResourceAssociatedControl{ControlID: "C-0057", ResourceAssociatedRules: []resourcesresults.ResourceAssociatedRule{{Name: "rule-privileged-container", Status: apis.StatusPassed}}}
+	results := map[string]resourcesresults.ResourceAssociatedControl{
+		deployment: passed,
+		pod1:       failedControl("C-0057", "rule-privileged-container", "spec.containers[0].securityContext.privileged"),
+		pod2:       failedControl("C-0057", "rule-privileged-container", "spec.containers[0].securityContext.privileged"),
+		orphan:     failedControl("C-0057", "rule-privileged-container", "spec.containers[0].securityContext.privileged"),
+	}
+
+	listener := &resultsListenerMock{}
+	opap := NewOPAProcessor(sessionObj, nil)
+	opap.resultsListeners = []IResultsListener{listener}
+	opap.streamResults(&reporthandling.Control{ControlID: "C-0057"}, results)
+
+	// the pods are streamed as their deployment, failed by the pods
+	assert.Equal(t, []string{orphan, deployment}, listener.resourceIDs)
+	assert.Equal(t, []apis.ScanningStatus{apis.StatusFailed, apis.StatusFailed}, listener.statuses)
+	// the results are not changed, they are attributed once the scan is done
+	assert.Equal(t, apis.StatusPassed, results[deployment].ResourceAssociatedRules[0].Status)
+
+	listener = &resultsListenerMock{}
+	opap.resultsListeners = []IResultsListener{listener}
+	opap.KeepOwnedResults = true
+	opap.streamResults(&reporthandling.Control{ControlID: "C-0057"}, results)
+	assert.Equal(t, []string{orphan, pod1, pod2, deployment}, listener.resourceIDs)
+}
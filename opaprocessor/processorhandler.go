@@ -3,37 +3,61 @@ package opaprocessor
 import (
 	"context"
 	"fmt"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/armosec/armoapi-go/armotypes"
 	"github.com/armosec/kubescape/cautils"
 	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/cautils/logger/helpers"
 	ksscore "github.com/armosec/kubescape/score"
 	"github.com/armosec/opa-utils/objectsenvelopes"
 	"github.com/armosec/opa-utils/reporthandling"
 	"github.com/armosec/opa-utils/reporthandling/apis"
 	"github.com/armosec/opa-utils/reporthandling/results/v1/resourcesresults"
-	"github.com/open-policy-agent/opa/storage"
 
 	"github.com/armosec/k8s-interface/k8sinterface"
 	"github.com/armosec/k8s-interface/workloadinterface"
 
 	"github.com/armosec/opa-utils/resources"
-	"github.com/open-policy-agent/opa/ast"
-	"github.com/open-policy-agent/opa/rego"
 )
 
 const ScoreConfigPath = "/resources/config"
 
+// DefaultEvalConcurrency the default number of the controls evaluated concurrently
+const DefaultEvalConcurrency = 4
+
 type OPAProcessorHandler struct {
+	ctx                  context.Context
+	evalTimeout          time.Duration // the timeout of the evaluation of the controls of a scan, none when zero
 	processedPolicy      *chan *cautils.OPASessionObj
 	reportResults        *chan *cautils.OPASessionObj
 	regoDependenciesData *resources.RegoDependenciesData
+	resultsListeners     []IResultsListener
+	evalConcurrency      int
 }
 
 type OPAProcessor struct {
 	*cautils.OPASessionObj
+	ctx                  context.Context // the evaluation is cancelled when done
 	regoDependenciesData *resources.RegoDependenciesData
+	resultsListeners     []IResultsListener
+	evalConcurrency      int          // the number of the controls evaluated concurrently
+	resourcesLock        sync.RWMutex // guards AllResources while evaluating the controls
+}
+
+// evaluatedControl the results of a control evaluated by a worker of the pool
+type evaluatedControl struct {
+	control  *reporthandling.Control
+	results  map[string]resourcesresults.ResourceAssociatedControl
+	duration time.Duration
+	err      error
+}
+
+// IResultsListener is notified of the results of a control as soon as the control is evaluated, before the scan is done
+type IResultsListener interface {
+	StreamResult(opaSessionObj *cautils.OPASessionObj, control *reporthandling.Control, resourceID string, result *resourcesresults.ResourceAssociatedControl)
 }
 
 func NewOPAProcessor(sessionObj *cautils.OPASessionObj, regoDependenciesData *resources.RegoDependenciesData) *OPAProcessor {
@@ -41,42 +65,81 @@ func NewOPAProcessor(sessionObj *cautils.OPASessionObj, regoDependenciesData *re
 		regoDependenciesData.PostureControlInputs = sessionObj.RegoInputData.PostureControlInputs
 	}
 	return &OPAProcessor{
+		ctx:                  context.Background(),
 		OPASessionObj:        sessionObj,
 		regoDependenciesData: regoDependenciesData,
 	}
 }
 
-func NewOPAProcessorHandler(processedPolicy, reportResults *chan *cautils.OPASessionObj) *OPAProcessorHandler {
+func NewOPAProcessorHandler(ctx context.Context, processedPolicy, reportResults *chan *cautils.OPASessionObj, evalConcurrency int, evalTimeout time.Duration) *OPAProcessorHandler {
 	return &OPAProcessorHandler{
+		ctx:                  ctx,
+		evalTimeout:          evalTimeout,
 		processedPolicy:      processedPolicy,
 		reportResults:        reportResults,
 		regoDependenciesData: resources.NewRegoDependenciesData(k8sinterface.GetK8sConfig(), cautils.ClusterName),
+		evalConcurrency:      evalConcurrency,
 	}
 }
 
-func (opaHandler *OPAProcessorHandler) ProcessRulesListenner() {
+// AddResultsListener adds a listener to the results of every control
+func (opaHandler *OPAProcessorHandler) AddResultsListener(listener IResultsListener) {
+	opaHandler.resultsListeners = append(opaHandler.resultsListeners, listener)
+}
+
+// ProcessRulesListenner evaluates the controls of the sessions received, and sends the results.
+// Returns the error of an evaluation cancelled by the context of the handler or timed out, and the context error when the context is done
+// while waiting for a session or for the results to be received
+func (opaHandler *OPAProcessorHandler) ProcessRulesListenner() error {
 
 	for {
-		opaSessionObj := <-*opaHandler.processedPolicy
+		var opaSessionObj *cautils.OPASessionObj
+		select {
+		case opaSessionObj = <-*opaHandler.processedPolicy:
+		case <-opaHandler.ctx.Done():
+			return opaHandler.ctx.Err()
+		}
 		opap := NewOPAProcessor(opaSessionObj, opaHandler.regoDependenciesData)
+		opap.resultsListeners = opaHandler.resultsListeners
+		opap.evalConcurrency = opaHandler.evalConcurrency
 
 		policies := ConvertFrameworksToPolicies(opap.Frameworks, cautils.BuildNumber)
 
 		ConvertFrameworksToSummaryDetails(&opap.Report.SummaryDetails, opap.Frameworks, policies)
 
 		// process
-		if err := opap.Process(policies); err != nil {
-			logger.L().Error(err.Error())
+		start := time.Now()
+		var cancel context.CancelFunc
+		opap.ctx, cancel = cautils.PhaseContext(opaHandler.ctx, opaHandler.evalTimeout)
+		err := opap.Process(policies)
+		cancel()
+		if err != nil {
+			return cautils.PhaseError(opaHandler.ctx, cautils.PhaseControlsEvaluation, opaHandler.evalTimeout, err)
 		}
 
+		compiledRules.endScan()
+		opaSessionObj.Profile.AddPhase(cautils.PhaseControlsEvaluation, start)
+
 		// edit results
+		start = time.Now()
+		if !opap.KeepOwnedResults {
+			opap.attributeToOwners()
+		}
 		opap.updateResults()
+		opap.markDataGaps(policies)
 
 		//TODO: review this location
 		scorewrapper := ksscore.NewScoreWrapper(opaSessionObj)
 		scorewrapper.Calculate(ksscore.EPostureReportV2)
+		scorewrapper.CalculateBreakdown()
+		opaSessionObj.Profile.AddPhase(cautils.PhaseResultsProcessing, start)
+		opaSessionObj.ScanTimes.End = time.Now().UTC()
 		// report
-		*opaHandler.reportResults <- opaSessionObj
+		select {
+		case *opaHandler.reportResults <- opaSessionObj:
+		case <-opaHandler.ctx.Done():
+			return opaHandler.ctx.Err()
+		}
 	}
 }
 
@@ -86,15 +149,18 @@ func (opap *OPAProcessor) Process(policies *cautils.Policies) error {
 	cautils.StartSpinner()
 
 	var errs error
-	for _, control := range policies.Controls {
-
-		resourcesAssociatedControl, err := opap.processControl(&control)
-		if err != nil {
-			logger.L().Error(err.Error())
+	opap.EvaluationTime = make(map[string]time.Duration, len(policies.Controls))
+	for evaluated := range opap.evaluateControls(policies.Controls) {
+		if evaluated.err != nil && opap.ctx.Err() == nil {
+			logger.L().Error(evaluated.err.Error())
 		}
+		control := evaluated.control
+		opap.EvaluationTime[control.ControlID] = evaluated.duration
+		logger.L().Debug("Control evaluated", helpers.String("controlID", control.ControlID), helpers.String("duration", evaluated.duration.String()))
+
 		// update resources with latest results
-		if len(resourcesAssociatedControl) != 0 {
-			for resourceID, controlResult := range resourcesAssociatedControl {
+		if len(evaluated.results) != 0 {
+			for resourceID, controlResult := range evaluated.results {
 				if _, ok := opap.ResourcesResult[resourceID]; !ok {
 					opap.ResourcesResult[resourceID] = resourcesresults.Result{ResourceID: resourceID}
 				}
@@ -102,16 +168,61 @@ func (opap *OPAProcessor) Process(policies *cautils.Policies) error {
 				t.AssociatedControls = append(t.AssociatedControls, controlResult)
 				opap.ResourcesResult[resourceID] = t
 			}
+			opap.streamResults(control, evaluated.results)
 		}
 	}
 
 	opap.Report.ReportGenerationTime = time.Now().UTC()
 
 	cautils.StopSpinner()
+	if err := opap.ctx.Err(); err != nil {
+		return err
+	}
 	logger.L().Success(fmt.Sprintf("Done scanning cluster %s", cautils.ClusterName))
 	return errs
 }
 
+// evaluateControls evaluates the controls by a pool of evalConcurrency workers, the results are sent as soon as a control is evaluated
+func (opap *OPAProcessor) evaluateControls(controls map[string]reporthandling.Control) <-chan evaluatedControl {
+	controlIDs := make([]string, 0, len(controls))
+	for controlID := range controls {
+		controlIDs = append(controlIDs, controlID)
+	}
+	sort.Strings(controlIDs)
+
+	workers := opap.evalConcurrency
+	if workers < 1 {
+		workers = 1
+	}
+	queue := make(chan *reporthandling.Control)
+	evaluated := make(chan evaluatedControl)
+	wg := sync.WaitGroup{}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for control := range queue {
+				start := time.Now()
+				results, err := opap.processControl(control)
+				evaluated <- evaluatedControl{control: control, results: results, duration: time.Since(start), err: err}
+			}
+		}()
+	}
+	go func() {
+		for _, controlID := range controlIDs {
+			if opap.ctx.Err() != nil {
+				break // the evaluation is cancelled, the controls in evaluation are stopped by the context
+			}
+			control := controls[controlID]
+			queue <- &control
+		}
+		close(queue)
+		wg.Wait()
+		close(evaluated)
+	}()
+	return evaluated
+}
+
 func (opap *OPAProcessor) processControl(control *reporthandling.Control) (map[string]resourcesresults.ResourceAssociatedControl, error) {
 	var errs error
 
@@ -121,6 +232,9 @@ func (opap *OPAProcessor) processControl(control *reporthandling.Control) (map[s
 	for i := range control.Rules {
 		resourceAssociatedRule, err := opap.processRule(&control.Rules[i])
 		if err != nil {
+			if opap.ctx.Err() != nil {
+				return nil, err
+			}
 			logger.L().Error(err.Error())
 			continue
 		}
@@ -151,7 +265,10 @@ func (opap *OPAProcessor) processRule(rule *reporthandling.PolicyRule) (map[stri
 
 	postureControlInputs := opap.regoDependenciesData.GetFilteredPostureControlInputs(rule.ConfigInputs) // get store
 
-	inputResources, err := reporthandling.RegoResourcesAggregator(rule, getAllSupportedObjects(opap.K8SResources, opap.AllResources, rule))
+	opap.resourcesLock.RLock()
+	supportedObjects := getAllSupportedObjects(opap.K8SResources, opap.AllResources, rule)
+	opap.resourcesLock.RUnlock()
+	inputResources, err := reporthandling.RegoResourcesAggregator(rule, supportedObjects)
 	if err != nil {
 		return nil, fmt.Errorf("error getting aggregated k8sObjects: %s", err.Error())
 	}
@@ -161,27 +278,37 @@ func (opap *OPAProcessor) processRule(rule *reporthandling.PolicyRule) (map[stri
 
 	inputRawResources := workloadinterface.ListMetaToMap(inputResources)
 
+	// the workloads are evaluated by batches, bounding the input of the evaluation of the large clusters
+	var errs error
 	resources := map[string]*resourcesresults.ResourceAssociatedRule{}
-	// the failed resources are a subgroup of the enumeratedData, so we store the enumeratedData like it was the input data
-	enumeratedData, err := opap.enumerateData(rule, inputRawResources)
-	if err != nil {
-		return nil, err
-	}
-	inputResources = objectsenvelopes.ListMapToMeta(enumeratedData)
-	for i := range inputResources {
-		resources[inputResources[i].GetID()] = &resourcesresults.ResourceAssociatedRule{
-			Name:                  rule.Name,
-			ControlConfigurations: postureControlInputs,
-			Status:                apis.StatusPassed,
+	for _, batch := range evaluationBatches(rule, inputRawResources, evaluationBatchSize) {
+		// the failed resources are a subgroup of the enumeratedData, so we store the enumeratedData like it was the input data
+		enumeratedData, err := opap.enumerateData(rule, batch)
+		if err != nil {
+			return nil, err
 		}
-		opap.AllResources[inputResources[i].GetID()] = inputResources[i]
-	}
+		enumeratedResources := objectsenvelopes.ListMapToMeta(enumeratedData)
+		opap.resourcesLock.Lock()
+		for i := range enumeratedResources {
+			resources[enumeratedResources[i].GetID()] = &resourcesresults.ResourceAssociatedRule{
+				Name:                  rule.Name,
+				ControlConfigurations: postureControlInputs,
+				Status:                apis.StatusPassed,
+			}
+			opap.AllResources[enumeratedResources[i].GetID()] = enumeratedResources[i]
+		}
+		opap.resourcesLock.Unlock()
 
-	ruleResponses, err := opap.runOPAOnSingleRule(rule, inputRawResources, ruleData, postureControlInputs)
-	if err != nil {
-		// TODO - Handle error
-		logger.L().Error(err.Error())
-	} else {
+		ruleResponses, err := opap.runOPAOnSingleRule(rule, batch, ruleData, postureControlInputs)
+		if err != nil {
+			if opap.ctx.Err() != nil {
+				return nil, err
+			}
+			// TODO - Handle error
+			logger.L().Error(err.Error())
+			errs = err
+			continue
+		}
 		// ruleResponse to ruleResult
 		for i := range ruleResponses {
 			failedResources := objectsenvelopes.ListMapToMeta(ruleResponses[i].GetFailedResources())
@@ -203,7 +330,7 @@ func (opap *OPAProcessor) processRule(rule *reporthandling.PolicyRule) (map[stri
 		}
 	}
 
-	return resources, err
+	return resources, errs
 }
 
 func (opap *OPAProcessor) runOPAOnSingleRule(rule *reporthandling.PolicyRule, k8sObjects []map[string]interface{}, getRuleData func(*reporthandling.PolicyRule) string, postureControlInputs map[string][]string) ([]reporthandling.RuleResponse, error) {
@@ -217,54 +344,24 @@ func (opap *OPAProcessor) runOPAOnSingleRule(rule *reporthandling.PolicyRule, k8
 
 func (opap *OPAProcessor) runRegoOnK8s(rule *reporthandling.PolicyRule, k8sObjects []map[string]interface{}, getRuleData func(*reporthandling.PolicyRule) string, postureControlInputs map[string][]string) ([]reporthandling.RuleResponse, error) {
 
-	// compile modules
-	modules, err := getRuleDependencies()
-	if err != nil {
-		return nil, fmt.Errorf("rule: '%s', %s", rule.Name, err.Error())
-	}
-	modules[rule.Name] = getRuleData(rule)
-	compiled, err := ast.CompileModules(modules)
-	if err != nil {
-		return nil, fmt.Errorf("in 'runRegoOnSingleRule', failed to compile rule, name: %s, reason: %s", rule.Name, err.Error())
-	}
-
-	store, err := resources.TOStorage(postureControlInputs)
+	// compile modules, once per process
+	prepared, err := compiledRules.get(rule, getRuleData(rule), postureControlInputs)
 	if err != nil {
 		return nil, err
 	}
 
 	// Eval
-	results, err := opap.regoEval(k8sObjects, compiled, &store)
+	results, err := prepared.eval(opap.ctx, k8sObjects)
 	if err != nil {
+		if opap.ctx.Err() != nil {
+			return nil, opap.ctx.Err()
+		}
 		logger.L().Error(err.Error())
 	}
 
 	return results, nil
 }
 
-func (opap *OPAProcessor) regoEval(inputObj []map[string]interface{}, compiledRego *ast.Compiler, store *storage.Store) ([]reporthandling.RuleResponse, error) {
-	// opap.regoDependenciesData.PostureControlInputs
-
-	rego := rego.New(
-		rego.Query("data.armo_builtins"), // get package name from rule
-		rego.Compiler(compiledRego),
-		rego.Input(inputObj),
-		rego.Store(*store),
-	)
-
-	// Run evaluation
-	resultSet, err := rego.Eval(context.Background())
-	if err != nil {
-		return nil, err
-	}
-	results, err := reporthandling.ParseRegoResult(&resultSet)
-	if err != nil {
-		return results, err
-	}
-
-	return results, nil
-}
-
 func (opap *OPAProcessor) enumerateData(rule *reporthandling.PolicyRule, k8sObjects []map[string]interface{}) ([]map[string]interface{}, error) {
 
 	if ruleEnumeratorData(rule) == "" {
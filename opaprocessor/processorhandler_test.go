@@ -1,6 +1,7 @@
 package opaprocessor
 
 import (
+	"context"
 	"testing"
 
 	"github.com/armosec/armoapi-go/armotypes"
@@ -17,7 +18,7 @@ import (
 )
 
 func NewOPAProcessorMock() *OPAProcessor {
-	return &OPAProcessor{}
+	return &OPAProcessor{ctx: context.Background()}
 }
 func TestProcess(t *testing.T) {
 
@@ -56,6 +57,28 @@ func TestProcess(t *testing.T) {
 
 }
 
+func TestProcessCancelled(t *testing.T) {
+	k8sResources := make(cautils.K8SResources)
+	allResources := make(map[string]workloadinterface.IMetadata)
+	imetaObj := objectsenvelopes.ListMapToMeta(k8sinterface.ConvertUnstructuredSliceToMap(k8sinterface.V1KubeSystemNamespaceMock().Items))
+	for i := range imetaObj {
+		allResources[imetaObj[i].GetID()] = imetaObj[i]
+	}
+	k8sResources["/v1/pods"] = workloadinterface.ListMetaIDs(imetaObj)
+
+	opaSessionObj := cautils.NewOPASessionObjMock()
+	opaSessionObj.Frameworks = []reporthandling.Framework{*reporthandling.MockFrameworkA()}
+	opaSessionObj.K8SResources = &k8sResources
+	opaSessionObj.AllResources = allResources
+
+	opap := NewOPAProcessor(opaSessionObj, resources.NewRegoDependenciesDataMock())
+	var cancel context.CancelFunc
+	opap.ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	assert.Equal(t, context.Canceled, opap.Process(ConvertFrameworksToPolicies(opaSessionObj.Frameworks, "")))
+	assert.Empty(t, opap.ResourcesResult)
+}
+
 func TestProcessResourcesResult(t *testing.T) {
 
 	// set k8s
@@ -137,3 +160,41 @@ func TestProcessResourcesResult(t *testing.T) {
 	assert.Equal(t, 0, len(summaryDetails.ListResourcesIDs().Excluded()))
 	assert.Equal(t, 0, len(summaryDetails.ListResourcesIDs().Passed()))
 }
+
+func TestProcessConcurrently(t *testing.T) {
+	deployment := mocks.MockDevelopmentWithHostpath()
+	k8sResources := cautils.K8SResources{"apps/v1/deployments": workloadinterface.ListMetaIDs([]workloadinterface.IMetadata{deployment})}
+
+	opaSessionObj := cautils.NewOPASessionObjMock()
+	opaSessionObj.Frameworks = []reporthandling.Framework{*mocks.MockFramework_0006_0013()}
+	policies := ConvertFrameworksToPolicies(opaSessionObj.Frameworks, "")
+	ConvertFrameworksToSummaryDetails(&opaSessionObj.Report.SummaryDetails, opaSessionObj.Frameworks, policies)
+	opaSessionObj.K8SResources = &k8sResources
+	opaSessionObj.AllResources[deployment.GetID()] = deployment
+
+	opap := NewOPAProcessor(opaSessionObj, resources.NewRegoDependenciesDataMock())
+	opap.evalConcurrency = 4
+	assert.NoError(t, opap.Process(policies))
+
+	res := opaSessionObj.ResourcesResult[deployment.GetID()]
+	assert.Equal(t, 2, len(res.ListControlsIDs(nil).All()))
+	assert.Equal(t, 1, len(res.ListControlsIDs(nil).Failed()))
+	assert.Len(t, opaSessionObj.EvaluationTime, len(policies.Controls))
+	for controlID := range policies.Controls {
+		assert.Contains(t, opaSessionObj.EvaluationTime, controlID)
+	}
+}
+
+func TestProcessRulesListennerCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	processedPolicy := make(chan *cautils.OPASessionObj)
+	reportResults := make(chan *cautils.OPASessionObj)
+	opaHandler := &OPAProcessorHandler{ctx: ctx, processedPolicy: &processedPolicy, reportResults: &reportResults}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- opaHandler.ProcessRulesListenner()
+	}()
+	cancel()
+	assert.Equal(t, context.Canceled, <-done)
+}
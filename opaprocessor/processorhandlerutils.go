@@ -2,15 +2,19 @@ package opaprocessor
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
 	pkgcautils "github.com/armosec/utils-go/utils"
 
 	"github.com/armosec/kubescape/cautils"
 	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/exceptionshandler"
 
 	"github.com/armosec/k8s-interface/k8sinterface"
 	"github.com/armosec/k8s-interface/workloadinterface"
 	"github.com/armosec/opa-utils/reporthandling"
+	"github.com/armosec/opa-utils/reporthandling/results/v1/resourcesresults"
 	resources "github.com/armosec/opa-utils/resources"
 )
 
@@ -19,6 +23,7 @@ import (
 	- remove sensible data
 	- adding exceptions
 	- summarize results
+	- sort the controls of the results, the resources are summarized by the order of their IDs - the outputs are stable across scans
 */
 func (opap *OPAProcessor) updateResults() {
 
@@ -28,13 +33,14 @@ func (opap *OPAProcessor) updateResults() {
 	}
 
 	// set exceptions
-	for i := range opap.ResourcesResult {
+	for _, i := range sortedResourceIDs(opap.ResourcesResult) {
 
 		t := opap.ResourcesResult[i]
+		sortControls(t.AssociatedControls)
 
 		// first set exceptions
 		if resource, ok := opap.AllResources[i]; ok {
-			t.SetExceptions(resource, opap.Exceptions, cautils.ClusterName)
+			exceptionshandler.SetExceptions(&t, resource, opap.Exceptions, cautils.ClusterName)
 		}
 
 		// summarize the resources
@@ -62,6 +68,55 @@ func (opap *OPAProcessor) updateResults() {
 	// }
 }
 
+// sortedResourceIDs returns the IDs of the results, sorted
+func sortedResourceIDs(results map[string]resourcesresults.Result) []string {
+	resourceIDs := make([]string, 0, len(results))
+	for resourceID := range results {
+		resourceIDs = append(resourceIDs, resourceID)
+	}
+	sort.Strings(resourceIDs)
+	return resourceIDs
+}
+
+// sortControls sorts the controls of a result by ID, the controls are evaluated concurrently
+func sortControls(controls []resourcesresults.ResourceAssociatedControl) {
+	sort.Slice(controls, func(i, j int) bool {
+		return controls[i].ControlID < controls[j].ControlID
+	})
+}
+
+// streamResults notifies the listeners of the results of the control. The results are attributed to the owners and the exceptions are set
+// on a copy of the results, the results themselves are updated once all of the controls are evaluated (see attributeToOwners and updateResults)
+func (opap *OPAProcessor) streamResults(control *reporthandling.Control, resourcesAssociatedControl map[string]resourcesresults.ResourceAssociatedControl) {
+	if len(opap.resultsListeners) == 0 {
+		return
+	}
+	if !opap.KeepOwnedResults {
+		resourcesAssociatedControl = opap.attributeControlToOwners(resourcesAssociatedControl)
+	}
+	resourceIDs := make([]string, 0, len(resourcesAssociatedControl))
+	for resourceID := range resourcesAssociatedControl {
+		resourceIDs = append(resourceIDs, resourceID)
+	}
+	sort.Strings(resourceIDs)
+
+	for _, resourceID := range resourceIDs {
+		controlResult := resourcesAssociatedControl[resourceID]
+		controlResult.ResourceAssociatedRules = append([]resourcesresults.ResourceAssociatedRule{}, controlResult.ResourceAssociatedRules...)
+
+		result := resourcesresults.Result{ResourceID: resourceID, AssociatedControls: []resourcesresults.ResourceAssociatedControl{controlResult}}
+		opap.resourcesLock.RLock()
+		resource, ok := opap.AllResources[resourceID]
+		opap.resourcesLock.RUnlock()
+		if ok {
+			exceptionshandler.SetExceptions(&result, resource, opap.Exceptions, cautils.ClusterName)
+		}
+		for _, listener := range opap.resultsListeners {
+			listener.StreamResult(opap.OPASessionObj, control, resourceID, &result.AssociatedControls[0])
+		}
+	}
+}
+
 func getAllSupportedObjects(k8sResources *cautils.K8SResources, allResources map[string]workloadinterface.IMetadata, rule *reporthandling.PolicyRule) []workloadinterface.IMetadata {
 	k8sObjects := []workloadinterface.IMetadata{}
 	k8sObjects = append(k8sObjects, getKubernetesObjects(k8sResources, allResources, rule.Match)...)
@@ -95,6 +150,47 @@ func getKubernetesObjects(k8sResources *cautils.K8SResources, allResources map[s
 	return filterOutChildResources(k8sObjects, match)
 }
 
+// evaluationBatchSize the workloads of a single evaluation of a rule
+const evaluationBatchSize = 1000
+
+// workloadResources the resources of the rules evaluating each resource on its own, the rules match the kinds or the resources (e.g. Deployment, deployments)
+var workloadResources = map[string]bool{"pods": true, "deployments": true, "replicasets": true, "daemonsets": true, "statefulsets": true, "jobs": true, "cronjobs": true}
+
+// evaluationBatches splits the input of the rules matching the workloads only into batches. The other rules correlate the resources
+// (e.g. the workloads and their services, the roles and their bindings), evaluated on all of the resources at once
+func evaluationBatches(rule *reporthandling.PolicyRule, inputRawResources []map[string]interface{}, batchSize int) [][]map[string]interface{} {
+	if len(inputRawResources) <= batchSize || !isWorkloadsRule(rule) {
+		return [][]map[string]interface{}{inputRawResources}
+	}
+	batches := [][]map[string]interface{}{}
+	for start := 0; start < len(inputRawResources); start += batchSize {
+		end := start + batchSize
+		if end > len(inputRawResources) {
+			end = len(inputRawResources)
+		}
+		batches = append(batches, inputRawResources[start:end])
+	}
+	return batches
+}
+
+// isWorkloadsRule returns true when the rule matches the workloads only, not aggregating the resources
+func isWorkloadsRule(rule *reporthandling.PolicyRule) bool {
+	if _, ok := rule.Attributes["resourcesAggregator"]; ok || len(rule.DynamicMatch) > 0 || len(rule.Match) == 0 {
+		return false
+	}
+	for m := range rule.Match {
+		for _, resource := range rule.Match[m].Resources {
+			if resource = strings.ToLower(resource); !strings.HasSuffix(resource, "s") {
+				resource += "s"
+			}
+			if !workloadResources[resource] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
 // filterOutChildResources filter out child resources if the parent resource is in the list
 func filterOutChildResources(objects []workloadinterface.IMetadata, match []reporthandling.RuleMatchObjects) []workloadinterface.IMetadata {
 	response := []workloadinterface.IMetadata{}
@@ -9,6 +9,8 @@ import (
 	"github.com/armosec/k8s-interface/workloadinterface"
 	"github.com/armosec/kubescape/cautils"
 	"github.com/armosec/opa-utils/reporthandling"
+	"github.com/armosec/opa-utils/reporthandling/apis"
+	"github.com/armosec/opa-utils/reporthandling/results/v1/resourcesresults"
 )
 
 func TestGetKubernetesObjects(t *testing.T) {
@@ -70,3 +72,62 @@ func TestRemoveData(t *testing.T) {
 		}
 	}
 }
+
+type resultsListenerMock struct {
+	resourceIDs []string
+	statuses    []apis.ScanningStatus
+}
+
+func (listener *resultsListenerMock) StreamResult(opaSessionObj *cautils.OPASessionObj, control *reporthandling.Control, resourceID string, result *resourcesresults.ResourceAssociatedControl) {
+	listener.resourceIDs = append(listener.resourceIDs, resourceID)
+	listener.statuses = append(listener.statuses, result.GetStatus(nil).Status())
+}
+
+func TestStreamResults(t *testing.T) {
+	w := `{"apiVersion":"v1","kind":"Service","metadata":{"name":"web","namespace":"prod"},"spec":{"type":"NodePort"}}`
+	obj, _ := workloadinterface.NewWorkload([]byte(w))
+
+	opaSessionObj := cautils.NewOPASessionObjMock()
+	opaSessionObj.AllResources[obj.GetID()] = obj
+	opaSessionObj.Exceptions = []armotypes.PostureExceptionPolicy{{
+		PolicyType:      "postureExceptionPolicy",
+		Actions:         []armotypes.PostureExceptionPolicyActions{armotypes.AlertOnly},
+		Resources:       []armotypes.PortalDesignator{{DesignatorType: armotypes.DesignatorAttributes, Attributes: map[string]string{"kind": "Service", "name": "web"}}},
+		PosturePolicies: []armotypes.PosturePolicy{{ControlID: "C-0044"}},
+	}}
+
+	listener := &resultsListenerMock{}
+	opap := NewOPAProcessor(opaSessionObj, nil)
+	opap.resultsListeners = []IResultsListener{listener}
+
+	failed := resourcesresults.ResourceAssociatedControl{ControlID: "C-0044", Name: "Exposed NodePort", ResourceAssociatedRules: []resourcesresults.ResourceAssociatedRule{{Name: "nodeport", Status: apis.StatusFailed}}}
+	passed := resourcesresults.ResourceAssociatedControl{ControlID: "C-0044", Name: "Exposed NodePort", ResourceAssociatedRules: []resourcesresults.ResourceAssociatedRule{{Name: "nodeport", Status: apis.StatusPassed}}}
+	results := map[string]resourcesresults.ResourceAssociatedControl{obj.GetID(): failed, "/v1/dev/Service/api": passed}
+
+	opap.streamResults(&reporthandling.Control{ControlID: "C-0044"}, results)
+
+	assert.Equal(t, []string{"/v1/dev/Service/api", obj.GetID()}, listener.resourceIDs)
+	assert.Equal(t, []apis.ScanningStatus{apis.StatusPassed, apis.StatusExcluded}, listener.statuses)
+	// the exceptions are set on a copy of the results
+	assert.Nil(t, results[obj.GetID()].ResourceAssociatedRules[0].Exception)
+}
+
+func TestEvaluationBatches(t *testing.T) {
+	inputRawResources := make([]map[string]interface{}, 5)
+	workloadsRule := &reporthandling.PolicyRule{Match: []reporthandling.RuleMatchObjects{{APIGroups: []string{"apps"}, APIVersions: []string{"v1"}, Resources: []string{"Deployment", "StatefulSet"}}}}
+	servicesRule := &reporthandling.PolicyRule{Match: []reporthandling.RuleMatchObjects{{APIGroups: []string{""}, APIVersions: []string{"v1"}, Resources: []string{"Pod", "Service"}}}}
+	aggregatedRule := &reporthandling.PolicyRule{PortalBase: armotypes.PortalBase{Attributes: map[string]interface{}{"resourcesAggregator": "apiserver-pod"}}, Match: []reporthandling.RuleMatchObjects{{Resources: []string{"Pod"}}}}
+
+	batches := evaluationBatches(workloadsRule, inputRawResources, 2)
+	if assert.Len(t, batches, 3) {
+		assert.Len(t, batches[0], 2)
+		assert.Len(t, batches[2], 1)
+	}
+	assert.Len(t, evaluationBatches(workloadsRule, inputRawResources, 5), 1)
+	resourcesRule := &reporthandling.PolicyRule{Match: []reporthandling.RuleMatchObjects{{APIGroups: []string{"apps"}, APIVersions: []string{"v1"}, Resources: []string{"deployments", "daemonsets"}}}}
+	assert.Len(t, evaluationBatches(resourcesRule, inputRawResources, 2), 3)
+
+	// the rules correlating the resources are evaluated on all of the resources at once
+	assert.Len(t, evaluationBatches(servicesRule, inputRawResources, 2), 1)
+	assert.Len(t, evaluationBatches(aggregatedRule, inputRawResources, 2), 1)
+}
@@ -0,0 +1,329 @@
+package opaprocessor
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/armosec/kubescape/cautils/getter"
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/cautils/logger/helpers"
+	"github.com/armosec/opa-utils/reporthandling"
+	"github.com/armosec/opa-utils/resources"
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/storage"
+)
+
+const ruleAnalysisFile = "rules-analysis.json"
+
+// rulesCacheIdleScans the compiled rules which were not used by the last scans are evicted, e.g. the rules of a previous version of a framework
+const rulesCacheIdleScans = 5
+
+// ruleKinds the kinds of the input a rule can fail, the result of the partial evaluation of the rule.
+// Prunable is false when the rule can fail any input (e.g. the rule counts the input), every kind is evaluated
+type ruleKinds struct {
+	Prunable bool     `json:"prunable"`
+	Kinds    []string `json:"kinds"`
+}
+
+// preparedRule a compiled rule, ready for the evaluation of the inputs
+type preparedRule struct {
+	query rego.PreparedEvalQuery
+	kinds map[string]bool // nil when every kind is evaluated
+}
+
+// rulesCache the compiled rules of the process, by the hash of the source of the rule and of its inputs - the rules are compiled once
+// for all the batches, the frameworks and the scans of a process (watch, server). A new version of a framework changes the source of its
+// rules, the rules which were not used by the last rulesCacheIdleScans scans are evicted. The prepared queries can not be serialized, the
+// analysis of the kinds of the rules is persisted in the cache directory, skipping the partial evaluation of the unchanged rules on the next runs
+type rulesCache struct {
+	mutex    sync.Mutex
+	rules    map[string]*preparedRule
+	lastUsed map[string]int // map[<rule key>]<the last scan which used the rule>
+	scan     int
+	analysis map[string]ruleKinds
+	path     string
+	loaded   bool
+	modified bool
+}
+
+var compiledRules = newRulesCache(getter.GetDefaultPath(ruleAnalysisFile))
+
+func newRulesCache(path string) *rulesCache {
+	return &rulesCache{
+		rules:    map[string]*preparedRule{},
+		lastUsed: map[string]int{},
+		analysis: map[string]ruleKinds{},
+		path:     path,
+	}
+}
+
+// get returns the compiled rule, compiling the rule when missing
+func (cache *rulesCache) get(rule *reporthandling.PolicyRule, source string, postureControlInputs map[string][]string) (*preparedRule, error) {
+	key, err := ruleKey(rule.Name, source, postureControlInputs)
+	if err != nil {
+		return nil, err
+	}
+
+	cache.mutex.Lock()
+	prepared, ok := cache.rules[key]
+	if ok {
+		cache.lastUsed[key] = cache.scan
+	} else {
+		cache.load()
+	}
+	analysis, analyzed := cache.analysis[key]
+	cache.mutex.Unlock()
+	if ok {
+		return prepared, nil
+	}
+
+	// compile without holding the lock, the controls are evaluated concurrently
+	modules, err := getRuleDependencies()
+	if err != nil {
+		return nil, fmt.Errorf("rule: '%s', %s", rule.Name, err.Error())
+	}
+	modules[rule.Name] = source
+	compiled, err := ast.CompileModules(modules)
+	if err != nil {
+		return nil, fmt.Errorf("in 'runRegoOnSingleRule', failed to compile rule, name: %s, reason: %s", rule.Name, err.Error())
+	}
+	store, err := resources.TOStorage(postureControlInputs)
+	if err != nil {
+		return nil, err
+	}
+	query, err := rego.New(
+		rego.Query("data.armo_builtins"), // get package name from rule
+		rego.Compiler(compiled),
+		rego.Store(store),
+	).PrepareForEval(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("rule: '%s', %s", rule.Name, err.Error())
+	}
+
+	if !analyzed {
+		analysis = analyzeRuleKinds(compiled, store)
+	}
+	prepared = &preparedRule{query: query}
+	if analysis.Prunable {
+		prepared.kinds = map[string]bool{}
+		for _, kind := range analysis.Kinds {
+			prepared.kinds[kind] = true
+		}
+	}
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	if !analyzed {
+		cache.analysis[key] = analysis
+		cache.modified = true
+	}
+	cache.rules[key] = prepared
+	cache.lastUsed[key] = cache.scan
+	return prepared, nil
+}
+
+// endScan evicts the rules which were not used by the last scans, and persists the analysis of the rules
+func (cache *rulesCache) endScan() {
+	cache.mutex.Lock()
+	cache.scan++
+	for key, scan := range cache.lastUsed {
+		if cache.scan-scan > rulesCacheIdleScans {
+			delete(cache.rules, key)
+			delete(cache.lastUsed, key)
+		}
+	}
+	cache.mutex.Unlock()
+	cache.save()
+}
+
+// load reads the persisted analysis of the rules, once
+func (cache *rulesCache) load() {
+	if cache.loaded {
+		return
+	}
+	cache.loaded = true
+	data, err := os.ReadFile(cache.path)
+	if err != nil {
+		return
+	}
+	if err := json.Unmarshal(data, &cache.analysis); err != nil {
+		logger.L().Debug("failed to load the analysis of the rules", helpers.String("path", cache.path), helpers.Error(err))
+		cache.analysis = map[string]ruleKinds{}
+	}
+}
+
+// save persists the analysis of the rules when new rules were analyzed
+func (cache *rulesCache) save() {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	if !cache.modified {
+		return
+	}
+	data, err := json.Marshal(cache.analysis)
+	if err == nil {
+		if err = os.MkdirAll(filepath.Dir(cache.path), 0755); err == nil {
+			err = os.WriteFile(cache.path, data, 0644)
+		}
+	}
+	if err != nil {
+		logger.L().Debug("failed to save the analysis of the rules", helpers.String("path", cache.path), helpers.Error(err))
+		return
+	}
+	cache.modified = false
+}
+
+func ruleKey(name, source string, postureControlInputs map[string][]string) (string, error) {
+	inputs, err := json.Marshal(postureControlInputs) // the keys of the maps are sorted
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.New()
+	for _, s := range []string{name, source, string(inputs)} {
+		hash.Write([]byte(s))
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// eval evaluates the rule on the objects of the kinds the rule can fail
+func (prepared *preparedRule) eval(ctx context.Context, k8sObjects []map[string]interface{}) ([]reporthandling.RuleResponse, error) {
+	if prepared.kinds != nil {
+		k8sObjects = filterKinds(k8sObjects, prepared.kinds)
+		if len(k8sObjects) == 0 {
+			return nil, nil
+		}
+	}
+	resultSet, err := prepared.query.Eval(ctx, rego.EvalInput(k8sObjects))
+	if err != nil {
+		return nil, err
+	}
+	return reporthandling.ParseRegoResult(&resultSet)
+}
+
+func filterKinds(k8sObjects []map[string]interface{}, kinds map[string]bool) []map[string]interface{} {
+	filtered := make([]map[string]interface{}, 0, len(k8sObjects))
+	for i := range k8sObjects {
+		if kind, ok := k8sObjects[i]["kind"].(string); ok && kinds[kind] {
+			filtered = append(filtered, k8sObjects[i])
+		}
+	}
+	return filtered
+}
+
+// analyzeRuleKinds partially evaluates the deny rules with an unknown input. The rule is prunable when every element of the input the residual
+// queries refer to (input[x]) is constrained to a kind (input[x].kind == "Deployment") - the elements of the other kinds never fail the rule
+func analyzeRuleKinds(compiled *ast.Compiler, store storage.Store) ruleKinds {
+	partial, err := rego.New(
+		rego.Query("data.armo_builtins.deny[x]"),
+		rego.Compiler(compiled),
+		rego.Store(store),
+		rego.Unknowns([]string{"input"}),
+	).Partial(context.Background())
+	if err != nil || len(partial.Support) != 0 {
+		return ruleKinds{}
+	}
+
+	kinds := map[string]bool{}
+	for _, query := range partial.Queries {
+		queryKinds, ok := queryInputKinds(query)
+		if !ok {
+			return ruleKinds{}
+		}
+		for kind := range queryKinds {
+			kinds[kind] = true
+		}
+	}
+	analysis := ruleKinds{Prunable: true, Kinds: []string{}}
+	for kind := range kinds {
+		analysis.Kinds = append(analysis.Kinds, kind)
+	}
+	sort.Strings(analysis.Kinds)
+	return analysis
+}
+
+// queryInputKinds returns the kinds the elements of the input of a residual query are constrained to, false when an element of the input is not
+// constrained to a kind or when the input is referred to otherwise (input, input[0], the index of the element out of the input)
+func queryInputKinds(query ast.Body) (map[string]bool, bool) {
+	constrained := map[ast.Var]bool{}
+	kinds := map[string]bool{}
+	for _, expr := range query {
+		if index, kind, ok := inputKindConstraint(expr); ok {
+			constrained[index] = true
+			kinds[kind] = true
+		}
+	}
+
+	ok := true
+	var visitor *ast.GenericVisitor
+	visitor = ast.NewGenericVisitor(func(x interface{}) bool {
+		switch x := x.(type) {
+		case *ast.Expr:
+			if x.Negated && refersToInput(x) {
+				ok = false
+			}
+		case ast.Ref:
+			if !x.HasPrefix(ast.InputRootRef) {
+				return false
+			}
+			if len(x) < 2 {
+				ok = false
+				return true
+			}
+			if index, isVar := x[1].Value.(ast.Var); !isVar || !constrained[index] {
+				ok = false
+			}
+			for _, term := range x[2:] {
+				visitor.Walk(term)
+			}
+			return true
+		case ast.Var:
+			if constrained[x] {
+				ok = false // the index of the element, changed by the pruning
+			}
+		}
+		return !ok
+	})
+	visitor.Walk(query)
+	return kinds, ok
+}
+
+// inputKindConstraint returns the index and the kind of an expression input[x].kind == "<kind>"
+func inputKindConstraint(expr *ast.Expr) (ast.Var, string, bool) {
+	if expr.Negated || !(expr.IsEquality() || expr.Operator().Equal(ast.Equal.Ref())) {
+		return "", "", false
+	}
+	operands := expr.Operands()
+	if len(operands) != 2 {
+		return "", "", false
+	}
+	for i, operand := range operands {
+		ref, isRef := operand.Value.(ast.Ref)
+		kind, isString := operands[1-i].Value.(ast.String)
+		if !isRef || !isString || len(ref) != 3 || !ref.HasPrefix(ast.InputRootRef) || !ref[2].Equal(ast.StringTerm("kind")) {
+			continue
+		}
+		if index, isVar := ref[1].Value.(ast.Var); isVar {
+			return index, string(kind), true
+		}
+	}
+	return "", "", false
+}
+
+func refersToInput(expr *ast.Expr) bool {
+	found := false
+	ast.WalkRefs(expr, func(ref ast.Ref) bool {
+		if ref.HasPrefix(ast.InputRootRef) {
+			found = true
+		}
+		return found
+	})
+	return found
+}
@@ -0,0 +1,126 @@
+package opaprocessor
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/armosec/armoapi-go/armotypes"
+	"github.com/armosec/opa-utils/reporthandling"
+	"github.com/stretchr/testify/assert"
+)
+
+const nodePortRule = `package armo_builtins
+deny[msga] {
+	service := input[_]
+	service.kind == "Service"
+	service.spec.type == "NodePort"
+	msga := {"alertMessage": "nodeport service", "packagename": "armo_builtins", "alertScore": 9, "failedPaths": ["spec.type"], "fixPaths": [], "alertObject": {"k8sApiObjects": [service]}}
+}`
+
+const workloadsRule = `package armo_builtins
+deny[msga] {
+	wl := input[_]
+	spec_template_spec_patterns := {"Deployment", "DaemonSet"}
+	spec_template_spec_patterns[wl.kind]
+	container := wl.spec.template.spec.containers[i]
+	not container.resources.limits.memory
+	msga := {"alertMessage": "no memory limit", "packagename": "armo_builtins", "alertScore": 4, "failedPaths": [], "fixPaths": [], "alertObject": {"k8sApiObjects": [wl]}}
+}`
+
+const imageRule = `package armo_builtins
+deny[msga] {
+	pod := input[_]
+	pod.kind == "Pod"
+	container := pod.spec.containers[_]
+	container.image == data.postureControlInputs.untrustedImages[_]
+	msga := {"alertMessage": "untrusted image", "packagename": "armo_builtins", "alertScore": 4, "failedPaths": [], "fixPaths": [], "alertObject": {"k8sApiObjects": [pod]}}
+}`
+
+const countRule = `package armo_builtins
+deny[msga] {
+	count(input) > 1
+	msga := {"alertMessage": "too many resources", "packagename": "armo_builtins", "alertScore": 1, "failedPaths": [], "fixPaths": [], "alertObject": {"k8sApiObjects": [input[0]]}}
+}`
+
+func TestRuleKinds(t *testing.T) {
+	tests := []struct {
+		source string
+		inputs map[string][]string
+		want   ruleKinds
+	}{
+		{source: nodePortRule, want: ruleKinds{Prunable: true, Kinds: []string{"Service"}}},
+		{source: workloadsRule, want: ruleKinds{Prunable: true, Kinds: []string{"DaemonSet", "Deployment"}}},
+		{source: imageRule, inputs: map[string][]string{"untrustedImages": {"nginx"}}, want: ruleKinds{Prunable: true, Kinds: []string{"Pod"}}},
+		{source: imageRule, inputs: map[string][]string{}, want: ruleKinds{Prunable: true, Kinds: []string{}}}, // never fails
+		{source: countRule, want: ruleKinds{}},
+	}
+	for i, test := range tests {
+		cache := newRulesCache(filepath.Join(t.TempDir(), ruleAnalysisFile))
+		_, err := cache.get(&reporthandling.PolicyRule{PortalBase: armotypes.PortalBase{Name: "rule"}}, test.source, test.inputs)
+		if assert.NoError(t, err, i) && assert.Len(t, cache.analysis, 1, i) {
+			for _, analysis := range cache.analysis {
+				assert.Equal(t, test.want, analysis, i)
+			}
+		}
+	}
+}
+
+func TestRulesCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ruleAnalysisFile)
+	rule := &reporthandling.PolicyRule{PortalBase: armotypes.PortalBase{Name: "nodeport"}}
+	objects := []map[string]interface{}{
+		{"kind": "Pod", "spec": map[string]interface{}{"type": "NodePort"}},
+		{"kind": "Service", "metadata": map[string]interface{}{"name": "web"}, "spec": map[string]interface{}{"type": "NodePort"}},
+	}
+
+	cache := newRulesCache(path)
+	prepared, err := cache.get(rule, nodePortRule, nil)
+	assert.NoError(t, err)
+	again, err := cache.get(rule, nodePortRule, nil)
+	assert.NoError(t, err)
+	assert.True(t, prepared == again, "the rule is compiled once")
+
+	results, err := prepared.eval(context.Background(), objects)
+	assert.NoError(t, err)
+	if assert.Len(t, results, 1) {
+		assert.Equal(t, "web", results[0].GetFailedResources()[0]["metadata"].(map[string]interface{})["name"])
+	}
+	results, err = prepared.eval(context.Background(), objects[:1]) // no services, not evaluated
+	assert.NoError(t, err)
+	assert.Empty(t, results)
+
+	// the analysis is loaded from the file by the next process
+	cache.save()
+	next := newRulesCache(path)
+	next.load()
+	assert.Equal(t, cache.analysis, next.analysis)
+
+	_, err = cache.get(rule, "package armo_builtins\ndeny[msga] {", nil)
+	assert.Error(t, err)
+}
+
+func TestRulesCacheEviction(t *testing.T) {
+	cache := newRulesCache(filepath.Join(t.TempDir(), ruleAnalysisFile))
+	nodePort := &reporthandling.PolicyRule{PortalBase: armotypes.PortalBase{Name: "nodeport"}}
+	workloads := &reporthandling.PolicyRule{PortalBase: armotypes.PortalBase{Name: "workloads"}}
+
+	_, err := cache.get(workloads, workloadsRule, nil)
+	assert.NoError(t, err)
+	prepared, err := cache.get(nodePort, nodePortRule, nil)
+	assert.NoError(t, err)
+	for i := 0; i < rulesCacheIdleScans; i++ {
+		cache.endScan()
+		_, err := cache.get(nodePort, nodePortRule, nil)
+		assert.NoError(t, err)
+	}
+	assert.Len(t, cache.rules, 2)
+
+	// the rule which was not used by the last scans is evicted, the used one is kept
+	cache.endScan()
+	assert.Len(t, cache.rules, 1)
+	assert.Len(t, cache.lastUsed, 1)
+	again, err := cache.get(nodePort, nodePortRule, nil)
+	assert.NoError(t, err)
+	assert.True(t, prepared == again)
+}
@@ -0,0 +1,76 @@
+package opaprocessor
+
+import (
+	"context"
+	"sort"
+
+	"github.com/armosec/k8s-interface/workloadinterface"
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/cautils/logger/helpers"
+	"github.com/armosec/kubescape/exceptionshandler"
+	"github.com/armosec/opa-utils/reporthandling"
+	"github.com/armosec/opa-utils/reporthandling/results/v1/resourcesresults"
+	"github.com/armosec/opa-utils/resources"
+)
+
+// ResourceEvaluator evaluates the controls of a session on single resources, e.g. the resources of admission requests.
+// The controls are evaluated on the resource only, the related resources of the cluster are not part of the evaluation
+type ResourceEvaluator struct {
+	sessionObj           *cautils.OPASessionObj
+	controls             []reporthandling.Control
+	regoDependenciesData *resources.RegoDependenciesData
+}
+
+// NewResourceEvaluator the session should hold the policies (frameworks, exceptions and controls inputs), the resources of the session are ignored
+func NewResourceEvaluator(sessionObj *cautils.OPASessionObj, regoDependenciesData *resources.RegoDependenciesData) *ResourceEvaluator {
+	if regoDependenciesData != nil {
+		regoDependenciesData.PostureControlInputs = sessionObj.RegoInputData.PostureControlInputs
+	}
+	policies := ConvertFrameworksToPolicies(sessionObj.Frameworks, cautils.BuildNumber)
+	controls := make([]reporthandling.Control, 0, len(policies.Controls))
+	for _, control := range policies.Controls {
+		controls = append(controls, control)
+	}
+	sort.Slice(controls, func(i, j int) bool { return controls[i].ControlID < controls[j].ControlID })
+
+	return &ResourceEvaluator{
+		sessionObj:           sessionObj,
+		controls:             controls,
+		regoDependenciesData: regoDependenciesData,
+	}
+}
+
+// Evaluate returns the controls failed by the resource, sorted by control ID. The excluded controls (exceptions) are not failures.
+// Safe for concurrent use, each evaluation works on its own session
+func (evaluator *ResourceEvaluator) Evaluate(groupResource string, resource workloadinterface.IMetadata) []reporthandling.Control {
+	k8sResources := cautils.K8SResources{groupResource: []string{resource.GetID()}}
+	sessionObj := cautils.NewOPASessionObj(evaluator.sessionObj.Frameworks, &k8sResources)
+	sessionObj.AllResources[resource.GetID()] = resource
+	sessionObj.Exceptions = evaluator.sessionObj.Exceptions
+	sessionObj.RegoInputData = evaluator.sessionObj.RegoInputData
+	opap := &OPAProcessor{ctx: context.Background(), OPASessionObj: sessionObj, regoDependenciesData: evaluator.regoDependenciesData}
+
+	failed := []reporthandling.Control{}
+	for i := range evaluator.controls {
+		control := &evaluator.controls[i]
+		if !controlMatchesResources(control, map[string]bool{groupResource: true}) {
+			continue
+		}
+		resourcesAssociatedControl, err := opap.processControl(control)
+		if err != nil {
+			logger.L().Error("failed to evaluate control", helpers.String("control", control.ControlID), helpers.Error(err))
+			continue
+		}
+		controlResult, ok := resourcesAssociatedControl[resource.GetID()]
+		if !ok {
+			continue
+		}
+		result := resourcesresults.Result{ResourceID: resource.GetID(), AssociatedControls: []resourcesresults.ResourceAssociatedControl{controlResult}}
+		exceptionshandler.SetExceptions(&result, resource, sessionObj.Exceptions, cautils.ClusterName)
+		if result.AssociatedControls[0].GetStatus(nil).IsFailed() {
+			failed = append(failed, *control)
+		}
+	}
+	return failed
+}
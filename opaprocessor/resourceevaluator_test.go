@@ -0,0 +1,32 @@
+package opaprocessor
+
+import (
+	"testing"
+
+	"github.com/armosec/armoapi-go/armotypes"
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/mocks"
+	"github.com/armosec/opa-utils/reporthandling"
+	"github.com/armosec/opa-utils/resources"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResourceEvaluator(t *testing.T) {
+	opaSessionObj := cautils.NewOPASessionObjMock()
+	opaSessionObj.Frameworks = []reporthandling.Framework{*mocks.MockFramework_0006_0013()}
+	evaluator := NewResourceEvaluator(opaSessionObj, resources.NewRegoDependenciesDataMock())
+
+	// C-0006 (hostPath) fails, C-0013 (non-root) passes
+	failed := evaluator.Evaluate("apps/v1/deployments", mocks.MockDevelopmentWithHostpath())
+	if assert.Len(t, failed, 1) {
+		assert.Equal(t, "C-0006", failed[0].ControlID)
+	}
+
+	// the controls are not evaluated on other resources
+	assert.Empty(t, evaluator.Evaluate("/v1/services", mocks.MockDevelopmentWithHostpath()))
+
+	// an exception of the resource
+	opaSessionObj.Exceptions = []armotypes.PostureExceptionPolicy{*mocks.MockExceptionAllKinds(&armotypes.PosturePolicy{ControlID: "C-0006"})}
+	evaluator = NewResourceEvaluator(opaSessionObj, resources.NewRegoDependenciesDataMock())
+	assert.Empty(t, evaluator.Evaluate("apps/v1/deployments", mocks.MockDevelopmentWithHostpath()))
+}
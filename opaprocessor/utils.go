@@ -1,6 +1,8 @@
 package opaprocessor
 
 import (
+	"sort"
+
 	"github.com/armosec/kubescape/cautils"
 	"github.com/armosec/opa-utils/reporthandling"
 	"github.com/armosec/opa-utils/reporthandling/results/v1/reportsummary"
@@ -41,5 +43,9 @@ func ConvertFrameworksToSummaryDetails(summaryDetails *reportsummary.SummaryDeta
 			})
 		}
 	}
+	// the frameworks are listed by name in all of the outputs
+	sort.Slice(summaryDetails.Frameworks, func(i, j int) bool {
+		return summaryDetails.Frameworks[i].Name < summaryDetails.Frameworks[j].Name
+	})
 
 }
@@ -27,4 +27,6 @@ func TestInitializeSummaryDetails(t *testing.T) {
 	ConvertFrameworksToSummaryDetails(&summaryDetails, frameworks, policies)
 	assert.Equal(t, 2, len(summaryDetails.Frameworks))
 	assert.Equal(t, 3, len(summaryDetails.Controls))
+	// the frameworks are sorted by name
+	assert.True(t, summaryDetails.Frameworks[0].Name < summaryDetails.Frameworks[1].Name)
 }
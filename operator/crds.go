@@ -0,0 +1,72 @@
+package operator
+
+import (
+	"context"
+	"embed"
+	"fmt"
+
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/cautils/logger/helpers"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	Group   = "kubescape.cloud"
+	Version = "v1alpha1"
+
+	// ScheduleLabel the label of the reports, set to the name of the schedule which created the report
+	ScheduleLabel = Group + "/schedule"
+)
+
+var (
+	ScanScheduleResource = schema.GroupVersionResource{Group: Group, Version: Version, Resource: "scanschedules"}
+	ScanReportResource   = schema.GroupVersionResource{Group: Group, Version: Version, Resource: "scanreports"}
+
+	crdResource = schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}
+)
+
+//go:embed crds/*.yaml
+var crds embed.FS
+
+// InstallCRDs creates the ScanSchedule and the ScanReport CRDs, or updates them when already installed
+func InstallCRDs(client dynamic.Interface) error {
+	files, err := crds.ReadDir("crds")
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		data, err := crds.ReadFile("crds/" + file.Name())
+		if err != nil {
+			return err
+		}
+		crd := &unstructured.Unstructured{}
+		if err := yaml.Unmarshal(data, &crd.Object); err != nil {
+			return fmt.Errorf("failed to parse CRD '%s': %w", file.Name(), err)
+		}
+		if err := applyCRD(client, crd); err != nil {
+			return fmt.Errorf("failed to install CRD '%s': %w", crd.GetName(), err)
+		}
+		logger.L().Debug("CRD installed", helpers.String("name", crd.GetName()))
+	}
+	return nil
+}
+
+func applyCRD(client dynamic.Interface, crd *unstructured.Unstructured) error {
+	crds := client.Resource(crdResource)
+	current, err := crds.Get(context.Background(), crd.GetName(), metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		_, err = crds.Create(context.Background(), crd, metav1.CreateOptions{})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	crd.SetResourceVersion(current.GetResourceVersion())
+	_, err = crds.Update(context.Background(), crd, metav1.UpdateOptions{})
+	return err
+}
@@ -0,0 +1,123 @@
+package operator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/opa-utils/reporthandling/apis"
+	"github.com/armosec/opa-utils/reporthandling/results/v1/reportsummary"
+	reporthandlingv2 "github.com/armosec/opa-utils/reporthandling/v2"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func scanScheduleObject(schedule string, historyLimit int64) *unstructured.Unstructured {
+	spec := map[string]interface{}{
+		"schedule":          schedule,
+		"frameworks":        []interface{}{"nsa"},
+		"excludeNamespaces": []interface{}{"kube-system"},
+	}
+	if historyLimit > 0 {
+		spec["historyLimit"] = historyLimit
+	}
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": Group + "/" + Version,
+		"kind":       "ScanSchedule",
+		"metadata":   map[string]interface{}{"name": "nsa", "namespace": "kubescape", "uid": "1234"},
+		"spec":       spec,
+	}}
+}
+
+func TestParseScanSchedule(t *testing.T) {
+	schedule, err := ParseScanSchedule(scanScheduleObject("0 */6 * * *", 0))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"nsa"}, schedule.Spec.Frameworks)
+	assert.Equal(t, []string{"kube-system"}, schedule.Spec.ExcludeNamespaces)
+	assert.Equal(t, defaultHistoryLimit, schedule.Spec.HistoryLimit)
+	assert.Equal(t, "1234", string(schedule.UID))
+
+	schedule, err = ParseScanSchedule(scanScheduleObject("@daily", 2))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, schedule.Spec.HistoryLimit)
+
+	_, err = ParseScanSchedule(scanScheduleObject("every 6 hours", 0))
+	assert.Error(t, err)
+}
+
+func TestNewScanReport(t *testing.T) {
+	schedule, err := ParseScanSchedule(scanScheduleObject("@daily", 0))
+	assert.NoError(t, err)
+	opaSessionObj := &cautils.OPASessionObj{Report: &reporthandlingv2.PostureReport{ClusterName: "prod"}}
+	opaSessionObj.Report.SummaryDetails = reportsummary.SummaryDetails{
+		Score:            40,
+		Frameworks:       []reportsummary.FrameworkSummary{{Name: "NSA", Score: 40}},
+		ResourceCounters: reportsummary.ResourceCounters{PassedResources: 3, FailedResources: 2, ExcludedResources: 1},
+		Controls: reportsummary.ControlSummaries{
+			"C-0002": {ControlID: "C-0002", Name: "passed", Status: apis.StatusPassed, ResourceCounters: reportsummary.ResourceCounters{PassedResources: 6}},
+			"C-0001": {ControlID: "C-0001", Name: "failed", Status: apis.StatusFailed, ScoreFactor: 8, ResourceCounters: reportsummary.ResourceCounters{PassedResources: 3, FailedResources: 2, ExcludedResources: 1}},
+			"C-0003": {ControlID: "C-0003", Name: "excluded", Status: apis.StatusExcluded, ScoreFactor: 2, ResourceCounters: reportsummary.ResourceCounters{PassedResources: 5, ExcludedResources: 1}},
+		},
+	}
+
+	report := NewScanReport(schedule, opaSessionObj, time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC))
+	assert.Equal(t, "nsa-20220102-030405", report.Name)
+	assert.Equal(t, "kubescape", report.Namespace)
+	assert.Equal(t, "nsa", report.Labels[ScheduleLabel])
+	assert.Equal(t, schedule.UID, report.OwnerReferences[0].UID)
+	assert.Equal(t, ScanReportSummary{RiskScore: 40, FailedControls: 1, FailedResources: 2, ExcludedResources: 1, PassedResources: 3, TotalResources: 6}, report.Summary)
+	assert.Equal(t, []ScanReportScore{{Name: "NSA", RiskScore: 40}}, report.Frameworks)
+	if assert.Len(t, report.Controls, 2) {
+		assert.Equal(t, ScanReportControl{ControlID: "C-0001", Name: "failed", Severity: "High", Status: "failed", FailedResources: 2, ExcludedResources: 1, TotalResources: 6}, report.Controls[0])
+		assert.Equal(t, "excluded", report.Controls[1].Status)
+	}
+
+	// the report is a valid unstructured object
+	_, err = runtime.DefaultUnstructuredConverter.ToUnstructured(report)
+	assert.NoError(t, err)
+}
+
+func TestCreateScanReport(t *testing.T) {
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		ScanReportResource:   "ScanReportList",
+		ScanScheduleResource: "ScanScheduleList",
+	})
+	schedule, err := ParseScanSchedule(scanScheduleObject("@daily", 2))
+	assert.NoError(t, err)
+
+	opaSessionObj := &cautils.OPASessionObj{Report: &reporthandlingv2.PostureReport{}}
+	scanTime := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, CreateScanReport(client, schedule, NewScanReport(schedule, opaSessionObj, scanTime.Add(time.Duration(i)*time.Hour))))
+	}
+
+	list, err := client.Resource(ScanReportResource).Namespace("kubescape").List(context.Background(), metav1.ListOptions{})
+	assert.NoError(t, err)
+	names := []string{}
+	for i := range list.Items {
+		names = append(names, list.Items[i].GetName())
+	}
+	assert.ElementsMatch(t, []string{"nsa-20220101-010000", "nsa-20220101-020000"}, names)
+}
+
+func TestInstallCRDs(t *testing.T) {
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		crdResource: "CustomResourceDefinitionList",
+	})
+	// installed, then updated
+	assert.NoError(t, InstallCRDs(client))
+	assert.NoError(t, InstallCRDs(client))
+
+	for _, name := range []string{"scanschedules.kubescape.cloud", "scanreports.kubescape.cloud"} {
+		crd, err := client.Resource(crdResource).Get(context.Background(), name, metav1.GetOptions{})
+		if assert.NoError(t, err) {
+			group, _, _ := unstructured.NestedString(crd.Object, "spec", "group")
+			assert.Equal(t, Group, group)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/cautils/logger/helpers"
+	"github.com/armosec/opa-utils/reporthandling/apis"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+)
+
+// ScanReport the summary of a scan of a ScanSchedule, listed by 'kubectl get scanreports'
+type ScanReport struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	ScanTime    metav1.Time         `json:"scanTime"`
+	ClusterName string              `json:"clusterName,omitempty"`
+	Frameworks  []ScanReportScore   `json:"frameworks,omitempty"`
+	Summary     ScanReportSummary   `json:"summary"`
+	Controls    []ScanReportControl `json:"controls,omitempty"`
+}
+
+type ScanReportScore struct {
+	Name      string  `json:"name"`
+	RiskScore float64 `json:"riskScore"`
+}
+
+type ScanReportSummary struct {
+	RiskScore         float64 `json:"riskScore"`
+	FailedControls    int64   `json:"failedControls"`
+	FailedResources   int64   `json:"failedResources"`
+	ExcludedResources int64   `json:"excludedResources"`
+	PassedResources   int64   `json:"passedResources"`
+	TotalResources    int64   `json:"totalResources"`
+}
+
+// ScanReportControl a failed or an excluded control, the passed controls are not listed
+type ScanReportControl struct {
+	ControlID         string `json:"controlID"`
+	Name              string `json:"name"`
+	Severity          string `json:"severity"`
+	Status            string `json:"status"`
+	FailedResources   int64  `json:"failedResources"`
+	ExcludedResources int64  `json:"excludedResources"`
+	TotalResources    int64  `json:"totalResources"`
+}
+
+// NewScanReport returns the report of the scan, owned by the schedule - the reports are deleted with their schedule
+func NewScanReport(schedule *ScanSchedule, opaSessionObj *cautils.OPASessionObj, scanTime time.Time) *ScanReport {
+	summaryDetails := &opaSessionObj.Report.SummaryDetails
+	counters := summaryDetails.NumberOfResources()
+	report := &ScanReport{
+		TypeMeta: metav1.TypeMeta{APIVersion: Group + "/" + Version, Kind: "ScanReport"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-%s", schedule.Name, scanTime.UTC().Format("20060102-150405")),
+			Namespace: schedule.Namespace,
+			Labels:    map[string]string{ScheduleLabel: schedule.Name},
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: Group + "/" + Version,
+				Kind:       "ScanSchedule",
+				Name:       schedule.Name,
+				UID:        schedule.UID,
+			}},
+		},
+		ScanTime:    metav1.NewTime(scanTime.UTC()),
+		ClusterName: opaSessionObj.Report.ClusterName,
+		Frameworks:  []ScanReportScore{},
+		Summary: ScanReportSummary{
+			RiskScore:         float64(summaryDetails.Score),
+			FailedResources:   int64(counters.Failed()),
+			ExcludedResources: int64(counters.Excluded()),
+			PassedResources:   int64(counters.Passed()),
+			TotalResources:    int64(counters.All()),
+		},
+		Controls: []ScanReportControl{},
+	}
+	for _, framework := range summaryDetails.Frameworks {
+		report.Frameworks = append(report.Frameworks, ScanReportScore{Name: framework.GetName(), RiskScore: float64(framework.GetScore())})
+	}
+
+	controlIDs := summaryDetails.Controls.GetIDs()
+	sort.Strings(controlIDs)
+	for _, controlID := range controlIDs {
+		control := summaryDetails.Controls[controlID]
+		status := control.GetStatus().Status()
+		if status != apis.StatusFailed && status != apis.StatusExcluded {
+			continue
+		}
+		if status == apis.StatusFailed {
+			report.Summary.FailedControls++
+		}
+		report.Controls = append(report.Controls, ScanReportControl{
+			ControlID:         controlID,
+			Name:              control.GetName(),
+			Severity:          cautils.ControlSeverityToString(control.GetScoreFactor()),
+			Status:            string(status),
+			FailedResources:   int64(control.NumberOfResources().Failed()),
+			ExcludedResources: int64(control.NumberOfResources().Excluded()),
+			TotalResources:    int64(control.NumberOfResources().All()),
+		})
+	}
+	return report
+}
+
+// CreateScanReport creates the report and deletes the oldest reports of the schedule, keeping the history limit of the schedule
+func CreateScanReport(client dynamic.Interface, schedule *ScanSchedule, report *ScanReport) error {
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(report)
+	if err != nil {
+		return err
+	}
+	reports := client.Resource(ScanReportResource).Namespace(schedule.Namespace)
+	if _, err := reports.Create(context.Background(), &unstructured.Unstructured{Object: obj}, metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("failed to create ScanReport '%s/%s': %w", report.Namespace, report.Name, err)
+	}
+
+	list, err := reports.List(context.Background(), metav1.ListOptions{LabelSelector: fmt.Sprintf("%s=%s", ScheduleLabel, schedule.Name)})
+	if err != nil {
+		return fmt.Errorf("failed to list the ScanReports of '%s/%s': %w", schedule.Namespace, schedule.Name, err)
+	}
+	for _, name := range expiredReports(list.Items, schedule.Spec.HistoryLimit) {
+		if err := reports.Delete(context.Background(), name, metav1.DeleteOptions{}); err != nil {
+			logger.L().Warning("failed to delete expired ScanReport", helpers.String("name", name), helpers.Error(err))
+		}
+	}
+	return nil
+}
+
+// expiredReports returns the names of the reports beyond the history limit, the newest reports are kept
+func expiredReports(reports []unstructured.Unstructured, historyLimit int) []string {
+	if len(reports) <= historyLimit {
+		return nil
+	}
+	sorted := append([]unstructured.Unstructured{}, reports...)
+	sort.Slice(sorted, func(i, j int) bool {
+		// the names end with the scan time, the creation timestamp has a resolution of seconds
+		ti, tj := sorted[i].GetCreationTimestamp(), sorted[j].GetCreationTimestamp()
+		if !ti.Equal(&tj) {
+			return tj.Before(&ti)
+		}
+		return sorted[i].GetName() > sorted[j].GetName()
+	})
+	names := []string{}
+	for i := historyLimit; i < len(sorted); i++ {
+		names = append(names, sorted[i].GetName())
+	}
+	return names
+}
+
+// ScanReportPrinter is a printer that persists the results of a scheduled scan as a ScanReport.
+// Err returns the error of the last persisted report
+type ScanReportPrinter struct {
+	client   dynamic.Interface
+	schedule *ScanSchedule
+	report   string
+	err      error
+}
+
+func NewScanReportPrinter(client dynamic.Interface, schedule *ScanSchedule) *ScanReportPrinter {
+	return &ScanReportPrinter{client: client, schedule: schedule}
+}
+
+func (reportPrinter *ScanReportPrinter) SetWriter(outputFile string) {}
+
+func (reportPrinter *ScanReportPrinter) Score(score float32) {}
+
+func (reportPrinter *ScanReportPrinter) ActionPrint(opaSessionObj *cautils.OPASessionObj) error {
+	report := NewScanReport(reportPrinter.schedule, opaSessionObj, time.Now())
+	if reportPrinter.err = CreateScanReport(reportPrinter.client, reportPrinter.schedule, report); reportPrinter.err != nil {
+		return nil // the results were scanned, the failure of persisting them is reported by Err
+	}
+	reportPrinter.report = report.Name
+	logger.L().Success("ScanReport created", helpers.String("name", fmt.Sprintf("%s/%s", report.Namespace, report.Name)))
+	return nil
+}
+
+// Report returns the name of the created report, empty if the report was not created
+func (reportPrinter *ScanReportPrinter) Report() string {
+	return reportPrinter.report
+}
+
+func (reportPrinter *ScanReportPrinter) Err() error {
+	return reportPrinter.err
+}
@@ -0,0 +1,100 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/robfig/cron/v3"
+)
+
+const defaultHistoryLimit = 5
+
+// ScanSchedule scans the cluster periodically, the results of each scan are persisted as a ScanReport
+type ScanSchedule struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ScanScheduleSpec   `json:"spec"`
+	Status ScanScheduleStatus `json:"status,omitempty"`
+}
+
+type ScanScheduleSpec struct {
+	Schedule          string   `json:"schedule"`                    // cron expression
+	Frameworks        []string `json:"frameworks,omitempty"`        // all of the frameworks when empty
+	Namespaces        []string `json:"namespaces,omitempty"`        // all of the namespaces when empty
+	ExcludeNamespaces []string `json:"excludeNamespaces,omitempty"` //
+	HistoryLimit      int      `json:"historyLimit,omitempty"`      // number of reports kept per schedule
+	Suspend           bool     `json:"suspend,omitempty"`
+}
+
+type ScanScheduleStatus struct {
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+	LastReport       string       `json:"lastReport,omitempty"`
+	LastError        string       `json:"lastError,omitempty"`
+}
+
+// ParseScanSchedule converts the object to a schedule and validates the cron expression
+func ParseScanSchedule(obj *unstructured.Unstructured) (*ScanSchedule, error) {
+	schedule := &ScanSchedule{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, schedule); err != nil {
+		return nil, fmt.Errorf("failed to parse ScanSchedule '%s/%s': %w", obj.GetNamespace(), obj.GetName(), err)
+	}
+	if _, err := cron.ParseStandard(schedule.Spec.Schedule); err != nil {
+		return nil, fmt.Errorf("ScanSchedule '%s/%s': invalid schedule '%s': %w", obj.GetNamespace(), obj.GetName(), schedule.Spec.Schedule, err)
+	}
+	if schedule.Spec.HistoryLimit <= 0 {
+		schedule.Spec.HistoryLimit = defaultHistoryLimit
+	}
+	return schedule, nil
+}
+
+// ListScanSchedules lists the schedules of the namespace, of all of the namespaces when the namespace is empty.
+// The invalid schedules are returned as errors and are not part of the list
+func ListScanSchedules(client dynamic.Interface, namespace string) ([]ScanSchedule, []error, error) {
+	list, err := client.Resource(ScanScheduleResource).Namespace(namespace).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+	schedules := []ScanSchedule{}
+	errs := []error{}
+	for i := range list.Items {
+		schedule, err := ParseScanSchedule(&list.Items[i])
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		schedules = append(schedules, *schedule)
+	}
+	return schedules, errs, nil
+}
+
+// UpdateScanScheduleStatus sets the status of the latest scan of the schedule
+func UpdateScanScheduleStatus(client dynamic.Interface, schedule *ScanSchedule, scheduleTime time.Time, report string, scanErr error) error {
+	schedules := client.Resource(ScanScheduleResource).Namespace(schedule.Namespace)
+	current, err := schedules.Get(context.Background(), schedule.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	// the last report is kept when the scan failed
+	status, _, _ := unstructured.NestedMap(current.Object, "status")
+	if status == nil {
+		status = map[string]interface{}{}
+	}
+	status["lastScheduleTime"] = scheduleTime.UTC().Format(time.RFC3339)
+	if report != "" {
+		status["lastReport"] = report
+	}
+	delete(status, "lastError")
+	if scanErr != nil {
+		status["lastError"] = scanErr.Error()
+	}
+	current.Object["status"] = status
+	_, err = schedules.UpdateStatus(context.Background(), current, metav1.UpdateOptions{})
+	return err
+}
@@ -0,0 +1,157 @@
+// Package kubescape is the Go API of the kubescape scans, for embedding the scans in other programs without executing the CLI
+package kubescape
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/clihandler"
+	"github.com/armosec/kubescape/opaprocessor"
+	"github.com/armosec/kubescape/resourcehandler"
+	printerv2 "github.com/armosec/kubescape/resultshandling/printer/v2"
+	"github.com/armosec/opa-utils/reporthandling"
+	"github.com/armosec/opa-utils/reporthandling/results/v1/reportsummary"
+	reporthandlingv2 "github.com/armosec/opa-utils/reporthandling/v2"
+)
+
+// ScanRequest the scan of frameworks or of controls, the same as the scan command
+type ScanRequest struct {
+	Frameworks        []string // all of the frameworks when neither frameworks nor controls are set
+	Controls          []string // the IDs or the names of the controls
+	InputPatterns     []string // the scanned files, directories or URLs. The cluster of the kube context is scanned when none
+	IncludeNamespaces []string // supports globs and /regex/ patterns
+	ExcludeNamespaces []string // supports globs and /regex/ patterns
+	KubeContext       string   // the current context when empty
+	Kubeconfig        string   // $KUBECONFIG or ~/.kube/config when empty, or the service account when running in-cluster
+	Cluster           string   // the cluster of the kubeconfig, the cluster of the context when empty
+	UseFrom           []string // the files of the policies, downloaded from the released policies when none
+	Exceptions        string   // the file of the exceptions
+	ControlsInputs    string   // the file of the inputs of the configurable controls
+	Account           string   // the ARMO portal account, the results are not submitted unless Submit is set
+	Submit            bool
+	HostSensor        bool     // deploy the host sensor DaemonSet for the controls of the nodes
+	IncludePassed     bool     // list the passed and the skipped resources of every control in the json, html and pdf formats
+	Redact            []string // the sensitive data redacted from the resources of the json and html formats - secrets/env/annotations
+	OmitRawResources  bool     // keep only the references of the resources (apiVersion, kind, name, namespace) in the json and html formats
+}
+
+// Validate returns an error of a request scanning both frameworks and controls, or both including and excluding namespaces
+func (request *ScanRequest) Validate() error {
+	if len(request.Frameworks) > 0 && len(request.Controls) > 0 {
+		return fmt.Errorf("bad request: scan frameworks or controls, but not both")
+	}
+	if len(request.IncludeNamespaces) > 0 && len(request.ExcludeNamespaces) > 0 {
+		return fmt.Errorf("bad request: set the included or the excluded namespaces, but not both")
+	}
+	if err := (&cautils.RedactOptions{Redact: request.Redact}).Validate(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// scanInfo returns the scan info of the request, with the defaults of the flags of the scan command. Nothing is printed
+func (request *ScanRequest) scanInfo() *cautils.ScanInfo {
+	scanInfo := &cautils.ScanInfo{
+		InputPatterns:      request.InputPatterns,
+		IncludeNamespaces:  strings.Join(request.IncludeNamespaces, ","),
+		ExcludedNamespaces: strings.Join(request.ExcludeNamespaces, ","),
+		KubeContext:        request.KubeContext,
+		Kubeconfig:         request.Kubeconfig,
+		KubeCluster:        request.Cluster,
+		UseFrom:            request.UseFrom,
+		UseExceptions:      request.Exceptions,
+		ControlsInputs:     request.ControlsInputs,
+		Account:            request.Account,
+		Submit:             request.Submit,
+		Local:              !request.Submit,
+		IncludePassed:      request.IncludePassed,
+		RedactOptions:      cautils.RedactOptions{Redact: request.Redact, OmitRawResources: request.OmitRawResources},
+		Silent:             true,
+		FormatVersion:      "v2",
+		OwnerLabel:         "team",
+		FailThreshold:      100,
+		FetchConcurrency:   resourcehandler.DefaultFetchConcurrency,
+		FetchQPS:           resourcehandler.DefaultFetchQPS,
+		FetchPageSize:      resourcehandler.DefaultFetchPageSize,
+		CacheMaxAge:        resourcehandler.DefaultCacheMaxAge,
+		EvalConcurrency:    opaprocessor.DefaultEvalConcurrency,
+	}
+	scanInfo.HostSensorEnabled.SetBool(request.HostSensor)
+
+	scanInfo.ScanAll = len(request.Frameworks) == 0 && len(request.Controls) == 0
+	if len(request.Controls) > 0 {
+		scanInfo.SetPolicyIdentifiers(request.Controls, reporthandling.KindControl)
+	} else {
+		scanInfo.FrameworkScan = true
+		scanInfo.SetPolicyIdentifiers(request.Frameworks, reporthandling.KindFramework)
+	}
+	return scanInfo
+}
+
+// Results the results of a completed scan
+type Results struct {
+	opaSessionObj *cautils.OPASessionObj
+}
+
+// RiskScore returns the risk-score of the scan, 0 (excellent) to 100 (all failed)
+func (results *Results) RiskScore() float32 {
+	return results.opaSessionObj.Report.SummaryDetails.Score
+}
+
+// Summary returns the summary of the frameworks and of the controls of the scan
+func (results *Results) Summary() *reportsummary.SummaryDetails {
+	return &results.opaSessionObj.Report.SummaryDetails
+}
+
+// Report returns the report of the scan, the results of the resources with the summary
+func (results *Results) Report() *reporthandlingv2.PostureReport {
+	return results.opaSessionObj.Report
+}
+
+// Encode writes the results in the output format, one of Formats
+func (results *Results) Encode(writer io.Writer, format string) error {
+	encoder, err := printerv2.GetEncoder(format)
+	if err != nil {
+		return err
+	}
+	return encoder(writer, results.opaSessionObj)
+}
+
+// Formats returns the output formats of the results, e.g. json, sarif, html or pdf
+func Formats() []string {
+	return printerv2.EncoderFormats()
+}
+
+// Scan runs the scan of the request and returns its results.
+// The scan is cancelled when the context is done, the in-flight requests to the cluster are cancelled and the host sensor is removed
+func Scan(ctx context.Context, request *ScanRequest) (*Results, error) {
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+	scanInfo := request.scanInfo()
+	scanInfo.Init()
+	cautils.SetSilentMode(scanInfo.Silent)
+	if err := cautils.SetKubeconfig(scanInfo.Kubeconfig, scanInfo.KubeContext, scanInfo.KubeCluster); err != nil {
+		return nil, err
+	}
+
+	opaSessionObj, err := clihandler.ScanSession(ctx, scanInfo)
+	if err != nil {
+		return nil, err
+	}
+	return &Results{opaSessionObj: opaSessionObj}, nil
+}
+
+// LoadFrameworks returns the frameworks of the names, with their controls, all of the frameworks when none.
+// The frameworks are loaded from the files of useFrom, downloaded from the released policies when none
+func LoadFrameworks(names []string, useFrom []string) ([]reporthandling.Framework, error) {
+	return clihandler.LoadFrameworks(names, useFrom)
+}
+
+// ListFrameworks returns the names of the frameworks, of the files of useFrom or of the released policies when none
+func ListFrameworks(useFrom []string) []string {
+	return clihandler.ListFrameworks(useFrom)
+}
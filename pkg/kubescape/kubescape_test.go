@@ -0,0 +1,52 @@
+package kubescape
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/armosec/kubescape/cautils"
+	reporthandlingv2 "github.com/armosec/opa-utils/reporthandling/v2"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		request ScanRequest
+		valid   bool
+	}{
+		{request: ScanRequest{}, valid: true},
+		{request: ScanRequest{Frameworks: []string{"nsa"}, ExcludeNamespaces: []string{"kube-system"}}, valid: true},
+		{request: ScanRequest{Frameworks: []string{"nsa"}, Controls: []string{"C-0005"}}},
+		{request: ScanRequest{IncludeNamespaces: []string{"default"}, ExcludeNamespaces: []string{"kube-system"}}},
+	}
+	for i := range tests {
+		if err := tests[i].request.Validate(); (err == nil) != tests[i].valid {
+			t.Errorf("request %d: expected valid %v, received %v", i, tests[i].valid, err)
+		}
+	}
+}
+
+func TestScanInfo(t *testing.T) {
+	scanInfo := (&ScanRequest{Controls: []string{"C-0005", "C-0038"}}).scanInfo()
+	if scanInfo.ScanAll || scanInfo.FrameworkScan || len(scanInfo.PolicyIdentifier) != 2 {
+		t.Errorf("expected a scan of 2 controls, received %+v", scanInfo.PolicyIdentifier)
+	}
+	if enabled := scanInfo.HostSensorEnabled.Get(); enabled == nil || *enabled {
+		t.Error("expected the host sensor disabled")
+	}
+
+	scanInfo = (&ScanRequest{}).scanInfo()
+	if !scanInfo.ScanAll || !scanInfo.FrameworkScan {
+		t.Error("expected a scan of all of the frameworks")
+	}
+}
+
+func TestEncode(t *testing.T) {
+	results := &Results{opaSessionObj: &cautils.OPASessionObj{Report: &reporthandlingv2.PostureReport{}}}
+	if err := results.Encode(&bytes.Buffer{}, "pretty-printer"); err == nil {
+		t.Error("expected an error of an unsupported format")
+	}
+	buf := &bytes.Buffer{}
+	if err := results.Encode(buf, "json"); err != nil || buf.Len() == 0 {
+		t.Errorf("expected the json of the results, received %v", err)
+	}
+}
@@ -1,10 +1,18 @@
 package policyhandler
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/cautils/getter"
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/cautils/logger/helpers"
 	"github.com/armosec/kubescape/resourcehandler"
+	"github.com/armosec/kubescape/sbom"
 	"github.com/armosec/opa-utils/reporthandling"
 )
 
@@ -24,34 +32,134 @@ func NewPolicyHandler(processPolicy *chan *cautils.OPASessionObj, resourceHandle
 	}
 }
 
-func (policyHandler *PolicyHandler) HandleNotificationRequest(notification *reporthandling.PolicyNotification, scanInfo *cautils.ScanInfo) error {
+// HandleNotificationRequest downloads the policies and fetches the resources of the scan, each phase is bound by its timeout.
+// The resources fetch is cancelled when the context is done, the download of the policies is abandoned
+func (policyHandler *PolicyHandler) HandleNotificationRequest(ctx context.Context, notification *reporthandling.PolicyNotification, scanInfo *cautils.ScanInfo) error {
+	start := time.Now()
 	opaSessionObj := cautils.NewOPASessionObj(nil, nil)
+	opaSessionObj.OwnerLabel = scanInfo.OwnerLabel
+	opaSessionObj.KeepOwnedResults = scanInfo.KeepOwnedResults
+	opaSessionObj.IncludePassed = scanInfo.IncludePassed || scanInfo.VerboseMode
+	opaSessionObj.Redact = &scanInfo.RedactOptions
+	location, err := scanInfo.TimestampOptions.Location()
+	if err != nil {
+		return err
+	}
+	opaSessionObj.ScanTimes = cautils.ScanTimes{Start: start.UTC(), Location: location}
 	// validate notification
 	// TODO
 	policyHandler.getters = &scanInfo.Getters
 
 	// get policies
-	if err := policyHandler.getPolicies(notification, opaSessionObj); err != nil {
-		return err
+	downloadCtx, cancel := cautils.PhaseContext(ctx, scanInfo.Timeouts.Download)
+	err = cautils.RunWithContext(downloadCtx, func() error { return policyHandler.getPolicies(notification, opaSessionObj) })
+	cancel()
+	if err != nil {
+		return cautils.PhaseError(ctx, cautils.PhasePoliciesDownload, scanInfo.Timeouts.Download, err)
+	}
+	if scanInfo.LockFile != "" {
+		if err := lockPolicies(scanInfo, opaSessionObj.Frameworks); err != nil {
+			return err
+		}
+	}
+	if scanInfo.CustomControls != "" {
+		customControls, err := getter.LoadCustomControls(scanInfo.CustomControls)
+		if err != nil {
+			return err
+		}
+		// the controls of the scanned custom frameworks are scanned with their framework only
+		customControls = excludeFrameworksControls(customControls, opaSessionObj.Frameworks)
+		if len(customControls) > 0 {
+			opaSessionObj.Frameworks = append(opaSessionObj.Frameworks, *getter.NewCustomControlsFramework(customControls))
+			logger.L().Info("Loaded custom controls", helpers.String("path", scanInfo.CustomControls), helpers.Int("controls", len(customControls)))
+		}
 	}
+	if scanInfo.ScoringConfig != "" {
+		scoringConfig, err := cautils.LoadScoringConfig(scanInfo.ScoringConfig)
+		if err != nil {
+			return err
+		}
+		scoringConfig.ApplySeverities(opaSessionObj.Frameworks)
+		opaSessionObj.ScoringConfig = scoringConfig
+	}
+
+	opaSessionObj.Profile.AddPhase(cautils.PhasePoliciesDownload, start)
 
-	err := policyHandler.getResources(notification, opaSessionObj, scanInfo)
+	start = time.Now()
+	fetchCtx, cancel := cautils.PhaseContext(ctx, scanInfo.Timeouts.Fetch)
+	err = policyHandler.getResources(fetchCtx, notification, opaSessionObj)
+	cancel()
 	if err != nil {
-		return err
+		return cautils.PhaseError(ctx, cautils.PhaseResourcesFetch, scanInfo.Timeouts.Fetch, err)
 	}
+	opaSessionObj.Profile.AddPhase(cautils.PhaseResourcesFetch, start)
 	if opaSessionObj.K8SResources == nil || len(*opaSessionObj.K8SResources) == 0 {
 		return fmt.Errorf("empty list of resources")
 	}
 
+	if scanInfo.SBOMFormat != "" {
+		start = time.Now()
+		generator := sbom.NewTrivyGenerator(scanInfo.ImageScannerPath, scanInfo.Offline)
+		references, err := sbom.GenerateSBOMs(generator, cautils.ImagesWorkloads(opaSessionObj.AllResources), scanInfo.SBOMFormat, scanInfo.SBOMDir)
+		if err != nil {
+			logger.L().Warning("failed to generate the SBOMs", helpers.Error(err))
+		}
+		opaSessionObj.SBOMs = references
+		opaSessionObj.Profile.AddPhase(cautils.PhaseSBOMGeneration, start)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	// update channel
 	*policyHandler.processPolicy <- opaSessionObj
 	return nil
 }
 
-func (policyHandler *PolicyHandler) getResources(notification *reporthandling.PolicyNotification, opaSessionObj *cautils.OPASessionObj, scanInfo *cautils.ScanInfo) error {
+// excludeFrameworksControls returns the controls which are not controls of the frameworks
+func excludeFrameworksControls(controls []reporthandling.Control, frameworks []reporthandling.Framework) []reporthandling.Control {
+	frameworksControls := map[string]bool{}
+	for i := range frameworks {
+		for j := range frameworks[i].Controls {
+			frameworksControls[strings.ToUpper(frameworks[i].Controls[j].ControlID)] = true
+		}
+	}
+	remaining := []reporthandling.Control{}
+	for i := range controls {
+		if !frameworksControls[strings.ToUpper(controls[i].ControlID)] {
+			remaining = append(remaining, controls[i])
+		}
+	}
+	return remaining
+}
+
+// lockPolicies creates the lockfile of the policies when missing, otherwise verifies the policies against the lockfile
+func lockPolicies(scanInfo *cautils.ScanInfo, frameworks []reporthandling.Framework) error {
+	if _, err := os.Stat(scanInfo.LockFile); err != nil {
+		lockFile, err := cautils.NewLockFile(scanInfo.PolicyVersion, frameworks)
+		if err != nil {
+			return err
+		}
+		if err := lockFile.Save(scanInfo.LockFile); err != nil {
+			return fmt.Errorf("failed to save lockfile '%s': %w", scanInfo.LockFile, err)
+		}
+		logger.L().Success("Created lockfile", helpers.String("path", scanInfo.LockFile), helpers.String("version", scanInfo.PolicyVersion))
+		return nil
+	}
+	lockFile, err := cautils.LoadLockFile(scanInfo.LockFile)
+	if err != nil {
+		return err
+	}
+	if err := lockFile.Verify(scanInfo.PolicyVersion, frameworks); err != nil {
+		return fmt.Errorf("%w. Delete '%s' to lock the current policies", err, scanInfo.LockFile)
+	}
+	return nil
+}
+
+func (policyHandler *PolicyHandler) getResources(ctx context.Context, notification *reporthandling.PolicyNotification, opaSessionObj *cautils.OPASessionObj) error {
 
 	opaSessionObj.Report.ClusterAPIServerInfo = policyHandler.resourceHandler.GetClusterAPIServerInfo()
-	resourcesMap, allResources, err := policyHandler.resourceHandler.GetResources(opaSessionObj.Frameworks, &notification.Designators)
+	resourcesMap, allResources, err := policyHandler.resourceHandler.GetResources(ctx, opaSessionObj, &notification.Designators)
 	if err != nil {
 		return err
 	}
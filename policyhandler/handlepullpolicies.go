@@ -3,9 +3,12 @@ package policyhandler
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/armosec/kubescape/cautils"
 	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/cautils/logger/helpers"
+	"github.com/armosec/kubescape/exceptionshandler"
 	"github.com/armosec/opa-utils/reporthandling"
 )
 
@@ -25,7 +28,10 @@ func (policyHandler *PolicyHandler) getPolicies(notification *reporthandling.Pol
 	// get exceptions
 	exceptionPolicies, err := policyHandler.getters.ExceptionsGetter.GetExceptions(cautils.ClusterName)
 	if err == nil {
-		policiesAndResources.Exceptions = exceptionPolicies
+		policiesAndResources.Exceptions, policiesAndResources.ExpiredExceptions = exceptionshandler.RemoveExpired(exceptionPolicies, time.Now())
+		for i := range policiesAndResources.ExpiredExceptions {
+			logger.L().Warning("the exception expired and is not applied", helpers.String("exception", policiesAndResources.ExpiredExceptions[i].Name))
+		}
 	}
 
 	// get account configuration
@@ -79,3 +85,14 @@ func policyIdentifierToSlice(rules []reporthandling.PolicyIdentifier) []string {
 	}
 	return s
 }
+
+// LoadPolicies returns a session with the policies, the exceptions and the controls inputs of the notification, without resources.
+// Used for evaluating resources which are not collected by a scan, e.g. the resources of admission requests
+func LoadPolicies(notification *reporthandling.PolicyNotification, getters *cautils.Getters) (*cautils.OPASessionObj, error) {
+	policyHandler := &PolicyHandler{getters: getters}
+	opaSessionObj := cautils.NewOPASessionObj(nil, nil)
+	if err := policyHandler.getPolicies(notification, opaSessionObj); err != nil {
+		return nil, err
+	}
+	return opaSessionObj, nil
+}
@@ -0,0 +1,181 @@
+package policylint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/armosec/k8s-interface/k8sinterface"
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/cautils/getter"
+	"github.com/armosec/opa-utils/reporthandling"
+	"github.com/armosec/opa-utils/resources"
+	"github.com/open-policy-agent/opa/ast"
+)
+
+// Level the level of a lint finding, the errors break the control at scan time
+type Level string
+
+const (
+	LevelError   Level = "error"
+	LevelWarning Level = "warning"
+)
+
+// the package queried by the scan, see opaprocessor
+const regoPackage = "armo_builtins"
+
+// Finding a lint finding of a control file
+type Finding struct {
+	File    string
+	Control string
+	Rule    string
+	Level   Level
+	Message string
+}
+
+func (finding *Finding) String() string {
+	s := finding.File
+	if finding.Control != "" {
+		s += fmt.Sprintf(": control '%s'", finding.Control)
+	}
+	if finding.Rule != "" {
+		s += fmt.Sprintf(", rule '%s'", finding.Rule)
+	}
+	return fmt.Sprintf("%s: %s", s, finding.Message)
+}
+
+// HasErrors returns true if one of the findings is an error
+func HasErrors(findings []Finding) bool {
+	for i := range findings {
+		if findings[i].Level == LevelError {
+			return true
+		}
+	}
+	return false
+}
+
+// LintDirectory lints the user-authored controls of a directory, see getter.LoadCustomControls
+func LintDirectory(dir string) ([]Finding, error) {
+	filePaths, err := getter.ListCustomControlsFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(filePaths) == 0 {
+		return nil, fmt.Errorf("no controls found in '%s'", dir)
+	}
+	k8sinterface.InitializeMapResourcesMock() // initialize the resource map, for resolving the referenced kinds
+
+	findings := []Finding{}
+	controlsIDs := map[string]string{}
+	for _, filePath := range filePaths {
+		control, err := getter.LoadCustomControl(filePath)
+		if err != nil {
+			findings = append(findings, Finding{File: filePath, Level: LevelError, Message: err.Error()})
+			continue
+		}
+		if previous, ok := controlsIDs[strings.ToUpper(control.ControlID)]; ok {
+			findings = append(findings, Finding{File: filePath, Control: control.ControlID, Level: LevelError, Message: fmt.Sprintf("the control ID is already defined in '%s'", previous)})
+		}
+		controlsIDs[strings.ToUpper(control.ControlID)] = filePath
+		findings = append(findings, LintControl(filePath, control)...)
+	}
+	return findings, nil
+}
+
+// LintControl validates the metadata of the control and compiles its rules
+func LintControl(filePath string, control *reporthandling.Control) []Finding {
+	findings := []Finding{}
+	add := func(rule string, level Level, format string, args ...interface{}) {
+		findings = append(findings, Finding{File: filePath, Control: control.ControlID, Rule: rule, Level: level, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if strings.IndexFunc(control.ControlID, unicode.IsSpace) != -1 {
+		add("", LevelError, "the control ID has white spaces")
+	}
+	if strings.HasPrefix(strings.ToUpper(control.ControlID), "C-") {
+		add("", LevelWarning, "the 'C-' prefix is used by the built-in controls, prefer a prefix of your own (e.g. CUSTOM-)")
+	}
+	if severity := cautils.ControlSeverityToString(control.BaseScore); severity == cautils.SeverityUnknown || control.BaseScore > 10 {
+		add("", LevelError, "baseScore %v is out of the range 1-10, the severity of the control is unknown", control.BaseScore)
+	}
+	if control.Description == "" {
+		add("", LevelWarning, "missing description")
+	}
+	if control.Remediation == "" {
+		add("", LevelWarning, "missing remediation")
+	}
+
+	dependencies := resources.LoadRegoModules()
+	for i := range control.Rules {
+		rule := &control.Rules[i]
+		module, err := ast.ParseModule(rule.Name, rule.Rule)
+		if err != nil {
+			add(rule.Name, LevelError, err.Error())
+			continue
+		}
+		if name := strings.TrimPrefix(module.Package.Path.String(), "data."); name != regoPackage {
+			add(rule.Name, LevelError, "package '%s', the rules are evaluated in package '%s'", name, regoPackage)
+		}
+		if !hasDenyRule(module) {
+			add(rule.Name, LevelError, "no 'deny' rule, the failures of the rule are the results of 'deny'")
+		}
+
+		modules := map[string]string{rule.Name: rule.Rule}
+		for name, dependency := range dependencies {
+			modules[name] = dependency
+		}
+		if _, err := ast.CompileModules(modules); err != nil {
+			add(rule.Name, LevelError, "failed to compile: %s", err.Error())
+		}
+
+		for _, kind := range unmatchedKinds(module, rule) {
+			add(rule.Name, LevelWarning, "references kind '%s' which is not in the match of the rule, the rule is not evaluated on these resources", kind)
+		}
+	}
+	return findings
+}
+
+func hasDenyRule(module *ast.Module) bool {
+	for _, rule := range module.Rules {
+		if rule.Head.Name.String() == "deny" {
+			return true
+		}
+	}
+	return false
+}
+
+// unmatchedKinds returns the Kubernetes kinds referenced by the Rego policy (string literals, e.g. `wl.kind == "Pod"`) which are not in the match of the rule
+func unmatchedKinds(module *ast.Module, rule *reporthandling.PolicyRule) []string {
+	matched := map[string]bool{}
+	for _, match := range append(append([]reporthandling.RuleMatchObjects{}, rule.Match...), rule.DynamicMatch...) {
+		for _, resource := range match.Resources {
+			matched[strings.ToLower(resource)] = true
+			if groupVersionResource, err := k8sinterface.GetGroupVersionResource(resource); err == nil {
+				matched[groupVersionResource.Resource] = true
+			}
+		}
+	}
+	if matched["*"] {
+		return nil
+	}
+
+	kinds := map[string]bool{}
+	ast.WalkTerms(module, func(term *ast.Term) bool {
+		s, ok := term.Value.(ast.String)
+		if !ok || s == "" || !unicode.IsUpper(rune(s[0])) {
+			return false
+		}
+		groupVersionResource, err := k8sinterface.GetGroupVersionResource(string(s))
+		if err == nil && groupVersionResource.Resource != "" && !matched[groupVersionResource.Resource] {
+			kinds[string(s)] = true
+		}
+		return false
+	})
+	unmatched := make([]string, 0, len(kinds))
+	for kind := range kinds {
+		unmatched = append(unmatched, kind)
+	}
+	sort.Strings(unmatched)
+	return unmatched
+}
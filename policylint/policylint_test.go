@@ -0,0 +1,91 @@
+package policylint
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLintDirectory(t *testing.T) {
+	findings, err := LintDirectory(filepath.Join("..", "examples", "custom-controls"))
+	assert.NoError(t, err)
+	assert.Empty(t, findings)
+
+	dir := t.TempDir()
+	controls := map[string]string{
+		"pods.yaml": `
+controlID: C-9001
+name: Pods and deployments
+rules:
+  - name: pods-and-deployments
+    rule: |
+      package armo_builtins
+      deny[msga] {
+        wl := input[_]
+        kinds := {"Pod", "Deployment"}
+        kinds[wl.kind]
+        msga := {"alertMessage": "Pod: failed", "alertObject": {"k8sApiObjects": [wl]}}
+      }
+    match:
+      - apiGroups: [apps]
+        apiVersions: [v1]
+        resources: [deployments]
+`,
+		"package.yaml": `
+controlID: CUSTOM-1
+name: Wrong package
+description: a
+remediation: b
+baseScore: 4
+rules:
+  - name: wrong-package
+    rule: |
+      package custom
+      violation[msga] {
+        msga := input[_]
+      }
+    match:
+      - apiGroups: ["*"]
+        apiVersions: ["*"]
+        resources: ["*"]
+`,
+		"broken.yaml": `
+controlID: CUSTOM-2
+name: Broken
+rules:
+  - name: broken
+    rule: "package armo_builtins\ndeny[msga] {"
+    match:
+      - resources: [pods]
+`,
+		"duplicated.json": `{"controlID": "custom-1", "name": "a", "description": "a", "remediation": "b", "baseScore": 2,
+			"rules": [{"name": "r", "rule": "package armo_builtins\ndeny[msga] {\n msga := input[_].undefined_function(1)\n}", "match": [{"resources": ["pods"]}]}]}`,
+	}
+	for name, content := range controls {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	findings, err = LintDirectory(dir)
+	assert.NoError(t, err)
+	assert.True(t, HasErrors(findings))
+
+	messages := map[string][]Level{}
+	for i := range findings {
+		messages[filepath.Base(findings[i].File)] = append(messages[filepath.Base(findings[i].File)], findings[i].Level)
+	}
+	// prefix, base score, description, remediation, unmatched Pod
+	assert.Equal(t, []Level{LevelWarning, LevelError, LevelWarning, LevelWarning, LevelWarning}, messages["pods.yaml"])
+	assert.Contains(t, findings[len(findings)-1].String(), "rule 'pods-and-deployments': references kind 'Pod'")
+	// duplicated ID (the files are linted by name), package, deny
+	assert.Equal(t, []Level{LevelError, LevelError, LevelError}, messages["package.yaml"])
+	// parse error
+	assert.Equal(t, []Level{LevelError}, messages["broken.yaml"])
+	// compile error
+	assert.Equal(t, []Level{LevelError}, messages["duplicated.json"])
+
+	_, err = LintDirectory(t.TempDir())
+	assert.Error(t, err)
+}
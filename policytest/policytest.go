@@ -0,0 +1,209 @@
+package policytest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/armosec/k8s-interface/workloadinterface"
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/opaprocessor"
+	"github.com/armosec/opa-utils/reporthandling"
+	"github.com/armosec/opa-utils/reporthandling/apis"
+	"sigs.k8s.io/yaml"
+)
+
+// TestFileSuffixes the suffixes of the test files, when looking for the test files of a directory
+var TestFileSuffixes = []string{"_test.yaml", "_test.yml", "_test.json"}
+
+// TestFile the test cases of a control, a JSON/YAML file
+type TestFile struct {
+	Path    string     `json:"-"`
+	Control string     `json:"control"` // control ID
+	Tests   []TestCase `json:"tests"`
+}
+
+// TestCase evaluates the control on fixture resources and asserts the status of the control
+type TestCase struct {
+	Name            string              `json:"name"`
+	Inputs          []string            `json:"inputs"`                    // fixture files of Kubernetes resources, relative to the test file
+	Expected        apis.ScanningStatus `json:"expected"`                  // passed/failed/skipped (no resource matched by the control)
+	FailedResources []string            `json:"failedResources,omitempty"` // optional, the exact list of the failed resources - "<kind>/<name>"
+	ControlsInputs  map[string][]string `json:"controlsInputs,omitempty"`  // the inputs of configurable controls, same as the controls-config file
+}
+
+// TestResult the result of a test case
+type TestResult struct {
+	File            string
+	Control         string
+	Name            string
+	Expected        apis.ScanningStatus
+	Status          apis.ScanningStatus
+	FailedResources []string
+	Error           error
+}
+
+// Passed returns true if the control was evaluated as expected
+func (result *TestResult) Passed() bool {
+	return result.Error == nil && result.Status == result.Expected
+}
+
+// LoadTestFiles loads the test files, the directories are walked for the files with the TestFileSuffixes
+func LoadTestFiles(paths []string) ([]TestFile, error) {
+	testFiles := []TestFile{}
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil {
+			return nil, err
+		}
+		filePaths := []string{p}
+		if info.IsDir() {
+			filePaths = []string{}
+			err := filepath.Walk(p, func(filePath string, info os.FileInfo, err error) error {
+				if err != nil {
+					return err
+				}
+				for _, suffix := range TestFileSuffixes {
+					if !info.IsDir() && strings.HasSuffix(strings.ToLower(filePath), suffix) {
+						filePaths = append(filePaths, filePath)
+						break
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+		for _, filePath := range filePaths {
+			testFile, err := loadTestFile(filePath)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load test file '%s': %w", filePath, err)
+			}
+			testFiles = append(testFiles, *testFile)
+		}
+	}
+	if len(testFiles) == 0 {
+		return nil, fmt.Errorf("no test files found in %s", strings.Join(paths, ", "))
+	}
+	return testFiles, nil
+}
+
+func loadTestFile(filePath string) (*TestFile, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	testFile := &TestFile{Path: filePath}
+	if err := yaml.Unmarshal(data, testFile); err != nil {
+		return nil, err
+	}
+	if testFile.Control == "" {
+		return nil, fmt.Errorf("missing control")
+	}
+	if len(testFile.Tests) == 0 {
+		return nil, fmt.Errorf("no tests")
+	}
+	for i, test := range testFile.Tests {
+		if len(test.Inputs) == 0 {
+			return nil, fmt.Errorf("test %d '%s': missing inputs", i, test.Name)
+		}
+		switch test.Expected {
+		case apis.StatusPassed, apis.StatusFailed, apis.StatusSkipped:
+		default:
+			return nil, fmt.Errorf("test %d '%s': unsupported expected status '%s', supported: passed/failed/skipped", i, test.Name, test.Expected)
+		}
+	}
+	return testFile, nil
+}
+
+// Run runs the test cases of the test files, the controls are looked up by ID (case insensitive)
+func Run(testFiles []TestFile, controls []reporthandling.Control) []TestResult {
+	controlsByID := map[string]*reporthandling.Control{}
+	for i := range controls {
+		controlsByID[strings.ToUpper(controls[i].ControlID)] = &controls[i]
+	}
+
+	results := []TestResult{}
+	for i := range testFiles {
+		control := controlsByID[strings.ToUpper(testFiles[i].Control)]
+		for j := range testFiles[i].Tests {
+			test := &testFiles[i].Tests[j]
+			result := TestResult{File: testFiles[i].Path, Control: testFiles[i].Control, Name: test.Name, Expected: test.Expected}
+			if control == nil {
+				result.Error = fmt.Errorf("control '%s' not found", testFiles[i].Control)
+			} else {
+				result.Status, result.FailedResources, result.Error = runTest(filepath.Dir(testFiles[i].Path), control, test)
+				if result.Error == nil && test.FailedResources != nil && !equalResources(test.FailedResources, result.FailedResources) {
+					result.Error = fmt.Errorf("expected the failed resources [%s], received [%s]", strings.Join(test.FailedResources, ", "), strings.Join(result.FailedResources, ", "))
+				}
+			}
+			results = append(results, result)
+		}
+	}
+	return results
+}
+
+// runTest evaluates the control on the inputs of the test case. Returns the status of the control and the failed resources, sorted
+func runTest(dir string, control *reporthandling.Control, test *TestCase) (apis.ScanningStatus, []string, error) {
+	inputs := make([]string, 0, len(test.Inputs))
+	for _, input := range test.Inputs {
+		if !filepath.IsAbs(input) {
+			input = filepath.Join(dir, input)
+		}
+		if _, err := os.Stat(input); err != nil {
+			return apis.StatusUnknown, nil, err
+		}
+		inputs = append(inputs, input)
+	}
+	inputResources, _, err := cautils.LoadResourcesFromFiles(inputs)
+	if err != nil {
+		return apis.StatusUnknown, nil, err
+	}
+	if len(inputResources) == 0 {
+		return apis.StatusUnknown, nil, fmt.Errorf("no resources found in the inputs")
+	}
+	resourcesByID := map[string]workloadinterface.IMetadata{}
+	for i := range inputResources {
+		resourcesByID[inputResources[i].GetID()] = inputResources[i]
+	}
+
+	controlResults, err := opaprocessor.EvaluateControl(control, inputResources, test.ControlsInputs)
+	if err != nil {
+		return apis.StatusUnknown, nil, err
+	}
+	failedResources := []string{}
+	for resourceID := range controlResults {
+		controlResult := controlResults[resourceID]
+		if !controlResult.GetStatus(nil).IsFailed() {
+			continue
+		}
+		name := resourceID
+		if resource, ok := resourcesByID[resourceID]; ok {
+			name = fmt.Sprintf("%s/%s", resource.GetKind(), resource.GetName())
+		}
+		failedResources = append(failedResources, name)
+	}
+	sort.Strings(failedResources)
+
+	switch {
+	case len(failedResources) > 0:
+		return apis.StatusFailed, failedResources, nil
+	case len(controlResults) > 0:
+		return apis.StatusPassed, failedResources, nil
+	default:
+		return apis.StatusSkipped, failedResources, nil
+	}
+}
+
+func equalResources(expected, received []string) bool {
+	expected = append([]string{}, expected...)
+	sort.Strings(expected)
+	if len(expected) == 0 && len(received) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(expected, received)
+}
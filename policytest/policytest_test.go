@@ -0,0 +1,151 @@
+package policytest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/armosec/kubescape/cautils/getter"
+	"github.com/armosec/opa-utils/reporthandling/apis"
+	"github.com/stretchr/testify/assert"
+)
+
+var examplesPath = filepath.Join("..", "examples", "custom-controls")
+
+var hostControlsPath = filepath.Join("..", "examples", "host-controls")
+var cloudControlsPath = filepath.Join("..", "examples", "cloud-controls")
+var cisControlsPath = filepath.Join("..", "examples", "cis-controls")
+var networkControlsPath = filepath.Join("..", "examples", "network-controls")
+var secretsControlsPath = filepath.Join("..", "examples", "secrets-controls")
+
+func TestRun(t *testing.T) {
+	controls, err := getter.LoadCustomControls(examplesPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testFiles, err := LoadTestFiles([]string{filepath.Join(examplesPath, "tests")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := Run(testFiles, controls)
+	assert.Len(t, results, 8)
+	for i := range results {
+		assert.True(t, results[i].Passed(), "%s: %+v", results[i].Name, results[i])
+	}
+	assert.Equal(t, []string{"Deployment/api"}, results[0].FailedResources)
+	assert.Equal(t, []string{"Deployment/api"}, results[3].FailedResources, "the ImageMetadata objects are evaluated with the workloads")
+	assert.Equal(t, []string{"Deployment/api"}, results[6].FailedResources, "the ImageSignature objects are evaluated with the workloads")
+
+	// wrong expectations fail
+	testFiles[0].Tests[0].FailedResources = []string{"Deployment/web"}
+	testFiles[0].Tests[1].Expected = apis.StatusFailed
+	results = Run(testFiles, controls)
+	assert.False(t, results[0].Passed())
+	assert.Error(t, results[0].Error)
+	assert.False(t, results[1].Passed())
+	assert.Equal(t, apis.StatusPassed, results[1].Status)
+
+	// unknown control
+	testFiles[0].Control = "CUSTOM-9999"
+	results = Run(testFiles, controls)
+	assert.Error(t, results[0].Error)
+}
+
+func TestRunHostControls(t *testing.T) {
+	controls, err := getter.LoadCustomControls(hostControlsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testFiles, err := LoadTestFiles([]string{filepath.Join(hostControlsPath, "tests")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := Run(testFiles, controls)
+	assert.Len(t, results, 9)
+	for i := range results {
+		assert.True(t, results[i].Passed(), "%s: %+v", results[i].Name, results[i])
+	}
+}
+
+func TestRunCloudControls(t *testing.T) {
+	controls, err := getter.LoadCustomControls(cloudControlsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testFiles, err := LoadTestFiles([]string{filepath.Join(cloudControlsPath, "tests")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := Run(testFiles, controls)
+	assert.Len(t, results, 6)
+	for i := range results {
+		assert.True(t, results[i].Passed(), "%s: %+v", results[i].Name, results[i])
+	}
+}
+
+func TestRunCISControls(t *testing.T) {
+	controls, err := getter.LoadCustomControls(cisControlsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testFiles, err := LoadTestFiles([]string{filepath.Join(cisControlsPath, "tests")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := Run(testFiles, controls)
+	assert.Len(t, results, 16)
+	for i := range results {
+		assert.True(t, results[i].Passed(), "%s: %+v", results[i].Name, results[i])
+	}
+}
+
+func TestLoadTestFiles(t *testing.T) {
+	invalid := map[string]string{
+		"missing control":  `{"tests": [{"name": "a", "inputs": ["a.yaml"], "expected": "failed"}]}`,
+		"no tests":         `{"control": "C-0016"}`,
+		"missing inputs":   `{"control": "C-0016", "tests": [{"name": "a", "expected": "failed"}]}`,
+		"unknown expected": `{"control": "C-0016", "tests": [{"name": "a", "inputs": ["a.yaml"], "expected": "excluded"}]}`,
+	}
+	for name, content := range invalid {
+		filePath := filepath.Join(t.TempDir(), "a_test.json")
+		if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		_, err := LoadTestFiles([]string{filePath})
+		assert.Error(t, err, name)
+	}
+	_, err := LoadTestFiles([]string{t.TempDir()})
+	assert.Error(t, err)
+}
+
+func TestRunNetworkControls(t *testing.T) {
+	controls, err := getter.LoadCustomControls(networkControlsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testFiles, err := LoadTestFiles([]string{filepath.Join(networkControlsPath, "tests")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := Run(testFiles, controls)
+	assert.Len(t, results, 2)
+	for i := range results {
+		assert.True(t, results[i].Passed(), "%s: %+v", results[i].Name, results[i])
+	}
+}
+
+func TestRunSecretsControls(t *testing.T) {
+	controls, err := getter.LoadCustomControls(secretsControlsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testFiles, err := LoadTestFiles([]string{filepath.Join(secretsControlsPath, "tests")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := Run(testFiles, controls)
+	assert.Len(t, results, 2)
+	for i := range results {
+		assert.True(t, results[i].Passed(), "%s: %+v", results[i].Name, results[i])
+	}
+}
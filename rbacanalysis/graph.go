@@ -0,0 +1,180 @@
+package rbacanalysis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/armosec/k8s-interface/workloadinterface"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Subject a user, a group or a service account of the bindings
+type Subject struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"` // of the service accounts
+}
+
+func (subject Subject) String() string {
+	if subject.Namespace != "" {
+		return fmt.Sprintf("%s %s/%s", subject.Kind, subject.Namespace, subject.Name)
+	}
+	return fmt.Sprintf("%s %s", subject.Kind, subject.Name)
+}
+
+// ObjectRef a role or a binding
+type ObjectRef struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+func (ref ObjectRef) String() string {
+	if ref.Namespace != "" {
+		return fmt.Sprintf("%s %s/%s", ref.Kind, ref.Namespace, ref.Name)
+	}
+	return fmt.Sprintf("%s %s", ref.Kind, ref.Name)
+}
+
+// Permission a rule of a role granted to a subject by a binding
+type Permission struct {
+	Subject   Subject           `json:"subject"`
+	Namespace string            `json:"namespace,omitempty"` // the namespace the rule applies to, empty for the whole cluster
+	Role      ObjectRef         `json:"role"`
+	Binding   ObjectRef         `json:"binding"`
+	Rule      rbacv1.PolicyRule `json:"rule"`
+}
+
+// Objects the RBAC objects of a cluster
+type Objects struct {
+	Roles               []rbacv1.Role
+	ClusterRoles        []rbacv1.ClusterRole
+	RoleBindings        []rbacv1.RoleBinding
+	ClusterRoleBindings []rbacv1.ClusterRoleBinding
+}
+
+// Graph the permissions of the subjects, by the rules of the roles of their bindings. The bindings of the missing roles grant nothing,
+// the rules of the aggregated cluster roles are the rules aggregated by the API server
+type Graph struct {
+	subjects    []Subject
+	permissions map[Subject][]Permission
+}
+
+// ListObjects lists the RBAC objects of the cluster
+func ListObjects(client kubernetes.Interface) (*Objects, error) {
+	ctx := context.Background()
+	objects := &Objects{}
+	roles, err := client.RbacV1().Roles("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	objects.Roles = roles.Items
+	clusterRoles, err := client.RbacV1().ClusterRoles().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	objects.ClusterRoles = clusterRoles.Items
+	roleBindings, err := client.RbacV1().RoleBindings("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	objects.RoleBindings = roleBindings.Items
+	clusterRoleBindings, err := client.RbacV1().ClusterRoleBindings().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	objects.ClusterRoleBindings = clusterRoleBindings.Items
+	return objects, nil
+}
+
+// NewObjects returns the RBAC objects of the scanned resources, the other kinds are skipped
+func NewObjects(resources []workloadinterface.IMetadata) *Objects {
+	objects := &Objects{}
+	for _, resource := range resources {
+		var target interface{}
+		switch resource.GetKind() {
+		case "Role":
+			objects.Roles = append(objects.Roles, rbacv1.Role{})
+			target = &objects.Roles[len(objects.Roles)-1]
+		case "ClusterRole":
+			objects.ClusterRoles = append(objects.ClusterRoles, rbacv1.ClusterRole{})
+			target = &objects.ClusterRoles[len(objects.ClusterRoles)-1]
+		case "RoleBinding":
+			objects.RoleBindings = append(objects.RoleBindings, rbacv1.RoleBinding{})
+			target = &objects.RoleBindings[len(objects.RoleBindings)-1]
+		case "ClusterRoleBinding":
+			objects.ClusterRoleBindings = append(objects.ClusterRoleBindings, rbacv1.ClusterRoleBinding{})
+			target = &objects.ClusterRoleBindings[len(objects.ClusterRoleBindings)-1]
+		default:
+			continue
+		}
+		if b, err := json.Marshal(resource.GetObject()); err == nil {
+			json.Unmarshal(b, target)
+		}
+	}
+	return objects
+}
+
+// NewGraph resolves the roles of the bindings of the objects
+func NewGraph(objects *Objects) *Graph {
+	graph := &Graph{permissions: map[Subject][]Permission{}}
+	roles := map[ObjectRef][]rbacv1.PolicyRule{}
+	for i := range objects.Roles {
+		roles[ObjectRef{Kind: "Role", Name: objects.Roles[i].Name, Namespace: objects.Roles[i].Namespace}] = objects.Roles[i].Rules
+	}
+	for i := range objects.ClusterRoles {
+		roles[ObjectRef{Kind: "ClusterRole", Name: objects.ClusterRoles[i].Name}] = objects.ClusterRoles[i].Rules
+	}
+
+	for i := range objects.ClusterRoleBindings {
+		binding := &objects.ClusterRoleBindings[i]
+		graph.bind(ObjectRef{Kind: "ClusterRoleBinding", Name: binding.Name}, binding.Subjects, ObjectRef{Kind: binding.RoleRef.Kind, Name: binding.RoleRef.Name}, "", roles)
+	}
+	for i := range objects.RoleBindings {
+		binding := &objects.RoleBindings[i]
+		role := ObjectRef{Kind: binding.RoleRef.Kind, Name: binding.RoleRef.Name}
+		if role.Kind == "Role" {
+			role.Namespace = binding.Namespace
+		}
+		graph.bind(ObjectRef{Kind: "RoleBinding", Name: binding.Name, Namespace: binding.Namespace}, binding.Subjects, role, binding.Namespace, roles)
+	}
+
+	for subject := range graph.permissions {
+		graph.subjects = append(graph.subjects, subject)
+	}
+	sort.Slice(graph.subjects, func(i, j int) bool { return graph.subjects[i].String() < graph.subjects[j].String() })
+	return graph
+}
+
+func (graph *Graph) bind(binding ObjectRef, subjects []rbacv1.Subject, role ObjectRef, namespace string, roles map[ObjectRef][]rbacv1.PolicyRule) {
+	rules, ok := roles[role]
+	if !ok {
+		return
+	}
+	for _, s := range subjects {
+		subject := Subject{Kind: s.Kind, Name: s.Name}
+		if s.Kind == rbacv1.ServiceAccountKind {
+			subject.Namespace = s.Namespace
+			if subject.Namespace == "" {
+				subject.Namespace = binding.Namespace
+			}
+		}
+		for _, rule := range rules {
+			graph.permissions[subject] = append(graph.permissions[subject], Permission{Subject: subject, Namespace: namespace, Role: role, Binding: binding, Rule: rule})
+		}
+	}
+}
+
+// Subjects returns the subjects of the bindings, sorted
+func (graph *Graph) Subjects() []Subject {
+	return graph.subjects
+}
+
+// Permissions returns the permissions of the subject, the cluster wide permissions first
+func (graph *Graph) Permissions(subject Subject) []Permission {
+	return graph.permissions[subject]
+}
@@ -0,0 +1,140 @@
+package rbacanalysis
+
+import (
+	"testing"
+
+	"github.com/armosec/k8s-interface/workloadinterface"
+	"github.com/stretchr/testify/assert"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func testObjects() *Objects {
+	return &Objects{
+		ClusterRoles: []rbacv1.ClusterRole{
+			{ObjectMeta: metav1.ObjectMeta{Name: "cluster-admin"}, Rules: []rbacv1.PolicyRule{
+				{Verbs: []string{"*"}, APIGroups: []string{"*"}, Resources: []string{"*"}},
+				{Verbs: []string{"*"}, NonResourceURLs: []string{"*"}},
+			}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "pods-deleter"}, Rules: []rbacv1.PolicyRule{{Verbs: []string{"delete", "get"}, APIGroups: []string{""}, Resources: []string{"pods"}}}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "metrics"}, Rules: []rbacv1.PolicyRule{{Verbs: []string{"get"}, NonResourceURLs: []string{"/metrics/*"}}}},
+		},
+		Roles: []rbacv1.Role{
+			{ObjectMeta: metav1.ObjectMeta{Name: "debugger", Namespace: "kube-system"}, Rules: []rbacv1.PolicyRule{
+				{Verbs: []string{"create"}, APIGroups: []string{""}, Resources: []string{"pods/exec"}},
+				{Verbs: []string{"get", "list"}, APIGroups: []string{""}, Resources: []string{"secrets"}},
+			}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "deployer", Namespace: "default"}, Rules: []rbacv1.PolicyRule{{Verbs: []string{"*"}, APIGroups: []string{"apps"}, Resources: []string{"deployments"}}}},
+		},
+		ClusterRoleBindings: []rbacv1.ClusterRoleBinding{
+			{ObjectMeta: metav1.ObjectMeta{Name: "admins"}, RoleRef: rbacv1.RoleRef{Kind: "ClusterRole", Name: "cluster-admin"}, Subjects: []rbacv1.Subject{{Kind: "Group", Name: "admins"}}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "system:masters"}, RoleRef: rbacv1.RoleRef{Kind: "ClusterRole", Name: "cluster-admin"}, Subjects: []rbacv1.Subject{{Kind: "Group", Name: "system:masters"}}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "prometheus"}, RoleRef: rbacv1.RoleRef{Kind: "ClusterRole", Name: "metrics"}, Subjects: []rbacv1.Subject{{Kind: "ServiceAccount", Name: "prometheus", Namespace: "monitoring"}}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "missing"}, RoleRef: rbacv1.RoleRef{Kind: "ClusterRole", Name: "missing"}, Subjects: []rbacv1.Subject{{Kind: "User", Name: "nobody"}}},
+		},
+		RoleBindings: []rbacv1.RoleBinding{
+			{ObjectMeta: metav1.ObjectMeta{Name: "debuggers", Namespace: "kube-system"}, RoleRef: rbacv1.RoleRef{Kind: "Role", Name: "debugger"}, Subjects: []rbacv1.Subject{{Kind: "User", Name: "alice"}}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "cleaner", Namespace: "kube-system"}, RoleRef: rbacv1.RoleRef{Kind: "ClusterRole", Name: "pods-deleter"}, Subjects: []rbacv1.Subject{{Kind: "ServiceAccount", Name: "cleaner"}}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "deployers", Namespace: "default"}, RoleRef: rbacv1.RoleRef{Kind: "Role", Name: "deployer"}, Subjects: []rbacv1.Subject{{Kind: "User", Name: "ci"}}},
+		},
+	}
+}
+
+func TestNewGraph(t *testing.T) {
+	graph := NewGraph(testObjects())
+
+	subjects := []string{}
+	for _, subject := range graph.Subjects() {
+		subjects = append(subjects, subject.String())
+	}
+	assert.Equal(t, []string{"Group admins", "Group system:masters", "ServiceAccount kube-system/cleaner", "ServiceAccount monitoring/prometheus", "User alice", "User ci"}, subjects)
+
+	permissions := graph.Permissions(Subject{Kind: "ServiceAccount", Name: "cleaner", Namespace: "kube-system"})
+	if assert.Len(t, permissions, 1) {
+		assert.Equal(t, "kube-system", permissions[0].Namespace)
+		assert.Equal(t, ObjectRef{Kind: "ClusterRole", Name: "pods-deleter"}, permissions[0].Role)
+		assert.Equal(t, ObjectRef{Kind: "RoleBinding", Name: "cleaner", Namespace: "kube-system"}, permissions[0].Binding)
+	}
+	assert.Len(t, graph.Permissions(Subject{Kind: "User", Name: "alice"}), 2)
+	assert.Empty(t, graph.Permissions(Subject{Kind: "User", Name: "nobody"}))
+}
+
+func TestListObjects(t *testing.T) {
+	objects := testObjects()
+	client := fake.NewSimpleClientset(&objects.ClusterRoles[0], &objects.Roles[0], &objects.ClusterRoleBindings[0], &objects.RoleBindings[0])
+	listed, err := ListObjects(client)
+	if assert.NoError(t, err) {
+		assert.Len(t, listed.ClusterRoles, 1)
+		assert.Len(t, listed.Roles, 1)
+		assert.Len(t, listed.ClusterRoleBindings, 1)
+		assert.Len(t, listed.RoleBindings, 1)
+	}
+}
+
+func TestNewObjects(t *testing.T) {
+	resources := []workloadinterface.IMetadata{
+		workloadinterface.NewWorkloadObj(map[string]interface{}{
+			"apiVersion": "rbac.authorization.k8s.io/v1",
+			"kind":       "ClusterRoleBinding",
+			"metadata":   map[string]interface{}{"name": "admins"},
+			"roleRef":    map[string]interface{}{"apiGroup": "rbac.authorization.k8s.io", "kind": "ClusterRole", "name": "cluster-admin"},
+			"subjects":   []interface{}{map[string]interface{}{"kind": "ServiceAccount", "name": "ci", "namespace": "tools"}},
+		}),
+		workloadinterface.NewWorkloadObj(map[string]interface{}{"apiVersion": "v1", "kind": "ServiceAccount", "metadata": map[string]interface{}{"name": "ci"}}),
+	}
+	objects := NewObjects(resources)
+	assert.Empty(t, objects.Roles)
+	if assert.Len(t, objects.ClusterRoleBindings, 1) {
+		assert.Equal(t, "cluster-admin", objects.ClusterRoleBindings[0].RoleRef.Name)
+		assert.Equal(t, []rbacv1.Subject{{Kind: "ServiceAccount", Name: "ci", Namespace: "tools"}}, objects.ClusterRoleBindings[0].Subjects)
+	}
+}
+
+func TestWhoCan(t *testing.T) {
+	graph := NewGraph(testObjects())
+	tests := []struct {
+		verb, resource, name, namespace string
+		want                            []string
+	}{
+		{verb: "delete", resource: "pods", namespace: "kube-system", want: []string{"Group admins", "Group system:masters", "ServiceAccount kube-system/cleaner"}},
+		{verb: "delete", resource: "pods", namespace: "default", want: []string{"Group admins", "Group system:masters"}},
+		{verb: "delete", resource: "pods", want: []string{"Group admins", "Group system:masters", "ServiceAccount kube-system/cleaner"}},
+		{verb: "create", resource: "pods/exec", namespace: "kube-system", want: []string{"Group admins", "Group system:masters", "User alice"}},
+		{verb: "get", resource: "secrets", name: "token", namespace: "kube-system", want: []string{"Group admins", "Group system:masters", "User alice"}},
+		{verb: "patch", resource: "deployments.apps", namespace: "default", want: []string{"Group admins", "Group system:masters", "User ci"}},
+		{verb: "patch", resource: "deployments.extensions", namespace: "default", want: []string{"Group admins", "Group system:masters"}},
+		{verb: "get", resource: "/metrics/cadvisor", want: []string{"Group admins", "Group system:masters", "ServiceAccount monitoring/prometheus"}},
+		{verb: "get", resource: "/healthz", want: []string{"Group admins", "Group system:masters"}},
+	}
+	for _, test := range tests {
+		query, err := NewQuery(test.verb, test.resource, test.name, test.namespace)
+		if !assert.NoError(t, err) {
+			continue
+		}
+		subjects := []string{}
+		for _, permission := range graph.WhoCan(query) {
+			subjects = append(subjects, permission.Subject.String())
+		}
+		assert.Equal(t, test.want, subjects, test.verb+" "+test.resource+" -n "+test.namespace)
+	}
+
+	_, err := NewQuery("get", "", "", "")
+	assert.Error(t, err)
+}
+
+func TestRisks(t *testing.T) {
+	graph := NewGraph(testObjects())
+	risks := []string{}
+	for _, risk := range graph.Risks(false) {
+		risks = append(risks, risk.Severity+" "+risk.Type+" "+risk.Subject.String())
+	}
+	assert.Equal(t, []string{
+		"Critical cluster-admin Group admins",
+		"High pod-exec User alice",
+		"High secrets-read User alice",
+		"Medium wildcard-verbs User ci",
+	}, risks)
+
+	assert.Len(t, graph.Risks(true), 5)
+}
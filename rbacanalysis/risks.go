@@ -0,0 +1,129 @@
+package rbacanalysis
+
+import (
+	"sort"
+	"strings"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+// The types of the risky grants
+const (
+	RiskClusterAdmin  = "cluster-admin"
+	RiskSecretsRead   = "secrets-read"
+	RiskPodExec       = "pod-exec"
+	RiskWildcardVerbs = "wildcard-verbs"
+)
+
+// The severities of the risky grants, from the most severe
+var riskSeverities = []string{"Critical", "High", "Medium"}
+
+// Risk a risky permission of a subject
+type Risk struct {
+	Permission
+	Type        string `json:"type"`
+	Severity    string `json:"severity"`
+	Description string `json:"description"`
+}
+
+// Risks returns the risky grants of the graph, the most severe first. The grants to the system subjects and by the system bindings
+// (system:*) are the defaults of the cluster, they are returned when includeSystem is set
+func (graph *Graph) Risks(includeSystem bool) []Risk {
+	risks := []Risk{}
+	reported := map[string]bool{}
+	for _, subject := range graph.subjects {
+		for _, permission := range graph.permissions[subject] {
+			if !includeSystem && isSystem(&permission) {
+				continue
+			}
+			for _, risk := range permissionRisks(&permission) {
+				key := strings.Join([]string{subject.String(), risk.Binding.String(), risk.Role.String(), risk.Namespace, risk.Type}, "|")
+				if reported[key] {
+					continue
+				}
+				reported[key] = true
+				risks = append(risks, risk)
+			}
+		}
+	}
+	sort.SliceStable(risks, func(i, j int) bool {
+		if si, sj := severityIndex(risks[i].Severity), severityIndex(risks[j].Severity); si != sj {
+			return si < sj
+		}
+		return risks[i].Subject.String() < risks[j].Subject.String()
+	})
+	return risks
+}
+
+// permissionRisks returns the risks of the rule of the permission, the cluster-admin risk covers the others
+func permissionRisks(permission *Permission) []Risk {
+	rule := &permission.Rule
+	scope := "the cluster"
+	if permission.Namespace != "" {
+		scope = "namespace " + permission.Namespace
+	}
+
+	if isClusterAdmin(permission) {
+		if permission.Namespace == "" {
+			return []Risk{{Permission: *permission, Type: RiskClusterAdmin, Severity: "Critical", Description: "full control of the cluster"}}
+		}
+		return []Risk{{Permission: *permission, Type: RiskClusterAdmin, Severity: "High", Description: "full control of " + scope}}
+	}
+
+	risks := []Risk{}
+	if anyVerb(rule.Verbs, "get", "list", "watch") && grantsCoreResource(rule, "secrets", "") {
+		risks = append(risks, Risk{Permission: *permission, Type: RiskSecretsRead, Severity: "High", Description: "reads the secrets of " + scope})
+	}
+	if anyVerb(rule.Verbs, "create", "get") && (grantsCoreResource(rule, "pods", "exec") || grantsCoreResource(rule, "pods", "attach")) {
+		risks = append(risks, Risk{Permission: *permission, Type: RiskPodExec, Severity: "High", Description: "runs commands in the pods of " + scope})
+	}
+	if hasWildcard(rule.Verbs) {
+		risks = append(risks, Risk{Permission: *permission, Type: RiskWildcardVerbs, Severity: "Medium", Description: "any verb on " + strings.Join(append(append([]string{}, rule.Resources...), rule.NonResourceURLs...), ", ") + " of " + scope})
+	}
+	return risks
+}
+
+// grantsCoreResource returns true when the rule grants any object of the resource of the core group
+func grantsCoreResource(rule *rbacv1.PolicyRule, resource, subresource string) bool {
+	return len(rule.ResourceNames) == 0 && contains(rule.APIGroups, "") && matchResource(rule.Resources, resource, subresource)
+}
+
+// isClusterAdmin returns true for the cluster-admin role and for the rules of any verb on any resource of any group
+func isClusterAdmin(permission *Permission) bool {
+	if permission.Role.Kind == "ClusterRole" && permission.Role.Name == "cluster-admin" {
+		return true
+	}
+	rule := &permission.Rule
+	return len(rule.ResourceNames) == 0 && hasWildcard(rule.Verbs) && hasWildcard(rule.Resources) && hasWildcard(rule.APIGroups)
+}
+
+func isSystem(permission *Permission) bool {
+	return strings.HasPrefix(permission.Subject.Name, "system:") || strings.HasPrefix(permission.Binding.Name, "system:")
+}
+
+func anyVerb(verbs []string, values ...string) bool {
+	for _, value := range values {
+		if contains(verbs, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasWildcard(values []string) bool {
+	for _, v := range values {
+		if v == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+func severityIndex(severity string) int {
+	for i := range riskSeverities {
+		if riskSeverities[i] == severity {
+			return i
+		}
+	}
+	return len(riskSeverities)
+}
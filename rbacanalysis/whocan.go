@@ -0,0 +1,112 @@
+package rbacanalysis
+
+import (
+	"fmt"
+	"strings"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+// Query an action of the API - a verb on a resource of a group (e.g. "deployments.apps", "pods/exec"), or a non-resource URL (e.g. "/metrics")
+type Query struct {
+	Verb           string
+	Resource       string
+	Subresource    string
+	APIGroup       string
+	AnyAPIGroup    bool   // the resource was given without a group, e.g. "pods"
+	Name           string // the rules of other resource names do not match, the rules of any name match
+	Namespace      string // empty for any namespace
+	NonResourceURL string
+}
+
+// NewQuery parses the resource of the query, "<resource>[.<group>][/<subresource>]" or a non-resource URL
+func NewQuery(verb, resource, name, namespace string) (*Query, error) {
+	if verb == "" || resource == "" {
+		return nil, fmt.Errorf("missing the verb or the resource")
+	}
+	query := &Query{Verb: strings.ToLower(verb), Name: name, Namespace: namespace}
+	if strings.HasPrefix(resource, "/") {
+		query.NonResourceURL = resource
+		return query, nil
+	}
+	if n := strings.Index(resource, "/"); n >= 0 {
+		resource, query.Subresource = resource[:n], resource[n+1:]
+	}
+	if n := strings.Index(resource, "."); n >= 0 {
+		query.Resource, query.APIGroup = resource[:n], resource[n+1:]
+	} else {
+		query.Resource, query.AnyAPIGroup = resource, true
+	}
+	query.Resource = strings.ToLower(query.Resource)
+	return query, nil
+}
+
+// WhoCan returns the permissions granting the action of the query
+func (graph *Graph) WhoCan(query *Query) []Permission {
+	permissions := []Permission{}
+	for _, subject := range graph.subjects {
+		for _, permission := range graph.permissions[subject] {
+			if query.Matches(&permission) {
+				permissions = append(permissions, permission)
+			}
+		}
+	}
+	return permissions
+}
+
+// Matches returns true when the rule of the permission grants the action of the query
+func (query *Query) Matches(permission *Permission) bool {
+	rule := &permission.Rule
+	if !contains(rule.Verbs, query.Verb) {
+		return false
+	}
+	if query.NonResourceURL != "" {
+		return permission.Namespace == "" && matchNonResourceURL(rule.NonResourceURLs, query.NonResourceURL)
+	}
+	if query.Namespace != "" && permission.Namespace != "" && permission.Namespace != query.Namespace {
+		return false
+	}
+	if !query.AnyAPIGroup && !contains(rule.APIGroups, query.APIGroup) {
+		return false
+	}
+	if len(rule.ResourceNames) > 0 && (query.Name == "" || !contains(rule.ResourceNames, query.Name)) {
+		return false
+	}
+	return matchResource(rule.Resources, query.Resource, query.Subresource)
+}
+
+// matchResource matches the resources of a rule as the API server does, "*", "<resource>/*" and "*/<subresource>"
+func matchResource(resources []string, resource, subresource string) bool {
+	full := resource
+	if subresource != "" {
+		full += "/" + subresource
+	}
+	for _, r := range resources {
+		switch {
+		case r == rbacv1.ResourceAll || r == full:
+			return true
+		case subresource != "" && (r == resource+"/*" || r == "*/"+subresource):
+			return true
+		}
+	}
+	return false
+}
+
+func matchNonResourceURL(urls []string, url string) bool {
+	for _, u := range urls {
+		if u == rbacv1.NonResourceAll || u == url || (strings.HasSuffix(u, "*") && strings.HasPrefix(url, strings.TrimSuffix(u, "*"))) {
+			return true
+		}
+	}
+	return false
+}
+
+// contains returns true when the values include the value or "*"
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value || v == "*" {
+			return true
+		}
+	}
+	return false
+}
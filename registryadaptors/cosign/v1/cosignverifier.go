@@ -0,0 +1,181 @@
+package v1
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/armosec/kubescape/registryadaptors/imagesignatures"
+)
+
+const (
+	// DefaultCosignPath the cosign binary, looked up in the PATH
+	DefaultCosignPath = "cosign"
+	defaultTimeout    = 2 * time.Minute
+
+	// the error of cosign for the images without signatures, other errors are of signatures not verified by the trusted keys
+	noSignaturesError = "no signatures found"
+)
+
+func NewCosignVerifier(path string, policy *imagesignatures.TrustPolicy, offline bool) *CosignVerifier {
+	if path == "" {
+		path = DefaultCosignPath
+	}
+	return &CosignVerifier{
+		path:    path,
+		policy:  policy,
+		offline: offline,
+		timeout: defaultTimeout,
+	}
+}
+
+// Login verifies cosign is installed and something is trusted, the registries are logged in by cosign
+func (cosignVerifier *CosignVerifier) Login() error {
+	if cosignVerifier.policy == nil || cosignVerifier.policy.IsEmpty() {
+		return fmt.Errorf("no trusted keys or identities, set them with '--image-signature-key' or '--image-signature-identity'")
+	}
+	path, err := exec.LookPath(cosignVerifier.path)
+	if err != nil {
+		return fmt.Errorf("cosign was not found (%s), install it from https://docs.sigstore.dev for verifying the images", err.Error())
+	}
+	cosignVerifier.path = path
+	return nil
+}
+
+func (cosignVerifier *CosignVerifier) DescribeVerifier() string {
+	return "verifies the signatures of the images by cosign"
+}
+
+// VerifyImageSignature verifies the signatures of the image by each of the trusted keys and identities, and its attestations by any of them.
+// An image is verified when signed by one of them with all the required attestations
+func (cosignVerifier *CosignVerifier) VerifyImageSignature(image string) (*imagesignatures.ImageSignature, error) {
+	signature := &imagesignatures.ImageSignature{Image: image, Signers: []string{}, Attestations: []string{}}
+	failures := []string{}
+
+	trusted := cosignVerifier.trustedArgs()
+	verifiedBy := [][]string{}
+	for _, trust := range trusted {
+		stdout, stderr, err := cosignVerifier.run(append([]string{"verify"}, trust.args...), image)
+		if err != nil {
+			if !isVerificationError(err) {
+				return nil, err
+			}
+			if !strings.Contains(stderr, noSignaturesError) {
+				signature.Signed = true
+			}
+			failures = append(failures, stderr)
+			continue
+		}
+		signature.Signed = true
+		signature.Signers = append(signature.Signers, signers(stdout, trust.name)...)
+		verifiedBy = append(verifiedBy, trust.args)
+	}
+	if len(verifiedBy) == 0 {
+		signature.Error = lastLine(failures)
+		return signature, nil
+	}
+
+	missing := []string{}
+	for _, attestationType := range cosignVerifier.policy.AttestationTypes {
+		verified := false
+		for _, args := range verifiedBy {
+			_, stderr, err := cosignVerifier.run(append([]string{"verify-attestation", "--type", attestationType}, args...), image)
+			if err == nil {
+				verified = true
+				break
+			}
+			if !isVerificationError(err) {
+				return nil, err
+			}
+			failures = append(failures, stderr)
+		}
+		if verified {
+			signature.Attestations = append(signature.Attestations, attestationType)
+		} else {
+			missing = append(missing, attestationType)
+		}
+	}
+	if len(missing) != 0 {
+		signature.Error = fmt.Sprintf("missing attestations: %s", strings.Join(missing, ", "))
+		return signature, nil
+	}
+	signature.Verified = true
+	return signature, nil
+}
+
+type trustedArgs struct {
+	name string   // the key or the identity, listed as the signer
+	args []string // the arguments of cosign verifying by the key or the identity
+}
+
+func (cosignVerifier *CosignVerifier) trustedArgs() []trustedArgs {
+	trusted := []trustedArgs{}
+	for _, key := range cosignVerifier.policy.Keys {
+		trusted = append(trusted, trustedArgs{name: key, args: []string{"--key", key}})
+	}
+	for i := range cosignVerifier.policy.Identities {
+		identity := &cosignVerifier.policy.Identities[i]
+		trusted = append(trusted, trustedArgs{name: identity.String(), args: []string{"--certificate-oidc-issuer", identity.Issuer, "--certificate-identity-regexp", identity.Subject}})
+	}
+	return trusted
+}
+
+// run runs cosign, an *exec.ExitError when cosign failed to verify the image
+func (cosignVerifier *CosignVerifier) run(args []string, image string) ([]byte, string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), cosignVerifier.timeout)
+	defer cancel()
+
+	args = append(args, "--output", "json")
+	if cosignVerifier.offline {
+		args = append(args, "--offline")
+	}
+	stdout, stderr := bytes.Buffer{}, bytes.Buffer{}
+	cmd := exec.CommandContext(ctx, cosignVerifier.path, append(args, image)...)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	err := cmd.Run()
+	if ctx.Err() != nil {
+		return nil, "", fmt.Errorf("cosign timed out verifying '%s'", image)
+	}
+	if err != nil && !isVerificationError(err) {
+		return nil, "", fmt.Errorf("cosign failed: %s", err.Error())
+	}
+	return stdout.Bytes(), strings.TrimSpace(stderr.String()), err
+}
+
+func isVerificationError(err error) bool {
+	var exitError *exec.ExitError
+	return errors.As(err, &exitError)
+}
+
+// signers returns the subjects of the verified keyless signatures, the name of the key otherwise
+func signers(output []byte, name string) []string {
+	for _, line := range bytes.Split(bytes.TrimSpace(output), []byte("\n")) {
+		signatures := []cosignSignature{}
+		if json.Unmarshal(line, &signatures) != nil {
+			continue
+		}
+		subjects := []string{}
+		for i := range signatures {
+			if signatures[i].Optional.Subject != "" {
+				subjects = append(subjects, signatures[i].Optional.Subject)
+			}
+		}
+		if len(subjects) != 0 {
+			return subjects
+		}
+	}
+	return []string{name}
+}
+
+func lastLine(failures []string) string {
+	if len(failures) == 0 {
+		return ""
+	}
+	lines := strings.Split(failures[len(failures)-1], "\n")
+	return lines[len(lines)-1]
+}
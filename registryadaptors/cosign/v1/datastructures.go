@@ -0,0 +1,24 @@
+package v1
+
+import (
+	"time"
+
+	"github.com/armosec/kubescape/registryadaptors/imagesignatures"
+)
+
+// CosignVerifier verifies the signatures and the attestations of the images by the cosign CLI (https://github.com/sigstore/cosign),
+// the signatures are pulled by cosign with the credentials of the docker config of the host
+type CosignVerifier struct {
+	path    string                       // path of the cosign binary
+	policy  *imagesignatures.TrustPolicy // the trusted keys and identities
+	offline bool                         // the transparency log is not queried, the signatures are verified by their bundles
+	timeout time.Duration                // timeout of a verification of an image
+}
+
+// cosignSignature a verified signature of 'cosign verify --output json'
+type cosignSignature struct {
+	Optional struct {
+		Issuer  string `json:"Issuer"`
+		Subject string `json:"Subject"`
+	} `json:"optional"`
+}
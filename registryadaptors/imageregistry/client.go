@@ -0,0 +1,276 @@
+package imageregistry
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultPlatform the platform of the manifests selected from the indexes of the multi-platform images
+	DefaultPlatform = "linux/amd64"
+
+	// baseImageAnnotation the annotation (or label) of the base image of an image, set by the build tools
+	baseImageAnnotation = "org.opencontainers.image.base.name"
+
+	manifestMediaTypes = "application/vnd.oci.image.index.v1+json, application/vnd.docker.distribution.manifest.list.v2+json, " +
+		"application/vnd.oci.image.manifest.v1+json, application/vnd.docker.distribution.manifest.v2+json"
+
+	maxResponseSize = 10 << 20 // the manifests and the configs of the images are small
+	requestTimeout  = 30 * time.Second
+)
+
+var challengeParams = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// Client looks up the manifests and the configs of the images in their registries (the registry HTTP API v2), authenticated by the
+// credentials of the keychain
+type Client struct {
+	keychain *Keychain
+	http     *http.Client
+	platform string
+	mutex    sync.Mutex
+	auth     map[string]string // the authorization headers by the registry and the repository
+}
+
+func NewClient(keychain *Keychain, platform string) *Client {
+	if platform == "" {
+		platform = DefaultPlatform
+	}
+	return &Client{
+		keychain: keychain,
+		http:     &http.Client{Timeout: requestTimeout},
+		platform: platform,
+		auth:     map[string]string{},
+	}
+}
+
+// GetImageMetadata returns the configuration of the image of the platform of the client
+func (client *Client) GetImageMetadata(image string) (*ImageMetadata, error) {
+	ref, err := ParseReference(image)
+	if err != nil {
+		return nil, err
+	}
+
+	data, digest, err := client.get(ref, "manifests/"+ref.Reference, manifestMediaTypes)
+	if err != nil {
+		return nil, err
+	}
+	m := manifest{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse the manifest of '%s': %w", image, err)
+	}
+	if len(m.Manifests) > 0 { // an index, get the manifest of the platform
+		platformDigest, err := selectPlatform(m.Manifests, client.platform)
+		if err != nil {
+			return nil, fmt.Errorf("image '%s': %w", image, err)
+		}
+		if data, digest, err = client.get(ref, "manifests/"+platformDigest, manifestMediaTypes); err != nil {
+			return nil, err
+		}
+		annotations := m.Annotations
+		m = manifest{}
+		if err := json.Unmarshal(data, &m); err != nil {
+			return nil, fmt.Errorf("failed to parse the manifest of '%s': %w", image, err)
+		}
+		if m.Annotations == nil {
+			m.Annotations = annotations
+		}
+	}
+	if m.Config.Digest == "" {
+		return nil, fmt.Errorf("unsupported manifest of '%s' (%s)", image, m.MediaType)
+	}
+
+	data, _, err = client.get(ref, "blobs/"+m.Config.Digest, "*/*")
+	if err != nil {
+		return nil, err
+	}
+	config := imageConfig{}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse the config of '%s': %w", image, err)
+	}
+
+	metadata := &ImageMetadata{
+		Image:        image,
+		Digest:       digest,
+		User:         config.Config.User,
+		ExposedPorts: []string{},
+		Labels:       config.Config.Labels,
+		BaseImage:    m.Annotations[baseImageAnnotation],
+		OS:           config.OS,
+		Architecture: config.Architecture,
+		Created:      config.Created,
+	}
+	if metadata.Labels == nil {
+		metadata.Labels = map[string]string{}
+	}
+	if metadata.BaseImage == "" {
+		metadata.BaseImage = metadata.Labels[baseImageAnnotation]
+	}
+	for port := range config.Config.ExposedPorts {
+		metadata.ExposedPorts = append(metadata.ExposedPorts, port)
+	}
+	sort.Strings(metadata.ExposedPorts)
+	return metadata, nil
+}
+
+func selectPlatform(manifests []descriptor, platform string) (string, error) {
+	for i := range manifests {
+		if p := manifests[i].Platform; p != nil && p.OS+"/"+p.Architecture == platform {
+			return manifests[i].Digest, nil
+		}
+	}
+	return "", fmt.Errorf("no manifest of the platform %s", platform)
+}
+
+// get requests a path of the repository, authorizing by the challenge of the registry. Returns the body and its digest
+func (client *Client) get(ref *Reference, path, accept string) ([]byte, string, error) {
+	u := fmt.Sprintf("https://%s/v2/%s/%s", ref.APIHost(), ref.Repository, path)
+	authKey := ref.Registry + "/" + ref.Repository
+
+	client.mutex.Lock()
+	authorization := client.auth[authKey]
+	client.mutex.Unlock()
+
+	resp, err := client.request(u, accept, authorization)
+	if err != nil {
+		return nil, "", err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		resp.Body.Close()
+		if authorization, err = client.authorize(ref, challenge); err != nil {
+			return nil, "", fmt.Errorf("failed to authenticate to '%s': %w", ref.Registry, err)
+		}
+		client.mutex.Lock()
+		client.auth[authKey] = authorization
+		client.mutex.Unlock()
+		if resp, err = client.request(u, accept, authorization); err != nil {
+			return nil, "", err
+		}
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("failed to get '%s': %s %s", u, resp.Status, strings.TrimSpace(string(data)))
+	}
+	digest := resp.Header.Get("Docker-Content-Digest")
+	if digest == "" {
+		sum := sha256.Sum256(data)
+		digest = "sha256:" + hex.EncodeToString(sum[:])
+	}
+	return data, digest, nil
+}
+
+func (client *Client) request(u, accept, authorization string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", accept)
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	return client.http.Do(req)
+}
+
+// authorize returns the authorization header answering the challenge of the registry (the WWW-Authenticate header) - a bearer token
+// of the token server of the registry, or the basic credentials
+func (client *Client) authorize(ref *Reference, challenge string) (string, error) {
+	credentials, err := client.keychain.Resolve(ref)
+	if err != nil {
+		return "", err
+	}
+	params := map[string]string{}
+	for _, param := range challengeParams.FindAllStringSubmatch(challenge, -1) {
+		params[strings.ToLower(param[1])] = param[2]
+	}
+
+	switch scheme := strings.ToLower(strings.SplitN(challenge, " ", 2)[0]); scheme {
+	case "basic":
+		if credentials.IsEmpty() {
+			return "", fmt.Errorf("no credentials of the registry")
+		}
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(credentials.Username+":"+password(&credentials))), nil
+	case "bearer":
+		if params["realm"] == "" {
+			return "", fmt.Errorf("no realm in the challenge '%s'", challenge)
+		}
+		if params["scope"] == "" {
+			params["scope"] = fmt.Sprintf("repository:%s:pull", ref.Repository)
+		}
+		token, err := client.token(params, &credentials)
+		if err != nil {
+			return "", err
+		}
+		return "Bearer " + token, nil
+	default:
+		return "", fmt.Errorf("unsupported authentication scheme '%s'", scheme)
+	}
+}
+
+// token requests a token of the token server - by the OAuth2 refresh token flow for the identity tokens, otherwise by the basic credentials
+func (client *Client) token(params map[string]string, credentials *Credentials) (string, error) {
+	query := url.Values{}
+	query.Set("service", params["service"])
+	query.Set("scope", params["scope"])
+
+	var resp *http.Response
+	var err error
+	if credentials.IdentityToken != "" {
+		query.Set("grant_type", "refresh_token")
+		query.Set("refresh_token", credentials.IdentityToken)
+		query.Set("client_id", "kubescape")
+		resp, err = client.http.PostForm(params["realm"], query)
+	} else {
+		var req *http.Request
+		if req, err = http.NewRequest(http.MethodGet, params["realm"]+"?"+query.Encode(), nil); err != nil {
+			return "", err
+		}
+		if !credentials.IsEmpty() {
+			req.SetBasicAuth(credentials.Username, credentials.Password)
+		}
+		resp, err = client.http.Do(req)
+	}
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("the token server returned %s", resp.Status)
+	}
+	token := struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}{}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(&token); err != nil {
+		return "", fmt.Errorf("failed to parse the token: %w", err)
+	}
+	if token.Token != "" {
+		return token.Token, nil
+	}
+	if token.AccessToken != "" {
+		return token.AccessToken, nil
+	}
+	return "", fmt.Errorf("the token server returned no token")
+}
+
+func password(credentials *Credentials) string {
+	if credentials.IdentityToken != "" {
+		return credentials.IdentityToken
+	}
+	return credentials.Password
+}
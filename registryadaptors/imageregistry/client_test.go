@@ -0,0 +1,105 @@
+package imageregistry
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	indexMock = `{"mediaType": "application/vnd.oci.image.index.v1+json", "manifests": [
+  {"digest": "sha256:arm", "platform": {"os": "linux", "architecture": "arm64"}},
+  {"digest": "sha256:amd", "platform": {"os": "linux", "architecture": "amd64"}}
+], "annotations": {"org.opencontainers.image.base.name": "docker.io/library/alpine:3.15"}}`
+	manifestMock = `{"mediaType": "application/vnd.oci.image.manifest.v1+json", "config": {"digest": "sha256:config"}}`
+	configMock   = `{"os": "linux", "architecture": "amd64", "created": "2022-03-01T10:00:00Z",
+  "config": {"User": "1000", "ExposedPorts": {"8080/tcp": {}, "443/tcp": {}}, "Labels": {"maintainer": "team"}}}`
+)
+
+// mockRegistry serves the app repository to the bearer tokens of the token server, issued to user:password
+func mockRegistry(t *testing.T) *httptest.Server {
+	var server *httptest.Server
+	server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			user, password, ok := r.BasicAuth()
+			if !ok || user != "user" || password != "password" || r.URL.Query().Get("scope") != "repository:org/app:pull" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(`{"token": "secret-token"}`))
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer secret-token" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="`+server.URL+`/token",service="registry",scope="repository:org/app:pull"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/v2/org/app/manifests/1.0":
+			w.Write([]byte(indexMock))
+		case "/v2/org/app/manifests/sha256:amd":
+			w.Header().Set("Docker-Content-Digest", "sha256:amd")
+			w.Write([]byte(manifestMock))
+		case "/v2/org/app/blobs/sha256:config":
+			w.Write([]byte(configMock))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors": [{"code": "MANIFEST_UNKNOWN"}]}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newMockClient(t *testing.T, server *httptest.Server, auth string) *Client {
+	configDir := t.TempDir()
+	host := strings.TrimPrefix(server.URL, "https://")
+	config := `{"auths": {"` + host + `": {"auth": "` + base64.StdEncoding.EncodeToString([]byte(auth)) + `"}}}`
+	assert.NoError(t, os.WriteFile(filepath.Join(configDir, "config.json"), []byte(config), 0600))
+	keychain, err := NewKeychain(configDir)
+	assert.NoError(t, err)
+	client := NewClient(keychain, "")
+	client.http = server.Client()
+	return client
+}
+
+func TestGetImageMetadata(t *testing.T) {
+	server := mockRegistry(t)
+	image := strings.TrimPrefix(server.URL, "https://") + "/org/app:1.0"
+
+	client := newMockClient(t, server, "user:password")
+	metadata, err := client.GetImageMetadata(image)
+	assert.NoError(t, err)
+	assert.Equal(t, &ImageMetadata{
+		Image:        image,
+		Digest:       "sha256:amd",
+		User:         "1000",
+		ExposedPorts: []string{"443/tcp", "8080/tcp"},
+		Labels:       map[string]string{"maintainer": "team"},
+		BaseImage:    "docker.io/library/alpine:3.15",
+		OS:           "linux",
+		Architecture: "amd64",
+		Created:      "2022-03-01T10:00:00Z",
+	}, metadata)
+
+	_, err = client.GetImageMetadata(strings.TrimPrefix(server.URL, "https://") + "/org/app:2.0")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "MANIFEST_UNKNOWN")
+	}
+
+	client.platform = "windows/amd64"
+	_, err = client.GetImageMetadata(image)
+	assert.Error(t, err)
+
+	// wrong credentials
+	_, err = newMockClient(t, server, "user:wrong").GetImageMetadata(image)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "failed to authenticate")
+	}
+}
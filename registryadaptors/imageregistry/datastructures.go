@@ -0,0 +1,75 @@
+package imageregistry
+
+// ImageMetadata the configuration of an image, from its manifest and its config blob
+type ImageMetadata struct {
+	Image        string            `json:"image"`
+	Digest       string            `json:"digest"` // digest of the manifest of the platform
+	User         string            `json:"user"`   // the user of the image, root when empty
+	ExposedPorts []string          `json:"exposedPorts"`
+	Labels       map[string]string `json:"labels"`
+	BaseImage    string            `json:"baseImage,omitempty"` // the org.opencontainers.image.base.name annotation or label
+	OS           string            `json:"os"`
+	Architecture string            `json:"architecture"`
+	Created      string            `json:"created,omitempty"`
+}
+
+// Credentials the credentials of a registry, the identity token (e.g. of a token helper) replaces the password when set
+type Credentials struct {
+	Username      string
+	Password      string
+	IdentityToken string
+}
+
+// IsEmpty returns true for the anonymous access
+func (credentials *Credentials) IsEmpty() bool {
+	return credentials.Username == "" && credentials.Password == "" && credentials.IdentityToken == ""
+}
+
+// dockerConfig the docker config file (~/.docker/config.json)
+type dockerConfig struct {
+	Auths       map[string]dockerAuth `json:"auths"`
+	CredsStore  string                `json:"credsStore"`
+	CredHelpers map[string]string     `json:"credHelpers"`
+}
+
+type dockerAuth struct {
+	Auth          string `json:"auth"` // base64 of <username>:<password>
+	Username      string `json:"username"`
+	Password      string `json:"password"`
+	IdentityToken string `json:"identitytoken"`
+}
+
+// helperCredentials the output of 'docker-credential-<helper> get'
+type helperCredentials struct {
+	Username string `json:"Username"`
+	Secret   string `json:"Secret"`
+}
+
+// manifest an image manifest or an index (manifest list) of the manifests of the platforms
+type manifest struct {
+	MediaType   string            `json:"mediaType"`
+	Config      descriptor        `json:"config"`
+	Manifests   []descriptor      `json:"manifests"`
+	Annotations map[string]string `json:"annotations"`
+}
+
+type descriptor struct {
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+	Platform  *struct {
+		OS           string `json:"os"`
+		Architecture string `json:"architecture"`
+	} `json:"platform"`
+}
+
+// imageConfig the config blob of an image
+type imageConfig struct {
+	OS           string `json:"os"`
+	Architecture string `json:"architecture"`
+	Created      string `json:"created"`
+	Config       struct {
+		User         string              `json:"User"`
+		ExposedPorts map[string]struct{} `json:"ExposedPorts"`
+		Labels       map[string]string   `json:"Labels"`
+	} `json:"config"`
+}
@@ -0,0 +1,6 @@
+package imageregistry
+
+// IImageMetadataClient looks up the configurations of the images in their registries
+type IImageMetadataClient interface {
+	GetImageMetadata(image string) (*ImageMetadata, error)
+}
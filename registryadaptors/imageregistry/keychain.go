@@ -0,0 +1,143 @@
+package imageregistry
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const helperTimeout = time.Minute
+
+// tokenHelpers the credential helpers of the registries of the cloud providers, used when the docker config has no credentials
+// of the registry and the helper is installed
+var tokenHelpers = []struct {
+	host   *regexp.Regexp
+	helper string
+}{
+	{regexp.MustCompile(`\.dkr\.ecr(-fips)?\.[a-z0-9-]+\.amazonaws\.com(\.cn)?$`), "ecr-login"},
+	{regexp.MustCompile(`(^|\.)gcr\.io$|-docker\.pkg\.dev$`), "gcloud"},
+	{regexp.MustCompile(`\.azurecr\.(io|cn|us)$`), "acr-env"},
+}
+
+// Keychain resolves the credentials of the registries as docker does - the credential helper of the registry (credHelpers), the credentials
+// store (credsStore), the credentials of the config (auths), then the token helpers of ECR, GCR/Artifact Registry and ACR
+type Keychain struct {
+	config dockerConfig
+}
+
+// DockerConfigDir returns the directory of the docker config, DOCKER_CONFIG or ~/.docker
+func DockerConfigDir() string {
+	if dir := os.Getenv("DOCKER_CONFIG"); dir != "" {
+		return dir
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".docker")
+}
+
+// NewKeychain loads the config.json of the docker config directory, the keychain is anonymous (but for the token helpers) when the file is missing
+func NewKeychain(configDir string) (*Keychain, error) {
+	keychain := &Keychain{}
+	data, err := os.ReadFile(filepath.Join(configDir, "config.json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return keychain, nil
+		}
+		return keychain, err
+	}
+	if err := json.Unmarshal(data, &keychain.config); err != nil {
+		return keychain, fmt.Errorf("failed to read the docker config '%s': %w", configDir, err)
+	}
+	return keychain, nil
+}
+
+// Resolve returns the credentials of the registry of an image, empty for the anonymous access
+func (keychain *Keychain) Resolve(ref *Reference) (Credentials, error) {
+	key := ref.credentialKey()
+	if helper, ok := keychain.config.CredHelpers[ref.Registry]; ok {
+		return runCredentialHelper(helper, key)
+	}
+	if keychain.config.CredsStore != "" {
+		if credentials, err := runCredentialHelper(keychain.config.CredsStore, key); err == nil && !credentials.IsEmpty() {
+			return credentials, nil
+		}
+	}
+	if auth, ok := keychain.findAuth(ref.Registry, key); ok {
+		return auth.credentials()
+	}
+	for _, tokenHelper := range tokenHelpers {
+		if !tokenHelper.host.MatchString(ref.Registry) {
+			continue
+		}
+		if _, err := exec.LookPath(credentialHelperBinary(tokenHelper.helper)); err != nil {
+			break
+		}
+		return runCredentialHelper(tokenHelper.helper, key)
+	}
+	return Credentials{}, nil
+}
+
+// findAuth returns the credentials of the registry in the auths of the config, the keys are hosts or URLs (e.g. https://index.docker.io/v1/)
+func (keychain *Keychain) findAuth(registry, key string) (dockerAuth, bool) {
+	if auth, ok := keychain.config.Auths[key]; ok {
+		return auth, true
+	}
+	for server, auth := range keychain.config.Auths {
+		host := strings.TrimPrefix(strings.TrimPrefix(server, "https://"), "http://")
+		host = strings.SplitN(host, "/", 2)[0]
+		if host == registry || (registry == dockerHubRegistry && (host == "index.docker.io" || host == dockerHubAPIHost)) {
+			return auth, true
+		}
+	}
+	return dockerAuth{}, false
+}
+
+func (auth *dockerAuth) credentials() (Credentials, error) {
+	credentials := Credentials{Username: auth.Username, Password: auth.Password, IdentityToken: auth.IdentityToken}
+	if auth.Auth != "" {
+		decoded, err := base64.StdEncoding.DecodeString(auth.Auth)
+		if err != nil {
+			return credentials, fmt.Errorf("invalid auth of the docker config: %w", err)
+		}
+		userPassword := strings.SplitN(string(decoded), ":", 2)
+		if len(userPassword) != 2 {
+			return credentials, fmt.Errorf("invalid auth of the docker config, expected <username>:<password>")
+		}
+		credentials.Username, credentials.Password = userPassword[0], userPassword[1]
+	}
+	return credentials, nil
+}
+
+func credentialHelperBinary(helper string) string {
+	return "docker-credential-" + helper
+}
+
+// runCredentialHelper runs 'docker-credential-<helper> get' with the server on stdin. The helpers return the username "<token>"
+// for the identity tokens
+func runCredentialHelper(helper, server string) (Credentials, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), helperTimeout)
+	defer cancel()
+
+	stdout, stderr := bytes.Buffer{}, bytes.Buffer{}
+	cmd := exec.CommandContext(ctx, credentialHelperBinary(helper), "get")
+	cmd.Stdin = strings.NewReader(server)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return Credentials{}, fmt.Errorf("credential helper '%s' failed: %s %s", helper, err.Error(), strings.TrimSpace(stderr.String()+stdout.String()))
+	}
+	output := helperCredentials{}
+	if err := json.Unmarshal(stdout.Bytes(), &output); err != nil {
+		return Credentials{}, fmt.Errorf("failed to parse the output of the credential helper '%s': %w", helper, err)
+	}
+	if output.Username == "<token>" {
+		return Credentials{IdentityToken: output.Secret}, nil
+	}
+	return Credentials{Username: output.Username, Password: output.Secret}, nil
+}
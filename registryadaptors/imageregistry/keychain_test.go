@@ -0,0 +1,63 @@
+package imageregistry
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeHelper writes a credential helper in the PATH, printing the credentials of the servers
+func fakeHelper(t *testing.T, dir, name, output string) {
+	script := "#!/bin/sh\nread server\necho \"$server\" > " + filepath.Join(dir, name+".server") + "\necho '" + output + "'\n"
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, credentialHelperBinary(name)), []byte(script), 0755))
+}
+
+func TestKeychain(t *testing.T) {
+	binDir := t.TempDir()
+	t.Setenv("PATH", binDir)
+	fakeHelper(t, binDir, "fake", `{"ServerURL": "helper.example.com", "Username": "helper-user", "Secret": "helper-secret"}`)
+	fakeHelper(t, binDir, "ecr-login", `{"Username": "AWS", "Secret": "ecr-token"}`)
+	fakeHelper(t, binDir, "acr-env", `{"Username": "<token>", "Secret": "refresh-token"}`)
+
+	configDir := t.TempDir()
+	config := `{
+  "auths": {
+    "https://index.docker.io/v1/": {"auth": "` + base64.StdEncoding.EncodeToString([]byte("hub-user:hub-password")) + `"},
+    "https://private.example.com/v2/": {"username": "private-user", "password": "private-password"}
+  },
+  "credHelpers": {"helper.example.com": "fake"}
+}`
+	assert.NoError(t, os.WriteFile(filepath.Join(configDir, "config.json"), []byte(config), 0600))
+	keychain, err := NewKeychain(configDir)
+	assert.NoError(t, err)
+
+	tests := map[string]Credentials{
+		"nginx":                   {Username: "hub-user", Password: "hub-password"},
+		"private.example.com/app": {Username: "private-user", Password: "private-password"},
+		"helper.example.com/app":  {Username: "helper-user", Password: "helper-secret"},
+		"123456789012.dkr.ecr.us-east-1.amazonaws.com/app": {Username: "AWS", Password: "ecr-token"},
+		"registry.azurecr.io/app":                          {IdentityToken: "refresh-token"},
+		"gcr.io/project/app":                               {}, // the gcloud helper is not installed
+		"public.example.com/app":                           {},
+	}
+	for image, expected := range tests {
+		ref, _ := ParseReference(image)
+		credentials, err := keychain.Resolve(ref)
+		if assert.NoError(t, err, image) {
+			assert.Equal(t, expected, credentials, image)
+		}
+	}
+	server, _ := os.ReadFile(filepath.Join(binDir, "fake.server"))
+	assert.Equal(t, "helper.example.com\n", string(server))
+
+	// no docker config
+	keychain, err = NewKeychain(t.TempDir())
+	assert.NoError(t, err)
+	ref, _ := ParseReference("nginx")
+	credentials, err := keychain.Resolve(ref)
+	assert.NoError(t, err)
+	assert.True(t, credentials.IsEmpty())
+}
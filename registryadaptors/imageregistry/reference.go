@@ -0,0 +1,72 @@
+package imageregistry
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	dockerHubRegistry      = "docker.io"
+	dockerHubAPIHost       = "registry-1.docker.io"
+	dockerHubCredentialKey = "https://index.docker.io/v1/" // the key of the credentials of Docker Hub in the docker config
+)
+
+// Reference the registry, the repository and the tag or the digest of an image
+type Reference struct {
+	Registry   string // e.g. docker.io, gcr.io, localhost:5000
+	Repository string // e.g. library/nginx
+	Reference  string // the tag or the digest
+}
+
+// ParseReference parses an image as docker does - the images without a registry are of Docker Hub, the official images of Docker Hub
+// are in the library repository, the images without a tag are latest
+func ParseReference(image string) (*Reference, error) {
+	if image == "" || strings.ContainsAny(image, " \t\n") {
+		return nil, fmt.Errorf("invalid image '%s'", image)
+	}
+	ref := &Reference{Registry: dockerHubRegistry, Reference: "latest"}
+	name := image
+	if i := strings.Index(name, "@"); i >= 0 {
+		name, ref.Reference = name[:i], name[i+1:]
+	} else if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name, ref.Reference = name[:i], name[i+1:]
+	}
+	if i := strings.Index(name, "/"); i >= 0 && (strings.ContainsAny(name[:i], ".:") || name[:i] == "localhost") {
+		ref.Registry, name = name[:i], name[i+1:]
+	}
+	if ref.Registry == "index.docker.io" {
+		ref.Registry = dockerHubRegistry
+	}
+	if ref.Registry == dockerHubRegistry && !strings.Contains(name, "/") {
+		name = "library/" + name
+	}
+	if name == "" || ref.Reference == "" {
+		return nil, fmt.Errorf("invalid image '%s'", image)
+	}
+	ref.Repository = name
+	return ref, nil
+}
+
+// APIHost returns the host of the registry API
+func (ref *Reference) APIHost() string {
+	if ref.Registry == dockerHubRegistry {
+		return dockerHubAPIHost
+	}
+	return ref.Registry
+}
+
+// credentialKey returns the key of the credentials of the registry in the docker config
+func (ref *Reference) credentialKey() string {
+	if ref.Registry == dockerHubRegistry {
+		return dockerHubCredentialKey
+	}
+	return ref.Registry
+}
+
+func (ref *Reference) String() string {
+	separator := ":"
+	if strings.Contains(ref.Reference, ":") {
+		separator = "@"
+	}
+	return fmt.Sprintf("%s/%s%s%s", ref.Registry, ref.Repository, separator, ref.Reference)
+}
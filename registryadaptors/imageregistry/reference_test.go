@@ -0,0 +1,35 @@
+package imageregistry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseReference(t *testing.T) {
+	tests := map[string]Reference{
+		"nginx":                          {Registry: "docker.io", Repository: "library/nginx", Reference: "latest"},
+		"nginx:1.21":                     {Registry: "docker.io", Repository: "library/nginx", Reference: "1.21"},
+		"bitnami/redis:6":                {Registry: "docker.io", Repository: "bitnami/redis", Reference: "6"},
+		"index.docker.io/library/nginx":  {Registry: "docker.io", Repository: "library/nginx", Reference: "latest"},
+		"gcr.io/project/app:v1":          {Registry: "gcr.io", Repository: "project/app", Reference: "v1"},
+		"localhost:5000/app":             {Registry: "localhost:5000", Repository: "app", Reference: "latest"},
+		"localhost/app:dev":              {Registry: "localhost", Repository: "app", Reference: "dev"},
+		"registry.io/org/app@sha256:abc": {Registry: "registry.io", Repository: "org/app", Reference: "sha256:abc"},
+	}
+	for image, expected := range tests {
+		ref, err := ParseReference(image)
+		if assert.NoError(t, err, image) {
+			assert.Equal(t, expected, *ref, image)
+		}
+	}
+	ref, _ := ParseReference("nginx")
+	assert.Equal(t, "registry-1.docker.io", ref.APIHost())
+	assert.Equal(t, "https://index.docker.io/v1/", ref.credentialKey())
+	assert.Equal(t, "docker.io/library/nginx:latest", ref.String())
+
+	for _, image := range []string{"", "nginx:", "nginx@", "ngi nx"} {
+		_, err := ParseReference(image)
+		assert.Error(t, err, image)
+	}
+}
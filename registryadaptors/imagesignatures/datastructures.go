@@ -0,0 +1,47 @@
+package imagesignatures
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TrustPolicy the keys and the keyless identities trusted for signing the images, and the attestations required from the images
+type TrustPolicy struct {
+	Keys             []string          `json:"keys"`             // paths or KMS URIs of the public keys
+	Identities       []TrustedIdentity `json:"identities"`       // identities of the keyless signatures
+	AttestationTypes []string          `json:"attestationTypes"` // e.g. slsaprovenance, spdxjson, cyclonedx
+}
+
+// TrustedIdentity an identity of the keyless signatures, the subject of the certificate issued by the OIDC issuer
+type TrustedIdentity struct {
+	Issuer  string `json:"issuer"`  // e.g. https://token.actions.githubusercontent.com
+	Subject string `json:"subject"` // regular expression of the subject, e.g. ^https://github.com/org/.*
+}
+
+// ImageSignature the result of the verification of an image
+type ImageSignature struct {
+	Image        string   `json:"image"`
+	Signed       bool     `json:"signed"`       // signatures were found, trusted or not
+	Verified     bool     `json:"verified"`     // signed by a trusted key or identity, with all the required attestations
+	Signers      []string `json:"signers"`      // the trusted keys and identities of the verified signatures
+	Attestations []string `json:"attestations"` // the verified types of the attestations
+	Error        string   `json:"error,omitempty"`
+}
+
+// IsEmpty returns true when nothing is trusted, no image can be verified
+func (policy *TrustPolicy) IsEmpty() bool {
+	return len(policy.Keys) == 0 && len(policy.Identities) == 0
+}
+
+// ParseTrustedIdentity parses an identity of the form <issuer>=<subject>
+func ParseTrustedIdentity(s string) (TrustedIdentity, error) {
+	i := strings.Index(s, "=")
+	if i <= 0 || i == len(s)-1 {
+		return TrustedIdentity{}, fmt.Errorf("invalid identity '%s', expected <issuer>=<subject>", s)
+	}
+	return TrustedIdentity{Issuer: s[:i], Subject: s[i+1:]}, nil
+}
+
+func (identity *TrustedIdentity) String() string {
+	return fmt.Sprintf("%s=%s", identity.Issuer, identity.Subject)
+}
@@ -0,0 +1,19 @@
+package imagesignatures
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTrustedIdentity(t *testing.T) {
+	identity, err := ParseTrustedIdentity("https://token.actions.githubusercontent.com=^https://github.com/org/.*$")
+	assert.NoError(t, err)
+	assert.Equal(t, TrustedIdentity{Issuer: "https://token.actions.githubusercontent.com", Subject: "^https://github.com/org/.*$"}, identity)
+	assert.Equal(t, "https://token.actions.githubusercontent.com=^https://github.com/org/.*$", identity.String())
+
+	for _, s := range []string{"", "ci@example.com", "=ci@example.com", "https://accounts.example.com="} {
+		_, err := ParseTrustedIdentity(s)
+		assert.Error(t, err, s)
+	}
+}
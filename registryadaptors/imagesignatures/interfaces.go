@@ -0,0 +1,12 @@
+package imagesignatures
+
+type IImageSignatureVerifier interface {
+	// Login verifies the verifier can run, e.g. its binary is installed
+	Login() error
+
+	// For "help" purposes
+	DescribeVerifier() string
+
+	// VerifyImageSignature verifies the signatures and the attestations of the image by the trust policy of the verifier
+	VerifyImageSignature(image string) (*ImageSignature, error)
+}
@@ -0,0 +1,34 @@
+package v1
+
+import "time"
+
+// TrivyAdaptor scans the images by the trivy CLI (https://github.com/aquasecurity/trivy), the images are pulled by trivy
+// with the credentials of the docker config of the host
+type TrivyAdaptor struct {
+	path    string        // path of the trivy binary
+	offline bool          // the vulnerabilities database of trivy is not updated
+	timeout time.Duration // timeout of the scan of an image
+}
+
+// trivyReport the report of 'trivy image --format json'
+type trivyReport struct {
+	ArtifactName string        `json:"ArtifactName"`
+	Results      []trivyResult `json:"Results"`
+}
+
+type trivyResult struct {
+	Target          string               `json:"Target"`
+	Class           string               `json:"Class"`
+	Vulnerabilities []trivyVulnerability `json:"Vulnerabilities"`
+}
+
+type trivyVulnerability struct {
+	VulnerabilityID  string `json:"VulnerabilityID"`
+	PkgName          string `json:"PkgName"`
+	InstalledVersion string `json:"InstalledVersion"`
+	FixedVersion     string `json:"FixedVersion"`
+	Title            string `json:"Title"`
+	Description      string `json:"Description"`
+	Severity         string `json:"Severity"`
+	PrimaryURL       string `json:"PrimaryURL"`
+}
@@ -0,0 +1,129 @@
+package v1
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/cautils/logger/helpers"
+	"github.com/armosec/kubescape/registryadaptors/registryvulnerabilities"
+)
+
+const (
+	// DefaultTrivyPath the trivy binary, looked up in the PATH
+	DefaultTrivyPath = "trivy"
+	defaultTimeout   = 5 * time.Minute
+)
+
+func NewTrivyAdaptor(path string, offline bool) *TrivyAdaptor {
+	if path == "" {
+		path = DefaultTrivyPath
+	}
+	return &TrivyAdaptor{
+		path:    path,
+		offline: offline,
+		timeout: defaultTimeout,
+	}
+}
+
+// Login verifies trivy is installed, the registries are logged in by trivy
+func (trivyAdaptor *TrivyAdaptor) Login() error {
+	path, err := exec.LookPath(trivyAdaptor.path)
+	if err != nil {
+		return fmt.Errorf("trivy was not found (%s), install it from https://aquasecurity.github.io/trivy for scanning the images", err.Error())
+	}
+	trivyAdaptor.path = path
+	return nil
+}
+
+// GetImagesVulnerabilities scans the images one after the other, trivy locks its cache while scanning
+func (trivyAdaptor *TrivyAdaptor) GetImagesVulnerabilities(imageIDs []registryvulnerabilities.ContainerImageIdentifier) ([]registryvulnerabilities.ContainerImageVulnerabilityReport, error) {
+	resultList := make([]registryvulnerabilities.ContainerImageVulnerabilityReport, 0)
+	for i := range imageIDs {
+		logger.L().Debug("Scanning image", helpers.String("image", imageIDs[i].Tag))
+		result, err := trivyAdaptor.GetImageVulnerability(&imageIDs[i])
+		if err == nil {
+			resultList = append(resultList, *result)
+		} else {
+			logger.L().Warning("failed to scan image", helpers.String("image", imageIDs[i].Tag), helpers.Error(err))
+		}
+	}
+	return resultList, nil
+}
+
+func (trivyAdaptor *TrivyAdaptor) GetImageVulnerability(imageID *registryvulnerabilities.ContainerImageIdentifier) (*registryvulnerabilities.ContainerImageVulnerabilityReport, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), trivyAdaptor.timeout)
+	defer cancel()
+
+	args := []string{"image", "--quiet", "--format", "json"}
+	if trivyAdaptor.offline {
+		args = append(args, "--skip-db-update", "--offline-scan")
+	}
+	stdout, stderr := bytes.Buffer{}, bytes.Buffer{}
+	cmd := exec.CommandContext(ctx, trivyAdaptor.path, append(args, imageID.Tag)...)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("trivy failed: %s %s", err.Error(), strings.TrimSpace(stderr.String()))
+	}
+
+	report := trivyReport{}
+	if err := json.Unmarshal(stdout.Bytes(), &report); err != nil {
+		return nil, fmt.Errorf("failed to parse the trivy report: %w", err)
+	}
+	return &registryvulnerabilities.ContainerImageVulnerabilityReport{
+		ImageID:         *imageID,
+		Vulnerabilities: reportToVulnerabilities(&report, imageID.Tag),
+	}, nil
+}
+
+func (trivyAdaptor *TrivyAdaptor) DescribeAdaptor() string {
+	return "scans the images by trivy"
+}
+
+// GetImagesInformation is not supported - the trivy CLI scans the vulnerabilities of the images, the BOMs of the images are generated by the sbom package
+func (trivyAdaptor *TrivyAdaptor) GetImagesInformation(imageIDs []registryvulnerabilities.ContainerImageIdentifier) ([]registryvulnerabilities.ContainerImageInformation, error) {
+	return nil, fmt.Errorf("the trivy adaptor does not support the information of the images")
+}
+
+// GetImagesScanStatus is not supported - the trivy CLI scans the images on demand, there are no previous scans
+func (trivyAdaptor *TrivyAdaptor) GetImagesScanStatus(imageIDs []registryvulnerabilities.ContainerImageIdentifier) ([]registryvulnerabilities.ContainerImageScanStatus, error) {
+	return nil, fmt.Errorf("the trivy adaptor does not support the scan status of the images, the images are scanned on demand")
+}
+
+// reportToVulnerabilities converts the vulnerabilities of the report, the severities as reported by the ARMO backend (e.g. "Critical")
+func reportToVulnerabilities(report *trivyReport, imageTag string) []registryvulnerabilities.Vulnerability {
+	vulnerabilities := []registryvulnerabilities.Vulnerability{}
+	for _, result := range report.Results {
+		for _, v := range result.Vulnerabilities {
+			vulnerability := registryvulnerabilities.Vulnerability{
+				Name:               v.VulnerabilityID,
+				RelatedPackageName: v.PkgName,
+				PackageVersion:     v.InstalledVersion,
+				Link:               v.PrimaryURL,
+				Description:        v.Description,
+				Severity:           toSeverity(v.Severity),
+				Fixes:              []registryvulnerabilities.FixedIn{},
+			}
+			if vulnerability.Description == "" {
+				vulnerability.Description = v.Title
+			}
+			if v.FixedVersion != "" {
+				vulnerability.Fixes = append(vulnerability.Fixes, registryvulnerabilities.FixedIn{Name: v.PkgName, ImgTag: imageTag, Version: v.FixedVersion})
+			}
+			vulnerabilities = append(vulnerabilities, vulnerability)
+		}
+	}
+	return vulnerabilities
+}
+
+func toSeverity(severity string) string {
+	if severity == "" {
+		return "Unknown"
+	}
+	return strings.ToUpper(severity[:1]) + strings.ToLower(severity[1:])
+}
@@ -0,0 +1,70 @@
+package v1
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/armosec/kubescape/registryadaptors/registryvulnerabilities"
+	"github.com/stretchr/testify/assert"
+)
+
+const trivyReportMock = `{
+  "SchemaVersion": 2,
+  "ArtifactName": "nginx:1.21",
+  "Results": [
+    {"Target": "nginx:1.21 (debian 11.2)", "Class": "os-pkgs", "Vulnerabilities": [
+      {"VulnerabilityID": "CVE-2022-0778", "PkgName": "openssl", "InstalledVersion": "1.1.1k-1", "FixedVersion": "1.1.1n-0+deb11u1", "Severity": "HIGH", "Title": "infinite loop in BN_mod_sqrt()", "PrimaryURL": "https://avd.aquasec.com/nvd/cve-2022-0778"},
+      {"VulnerabilityID": "CVE-2021-3999", "PkgName": "libc6", "InstalledVersion": "2.31-13", "Severity": "CRITICAL", "Description": "off-by-one buffer overflow in getcwd()"}
+    ]},
+    {"Target": "usr/bin/app", "Class": "lang-pkgs"}
+  ]
+}`
+
+// fakeTrivy writes a script printing the report, and the arguments to args.txt
+func fakeTrivy(t *testing.T, report string, exitCode int) string {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "trivy")
+	script := fmt.Sprintf("#!/bin/sh\necho \"$@\" > %s\ncat <<'EOF'\n%s\nEOF\necho 'failed to pull the image' >&2\nexit %d\n", filepath.Join(dir, "args.txt"), report, exitCode)
+	assert.NoError(t, os.WriteFile(path, []byte(script), 0755))
+	return path
+}
+
+func TestTrivyAdaptor(t *testing.T) {
+	path := fakeTrivy(t, trivyReportMock, 0)
+	adaptor := NewTrivyAdaptor(path, true)
+	assert.NoError(t, adaptor.Login())
+
+	report, err := adaptor.GetImageVulnerability(&registryvulnerabilities.ContainerImageIdentifier{Tag: "nginx:1.21"})
+	assert.NoError(t, err)
+	if assert.Len(t, report.Vulnerabilities, 2) {
+		assert.Equal(t, registryvulnerabilities.Vulnerability{
+			Name: "CVE-2022-0778", RelatedPackageName: "openssl", PackageVersion: "1.1.1k-1", Severity: "High",
+			Description: "infinite loop in BN_mod_sqrt()", Link: "https://avd.aquasec.com/nvd/cve-2022-0778",
+			Fixes: []registryvulnerabilities.FixedIn{{Name: "openssl", ImgTag: "nginx:1.21", Version: "1.1.1n-0+deb11u1"}},
+		}, report.Vulnerabilities[0])
+		assert.Equal(t, "Critical", report.Vulnerabilities[1].Severity)
+		assert.Empty(t, report.Vulnerabilities[1].Fixes)
+	}
+	args, _ := os.ReadFile(filepath.Join(filepath.Dir(path), "args.txt"))
+	assert.Equal(t, "image --quiet --format json --skip-db-update --offline-scan nginx:1.21\n", string(args))
+
+	// the images failing to scan are skipped
+	failing := NewTrivyAdaptor(fakeTrivy(t, "", 1), false)
+	_, err = failing.GetImageVulnerability(&registryvulnerabilities.ContainerImageIdentifier{Tag: "private/app"})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "failed to pull the image")
+	}
+	reports, err := failing.GetImagesVulnerabilities([]registryvulnerabilities.ContainerImageIdentifier{{Tag: "private/app"}})
+	assert.NoError(t, err)
+	assert.Empty(t, reports)
+
+	assert.Error(t, NewTrivyAdaptor(filepath.Join(t.TempDir(), "missing"), false).Login())
+
+	// the scan status and the information of the images are not supported by the trivy CLI
+	_, err = adaptor.GetImagesScanStatus([]registryvulnerabilities.ContainerImageIdentifier{{Tag: "nginx:1.21"}})
+	assert.Error(t, err)
+	_, err = adaptor.GetImagesInformation([]registryvulnerabilities.ContainerImageIdentifier{{Tag: "nginx:1.21"}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,48 @@
+package resourcehandler
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/armosec/k8s-interface/k8sinterface"
+	"github.com/armosec/k8s-interface/workloadinterface"
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/cloudconnector"
+)
+
+const (
+	ClusterPostureObjectGroup   = "cloud.kubescape.cloud"
+	ClusterPostureObjectVersion = "v1beta0"
+	ClusterPostureObjectKind    = "ClusterPosture"
+)
+
+var (
+	KS_AKS_SUBSCRIPTION_ENV_VAR   = "KS_AKS_SUBSCRIPTION"
+	KS_AKS_RESOURCE_GROUP_ENV_VAR = "KS_AKS_RESOURCE_GROUP"
+)
+
+// getAKSClusterPosture reads the posture of the aks cluster from the Azure Resource Manager, the subscription and the resource group
+// of the cluster are not part of its kubeconfig context
+func getAKSClusterPosture(cluster string) (*cloudconnector.ClusterPosture, error) {
+	posture, err := cloudconnector.NewAKSClient().GetClusterPosture(os.Getenv(KS_AKS_SUBSCRIPTION_ENV_VAR), os.Getenv(KS_AKS_RESOURCE_GROUP_ENV_VAR), cluster)
+	if err != nil {
+		return nil, fmt.Errorf("%w. Set %s and %s, and log in by the azure cli (az login)", err, KS_AKS_SUBSCRIPTION_ENV_VAR, KS_AKS_RESOURCE_GROUP_ENV_VAR)
+	}
+	return posture, nil
+}
+
+// setClusterPosture adds the ClusterPosture object of the cluster, evaluated by the controls of the settings of the cloud provider
+func setClusterPosture(clusterName string, posture *cloudconnector.ClusterPosture, allResources map[string]workloadinterface.IMetadata, k8sResourcesMap *cautils.K8SResources) {
+	wl := clusterPostureToIMetadata(clusterName, posture)
+	allResources[wl.GetID()] = wl
+	(*k8sResourcesMap)[k8sinterface.JoinResourceTriplets(ClusterPostureObjectGroup, ClusterPostureObjectVersion, ClusterPostureObjectKind)] = []string{wl.GetID()}
+}
+
+func clusterPostureToIMetadata(clusterName string, posture *cloudconnector.ClusterPosture) workloadinterface.IMetadata {
+	obj := map[string]interface{}{}
+	obj["kind"] = ClusterPostureObjectKind
+	obj["apiVersion"] = k8sinterface.JoinGroupVersion(ClusterPostureObjectGroup, ClusterPostureObjectVersion)
+	obj["metadata"] = map[string]interface{}{"name": clusterName}
+	obj["data"] = *posture
+	return workloadinterface.NewWorkloadObj(obj)
+}
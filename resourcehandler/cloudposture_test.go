@@ -0,0 +1,36 @@
+package resourcehandler
+
+import (
+	"testing"
+
+	cloudsupportv1 "github.com/armosec/k8s-interface/cloudsupport/v1"
+	"github.com/armosec/k8s-interface/k8sinterface"
+	"github.com/armosec/k8s-interface/workloadinterface"
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/cloudconnector"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetClusterPosture(t *testing.T) {
+	describe, err := cloudsupportv1.GetClusterDescribeEKS(cloudsupportv1.NewEKSSupportMock(), "ca-terraform-eks-dev-stage", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	posture, err := cloudconnector.PostureFromDescribe("eks", describe.GetData())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	k8sResources := cautils.K8SResources{}
+	allResources := map[string]workloadinterface.IMetadata{}
+	setClusterPosture("ca-terraform-eks-dev-stage", posture, allResources, &k8sResources)
+
+	ids := k8sResources[k8sinterface.JoinResourceTriplets(ClusterPostureObjectGroup, ClusterPostureObjectVersion, ClusterPostureObjectKind)]
+	if assert.Len(t, ids, 1) {
+		resource := allResources[ids[0]]
+		assert.Equal(t, ClusterPostureObjectKind, resource.GetKind())
+		assert.Equal(t, "ca-terraform-eks-dev-stage", resource.GetName())
+		assert.Equal(t, "eks", resource.GetObject()["data"].(cloudconnector.ClusterPosture).Provider)
+	}
+	assert.NotNil(t, posture.PublicEndpoint, "the endpoint access of the describe-cluster output")
+}
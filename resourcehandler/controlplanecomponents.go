@@ -0,0 +1,218 @@
+package resourcehandler
+
+import (
+	"context"
+	"encoding/json"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/armosec/k8s-interface/k8sinterface"
+	"github.com/armosec/k8s-interface/workloadinterface"
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/hostsensorutils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	ControlPlaneComponentObjectGroup   = "controlplane.kubescape.cloud"
+	ControlPlaneComponentObjectVersion = "v1beta0"
+	ControlPlaneComponentObjectKind    = "ControlPlaneComponent"
+)
+
+// The sources of the command lines of the components
+const (
+	ControlPlaneSourceManifest = "manifest" // the static pod manifest of the node, read by the host sensor
+	ControlPlaneSourcePod      = "pod"      // the mirror pod of the static pod, listed from the cluster
+	ControlPlaneSourceFile     = "file"     // a scanned static pod manifest file
+)
+
+// controlPlanePodsSelector the labels of the static pods of the control plane set by kubeadm
+const controlPlanePodsSelector = "tier=control-plane"
+
+// controlPlaneComponents the executables of the components of the control plane
+var controlPlaneComponents = map[string]bool{"kube-apiserver": true, "kube-controller-manager": true, "kube-scheduler": true, "etcd": true}
+
+// ControlPlaneComponent the command line of a component of the control plane - the flags are evaluated by the CIS benchmark controls of
+// the API server, the controller manager, the scheduler and etcd
+type ControlPlaneComponent struct {
+	Component string            `json:"component"`
+	Node      string            `json:"node,omitempty"`
+	Source    string            `json:"source"`
+	Path      string            `json:"path,omitempty"` // the path of the static pod manifest on the node
+	Command   []string          `json:"command"`
+	Flags     map[string]string `json:"flags"` // by the name of the flag without the dashes, "true" for the flags without a value
+}
+
+// controlPlaneComponentsRequired returns true when the scanned frameworks evaluate the components of the control plane
+func controlPlaneComponentsRequired(k8sResourcesMap *cautils.K8SResources) bool {
+	_, ok := (*k8sResourcesMap)[controlPlaneComponentsTriplet()]
+	return ok
+}
+
+func controlPlaneComponentsTriplet() string {
+	return k8sinterface.JoinResourceTriplets(ControlPlaneComponentObjectGroup, ControlPlaneComponentObjectVersion, ControlPlaneComponentObjectKind)
+}
+
+// listControlPlanePods lists the static pods of the control plane, regardless of the scanned namespaces. The managed clusters have no
+// such pods, their control plane is evaluated by the cloud controls
+func (k8sHandler *K8sResourceHandler) listControlPlanePods(ctx context.Context) ([]workloadinterface.IMetadata, error) {
+	podsResource := schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+	list, err := k8sHandler.k8s.DynamicClient.Resource(podsResource).Namespace("kube-system").List(ctx, metav1.ListOptions{LabelSelector: controlPlanePodsSelector})
+	if err != nil {
+		return nil, err
+	}
+	pods := make([]workloadinterface.IMetadata, 0, len(list.Items))
+	for i := range list.Items {
+		pods = append(pods, workloadinterface.NewWorkloadObj(list.Items[i].Object))
+	}
+	return pods, nil
+}
+
+// collectControlPlaneComponents adds the components of the control plane of the cluster, by the host sensor and the mirror pods
+func (k8sHandler *K8sResourceHandler) collectControlPlaneComponents(ctx context.Context, allResources map[string]workloadinterface.IMetadata, k8sResourcesMap *cautils.K8SResources) error {
+	if !controlPlaneComponentsRequired(k8sResourcesMap) {
+		return nil
+	}
+	logger.L().Debug("Collecting control plane components")
+
+	objects, err := k8sHandler.listControlPlanePods(ctx)
+	for _, wl := range allResources {
+		if wl.GetKind() == hostsensorutils.KindControlPlaneInfo {
+			objects = append(objects, wl)
+		}
+	}
+	setControlPlaneComponents(objects, ControlPlaneSourcePod, allResources, k8sResourcesMap)
+	return err
+}
+
+// setControlPlaneComponents adds the ControlPlaneComponent objects of the static pods - of the manifests of the ControlPlaneInfo objects
+// of the host sensor, of the mirror pods or of the scanned manifest files. A component of a node is added once, the manifests first
+func setControlPlaneComponents(objects []workloadinterface.IMetadata, source string, allResources map[string]workloadinterface.IMetadata, k8sResourcesMap *cautils.K8SResources) {
+	components := map[string]workloadinterface.IMetadata{}
+	for _, id := range (*k8sResourcesMap)[controlPlaneComponentsTriplet()] {
+		components[id] = allResources[id]
+	}
+
+	// the manifests first, the mirror pods may be outdated
+	sort.SliceStable(objects, func(i, j int) bool {
+		return objects[i].GetKind() == hostsensorutils.KindControlPlaneInfo && objects[j].GetKind() != hostsensorutils.KindControlPlaneInfo
+	})
+	for _, obj := range objects {
+		switch obj.GetKind() {
+		case hostsensorutils.KindControlPlaneInfo:
+			for _, component := range manifestsComponents(obj) {
+				addControlPlaneComponent(components, component.Component+"-"+component.Node, component) // the name of the mirror pod
+			}
+		case "Pod":
+			if component := podComponent(obj.GetObject(), source); component != nil {
+				addControlPlaneComponent(components, obj.GetName(), component)
+			}
+		}
+	}
+
+	ids := make([]string, 0, len(components))
+	for id, wl := range components {
+		allResources[id] = wl
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	(*k8sResourcesMap)[controlPlaneComponentsTriplet()] = ids
+}
+
+func addControlPlaneComponent(components map[string]workloadinterface.IMetadata, name string, component *ControlPlaneComponent) {
+	wl := controlPlaneComponentToIMetadata(name, component)
+	if _, ok := components[wl.GetID()]; !ok {
+		components[wl.GetID()] = wl
+	}
+}
+
+// manifestsComponents returns the components of the static pod manifests of a ControlPlaneInfo object
+func manifestsComponents(obj workloadinterface.IMetadata) []*ControlPlaneComponent {
+	info := struct {
+		Data hostsensorutils.ControlPlaneInfo `json:"data"`
+	}{}
+	b, err := json.Marshal(obj.GetObject())
+	if err != nil || json.Unmarshal(b, &info) != nil {
+		return nil
+	}
+	components := []*ControlPlaneComponent{}
+	for _, file := range info.Data.ManifestFiles {
+		if component := podComponent(file.Config, ControlPlaneSourceManifest); component != nil {
+			component.Node = obj.GetName()
+			component.Path = file.Path
+			components = append(components, component)
+		}
+	}
+	return components
+}
+
+// podComponent returns the component run by the first container of the pod running a component of the control plane, nil when none
+func podComponent(pod map[string]interface{}, source string) *ControlPlaneComponent {
+	wl := workloadinterface.NewWorkloadObj(pod)
+	containers, err := wl.GetContainers()
+	if err != nil {
+		return nil
+	}
+	for i := range containers {
+		command := append(append([]string{}, containers[i].Command...), containers[i].Args...)
+		command = unwrapShell(command)
+		if len(command) == 0 || !controlPlaneComponents[path.Base(command[0])] {
+			continue
+		}
+		component := &ControlPlaneComponent{
+			Component: path.Base(command[0]),
+			Source:    source,
+			Command:   command,
+			Flags:     parseFlags(command[1:]),
+		}
+		if source != ControlPlaneSourceFile {
+			if nodeName, ok := workloadinterface.InspectMap(pod, "spec", "nodeName"); ok {
+				component.Node, _ = nodeName.(string)
+			}
+		}
+		return component
+	}
+	return nil
+}
+
+// unwrapShell returns the command run by a shell, e.g. ["/bin/sh", "-c", "kube-apiserver --flag=value"]
+func unwrapShell(command []string) []string {
+	if len(command) == 3 && (path.Base(command[0]) == "sh" || path.Base(command[0]) == "bash") && command[1] == "-c" {
+		return strings.Fields(command[2])
+	}
+	return command
+}
+
+// parseFlags parses the flags of a command line, --name=value, --name value and --name
+func parseFlags(args []string) map[string]string {
+	flags := map[string]string{}
+	for i := 0; i < len(args); i++ {
+		if !strings.HasPrefix(args[i], "-") {
+			continue
+		}
+		name := strings.TrimLeft(args[i], "-")
+		if n := strings.Index(name, "="); n >= 0 {
+			flags[name[:n]] = name[n+1:]
+			continue
+		}
+		if i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
+			flags[name] = args[i+1]
+			i++
+			continue
+		}
+		flags[name] = "true"
+	}
+	return flags
+}
+
+func controlPlaneComponentToIMetadata(name string, component *ControlPlaneComponent) workloadinterface.IMetadata {
+	obj := map[string]interface{}{}
+	obj["kind"] = ControlPlaneComponentObjectKind
+	obj["apiVersion"] = k8sinterface.JoinGroupVersion(ControlPlaneComponentObjectGroup, ControlPlaneComponentObjectVersion)
+	obj["metadata"] = map[string]interface{}{"name": name}
+	obj["data"] = *component
+	return workloadinterface.NewWorkloadObj(obj)
+}
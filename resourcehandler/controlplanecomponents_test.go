@@ -0,0 +1,63 @@
+package resourcehandler
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/armosec/k8s-interface/workloadinterface"
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/hostsensorutils"
+	"github.com/armosec/opa-utils/objectsenvelopes/hostsensor"
+	"github.com/stretchr/testify/assert"
+)
+
+func staticPod(name, node string, command ...interface{}) map[string]interface{} {
+	spec := map[string]interface{}{"containers": []interface{}{map[string]interface{}{"name": name, "command": command}}}
+	if node != "" {
+		spec["nodeName"] = node
+	}
+	return map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata":   map[string]interface{}{"name": name, "namespace": "kube-system"},
+		"spec":       spec,
+	}
+}
+
+func TestParseFlags(t *testing.T) {
+	flags := parseFlags([]string{"--anonymous-auth=false", "--authorization-mode", "Node,RBAC", "--profiling", "-v=2", "--data-dir=/var/lib/etcd"})
+	assert.Equal(t, map[string]string{"anonymous-auth": "false", "authorization-mode": "Node,RBAC", "profiling": "true", "v": "2", "data-dir": "/var/lib/etcd"}, flags)
+}
+
+func TestSetControlPlaneComponents(t *testing.T) {
+	manifests, _ := json.Marshal(hostsensorutils.ControlPlaneInfo{ManifestFiles: []hostsensorutils.FileInfo{
+		{Path: "/etc/kubernetes/manifests/kube-apiserver.yaml", Config: staticPod("kube-apiserver", "", "kube-apiserver", "--anonymous-auth=false")},
+		{Path: "/etc/kubernetes/admin.conf"},
+	}})
+	info := &hostsensor.HostSensorDataEnvelope{ApiVersion: "hostdata.kubescape.cloud/v1beta0", Kind: hostsensorutils.KindControlPlaneInfo, Data: manifests}
+	info.SetName("control-plane-1")
+
+	objects := []workloadinterface.IMetadata{
+		workloadinterface.NewWorkloadObj(staticPod("kube-apiserver-control-plane-1", "control-plane-1", "kube-apiserver", "--anonymous-auth=true")),
+		workloadinterface.NewWorkloadObj(staticPod("etcd-control-plane-1", "control-plane-1", "/bin/sh", "-c", "etcd --client-cert-auth")),
+		workloadinterface.NewWorkloadObj(staticPod("coredns", "control-plane-1", "/coredns")),
+		info,
+	}
+	k8sResources := cautils.K8SResources{controlPlaneComponentsTriplet(): nil}
+	allResources := map[string]workloadinterface.IMetadata{}
+	setControlPlaneComponents(objects, ControlPlaneSourcePod, allResources, &k8sResources)
+
+	ids := k8sResources[controlPlaneComponentsTriplet()]
+	if assert.Len(t, ids, 2, "the component of the manifest and the mirror pod is added once") {
+		etcd := allResources[ids[0]].GetObject()["data"].(ControlPlaneComponent)
+		assert.Equal(t, "etcd-control-plane-1", allResources[ids[0]].GetName())
+		assert.Equal(t, ControlPlaneComponent{Component: "etcd", Node: "control-plane-1", Source: ControlPlaneSourcePod,
+			Command: []string{"etcd", "--client-cert-auth"}, Flags: map[string]string{"client-cert-auth": "true"}}, etcd)
+
+		apiServer := allResources[ids[1]].GetObject()["data"].(ControlPlaneComponent)
+		assert.Equal(t, "kube-apiserver-control-plane-1", allResources[ids[1]].GetName())
+		assert.Equal(t, ControlPlaneSourceManifest, apiServer.Source, "the manifest overrides the mirror pod")
+		assert.Equal(t, "/etc/kubernetes/manifests/kube-apiserver.yaml", apiServer.Path)
+		assert.Equal(t, "false", apiServer.Flags["anonymous-auth"])
+	}
+}
@@ -41,3 +41,9 @@ func TestIncludeNamespacesSelectors(t *testing.T) {
 	assert.Equal(t, "metadata.name==default", selectors2[0])
 	assert.Equal(t, "metadata.name==ingress", selectors2[1])
 }
+
+func TestJoinSelectors(t *testing.T) {
+	assert.Equal(t, "", joinSelectors("", ""))
+	assert.Equal(t, "app=web", joinSelectors("", "app=web"))
+	assert.Equal(t, "metadata.namespace!=default,metadata.namespace!=ingress,metadata.name=nginx", joinSelectors("metadata.namespace!=default,metadata.namespace!=ingress,", "metadata.name=nginx"))
+}
@@ -1,8 +1,8 @@
 package resourcehandler
 
 import (
+	"context"
 	"fmt"
-	"os"
 
 	"github.com/armosec/armoapi-go/armotypes"
 	"github.com/armosec/k8s-interface/workloadinterface"
@@ -10,50 +10,93 @@ import (
 
 	"github.com/armosec/k8s-interface/k8sinterface"
 	"github.com/armosec/kubescape/cautils"
-	"github.com/armosec/opa-utils/reporthandling"
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/cautils/logger/helpers"
 )
 
 // FileResourceHandler handle resources from files and URLs
 type FileResourceHandler struct {
-	inputPatterns    []string
-	registryAdaptors *RegistryAdaptors
+	inputPatterns      []string
+	helmChart          *cautils.HelmChartOptions
+	kustomizeDirectory string
+	gitOptions         *cautils.GitOptions
+	registryAdaptors   *RegistryAdaptors
 }
 
-func NewFileResourceHandler(inputPatterns []string, registryAdaptors *RegistryAdaptors) *FileResourceHandler {
+func NewFileResourceHandler(inputPatterns []string, gitOptions *cautils.GitOptions, registryAdaptors *RegistryAdaptors) *FileResourceHandler {
 	k8sinterface.InitializeMapResourcesMock() // initialize the resource map
 	return &FileResourceHandler{
 		inputPatterns:    inputPatterns,
+		gitOptions:       gitOptions,
 		registryAdaptors: registryAdaptors,
 	}
 }
 
-func (fileHandler *FileResourceHandler) GetResources(frameworks []reporthandling.Framework, designator *armotypes.PortalDesignator) (*cautils.K8SResources, map[string]workloadinterface.IMetadata, error) {
+// NewHelmChartResourceHandler handle the rendered resources of a local helm chart
+func NewHelmChartResourceHandler(helmChart *cautils.HelmChartOptions, registryAdaptors *RegistryAdaptors) *FileResourceHandler {
+	k8sinterface.InitializeMapResourcesMock() // initialize the resource map
+	return &FileResourceHandler{
+		helmChart:        helmChart,
+		registryAdaptors: registryAdaptors,
+	}
+}
+
+// NewKustomizeResourceHandler handle the built resources of a kustomization directory
+func NewKustomizeResourceHandler(kustomizeDirectory string, registryAdaptors *RegistryAdaptors) *FileResourceHandler {
+	k8sinterface.InitializeMapResourcesMock() // initialize the resource map
+	return &FileResourceHandler{
+		kustomizeDirectory: kustomizeDirectory,
+		registryAdaptors:   registryAdaptors,
+	}
+}
+
+func (fileHandler *FileResourceHandler) GetResources(ctx context.Context, sessionObj *cautils.OPASessionObj, designator *armotypes.PortalDesignator) (*cautils.K8SResources, map[string]workloadinterface.IMetadata, error) {
 
 	// build resources map
 	// map resources based on framework required resources: map["/group/version/kind"][]<k8s workloads ids>
-	k8sResources := setResourceMap(frameworks)
+	k8sResources := setResourceMap(sessionObj.Frameworks)
 	allResources := map[string]workloadinterface.IMetadata{}
 
 	workloads := []workloadinterface.IMetadata{}
 
-	// load resource from local file system
-	w, err := cautils.LoadResourcesFromFiles(fileHandler.inputPatterns)
+	// load resource from local file system, render them from the helm chart or build them from the kustomization
+	var w []workloadinterface.IMetadata
+	var sources map[string]cautils.ResourceSource
+	var err error
+	switch {
+	case fileHandler.helmChart != nil:
+		w, sources, err = cautils.LoadResourcesFromHelmChart(fileHandler.helmChart)
+	case fileHandler.kustomizeDirectory != "":
+		w, sources, err = cautils.LoadResourcesFromKustomize(fileHandler.kustomizeDirectory)
+	default:
+		w, sources, err = cautils.LoadResourcesFromFiles(fileHandler.inputPatterns)
+	}
 	if err != nil {
 		return nil, allResources, err
 	}
 	if w != nil {
 		workloads = append(workloads, w...)
 	}
+	for resourceID, source := range sources {
+		sessionObj.ResourceSource[resourceID] = source
+	}
 
 	// load resources from url
-	w, err = loadResourcesFromUrl(fileHandler.inputPatterns)
+	w, sources, err = loadResourcesFromUrl(fileHandler.inputPatterns, fileHandler.gitOptions)
 	if err != nil {
 		return nil, allResources, err
 	}
 	if w != nil {
 		workloads = append(workloads, w...)
 	}
+	for resourceID, source := range sources {
+		sessionObj.ResourceSource[resourceID] = source
+	}
 
+	// the loading of the files and of the repositories is not cancellable, the scan is stopped once loaded
+	if err := ctx.Err(); err != nil {
+		return nil, allResources, err
+	}
 	if len(workloads) == 0 {
 		return nil, allResources, fmt.Errorf("empty list of workloads - no workloads found")
 	}
@@ -74,8 +117,19 @@ func (fileHandler *FileResourceHandler) GetResources(frameworks []reporthandling
 	}
 
 	if err := fileHandler.registryAdaptors.collectImagesVulnerabilities(k8sResources, allResources); err != nil {
-		cautils.WarningDisplay(os.Stderr, "Warning: failed to collect images vulnerabilities: %s\n", err.Error())
+		logger.L().Warning("failed to collect images vulnerabilities", helpers.Error(err))
+	}
+	if err := fileHandler.registryAdaptors.collectImagesSignatures(k8sResources, allResources); err != nil {
+		logger.L().Warning("failed to verify images signatures", helpers.Error(err))
+	}
+	if err := fileHandler.registryAdaptors.collectImagesMetadata(k8sResources, allResources); err != nil {
+		logger.L().Warning("failed to look up images metadata", helpers.Error(err))
+	}
+	if controlPlaneComponentsRequired(k8sResources) {
+		setControlPlaneComponents(workloads, ControlPlaneSourceFile, allResources, k8sResources)
 	}
+	setNetworkPolicyCoverage(allResources, k8sResources)
+	setSecretExposure(allResources, k8sResources)
 
 	return k8sResources, allResources, nil
 
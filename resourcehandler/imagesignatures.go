@@ -0,0 +1,58 @@
+package resourcehandler
+
+import (
+	"github.com/armosec/k8s-interface/k8sinterface"
+	"github.com/armosec/k8s-interface/workloadinterface"
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/cautils/logger/helpers"
+	"github.com/armosec/kubescape/registryadaptors/imagesignatures"
+)
+
+const (
+	ImageSignatureObjectGroup   = "image.signatures.com"
+	ImageSignatureObjectVersion = "v1"
+	ImageSignatureObjectKind    = "ImageSignature"
+)
+
+// SetSignatureVerifier sets the verifier of the signatures of the images of the scanned workloads
+func (registryAdaptors *RegistryAdaptors) SetSignatureVerifier(verifier imagesignatures.IImageSignatureVerifier) {
+	registryAdaptors.signatureVerifier = verifier
+}
+
+// collectImagesSignatures verifies the signatures of the images, the results are ImageSignature objects evaluated by the controls of the signatures
+func (registryAdaptors *RegistryAdaptors) collectImagesSignatures(k8sResourcesMap *cautils.K8SResources, allResources map[string]workloadinterface.IMetadata) error {
+	if registryAdaptors.signatureVerifier == nil {
+		return nil
+	}
+	logger.L().Debug("Verifying images signatures")
+	if err := registryAdaptors.signatureVerifier.Login(); err != nil {
+		return err
+	}
+
+	metaObjs := []workloadinterface.IMetadata{}
+	for _, image := range listImagesTags(k8sResourcesMap, allResources) {
+		signature, err := registryAdaptors.signatureVerifier.VerifyImageSignature(image)
+		if err != nil {
+			logger.L().Warning("failed to verify image signature", helpers.String("image", image), helpers.Error(err))
+			continue
+		}
+		logger.L().Debug("Verified image signature", helpers.String("image", image), helpers.Interface("verified", signature.Verified))
+		metaObjs = append(metaObjs, signatureToIMetadata(signature))
+	}
+
+	for i := range metaObjs {
+		allResources[metaObjs[i].GetID()] = metaObjs[i]
+	}
+	(*k8sResourcesMap)[k8sinterface.JoinResourceTriplets(ImageSignatureObjectGroup, ImageSignatureObjectVersion, ImageSignatureObjectKind)] = workloadinterface.ListMetaIDs(metaObjs)
+	return nil
+}
+
+func signatureToIMetadata(signature *imagesignatures.ImageSignature) workloadinterface.IMetadata {
+	obj := map[string]interface{}{}
+	obj["kind"] = ImageSignatureObjectKind
+	obj["apiVersion"] = k8sinterface.JoinGroupVersion(ImageSignatureObjectGroup, ImageSignatureObjectVersion)
+	obj["metadata"] = map[string]interface{}{"name": signature.Image} // store image tag as object name
+	obj["data"] = *signature
+	return workloadinterface.NewWorkloadObj(obj)
+}
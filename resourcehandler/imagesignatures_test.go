@@ -0,0 +1,58 @@
+package resourcehandler
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/armosec/k8s-interface/k8sinterface"
+	"github.com/armosec/k8s-interface/workloadinterface"
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/registryadaptors/imagesignatures"
+	"github.com/stretchr/testify/assert"
+)
+
+type signatureVerifierMock struct {
+	verified map[string]bool
+}
+
+func (mock *signatureVerifierMock) Login() error             { return nil }
+func (mock *signatureVerifierMock) DescribeVerifier() string { return "mock" }
+func (mock *signatureVerifierMock) VerifyImageSignature(image string) (*imagesignatures.ImageSignature, error) {
+	verified, ok := mock.verified[image]
+	if !ok {
+		return nil, fmt.Errorf("failed to pull the signatures of '%s'", image)
+	}
+	return &imagesignatures.ImageSignature{Image: image, Signed: true, Verified: verified, Signers: []string{}, Attestations: []string{}}, nil
+}
+
+func TestCollectImagesSignatures(t *testing.T) {
+	deployment := workloadinterface.NewWorkloadObj(map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]interface{}{"name": "web", "namespace": "default"},
+		"spec": map[string]interface{}{"template": map[string]interface{}{"spec": map[string]interface{}{
+			"containers":     []interface{}{map[string]interface{}{"name": "web", "image": "nginx:1.21"}, map[string]interface{}{"name": "proxy", "image": "envoy:1.20"}},
+			"initContainers": []interface{}{map[string]interface{}{"name": "init", "image": "private/init"}},
+		}}},
+	})
+	k8sResources := cautils.K8SResources{"apps/v1/deployments": []string{deployment.GetID()}}
+	allResources := map[string]workloadinterface.IMetadata{deployment.GetID(): deployment}
+
+	// no verifier, nothing is verified
+	assert.NoError(t, (&RegistryAdaptors{}).collectImagesSignatures(&k8sResources, allResources))
+	assert.Len(t, allResources, 1)
+
+	registryAdaptors := &RegistryAdaptors{}
+	registryAdaptors.SetSignatureVerifier(&signatureVerifierMock{verified: map[string]bool{"nginx:1.21": true, "envoy:1.20": false}})
+	assert.NoError(t, registryAdaptors.collectImagesSignatures(&k8sResources, allResources))
+
+	signatures := k8sResources[k8sinterface.JoinResourceTriplets(ImageSignatureObjectGroup, ImageSignatureObjectVersion, ImageSignatureObjectKind)]
+	assert.Len(t, signatures, 2, "the images failing to verify are skipped")
+	verified := map[string]bool{}
+	for _, id := range signatures {
+		resource := allResources[id]
+		assert.Equal(t, ImageSignatureObjectKind, resource.GetKind())
+		verified[resource.GetName()] = resource.GetObject()["data"].(imagesignatures.ImageSignature).Verified
+	}
+	assert.Equal(t, map[string]bool{"nginx:1.21": true, "envoy:1.20": false}, verified)
+}
@@ -0,0 +1,55 @@
+package resourcehandler
+
+import (
+	"github.com/armosec/k8s-interface/k8sinterface"
+	"github.com/armosec/k8s-interface/workloadinterface"
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/cautils/logger/helpers"
+	"github.com/armosec/kubescape/registryadaptors/imageregistry"
+)
+
+const (
+	ImageMetadataObjectGroup   = "image.metadata.com"
+	ImageMetadataObjectVersion = "v1"
+	ImageMetadataObjectKind    = "ImageMetadata"
+)
+
+// SetMetadataClient sets the client looking up the configurations of the images of the scanned workloads in their registries
+func (registryAdaptors *RegistryAdaptors) SetMetadataClient(client imageregistry.IImageMetadataClient) {
+	registryAdaptors.metadataClient = client
+}
+
+// collectImagesMetadata looks up the configurations of the images, the results are ImageMetadata objects evaluated by the controls of the
+// images configurations (user, exposed ports, base image)
+func (registryAdaptors *RegistryAdaptors) collectImagesMetadata(k8sResourcesMap *cautils.K8SResources, allResources map[string]workloadinterface.IMetadata) error {
+	if registryAdaptors.metadataClient == nil {
+		return nil
+	}
+	logger.L().Debug("Looking up images metadata")
+
+	metaObjs := []workloadinterface.IMetadata{}
+	for _, image := range listImagesTags(k8sResourcesMap, allResources) {
+		metadata, err := registryAdaptors.metadataClient.GetImageMetadata(image)
+		if err != nil {
+			logger.L().Warning("failed to look up image metadata", helpers.String("image", image), helpers.Error(err))
+			continue
+		}
+		metaObjs = append(metaObjs, imageMetadataToIMetadata(metadata))
+	}
+
+	for i := range metaObjs {
+		allResources[metaObjs[i].GetID()] = metaObjs[i]
+	}
+	(*k8sResourcesMap)[k8sinterface.JoinResourceTriplets(ImageMetadataObjectGroup, ImageMetadataObjectVersion, ImageMetadataObjectKind)] = workloadinterface.ListMetaIDs(metaObjs)
+	return nil
+}
+
+func imageMetadataToIMetadata(metadata *imageregistry.ImageMetadata) workloadinterface.IMetadata {
+	obj := map[string]interface{}{}
+	obj["kind"] = ImageMetadataObjectKind
+	obj["apiVersion"] = k8sinterface.JoinGroupVersion(ImageMetadataObjectGroup, ImageMetadataObjectVersion)
+	obj["metadata"] = map[string]interface{}{"name": metadata.Image} // store image tag as object name
+	obj["data"] = *metadata
+	return workloadinterface.NewWorkloadObj(obj)
+}
@@ -0,0 +1,53 @@
+package resourcehandler
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/armosec/k8s-interface/k8sinterface"
+	"github.com/armosec/k8s-interface/workloadinterface"
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/registryadaptors/imageregistry"
+	"github.com/stretchr/testify/assert"
+)
+
+type metadataClientMock struct {
+	users map[string]string
+}
+
+func (mock *metadataClientMock) GetImageMetadata(image string) (*imageregistry.ImageMetadata, error) {
+	user, ok := mock.users[image]
+	if !ok {
+		return nil, fmt.Errorf("failed to fetch the manifest of '%s': 401 Unauthorized", image)
+	}
+	return &imageregistry.ImageMetadata{Image: image, User: user, ExposedPorts: []string{}, Labels: map[string]string{}}, nil
+}
+
+func TestCollectImagesMetadata(t *testing.T) {
+	deployment := workloadinterface.NewWorkloadObj(map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]interface{}{"name": "web", "namespace": "default"},
+		"spec": map[string]interface{}{"template": map[string]interface{}{"spec": map[string]interface{}{
+			"containers": []interface{}{map[string]interface{}{"name": "web", "image": "nginx:1.21"}, map[string]interface{}{"name": "app", "image": "private.example.com/app:1.0"}},
+		}}},
+	})
+	k8sResources := cautils.K8SResources{"apps/v1/deployments": []string{deployment.GetID()}}
+	allResources := map[string]workloadinterface.IMetadata{deployment.GetID(): deployment}
+
+	// no client, nothing is looked up
+	assert.NoError(t, (&RegistryAdaptors{}).collectImagesMetadata(&k8sResources, allResources))
+	assert.Len(t, allResources, 1)
+
+	registryAdaptors := &RegistryAdaptors{}
+	registryAdaptors.SetMetadataClient(&metadataClientMock{users: map[string]string{"private.example.com/app:1.0": "1000"}})
+	assert.NoError(t, registryAdaptors.collectImagesMetadata(&k8sResources, allResources))
+
+	metadata := k8sResources[k8sinterface.JoinResourceTriplets(ImageMetadataObjectGroup, ImageMetadataObjectVersion, ImageMetadataObjectKind)]
+	if assert.Len(t, metadata, 1, "the images failing to look up are skipped") {
+		resource := allResources[metadata[0]]
+		assert.Equal(t, ImageMetadataObjectKind, resource.GetKind())
+		assert.Equal(t, "private.example.com/app:1.0", resource.GetName())
+		assert.Equal(t, "1000", resource.GetObject()["data"].(imageregistry.ImageMetadata).User)
+	}
+}
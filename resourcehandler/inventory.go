@@ -0,0 +1,100 @@
+package resourcehandler
+
+import (
+	"context"
+	"sort"
+
+	"github.com/armosec/k8s-interface/cloudsupport/apis"
+	"github.com/armosec/k8s-interface/k8sinterface"
+	"github.com/armosec/opa-utils/objectsenvelopes/hostsensor"
+	"github.com/armosec/opa-utils/reporthandling"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// the sources the resources required by the controls are fetched from
+const (
+	ResourceSourceKubernetesAPI = "kubernetes-api"
+	ResourceSourceHostSensor    = "host-sensor" // requires the host sensor DaemonSet (--enable-host-scan)
+	ResourceSourceCloudAPI      = "cloud-api"   // requires the credentials of the cloud provider
+	ResourceSourceKubescape     = "kubescape"   // derived by kubescape, e.g. the image vulnerabilities or the network policies coverage
+)
+
+// resourceSourcesOrder the order of the listed sources
+var resourceSourcesOrder = map[string]int{ResourceSourceKubernetesAPI: 0, ResourceSourceKubescape: 1, ResourceSourceHostSensor: 2, ResourceSourceCloudAPI: 3}
+
+// RequiredResource a resource fetched for scanning the frameworks, with the controls requiring it
+type RequiredResource struct {
+	GroupResource string   `json:"groupResource"` // group/version/resource
+	Source        string   `json:"source"`
+	Controls      []string `json:"controls"`
+	Objects       *int     `json:"objects,omitempty"` // the number of objects in the cluster, when counted
+}
+
+// ListRequiredResources lists the resources fetched for scanning the frameworks, sorted by source and resource
+func ListRequiredResources(frameworks []reporthandling.Framework) []RequiredResource {
+	controls := map[string]map[string]bool{} // map[<group/version/resource>]map[<control ID>]
+	for i := range frameworks {
+		for j := range frameworks[i].Controls {
+			control := &frameworks[i].Controls[j]
+			singleControl := []reporthandling.Framework{{Controls: []reporthandling.Control{*control}}}
+			for groupResource := range *setResourceMap(singleControl) {
+				if _, ok := controls[groupResource]; !ok {
+					controls[groupResource] = map[string]bool{}
+				}
+				controls[groupResource][control.ControlID] = true
+			}
+		}
+	}
+
+	resources := make([]RequiredResource, 0, len(controls))
+	for groupResource, controlIDs := range controls {
+		resource := RequiredResource{GroupResource: groupResource, Source: resourceSource(groupResource), Controls: []string{}}
+		for controlID := range controlIDs {
+			resource.Controls = append(resource.Controls, controlID)
+		}
+		sort.Strings(resource.Controls)
+		resources = append(resources, resource)
+	}
+	sort.Slice(resources, func(i, j int) bool {
+		if resources[i].Source != resources[j].Source {
+			return resourceSourcesOrder[resources[i].Source] < resourceSourcesOrder[resources[j].Source]
+		}
+		return resources[i].GroupResource < resources[j].GroupResource
+	})
+	return resources
+}
+
+// resourceSource returns the source the resource is fetched from, by the group of the resource
+func resourceSource(groupResource string) string {
+	group, _, _ := k8sinterface.StringToResourceGroup(groupResource)
+	switch group {
+	case hostsensor.GroupHostSensor:
+		return ResourceSourceHostSensor
+	case apis.ApiVersionEKS, apis.ApiVersionGKE, ClusterPostureObjectGroup:
+		return ResourceSourceCloudAPI
+	case ImagevulnerabilitiesObjectGroup, ImageSignatureObjectGroup, ImageMetadataObjectGroup, ControlPlaneComponentObjectGroup, NetworkPolicyCoverageObjectGroup, SecretExposureObjectGroup:
+		return ResourceSourceKubescape
+	}
+	return ResourceSourceKubernetesAPI
+}
+
+// CountObjects counts the objects of the kubernetes API resources in the cluster, a request of a single object per resource.
+// The resources which could not be listed, e.g. for missing permissions, are not counted
+func CountObjects(k8s *k8sinterface.KubernetesApi, resources []RequiredResource) {
+	for i := range resources {
+		if resources[i].Source != ResourceSourceKubernetesAPI {
+			continue
+		}
+		group, version, resource := k8sinterface.StringToResourceGroup(resources[i].GroupResource)
+		result, err := k8s.DynamicClient.Resource(schema.GroupVersionResource{Group: group, Version: version, Resource: resource}).List(context.Background(), metav1.ListOptions{Limit: 1})
+		if err != nil || result == nil {
+			continue
+		}
+		count := len(result.Items)
+		if remaining := result.GetRemainingItemCount(); remaining != nil {
+			count += int(*remaining)
+		}
+		resources[i].Objects = &count
+	}
+}
@@ -0,0 +1,54 @@
+package resourcehandler
+
+import (
+	"testing"
+
+	"github.com/armosec/k8s-interface/k8sinterface"
+	"github.com/armosec/opa-utils/objectsenvelopes/hostsensor"
+	"github.com/armosec/opa-utils/reporthandling"
+)
+
+func mockMatchControl(controlID string, match reporthandling.RuleMatchObjects) reporthandling.Control {
+	control := reporthandling.Control{ControlID: controlID}
+	control.Rules = []reporthandling.PolicyRule{{Match: []reporthandling.RuleMatchObjects{match}}}
+	return control
+}
+
+func TestListRequiredResources(t *testing.T) {
+	k8sinterface.InitializeMapResourcesMock()
+	frameworks := []reporthandling.Framework{
+		{Controls: []reporthandling.Control{
+			mockMatchControl("C-0002", reporthandling.RuleMatchObjects{APIGroups: []string{"apps"}, APIVersions: []string{"v1"}, Resources: []string{"Deployment"}}),
+			mockMatchControl("C-0001", reporthandling.RuleMatchObjects{APIGroups: []string{"apps"}, APIVersions: []string{"v1"}, Resources: []string{"Deployment"}}),
+			mockMatchControl("C-0069", reporthandling.RuleMatchObjects{APIGroups: []string{hostsensor.GroupHostSensor}, APIVersions: []string{"v1beta0"}, Resources: []string{"KubeletInfo"}}),
+		}},
+		{Controls: []reporthandling.Control{
+			mockMatchControl("C-0067", reporthandling.RuleMatchObjects{APIGroups: []string{"container.googleapis.com"}, APIVersions: []string{"v1"}, Resources: []string{"ClusterDescribe"}}),
+			mockMatchControl("C-0078", reporthandling.RuleMatchObjects{APIGroups: []string{ImagevulnerabilitiesObjectGroup}, APIVersions: []string{ImagevulnerabilitiesObjectVersion}, Resources: []string{ImagevulnerabilitiesObjectKind}}),
+			mockMatchControl("C-0001", reporthandling.RuleMatchObjects{APIGroups: []string{"apps"}, APIVersions: []string{"v1"}, Resources: []string{"Deployment"}}),
+		}},
+	}
+
+	resources := ListRequiredResources(frameworks)
+	expected := []struct {
+		source   string
+		controls int
+	}{
+		{ResourceSourceKubernetesAPI, 2},
+		{ResourceSourceKubescape, 1},
+		{ResourceSourceHostSensor, 1},
+		{ResourceSourceCloudAPI, 1},
+	}
+	if len(resources) != len(expected) {
+		t.Fatalf("expected %d resources, received %v", len(expected), resources)
+	}
+	for i := range expected {
+		if resources[i].Source != expected[i].source || len(resources[i].Controls) != expected[i].controls {
+			t.Errorf("resource %d: expected a %s resource of %d controls, received %v", i, expected[i].source, expected[i].controls, resources[i])
+		}
+	}
+	// the controls of the resource are listed once, sorted
+	if controls := resources[0].Controls; controls[0] != "C-0001" || controls[1] != "C-0002" {
+		t.Errorf("expected the sorted controls of the deployments, received %v", controls)
+	}
+}
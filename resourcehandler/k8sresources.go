@@ -3,14 +3,16 @@ package resourcehandler
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/armosec/kubescape/cautils"
 	"github.com/armosec/kubescape/cautils/logger"
 	"github.com/armosec/kubescape/cautils/logger/helpers"
+	"github.com/armosec/kubescape/cloudconnector"
 	"github.com/armosec/kubescape/hostsensorutils"
 	"github.com/armosec/opa-utils/objectsenvelopes"
-	"github.com/armosec/opa-utils/reporthandling"
 
 	"github.com/armosec/k8s-interface/cloudsupport"
 	"github.com/armosec/k8s-interface/k8sinterface"
@@ -18,33 +20,63 @@ import (
 
 	"github.com/armosec/armoapi-go/armotypes"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	k8slabels "k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/version"
 	"k8s.io/client-go/dynamic"
 )
 
+const (
+	// DefaultFetchConcurrency the number of the API resources listed concurrently
+	DefaultFetchConcurrency = 8
+	// DefaultFetchQPS the requests per second to the API server, the clients default to 5
+	DefaultFetchQPS = 50
+	// DefaultFetchPageSize the objects of a list request, the lists of the large clusters are paginated
+	DefaultFetchPageSize = 500
+)
+
 type K8sResourceHandler struct {
 	k8s               *k8sinterface.KubernetesApi
 	hostSensorHandler hostsensorutils.IHostSensor
 	fieldSelector     IFieldSelector
+	selectors         *cautils.SelectorOptions
 	rbacObjectsAPI    *cautils.RBACObjects
 	registryAdaptors  *RegistryAdaptors
+	fetchConcurrency  int   // the number of the API resources listed concurrently
+	fetchPageSize     int64 // the objects of a list request, 0 lists all of the objects at once
+	resourcesCache    *ResourcesCache
+	offline           bool // no calls to the cloud provider, the cluster is the only endpoint
 }
 
-func NewK8sResourceHandler(k8s *k8sinterface.KubernetesApi, fieldSelector IFieldSelector, hostSensorHandler hostsensorutils.IHostSensor, rbacObjects *cautils.RBACObjects, registryAdaptors *RegistryAdaptors) *K8sResourceHandler {
+func NewK8sResourceHandler(k8s *k8sinterface.KubernetesApi, fieldSelector IFieldSelector, selectors *cautils.SelectorOptions, hostSensorHandler hostsensorutils.IHostSensor, rbacObjects *cautils.RBACObjects, registryAdaptors *RegistryAdaptors, fetchConcurrency int, fetchPageSize int64) *K8sResourceHandler {
+	if fetchConcurrency < 1 {
+		fetchConcurrency = 1
+	}
 	return &K8sResourceHandler{
 		k8s:               k8s,
 		fieldSelector:     fieldSelector,
+		selectors:         selectors,
 		hostSensorHandler: hostSensorHandler,
 		rbacObjectsAPI:    rbacObjects,
 		registryAdaptors:  registryAdaptors,
+		fetchConcurrency:  fetchConcurrency,
+		fetchPageSize:     fetchPageSize,
 	}
 }
 
-func (k8sHandler *K8sResourceHandler) GetResources(frameworks []reporthandling.Framework, designator *armotypes.PortalDesignator) (*cautils.K8SResources, map[string]workloadinterface.IMetadata, error) {
+// SetOffline skips the lookups of the cloud provider - the description of the eks/gke cluster and the posture of the aks cluster
+func (k8sHandler *K8sResourceHandler) SetOffline(offline bool) {
+	k8sHandler.offline = offline
+}
+
+// SetResourcesCache sets the cache of the lists of the previous scans, the lists missing from the cache are listed and added to it
+func (k8sHandler *K8sResourceHandler) SetResourcesCache(cache *ResourcesCache) {
+	k8sHandler.resourcesCache = cache
+}
+
+func (k8sHandler *K8sResourceHandler) GetResources(ctx context.Context, sessionObj *cautils.OPASessionObj, designator *armotypes.PortalDesignator) (*cautils.K8SResources, map[string]workloadinterface.IMetadata, error) {
 	allResources := map[string]workloadinterface.IMetadata{}
 
 	// get k8s resources
@@ -54,32 +86,69 @@ func (k8sHandler *K8sResourceHandler) GetResources(frameworks []reporthandling.F
 
 	// build resources map
 	// map resources based on framework required resources: map["/group/version/kind"][]<k8s workloads ids>
-	k8sResourcesMap := setResourceMap(frameworks)
+	k8sResourcesMap := setResourceMap(sessionObj.Frameworks)
 
 	// get namespace and labels from designator (ignore cluster labels)
 	_, namespace, labels := armotypes.DigestPortalDesignator(designator)
 
-	// pull k8s recourses
-	if err := k8sHandler.pullResources(k8sResourcesMap, allResources, namespace, labels); err != nil {
-		return k8sResourcesMap, allResources, err
+	// pull k8s recourses, the scan goes on without the resources which could not be listed - the controls requiring them are reported.
+	// The in-flight requests are cancelled when the context is done
+	dataGaps := k8sHandler.pullResources(ctx, k8sResourcesMap, allResources, namespace, labels)
+	if err := ctx.Err(); err != nil {
+		cautils.StopSpinner()
+		return nil, nil, err
+	}
+	if len(dataGaps) > 0 {
+		sessionObj.DataGaps = dataGaps
+		for _, groupResource := range sortedKeys(dataGaps) {
+			logger.L().Warning("failed to list resources, the controls requiring them are reported with missing data", helpers.String("resource", groupResource), helpers.String("reason", dataGaps[groupResource]))
+		}
+	}
+	if k8sHandler.resourcesCache != nil {
+		if hits := k8sHandler.resourcesCache.Hits(); hits > 0 {
+			logger.L().Info("Loaded Kubernetes objects from the cache", helpers.Int("lists", hits), helpers.String("path", k8sHandler.resourcesCache.path))
+		}
+		if err := k8sHandler.resourcesCache.Save(); err != nil {
+			logger.L().Warning("failed to save the resources cache", helpers.Error(err))
+		}
 	}
 
 	if err := k8sHandler.registryAdaptors.collectImagesVulnerabilities(k8sResourcesMap, allResources); err != nil {
 		logger.L().Warning("failed to collect image vulnerabilities", helpers.Error(err))
 	}
 
+	if err := k8sHandler.registryAdaptors.collectImagesSignatures(k8sResourcesMap, allResources); err != nil {
+		logger.L().Warning("failed to verify image signatures", helpers.Error(err))
+	}
+
+	if err := k8sHandler.registryAdaptors.collectImagesMetadata(k8sResourcesMap, allResources); err != nil {
+		logger.L().Warning("failed to look up image metadata", helpers.Error(err))
+	}
+
 	if err := k8sHandler.collectHostResources(allResources, k8sResourcesMap); err != nil {
 		logger.L().Warning("failed to collect host sensor resources", helpers.Error(err))
 	}
 
+	if err := k8sHandler.collectControlPlaneComponents(ctx, allResources, k8sResourcesMap); err != nil {
+		logger.L().Warning("failed to list the control plane pods", helpers.Error(err))
+	}
+
+	setNetworkPolicyCoverage(allResources, k8sResourcesMap)
+	setSecretExposure(allResources, k8sResourcesMap)
+
 	if err := k8sHandler.collectRbacResources(allResources); err != nil {
 		logger.L().Warning("failed to collect rbac resources", helpers.Error(err))
 	}
-	if err := getCloudProviderDescription(allResources, k8sResourcesMap); err != nil {
+	if k8sHandler.offline {
+		logger.L().Debug("offline, skipping the cloud data")
+	} else if err := getCloudProviderDescription(allResources, k8sResourcesMap); err != nil {
 		logger.L().Warning("failed to collect cloud data", helpers.Error(err))
 	}
 
 	cautils.StopSpinner()
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
 	logger.L().Success("Accessed to Kubernetes objects")
 
 	return k8sResourcesMap, allResources, nil
@@ -94,37 +163,88 @@ func (k8sHandler *K8sResourceHandler) GetClusterAPIServerInfo() *version.Info {
 	return clusterAPIServerInfo
 }
 
-func (k8sHandler *K8sResourceHandler) pullResources(k8sResources *cautils.K8SResources, allResources map[string]workloadinterface.IMetadata, namespace string, labels map[string]string) error {
-	logger.L().Debug("Accessing Kubernetes objects")
+// pulledResource the objects of an API resource, listed by a worker of pullResources
+type pulledResource struct {
+	groupResource string
+	objects       []workloadinterface.IMetadata
+	err           error
+}
+
+// pullResources lists the API resources by a pool of workers, the requests to the API server are rate limited by the client.
+// Returns the reasons of the resources which could not be listed, map[<group/version/resource>]<reason>
+func (k8sHandler *K8sResourceHandler) pullResources(ctx context.Context, k8sResources *cautils.K8SResources, allResources map[string]workloadinterface.IMetadata, namespace string, labels map[string]string) map[string]string {
+	logger.L().Debug("Accessing Kubernetes objects", helpers.Int("concurrency", k8sHandler.fetchConcurrency))
 
-	var errs error
+	groupResources := make([]string, 0, len(*k8sResources))
 	for groupResource := range *k8sResources {
-		apiGroup, apiVersion, resource := k8sinterface.StringToResourceGroup(groupResource)
-		gvr := schema.GroupVersionResource{Group: apiGroup, Version: apiVersion, Resource: resource}
-		result, err := k8sHandler.pullSingleResource(&gvr, namespace, labels)
-		if err != nil {
-			if !strings.Contains(err.Error(), "the server could not find the requested resource") {
-				// handle error
-				if errs == nil {
-					errs = err
-				} else {
-					errs = fmt.Errorf("%s; %s", errs, err.Error())
-				}
+		groupResources = append(groupResources, groupResource)
+	}
+	sort.Strings(groupResources)
+
+	queue := make(chan string)
+	pulled := make(chan pulledResource)
+	wg := sync.WaitGroup{}
+	for i := 0; i < k8sHandler.fetchConcurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for groupResource := range queue {
+				apiGroup, apiVersion, resource := k8sinterface.StringToResourceGroup(groupResource)
+				gvr := schema.GroupVersionResource{Group: apiGroup, Version: apiVersion, Resource: resource}
+				objects, err := k8sHandler.pullSingleResource(ctx, &gvr, namespace, labels)
+				pulled <- pulledResource{groupResource: groupResource, objects: objects, err: err}
 			}
+		}()
+	}
+	go func() {
+		for i := range groupResources {
+			if ctx.Err() != nil {
+				break
+			}
+			queue <- groupResources[i]
+		}
+		close(queue)
+		wg.Wait()
+		close(pulled)
+	}()
+
+	dataGaps := map[string]string{}
+	for p := range pulled {
+		if p.err != nil {
+			dataGaps[p.groupResource] = dataGapReason(p.err)
 			continue
 		}
-		// store result as []map[string]interface{}
-		metaObjs := ConvertMapListToMeta(k8sinterface.ConvertUnstructuredSliceToMap(result))
-		for i := range metaObjs {
-			allResources[metaObjs[i].GetID()] = metaObjs[i]
+		for i := range p.objects {
+			allResources[p.objects[i].GetID()] = p.objects[i]
 		}
-		(*k8sResources)[groupResource] = workloadinterface.ListMetaIDs(metaObjs)
+		(*k8sResources)[p.groupResource] = workloadinterface.ListMetaIDs(p.objects)
 	}
-	return errs
+	return dataGaps
 }
 
-func (k8sHandler *K8sResourceHandler) pullSingleResource(resource *schema.GroupVersionResource, namespace string, labels map[string]string) ([]unstructured.Unstructured, error) {
-	resourceList := []unstructured.Unstructured{}
+// dataGapReason returns the reason a resource could not be listed
+func dataGapReason(err error) string {
+	switch {
+	case strings.Contains(err.Error(), "the server could not find the requested resource"):
+		return "the API group is not served by the cluster"
+	case strings.Contains(err.Error(), "forbidden"):
+		return "forbidden, the permission to list the resource is missing"
+	}
+	return err.Error()
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// pullSingleResource lists the objects of the API resource, page by page - each page is converted to the objects of the scan once received
+func (k8sHandler *K8sResourceHandler) pullSingleResource(ctx context.Context, resource *schema.GroupVersionResource, namespace string, labels map[string]string) ([]workloadinterface.IMetadata, error) {
+	resourceList := []workloadinterface.IMetadata{}
 	// set labels
 	listOptions := metav1.ListOptions{}
 	fieldSelectors := k8sHandler.fieldSelector.GetNamespacesSelectors(resource)
@@ -137,6 +257,21 @@ func (k8sHandler *K8sResourceHandler) pullSingleResource(resource *schema.GroupV
 			listOptions.LabelSelector = set.AsSelector().String()
 		}
 
+		// the user selectors target the workloads, the cluster scoped resources are required by the controls regardless
+		if k8sHandler.selectors != nil && k8sinterface.IsNamespaceScope(resource) {
+			listOptions.LabelSelector = joinSelectors(listOptions.LabelSelector, k8sHandler.selectors.LabelSelector)
+			listOptions.FieldSelector = joinSelectors(listOptions.FieldSelector, k8sHandler.selectors.FieldSelector)
+		}
+
+		// the lists of the previous scans, nothing is requested from the API server
+		key := listKey(resource, namespace, listOptions.LabelSelector, listOptions.FieldSelector)
+		if k8sHandler.resourcesCache != nil {
+			if objects, ok := k8sHandler.resourcesCache.get(resource, key); ok {
+				resourceList = append(resourceList, ConvertMapListToMeta(objects)...)
+				continue
+			}
+		}
+
 		// set dynamic object
 		var clientResource dynamic.ResourceInterface
 		if namespace != "" && k8sinterface.IsNamespaceScope(resource) {
@@ -145,19 +280,50 @@ func (k8sHandler *K8sResourceHandler) pullSingleResource(resource *schema.GroupV
 			clientResource = k8sHandler.k8s.DynamicClient.Resource(*resource)
 		}
 
-		// list resources
-		result, err := clientResource.List(context.Background(), listOptions)
-		if err != nil || result == nil {
-			return nil, fmt.Errorf("failed to get resource: %v, namespace: %s, labelSelector: %v, reason: %v", resource, namespace, listOptions.LabelSelector, err)
-		}
+		// list resources, following the continue token of the pages
+		listOptions.Limit = k8sHandler.fetchPageSize
+		listOptions.Continue = ""
+		listed := []map[string]interface{}{}
+		for {
+			result, err := clientResource.List(ctx, listOptions)
+			if err != nil && listOptions.Continue != "" && apierrors.IsResourceExpired(err) {
+				// the continue token expired (410 Gone) while listing the pages, the pages listed so far are dropped and the resources are listed in a single request
+				logger.L().Warning("the list continue token expired, listing all the resources in a single request", helpers.String("resource", resource.String()), helpers.String("namespace", namespace))
+				listOptions.Limit = 0
+				listOptions.Continue = ""
+				listed = []map[string]interface{}{}
+				continue
+			}
+			if err != nil || result == nil {
+				return nil, fmt.Errorf("failed to get resource: %v, namespace: %s, labelSelector: %v, fieldSelector: %v, reason: %v", resource, namespace, listOptions.LabelSelector, listOptions.FieldSelector, err)
+			}
+			listed = append(listed, k8sinterface.ConvertUnstructuredSliceToMap(result.Items)...)
 
-		resourceList = append(resourceList, result.Items...)
+			if listOptions.Continue = result.GetContinue(); listOptions.Continue == "" {
+				break
+			}
+		}
+		resourceList = append(resourceList, ConvertMapListToMeta(listed)...)
+		if k8sHandler.resourcesCache != nil {
+			k8sHandler.resourcesCache.set(resource, key, listed)
+		}
 
 	}
 
 	return resourceList, nil
 
 }
+// joinSelectors joins the selectors with a comma (logical AND), ignoring the empty ones
+func joinSelectors(selectors ...string) string {
+	l := []string{}
+	for i := range selectors {
+		if s := strings.Trim(selectors[i], ","); s != "" {
+			l = append(l, s)
+		}
+	}
+	return strings.Join(l, ",")
+}
+
 func ConvertMapListToMeta(resourceMap []map[string]interface{}) []workloadinterface.IMetadata {
 	workloads := []workloadinterface.IMetadata{}
 	for i := range resourceMap {
@@ -237,6 +403,15 @@ func getCloudProviderDescription(allResources map[string]workloadinterface.IMeta
 	if provider != "" {
 		logger.L().Debug("cloud", helpers.String("cluster", cluster), helpers.String("clusterName", clusterName), helpers.String("provider", provider), helpers.String("region", region), helpers.String("project", project))
 
+		if provider == "aks" { // aks has no cluster description, the posture is read from the managed cluster
+			posture, err := getAKSClusterPosture(clusterName)
+			if err != nil {
+				return fmt.Errorf("could not get the posture of aks cluster: %s. %v", cluster, err.Error())
+			}
+			setClusterPosture(clusterName, posture, allResources, k8sResourcesMap)
+			return nil
+		}
+
 		wl, err := cloudsupport.GetDescriptiveInfoFromCloudProvider(clusterName, provider, region, project)
 		if err != nil {
 			// Return error with useful info on how to configure credentials for getting cloud provider info
@@ -252,6 +427,13 @@ func getCloudProviderDescription(allResources map[string]workloadinterface.IMeta
 		}
 		allResources[wl.GetID()] = wl
 		(*k8sResourcesMap)[fmt.Sprintf("%s/%s", wl.GetApiVersion(), wl.GetKind())] = []string{wl.GetID()}
+
+		data, _ := wl.GetObject()["data"].(map[string]interface{})
+		posture, err := cloudconnector.PostureFromDescribe(provider, data)
+		if err != nil {
+			return err
+		}
+		setClusterPosture(clusterName, posture, allResources, k8sResourcesMap)
 	}
 	return nil
 
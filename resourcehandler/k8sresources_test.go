@@ -0,0 +1,151 @@
+package resourcehandler
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/armosec/armoapi-go/armotypes"
+	"github.com/armosec/k8s-interface/k8sinterface"
+	"github.com/armosec/k8s-interface/workloadinterface"
+	"github.com/armosec/kubescape/cautils"
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestPullResources(t *testing.T) {
+	k8sinterface.InitializeMapResourcesMock()
+	objects := []runtime.Object{}
+	for i := 0; i < 20; i++ {
+		objects = append(objects, newUnstructuredDeployment("web", fmt.Sprintf("nginx-%d", i)))
+	}
+	objects = append(objects, &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1", "kind": "Pod", "metadata": map[string]interface{}{"name": "nginx", "namespace": "web"},
+	}})
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		{Group: "apps", Version: "v1", Resource: "deployments"}: "DeploymentList",
+		{Version: "v1", Resource: "pods"}:                       "PodList",
+		{Version: "v1", Resource: "secrets"}:                    "SecretList",
+		{Version: "v1", Resource: "configmaps"}:                 "ConfigMapList",
+	}, objects...)
+	client.PrependReactor("list", "secrets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, fmt.Errorf("forbidden")
+	})
+	client.PrependReactor("list", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, fmt.Errorf("the server could not find the requested resource")
+	})
+
+	for _, concurrency := range []int{0, 1, 3} {
+		k8sHandler := NewK8sResourceHandler(&k8sinterface.KubernetesApi{DynamicClient: client}, &EmptySelector{}, nil, nil, nil, nil, concurrency, 0)
+		k8sResources := cautils.K8SResources{"apps/v1/deployments": nil, "/v1/pods": nil, "/v1/secrets": nil, "/v1/configmaps": nil}
+		allResources := map[string]workloadinterface.IMetadata{}
+
+		dataGaps := k8sHandler.pullResources(context.Background(), &k8sResources, allResources, "", nil)
+
+		// the resources which could not be listed are reported as data gaps, the others are pulled
+		assert.Len(t, dataGaps, 2, concurrency)
+		assert.Contains(t, dataGaps["/v1/secrets"], "forbidden", concurrency)
+		assert.Contains(t, dataGaps["/v1/configmaps"], "not served", concurrency)
+		assert.Len(t, allResources, 21, concurrency)
+		assert.Len(t, k8sResources["apps/v1/deployments"], 20, concurrency)
+		assert.Len(t, k8sResources["/v1/pods"], 1, concurrency)
+		assert.Empty(t, k8sResources["/v1/secrets"], concurrency)
+	}
+}
+
+// pagedDynamicClient lists a page per request, the fake dynamic client does not paginate
+type pagedDynamicClient struct {
+	dynamic.Interface
+	pages    int
+	expireAt int // the request at which the continue token expires, 0 if it does not
+	requests []metav1.ListOptions
+}
+
+type pagedResourceClient struct {
+	dynamic.NamespaceableResourceInterface
+	client *pagedDynamicClient
+}
+
+func (client *pagedDynamicClient) Resource(resource schema.GroupVersionResource) dynamic.NamespaceableResourceInterface {
+	return &pagedResourceClient{NamespaceableResourceInterface: client.Interface.Resource(resource), client: client}
+}
+
+func (resourceClient *pagedResourceClient) List(ctx context.Context, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+	resourceClient.client.requests = append(resourceClient.client.requests, opts)
+	page := len(resourceClient.client.requests)
+	if page == resourceClient.client.expireAt {
+		return nil, apierrors.NewResourceExpired("the provided continue parameter is too old")
+	}
+	if opts.Limit == 0 {
+		list := &unstructured.UnstructuredList{}
+		for i := 1; i <= resourceClient.client.pages; i++ {
+			list.Items = append(list.Items, *newUnstructuredDeployment("web", fmt.Sprintf("nginx-%d", i)))
+		}
+		return list, nil
+	}
+	list := &unstructured.UnstructuredList{Items: []unstructured.Unstructured{*newUnstructuredDeployment("web", fmt.Sprintf("nginx-%d", page))}}
+	if page < resourceClient.client.pages {
+		list.SetContinue(fmt.Sprintf("page-%d", page+1))
+	}
+	return list, nil
+}
+
+func TestPullSingleResourcePages(t *testing.T) {
+	k8sinterface.InitializeMapResourcesMock()
+	deployments := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	client := &pagedDynamicClient{Interface: dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{deployments: "DeploymentList"}), pages: 3}
+
+	k8sHandler := NewK8sResourceHandler(&k8sinterface.KubernetesApi{DynamicClient: client}, &EmptySelector{}, nil, nil, nil, nil, 1, 100)
+	objects, err := k8sHandler.pullSingleResource(context.Background(), &deployments, "", nil)
+	assert.NoError(t, err)
+	if assert.Len(t, client.requests, 3) {
+		assert.Equal(t, int64(100), client.requests[0].Limit)
+		assert.Equal(t, "", client.requests[0].Continue)
+		assert.Equal(t, "page-3", client.requests[2].Continue)
+	}
+	if assert.Len(t, objects, 3) {
+		assert.Equal(t, "nginx-3", objects[2].GetName())
+	}
+}
+
+func TestPullSingleResourceExpiredContinue(t *testing.T) {
+	k8sinterface.InitializeMapResourcesMock()
+	deployments := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	client := &pagedDynamicClient{Interface: dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{deployments: "DeploymentList"}), pages: 3, expireAt: 2}
+
+	// the pages listed before the continue token expired are dropped, the resources are listed in a single request
+	k8sHandler := NewK8sResourceHandler(&k8sinterface.KubernetesApi{DynamicClient: client}, &EmptySelector{}, nil, nil, nil, nil, 1, 100)
+	objects, err := k8sHandler.pullSingleResource(context.Background(), &deployments, "", nil)
+	assert.NoError(t, err)
+	if assert.Len(t, client.requests, 3) {
+		assert.Equal(t, "page-2", client.requests[1].Continue)
+		assert.Equal(t, int64(0), client.requests[2].Limit)
+		assert.Equal(t, "", client.requests[2].Continue)
+	}
+	assert.Len(t, objects, 3)
+
+	// an expired first request is not retried
+	client = &pagedDynamicClient{Interface: client.Interface, pages: 3, expireAt: 1}
+	k8sHandler = NewK8sResourceHandler(&k8sinterface.KubernetesApi{DynamicClient: client}, &EmptySelector{}, nil, nil, nil, nil, 1, 100)
+	_, err = k8sHandler.pullSingleResource(context.Background(), &deployments, "", nil)
+	assert.Error(t, err)
+}
+
+func TestGetResourcesCancelled(t *testing.T) {
+	k8sinterface.InitializeMapResourcesMock()
+	deployments := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{deployments: "DeploymentList"})
+	k8sHandler := NewK8sResourceHandler(&k8sinterface.KubernetesApi{DynamicClient: client}, &EmptySelector{}, nil, nil, nil, nil, 1, 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err := k8sHandler.GetResources(ctx, cautils.NewOPASessionObj(nil, nil), &armotypes.PortalDesignator{})
+	assert.Equal(t, context.Canceled, err)
+}
@@ -0,0 +1,102 @@
+package resourcehandler
+
+import (
+	"fmt"
+
+	"github.com/armosec/k8s-interface/k8sinterface"
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/cautils/logger/helpers"
+	"github.com/armosec/opa-utils/objectsenvelopes"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+)
+
+// K8sResourceWatcher watches the scanned resources with informers. The namespaces and the selectors of the scan are applied to the watched resources
+type K8sResourceWatcher struct {
+	k8s           *k8sinterface.KubernetesApi
+	fieldSelector IFieldSelector
+	selectors     *cautils.SelectorOptions
+}
+
+func NewK8sResourceWatcher(k8s *k8sinterface.KubernetesApi, fieldSelector IFieldSelector, selectors *cautils.SelectorOptions) *K8sResourceWatcher {
+	return &K8sResourceWatcher{
+		k8s:           k8s,
+		fieldSelector: fieldSelector,
+		selectors:     selectors,
+	}
+}
+
+// Watch starts an informer per resource of the map and sends the changed resources to the channel, until the stop channel is closed.
+// The informers list the existing resources when started, these are sent as changes as well - the unchanged resources
+// should be ignored by the receiver (e.g. by the resource version)
+func (watcher *K8sResourceWatcher) Watch(k8sResources *cautils.K8SResources, changes chan<- cautils.ResourceChange, stop <-chan struct{}) error {
+	informers := []cache.SharedIndexInformer{}
+	for groupResource := range *k8sResources {
+		apiGroup, apiVersion, resource := k8sinterface.StringToResourceGroup(groupResource)
+		if !isKubernetesResource(apiGroup, apiVersion, resource) {
+			continue // e.g. host sensor and cloud provider resources
+		}
+		gvr := schema.GroupVersionResource{Group: apiGroup, Version: apiVersion, Resource: resource}
+		for _, fieldSelector := range watcher.fieldSelector.GetNamespacesSelectors(&gvr) {
+			informer := dynamicinformer.NewFilteredDynamicInformer(watcher.k8s.DynamicClient, gvr, "", 0, cache.Indexers{}, watcher.tweakListOptions(&gvr, fieldSelector)).Informer()
+			informer.AddEventHandler(resourceChangeHandler(groupResource, changes))
+			informers = append(informers, informer)
+		}
+	}
+	if len(informers) == 0 {
+		return fmt.Errorf("no kubernetes resources to watch")
+	}
+
+	synced := []cache.InformerSynced{}
+	for i := range informers {
+		go informers[i].Run(stop)
+		synced = append(synced, informers[i].HasSynced)
+	}
+	if !cache.WaitForCacheSync(stop, synced...) {
+		return fmt.Errorf("failed to sync the informers of the watched resources")
+	}
+	logger.L().Debug("watching kubernetes resources", helpers.Int("informers", len(informers)))
+	return nil
+}
+
+// tweakListOptions sets the same selectors as pullSingleResource
+func (watcher *K8sResourceWatcher) tweakListOptions(resource *schema.GroupVersionResource, fieldSelector string) dynamicinformer.TweakListOptionsFunc {
+	return func(listOptions *metav1.ListOptions) {
+		listOptions.FieldSelector = fieldSelector
+		if watcher.selectors != nil && k8sinterface.IsNamespaceScope(resource) {
+			listOptions.LabelSelector = joinSelectors(listOptions.LabelSelector, watcher.selectors.LabelSelector)
+			listOptions.FieldSelector = joinSelectors(listOptions.FieldSelector, watcher.selectors.FieldSelector)
+		}
+	}
+}
+
+func resourceChangeHandler(groupResource string, changes chan<- cautils.ResourceChange) cache.ResourceEventHandlerFuncs {
+	send := func(obj interface{}, deleted bool) {
+		if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+			obj = tombstone.Obj
+		}
+		u, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			return
+		}
+		if resource := objectsenvelopes.NewObject(u.Object); resource != nil {
+			changes <- cautils.ResourceChange{GroupVersionResource: groupResource, Resource: resource, Deleted: deleted}
+		}
+	}
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { send(obj, false) },
+		UpdateFunc: func(oldObj, newObj interface{}) { send(newObj, false) },
+		DeleteFunc: func(obj interface{}) { send(obj, true) },
+	}
+}
+
+// isKubernetesResource returns true for the resources served by the kubernetes API
+func isKubernetesResource(apiGroup, apiVersion, resource string) bool {
+	groupVersion, ok := k8sinterface.GetSingleResourceFromGroupMapping(resource)
+	return ok && groupVersion == k8sinterface.JoinGroupVersion(apiGroup, apiVersion)
+}
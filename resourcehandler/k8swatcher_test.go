@@ -0,0 +1,69 @@
+package resourcehandler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/armosec/k8s-interface/k8sinterface"
+	"github.com/armosec/kubescape/cautils"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func newUnstructuredDeployment(namespace, name string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]interface{}{"name": name, "namespace": namespace},
+	}}
+}
+
+func receiveChange(t *testing.T, changes <-chan cautils.ResourceChange) cautils.ResourceChange {
+	select {
+	case change := <-changes:
+		return change
+	case <-time.After(5 * time.Second):
+		t.Fatal("no resource change received")
+	}
+	return cautils.ResourceChange{}
+}
+
+func TestK8sResourceWatcher(t *testing.T) {
+	k8sinterface.InitializeMapResourcesMock()
+	deployments := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{deployments: "DeploymentList"}, newUnstructuredDeployment("web", "nginx"))
+
+	watcher := NewK8sResourceWatcher(&k8sinterface.KubernetesApi{DynamicClient: client}, &EmptySelector{}, &cautils.SelectorOptions{})
+	k8sResources := cautils.K8SResources{"apps/v1/deployments": nil, "hostdata.kubescape.cloud/v1beta0/KernelVersion": nil}
+	changes := make(chan cautils.ResourceChange, 10)
+	stop := make(chan struct{})
+	defer close(stop)
+	assert.NoError(t, watcher.Watch(&k8sResources, changes, stop))
+
+	// the initial list
+	change := receiveChange(t, changes)
+	assert.Equal(t, "apps/v1/deployments", change.GroupVersionResource)
+	assert.Equal(t, "nginx", change.Resource.GetName())
+	assert.False(t, change.Deleted)
+
+	_, err := client.Resource(deployments).Namespace("web").Create(context.Background(), newUnstructuredDeployment("web", "redis"), metav1.CreateOptions{})
+	assert.NoError(t, err)
+	change = receiveChange(t, changes)
+	assert.Equal(t, "redis", change.Resource.GetName())
+
+	assert.NoError(t, client.Resource(deployments).Namespace("web").Delete(context.Background(), "nginx", metav1.DeleteOptions{}))
+	change = receiveChange(t, changes)
+	assert.Equal(t, "nginx", change.Resource.GetName())
+	assert.True(t, change.Deleted)
+}
+
+func TestK8sResourceWatcherNoResources(t *testing.T) {
+	watcher := NewK8sResourceWatcher(&k8sinterface.KubernetesApi{}, &EmptySelector{}, nil)
+	k8sResources := cautils.K8SResources{"hostdata.kubescape.cloud/v1beta0/KernelVersion": nil}
+	assert.Error(t, watcher.Watch(&k8sResources, make(chan cautils.ResourceChange), make(chan struct{})))
+}
@@ -0,0 +1,128 @@
+package resourcehandler
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/armosec/k8s-interface/k8sinterface"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Namespace patterns are globs (e.g. prod-*) or regular expressions enclosed in slashes (e.g. /^team-(a|b)$/).
+// The Kubernetes field selectors support only exact names, so the patterns are resolved to the names of the cluster namespaces
+
+// IsNamespacePattern returns true when one of the comma separated namespaces is a glob or a regular expression
+func IsNamespacePattern(namespaces string) bool {
+	for _, ns := range splitNamespaces(namespaces) {
+		if isRegexPattern(ns) || strings.ContainsAny(ns, "*?[") {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolveNamespaces returns the namespaces matching one of the comma separated patterns, sorted
+func ResolveNamespaces(patterns string, namespaces []string) ([]string, error) {
+	matchers := []func(string) bool{}
+	for _, pattern := range splitNamespaces(patterns) {
+		matcher, err := namespaceMatcher(pattern)
+		if err != nil {
+			return nil, err
+		}
+		matchers = append(matchers, matcher)
+	}
+
+	resolved := []string{}
+	for _, ns := range namespaces {
+		for _, match := range matchers {
+			if match(ns) {
+				resolved = append(resolved, ns)
+				break
+			}
+		}
+	}
+	sort.Strings(resolved)
+	return resolved, nil
+}
+
+// ListNamespaces returns the names of the cluster namespaces
+func ListNamespaces(k8s *k8sinterface.KubernetesApi) ([]string, error) {
+	namespaceList, err := k8s.KubernetesClient.CoreV1().Namespaces().List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list namespaces: %w", err)
+	}
+	namespaces := make([]string, 0, len(namespaceList.Items))
+	for i := range namespaceList.Items {
+		namespaces = append(namespaces, namespaceList.Items[i].GetName())
+	}
+	return namespaces, nil
+}
+
+func namespaceMatcher(pattern string) (func(string) bool, error) {
+	if isRegexPattern(pattern) {
+		re, err := regexp.Compile(pattern[1 : len(pattern)-1])
+		if err != nil {
+			return nil, fmt.Errorf("bad argument: invalid namespace regular expression '%s': %w", pattern, err)
+		}
+		return re.MatchString, nil
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("bad argument: invalid namespace pattern '%s': %w", pattern, err)
+	}
+	return func(ns string) bool {
+		matched, _ := path.Match(pattern, ns)
+		return matched
+	}, nil
+}
+
+func isRegexPattern(pattern string) bool {
+	return len(pattern) > 1 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/")
+}
+
+func splitNamespaces(namespaces string) []string {
+	l := []string{}
+	for _, ns := range strings.Split(namespaces, ",") {
+		if ns = strings.TrimSpace(ns); ns != "" {
+			l = append(l, ns)
+		}
+	}
+	return l
+}
+
+// NewNamespacePatternSelector resolves the include/exclude namespace patterns against the cluster namespaces.
+// When both are set, the excluded namespaces are removed from the included ones
+func NewNamespacePatternSelector(includeNamespaces, excludeNamespaces string, namespaces []string) (IFieldSelector, error) {
+	excluded, err := ResolveNamespaces(excludeNamespaces, namespaces)
+	if err != nil {
+		return nil, err
+	}
+	if includeNamespaces == "" {
+		if len(excluded) == 0 {
+			return &EmptySelector{}, nil
+		}
+		return NewExcludeSelector(strings.Join(excluded, ",")), nil
+	}
+
+	included, err := ResolveNamespaces(includeNamespaces, namespaces)
+	if err != nil {
+		return nil, err
+	}
+	excludedMap := map[string]bool{}
+	for _, ns := range excluded {
+		excludedMap[ns] = true
+	}
+	selected := []string{}
+	for _, ns := range included {
+		if !excludedMap[ns] {
+			selected = append(selected, ns)
+		}
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no namespace matches the include pattern '%s' and the exclude pattern '%s'", includeNamespaces, excludeNamespaces)
+	}
+	return NewIncludeSelector(strings.Join(selected, ",")), nil
+}
@@ -0,0 +1,59 @@
+package resourcehandler
+
+import (
+	"testing"
+
+	"github.com/armosec/k8s-interface/k8sinterface"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var clusterNamespaces = []string{"default", "kube-system", "kube-public", "prod-api", "prod-web", "test-api", "team-a", "team-b"}
+
+func TestIsNamespacePattern(t *testing.T) {
+	assert.False(t, IsNamespacePattern(""))
+	assert.False(t, IsNamespacePattern("default,kube-system"))
+	assert.True(t, IsNamespacePattern("default,prod-*"))
+	assert.True(t, IsNamespacePattern("team-?"))
+	assert.True(t, IsNamespacePattern("/^team-(a|b)$/"))
+	assert.False(t, IsNamespacePattern("/"))
+}
+
+func TestResolveNamespaces(t *testing.T) {
+	resolved, err := ResolveNamespaces("prod-*,default", clusterNamespaces)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"default", "prod-api", "prod-web"}, resolved)
+
+	resolved, err = ResolveNamespaces("/^team-(a|b)$/, kube-*", clusterNamespaces)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"kube-public", "kube-system", "team-a", "team-b"}, resolved)
+
+	resolved, err = ResolveNamespaces("staging-*", clusterNamespaces)
+	assert.NoError(t, err)
+	assert.Empty(t, resolved)
+
+	_, err = ResolveNamespaces("/team-(/", clusterNamespaces)
+	assert.Error(t, err)
+	_, err = ResolveNamespaces("team-[", clusterNamespaces)
+	assert.Error(t, err)
+}
+
+func TestNewNamespacePatternSelector(t *testing.T) {
+	k8sinterface.InitializeMapResourcesMock()
+	pods := &schema.GroupVersionResource{Resource: "pods"}
+
+	fs, err := NewNamespacePatternSelector("", "kube-*,test-*", clusterNamespaces)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"metadata.namespace!=kube-public,metadata.namespace!=kube-system,metadata.namespace!=test-api,"}, fs.GetNamespacesSelectors(pods))
+
+	fs, err = NewNamespacePatternSelector("prod-*,test-*", "*-api", clusterNamespaces)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"metadata.namespace==prod-web"}, fs.GetNamespacesSelectors(pods))
+
+	fs, err = NewNamespacePatternSelector("", "staging-*", clusterNamespaces)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{""}, fs.GetNamespacesSelectors(pods))
+
+	_, err = NewNamespacePatternSelector("staging-*", "", clusterNamespaces)
+	assert.Error(t, err)
+}
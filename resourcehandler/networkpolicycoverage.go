@@ -0,0 +1,57 @@
+package resourcehandler
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/armosec/k8s-interface/k8sinterface"
+	"github.com/armosec/k8s-interface/workloadinterface"
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/networkpolicyanalysis"
+)
+
+const (
+	NetworkPolicyCoverageObjectGroup   = "networkpolicy.kubescape.cloud"
+	NetworkPolicyCoverageObjectVersion = "v1beta0"
+	NetworkPolicyCoverageObjectKind    = "NetworkPolicyCoverage"
+)
+
+// networkPolicyCoverageRequired returns true when the scanned frameworks evaluate the coverage of the workloads by the network policies
+func networkPolicyCoverageRequired(k8sResourcesMap *cautils.K8SResources) bool {
+	_, ok := (*k8sResourcesMap)[networkPolicyCoverageTriplet()]
+	return ok
+}
+
+func networkPolicyCoverageTriplet() string {
+	return k8sinterface.JoinResourceTriplets(NetworkPolicyCoverageObjectGroup, NetworkPolicyCoverageObjectVersion, NetworkPolicyCoverageObjectKind)
+}
+
+// setNetworkPolicyCoverage adds a NetworkPolicyCoverage object per scanned workload, by the scanned NetworkPolicy objects - the controls
+// evaluating the coverage match the kinds of the workloads and the NetworkPolicy kind as well
+func setNetworkPolicyCoverage(allResources map[string]workloadinterface.IMetadata, k8sResourcesMap *cautils.K8SResources) {
+	if !networkPolicyCoverageRequired(k8sResourcesMap) {
+		return
+	}
+	objects := make([]workloadinterface.IMetadata, 0, len(allResources))
+	for _, wl := range allResources {
+		objects = append(objects, wl)
+	}
+	coverage := networkpolicyanalysis.Analyze(objects)
+	ids := make([]string, 0, len(coverage.Workloads))
+	for i := range coverage.Workloads {
+		wl := networkPolicyCoverageToIMetadata(&coverage.Workloads[i])
+		allResources[wl.GetID()] = wl
+		ids = append(ids, wl.GetID())
+	}
+	sort.Strings(ids)
+	(*k8sResourcesMap)[networkPolicyCoverageTriplet()] = ids
+}
+
+func networkPolicyCoverageToIMetadata(workload *networkpolicyanalysis.WorkloadCoverage) workloadinterface.IMetadata {
+	obj := map[string]interface{}{}
+	obj["kind"] = NetworkPolicyCoverageObjectKind
+	obj["apiVersion"] = k8sinterface.JoinGroupVersion(NetworkPolicyCoverageObjectGroup, NetworkPolicyCoverageObjectVersion)
+	obj["metadata"] = map[string]interface{}{"name": strings.ToLower(workload.Kind) + "-" + workload.Name, "namespace": workload.Namespace}
+	obj["data"] = *workload
+	return workloadinterface.NewWorkloadObj(obj)
+}
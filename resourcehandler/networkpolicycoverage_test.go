@@ -0,0 +1,39 @@
+package resourcehandler
+
+import (
+	"testing"
+
+	"github.com/armosec/k8s-interface/workloadinterface"
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/networkpolicyanalysis"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetNetworkPolicyCoverage(t *testing.T) {
+	deployment := workloadinterface.NewWorkloadObj(map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]interface{}{"name": "api", "namespace": "web"},
+		"spec": map[string]interface{}{"template": map[string]interface{}{
+			"metadata": map[string]interface{}{"labels": map[string]interface{}{"app": "api"}},
+			"spec":     map[string]interface{}{"containers": []interface{}{map[string]interface{}{"name": "api", "ports": []interface{}{map[string]interface{}{"containerPort": 8080}}}}},
+		}},
+	})
+	allResources := map[string]workloadinterface.IMetadata{deployment.GetID(): deployment}
+
+	k8sResources := cautils.K8SResources{}
+	setNetworkPolicyCoverage(allResources, &k8sResources)
+	assert.Len(t, allResources, 1, "not required by the frameworks")
+
+	k8sResources[networkPolicyCoverageTriplet()] = nil
+	setNetworkPolicyCoverage(allResources, &k8sResources)
+	ids := k8sResources[networkPolicyCoverageTriplet()]
+	if assert.Len(t, ids, 1) {
+		wl := allResources[ids[0]]
+		assert.Equal(t, "deployment-api", wl.GetName())
+		assert.Equal(t, "web", wl.GetNamespace())
+		coverage := wl.GetObject()["data"].(networkpolicyanalysis.WorkloadCoverage)
+		assert.Equal(t, networkpolicyanalysis.StatusUncovered, coverage.Ingress)
+		assert.Equal(t, []networkpolicyanalysis.Port{{Container: "api", Port: 8080, Protocol: "TCP", Status: networkpolicyanalysis.StatusUncovered}}, coverage.Ports)
+	}
+}
@@ -0,0 +1,70 @@
+package resourcehandler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/armosec/k8s-interface/k8sinterface"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	authorizationv1client "k8s.io/client-go/kubernetes/typed/authorization/v1"
+)
+
+// MissingPermission a permission required by the scan which is not granted to the user
+type MissingPermission struct {
+	Verb      string `json:"verb"`
+	Group     string `json:"group"`
+	Resource  string `json:"resource"`            // <resource>[/<subresource>]
+	Namespace string `json:"namespace,omitempty"` // all of the namespaces when empty
+}
+
+func (permission *MissingPermission) String() string {
+	resource := permission.Resource
+	if permission.Group != "" {
+		resource = fmt.Sprintf("%s.%s", resource, permission.Group)
+	}
+	if permission.Namespace != "" {
+		return fmt.Sprintf("%s %s (namespace: %s)", permission.Verb, resource, permission.Namespace)
+	}
+	return fmt.Sprintf("%s %s", permission.Verb, resource)
+}
+
+// CheckPermissions reviews the access of the user to the resources of the rules by SelfSubjectAccessReviews, a review per verb and resource.
+// The namespaced resources are reviewed in each of the namespaces, in all of the namespaces when none. The reviews stop when the context is done
+func CheckPermissions(ctx context.Context, reviews authorizationv1client.SelfSubjectAccessReviewInterface, rules []rbacv1.PolicyRule, namespaces []string) ([]MissingPermission, error) {
+	if len(namespaces) == 0 {
+		namespaces = []string{""}
+	}
+	missing := []MissingPermission{}
+	for i := range rules {
+		for _, group := range rules[i].APIGroups {
+			for _, groupResource := range rules[i].Resources {
+				resource, subresource := groupResource, ""
+				if parts := strings.SplitN(groupResource, "/", 2); len(parts) == 2 {
+					resource, subresource = parts[0], parts[1]
+				}
+				resourceNamespaces := namespaces
+				if !k8sinterface.IsResourceInNamespaceScope(resource) {
+					resourceNamespaces = []string{""}
+				}
+				for _, verb := range rules[i].Verbs {
+					for _, namespace := range resourceNamespaces {
+						review := &authorizationv1.SelfSubjectAccessReview{Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+							ResourceAttributes: &authorizationv1.ResourceAttributes{Namespace: namespace, Verb: verb, Group: group, Resource: resource, Subresource: subresource},
+						}}
+						result, err := reviews.Create(ctx, review, metav1.CreateOptions{})
+						if err != nil {
+							return nil, fmt.Errorf("failed to review the access to '%s': %w", groupResource, err)
+						}
+						if !result.Status.Allowed {
+							missing = append(missing, MissingPermission{Verb: verb, Group: group, Resource: groupResource, Namespace: namespace})
+						}
+					}
+				}
+			}
+		}
+	}
+	return missing, nil
+}
@@ -0,0 +1,43 @@
+package resourcehandler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/armosec/k8s-interface/k8sinterface"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestCheckPermissions(t *testing.T) {
+	k8sinterface.InitializeMapResourcesMock()
+	client := fake.NewSimpleClientset()
+	// the user may list the pods of the default namespace only, and may get all of the resources
+	client.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		attributes := review.Spec.ResourceAttributes
+		review.Status.Allowed = attributes.Verb == "get" || (attributes.Resource == "pods" && attributes.Namespace == "default")
+		return true, review, nil
+	})
+
+	rules := []rbacv1.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{"namespaces", "pods"}, Verbs: []string{"get", "list"}},
+	}
+	missing, err := CheckPermissions(context.Background(), client.AuthorizationV1().SelfSubjectAccessReviews(), rules, []string{"default", "prod"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the cluster scoped namespaces are reviewed once, the pods in each of the namespaces
+	expected := []string{"list namespaces", "list pods (namespace: prod)"}
+	if len(missing) != len(expected) {
+		t.Fatalf("expected %v, received %v", expected, missing)
+	}
+	for i := range expected {
+		if s := missing[i].String(); s != expected[i] {
+			t.Errorf("expected %s, received %s", expected[i], s)
+		}
+	}
+}
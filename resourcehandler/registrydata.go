@@ -8,6 +8,8 @@ import (
 	"github.com/armosec/kubescape/cautils/logger"
 	"github.com/armosec/kubescape/cautils/logger/helpers"
 	armosecadaptorv1 "github.com/armosec/kubescape/registryadaptors/armosec/v1"
+	"github.com/armosec/kubescape/registryadaptors/imageregistry"
+	"github.com/armosec/kubescape/registryadaptors/imagesignatures"
 	"github.com/armosec/kubescape/registryadaptors/registryvulnerabilities"
 	"github.com/armosec/opa-utils/shared"
 )
@@ -19,7 +21,9 @@ const (
 )
 
 type RegistryAdaptors struct {
-	adaptors []registryvulnerabilities.IContainerImageVulnerabilityAdaptor
+	adaptors          []registryvulnerabilities.IContainerImageVulnerabilityAdaptor
+	signatureVerifier imagesignatures.IImageSignatureVerifier // nil when the signatures are not verified
+	metadataClient    imageregistry.IImageMetadataClient      // nil when the images configurations are not looked up
 }
 
 func NewRegistryAdaptors() (*RegistryAdaptors, error) {
@@ -33,6 +37,11 @@ func NewRegistryAdaptors() (*RegistryAdaptors, error) {
 	return registryAdaptors, nil
 }
 
+// AddAdaptor adds an adaptor, e.g. scanning the images locally
+func (registryAdaptors *RegistryAdaptors) AddAdaptor(adaptor registryvulnerabilities.IContainerImageVulnerabilityAdaptor) {
+	registryAdaptors.adaptors = append(registryAdaptors.adaptors, adaptor)
+}
+
 func (registryAdaptors *RegistryAdaptors) collectImagesVulnerabilities(k8sResourcesMap *cautils.K8SResources, allResources map[string]workloadinterface.IMetadata) error {
 	logger.L().Debug("Collecting images vulnerabilities")
 
@@ -1,154 +1,219 @@
 package resourcehandler
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
-	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
 	"strings"
 
-	"github.com/armosec/kubescape/cautils/getter"
+	"github.com/armosec/k8s-interface/workloadinterface"
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/cautils/logger/helpers"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"sigs.k8s.io/kustomize/api/konfig"
 )
 
-type IRepository interface {
-	setBranch(string) error
-	setTree() error
-	getYamlFromTree() []string
-}
+var commitHashRegex = regexp.MustCompile("^[0-9a-f]{7,40}$")
 
-type innerTree struct {
-	Path string `json:"path"`
-}
-type tree struct {
-	InnerTrees []innerTree `json:"tree"`
+// GitRepository a remote git repository, parsed from https://<host>/<org>/<repo>[/path][@ref], ssh://git@<host>/<org>/<repo>[/path][@ref]
+// or git@<host>:<org>/<repo>[/path][@ref]
+type GitRepository struct {
+	CloneURL string // URL of the repository, without the path and the ref
+	Path     string // Scanned directory of the repository, the root when empty
+	Ref      string // Branch, tag or commit, the default branch when empty
 }
 
-type GitHubRepository struct {
-	host   string
-	name   string // <org>/<repo>
-	branch string
-	tree   tree
-}
-type githubDefaultBranchAPI struct {
-	DefaultBranch string `json:"default_branch"`
+// IsGitRepositoryURL returns true for the URLs of repositories, the URLs of single yaml/json files are downloaded as is
+func IsGitRepositoryURL(pattern string) bool {
+	return cautils.IsURL(pattern) && !cautils.IsYaml(pattern) && !cautils.IsJson(pattern)
 }
 
-func NewGitHubRepository(rep string) *GitHubRepository {
-	return &GitHubRepository{
-		host: "github",
-		name: rep,
+func ParseGitRepositoryURL(url string) (*GitRepository, error) {
+	var prefix, repoPath string
+	if strings.HasPrefix(url, "git@") { // scp-like syntax - git@<host>:<org>/<repo>
+		hostPath := strings.SplitN(url, ":", 2)
+		if len(hostPath) != 2 {
+			return nil, fmt.Errorf("failed to parse git repository url: %s", url)
+		}
+		prefix, repoPath = hostPath[0]+":", hostPath[1]
+	} else {
+		schemeRest := strings.SplitN(url, "://", 2)
+		if len(schemeRest) != 2 {
+			return nil, fmt.Errorf("failed to parse git repository url: %s", url)
+		}
+		hostPath := strings.SplitN(schemeRest[1], "/", 2)
+		if len(hostPath) != 2 {
+			return nil, fmt.Errorf("failed to parse git repository url: %s", url)
+		}
+		prefix, repoPath = schemeRest[0]+"://"+hostPath[0]+"/", hostPath[1]
 	}
-}
 
-func ScanRepository(command string, branchOptional string) ([]string, error) {
-	repo, err := getRepository(command)
-	if err != nil {
-		return nil, err
+	repository := &GitRepository{}
+	if i := strings.LastIndex(repoPath, "@"); i >= 0 {
+		repository.Ref = repoPath[i+1:]
+		repoPath = repoPath[:i]
+	}
+	segments := strings.Split(strings.Trim(repoPath, "/"), "/")
+	if len(segments) < 2 || segments[0] == "" || segments[1] == "" {
+		return nil, fmt.Errorf("failed to parse git repository url: %s, expected <host>/<org>/<repo>[/path][@ref]", url)
 	}
+	repository.CloneURL = prefix + segments[0] + "/" + strings.TrimSuffix(segments[1], ".git")
+	repository.Path = path.Join(segments[2:]...)
+	return repository, nil
+}
 
-	err = repo.setBranch(branchOptional)
+// LoadResourcesFromGitRepository clones the repository and loads its manifests, helm charts (rendered with the default values) and kustomizations.
+// The source paths of the resources are relative to the root of the repository
+func LoadResourcesFromGitRepository(url string, gitOptions *cautils.GitOptions) ([]workloadinterface.IMetadata, map[string]cautils.ResourceSource, error) {
+	repository, err := ParseGitRepositoryURL(url)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-
-	err = repo.setTree()
+	cloneDir, err := os.MkdirTemp("", "kubescape-repo-")
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
+	defer os.RemoveAll(cloneDir)
 
-	// get all paths that are of the yaml type, and build them into a valid url
-	return repo.getYamlFromTree(), nil
-}
-
-func getHostAndRepoName(url string) (string, string, error) {
-	splitUrl := strings.Split(url, "/")
-
-	if len(splitUrl) != 5 {
-		return "", "", fmt.Errorf("failed to pars url: %s", url)
+	logger.L().Info("Cloning git repository", helpers.String("url", repository.CloneURL), helpers.String("ref", repository.Ref))
+	if err := cloneGitRepository(repository, cloneDir, gitOptions); err != nil {
+		return nil, nil, fmt.Errorf("failed to clone git repository '%s': %w", repository.CloneURL, err)
 	}
 
-	hostUrl := splitUrl[2]                                               // github.com, gitlab.com, etc.
-	repository := splitUrl[3] + "/" + strings.Split(splitUrl[4], ".")[0] // user/reposetory
-
-	return hostUrl, repository, nil
-}
-
-func getRepository(url string) (IRepository, error) {
-	hostUrl, repoName, err := getHostAndRepoName(url)
+	workloads, sources, err := loadResourcesFromDirectory(filepath.Join(cloneDir, filepath.FromSlash(repository.Path)))
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-
-	var repo IRepository
-	switch repoHost := strings.Split(hostUrl, ".")[0]; repoHost {
-	case "github":
-		repo = NewGitHubRepository(repoName)
-	default:
-		return nil, fmt.Errorf("unknown repository host: %s", repoHost)
+	for resourceID, source := range sources {
+		if rel, err := filepath.Rel(cloneDir, source.Path); err == nil {
+			source.Path = filepath.ToSlash(rel)
+		}
+		sources[resourceID] = source
 	}
-
-	// Returns the host-url, and the part of the user and repository from the url
-	return repo, nil
+	return workloads, sources, nil
 }
 
-func (g *GitHubRepository) setBranch(branchOptional string) error {
-	// Checks whether the repository type is a master or another type.
-	// By default it is "master", unless the branchOptional came with a value
-	if branchOptional == "" {
+func cloneGitRepository(repository *GitRepository, dir string, gitOptions *cautils.GitOptions) error {
+	auth, err := gitAuth(repository.CloneURL, gitOptions)
+	if err != nil {
+		return err
+	}
+	cloneOptions := &git.CloneOptions{URL: repository.CloneURL, Auth: auth, Depth: 1, SingleBranch: true}
+	if repository.Ref == "" {
+		_, err = git.PlainClone(dir, false, cloneOptions)
+		return err
+	}
 
-		body, err := getter.HttpGetter(&http.Client{}, g.defaultBranchAPI(), nil)
-		if err != nil {
-			return err
+	// the ref is a branch or a tag - shallow clone of the ref
+	for _, referenceName := range []plumbing.ReferenceName{plumbing.NewBranchReferenceName(repository.Ref), plumbing.NewTagReferenceName(repository.Ref)} {
+		cloneOptions.ReferenceName = referenceName
+		if _, err = git.PlainClone(dir, false, cloneOptions); err == nil {
+			return nil
 		}
-
-		var data githubDefaultBranchAPI
-		err = json.Unmarshal([]byte(body), &data)
-		if err != nil {
+		if !errors.Is(err, git.NoMatchingRefSpecError{}) && !errors.Is(err, plumbing.ErrReferenceNotFound) {
 			return err
 		}
-		g.branch = data.DefaultBranch
-	} else {
-		g.branch = branchOptional
+		os.RemoveAll(filepath.Join(dir, ".git"))
+	}
+	if !commitHashRegex.MatchString(repository.Ref) {
+		return fmt.Errorf("ref '%s' not found: %w", repository.Ref, err)
 	}
-	return nil
-}
-
-func (g *GitHubRepository) defaultBranchAPI() string {
-	return fmt.Sprintf("https://api.github.com/repos/%s", g.name)
-}
 
-func (g *GitHubRepository) setTree() error {
-	body, err := getter.HttpGetter(&http.Client{}, g.treeAPI(), nil)
+	// the ref is a commit - commits can not be fetched by a shallow clone
+	repo, err := git.PlainClone(dir, false, &git.CloneOptions{URL: repository.CloneURL, Auth: auth, NoCheckout: true})
 	if err != nil {
 		return err
 	}
-
-	// press all tree to json
-	var tree tree
-	err = json.Unmarshal([]byte(body), &tree)
+	hash, err := repo.ResolveRevision(plumbing.Revision(repository.Ref))
 	if err != nil {
-		return fmt.Errorf("failed to unmarshal response body from '%s', reason: %s", g.treeAPI(), err.Error())
-		// return nil
+		return fmt.Errorf("ref '%s' not found: %w", repository.Ref, err)
 	}
-	g.tree = tree
-
-	return nil
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return err
+	}
+	return worktree.Checkout(&git.CheckoutOptions{Hash: *hash})
 }
 
-func (g *GitHubRepository) treeAPI() string {
-	return fmt.Sprintf("https://api.github.com/repos/%s/git/trees/%s?recursive=1", g.name, g.branch)
+// gitAuth returns the token authentication of https URLs, and the ssh key or the ssh agent authentication of ssh URLs
+func gitAuth(url string, gitOptions *cautils.GitOptions) (transport.AuthMethod, error) {
+	if strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://") {
+		if gitOptions == nil || gitOptions.Token == "" {
+			return nil, nil
+		}
+		// any non empty username is accepted with a token by GitHub and GitLab
+		return &http.BasicAuth{Username: "oauth2", Password: gitOptions.Token}, nil
+	}
+	if !strings.HasPrefix(url, "ssh://") && !strings.HasPrefix(url, "git@") {
+		return nil, nil // local repositories
+	}
+	if gitOptions != nil && gitOptions.SSHKeyPath != "" {
+		return ssh.NewPublicKeysFromFile("git", gitOptions.SSHKeyPath, "")
+	}
+	return ssh.NewSSHAgentAuth("git")
 }
 
-// return a list of yaml for a given repository tree
-func (g *GitHubRepository) getYamlFromTree() []string {
-	var urls []string
-	for _, path := range g.tree.InnerTrees {
-		if strings.HasSuffix(path.Path, ".yaml") {
-			urls = append(urls, fmt.Sprintf("%s/%s", g.rowYamlUrl(), path.Path))
+// loadResourcesFromDirectory loads the resources of the directory - the helm charts and the kustomizations are rendered/built,
+// their files are not loaded as is. The paths ignored by .kubescapeignore files are skipped
+func loadResourcesFromDirectory(root string) ([]workloadinterface.IMetadata, map[string]cautils.ResourceSource, error) {
+	workloads := []workloadinterface.IMetadata{}
+	sources := map[string]cautils.ResourceSource{}
+	add := func(w []workloadinterface.IMetadata, s map[string]cautils.ResourceSource) {
+		workloads = append(workloads, w...)
+		for resourceID, source := range s {
+			sources[resourceID] = source
 		}
 	}
-	return urls
-}
 
-func (g *GitHubRepository) rowYamlUrl() string {
-	return fmt.Sprintf("https://raw.githubusercontent.com/%s/%s", g.name, g.branch)
+	files := []string{}
+	err := cautils.WalkDirectory(root, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			if cautils.IsYaml(p) || cautils.IsJson(p) {
+				files = append(files, p)
+			}
+			return nil
+		}
+		if cautils.IsFile(filepath.Join(p, "Chart.yaml")) {
+			w, s, err := cautils.LoadResourcesFromHelmChart(&cautils.HelmChartOptions{ChartPath: p, ReleaseName: "release-name", Namespace: "default"})
+			if err != nil {
+				logger.L().Warning("failed to render helm chart", helpers.String("chart", p), helpers.Error(err))
+			}
+			add(w, s)
+			return filepath.SkipDir
+		}
+		for _, kustomizationFile := range konfig.RecognizedKustomizationFileNames() {
+			if cautils.IsFile(filepath.Join(p, kustomizationFile)) {
+				w, s, err := cautils.LoadResourcesFromKustomize(p)
+				if err != nil {
+					logger.L().Warning("failed to build kustomization", helpers.String("kustomization", p), helpers.Error(err))
+				}
+				add(w, s)
+				return filepath.SkipDir
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(files) > 0 {
+		w, s, err := cautils.LoadResourcesFromFiles(files)
+		if err != nil {
+			return nil, nil, err
+		}
+		add(w, s)
+	}
+	return workloads, sources, nil
 }
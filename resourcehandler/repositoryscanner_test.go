@@ -0,0 +1,87 @@
+package resourcehandler
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseGitRepositoryURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		want    *GitRepository
+		wantErr bool
+	}{
+		{url: "https://github.com/armosec/kubescape", want: &GitRepository{CloneURL: "https://github.com/armosec/kubescape"}},
+		{url: "https://github.com/armosec/kubescape.git", want: &GitRepository{CloneURL: "https://github.com/armosec/kubescape"}},
+		{url: "https://github.com/armosec/kubescape/examples/online-boutique@master", want: &GitRepository{CloneURL: "https://github.com/armosec/kubescape", Path: "examples/online-boutique", Ref: "master"}},
+		{url: "git@github.com:armosec/kubescape.git@v1.0.130", want: &GitRepository{CloneURL: "git@github.com:armosec/kubescape", Ref: "v1.0.130"}},
+		{url: "git@github.com:armosec/kubescape/examples", want: &GitRepository{CloneURL: "git@github.com:armosec/kubescape", Path: "examples"}},
+		{url: "ssh://git@gitlab.com/org/repo/deploy@3f2a9c1", want: &GitRepository{CloneURL: "ssh://git@gitlab.com/org/repo", Path: "deploy", Ref: "3f2a9c1"}},
+		{url: "https://github.com/armosec", wantErr: true},
+		{url: "git@github.com", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			got, err := ParseGitRepositoryURL(tt.url)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestIsGitRepositoryURL(t *testing.T) {
+	assert.True(t, IsGitRepositoryURL("https://github.com/armosec/kubescape"))
+	assert.True(t, IsGitRepositoryURL("git@github.com:armosec/kubescape.git"))
+	assert.False(t, IsGitRepositoryURL("https://raw.githubusercontent.com/armosec/kubescape/master/examples/deployment.yaml"))
+	assert.False(t, IsGitRepositoryURL("examples/deployment.yaml"))
+}
+
+func TestLoadResourcesFromDirectory(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"deploy/app.yaml":                     "apiVersion: v1\nkind: Service\nmetadata:\n  name: web\n---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: web-config\n",
+		"deploy/kustomize/kustomization.yaml": "resources:\n- sa.yaml\n",
+		"deploy/kustomize/sa.yaml":            "apiVersion: v1\nkind: ServiceAccount\nmetadata:\n  name: web-sa\n",
+		"charts/web/Chart.yaml":               "apiVersion: v2\nname: web\nversion: 0.1.0\n",
+		"charts/web/values.yaml":              "name: chart-server\n",
+		"charts/web/templates/secret.yaml":    "apiVersion: v1\nkind: Secret\nmetadata:\n  name: {{ .Values.name }}\n",
+		".git/config.yaml":                    "apiVersion: v1\nkind: Secret\nmetadata:\n  name: ignored\n",
+	}
+	for name, content := range files {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		assert.NoError(t, os.MkdirAll(filepath.Dir(p), 0755))
+		assert.NoError(t, os.WriteFile(p, []byte(content), 0644))
+	}
+
+	workloads, sources, err := loadResourcesFromDirectory(root)
+	assert.NoError(t, err)
+
+	names := []string{}
+	for i := range workloads {
+		names = append(names, workloads[i].GetKind()+"/"+workloads[i].GetName())
+	}
+	sort.Strings(names)
+	assert.Equal(t, []string{"ConfigMap/web-config", "Secret/chart-server", "Service/web", "ServiceAccount/web-sa"}, names)
+
+	for i := range workloads {
+		source, ok := sources[workloads[i].GetID()]
+		assert.True(t, ok, workloads[i].GetID())
+		switch workloads[i].GetKind() {
+		case "Service":
+			assert.Equal(t, filepath.Join(root, "deploy", "app.yaml"), source.Path)
+			assert.Equal(t, 1, source.Line)
+		case "ServiceAccount":
+			assert.Equal(t, filepath.Join(root, "deploy", "kustomize", "sa.yaml"), source.Path)
+		case "Secret":
+			assert.Equal(t, filepath.Join(root, "charts", "web", "templates", "secret.yaml"), source.Path)
+		}
+	}
+}
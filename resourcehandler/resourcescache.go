@@ -0,0 +1,140 @@
+package resourcehandler
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/armosec/kubescape/cautils/getter"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// DefaultCacheMaxAge the age of the cached lists, the older lists are listed again from the API server
+const DefaultCacheMaxAge = time.Hour
+
+const resourcesCacheDir = "resources-cache"
+
+var unsafeFileNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+// uncachedResources the API resources never written to the disk, listed on every scan
+var uncachedResources = map[string]bool{"secrets": true}
+
+// ResourcesCache the objects of the API resources listed by the previous scans of a cluster, by the options of the list requests.
+// The cached lists are used as is until they are older than maxAge, nothing is requested from the API server for them - the resourceVersion
+// of a list is the revision of the whole cluster, it does not tell whether the objects of the list changed
+type ResourcesCache struct {
+	mutex    sync.Mutex
+	path     string
+	maxAge   time.Duration
+	lists    map[string]cachedList
+	hits     int
+	modified bool
+}
+
+// cachedList the objects of a list, and when it was listed
+type cachedList struct {
+	Listed  time.Time                `json:"listed"`
+	Objects []map[string]interface{} `json:"objects"`
+}
+
+// DefaultResourcesCachePath returns the path of the cache of the cluster in the kubescape cache directory
+func DefaultResourcesCachePath(clusterName string) string {
+	return getter.GetDefaultPath(filepath.Join(resourcesCacheDir, unsafeFileNameChars.ReplaceAllString(clusterName, "-")+".json.gz"))
+}
+
+// NewResourcesCache loads the cache of the path, the cache is empty when the file is missing.
+// The lists older than maxAge are dropped
+func NewResourcesCache(path string, maxAge time.Duration) (*ResourcesCache, error) {
+	cache := &ResourcesCache{path: path, maxAge: maxAge, lists: map[string]cachedList{}}
+
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cache, nil
+		}
+		return cache, err
+	}
+	defer f.Close()
+	reader, err := gzip.NewReader(f)
+	if err != nil {
+		return cache, fmt.Errorf("failed to read the resources cache '%s': %w", path, err)
+	}
+	lists := map[string]cachedList{}
+	if err := json.NewDecoder(reader).Decode(&lists); err != nil {
+		return cache, fmt.Errorf("failed to read the resources cache '%s': %w", path, err)
+	}
+	for key, list := range lists {
+		if time.Since(list.Listed) <= maxAge {
+			cache.lists[key] = list
+		} else {
+			cache.modified = true
+		}
+	}
+	return cache, nil
+}
+
+// Save writes the cache when lists were added or dropped
+func (cache *ResourcesCache) Save() error {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	if !cache.modified {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(cache.path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(cache.path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	writer := gzip.NewWriter(f)
+	if err := json.NewEncoder(writer).Encode(cache.lists); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	cache.modified = false
+	return nil
+}
+
+// Hits returns the number of the lists loaded from the cache
+func (cache *ResourcesCache) Hits() int {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	return cache.hits
+}
+
+func (cache *ResourcesCache) get(resource *schema.GroupVersionResource, key string) ([]map[string]interface{}, bool) {
+	if uncachedResources[resource.Resource] {
+		return nil, false
+	}
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	list, ok := cache.lists[key]
+	if ok {
+		cache.hits++
+	}
+	return list.Objects, ok
+}
+
+func (cache *ResourcesCache) set(resource *schema.GroupVersionResource, key string, objects []map[string]interface{}) {
+	if uncachedResources[resource.Resource] {
+		return
+	}
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	cache.lists[key] = cachedList{Listed: time.Now().UTC(), Objects: objects}
+	cache.modified = true
+}
+
+// listKey the key of a list request in the cache - the resource and the options selecting its objects
+func listKey(resource *schema.GroupVersionResource, namespace, labelSelector, fieldSelector string) string {
+	return fmt.Sprintf("%s/%s/%s?namespace=%s&labelSelector=%s&fieldSelector=%s", resource.Group, resource.Version, resource.Resource, namespace, labelSelector, fieldSelector)
+}
@@ -0,0 +1,54 @@
+package resourcehandler
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/armosec/k8s-interface/k8sinterface"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func TestResourcesCache(t *testing.T) {
+	k8sinterface.InitializeMapResourcesMock()
+	path := filepath.Join(t.TempDir(), "cluster.json.gz")
+	deployments := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	secrets := schema.GroupVersionResource{Version: "v1", Resource: "secrets"}
+	client := &pagedDynamicClient{Interface: dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{deployments: "DeploymentList", secrets: "SecretList"}), pages: 2}
+
+	scan := func(maxAge time.Duration) *ResourcesCache {
+		cache, err := NewResourcesCache(path, maxAge)
+		assert.NoError(t, err)
+		k8sHandler := NewK8sResourceHandler(&k8sinterface.KubernetesApi{DynamicClient: client}, &EmptySelector{}, nil, nil, nil, nil, 1, 100)
+		k8sHandler.SetResourcesCache(cache)
+		for _, resource := range []schema.GroupVersionResource{deployments, secrets} {
+			objects, err := k8sHandler.pullSingleResource(context.Background(), &resource, "", nil)
+			assert.NoError(t, err)
+			assert.NotEmpty(t, objects)
+		}
+		assert.NoError(t, cache.Save())
+		return cache
+	}
+
+	scan(time.Hour)
+	assert.Len(t, client.requests, 3) // the pages of the deployments, the secrets
+
+	// the deployments are loaded from the cache, the secrets are never cached
+	cache := scan(time.Hour)
+	assert.Len(t, client.requests, 4)
+	assert.Equal(t, 1, cache.Hits())
+	if assert.Len(t, cache.lists, 1) {
+		assert.Len(t, cache.lists[listKey(&deployments, "", "", "")].Objects, 2)
+	}
+
+	// the lists older than the max age are listed again
+	scan(0)
+	assert.Len(t, client.requests, 6)
+
+	_, err := NewResourcesCache(filepath.Join(t.TempDir(), "missing.json.gz"), time.Hour)
+	assert.NoError(t, err)
+}
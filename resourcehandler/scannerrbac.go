@@ -0,0 +1,73 @@
+package resourcehandler
+
+import (
+	"sort"
+
+	"github.com/armosec/k8s-interface/k8sinterface"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// scannerVerbs the verbs of the resources fetched from the kubernetes API
+var scannerVerbs = []string{"get", "list"}
+
+// hostSensorPolicyRules the permissions of deploying the host sensor DaemonSet and of collecting the data from its pods
+var hostSensorPolicyRules = []rbacv1.PolicyRule{
+	{APIGroups: []string{""}, Resources: []string{"namespaces"}, Verbs: []string{"get", "create", "delete"}},
+	{APIGroups: []string{""}, Resources: []string{"nodes"}, Verbs: []string{"list"}},
+	{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"list", "watch"}},
+	{APIGroups: []string{""}, Resources: []string{"pods/proxy"}, Verbs: []string{"get"}},
+	{APIGroups: []string{"apps"}, Resources: []string{"daemonsets"}, Verbs: []string{"get", "create", "delete"}},
+}
+
+// ScannerPolicyRules returns the least-privilege rules of scanning the required resources, a rule per API group.
+// With namespaced, the rules of the cluster scoped resources are left out, for a Role of scanning a single namespace.
+// The rules of the host sensor are added with hostSensor, the cloud provider APIs are authorized by the cloud credentials
+func ScannerPolicyRules(resources []RequiredResource, namespaced, hostSensor bool) []rbacv1.PolicyRule {
+	groupsResources := map[string]map[string]bool{}
+	addResource := func(group, resource string) {
+		if _, ok := groupsResources[group]; !ok {
+			groupsResources[group] = map[string]bool{}
+		}
+		groupsResources[group][resource] = true
+	}
+	for i := range resources {
+		switch resources[i].Source {
+		case ResourceSourceKubernetesAPI:
+			group, version, resource := k8sinterface.StringToResourceGroup(resources[i].GroupResource)
+			if namespaced && !k8sinterface.IsNamespaceScope(&schema.GroupVersionResource{Group: group, Version: version, Resource: resource}) {
+				continue
+			}
+			addResource(group, resource)
+		case ResourceSourceKubescape:
+			// the control plane components are read from the pods of the control plane
+			if group, _, _ := k8sinterface.StringToResourceGroup(resources[i].GroupResource); group == ControlPlaneComponentObjectGroup {
+				addResource("", "pods")
+			}
+		}
+	}
+
+	groups := make([]string, 0, len(groupsResources))
+	for group := range groupsResources {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+	rules := make([]rbacv1.PolicyRule, 0, len(groups))
+	for _, group := range groups {
+		rule := rbacv1.PolicyRule{APIGroups: []string{group}, Verbs: scannerVerbs}
+		for resource := range groupsResources[group] {
+			rule.Resources = append(rule.Resources, resource)
+		}
+		sort.Strings(rule.Resources)
+		rules = append(rules, rule)
+	}
+	if hostSensor && !namespaced {
+		for i := range resources {
+			if resources[i].Source == ResourceSourceHostSensor {
+				rules = append(rules, hostSensorPolicyRules...)
+				break
+			}
+		}
+	}
+	return rules
+}
@@ -0,0 +1,41 @@
+package resourcehandler
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/armosec/k8s-interface/k8sinterface"
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+func TestScannerPolicyRules(t *testing.T) {
+	k8sinterface.InitializeMapResourcesMock()
+	resources := []RequiredResource{
+		{GroupResource: "apps/v1/deployments", Source: ResourceSourceKubernetesAPI},
+		{GroupResource: "apps/v1/daemonsets", Source: ResourceSourceKubernetesAPI},
+		{GroupResource: "/v1/namespaces", Source: ResourceSourceKubernetesAPI},
+		{GroupResource: controlPlaneComponentsTriplet(), Source: ResourceSourceKubescape},
+		{GroupResource: "hostdata.kubescape.cloud/v1beta0/KubeletInfo", Source: ResourceSourceHostSensor},
+		{GroupResource: "container.googleapis.com/v1/ClusterDescribe", Source: ResourceSourceCloudAPI},
+	}
+
+	rules := ScannerPolicyRules(resources, false, false)
+	expected := []rbacv1.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{"namespaces", "pods"}, Verbs: scannerVerbs},
+		{APIGroups: []string{"apps"}, Resources: []string{"daemonsets", "deployments"}, Verbs: scannerVerbs},
+	}
+	if !reflect.DeepEqual(rules, expected) {
+		t.Errorf("expected %v, received %v", expected, rules)
+	}
+
+	// the cluster scoped resources are left out of the Role of a namespace
+	rules = ScannerPolicyRules(resources, true, true)
+	if len(rules) != 2 || !reflect.DeepEqual(rules[0].Resources, []string{"pods"}) {
+		t.Errorf("expected the namespaced resources only, received %v", rules)
+	}
+
+	// the host sensor is deployed when the resources of the hosts are required
+	if rules = ScannerPolicyRules(resources, false, true); len(rules) != 2+len(hostSensorPolicyRules) {
+		t.Errorf("expected the rules of the host sensor, received %v", rules)
+	}
+}
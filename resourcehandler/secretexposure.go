@@ -0,0 +1,57 @@
+package resourcehandler
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/armosec/k8s-interface/k8sinterface"
+	"github.com/armosec/k8s-interface/workloadinterface"
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/secretsdetection"
+)
+
+const (
+	SecretExposureObjectGroup   = "secrets.kubescape.cloud"
+	SecretExposureObjectVersion = "v1beta0"
+	SecretExposureObjectKind    = "SecretExposure"
+)
+
+// secretExposureRequired returns true when the scanned frameworks evaluate the credentials embedded in the objects
+func secretExposureRequired(k8sResourcesMap *cautils.K8SResources) bool {
+	_, ok := (*k8sResourcesMap)[secretExposureTriplet()]
+	return ok
+}
+
+func secretExposureTriplet() string {
+	return k8sinterface.JoinResourceTriplets(SecretExposureObjectGroup, SecretExposureObjectVersion, SecretExposureObjectKind)
+}
+
+// setSecretExposure adds a SecretExposure object per scanned ConfigMap and workload embedding credentials. The findings hold the redacted
+// values only - the controls evaluating them match the ConfigMap kind and the kinds of the workloads as well
+func setSecretExposure(allResources map[string]workloadinterface.IMetadata, k8sResourcesMap *cautils.K8SResources) {
+	if !secretExposureRequired(k8sResourcesMap) {
+		return
+	}
+	objects := make([]workloadinterface.IMetadata, 0, len(allResources))
+	for _, wl := range allResources {
+		objects = append(objects, wl)
+	}
+	exposures := secretsdetection.Detect(objects)
+	ids := make([]string, 0, len(exposures))
+	for i := range exposures {
+		wl := secretExposureToIMetadata(&exposures[i])
+		allResources[wl.GetID()] = wl
+		ids = append(ids, wl.GetID())
+	}
+	sort.Strings(ids)
+	(*k8sResourcesMap)[secretExposureTriplet()] = ids
+}
+
+func secretExposureToIMetadata(exposure *secretsdetection.ObjectFindings) workloadinterface.IMetadata {
+	obj := map[string]interface{}{}
+	obj["kind"] = SecretExposureObjectKind
+	obj["apiVersion"] = k8sinterface.JoinGroupVersion(SecretExposureObjectGroup, SecretExposureObjectVersion)
+	obj["metadata"] = map[string]interface{}{"name": strings.ToLower(exposure.Kind) + "-" + exposure.Name, "namespace": exposure.Namespace}
+	obj["data"] = *exposure
+	return workloadinterface.NewWorkloadObj(obj)
+}
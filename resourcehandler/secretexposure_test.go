@@ -0,0 +1,41 @@
+package resourcehandler
+
+import (
+	"testing"
+
+	"github.com/armosec/k8s-interface/workloadinterface"
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/secretsdetection"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetSecretExposure(t *testing.T) {
+	configMaps := []workloadinterface.IMetadata{
+		workloadinterface.NewWorkloadObj(map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata":   map[string]interface{}{"name": "app", "namespace": "shop"},
+			"data":       map[string]interface{}{"DB_PASSWORD": "s3cr3t-passw0rd"},
+		}),
+		workloadinterface.NewWorkloadObj(map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata":   map[string]interface{}{"name": "clean", "namespace": "shop"},
+			"data":       map[string]interface{}{"LOG_LEVEL": "info"},
+		}),
+	}
+	allResources := map[string]workloadinterface.IMetadata{}
+	for i := range configMaps {
+		allResources[configMaps[i].GetID()] = configMaps[i]
+	}
+
+	k8sResources := cautils.K8SResources{secretExposureTriplet(): nil}
+	setSecretExposure(allResources, &k8sResources)
+	ids := k8sResources[secretExposureTriplet()]
+	if assert.Len(t, ids, 1, "the objects without findings are skipped") {
+		wl := allResources[ids[0]]
+		assert.Equal(t, "configmap-app", wl.GetName())
+		exposure := wl.GetObject()["data"].(secretsdetection.ObjectFindings)
+		assert.Equal(t, []secretsdetection.Finding{{Path: "data.DB_PASSWORD", Type: secretsdetection.TypePassword, Evidence: "s3cr****"}}, exposure.Findings)
+	}
+}
@@ -5,43 +5,46 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"strings"
 
 	"github.com/armosec/k8s-interface/workloadinterface"
 	"github.com/armosec/kubescape/cautils"
 	"github.com/armosec/kubescape/cautils/logger"
 )
 
-func loadResourcesFromUrl(inputPatterns []string) ([]workloadinterface.IMetadata, error) {
-	urls := listUrls(inputPatterns)
-	if len(urls) == 0 {
-		return nil, nil
-	}
-
-	workloads, errs := downloadFiles(urls)
-	if len(errs) > 0 {
-		logger.L().Error(fmt.Sprintf("%v", errs))
-	}
-	return workloads, nil
-}
+func loadResourcesFromUrl(inputPatterns []string, gitOptions *cautils.GitOptions) ([]workloadinterface.IMetadata, map[string]cautils.ResourceSource, error) {
+	workloads := []workloadinterface.IMetadata{}
+	sources := map[string]cautils.ResourceSource{}
+	errs := []error{}
 
-func listUrls(patterns []string) []string {
 	urls := []string{}
-	for i := range patterns {
-		if strings.HasPrefix(patterns[i], "http") {
-			if !cautils.IsYaml(patterns[i]) && !cautils.IsJson(patterns[i]) { // if url of repo
-				if yamls, err := ScanRepository(patterns[i], ""); err == nil { // TODO - support branch
-					urls = append(urls, yamls...)
-				} else {
-					logger.L().Error(err.Error())
-				}
-			} else { // url of single file
-				urls = append(urls, patterns[i])
-			}
+	for i := range inputPatterns {
+		if !cautils.IsURL(inputPatterns[i]) {
+			continue
+		}
+		if !IsGitRepositoryURL(inputPatterns[i]) { // url of single file
+			urls = append(urls, inputPatterns[i])
+			continue
+		}
+		w, s, err := LoadResourcesFromGitRepository(inputPatterns[i], gitOptions)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		workloads = append(workloads, w...)
+		for resourceID, source := range s {
+			sources[resourceID] = source
 		}
 	}
 
-	return urls
+	if len(urls) > 0 {
+		w, e := downloadFiles(urls)
+		errs = append(errs, e...)
+		workloads = append(workloads, w...)
+	}
+	if len(errs) > 0 {
+		logger.L().Error(fmt.Sprintf("%v", errs))
+	}
+	return workloads, sources, nil
 }
 
 func downloadFiles(urls []string) ([]workloadinterface.IMetadata, []error) {
@@ -0,0 +1,169 @@
+package resourcehandler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/armosec/armoapi-go/armotypes"
+	"github.com/armosec/k8s-interface/workloadinterface"
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/cautils/logger/helpers"
+	"k8s.io/apimachinery/pkg/version"
+)
+
+// kubectl short names of the workload kinds
+var workloadKindShortNames = map[string]string{
+	"po":     "Pod",
+	"deploy": "Deployment",
+	"rs":     "ReplicaSet",
+	"sts":    "StatefulSet",
+	"ds":     "DaemonSet",
+	"cj":     "CronJob",
+}
+
+// WorkloadResourceHandler wraps the cluster resource handler, keeping only the scanned workload and its related objects:
+// the ServiceAccount, the RBAC bindings of the ServiceAccount and their roles, the NetworkPolicies and the Namespace of the workload
+type WorkloadResourceHandler struct {
+	resourceHandler IResourceHandler
+	workload        *cautils.WorkloadIdentifier
+}
+
+func NewWorkloadResourceHandler(resourceHandler IResourceHandler, workload *cautils.WorkloadIdentifier) *WorkloadResourceHandler {
+	return &WorkloadResourceHandler{
+		resourceHandler: resourceHandler,
+		workload:        workload,
+	}
+}
+
+func (workloadHandler *WorkloadResourceHandler) GetResources(ctx context.Context, sessionObj *cautils.OPASessionObj, designator *armotypes.PortalDesignator) (*cautils.K8SResources, map[string]workloadinterface.IMetadata, error) {
+	k8sResources, allResources, err := workloadHandler.resourceHandler.GetResources(ctx, sessionObj, designator)
+	if err != nil {
+		return k8sResources, allResources, err
+	}
+	if err := filterWorkloadResources(workloadHandler.workload, k8sResources, allResources); err != nil {
+		return k8sResources, allResources, err
+	}
+	logger.L().Info("Scanning a single workload", helpers.String("workload", workloadHandler.workload.String()), helpers.Int("resources", len(allResources)))
+	return k8sResources, allResources, nil
+}
+
+func (workloadHandler *WorkloadResourceHandler) GetClusterAPIServerInfo() *version.Info {
+	return workloadHandler.resourceHandler.GetClusterAPIServerInfo()
+}
+
+// filterWorkloadResources removes the resources unrelated to the workload
+func filterWorkloadResources(workload *cautils.WorkloadIdentifier, k8sResources *cautils.K8SResources, allResources map[string]workloadinterface.IMetadata) error {
+	target := findWorkload(workload, allResources)
+	if target == nil {
+		return fmt.Errorf("workload %s not found", workload.String())
+	}
+
+	related := relatedResources(target, allResources)
+	for resourceID := range allResources {
+		if !related[resourceID] {
+			delete(allResources, resourceID)
+		}
+	}
+	for groupResource, resourceIDs := range *k8sResources {
+		filtered := []string{}
+		for i := range resourceIDs {
+			if related[resourceIDs[i]] {
+				filtered = append(filtered, resourceIDs[i])
+			}
+		}
+		(*k8sResources)[groupResource] = filtered
+	}
+	return nil
+}
+
+func findWorkload(workload *cautils.WorkloadIdentifier, allResources map[string]workloadinterface.IMetadata) workloadinterface.IMetadata {
+	kind := workload.Kind
+	if k, ok := workloadKindShortNames[strings.ToLower(kind)]; ok {
+		kind = k
+	}
+	for _, resource := range allResources {
+		if strings.EqualFold(resource.GetKind(), kind) && resource.GetName() == workload.Name && resource.GetNamespace() == workload.Namespace {
+			return resource
+		}
+	}
+	return nil
+}
+
+// relatedResources returns the IDs of the workload and of its related objects
+func relatedResources(target workloadinterface.IMetadata, allResources map[string]workloadinterface.IMetadata) map[string]bool {
+	namespace := target.GetNamespace()
+	serviceAccount := ""
+	if target.GetKind() != "Namespace" { // the pod spec of a Namespace is its spec
+		w := workloadinterface.NewWorkloadObj(target.GetObject())
+		if _, err := w.GetPodSpec(); err == nil {
+			if serviceAccount = w.GetServiceAccountName(); serviceAccount == "" {
+				serviceAccount = "default"
+			}
+		}
+	}
+
+	related := map[string]bool{}
+	roles := map[string]bool{} // <kind>/<namespace>/<name> of the roles bound to the ServiceAccount
+	for resourceID, resource := range allResources {
+		switch resource.GetKind() {
+		case "Namespace":
+			related[resourceID] = resource.GetName() == namespace
+		case "NetworkPolicy":
+			related[resourceID] = resource.GetNamespace() == namespace
+		case NetworkPolicyCoverageObjectKind, SecretExposureObjectKind:
+			related[resourceID] = resource.GetNamespace() == namespace && resource.GetName() == strings.ToLower(target.GetKind())+"-"+target.GetName()
+		case "ServiceAccount":
+			related[resourceID] = resource.GetNamespace() == namespace && resource.GetName() == serviceAccount
+		case "RoleBinding", "ClusterRoleBinding":
+			if serviceAccount != "" && isServiceAccountSubject(resource, serviceAccount, namespace) {
+				related[resourceID] = true
+				if kind, name := roleRef(resource.GetObject()); kind == "ClusterRole" {
+					roles[fmt.Sprintf("%s//%s", kind, name)] = true
+				} else {
+					roles[fmt.Sprintf("%s/%s/%s", kind, resource.GetNamespace(), name)] = true
+				}
+			}
+		}
+	}
+	for resourceID, resource := range allResources {
+		if kind := resource.GetKind(); kind == "Role" || kind == "ClusterRole" {
+			related[resourceID] = roles[fmt.Sprintf("%s/%s/%s", kind, resource.GetNamespace(), resource.GetName())]
+		}
+	}
+	related[target.GetID()] = true
+	return related
+}
+
+func isServiceAccountSubject(binding workloadinterface.IMetadata, serviceAccount, namespace string) bool {
+	subjects, ok := binding.GetObject()["subjects"].([]interface{})
+	if !ok {
+		return false
+	}
+	for i := range subjects {
+		subject, ok := subjects[i].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		// the namespace of a RoleBinding subject defaults to the namespace of the binding
+		subjectNamespace, _ := subject["namespace"].(string)
+		if subjectNamespace == "" {
+			subjectNamespace = binding.GetNamespace()
+		}
+		if subject["kind"] == "ServiceAccount" && subject["name"] == serviceAccount && subjectNamespace == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+func roleRef(binding map[string]interface{}) (string, string) {
+	ref, ok := binding["roleRef"].(map[string]interface{})
+	if !ok {
+		return "", ""
+	}
+	kind, _ := ref["kind"].(string)
+	name, _ := ref["name"].(string)
+	return kind, name
+}
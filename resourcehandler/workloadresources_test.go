@@ -0,0 +1,81 @@
+package resourcehandler
+
+import (
+	"testing"
+
+	"github.com/armosec/k8s-interface/workloadinterface"
+	"github.com/armosec/kubescape/cautils"
+	"github.com/stretchr/testify/assert"
+)
+
+func newMetadataMock(apiVersion, kind, namespace, name string, fields map[string]interface{}) workloadinterface.IMetadata {
+	obj := map[string]interface{}{
+		"apiVersion": apiVersion,
+		"kind":       kind,
+		"metadata":   map[string]interface{}{"name": name, "namespace": namespace},
+	}
+	for k, v := range fields {
+		obj[k] = v
+	}
+	return workloadinterface.NewWorkloadObj(obj)
+}
+
+func workloadResourcesMock() (*cautils.K8SResources, map[string]workloadinterface.IMetadata) {
+	podSpec := map[string]interface{}{"template": map[string]interface{}{"spec": map[string]interface{}{"serviceAccountName": "nginx-sa"}}}
+	binding := func(kind, namespace, name, roleKind, roleName, saNamespace string) workloadinterface.IMetadata {
+		subject := map[string]interface{}{"kind": "ServiceAccount", "name": "nginx-sa"}
+		if saNamespace != "" {
+			subject["namespace"] = saNamespace
+		}
+		return newMetadataMock("rbac.authorization.k8s.io/v1", kind, namespace, name, map[string]interface{}{
+			"subjects": []interface{}{subject},
+			"roleRef":  map[string]interface{}{"kind": roleKind, "name": roleName},
+		})
+	}
+	resources := []workloadinterface.IMetadata{
+		newMetadataMock("apps/v1", "Deployment", "web", "nginx", map[string]interface{}{"spec": podSpec}),
+		newMetadataMock("apps/v1", "Deployment", "web", "redis", map[string]interface{}{"spec": map[string]interface{}{"template": map[string]interface{}{"spec": map[string]interface{}{}}}}),
+		newMetadataMock("v1", "ServiceAccount", "web", "nginx-sa", nil),
+		newMetadataMock("v1", "ServiceAccount", "web", "default", nil),
+		newMetadataMock("v1", "Namespace", "", "web", nil),
+		newMetadataMock("v1", "Namespace", "", "kube-system", nil),
+		newMetadataMock("networking.k8s.io/v1", "NetworkPolicy", "web", "deny-all", nil),
+		binding("RoleBinding", "web", "nginx-rb", "Role", "nginx-role", ""),
+		binding("RoleBinding", "other", "other-rb", "Role", "other-role", "other"),
+		binding("ClusterRoleBinding", "", "nginx-crb", "ClusterRole", "view", "web"),
+		newMetadataMock("rbac.authorization.k8s.io/v1", "Role", "web", "nginx-role", nil),
+		newMetadataMock("rbac.authorization.k8s.io/v1", "Role", "other", "other-role", nil),
+		newMetadataMock("rbac.authorization.k8s.io/v1", "ClusterRole", "", "view", nil),
+		newMetadataMock("rbac.authorization.k8s.io/v1", "ClusterRole", "", "admin", nil),
+	}
+	allResources := map[string]workloadinterface.IMetadata{}
+	k8sResources := cautils.K8SResources{}
+	for i := range resources {
+		allResources[resources[i].GetID()] = resources[i]
+		k8sResources[resources[i].GetKind()] = append(k8sResources[resources[i].GetKind()], resources[i].GetID())
+	}
+	return &k8sResources, allResources
+}
+
+func TestFilterWorkloadResources(t *testing.T) {
+	k8sResources, allResources := workloadResourcesMock()
+	err := filterWorkloadResources(&cautils.WorkloadIdentifier{Kind: "deploy", Name: "nginx", Namespace: "web"}, k8sResources, allResources)
+	assert.NoError(t, err)
+
+	names := []string{}
+	for _, resource := range allResources {
+		names = append(names, resource.GetKind()+"/"+resource.GetName())
+	}
+	assert.ElementsMatch(t, []string{"Deployment/nginx", "ServiceAccount/nginx-sa", "Namespace/web", "NetworkPolicy/deny-all",
+		"RoleBinding/nginx-rb", "ClusterRoleBinding/nginx-crb", "Role/nginx-role", "ClusterRole/view"}, names)
+
+	assert.Equal(t, 1, len((*k8sResources)["Deployment"]))
+	assert.Equal(t, 1, len((*k8sResources)["ClusterRole"]))
+	assert.Equal(t, 1, len((*k8sResources)["Namespace"]))
+}
+
+func TestFilterWorkloadResourcesNotFound(t *testing.T) {
+	k8sResources, allResources := workloadResourcesMock()
+	assert.Error(t, filterWorkloadResources(&cautils.WorkloadIdentifier{Kind: "Deployment", Name: "nginx", Namespace: "default"}, k8sResources, allResources))
+	assert.Error(t, filterWorkloadResources(&cautils.WorkloadIdentifier{Kind: "StatefulSet", Name: "nginx", Namespace: "web"}, k8sResources, allResources))
+}
@@ -0,0 +1,99 @@
+package diff
+
+import (
+	"sort"
+
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/opa-utils/reporthandling/apis"
+	reporthandlingv2 "github.com/armosec/opa-utils/reporthandling/v2"
+)
+
+// CompareReport the side-by-side posture of two clusters
+type CompareReport struct {
+	Clusters   [2]string             `json:"clusters"`
+	Scores     [2]float32            `json:"scores"`
+	Frameworks []FrameworkComparison `json:"frameworks,omitempty"`
+	Controls   []ControlComparison   `json:"controls"` // the diverging controls first
+}
+
+type FrameworkComparison struct {
+	Name   string     `json:"name"`
+	Scores [2]float32 `json:"scores"`
+}
+
+type ControlComparison struct {
+	ControlID       string                 `json:"controlID"`
+	Name            string                 `json:"name"`
+	Severity        string                 `json:"severity"`
+	Statuses        [2]apis.ScanningStatus `json:"statuses"` // empty when the control was not evaluated in the cluster
+	FailedResources [2]int                 `json:"failedResources"`
+	Diverging       bool                   `json:"diverging"` // the control passes in one cluster but fails in the other
+	scoreFactor     float32
+}
+
+// Diverging returns the controls passing in one cluster and failing in the other
+func (compareReport *CompareReport) Diverging() []ControlComparison {
+	diverging := []ControlComparison{}
+	for i := range compareReport.Controls {
+		if compareReport.Controls[i].Diverging {
+			diverging = append(diverging, compareReport.Controls[i])
+		}
+	}
+	return diverging
+}
+
+// NewCompareReport compares the results of the scans of two clusters
+func NewCompareReport(clusters [2]string, reports [2]*reporthandlingv2.PostureReport) *CompareReport {
+	compareReport := &CompareReport{
+		Clusters: clusters,
+		Scores:   [2]float32{reports[0].SummaryDetails.Score, reports[1].SummaryDetails.Score},
+		Controls: []ControlComparison{},
+	}
+
+	otherFrameworks := map[string]float32{}
+	for _, framework := range reports[1].SummaryDetails.Frameworks {
+		otherFrameworks[framework.Name] = framework.Score
+	}
+	for _, framework := range reports[0].SummaryDetails.Frameworks {
+		if score, ok := otherFrameworks[framework.Name]; ok {
+			compareReport.Frameworks = append(compareReport.Frameworks, FrameworkComparison{Name: framework.Name, Scores: [2]float32{framework.Score, score}})
+		}
+	}
+
+	controls := map[string]*ControlComparison{}
+	for i := range reports {
+		failed := failedResources(reports[i])
+		for _, controlID := range reports[i].SummaryDetails.Controls.GetIDs() {
+			control := reports[i].SummaryDetails.Controls[controlID]
+			controlComparison, ok := controls[controlID]
+			if !ok {
+				controlComparison = &ControlComparison{
+					ControlID:   controlID,
+					Name:        control.GetName(),
+					Severity:    cautils.ControlSeverityToString(control.GetScoreFactor()),
+					scoreFactor: control.GetScoreFactor(),
+				}
+				controls[controlID] = controlComparison
+			}
+			controlComparison.Statuses[i] = control.GetStatus().Status()
+			controlComparison.FailedResources[i] = len(failed[controlID])
+		}
+	}
+	for _, controlComparison := range controls {
+		statuses := controlComparison.Statuses
+		controlComparison.Diverging = (statuses[0] == apis.StatusFailed && statuses[1] == apis.StatusPassed) || (statuses[0] == apis.StatusPassed && statuses[1] == apis.StatusFailed)
+		compareReport.Controls = append(compareReport.Controls, *controlComparison)
+	}
+
+	sort.Slice(compareReport.Controls, func(i, j int) bool {
+		a, b := compareReport.Controls[i], compareReport.Controls[j]
+		if a.Diverging != b.Diverging {
+			return a.Diverging
+		}
+		if a.scoreFactor != b.scoreFactor {
+			return a.scoreFactor > b.scoreFactor
+		}
+		return a.ControlID < b.ControlID
+	})
+	return compareReport
+}
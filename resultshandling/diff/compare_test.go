@@ -0,0 +1,47 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/armosec/opa-utils/reporthandling/apis"
+	reporthandlingv2 "github.com/armosec/opa-utils/reporthandling/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewCompareReport(t *testing.T) {
+	staging := postureReportMock(20,
+		map[string]apis.ScanningStatus{"C-0001": apis.StatusPassed, "C-0002": apis.StatusFailed, "C-0003": apis.StatusFailed},
+		map[string]map[string]apis.ScanningStatus{
+			"a": {"C-0001": apis.StatusPassed, "C-0002": apis.StatusFailed, "C-0003": apis.StatusFailed},
+			"b": {"C-0001": apis.StatusPassed, "C-0002": apis.StatusFailed, "C-0003": apis.StatusPassed},
+		})
+	production := postureReportMock(30,
+		map[string]apis.ScanningStatus{"C-0001": apis.StatusFailed, "C-0002": apis.StatusFailed, "C-0004": apis.StatusPassed},
+		map[string]map[string]apis.ScanningStatus{
+			"a": {"C-0001": apis.StatusFailed, "C-0002": apis.StatusFailed, "C-0004": apis.StatusPassed},
+		})
+
+	compareReport := NewCompareReport([2]string{"staging", "production"}, [2]*reporthandlingv2.PostureReport{staging, production})
+
+	assert.Equal(t, [2]float32{20, 30}, compareReport.Scores)
+	assert.Equal(t, []FrameworkComparison{{Name: "nsa", Scores: [2]float32{20, 30}}}, compareReport.Frameworks)
+
+	// the diverging controls first
+	if assert.Len(t, compareReport.Controls, 4) {
+		assert.Equal(t, "C-0001", compareReport.Controls[0].ControlID)
+		assert.True(t, compareReport.Controls[0].Diverging)
+		assert.Equal(t, [2]apis.ScanningStatus{apis.StatusPassed, apis.StatusFailed}, compareReport.Controls[0].Statuses)
+		assert.Equal(t, [2]int{0, 1}, compareReport.Controls[0].FailedResources)
+
+		// failing in both clusters
+		assert.Equal(t, "C-0002", compareReport.Controls[1].ControlID)
+		assert.False(t, compareReport.Controls[1].Diverging)
+		assert.Equal(t, [2]int{2, 1}, compareReport.Controls[1].FailedResources)
+
+		// evaluated in a single cluster
+		assert.Equal(t, [2]apis.ScanningStatus{apis.StatusFailed, ""}, compareReport.Controls[2].Statuses)
+		assert.False(t, compareReport.Controls[2].Diverging)
+		assert.Equal(t, "not evaluated", controlStatus(&compareReport.Controls[3], 0))
+	}
+	assert.Len(t, compareReport.Diverging(), 1)
+}
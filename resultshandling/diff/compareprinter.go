@@ -0,0 +1,170 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/cautils/logger/helpers"
+	"github.com/armosec/kubescape/resultshandling/printer"
+	"github.com/armosec/opa-utils/reporthandling/apis"
+	"github.com/olekukonko/tablewriter"
+)
+
+type IComparePrinter interface {
+	ActionPrint(compareReport *CompareReport) error
+	SetWriter(outputFile string)
+}
+
+// NewComparePrinter returns the printer of the comparison report. Supported formats: pretty-printer/json/markdown
+func NewComparePrinter(format string) (IComparePrinter, error) {
+	switch format {
+	case printer.PrettyFormat:
+		return &PrettyComparePrinter{}, nil
+	case printer.JsonFormat:
+		return &JsonComparePrinter{}, nil
+	case printer.MarkdownFormat:
+		return &MarkdownComparePrinter{}, nil
+	default:
+		return nil, fmt.Errorf("format '%s' is not supported, supported formats: %s/%s/%s", format, printer.PrettyFormat, printer.JsonFormat, printer.MarkdownFormat)
+	}
+}
+
+// ============================================ pretty-printer ============================================
+
+type PrettyComparePrinter struct {
+	writer *os.File
+}
+
+func (prettyComparePrinter *PrettyComparePrinter) SetWriter(outputFile string) {
+	prettyComparePrinter.writer = printer.GetWriter(outputFile)
+}
+
+func (prettyComparePrinter *PrettyComparePrinter) ActionPrint(compareReport *CompareReport) error {
+	w := prettyComparePrinter.writer
+
+	diverging := compareReport.Diverging()
+	if len(diverging) > 0 {
+		cautils.WarningDisplay(w, "\nControls passing in one cluster and failing in the other (%d):\n", len(diverging))
+		for i := range diverging {
+			cautils.SimpleDisplay(w, "%s[%s] %s - %s\n", printer.INDENT, diverging[i].Severity, diverging[i].ControlID, diverging[i].Name)
+			for c := range compareReport.Clusters {
+				cautils.DescriptionDisplay(w, "%s%s%s: %s\n", printer.INDENT, printer.INDENT, compareReport.Clusters[c], controlStatus(&diverging[i], c))
+			}
+		}
+		cautils.SimpleDisplay(w, "\n")
+	}
+
+	controlsTable := tablewriter.NewWriter(w)
+	controlsTable.SetAutoWrapText(false)
+	controlsTable.SetHeader([]string{"Severity", "Control", compareReport.Clusters[0], compareReport.Clusters[1]})
+	controlsTable.SetHeaderLine(true)
+	controlsTable.SetAutoFormatHeaders(false) // the cluster names
+	controlsTable.SetColumnAlignment([]int{tablewriter.ALIGN_LEFT, tablewriter.ALIGN_LEFT, tablewriter.ALIGN_CENTER, tablewriter.ALIGN_CENTER})
+	for i := range compareReport.Controls {
+		control := &compareReport.Controls[i]
+		name := fmt.Sprintf("%s - %s", control.ControlID, control.Name)
+		if control.Diverging {
+			name = "* " + name
+		}
+		controlsTable.Append([]string{control.Severity, name, controlStatus(control, 0), controlStatus(control, 1)})
+	}
+	controlsTable.Render()
+
+	scoreTable := tablewriter.NewWriter(w)
+	scoreTable.SetAutoWrapText(false)
+	scoreTable.SetHeader([]string{"", compareReport.Clusters[0], compareReport.Clusters[1]})
+	scoreTable.SetHeaderLine(true)
+	scoreTable.SetAutoFormatHeaders(false) // the cluster names
+	scoreTable.SetColumnAlignment([]int{tablewriter.ALIGN_LEFT, tablewriter.ALIGN_CENTER, tablewriter.ALIGN_CENTER})
+	for _, framework := range compareReport.Frameworks {
+		scoreTable.Append([]string{framework.Name, percentage(framework.Scores[0]), percentage(framework.Scores[1])})
+	}
+	scoreTable.SetFooter([]string{"Overall", percentage(compareReport.Scores[0]), percentage(compareReport.Scores[1])})
+	cautils.SimpleDisplay(w, "\n")
+	scoreTable.Render()
+
+	logCompareOutputFile(w.Name())
+	return nil
+}
+
+// ================================================= json =================================================
+
+type JsonComparePrinter struct {
+	writer *os.File
+}
+
+func (jsonComparePrinter *JsonComparePrinter) SetWriter(outputFile string) {
+	jsonComparePrinter.writer = printer.GetWriter(outputFile)
+}
+
+func (jsonComparePrinter *JsonComparePrinter) ActionPrint(compareReport *CompareReport) error {
+	r, err := json.MarshalIndent(compareReport, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to Marshal comparison report object: %w", err)
+	}
+	if _, err := jsonComparePrinter.writer.Write(append(r, '\n')); err != nil {
+		return fmt.Errorf("failed to write comparison report: %w", err)
+	}
+	logCompareOutputFile(jsonComparePrinter.writer.Name())
+	return nil
+}
+
+// =============================================== markdown ===============================================
+
+type MarkdownComparePrinter struct {
+	writer *os.File
+}
+
+func (markdownComparePrinter *MarkdownComparePrinter) SetWriter(outputFile string) {
+	markdownComparePrinter.writer = printer.GetWriter(outputFile)
+}
+
+func (markdownComparePrinter *MarkdownComparePrinter) ActionPrint(compareReport *CompareReport) error {
+	clusters := fmt.Sprintf("%s | %s", printer.MarkdownEscape(compareReport.Clusters[0]), printer.MarkdownEscape(compareReport.Clusters[1]))
+
+	sb := strings.Builder{}
+	sb.WriteString("## Kubescape clusters comparison\n\n")
+	sb.WriteString(fmt.Sprintf("| | %s |\n|---|:---:|:---:|\n", clusters))
+	for _, framework := range compareReport.Frameworks {
+		sb.WriteString(fmt.Sprintf("| %s | %s | %s |\n", printer.MarkdownEscape(framework.Name), percentage(framework.Scores[0]), percentage(framework.Scores[1])))
+	}
+	sb.WriteString(fmt.Sprintf("| **Overall** | %s | %s |\n", percentage(compareReport.Scores[0]), percentage(compareReport.Scores[1])))
+
+	sb.WriteString(fmt.Sprintf("\n### Controls\n\n| Severity | Control | %s |\n|---|---|:---:|:---:|\n", clusters))
+	for i := range compareReport.Controls {
+		control := &compareReport.Controls[i]
+		name := fmt.Sprintf("%s - %s", control.ControlID, printer.MarkdownEscape(control.Name))
+		if control.Diverging {
+			name = ":warning: **" + name + "**"
+		}
+		sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n", control.Severity, name, controlStatus(control, 0), controlStatus(control, 1)))
+	}
+
+	if _, err := markdownComparePrinter.writer.WriteString(sb.String()); err != nil {
+		return fmt.Errorf("failed to write comparison report: %w", err)
+	}
+	logCompareOutputFile(markdownComparePrinter.writer.Name())
+	return nil
+}
+
+// controlStatus returns the status of the control in the cluster, with the number of the failed resources
+func controlStatus(control *ControlComparison, cluster int) string {
+	switch status := control.Statuses[cluster]; status {
+	case "":
+		return "not evaluated"
+	case apis.StatusFailed:
+		return fmt.Sprintf("failed (%d)", control.FailedResources[cluster])
+	default:
+		return string(status)
+	}
+}
+
+func logCompareOutputFile(fileName string) {
+	if fileName != "/dev/stdout" && fileName != "/dev/stderr" {
+		logger.L().Success("Comparison report saved", helpers.String("filename", fileName))
+	}
+}
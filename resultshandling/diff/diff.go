@@ -0,0 +1,162 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/opa-utils/reporthandling/apis"
+	reporthandlingv2 "github.com/armosec/opa-utils/reporthandling/v2"
+)
+
+// DiffReport the posture changes between two scan results
+type DiffReport struct {
+	OldScore        float32         `json:"oldScore"`
+	NewScore        float32         `json:"newScore"`
+	ScoreDelta      float32         `json:"scoreDelta"`
+	Frameworks      []FrameworkDiff `json:"frameworks,omitempty"`
+	NewFailures     []ControlDiff   `json:"newFailures"`     // controls that passed (or were not evaluated) and now fail
+	FixedControls   []ControlDiff   `json:"fixedControls"`   // controls that failed and now pass
+	ChangedControls []ControlDiff   `json:"changedControls"` // controls that still fail, but with different failed resources
+}
+
+type FrameworkDiff struct {
+	Name       string  `json:"name"`
+	OldScore   float32 `json:"oldScore"`
+	NewScore   float32 `json:"newScore"`
+	ScoreDelta float32 `json:"scoreDelta"`
+}
+
+type ControlDiff struct {
+	ControlID          string              `json:"controlID"`
+	Name               string              `json:"name"`
+	Severity           string              `json:"severity"`
+	OldStatus          apis.ScanningStatus `json:"oldStatus,omitempty"`
+	NewStatus          apis.ScanningStatus `json:"newStatus,omitempty"`
+	NewFailedResources []string            `json:"newFailedResources,omitempty"`
+	FixedResources     []string            `json:"fixedResources,omitempty"`
+	scoreFactor        float32
+}
+
+// IsRegression returns true when the posture degraded, i.e. the score increased or new controls fail
+func (diffReport *DiffReport) IsRegression() bool {
+	return diffReport.ScoreDelta > 0 || len(diffReport.NewFailures) > 0
+}
+
+// LoadReport loads a results file generated by 'kubescape scan --format json --format-version v2'
+func LoadReport(path string) (*reporthandlingv2.PostureReport, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseReport(path, data)
+}
+
+// ParseReport parses the data of the results file of the path
+func ParseReport(path string, data []byte) (*reporthandlingv2.PostureReport, error) {
+	report := &reporthandlingv2.PostureReport{}
+	if err := json.Unmarshal(data, report); err != nil {
+		return nil, fmt.Errorf("failed to load results file '%s': %w", path, err)
+	}
+	if report.SummaryDetails.Controls == nil && len(report.SummaryDetails.Frameworks) == 0 {
+		return nil, fmt.Errorf("results file '%s' is not in the v2 format, run the scan with '--format json --format-version v2'", path)
+	}
+	return report, nil
+}
+
+// NewDiffReport compares the results of the old scan to the results of the new scan
+func NewDiffReport(oldReport, newReport *reporthandlingv2.PostureReport) *DiffReport {
+	diffReport := &DiffReport{
+		OldScore:        oldReport.SummaryDetails.Score,
+		NewScore:        newReport.SummaryDetails.Score,
+		ScoreDelta:      newReport.SummaryDetails.Score - oldReport.SummaryDetails.Score,
+		NewFailures:     []ControlDiff{},
+		FixedControls:   []ControlDiff{},
+		ChangedControls: []ControlDiff{},
+	}
+
+	oldFrameworks := map[string]float32{}
+	for _, framework := range oldReport.SummaryDetails.Frameworks {
+		oldFrameworks[framework.Name] = framework.Score
+	}
+	for _, framework := range newReport.SummaryDetails.Frameworks {
+		if oldScore, ok := oldFrameworks[framework.Name]; ok {
+			diffReport.Frameworks = append(diffReport.Frameworks, FrameworkDiff{Name: framework.Name, OldScore: oldScore, NewScore: framework.Score, ScoreDelta: framework.Score - oldScore})
+		}
+	}
+
+	oldFailed, newFailed := failedResources(oldReport), failedResources(newReport)
+	for _, controlID := range newReport.SummaryDetails.Controls.GetIDs() {
+		newControl := newReport.SummaryDetails.Controls[controlID]
+		controlDiff := ControlDiff{
+			ControlID:          controlID,
+			Name:               newControl.GetName(),
+			Severity:           cautils.ControlSeverityToString(newControl.GetScoreFactor()),
+			scoreFactor:        newControl.GetScoreFactor(),
+			NewStatus:          newControl.GetStatus().Status(),
+			NewFailedResources: subtract(newFailed[controlID], oldFailed[controlID]),
+			FixedResources:     subtract(oldFailed[controlID], newFailed[controlID]),
+		}
+		if oldControl, ok := oldReport.SummaryDetails.Controls[controlID]; ok {
+			controlDiff.OldStatus = oldControl.GetStatus().Status()
+		} else {
+			// the control was not evaluated by the old scan, the failed resources are not new failures of existing resources
+			controlDiff.FixedResources = nil
+		}
+
+		switch {
+		case controlDiff.NewStatus == apis.StatusFailed && controlDiff.OldStatus != apis.StatusFailed:
+			diffReport.NewFailures = append(diffReport.NewFailures, controlDiff)
+		case controlDiff.OldStatus == apis.StatusFailed && controlDiff.NewStatus == apis.StatusPassed:
+			diffReport.FixedControls = append(diffReport.FixedControls, controlDiff)
+		case controlDiff.NewStatus == apis.StatusFailed && (len(controlDiff.NewFailedResources) > 0 || len(controlDiff.FixedResources) > 0):
+			diffReport.ChangedControls = append(diffReport.ChangedControls, controlDiff)
+		}
+	}
+
+	for _, controls := range [][]ControlDiff{diffReport.NewFailures, diffReport.FixedControls, diffReport.ChangedControls} {
+		sortControls(controls)
+	}
+	return diffReport
+}
+
+// failedResources returns the IDs of the failed resources, grouped by control ID
+func failedResources(report *reporthandlingv2.PostureReport) map[string][]string {
+	failed := map[string][]string{}
+	for i := range report.Results {
+		for _, control := range report.Results[i].ListControls() {
+			if control.GetStatus(nil).IsFailed() {
+				failed[control.GetID()] = append(failed[control.GetID()], report.Results[i].GetResourceID())
+			}
+		}
+	}
+	return failed
+}
+
+// subtract returns the sorted strings of a which are not in b
+func subtract(a, b []string) []string {
+	exists := map[string]bool{}
+	for i := range b {
+		exists[b[i]] = true
+	}
+	s := []string{}
+	for i := range a {
+		if !exists[a[i]] {
+			s = append(s, a[i])
+		}
+	}
+	sort.Strings(s)
+	return s
+}
+
+// sortControls sorts the controls by severity, then by ID
+func sortControls(controls []ControlDiff) {
+	sort.Slice(controls, func(i, j int) bool {
+		if controls[i].scoreFactor != controls[j].scoreFactor {
+			return controls[i].scoreFactor > controls[j].scoreFactor
+		}
+		return controls[i].ControlID < controls[j].ControlID
+	})
+}
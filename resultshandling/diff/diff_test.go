@@ -0,0 +1,118 @@
+package diff
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/armosec/opa-utils/reporthandling/apis"
+	"github.com/armosec/opa-utils/reporthandling/results/v1/reportsummary"
+	"github.com/armosec/opa-utils/reporthandling/results/v1/resourcesresults"
+	reporthandlingv2 "github.com/armosec/opa-utils/reporthandling/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func postureReportMock(score float32, controls map[string]apis.ScanningStatus, results map[string]map[string]apis.ScanningStatus) *reporthandlingv2.PostureReport {
+	report := &reporthandlingv2.PostureReport{
+		SummaryDetails: reportsummary.SummaryDetails{
+			Score:      score,
+			Frameworks: []reportsummary.FrameworkSummary{{Name: "nsa", Score: score}},
+			Controls:   reportsummary.ControlSummaries{},
+		},
+	}
+	for controlID, status := range controls {
+		report.SummaryDetails.Controls[controlID] = reportsummary.ControlSummary{ControlID: controlID, Name: controlID, Status: status, ScoreFactor: 7}
+	}
+	for resourceID, resourceControls := range results {
+		result := resourcesresults.Result{ResourceID: resourceID}
+		for controlID, status := range resourceControls {
+			result.AssociatedControls = append(result.AssociatedControls, resourcesresults.ResourceAssociatedControl{
+				ControlID:               controlID,
+				ResourceAssociatedRules: []resourcesresults.ResourceAssociatedRule{{Name: "rule", Status: status}},
+			})
+		}
+		report.Results = append(report.Results, result)
+	}
+	return report
+}
+
+func TestNewDiffReport(t *testing.T) {
+	oldReport := postureReportMock(40,
+		map[string]apis.ScanningStatus{"C-0001": apis.StatusFailed, "C-0002": apis.StatusPassed, "C-0003": apis.StatusFailed, "C-0004": apis.StatusFailed},
+		map[string]map[string]apis.ScanningStatus{
+			"a": {"C-0001": apis.StatusFailed, "C-0002": apis.StatusPassed, "C-0003": apis.StatusFailed, "C-0004": apis.StatusFailed},
+			"b": {"C-0001": apis.StatusPassed, "C-0002": apis.StatusPassed, "C-0003": apis.StatusPassed, "C-0004": apis.StatusFailed},
+		})
+	newReport := postureReportMock(50,
+		map[string]apis.ScanningStatus{"C-0001": apis.StatusPassed, "C-0002": apis.StatusFailed, "C-0003": apis.StatusFailed, "C-0004": apis.StatusFailed, "C-0005": apis.StatusFailed},
+		map[string]map[string]apis.ScanningStatus{
+			"a": {"C-0001": apis.StatusPassed, "C-0002": apis.StatusPassed, "C-0003": apis.StatusPassed, "C-0004": apis.StatusFailed, "C-0005": apis.StatusFailed},
+			"b": {"C-0001": apis.StatusPassed, "C-0002": apis.StatusFailed, "C-0003": apis.StatusFailed, "C-0004": apis.StatusFailed, "C-0005": apis.StatusPassed},
+		})
+
+	diffReport := NewDiffReport(oldReport, newReport)
+
+	assert.Equal(t, float32(10), diffReport.ScoreDelta)
+	assert.Equal(t, []FrameworkDiff{{Name: "nsa", OldScore: 40, NewScore: 50, ScoreDelta: 10}}, diffReport.Frameworks)
+	assert.True(t, diffReport.IsRegression())
+
+	// C-0005 was not evaluated by the old scan
+	if assert.Len(t, diffReport.NewFailures, 2) {
+		assert.Equal(t, "C-0002", diffReport.NewFailures[0].ControlID)
+		assert.Equal(t, []string{"b"}, diffReport.NewFailures[0].NewFailedResources)
+		assert.Equal(t, "C-0005", diffReport.NewFailures[1].ControlID)
+		assert.Equal(t, apis.ScanningStatus(""), diffReport.NewFailures[1].OldStatus)
+		assert.Nil(t, diffReport.NewFailures[1].FixedResources)
+	}
+	if assert.Len(t, diffReport.FixedControls, 1) {
+		assert.Equal(t, "C-0001", diffReport.FixedControls[0].ControlID)
+		assert.Equal(t, []string{"a"}, diffReport.FixedControls[0].FixedResources)
+	}
+	// C-0004 fails on the same resources
+	if assert.Len(t, diffReport.ChangedControls, 1) {
+		assert.Equal(t, "C-0003", diffReport.ChangedControls[0].ControlID)
+		assert.Equal(t, []string{"b"}, diffReport.ChangedControls[0].NewFailedResources)
+		assert.Equal(t, []string{"a"}, diffReport.ChangedControls[0].FixedResources)
+	}
+
+	// no changes
+	diffReport = NewDiffReport(newReport, newReport)
+	assert.False(t, diffReport.IsRegression())
+	assert.Empty(t, diffReport.NewFailures)
+	assert.Empty(t, diffReport.FixedControls)
+	assert.Empty(t, diffReport.ChangedControls)
+}
+
+func TestNewDiffPrinter(t *testing.T) {
+	for _, format := range []string{"pretty-printer", "json", "markdown"} {
+		if _, err := NewDiffPrinter(format); err != nil {
+			t.Errorf("unexpected error for format %s: %v", format, err)
+		}
+	}
+	if _, err := NewDiffPrinter("pdf"); err == nil {
+		t.Errorf("expected an error for an unsupported format")
+	}
+}
+
+func TestDiffPrinterWriteError(t *testing.T) {
+	diffReport := NewDiffReport(postureReportMock(20, nil, nil), postureReportMock(10, nil, nil))
+	for _, format := range []string{"json", "markdown"} {
+		diffPrinter, _ := NewDiffPrinter(format)
+		outputFile := filepath.Join(t.TempDir(), "diff")
+		diffPrinter.SetWriter(outputFile)
+		assert.NoError(t, diffPrinter.ActionPrint(diffReport), format)
+
+		// the writer was closed
+		diffPrinter.SetWriter(outputFile)
+		closeWriter(diffPrinter)
+		assert.Error(t, diffPrinter.ActionPrint(diffReport), format)
+	}
+}
+
+func closeWriter(diffPrinter IDiffPrinter) {
+	switch p := diffPrinter.(type) {
+	case *JsonDiffPrinter:
+		p.writer.Close()
+	case *MarkdownDiffPrinter:
+		p.writer.Close()
+	}
+}
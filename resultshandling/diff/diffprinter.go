@@ -0,0 +1,177 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/cautils/logger/helpers"
+	"github.com/armosec/kubescape/resultshandling/printer"
+	"github.com/olekukonko/tablewriter"
+)
+
+type IDiffPrinter interface {
+	ActionPrint(diffReport *DiffReport) error
+	SetWriter(outputFile string)
+}
+
+// NewDiffPrinter returns the printer of the diff report. Supported formats: pretty-printer/json/markdown
+func NewDiffPrinter(format string) (IDiffPrinter, error) {
+	switch format {
+	case printer.PrettyFormat:
+		return &PrettyDiffPrinter{}, nil
+	case printer.JsonFormat:
+		return &JsonDiffPrinter{}, nil
+	case printer.MarkdownFormat:
+		return &MarkdownDiffPrinter{}, nil
+	default:
+		return nil, fmt.Errorf("format '%s' is not supported, supported formats: %s/%s/%s", format, printer.PrettyFormat, printer.JsonFormat, printer.MarkdownFormat)
+	}
+}
+
+// ============================================ pretty-printer ============================================
+
+type PrettyDiffPrinter struct {
+	writer *os.File
+}
+
+func (prettyDiffPrinter *PrettyDiffPrinter) SetWriter(outputFile string) {
+	prettyDiffPrinter.writer = printer.GetWriter(outputFile)
+}
+
+func (prettyDiffPrinter *PrettyDiffPrinter) ActionPrint(diffReport *DiffReport) error {
+	w := prettyDiffPrinter.writer
+
+	prettyDiffPrinter.printControls("New failures", diffReport.NewFailures, cautils.FailureDisplay)
+	prettyDiffPrinter.printControls("Fixed controls", diffReport.FixedControls, cautils.SuccessDisplay)
+	prettyDiffPrinter.printControls("Changed controls", diffReport.ChangedControls, cautils.WarningDisplay)
+
+	scoreTable := tablewriter.NewWriter(w)
+	scoreTable.SetAutoWrapText(false)
+	scoreTable.SetHeader([]string{"", "Old risk-score", "New risk-score", "Delta"})
+	scoreTable.SetHeaderLine(true)
+	scoreTable.SetColumnAlignment([]int{tablewriter.ALIGN_LEFT, tablewriter.ALIGN_CENTER, tablewriter.ALIGN_CENTER, tablewriter.ALIGN_CENTER})
+	for _, framework := range diffReport.Frameworks {
+		scoreTable.Append([]string{framework.Name, percentage(framework.OldScore), percentage(framework.NewScore), delta(framework.ScoreDelta)})
+	}
+	scoreTable.SetFooter([]string{"Overall", percentage(diffReport.OldScore), percentage(diffReport.NewScore), delta(diffReport.ScoreDelta)})
+	scoreTable.Render()
+
+	if diffReport.IsRegression() {
+		cautils.FailureDisplay(w, "\nThe security posture degraded\n")
+	} else {
+		cautils.SuccessDisplay(w, "\nThe security posture did not degrade\n")
+	}
+
+	logOutputFile(w.Name())
+	return nil
+}
+
+func (prettyDiffPrinter *PrettyDiffPrinter) printControls(title string, controls []ControlDiff, display func(w io.Writer, format string, a ...interface{})) {
+	if len(controls) == 0 {
+		return
+	}
+	display(prettyDiffPrinter.writer, "\n%s (%d):\n", title, len(controls))
+	for i := range controls {
+		cautils.SimpleDisplay(prettyDiffPrinter.writer, "%s[%s] %s - %s\n", printer.INDENT, controls[i].Severity, controls[i].ControlID, controls[i].Name)
+		for _, resourceID := range controls[i].NewFailedResources {
+			cautils.FailureTextDisplay(prettyDiffPrinter.writer, "%s%s+ %s\n", printer.INDENT, printer.INDENT, resourceID)
+		}
+		for _, resourceID := range controls[i].FixedResources {
+			cautils.DescriptionDisplay(prettyDiffPrinter.writer, "%s%s- %s\n", printer.INDENT, printer.INDENT, resourceID)
+		}
+	}
+	cautils.SimpleDisplay(prettyDiffPrinter.writer, "\n")
+}
+
+// ================================================= json =================================================
+
+type JsonDiffPrinter struct {
+	writer *os.File
+}
+
+func (jsonDiffPrinter *JsonDiffPrinter) SetWriter(outputFile string) {
+	jsonDiffPrinter.writer = printer.GetWriter(outputFile)
+}
+
+func (jsonDiffPrinter *JsonDiffPrinter) ActionPrint(diffReport *DiffReport) error {
+	r, err := json.MarshalIndent(diffReport, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to Marshal diff report object: %w", err)
+	}
+	if _, err := jsonDiffPrinter.writer.Write(append(r, '\n')); err != nil {
+		return fmt.Errorf("failed to write diff report: %w", err)
+	}
+	logOutputFile(jsonDiffPrinter.writer.Name())
+	return nil
+}
+
+// =============================================== markdown ===============================================
+
+type MarkdownDiffPrinter struct {
+	writer *os.File
+}
+
+func (markdownDiffPrinter *MarkdownDiffPrinter) SetWriter(outputFile string) {
+	markdownDiffPrinter.writer = printer.GetWriter(outputFile)
+}
+
+func (markdownDiffPrinter *MarkdownDiffPrinter) ActionPrint(diffReport *DiffReport) error {
+	sb := strings.Builder{}
+	sb.WriteString("## Kubescape security posture diff\n\n")
+	sb.WriteString("| | Old risk-score | New risk-score | Delta |\n|---|:---:|:---:|:---:|\n")
+	for _, framework := range diffReport.Frameworks {
+		sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n", printer.MarkdownEscape(framework.Name), percentage(framework.OldScore), percentage(framework.NewScore), delta(framework.ScoreDelta)))
+	}
+	sb.WriteString(fmt.Sprintf("| **Overall** | %s | %s | %s |\n", percentage(diffReport.OldScore), percentage(diffReport.NewScore), delta(diffReport.ScoreDelta)))
+
+	for _, section := range []struct {
+		title    string
+		controls []ControlDiff
+	}{
+		{":x: New failures", diffReport.NewFailures},
+		{":white_check_mark: Fixed controls", diffReport.FixedControls},
+		{":warning: Changed controls", diffReport.ChangedControls},
+	} {
+		if len(section.controls) == 0 {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("\n### %s (%d)\n\n", section.title, len(section.controls)))
+		sb.WriteString("| Severity | Control | New failed resources | Fixed resources |\n|---|---|---|---|\n")
+		for _, control := range section.controls {
+			sb.WriteString(fmt.Sprintf("| %s | %s - %s | %s | %s |\n", control.Severity, control.ControlID, printer.MarkdownEscape(control.Name), markdownList(control.NewFailedResources), markdownList(control.FixedResources)))
+		}
+	}
+
+	if _, err := markdownDiffPrinter.writer.WriteString(sb.String()); err != nil {
+		return fmt.Errorf("failed to write diff report: %w", err)
+	}
+	logOutputFile(markdownDiffPrinter.writer.Name())
+	return nil
+}
+
+func markdownList(l []string) string {
+	escaped := make([]string, len(l))
+	for i := range l {
+		escaped[i] = "`" + printer.MarkdownEscape(l[i]) + "`"
+	}
+	return strings.Join(escaped, "<br>")
+}
+
+func percentage(score float32) string {
+	return fmt.Sprintf("%.2f%%", score)
+}
+
+func delta(scoreDelta float32) string {
+	return fmt.Sprintf("%+.2f%%", scoreDelta)
+}
+
+func logOutputFile(fileName string) {
+	if fileName != "/dev/stdout" && fileName != "/dev/stderr" {
+		logger.L().Success("Diff report saved", helpers.String("filename", fileName))
+	}
+}
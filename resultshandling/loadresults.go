@@ -0,0 +1,30 @@
+package resultshandling
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/resultshandling/diff"
+	reporthandlingv2 "github.com/armosec/opa-utils/reporthandling/v2"
+)
+
+// LoadResults loads a results file of 'kubescape scan --format json --format-version v2' and the source files of the resources loaded from files
+func LoadResults(path string) (*reporthandlingv2.PostureReport, map[string]cautils.ResourceSource, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	report, err := diff.ParseReport(path, data)
+	if err != nil {
+		return nil, nil, err
+	}
+	sources := struct {
+		ResourcesSource map[string]cautils.ResourceSource `json:"resourcesSource"`
+	}{}
+	if err := json.Unmarshal(data, &sources); err != nil {
+		return nil, nil, fmt.Errorf("failed to load results file '%s': %w", path, err)
+	}
+	return report, sources.ResourcesSource, nil
+}
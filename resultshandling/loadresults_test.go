@@ -0,0 +1,27 @@
+package resultshandling
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/armosec/kubescape/cautils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadResults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"summaryDetails": {"score": 12, "controls": {}}, "resourcesSource": {"apps/v1/prod/Deployment/web": {"path": "web.yaml", "line": 3}}}`), 0644))
+	report, sources, err := LoadResults(path)
+	assert.NoError(t, err)
+	assert.Equal(t, float32(12), report.SummaryDetails.Score)
+	assert.Equal(t, map[string]cautils.ResourceSource{"apps/v1/prod/Deployment/web": {Path: "web.yaml", Line: 3}}, sources)
+
+	// v1 results are not loaded
+	assert.NoError(t, os.WriteFile(path, []byte(`[{"name": "nsa"}]`), 0644))
+	_, _, err = LoadResults(path)
+	assert.Error(t, err)
+
+	_, _, err = LoadResults(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+}
@@ -3,9 +3,11 @@ package printer
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/armosec/kubescape/cautils"
 	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/cautils/logger/helpers"
 )
 
 var INDENT = "   "
@@ -16,10 +18,22 @@ const (
 	JunitResultFormat string = "junit"
 	PrometheusFormat  string = "prometheus"
 	PdfFormat         string = "pdf"
+	SARIFFormat       string = "sarif"
+	CSVFormat         string = "csv"
+	XLSXFormat        string = "xlsx"
+	GoTemplateFormat  string = "gotemplate"
+	MarkdownFormat    string = "markdown"
+	HTMLFormat        string = "html"
+	CodeQualityFormat string = "gitlab-codequality"
+	GithubFormat      string = "github-annotations"
+	OSCALFormat       string = "oscal"
+	CEFFormat         string = "cef"
+	NDJSONFormat      string = "ndjson"
+	DotFormat         string = "dot"
 )
 
 type IPrinter interface {
-	ActionPrint(opaSessionObj *cautils.OPASessionObj)
+	ActionPrint(opaSessionObj *cautils.OPASessionObj) error
 	SetWriter(outputFile string)
 	Score(score float32)
 }
@@ -37,3 +51,15 @@ func GetWriter(outputFile string) *os.File {
 	return os.Stdout
 
 }
+
+// LogScore logs the overall risk-score of the scan, by the printers of the formats not showing the score in their output
+func LogScore(score float32) {
+	logger.L().Info("Overall risk-score (0- Excellent, 100- All failed)", helpers.Int("risk-score", int(score)))
+}
+
+var markdownReplacer = strings.NewReplacer("|", `\|`, "<", "&lt;", ">", "&gt;", "\n", " ")
+
+// MarkdownEscape escapes text so it does not break markdown tables and html tags
+func MarkdownEscape(s string) string {
+	return markdownReplacer.Replace(s)
+}
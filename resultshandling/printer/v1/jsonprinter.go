@@ -6,7 +6,6 @@ import (
 	"os"
 
 	"github.com/armosec/kubescape/cautils"
-	"github.com/armosec/kubescape/cautils/logger"
 	"github.com/armosec/kubescape/resultshandling/printer"
 )
 
@@ -23,10 +22,11 @@ func (jsonPrinter *JsonPrinter) SetWriter(outputFile string) {
 }
 
 func (jsonPrinter *JsonPrinter) Score(score float32) {
-	fmt.Fprintf(os.Stderr, "\nOverall risk-score (0- Excellent, 100- All failed): %d\n", int(score))
+	printer.LogScore(score)
 }
 
-func (jsonPrinter *JsonPrinter) ActionPrint(opaSessionObj *cautils.OPASessionObj) {
+// ActionPrint prints the framework reports, the expired exceptions are not part of the v1 format (they are logged by the policy handler)
+func (jsonPrinter *JsonPrinter) ActionPrint(opaSessionObj *cautils.OPASessionObj) error {
 	cautils.ReportV2ToV1(opaSessionObj)
 
 	var postureReportStr []byte
@@ -39,7 +39,8 @@ func (jsonPrinter *JsonPrinter) ActionPrint(opaSessionObj *cautils.OPASessionObj
 	}
 
 	if err != nil {
-		logger.L().Fatal("failed to convert posture report object")
+		return fmt.Errorf("failed to convert posture report object: %w", err)
 	}
-	jsonPrinter.writer.Write(postureReportStr)
+	_, err = jsonPrinter.writer.Write(postureReportStr)
+	return err
 }
@@ -6,7 +6,6 @@ import (
 
 	"github.com/armosec/k8s-interface/workloadinterface"
 	"github.com/armosec/kubescape/cautils"
-	"github.com/armosec/kubescape/cautils/logger"
 	"github.com/armosec/kubescape/resultshandling/printer"
 	"github.com/armosec/opa-utils/reporthandling"
 )
@@ -86,11 +85,9 @@ func (printer *PrometheusPrinter) printReports(allResources map[string]workloadi
 	return nil
 }
 
-func (printer *PrometheusPrinter) ActionPrint(opaSessionObj *cautils.OPASessionObj) {
+func (printer *PrometheusPrinter) ActionPrint(opaSessionObj *cautils.OPASessionObj) error {
 	cautils.ReportV2ToV1(opaSessionObj)
 
-	err := printer.printReports(opaSessionObj.AllResources, opaSessionObj.PostureReport.FrameworkReports)
-	if err != nil {
-		logger.L().Fatal(err.Error())
-	}
+	fmt.Fprintf(printer.writer, "# Number of exceptions which expired and were not applied\nkubescape_expired_exceptions %d\n", len(opaSessionObj.ExpiredExceptions))
+	return printer.printReports(opaSessionObj.AllResources, opaSessionObj.PostureReport.FrameworkReports)
 }
@@ -0,0 +1,129 @@
+package v2
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/resultshandling/printer"
+	"github.com/armosec/opa-utils/reporthandling/results/v1/reportsummary"
+)
+
+// https://www.microfocus.com/documentation/arcsight/arcsight-smartconnectors-8.3/cef-implementation-standard/
+
+// cefEvent a single failed (control, resource) pair, or an expired exception
+type cefEvent struct {
+	severity string
+	event    string
+}
+
+type CefPrinter struct {
+	writer *os.File
+}
+
+func NewCefPrinter() *CefPrinter {
+	return &CefPrinter{}
+}
+
+func (cefPrinter *CefPrinter) SetWriter(outputFile string) {
+	cefPrinter.writer = printer.GetWriter(outputFile)
+}
+
+func (cefPrinter *CefPrinter) Score(score float32) {
+	printer.LogScore(score)
+}
+
+func (cefPrinter *CefPrinter) ActionPrint(opaSessionObj *cautils.OPASessionObj) error {
+	return encodeCef(cefPrinter.writer, opaSessionObj)
+}
+
+func encodeCef(writer io.Writer, opaSessionObj *cautils.OPASessionObj) error {
+	for _, event := range cefEvents(opaSessionObj) {
+		if _, err := fmt.Fprintln(writer, event.event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cefEvents returns a CEF event per failed (control, resource) pair, followed by an event per expired exception
+func cefEvents(opaSessionObj *cautils.OPASessionObj) []cefEvent {
+	events := []cefEvent{}
+
+	controls := opaSessionObj.Report.SummaryDetails.Controls
+	controlIDs := controls.ListControlsIDs().Failed()
+	sort.Strings(controlIDs)
+
+	reportTime := opaSessionObj.Report.ReportGenerationTime.UnixNano() / 1e6
+	for _, controlID := range controlIDs {
+		control := controls.GetControl(reportsummary.EControlCriteriaID, controlID)
+		if control == nil {
+			continue
+		}
+		severity := cautils.ControlSeverityToString(control.GetScoreFactor())
+		resourceIDs := control.ListResourcesIDs().Failed()
+		sort.Strings(resourceIDs)
+		for _, resourceID := range resourceIDs {
+			resource, ok := opaSessionObj.AllResources[resourceID]
+			if !ok {
+				continue
+			}
+			extension := []string{
+				fmt.Sprintf("rt=%d", reportTime),
+				"cs1Label=namespace", "cs1=" + escapeCefExtension(resource.GetNamespace()),
+				"cs2Label=kind", "cs2=" + escapeCefExtension(resource.GetKind()),
+				"cs3Label=name", "cs3=" + escapeCefExtension(resource.GetName()),
+				"cs4Label=severity", "cs4=" + severity,
+				"cs5Label=cluster", "cs5=" + escapeCefExtension(opaSessionObj.Report.ClusterName),
+				"cs6Label=remediation", "cs6=" + escapeCefExtension(control.GetRemediation()),
+				"request=" + escapeCefExtension(getControlURL(control.GetID())),
+				"msg=" + escapeCefExtension(fmt.Sprintf("%s failed control '%s'", resourceFullName(resource), control.GetName())),
+			}
+			events = append(events, cefEvent{
+				severity: severity,
+				event: fmt.Sprintf("CEF:0|ARMO|Kubescape|%s|%s|%s|%d|%s",
+					escapeCefHeader(cautils.BuildNumber),
+					escapeCefHeader(control.GetID()),
+					escapeCefHeader(control.GetName()),
+					int(control.GetScoreFactor()),
+					strings.Join(extension, " ")),
+			})
+		}
+	}
+
+	expiredExceptions := listExpiredExceptions(opaSessionObj)
+	for i := range expiredExceptions {
+		extension := []string{
+			fmt.Sprintf("rt=%d", reportTime),
+			"cs1Label=owner", "cs1=" + escapeCefExtension(expiredExceptions[i].Owner),
+			"cs2Label=reason", "cs2=" + escapeCefExtension(expiredExceptions[i].Reason),
+			"cs3Label=expirationDate", "cs3=" + escapeCefExtension(expiredExceptions[i].ExpirationDate),
+			"cs5Label=cluster", "cs5=" + escapeCefExtension(opaSessionObj.Report.ClusterName),
+			"msg=" + escapeCefExtension(fmt.Sprintf("exception '%s' expired and is not applied", expiredExceptions[i].Name)),
+		}
+		events = append(events, cefEvent{
+			severity: cautils.SeverityLow,
+			event: fmt.Sprintf("CEF:0|ARMO|Kubescape|%s|expired-exception|%s|3|%s",
+				escapeCefHeader(cautils.BuildNumber),
+				escapeCefHeader("Expired exception "+expiredExceptions[i].Name),
+				strings.Join(extension, " ")),
+		})
+	}
+	return events
+}
+
+var (
+	cefHeaderReplacer    = strings.NewReplacer(`\`, `\\`, "|", `\|`, "\n", " ", "\r", " ")
+	cefExtensionReplacer = strings.NewReplacer(`\`, `\\`, "=", `\=`, "\n", `\n`, "\r", `\r`)
+)
+
+func escapeCefHeader(s string) string {
+	return cefHeaderReplacer.Replace(s)
+}
+
+func escapeCefExtension(s string) string {
+	return cefExtensionReplacer.Replace(s)
+}
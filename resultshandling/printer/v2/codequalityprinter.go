@@ -0,0 +1,141 @@
+package v2
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/cautils/logger/helpers"
+	"github.com/armosec/kubescape/resultshandling/printer"
+	"github.com/armosec/opa-utils/reporthandling/results/v1/reportsummary"
+)
+
+// https://docs.gitlab.com/ee/user/project/merge_requests/code_quality.html#implementing-a-custom-tool
+
+// CodeQualityIssue represents a single resource that failed a control
+type CodeQualityIssue struct {
+	Description string              `json:"description"`
+	CheckName   string              `json:"check_name"`
+	Fingerprint string              `json:"fingerprint"`
+	Severity    string              `json:"severity"`
+	Location    CodeQualityLocation `json:"location"`
+}
+
+type CodeQualityLocation struct {
+	Path  string           `json:"path"`
+	Lines CodeQualityLines `json:"lines"`
+}
+
+type CodeQualityLines struct {
+	Begin int `json:"begin"`
+}
+
+type CodeQualityPrinter struct {
+	writer *os.File
+}
+
+func NewCodeQualityPrinter() *CodeQualityPrinter {
+	return &CodeQualityPrinter{}
+}
+
+func (codeQualityPrinter *CodeQualityPrinter) SetWriter(outputFile string) {
+	codeQualityPrinter.writer = printer.GetWriter(outputFile)
+}
+
+func (codeQualityPrinter *CodeQualityPrinter) Score(score float32) {
+	printer.LogScore(score)
+}
+
+func (codeQualityPrinter *CodeQualityPrinter) ActionPrint(opaSessionObj *cautils.OPASessionObj) error {
+	if err := encodeCodeQuality(codeQualityPrinter.writer, opaSessionObj); err != nil {
+		return err
+	}
+
+	logOUtputFile(codeQualityPrinter.writer.Name())
+	return nil
+}
+
+func encodeCodeQuality(writer io.Writer, opaSessionObj *cautils.OPASessionObj) error {
+	r, err := json.MarshalIndent(codeQualityIssues(opaSessionObj), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to Marshal code quality result object: %w", err)
+	}
+	_, err = writer.Write(r)
+	return err
+}
+
+// codeQualityIssues returns an issue per failed resource, resources that were not loaded from a file are ignored since the report requires a path
+func codeQualityIssues(opaSessionObj *cautils.OPASessionObj) []CodeQualityIssue {
+	issues := []CodeQualityIssue{}
+
+	controls := opaSessionObj.Report.SummaryDetails.Controls
+	controlIDs := controls.ListControlsIDs().Failed()
+	sort.Strings(controlIDs)
+
+	skipped := 0
+	for _, controlID := range controlIDs {
+		control := controls.GetControl(reportsummary.EControlCriteriaID, controlID)
+		if control == nil {
+			continue
+		}
+		resourceIDs := control.ListResourcesIDs().Failed()
+		sort.Strings(resourceIDs)
+		for _, resourceID := range resourceIDs {
+			resource, ok := opaSessionObj.AllResources[resourceID]
+			if !ok {
+				continue
+			}
+			source, ok := opaSessionObj.ResourceSource[resourceID]
+			if !ok {
+				skipped++
+				continue
+			}
+			line := source.Line
+			if line == 0 {
+				line = 1
+			}
+			issues = append(issues, CodeQualityIssue{
+				Description: fmt.Sprintf("%s %s: %s. %s", control.GetID(), control.GetName(), resourceFullName(resource), control.GetRemediation()),
+				CheckName:   control.GetID(),
+				Fingerprint: codeQualityFingerprint(control.GetID(), resourceID),
+				Severity:    severityToCodeQualitySeverity(cautils.ControlSeverityToString(control.GetScoreFactor())),
+				Location: CodeQualityLocation{
+					Path:  relativeSourcePath(source.Path),
+					Lines: CodeQualityLines{Begin: line},
+				},
+			})
+		}
+	}
+	if skipped > 0 {
+		logger.L().Warning("resources without a source file are not part of the code quality report", helpers.Int("resources", skipped))
+	}
+	if len(opaSessionObj.ExpiredExceptions) > 0 {
+		// the issues of the report are located in a source file, the exceptions have none
+		logger.L().Warning("expired exceptions are not part of the code quality report", helpers.Int("exceptions", len(opaSessionObj.ExpiredExceptions)))
+	}
+	return issues
+}
+
+func codeQualityFingerprint(controlID, resourceID string) string {
+	sum := sha256.Sum256([]byte(controlID + "/" + resourceID))
+	return hex.EncodeToString(sum[:])
+}
+
+func severityToCodeQualitySeverity(severity string) string {
+	switch severity {
+	case cautils.SeverityCritical:
+		return "critical"
+	case cautils.SeverityHigh:
+		return "major"
+	case cautils.SeverityMedium:
+		return "minor"
+	default:
+		return "info"
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"sort"
 
+	"github.com/armosec/kubescape/cautils"
 	"github.com/armosec/opa-utils/reporthandling/results/v1/reportsummary"
 )
 
@@ -13,10 +14,13 @@ func generateRow(controlSummary reportsummary.IControlSummary) []string {
 	row = append(row, fmt.Sprintf("%d", controlSummary.NumberOfResources().Excluded()))
 	row = append(row, fmt.Sprintf("%d", controlSummary.NumberOfResources().All()))
 
-	if !controlSummary.GetStatus().IsSkipped() {
-		row = append(row, fmt.Sprintf("%d", int(controlSummary.GetScore()))+"%")
-	} else {
+	switch {
+	case controlSummary.GetStatus().IsSkipped():
 		row = append(row, "skipped")
+	case controlSummary.GetStatus().Status() == cautils.StatusMissingData:
+		row = append(row, "missing data")
+	default:
+		row = append(row, fmt.Sprintf("%d", int(controlSummary.GetScore()))+"%")
 	}
 	return row
 }
@@ -0,0 +1,118 @@
+package v2
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/armosec/k8s-interface/workloadinterface"
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/resultshandling/printer"
+	"github.com/armosec/opa-utils/reporthandling/apis"
+	helpersv1 "github.com/armosec/opa-utils/reporthandling/helpers/v1"
+	"github.com/armosec/opa-utils/reporthandling/results/v1/reportsummary"
+)
+
+type CsvPrinter struct {
+	writer *os.File
+}
+
+func NewCsvPrinter() *CsvPrinter {
+	return &CsvPrinter{}
+}
+
+func (csvPrinter *CsvPrinter) SetWriter(outputFile string) {
+	csvPrinter.writer = printer.GetWriter(outputFile)
+}
+
+func (csvPrinter *CsvPrinter) Score(score float32) {
+	printer.LogScore(score)
+}
+
+func (csvPrinter *CsvPrinter) ActionPrint(opaSessionObj *cautils.OPASessionObj) error {
+	if err := encodeCsv(csvPrinter.writer, opaSessionObj); err != nil {
+		return err
+	}
+
+	logOUtputFile(csvPrinter.writer.Name())
+	return nil
+}
+
+func encodeCsv(writer io.Writer, opaSessionObj *cautils.OPASessionObj) error {
+	w := csv.NewWriter(writer)
+	w.Write(getControlResourceHeaders())
+	w.WriteAll(append(generateControlResourceRows(opaSessionObj), expiredExceptionRows(opaSessionObj)...))
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("failed to write csv results: %w", err)
+	}
+	return nil
+}
+
+func getControlResourceHeaders() []string {
+	return []string{"Control ID", "Control Name", "Severity", "Status", "Namespace", "Kind", "Name", "File", "Remediation"}
+}
+
+// generateControlResourceRows returns a row per (control, resource) pair, sorted by control ID and resource ID
+func generateControlResourceRows(opaSessionObj *cautils.OPASessionObj) [][]string {
+	rows := [][]string{}
+
+	controls := opaSessionObj.Report.SummaryDetails.Controls
+	controlIDs := controls.ListControlsIDs().All()
+	sort.Strings(controlIDs)
+
+	for _, controlID := range controlIDs {
+		control := controls.GetControl(reportsummary.EControlCriteriaID, controlID)
+		if control == nil {
+			continue
+		}
+		severity := cautils.ControlSeverityToString(control.GetScoreFactor())
+		resourcesIDs := control.ListResourcesIDs()
+		for _, status := range []apis.ScanningStatus{apis.StatusFailed, apis.StatusExcluded, apis.StatusPassed} {
+			ids := resourceIDsByStatus(resourcesIDs, status)
+			sort.Strings(ids)
+			for _, resourceID := range ids {
+				resource, ok := opaSessionObj.AllResources[resourceID]
+				if !ok {
+					continue
+				}
+				rows = append(rows, generateControlResourceRow(control, severity, string(status), resource, opaSessionObj.ResourceSource))
+			}
+		}
+	}
+	return rows
+}
+
+func resourceIDsByStatus(resourcesIDs *helpersv1.AllLists, status apis.ScanningStatus) []string {
+	switch status {
+	case apis.StatusFailed:
+		return resourcesIDs.Failed()
+	case apis.StatusExcluded:
+		return resourcesIDs.Excluded()
+	case apis.StatusPassed:
+		return resourcesIDs.Passed()
+	case apis.StatusSkipped:
+		// the ignored resources, irrelevant to the control, are listed as skipped
+		return append(append([]string{}, resourcesIDs.Skipped()...), resourcesIDs.Other()...)
+	}
+	return []string{}
+}
+
+func generateControlResourceRow(control reportsummary.IControlSummary, severity, status string, resource workloadinterface.IMetadata, resourceSource map[string]cautils.ResourceSource) []string {
+	file := ""
+	if source, ok := resourceSource[resource.GetID()]; ok {
+		file = relativeSourcePath(source.Path)
+	}
+	return []string{
+		control.GetID(),
+		control.GetName(),
+		severity,
+		status,
+		resource.GetNamespace(),
+		resource.GetKind(),
+		resource.GetName(),
+		file,
+		control.GetRemediation(),
+	}
+}
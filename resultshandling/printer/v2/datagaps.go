@@ -0,0 +1,42 @@
+package v2
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/armosec/kubescape/cautils"
+)
+
+// DataGap a resource which could not be fetched, the controls requiring it were evaluated without it
+type DataGap struct {
+	Resource string   `json:"resource"` // <api group>/<api version>/<resource>
+	Reason   string   `json:"reason"`
+	Controls []string `json:"controls,omitempty"` // the controls requiring the resource, sorted
+}
+
+// listDataGaps returns the resources of the session which could not be fetched, sorted by resource
+func listDataGaps(opaSessionObj *cautils.OPASessionObj) []DataGap {
+	dataGaps := []DataGap{}
+	for resource, reason := range opaSessionObj.DataGaps {
+		dataGap := DataGap{Resource: resource, Reason: reason}
+		for controlID, resources := range opaSessionObj.ControlsDataGaps {
+			if cautils.StringInSlice(resources, resource) != cautils.ValueNotFound {
+				dataGap.Controls = append(dataGap.Controls, controlID)
+			}
+		}
+		sort.Strings(dataGap.Controls)
+		dataGaps = append(dataGaps, dataGap)
+	}
+	sort.Slice(dataGaps, func(i, j int) bool { return dataGaps[i].Resource < dataGaps[j].Resource })
+	return dataGaps
+}
+
+// String returns a one line description of the data gap
+func (dataGap *DataGap) String() string {
+	s := fmt.Sprintf("%s: %s", dataGap.Resource, dataGap.Reason)
+	if len(dataGap.Controls) > 0 {
+		s += fmt.Sprintf(", controls: %s", strings.Join(dataGap.Controls, ", "))
+	}
+	return s
+}
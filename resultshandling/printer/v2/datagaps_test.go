@@ -0,0 +1,31 @@
+package v2
+
+import (
+	"testing"
+
+	"github.com/armosec/kubescape/cautils"
+)
+
+func TestListDataGaps(t *testing.T) {
+	opaSessionObj := cautils.NewOPASessionObj(nil, nil)
+	opaSessionObj.DataGaps = map[string]string{
+		"/v1/secrets":                    "forbidden, the permission to list the resource is missing",
+		"networking.k8s.io/v1/ingresses": "the API group is not served by the cluster",
+	}
+	opaSessionObj.ControlsDataGaps = map[string][]string{
+		"C-0015": {"/v1/secrets"},
+		"C-0012": {"/v1/secrets"},
+	}
+
+	dataGaps := listDataGaps(opaSessionObj)
+	if len(dataGaps) != 2 || dataGaps[0].Resource != "/v1/secrets" {
+		t.Fatalf("expected the data gaps sorted by resource, received %v", dataGaps)
+	}
+	expected := "/v1/secrets: forbidden, the permission to list the resource is missing, controls: C-0012, C-0015"
+	if s := dataGaps[0].String(); s != expected {
+		t.Errorf("expected '%s', received '%s'", expected, s)
+	}
+	if len(dataGaps[1].Controls) != 0 {
+		t.Errorf("expected no controls requiring the ingresses, received %v", dataGaps[1].Controls)
+	}
+}
@@ -0,0 +1,129 @@
+package v2
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/armosec/kubescape/attacktracks"
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/resultshandling/printer"
+)
+
+// https://graphviz.org/doc/info/lang.html
+
+// dotSeverityColors the colors of the steps of the tracks, by the highest severity of the stage
+var dotSeverityColors = map[string]string{
+	cautils.SeverityCritical: "#d32f2f",
+	cautils.SeverityHigh:     "#f57c00",
+	cautils.SeverityMedium:   "#fbc02d",
+	cautils.SeverityLow:      "#7cb342",
+}
+
+// DotPrinter writes the attack tracks of the workloads as a Graphviz graph, a cluster per workload chaining the stages of its track,
+// e.g. dot -Tsvg report.dot -o report.svg
+type DotPrinter struct {
+	writer *os.File
+}
+
+func NewDotPrinter() *DotPrinter {
+	return &DotPrinter{}
+}
+
+func (dotPrinter *DotPrinter) SetWriter(outputFile string) {
+	dotPrinter.writer = printer.GetWriter(outputFile)
+}
+
+func (dotPrinter *DotPrinter) Score(score float32) {
+	printer.LogScore(score)
+}
+
+func (dotPrinter *DotPrinter) ActionPrint(opaSessionObj *cautils.OPASessionObj) error {
+	if err := encodeDot(dotPrinter.writer, opaSessionObj); err != nil {
+		return err
+	}
+
+	logOUtputFile(dotPrinter.writer.Name())
+	return nil
+}
+
+func encodeDot(writer io.Writer, opaSessionObj *cautils.OPASessionObj) error {
+	if err := writeDot(writer, attacktracks.ListAttackTracks(opaSessionObj)); err != nil {
+		return fmt.Errorf("failed to write the attack tracks: %w", err)
+	}
+	return nil
+}
+
+// writeDot writes a graph of the tracks, the node of a stage lists the steps of the stage
+func writeDot(w io.Writer, tracks []attacktracks.AttackTrack) error {
+	var b strings.Builder
+	b.WriteString("digraph attacktracks {\n")
+	b.WriteString("\trankdir=LR;\n")
+	b.WriteString("\tnode [shape=box, style=\"rounded,filled\", fontname=\"Helvetica\", fontsize=10];\n")
+	for i := range tracks {
+		track := &tracks[i]
+		fmt.Fprintf(&b, "\tsubgraph cluster_%d {\n", i)
+		fmt.Fprintf(&b, "\t\tlabel=%s;\n", dotQuote(fmt.Sprintf("%s (score %d)", trackTitle(track), track.Score)))
+		for _, stage := range track.Stages {
+			steps, severity := stageSteps(track, stage)
+			label := []string{stage}
+			for _, step := range steps {
+				label = append(label, stepLabel(&step))
+			}
+			color, ok := dotSeverityColors[severity]
+			if !ok {
+				color = "#e0e0e0"
+			}
+			fmt.Fprintf(&b, "\t\t%s [label=%s, fillcolor=%s];\n", dotNode(i, stage), dotQuote(strings.Join(label, "\n")), dotQuote(color))
+		}
+		for j := 1; j < len(track.Stages); j++ {
+			fmt.Fprintf(&b, "\t\t%s -> %s;\n", dotNode(i, track.Stages[j-1]), dotNode(i, track.Stages[j]))
+		}
+		b.WriteString("\t}\n")
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// stageSteps returns the steps of the stage and their highest severity
+func stageSteps(track *attacktracks.AttackTrack, stage string) ([]attacktracks.Step, string) {
+	steps := []attacktracks.Step{}
+	severity := ""
+	for _, step := range track.Steps {
+		if step.Stage != stage {
+			continue
+		}
+		steps = append(steps, step)
+		if cautils.SeverityToInt(step.Severity) > cautils.SeverityToInt(severity) {
+			severity = step.Severity
+		}
+	}
+	return steps, severity
+}
+
+func trackTitle(track *attacktracks.AttackTrack) string {
+	if track.Namespace != "" {
+		return fmt.Sprintf("%s %s/%s", track.Kind, track.Namespace, track.Name)
+	}
+	return fmt.Sprintf("%s %s", track.Kind, track.Name)
+}
+
+func stepLabel(step *attacktracks.Step) string {
+	if step.ControlID != "" {
+		return fmt.Sprintf("%s %s (%s)", step.ControlID, step.Description, step.Severity)
+	}
+	return fmt.Sprintf("%s (%s)", step.Description, step.Severity)
+}
+
+func dotNode(track int, stage string) string {
+	return dotQuote(fmt.Sprintf("%d/%s", track, stage))
+}
+
+var dotReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// dotQuote returns the quoted ID of the text
+func dotQuote(s string) string {
+	return `"` + dotReplacer.Replace(s) + `"`
+}
@@ -0,0 +1,34 @@
+package v2
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/armosec/kubescape/attacktracks"
+)
+
+func TestWriteDot(t *testing.T) {
+	tracks := []attacktracks.AttackTrack{{
+		WorkloadID: "apps/v1/prod/Deployment/web", Kind: "Deployment", Name: "web", Namespace: "prod", Score: 12,
+		Stages: []string{attacktracks.StageExposure, attacktracks.StagePrivilegeEscalation},
+		Steps: []attacktracks.Step{
+			{Stage: attacktracks.StageExposure, Description: `exposed by the LoadBalancer service "web"`, Severity: "High"},
+			{Stage: attacktracks.StagePrivilegeEscalation, ControlID: "C-0057", Description: "Privileged container", Severity: "Critical"},
+		},
+	}}
+	dot := &bytes.Buffer{}
+	if err := writeDot(dot, tracks); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		`label="Deployment prod/web (score 12)"`,
+		`"0/exposure" [label="exposure\nexposed by the LoadBalancer service \"web\" (High)", fillcolor="#f57c00"]`,
+		`"0/privilege-escalation" [label="privilege-escalation\nC-0057 Privileged container (Critical)", fillcolor="#d32f2f"]`,
+		`"0/exposure" -> "0/privilege-escalation"`,
+	} {
+		if !strings.Contains(dot.String(), expected) {
+			t.Errorf("expected %s in the graph: %s", expected, dot.String())
+		}
+	}
+}
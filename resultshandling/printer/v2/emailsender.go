@@ -0,0 +1,203 @@
+package v2
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/cautils/logger/helpers"
+	"github.com/armosec/opa-utils/reporthandling/apis"
+)
+
+// EmailSender is a printer that emails a summary of the scan together with the output files of the scan
+type EmailSender struct {
+	options     cautils.EmailOptions
+	attachments []string
+}
+
+func NewEmailSender(options cautils.EmailOptions, attachments []string) *EmailSender {
+	return &EmailSender{
+		options:     options,
+		attachments: attachments,
+	}
+}
+
+func (emailSender *EmailSender) SetWriter(outputFile string) {}
+
+func (emailSender *EmailSender) Score(score float32) {}
+
+func (emailSender *EmailSender) ActionPrint(opaSessionObj *cautils.OPASessionObj) error {
+	message, err := emailSender.message(NewTemplateData(opaSessionObj))
+	if err != nil {
+		return fmt.Errorf("failed to create results email: %w", err)
+	}
+	if err := emailSender.send(message); err != nil {
+		return fmt.Errorf("failed to send results email by '%s': %w", emailSender.options.SMTPServer, err)
+	}
+	logger.L().Success("results email sent", helpers.String("to", strings.Join(emailSender.options.To, ",")))
+	return nil
+}
+
+// message returns a multipart message of the text summary and the attachments
+func (emailSender *EmailSender) message(data *TemplateData) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	w := multipart.NewWriter(buf)
+
+	fmt.Fprintf(buf, "From: %s\r\n", emailSender.options.From)
+	fmt.Fprintf(buf, "To: %s\r\n", strings.Join(emailSender.options.To, ", "))
+	fmt.Fprintf(buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", emailSender.options.Subject))
+	fmt.Fprintf(buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(buf, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(buf, "Content-Type: multipart/mixed; boundary=%s\r\n\r\n", w.Boundary())
+
+	part, err := w.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {"text/plain; charset=utf-8"},
+		"Content-Transfer-Encoding": {"base64"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	writeBase64(part, []byte(emailSummary(data)))
+
+	for _, attachment := range emailSender.attachments {
+		content, err := os.ReadFile(attachment)
+		if err != nil {
+			logger.L().Warning("failed to attach file to the results email", helpers.String("file", attachment), helpers.Error(err))
+			continue
+		}
+		contentType := mime.TypeByExtension(filepath.Ext(attachment))
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		part, err := w.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {contentType},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": filepath.Base(attachment)})},
+		})
+		if err != nil {
+			return nil, err
+		}
+		writeBase64(part, content)
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (emailSender *EmailSender) send(message []byte) error {
+	host, _, err := net.SplitHostPort(emailSender.options.SMTPServer)
+	if err != nil {
+		return err
+	}
+	tlsConfig := &tls.Config{ServerName: host}
+
+	var conn net.Conn
+	if strings.EqualFold(emailSender.options.TLS, "tls") {
+		conn, err = tls.DialWithDialer(&net.Dialer{Timeout: 30 * time.Second}, "tcp", emailSender.options.SMTPServer, tlsConfig)
+	} else {
+		conn, err = net.DialTimeout("tcp", emailSender.options.SMTPServer, 30*time.Second)
+	}
+	if err != nil {
+		return err
+	}
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if strings.EqualFold(emailSender.options.TLS, "starttls") {
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return err
+		}
+	}
+	if emailSender.options.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", emailSender.options.Username, emailSender.options.Password, host)); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(emailSender.options.From); err != nil {
+		return err
+	}
+	for _, to := range emailSender.options.To {
+		if err := client.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(message); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// emailSummary the text body of the email - the risk-score and the failed controls, by severity
+func emailSummary(data *TemplateData) string {
+	controls := []TemplateControl{}
+	for _, control := range data.Controls {
+		if control.Status == string(apis.StatusFailed) {
+			controls = append(controls, control)
+		}
+	}
+	severityIndex := func(severity string) int {
+		if i := cautils.StringInSlice(severitiesOrder, severity); i != cautils.ValueNotFound {
+			return i
+		}
+		return len(severitiesOrder)
+	}
+	sort.SliceStable(controls, func(i, j int) bool {
+		return severityIndex(controls[i].Severity) < severityIndex(controls[j].Severity)
+	})
+
+	sb := strings.Builder{}
+	sb.WriteString(notificationTitle(data) + "\n\n")
+	sb.WriteString(notificationSummary(data) + "\n")
+	for _, framework := range data.Frameworks {
+		sb.WriteString(fmt.Sprintf("  %s risk-score: %.2f%%\n", framework.Name, framework.Score))
+	}
+	if len(controls) > 0 {
+		sb.WriteString("\nFailed controls:\n")
+	}
+	for _, control := range controls {
+		sb.WriteString(fmt.Sprintf("  [%s] %s %s - %d failed resources\n      %s\n", control.Severity, control.ID, control.Name, len(control.FailedResources), control.URL))
+	}
+	if len(data.ExpiredExceptions) > 0 {
+		sb.WriteString("\nExpired exceptions:\n")
+	}
+	for i := range data.ExpiredExceptions {
+		sb.WriteString(fmt.Sprintf("  %s\n", data.ExpiredExceptions[i].String()))
+	}
+	return sb.String()
+}
+
+// writeBase64 writes the content base64 encoded, in lines of 76 characters (RFC 2045)
+func writeBase64(w io.Writer, content []byte) {
+	encoded := base64.StdEncoding.EncodeToString(content)
+	for len(encoded) > 76 {
+		w.Write([]byte(encoded[:76] + "\r\n"))
+		encoded = encoded[76:]
+	}
+	w.Write([]byte(encoded + "\r\n"))
+}
@@ -0,0 +1,72 @@
+package v2
+
+import (
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/opa-utils/reporthandling/apis"
+)
+
+func TestEmailSummary(t *testing.T) {
+	data := &TemplateData{
+		Controls: []TemplateControl{
+			{ID: "C-0001", Severity: cautils.SeverityLow, Status: string(apis.StatusFailed)},
+			{ID: "C-0002", Severity: cautils.SeverityCritical, Status: string(apis.StatusFailed)},
+			{ID: "C-0003", Severity: cautils.SeverityCritical, Status: string(apis.StatusPassed)},
+		},
+	}
+	summary := emailSummary(data)
+	if strings.Contains(summary, "C-0003") {
+		t.Errorf("passed controls should not be part of the summary: %s", summary)
+	}
+	if strings.Index(summary, "C-0002") > strings.Index(summary, "C-0001") {
+		t.Errorf("failed controls should be sorted by severity: %s", summary)
+	}
+}
+
+func TestEmailMessage(t *testing.T) {
+	attachment := filepath.Join(t.TempDir(), "report.json")
+	if err := os.WriteFile(attachment, []byte(`{"score": 42}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	emailSender := NewEmailSender(cautils.EmailOptions{From: "kubescape@example.com", To: []string{"a@example.com", "b@example.com"}, Subject: "scan results"}, []string{attachment, "missing.pdf"})
+	message, err := emailSender.message(&TemplateData{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg, err := mail.ReadMessage(strings.NewReader(string(message)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if to := msg.Header.Get("To"); to != "a@example.com, b@example.com" {
+		t.Errorf("unexpected recipients: %s", to)
+	}
+	_, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := multipart.NewReader(msg.Body, params["boundary"])
+	parts := []string{}
+	for {
+		part, err := r.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		parts = append(parts, part.FileName())
+	}
+	// the text summary and the existing attachment
+	if len(parts) != 2 || parts[0] != "" || parts[1] != "report.json" {
+		t.Errorf("unexpected message parts: %v", parts)
+	}
+}
@@ -0,0 +1,52 @@
+package v2
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/resultshandling/printer"
+)
+
+// Encoder writes the results of the session in an output format to any writer, e.g. of a program embedding the scans
+type Encoder func(writer io.Writer, opaSessionObj *cautils.OPASessionObj) error
+
+// encoders the output formats of the printers, which are not bound to an output file nor to the options of the printers.
+// The pdf is encoded with the default options, the pretty-printer and the gotemplate formats are not encoders
+var encoders = map[string]Encoder{
+	printer.JsonFormat:        encodeJson,
+	printer.JunitResultFormat: encodeJunit,
+	printer.PdfFormat:         encodePdf,
+	printer.SARIFFormat:       encodeSarif,
+	printer.CSVFormat:         encodeCsv,
+	printer.XLSXFormat:        encodeXlsx,
+	printer.MarkdownFormat:    encodeMarkdown,
+	printer.HTMLFormat:        GenerateHtml,
+	printer.CodeQualityFormat: encodeCodeQuality,
+	printer.GithubFormat:      encodeGithubAnnotations,
+	printer.OSCALFormat:       encodeOscal,
+	printer.CEFFormat:         encodeCef,
+	printer.NDJSONFormat:      encodeNdjson,
+	printer.DotFormat:         encodeDot,
+}
+
+// GetEncoder returns the encoder of the output format
+func GetEncoder(format string) (Encoder, error) {
+	encoder, ok := encoders[format]
+	if !ok {
+		return nil, fmt.Errorf("bad argument: unsupported format '%s'. Supported: %s", format, strings.Join(EncoderFormats(), ", "))
+	}
+	return encoder, nil
+}
+
+// EncoderFormats returns the output formats of the encoders, sorted
+func EncoderFormats() []string {
+	formats := make([]string, 0, len(encoders))
+	for format := range encoders {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
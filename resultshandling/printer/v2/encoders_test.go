@@ -0,0 +1,69 @@
+package v2
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/armosec/k8s-interface/workloadinterface"
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/opa-utils/reporthandling/apis"
+	"github.com/armosec/opa-utils/reporthandling/results/v1/reportsummary"
+	"github.com/armosec/opa-utils/reporthandling/results/v1/resourcesresults"
+	reporthandlingv2 "github.com/armosec/opa-utils/reporthandling/v2"
+)
+
+func TestEncoders(t *testing.T) {
+	for _, format := range EncoderFormats() {
+		encoder, err := GetEncoder(format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		buf := &bytes.Buffer{}
+		if err := encoder(buf, cautils.NewOPASessionObj(nil, nil)); err != nil {
+			t.Errorf("format %s: %v", format, err)
+		}
+		// an empty scan has no annotations nor events
+		if buf.Len() == 0 && format != "github-annotations" && format != "cef" {
+			t.Errorf("format %s: expected the encoded results", format)
+		}
+	}
+
+	if _, err := GetEncoder("pretty-printer"); err == nil {
+		t.Errorf("expected an error of a format without an encoder")
+	}
+}
+
+func TestEncodersOfResultsFile(t *testing.T) {
+	opaSessionObj := cautils.NewOPASessionObj(nil, nil)
+	opaSessionObj.Report.SummaryDetails.Controls = reportsummary.ControlSummaries{
+		"C-0013": {ControlID: "C-0013", Name: "Non-root containers", Status: apis.StatusFailed},
+	}
+	resourceID := "apps/v1/prod/Deployment/web"
+	opaSessionObj.ResourcesResult[resourceID] = resourcesresults.Result{ResourceID: resourceID, AssociatedControls: []resourcesresults.ResourceAssociatedControl{
+		{ControlID: "C-0013", ResourceAssociatedRules: []resourcesresults.ResourceAssociatedRule{{Name: "non-root-containers", Status: apis.StatusFailed}}},
+	}}
+	opaSessionObj.AllResources[resourceID] = workloadinterface.NewWorkloadObj(map[string]interface{}{
+		"apiVersion": "apps/v1", "kind": "Deployment", "metadata": map[string]interface{}{"name": "web", "namespace": "prod"},
+	})
+	results, err := GenerateJson(opaSessionObj)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the session restored from the results file, as by 'kubescape convert'
+	report := &reporthandlingv2.PostureReport{}
+	if err := json.Unmarshal(results, report); err != nil {
+		t.Fatal(err)
+	}
+	for _, format := range EncoderFormats() {
+		encoder, _ := GetEncoder(format)
+		buf := &bytes.Buffer{}
+		if err := encoder(buf, NewReportSession(report, nil)); err != nil {
+			t.Errorf("format %s: %v", format, err)
+		}
+		if buf.Len() == 0 {
+			t.Errorf("format %s: expected the encoded results", format)
+		}
+	}
+}
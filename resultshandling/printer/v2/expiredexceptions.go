@@ -0,0 +1,61 @@
+package v2
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/exceptionshandler"
+)
+
+// expiredExceptionStatus the status of the rows of the expired exceptions in the tabular formats
+const expiredExceptionStatus = "expired exception"
+
+// ExpiredException an exception which expired and was not applied on the scan results, reported for reviewing the risk acceptance
+type ExpiredException struct {
+	Name           string `json:"name"`
+	Owner          string `json:"owner,omitempty"`
+	Reason         string `json:"reason,omitempty"`
+	ExpirationDate string `json:"expirationDate"`
+}
+
+// listExpiredExceptions returns the expired exceptions of the session, sorted by name
+func listExpiredExceptions(opaSessionObj *cautils.OPASessionObj) []ExpiredException {
+	expiredExceptions := []ExpiredException{}
+	for i := range opaSessionObj.ExpiredExceptions {
+		exception := &opaSessionObj.ExpiredExceptions[i]
+		expiredException := ExpiredException{
+			Name:   exception.Name,
+			Owner:  exceptionshandler.GetOwner(exception),
+			Reason: exceptionshandler.GetReason(exception),
+		}
+		if expiration, err := exceptionshandler.GetExpiration(exception); err == nil && expiration != nil {
+			expiredException.ExpirationDate = expiration.UTC().Format(time.RFC3339)
+		}
+		expiredExceptions = append(expiredExceptions, expiredException)
+	}
+	sort.Slice(expiredExceptions, func(i, j int) bool { return expiredExceptions[i].Name < expiredExceptions[j].Name })
+	return expiredExceptions
+}
+
+// String returns a one line description of the expired exception
+func (expiredException *ExpiredException) String() string {
+	s := fmt.Sprintf("exception '%s' expired at %s", expiredException.Name, expiredException.ExpirationDate)
+	if expiredException.Owner != "" {
+		s += fmt.Sprintf(", owner: %s", expiredException.Owner)
+	}
+	if expiredException.Reason != "" {
+		s += fmt.Sprintf(", reason: %s", expiredException.Reason)
+	}
+	return s
+}
+
+// expiredExceptionRows returns a row per expired exception, in the columns of the control resource rows (getControlResourceHeaders)
+func expiredExceptionRows(opaSessionObj *cautils.OPASessionObj) [][]string {
+	rows := [][]string{}
+	for _, expiredException := range listExpiredExceptions(opaSessionObj) {
+		rows = append(rows, []string{"", "", "", expiredExceptionStatus, "", "PostureExceptionPolicy", expiredException.Name, "", expiredException.String()})
+	}
+	return rows
+}
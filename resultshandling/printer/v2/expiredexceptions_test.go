@@ -0,0 +1,106 @@
+package v2
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/armosec/armoapi-go/armotypes"
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/exceptionshandler"
+)
+
+func mockExpiredExceptionsSession() *cautils.OPASessionObj {
+	opaSessionObj := cautils.NewOPASessionObj(nil, nil)
+	opaSessionObj.ExpiredExceptions = []armotypes.PostureExceptionPolicy{
+		{PortalBase: armotypes.PortalBase{Name: "dev", Attributes: map[string]interface{}{
+			exceptionshandler.ExpirationAttribute: "2022-01-01",
+			exceptionshandler.OwnerAttribute:      "platform-team",
+			exceptionshandler.ReasonAttribute:     "legacy workload",
+		}}},
+		{PortalBase: armotypes.PortalBase{Name: "archived", Attributes: map[string]interface{}{
+			exceptionshandler.ExpirationAttribute: "2021-06-01T12:00:00Z",
+		}}},
+	}
+	return opaSessionObj
+}
+
+func TestListExpiredExceptions(t *testing.T) {
+	expiredExceptions := listExpiredExceptions(mockExpiredExceptionsSession())
+	expected := []ExpiredException{
+		{Name: "archived", ExpirationDate: "2021-06-01T12:00:00Z"},
+		{Name: "dev", Owner: "platform-team", Reason: "legacy workload", ExpirationDate: "2022-01-01T00:00:00Z"},
+	}
+	if len(expiredExceptions) != len(expected) {
+		t.Fatalf("expected %d expired exceptions, got %d", len(expected), len(expiredExceptions))
+	}
+	for i := range expected {
+		if expiredExceptions[i] != expected[i] {
+			t.Errorf("unexpected expired exception %d: %+v, expected %+v", i, expiredExceptions[i], expected[i])
+		}
+	}
+	if s := expiredExceptions[1].String(); s != "exception 'dev' expired at 2022-01-01T00:00:00Z, owner: platform-team, reason: legacy workload" {
+		t.Errorf("unexpected description: %s", s)
+	}
+}
+
+func TestExpiredExceptionsOutput(t *testing.T) {
+	opaSessionObj := mockExpiredExceptionsSession()
+
+	report, err := GenerateJson(opaSessionObj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jsonReport := struct {
+		ExpiredExceptions []ExpiredException `json:"expiredExceptions"`
+	}{}
+	if err := json.Unmarshal(report, &jsonReport); err != nil {
+		t.Fatal(err)
+	}
+	if len(jsonReport.ExpiredExceptions) != 2 {
+		t.Errorf("expected the expired exceptions in the json report, got: %s", string(report))
+	}
+
+	sarifLog := sarifResults(opaSessionObj)
+	if len(sarifLog.Runs[0].Invocations) != 1 || len(sarifLog.Runs[0].Invocations[0].ToolExecutionNotifications) != 2 {
+		t.Errorf("expected a notification per expired exception: %+v", sarifLog.Runs[0].Invocations)
+	}
+
+	annotations := githubAnnotations(opaSessionObj)
+	if len(annotations) != 2 || !strings.HasPrefix(annotations[0], "::warning title=Expired exception archived::") {
+		t.Errorf("unexpected annotations: %v", annotations)
+	}
+
+	events := cefEvents(opaSessionObj)
+	if len(events) != 2 || !strings.Contains(events[1].event, "|expired-exception|Expired exception dev|") || !strings.Contains(events[1].event, "cs1=platform-team") {
+		t.Errorf("unexpected events: %v", events)
+	}
+
+	rows := expiredExceptionRows(opaSessionObj)
+	if len(rows) != 2 || len(rows[1]) != len(getControlResourceHeaders()) || rows[1][3] != expiredExceptionStatus || rows[1][6] != "dev" {
+		t.Errorf("unexpected csv rows: %v", rows)
+	}
+
+	oscal := oscalDocument(opaSessionObj)
+	if observations := oscal.AssessmentResults.Results[0].Observations; len(observations) != 2 || observations[1].Title != "Expired exception dev" {
+		t.Errorf("unexpected oscal observations: %+v", observations)
+	}
+
+	data := NewTemplateData(opaSessionObj)
+	if summary := emailSummary(data); !strings.Contains(summary, "Expired exceptions:\n  exception 'archived' expired at 2021-06-01T12:00:00Z\n") {
+		t.Errorf("expected the expired exceptions in the email: %s", summary)
+	}
+	slack, _ := json.Marshal(slackMessage(data, ""))
+	teams, _ := json.Marshal(teamsMessage(data, ""))
+	for _, message := range []string{string(slack), string(teams)} {
+		if !strings.Contains(message, "Expired exceptions") || !strings.Contains(message, "exception 'dev' expired at 2022-01-01T00:00:00Z") {
+			t.Errorf("expected the expired exceptions in the notification: %s", message)
+		}
+	}
+
+	// no expired exceptions - the json report is not changed
+	opaSessionObj.ExpiredExceptions = nil
+	if report, _ := GenerateJson(opaSessionObj); strings.Contains(string(report), "expiredExceptions") {
+		t.Errorf("unexpected expired exceptions in the json report: %s", string(report))
+	}
+}
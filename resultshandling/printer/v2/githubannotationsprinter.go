@@ -0,0 +1,112 @@
+package v2
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/resultshandling/printer"
+	"github.com/armosec/opa-utils/reporthandling/results/v1/reportsummary"
+)
+
+// https://docs.github.com/en/actions/using-workflows/workflow-commands-for-github-actions#setting-an-error-message
+
+type GithubAnnotationsPrinter struct {
+	writer *os.File
+}
+
+func NewGithubAnnotationsPrinter() *GithubAnnotationsPrinter {
+	return &GithubAnnotationsPrinter{}
+}
+
+func (githubAnnotationsPrinter *GithubAnnotationsPrinter) SetWriter(outputFile string) {
+	githubAnnotationsPrinter.writer = printer.GetWriter(outputFile)
+}
+
+func (githubAnnotationsPrinter *GithubAnnotationsPrinter) Score(score float32) {
+	printer.LogScore(score)
+}
+
+func (githubAnnotationsPrinter *GithubAnnotationsPrinter) ActionPrint(opaSessionObj *cautils.OPASessionObj) error {
+	return encodeGithubAnnotations(githubAnnotationsPrinter.writer, opaSessionObj)
+}
+
+func encodeGithubAnnotations(writer io.Writer, opaSessionObj *cautils.OPASessionObj) error {
+	for _, annotation := range githubAnnotations(opaSessionObj) {
+		if _, err := fmt.Fprintln(writer, annotation); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// githubAnnotations returns a workflow command per failed resource, the file and line are set when the resource was loaded from a file
+func githubAnnotations(opaSessionObj *cautils.OPASessionObj) []string {
+	annotations := []string{}
+
+	controls := opaSessionObj.Report.SummaryDetails.Controls
+	controlIDs := controls.ListControlsIDs().Failed()
+	sort.Strings(controlIDs)
+
+	for _, controlID := range controlIDs {
+		control := controls.GetControl(reportsummary.EControlCriteriaID, controlID)
+		if control == nil {
+			continue
+		}
+		command := severityToGithubCommand(cautils.ControlSeverityToString(control.GetScoreFactor()))
+		resourceIDs := control.ListResourcesIDs().Failed()
+		sort.Strings(resourceIDs)
+		for _, resourceID := range resourceIDs {
+			resource, ok := opaSessionObj.AllResources[resourceID]
+			if !ok {
+				continue
+			}
+			properties := []string{}
+			if source, ok := opaSessionObj.ResourceSource[resourceID]; ok {
+				properties = append(properties, "file="+escapeGithubProperty(relativeSourcePath(source.Path)))
+				if source.Line > 0 {
+					properties = append(properties, fmt.Sprintf("line=%d", source.Line))
+				}
+			}
+			properties = append(properties, "title="+escapeGithubProperty(fmt.Sprintf("%s %s", control.GetID(), control.GetName())))
+
+			message := fmt.Sprintf("%s failed control '%s'\nRemediation: %s\nMore details: %s", resourceFullName(resource), control.GetName(), control.GetRemediation(), getControlURL(control.GetID()))
+			annotations = append(annotations, fmt.Sprintf("::%s %s::%s", command, strings.Join(properties, ","), escapeGithubData(message)))
+		}
+	}
+
+	expiredExceptions := listExpiredExceptions(opaSessionObj)
+	for i := range expiredExceptions {
+		title := "title=" + escapeGithubProperty("Expired exception "+expiredExceptions[i].Name)
+		message := fmt.Sprintf("The %s and is not applied, review the risk acceptance", expiredExceptions[i].String())
+		annotations = append(annotations, fmt.Sprintf("::warning %s::%s", title, escapeGithubData(message)))
+	}
+	return annotations
+}
+
+func severityToGithubCommand(severity string) string {
+	switch severity {
+	case cautils.SeverityCritical, cautils.SeverityHigh:
+		return "error"
+	case cautils.SeverityMedium:
+		return "warning"
+	default:
+		return "notice"
+	}
+}
+
+var (
+	githubDataReplacer     = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+	githubPropertyReplacer = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+)
+
+func escapeGithubData(s string) string {
+	return githubDataReplacer.Replace(s)
+}
+
+func escapeGithubProperty(s string) string {
+	return githubPropertyReplacer.Replace(s)
+}
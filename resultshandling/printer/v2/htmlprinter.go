@@ -0,0 +1,98 @@
+package v2
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/armosec/k8s-interface/workloadinterface"
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/resultshandling/printer"
+	"github.com/armosec/opa-utils/reporthandling/results/v1/reportsummary"
+	"github.com/armosec/opa-utils/reporthandling/results/v1/resourcesresults"
+	reporthandlingv2 "github.com/armosec/opa-utils/reporthandling/v2"
+)
+
+var (
+	//go:embed templates/html.tmpl
+	htmlTemplate string
+)
+
+type HtmlPrinter struct {
+	writer *os.File
+}
+
+func NewHtmlPrinter() *HtmlPrinter {
+	return &HtmlPrinter{}
+}
+
+func (htmlPrinter *HtmlPrinter) SetWriter(outputFile string) {
+	htmlPrinter.writer = printer.GetWriter(outputFile)
+}
+
+func (htmlPrinter *HtmlPrinter) Score(score float32) {
+	printer.LogScore(score)
+}
+
+func (htmlPrinter *HtmlPrinter) ActionPrint(opaSessionObj *cautils.OPASessionObj) error {
+	if err := GenerateHtml(htmlPrinter.writer, opaSessionObj); err != nil {
+		return fmt.Errorf("failed to generate html results: %w", err)
+	}
+
+	logOUtputFile(htmlPrinter.writer.Name())
+	return nil
+}
+
+// GenerateHtml writes the results of the session as a standalone html report
+func GenerateHtml(writer io.Writer, opaSessionObj *cautils.OPASessionObj) error {
+	tmpl, err := template.New("html").Funcs(template.FuncMap{"lower": strings.ToLower}).Parse(htmlTemplate)
+	if err != nil {
+		return err
+	}
+	return tmpl.Execute(writer, NewTemplateData(opaSessionObj))
+}
+
+// NewReportSession returns the session of a results file (generated by --format json --format-version v2), e.g. for rendering it again as an html report
+func NewReportSession(report *reporthandlingv2.PostureReport, sources map[string]cautils.ResourceSource) *cautils.OPASessionObj {
+	opaSessionObj := cautils.NewOPASessionObj(nil, nil)
+	opaSessionObj.Report = report
+	for i := range report.Results {
+		opaSessionObj.ResourcesResult[report.Results[i].ResourceID] = report.Results[i]
+	}
+	for i := range report.Resources {
+		if object, ok := report.Resources[i].Object.(map[string]interface{}); ok {
+			opaSessionObj.AllResources[report.Resources[i].ResourceID] = workloadinterface.NewWorkloadObj(object)
+		}
+	}
+	if sources != nil {
+		opaSessionObj.ResourceSource = sources
+	}
+
+	// the resources of the controls are listed by the results, the summary of the results files does not list them
+	restoreResourceIDs(report.SummaryDetails.Controls, report.Results)
+	for i := range report.SummaryDetails.Frameworks {
+		restoreResourceIDs(report.SummaryDetails.Frameworks[i].Controls, report.Results)
+	}
+	return opaSessionObj
+}
+
+func restoreResourceIDs(controls reportsummary.ControlSummaries, results []resourcesresults.Result) {
+	restored := map[string]bool{}
+	for controlID, control := range controls {
+		restored[controlID] = len(control.ResourceIDs.All()) == 0
+	}
+	for i := range results {
+		for j := range results[i].AssociatedControls {
+			associatedControl := &results[i].AssociatedControls[j]
+			control, ok := controls[associatedControl.GetID()]
+			if !ok || !restored[associatedControl.GetID()] {
+				continue
+			}
+			control.ResourceIDs.Append(associatedControl.GetStatus(nil).Status(), results[i].ResourceID)
+			controls[associatedControl.GetID()] = control
+		}
+	}
+}
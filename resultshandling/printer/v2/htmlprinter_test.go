@@ -0,0 +1,57 @@
+package v2
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/opa-utils/reporthandling"
+	"github.com/armosec/opa-utils/reporthandling/apis"
+	"github.com/armosec/opa-utils/reporthandling/results/v1/reportsummary"
+	"github.com/armosec/opa-utils/reporthandling/results/v1/resourcesresults"
+	reporthandlingv2 "github.com/armosec/opa-utils/reporthandling/v2"
+)
+
+func TestNewReportSession(t *testing.T) {
+	resourceID := "apps/v1/prod/Deployment/web"
+	// the summary of a results file does not list the resources of the controls
+	controls := reportsummary.ControlSummaries{
+		"C-0013": {ControlID: "C-0013", Name: "Non-root containers", Status: apis.StatusFailed},
+	}
+	report := &reporthandlingv2.PostureReport{
+		SummaryDetails: reportsummary.SummaryDetails{
+			Frameworks: []reportsummary.FrameworkSummary{{Name: "NSA", Controls: controls}},
+			Controls:   controls,
+		},
+		Results: []resourcesresults.Result{{ResourceID: resourceID, AssociatedControls: []resourcesresults.ResourceAssociatedControl{
+			{ControlID: "C-0013", ResourceAssociatedRules: []resourcesresults.ResourceAssociatedRule{{Name: "non-root-containers", Status: apis.StatusFailed}}},
+		}}},
+		Resources: []reporthandling.Resource{{ResourceID: resourceID, Object: map[string]interface{}{
+			"apiVersion": "apps/v1", "kind": "Deployment", "metadata": map[string]interface{}{"name": "web", "namespace": "prod"},
+		}}},
+	}
+
+	opaSessionObj := NewReportSession(report, map[string]cautils.ResourceSource{resourceID: {Path: "web.yaml", Line: 1}})
+	if _, ok := opaSessionObj.AllResources[resourceID]; !ok {
+		t.Errorf("expected the resources of the results file")
+	}
+	control := opaSessionObj.Report.SummaryDetails.Controls["C-0013"]
+	if failed := control.ResourceIDs.Failed(); len(failed) != 1 || failed[0] != resourceID {
+		t.Errorf("expected the failed resources of the control, received %v", failed)
+	}
+	control = opaSessionObj.Report.SummaryDetails.Frameworks[0].Controls["C-0013"]
+	if failed := control.ResourceIDs.Failed(); len(failed) != 1 {
+		t.Errorf("expected the failed resources of the framework control once, received %v", failed)
+	}
+
+	html := &bytes.Buffer{}
+	if err := GenerateHtml(html, opaSessionObj); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{`id="search"`, `data-status="failed"`, `data-resource="apps/v1/prod/deployment/web web.yaml"`} {
+		if !strings.Contains(html.String(), expected) {
+			t.Errorf("expected %s in the html report: %s", expected, html.String())
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package v2
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/registryadaptors/imagesignatures"
+	"github.com/armosec/kubescape/resourcehandler"
+)
+
+// ImageSignature the verification of the signatures of an image of the scanned workloads
+type ImageSignature struct {
+	imagesignatures.ImageSignature
+	Workloads []string `json:"workloads"` // IDs of the workloads running the image
+}
+
+// listImagesSignatures returns the verifications of the scanned images, the unverified images first
+func listImagesSignatures(opaSessionObj *cautils.OPASessionObj) []ImageSignature {
+	images := []ImageSignature{}
+	for _, resource := range opaSessionObj.AllResources {
+		if resource.GetKind() != resourcehandler.ImageSignatureObjectKind {
+			continue
+		}
+		image := ImageSignature{Workloads: []string{}}
+		if data, ok := resource.GetObject()["data"]; ok {
+			image.ImageSignature = toImageSignature(data)
+		}
+		image.Image = resource.GetName()
+		images = append(images, image)
+	}
+	if len(images) == 0 {
+		return images
+	}
+
+	workloads := cautils.ImagesWorkloads(opaSessionObj.AllResources)
+	for i := range images {
+		if w, ok := workloads[images[i].Image]; ok {
+			images[i].Workloads = w
+		}
+	}
+	sort.Slice(images, func(i, j int) bool {
+		if images[i].Verified != images[j].Verified {
+			return !images[i].Verified
+		}
+		return images[i].Image < images[j].Image
+	})
+	return images
+}
+
+// toImageSignature converts the data of the ImageSignature objects, set by the verifier or loaded from a file
+func toImageSignature(data interface{}) imagesignatures.ImageSignature {
+	if signature, ok := data.(imagesignatures.ImageSignature); ok {
+		return signature
+	}
+	signature := imagesignatures.ImageSignature{}
+	if b, err := json.Marshal(data); err == nil {
+		json.Unmarshal(b, &signature)
+	}
+	return signature
+}
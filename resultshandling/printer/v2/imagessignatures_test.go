@@ -0,0 +1,48 @@
+package v2
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/armosec/k8s-interface/workloadinterface"
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/registryadaptors/imagesignatures"
+	"github.com/armosec/kubescape/resourcehandler"
+)
+
+func mockImageSignature(image string, data interface{}) workloadinterface.IMetadata {
+	return workloadinterface.NewWorkloadObj(map[string]interface{}{
+		"apiVersion": "image.signatures.com/v1",
+		"kind":       resourcehandler.ImageSignatureObjectKind,
+		"metadata":   map[string]interface{}{"name": image},
+		"data":       data,
+	})
+}
+
+func TestListImagesSignatures(t *testing.T) {
+	opaSessionObj := cautils.NewOPASessionObj(nil, nil)
+	opaSessionObj.AllResources = map[string]workloadinterface.IMetadata{
+		"nginx":  mockImageSignature("nginx:1.14", imagesignatures.ImageSignature{Image: "nginx:1.14", Signed: true, Verified: true, Signers: []string{"cosign.pub"}}),
+		"redis":  mockImageSignature("redis:6", imagesignatures.ImageSignature{Image: "redis:6", Error: "no signatures found"}),
+		"alpine": mockImageSignature("alpine:3", map[string]interface{}{"image": "alpine:3", "signed": true, "verified": false}), // loaded from a file
+		"web":    mockDeployment("web", "nginx:1.14", "redis:6"),
+	}
+
+	images := listImagesSignatures(opaSessionObj)
+	if len(images) != 3 {
+		t.Fatalf("expected 3 images, got %d", len(images))
+	}
+	order := []string{images[0].Image, images[1].Image, images[2].Image}
+	if !reflect.DeepEqual(order, []string{"alpine:3", "redis:6", "nginx:1.14"}) {
+		t.Errorf("unexpected order of the images: %v", order)
+	}
+	if !images[0].Signed || images[0].Verified {
+		t.Errorf("unexpected alpine: %+v", images[0])
+	}
+	if !reflect.DeepEqual(images[1].Workloads, []string{"web"}) || images[1].Error != "no signatures found" {
+		t.Errorf("unexpected redis: %+v", images[1])
+	}
+	if !images[2].Verified || !reflect.DeepEqual(images[2].Signers, []string{"cosign.pub"}) {
+		t.Errorf("unexpected nginx: %+v", images[2])
+	}
+}
@@ -0,0 +1,75 @@
+package v2
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/registryadaptors/registryvulnerabilities"
+	"github.com/armosec/kubescape/resourcehandler"
+)
+
+// vulnerabilitySeverities the severities of the vulnerabilities, from the most severe
+var vulnerabilitySeverities = []string{"Critical", "High", "Medium", "Low", "Negligible", "Unknown"}
+
+// ImageVulnerabilities the vulnerabilities of an image of the scanned workloads
+type ImageVulnerabilities struct {
+	Image           string                                  `json:"image"`
+	Workloads       []string                                `json:"workloads"`  // IDs of the workloads running the image
+	Severities      map[string]int                          `json:"severities"` // number of the vulnerabilities by severity
+	Vulnerabilities []registryvulnerabilities.Vulnerability `json:"vulnerabilities"`
+}
+
+// SeverityCount returns the number of the vulnerabilities of the severity
+func (image *ImageVulnerabilities) SeverityCount(severity string) int {
+	return image.Severities[severity]
+}
+
+// listImagesVulnerabilities returns the vulnerabilities of the scanned images, the images with the most severe vulnerabilities first
+func listImagesVulnerabilities(opaSessionObj *cautils.OPASessionObj) []ImageVulnerabilities {
+	images := []ImageVulnerabilities{}
+	for _, resource := range opaSessionObj.AllResources {
+		if resource.GetKind() != resourcehandler.ImagevulnerabilitiesObjectKind {
+			continue
+		}
+		image := ImageVulnerabilities{Image: resource.GetName(), Workloads: []string{}, Severities: map[string]int{}}
+		if data, ok := resource.GetObject()["data"]; ok {
+			image.Vulnerabilities = toVulnerabilities(data)
+		}
+		for i := range image.Vulnerabilities {
+			image.Severities[image.Vulnerabilities[i].Severity]++
+		}
+		images = append(images, image)
+	}
+	if len(images) == 0 {
+		return images
+	}
+
+	workloads := cautils.ImagesWorkloads(opaSessionObj.AllResources)
+	for i := range images {
+		if w, ok := workloads[images[i].Image]; ok {
+			images[i].Workloads = w
+		}
+	}
+	sort.Slice(images, func(i, j int) bool {
+		for _, severity := range vulnerabilitySeverities {
+			if images[i].Severities[severity] != images[j].Severities[severity] {
+				return images[i].Severities[severity] > images[j].Severities[severity]
+			}
+		}
+		return images[i].Image < images[j].Image
+	})
+	return images
+}
+
+// toVulnerabilities converts the data of the ImageVulnerabilities objects, set by the adaptors or loaded from a file
+func toVulnerabilities(data interface{}) []registryvulnerabilities.Vulnerability {
+	if vulnerabilities, ok := data.([]registryvulnerabilities.Vulnerability); ok {
+		return vulnerabilities
+	}
+	vulnerabilities := []registryvulnerabilities.Vulnerability{}
+	if b, err := json.Marshal(data); err == nil {
+		json.Unmarshal(b, &vulnerabilities)
+	}
+	return vulnerabilities
+}
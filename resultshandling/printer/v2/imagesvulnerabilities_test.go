@@ -0,0 +1,68 @@
+package v2
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/armosec/k8s-interface/workloadinterface"
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/registryadaptors/registryvulnerabilities"
+	"github.com/armosec/kubescape/resourcehandler"
+)
+
+func mockImageVulnerabilities(image string, data interface{}) workloadinterface.IMetadata {
+	return workloadinterface.NewWorkloadObj(map[string]interface{}{
+		"apiVersion": "armo.vuln.images/v1",
+		"kind":       resourcehandler.ImagevulnerabilitiesObjectKind,
+		"metadata":   map[string]interface{}{"name": image},
+		"data":       data,
+	})
+}
+
+func mockDeployment(name string, images ...string) workloadinterface.IMetadata {
+	containers := []interface{}{}
+	for _, image := range images {
+		containers = append(containers, map[string]interface{}{"name": image, "image": image})
+	}
+	return workloadinterface.NewWorkloadObj(map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]interface{}{"name": name, "namespace": "default"},
+		"spec": map[string]interface{}{"template": map[string]interface{}{"spec": map[string]interface{}{
+			"containers": containers,
+		}}},
+	})
+}
+
+func TestListImagesVulnerabilities(t *testing.T) {
+	opaSessionObj := cautils.NewOPASessionObj(nil, nil)
+	opaSessionObj.AllResources = map[string]workloadinterface.IMetadata{
+		"nginx":  mockImageVulnerabilities("nginx:1.14", []registryvulnerabilities.Vulnerability{{Name: "CVE-1", Severity: "High"}, {Name: "CVE-2", Severity: "Low"}}),
+		"redis":  mockImageVulnerabilities("redis:6", []registryvulnerabilities.Vulnerability{{Name: "CVE-3", Severity: "Critical"}}),
+		"alpine": mockImageVulnerabilities("alpine:3", []interface{}{map[string]interface{}{"name": "CVE-4", "severity": "High"}}), // loaded from a file
+		"web":    mockDeployment("web", "nginx:1.14", "redis:6"),
+		"cache":  mockDeployment("cache", "redis:6"),
+	}
+
+	images := listImagesVulnerabilities(opaSessionObj)
+	if len(images) != 3 {
+		t.Fatalf("expected 3 images, got %d", len(images))
+	}
+	order := []string{images[0].Image, images[1].Image, images[2].Image}
+	if !reflect.DeepEqual(order, []string{"redis:6", "nginx:1.14", "alpine:3"}) {
+		t.Errorf("unexpected order of the images: %v", order)
+	}
+	if !reflect.DeepEqual(images[0].Workloads, []string{"cache", "web"}) {
+		t.Errorf("unexpected workloads of redis: %v", images[0].Workloads)
+	}
+	if images[1].SeverityCount("High") != 1 || images[1].SeverityCount("Low") != 1 || images[1].SeverityCount("Critical") != 0 {
+		t.Errorf("unexpected severities of nginx: %v", images[1].Severities)
+	}
+	if len(images[2].Workloads) != 0 || len(images[2].Vulnerabilities) != 1 || images[2].Vulnerabilities[0].Name != "CVE-4" {
+		t.Errorf("unexpected alpine: %+v", images[2])
+	}
+
+	if images := listImagesVulnerabilities(cautils.NewOPASessionObj(nil, nil)); len(images) != 0 {
+		t.Errorf("expected no images, got %d", len(images))
+	}
+}
@@ -3,13 +3,97 @@ package v2
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"sort"
 
+	"github.com/armosec/kubescape/attacktracks"
 	"github.com/armosec/kubescape/cautils"
-	"github.com/armosec/kubescape/cautils/logger"
 	"github.com/armosec/kubescape/resultshandling/printer"
+	"github.com/armosec/opa-utils/reporthandling/apis"
+	"github.com/armosec/opa-utils/reporthandling/results/v1/reportsummary"
+	reporthandlingv2 "github.com/armosec/opa-utils/reporthandling/v2"
 )
 
+// jsonReport the report with the expired exceptions of the scan, which were not applied on the results, the remediations
+// of the failed controls, the source files of the resources loaded from files, for fixing the files (kubescape fix), and the
+// vulnerabilities (--enable-image-scan), the signatures (--verify-image-signatures) and the SBOMs (--sbom) of the images of the workloads,
+// the attack tracks of the workloads, the risk-scores by namespace and by owner, the resources attributed to their controllers, and
+// the timestamps of the scan, the resources of the controls by status (--include-passed), and the resources which could not be fetched
+type jsonReport struct {
+	*reporthandlingv2.PostureReport
+	ScanMetadata          *ScanMetadata                     `json:"scanMetadata,omitempty"`
+	ExpiredExceptions     []ExpiredException                `json:"expiredExceptions,omitempty"`
+	Remediations          []Remediation                     `json:"remediations,omitempty"`
+	ResourcesSource       map[string]cautils.ResourceSource `json:"resourcesSource,omitempty"` // map[<resource ID>]<resource source>
+	ImagesVulnerabilities []ImageVulnerabilities            `json:"imagesVulnerabilities,omitempty"`
+	ImagesSignatures      []ImageSignature                  `json:"imagesSignatures,omitempty"`
+	SBOMs                 []cautils.SBOMReference           `json:"sboms,omitempty"`
+	AttackTracks          []attacktracks.AttackTrack        `json:"attackTracks,omitempty"`
+	ScoresBreakdown       *cautils.ScoresBreakdown          `json:"scoresBreakdown,omitempty"`
+	OwnedResources        map[string][]string               `json:"ownedResources,omitempty"` // map[<controller ID>][]<resource ID>
+	ControlsResources     []ControlResources                `json:"controlsResources,omitempty"`
+	DataGaps              []DataGap                         `json:"dataGaps,omitempty"`
+}
+
+// ScanMetadata the start and the end of the scan, RFC3339 timestamps in the time zone of the reports (--timezone)
+type ScanMetadata struct {
+	StartTime string `json:"startTime"`
+	EndTime   string `json:"endTime"`
+	Timezone  string `json:"timezone"`
+}
+
+// scanMetadata returns the timestamps of the scan, nil for the results loaded from a file
+func scanMetadata(opaSessionObj *cautils.OPASessionObj) *ScanMetadata {
+	times := &opaSessionObj.ScanTimes
+	if times.Start.IsZero() {
+		return nil
+	}
+	return &ScanMetadata{StartTime: times.Format(times.Start), EndTime: times.Format(times.End), Timezone: times.In(times.Start).Location().String()}
+}
+
+// ControlResources the resources checked by a control by their status, the passed resources are the evidence of the passed checks
+type ControlResources struct {
+	ControlID string   `json:"controlID"`
+	Name      string   `json:"name"`
+	Status    string   `json:"status"`
+	Failed    []string `json:"failed,omitempty"`
+	Excluded  []string `json:"excluded,omitempty"`
+	Passed    []string `json:"passed,omitempty"`
+	Skipped   []string `json:"skipped,omitempty"` // including the resources irrelevant to the control
+}
+
+// listControlsResources returns the sorted resources of the controls by status, sorted by control ID
+func listControlsResources(opaSessionObj *cautils.OPASessionObj) []ControlResources {
+	controls := &opaSessionObj.Report.SummaryDetails.Controls
+	controlIDs := controls.ListControlsIDs().All()
+	sort.Strings(controlIDs)
+
+	controlsResources := []ControlResources{}
+	for _, controlID := range controlIDs {
+		control := controls.GetControl(reportsummary.EControlCriteriaID, controlID)
+		if control == nil {
+			continue
+		}
+		resourcesIDs := control.ListResourcesIDs()
+		sorted := func(status apis.ScanningStatus) []string {
+			ids := append([]string{}, resourceIDsByStatus(resourcesIDs, status)...)
+			sort.Strings(ids)
+			return ids
+		}
+		controlsResources = append(controlsResources, ControlResources{
+			ControlID: controlID,
+			Name:      control.GetName(),
+			Status:    string(control.GetStatus().Status()),
+			Failed:    sorted(apis.StatusFailed),
+			Excluded:  sorted(apis.StatusExcluded),
+			Passed:    sorted(apis.StatusPassed),
+			Skipped:   sorted(apis.StatusSkipped),
+		})
+	}
+	return controlsResources
+}
+
 type JsonPrinter struct {
 	writer *os.File
 }
@@ -23,14 +107,47 @@ func (jsonPrinter *JsonPrinter) SetWriter(outputFile string) {
 }
 
 func (jsonPrinter *JsonPrinter) Score(score float32) {
-	fmt.Fprintf(os.Stderr, "\nOverall risk-score (0- Excellent, 100- All failed): %d\n", int(score))
+	printer.LogScore(score)
 }
 
-func (jsonPrinter *JsonPrinter) ActionPrint(opaSessionObj *cautils.OPASessionObj) {
-	finalizeJson(opaSessionObj)
-	r, err := json.Marshal(opaSessionObj.Report)
+func (jsonPrinter *JsonPrinter) ActionPrint(opaSessionObj *cautils.OPASessionObj) error {
+	return encodeJson(jsonPrinter.writer, opaSessionObj)
+}
+
+func encodeJson(writer io.Writer, opaSessionObj *cautils.OPASessionObj) error {
+	r, err := GenerateJson(opaSessionObj)
 	if err != nil {
-		logger.L().Fatal("failed to Marshal posture report object")
+		return fmt.Errorf("failed to Marshal posture report object: %w", err)
+	}
+	_, err = writer.Write(r)
+	return err
+}
+
+// GenerateJson returns the results of the session in the json format
+func GenerateJson(opaSessionObj *cautils.OPASessionObj) ([]byte, error) {
+	finalizeJson(opaSessionObj)
+	report := &jsonReport{PostureReport: opaSessionObj.Report, ScanMetadata: scanMetadata(opaSessionObj), Remediations: listRemediations(opaSessionObj), ResourcesSource: opaSessionObj.ResourceSource, ScoresBreakdown: opaSessionObj.ScoresBreakdown}
+	if len(opaSessionObj.ExpiredExceptions) > 0 {
+		report.ExpiredExceptions = listExpiredExceptions(opaSessionObj)
+	}
+	if images := listImagesVulnerabilities(opaSessionObj); len(images) > 0 {
+		report.ImagesVulnerabilities = images
+	}
+	if images := listImagesSignatures(opaSessionObj); len(images) > 0 {
+		report.ImagesSignatures = images
+	}
+	report.SBOMs = opaSessionObj.SBOMs
+	if len(opaSessionObj.DataGaps) > 0 {
+		report.DataGaps = listDataGaps(opaSessionObj)
+	}
+	if opaSessionObj.IncludePassed {
+		report.ControlsResources = listControlsResources(opaSessionObj)
+	}
+	if len(opaSessionObj.OwnedResources) > 0 {
+		report.OwnedResources = opaSessionObj.OwnedResources
+	}
+	if tracks := attacktracks.ListAttackTracks(opaSessionObj); len(tracks) > 0 {
+		report.AttackTracks = tracks
 	}
-	jsonPrinter.writer.Write(r)
+	return json.Marshal(report)
 }
@@ -0,0 +1,94 @@
+package v2
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/opa-utils/reporthandling/apis"
+	"github.com/armosec/opa-utils/reporthandling/results/v1/reportsummary"
+)
+
+func TestGenerateJsonScanMetadata(t *testing.T) {
+	opaSessionObj := cautils.NewOPASessionObj(nil, nil)
+	start := time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC)
+	opaSessionObj.ScanTimes = cautils.ScanTimes{Start: start, End: start.Add(90 * time.Second), Location: time.FixedZone("CET", 3600)}
+
+	b, err := GenerateJson(opaSessionObj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := struct {
+		ScanMetadata *ScanMetadata `json:"scanMetadata"`
+	}{}
+	if err := json.Unmarshal(b, &report); err != nil {
+		t.Fatal(err)
+	}
+	expected := ScanMetadata{StartTime: "2022-03-01T11:00:00+01:00", EndTime: "2022-03-01T11:01:30+01:00", Timezone: "CET"}
+	if report.ScanMetadata == nil || *report.ScanMetadata != expected {
+		t.Errorf("expected the scan metadata %v, received %v", expected, report.ScanMetadata)
+	}
+	if duration := junitTime(opaSessionObj); duration != "90.000" {
+		t.Errorf("expected a junit time of 90 seconds, received %s", duration)
+	}
+
+	// the results loaded from a file have no scan metadata
+	if scanMetadata(cautils.NewOPASessionObj(nil, nil)) != nil {
+		t.Errorf("expected no scan metadata without the start of the scan")
+	}
+}
+
+func TestListControlsResources(t *testing.T) {
+	opaSessionObj := cautils.NewOPASessionObj(nil, nil)
+	control := reportsummary.ControlSummary{ControlID: "C-0013", Name: "Non-root containers", Status: apis.StatusFailed}
+	control.ResourceIDs.Append(apis.StatusFailed, "apps/v1/prod/Deployment/web")
+	control.ResourceIDs.Append(apis.StatusPassed, "apps/v1/prod/Deployment/db", "apps/v1/prod/Deployment/api")
+	control.ResourceIDs.Append(apis.StatusSkipped, "/v1/prod/Service/web")
+	control.ResourceIDs.Append(apis.StatusIgnored, "/v1/prod/ConfigMap/web")
+	opaSessionObj.Report.SummaryDetails.Controls = reportsummary.ControlSummaries{
+		"C-0016": {ControlID: "C-0016", Name: "Allow privilege escalation", Status: apis.StatusPassed},
+		"C-0013": control,
+	}
+
+	controlsResources := listControlsResources(opaSessionObj)
+	if len(controlsResources) != 2 || controlsResources[0].ControlID != "C-0013" || controlsResources[1].ControlID != "C-0016" {
+		t.Fatalf("expected the resources of C-0013 and C-0016, received %v", controlsResources)
+	}
+	resources := controlsResources[0]
+	if resources.Status != string(apis.StatusFailed) || len(resources.Failed) != 1 {
+		t.Errorf("expected a failed control with one failed resource, received %v", resources)
+	}
+	if len(resources.Passed) != 2 || resources.Passed[0] != "apps/v1/prod/Deployment/api" {
+		t.Errorf("expected the sorted passed resources, received %v", resources.Passed)
+	}
+	// the ignored resources are listed as skipped
+	if len(resources.Skipped) != 2 {
+		t.Errorf("expected 2 skipped resources, received %v", resources.Skipped)
+	}
+
+	// the resources of the controls are listed with --include-passed only
+	b, err := GenerateJson(opaSessionObj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := struct {
+		ControlsResources []ControlResources `json:"controlsResources"`
+	}{}
+	if err := json.Unmarshal(b, &report); err != nil {
+		t.Fatal(err)
+	}
+	if len(report.ControlsResources) != 0 {
+		t.Errorf("expected no resources of the controls without --include-passed, received %v", report.ControlsResources)
+	}
+	opaSessionObj.IncludePassed = true
+	if b, err = GenerateJson(opaSessionObj); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(b, &report); err != nil {
+		t.Fatal(err)
+	}
+	if len(report.ControlsResources) != 2 {
+		t.Errorf("expected the resources of 2 controls, received %v", report.ControlsResources)
+	}
+}
@@ -3,14 +3,13 @@ package v2
 import (
 	"encoding/xml"
 	"fmt"
+	"io"
 	"os"
 	"sort"
 	"strings"
 
 	"github.com/armosec/k8s-interface/workloadinterface"
 	"github.com/armosec/kubescape/cautils"
-	"github.com/armosec/kubescape/cautils/logger"
-	"github.com/armosec/kubescape/cautils/logger/helpers"
 	"github.com/armosec/kubescape/resultshandling/printer"
 	"github.com/armosec/opa-utils/reporthandling/results/v1/reportsummary"
 	"github.com/armosec/opa-utils/shared"
@@ -53,7 +52,8 @@ type JUnitTestSuite struct {
 	Failures   int             `xml:"failures,attr"`  // The total number of tests in the suite that failed
 	Hostname   string          `xml:"hostname,attr"`  // Host on which the tests were executed ? cluster name ?
 	ID         int             `xml:"id,attr"`        // Starts at 0 for the first testsuite and is incremented by 1 for each following testsuite
-	Skipped    string          `xml:"skipped,attr"`   // The total number of skipped tests
+	Skipped    int             `xml:"skipped,attr"`   // The total number of skipped tests
+	Tests      int             `xml:"tests,attr"`     // The total number of tests in the suite
 	Time       string          `xml:"time,attr"`      // Time taken (in seconds) to execute the tests in the suite
 	Timestamp  string          `xml:"timestamp,attr"` // when the test was executed in ISO 8601 format (2014-01-21T16:17:18)
 	Properties []JUnitProperty `xml:"properties>property,omitempty"`
@@ -100,29 +100,49 @@ func (junitPrinter *JunitPrinter) SetWriter(outputFile string) {
 }
 
 func (junitPrinter *JunitPrinter) Score(score float32) {
-	fmt.Fprintf(os.Stderr, "\nOverall risk-score (0- Excellent, 100- All failed): %d\n", int(score))
+	printer.LogScore(score)
 }
 
-func (junitPrinter *JunitPrinter) ActionPrint(opaSessionObj *cautils.OPASessionObj) {
-	junitResult := testsSuites(opaSessionObj)
-	postureReportStr, err := xml.Marshal(junitResult)
-	if err != nil {
-		logger.L().Fatal("failed to Marshal xml result object", helpers.Error(err))
+func (junitPrinter *JunitPrinter) ActionPrint(opaSessionObj *cautils.OPASessionObj) error {
+	if err := encodeJunit(junitPrinter.writer, opaSessionObj); err != nil {
+		return err
 	}
 
 	logOUtputFile(junitPrinter.writer.Name())
+	return nil
+}
 
-	junitPrinter.writer.Write(postureReportStr)
+func encodeJunit(writer io.Writer, opaSessionObj *cautils.OPASessionObj) error {
+	postureReportStr, err := xml.Marshal(testsSuites(opaSessionObj))
+	if err != nil {
+		return fmt.Errorf("failed to Marshal xml result object: %w", err)
+	}
+	_, err = writer.Write(append([]byte(xml.Header), postureReportStr...))
+	return err
 }
 
 func testsSuites(results *cautils.OPASessionObj) *JUnitTestSuites {
 	return &JUnitTestSuites{
 		Suites:   listTestsSuite(results),
 		Tests:    results.Report.SummaryDetails.NumberOfControls().All(),
+		Time:     junitTime(results),
 		Name:     "Kubescape Scanning",
 		Failures: results.Report.SummaryDetails.NumberOfControls().Failed(),
 	}
 }
+// junitTimestamp returns the time of the report in the time zone of the reports, ISO 8601 without a time zone as the JUnit schema requires
+func junitTimestamp(results *cautils.OPASessionObj) string {
+	return results.ScanTimes.In(results.Report.ReportGenerationTime).Format("2006-01-02T15:04:05")
+}
+
+// junitTime returns the duration of the scan in seconds
+func junitTime(results *cautils.OPASessionObj) string {
+	if results.ScanTimes.Start.IsZero() || results.ScanTimes.End.IsZero() {
+		return "0"
+	}
+	return fmt.Sprintf("%.3f", results.ScanTimes.End.Sub(results.ScanTimes.Start).Seconds())
+}
+
 func listTestsSuite(results *cautils.OPASessionObj) []JUnitTestSuite {
 	var testSuites []JUnitTestSuite
 
@@ -130,10 +150,13 @@ func listTestsSuite(results *cautils.OPASessionObj) []JUnitTestSuite {
 	if len(results.Report.SummaryDetails.ListFrameworks().All()) == 0 {
 		testSuite := JUnitTestSuite{}
 		testSuite.Failures = results.Report.SummaryDetails.NumberOfControls().Failed()
-		testSuite.Timestamp = results.Report.ReportGenerationTime.String()
+		testSuite.Skipped = results.Report.SummaryDetails.NumberOfControls().Skipped()
+		testSuite.Tests = results.Report.SummaryDetails.NumberOfControls().All()
+		testSuite.Timestamp = junitTimestamp(results)
+		testSuite.Time = junitTime(results)
 		testSuite.ID = 0
 		testSuite.Name = "kubescape"
-		testSuite.Properties = properties(results.Report.SummaryDetails.Score)
+		testSuite.Properties = properties(results.Report.SummaryDetails.Score, listExpiredExceptions(results))
 		testSuite.TestCases = testsCases(results, &results.Report.SummaryDetails.Controls, "Kubescape")
 		testSuites = append(testSuites, testSuite)
 		return testSuites
@@ -142,10 +165,13 @@ func listTestsSuite(results *cautils.OPASessionObj) []JUnitTestSuite {
 	for i, f := range results.Report.SummaryDetails.Frameworks {
 		testSuite := JUnitTestSuite{}
 		testSuite.Failures = f.NumberOfControls().Failed()
-		testSuite.Timestamp = results.Report.ReportGenerationTime.String()
+		testSuite.Skipped = f.NumberOfControls().Skipped()
+		testSuite.Tests = f.NumberOfControls().All()
+		testSuite.Timestamp = junitTimestamp(results)
+		testSuite.Time = junitTime(results)
 		testSuite.ID = i
 		testSuite.Name = f.Name
-		testSuite.Properties = properties(f.Score)
+		testSuite.Properties = properties(f.Score, listExpiredExceptions(results))
 		testSuite.TestCases = testsCases(results, f.ListControls(), f.GetName())
 		testSuites = append(testSuites, testSuite)
 	}
@@ -155,32 +181,41 @@ func listTestsSuite(results *cautils.OPASessionObj) []JUnitTestSuite {
 func testsCases(results *cautils.OPASessionObj, controls reportsummary.IControlsSummaries, classname string) []JUnitTestCase {
 	var testCases []JUnitTestCase
 
-	for _, cID := range controls.ListControlsIDs().All() {
+	controlIDs := controls.ListControlsIDs().All()
+	sort.Strings(controlIDs)
+
+	for _, cID := range controlIDs {
 		testCase := JUnitTestCase{}
 		control := results.Report.SummaryDetails.Controls.GetControl(reportsummary.EControlCriteriaID, cID)
+		if control == nil {
+			continue
+		}
 
 		testCase.Name = control.GetName()
 		testCase.Classname = classname
 		testCase.Status = string(control.GetStatus().Status())
+		testCase.Time = "0"
 
 		if control.GetStatus().IsFailed() {
 			resources := map[string]interface{}{}
 			resourceIDs := control.ListResourcesIDs().Failed()
 			for j := range resourceIDs {
-				resource := results.AllResources[resourceIDs[j]]
-				resources[resourceToString(resource)] = nil
+				if resource, ok := results.AllResources[resourceIDs[j]]; ok {
+					resources[resourceToString(resource)] = nil
+				}
 			}
 			resourcesStr := shared.MapStringToSlice(resources)
 			sort.Strings(resourcesStr)
 			testCaseFailure := JUnitFailure{}
 			testCaseFailure.Type = "Control"
-			// testCaseFailure.Contents =
-			testCaseFailure.Message = fmt.Sprintf("Remediation: %s\nMore details: %s\n\n%s", control.GetRemediation(), getControlURL(control.GetID()), strings.Join(resourcesStr, "\n"))
+			// keep the message attribute short, CI tools display it as the failure title
+			testCaseFailure.Message = fmt.Sprintf("%s: %d failed resources, severity: %s", control.GetID(), len(resourcesStr), cautils.ControlSeverityToString(control.GetScoreFactor()))
+			testCaseFailure.Contents = fmt.Sprintf("Remediation: %s\nMore details: %s\n\n%s", control.GetRemediation(), getControlURL(control.GetID()), strings.Join(resourcesStr, "\n"))
 
 			testCase.Failure = &testCaseFailure
 		} else if control.GetStatus().IsSkipped() {
 			testCase.SkipMessage = &JUnitSkipMessage{
-				Message: "", // TODO - fill after statusInfo is supportred
+				Message: "no resources matched the control", // TODO - use the statusInfo once supported
 			}
 
 		}
@@ -201,11 +236,15 @@ func resourceToString(resource workloadinterface.IMetadata) string {
 	return s
 }
 
-func properties(riskScore float32) []JUnitProperty {
-	return []JUnitProperty{
+func properties(riskScore float32, expiredExceptions []ExpiredException) []JUnitProperty {
+	props := []JUnitProperty{
 		{
 			Name:  "riskScore",
 			Value: fmt.Sprintf("%.2f", riskScore),
 		},
 	}
+	for i := range expiredExceptions {
+		props = append(props, JUnitProperty{Name: "expiredException", Value: expiredExceptions[i].String()})
+	}
+	return props
 }
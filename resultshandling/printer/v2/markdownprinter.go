@@ -0,0 +1,50 @@
+package v2
+
+import (
+	_ "embed"
+	"fmt"
+	"io"
+	"os"
+	"text/template"
+
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/resultshandling/printer"
+)
+
+var (
+	//go:embed templates/markdown.tmpl
+	markdownTemplate string
+)
+
+type MarkdownPrinter struct {
+	writer *os.File
+}
+
+func NewMarkdownPrinter() *MarkdownPrinter {
+	return &MarkdownPrinter{}
+}
+
+func (markdownPrinter *MarkdownPrinter) SetWriter(outputFile string) {
+	markdownPrinter.writer = printer.GetWriter(outputFile)
+}
+
+func (markdownPrinter *MarkdownPrinter) Score(score float32) {
+	printer.LogScore(score)
+}
+
+func (markdownPrinter *MarkdownPrinter) ActionPrint(opaSessionObj *cautils.OPASessionObj) error {
+	if err := encodeMarkdown(markdownPrinter.writer, opaSessionObj); err != nil {
+		return err
+	}
+
+	logOUtputFile(markdownPrinter.writer.Name())
+	return nil
+}
+
+func encodeMarkdown(writer io.Writer, opaSessionObj *cautils.OPASessionObj) error {
+	tmpl := template.Must(template.New("markdown").Funcs(template.FuncMap{"mdEscape": printer.MarkdownEscape}).Parse(markdownTemplate))
+	if err := tmpl.Execute(writer, NewTemplateData(opaSessionObj)); err != nil {
+		return fmt.Errorf("failed to generate markdown results: %w", err)
+	}
+	return nil
+}
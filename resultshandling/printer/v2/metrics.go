@@ -0,0 +1,87 @@
+package v2
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/opa-utils/reporthandling/results/v1/reportsummary"
+)
+
+// https://prometheus.io/docs/instrumenting/exposition_formats/#text-based-format
+
+// GenerateMetrics converts the scan results to the prometheus text exposition format
+func GenerateMetrics(opaSessionObj *cautils.OPASessionObj) []byte {
+	buf := &bytes.Buffer{}
+	summaryDetails := &opaSessionObj.Report.SummaryDetails
+
+	writeMetricHeader(buf, "kubescape_score", "Overall risk-score (0- Excellent, 100- All failed)")
+	fmt.Fprintf(buf, "kubescape_score %g\n", summaryDetails.Score)
+
+	writeMetricHeader(buf, "kubescape_last_scan_timestamp_seconds", "Unix time of the last completed scan")
+	fmt.Fprintf(buf, "kubescape_last_scan_timestamp_seconds %d\n", opaSessionObj.Report.ReportGenerationTime.Unix())
+
+	writeMetricHeader(buf, "kubescape_expired_exceptions", "Number of exceptions which expired and were not applied")
+	fmt.Fprintf(buf, "kubescape_expired_exceptions %d\n", len(opaSessionObj.ExpiredExceptions))
+
+	writeMetricHeader(buf, "kubescape_framework_score", "Risk-score of the framework (0- Excellent, 100- All failed)")
+	for _, framework := range summaryDetails.Frameworks {
+		fmt.Fprintf(buf, "kubescape_framework_score{framework=\"%s\"} %g\n", escapeLabelValue(framework.GetName()), framework.GetScore())
+	}
+
+	controls := summaryDetails.Controls
+	controlIDs := controls.ListControlsIDs().All()
+	sort.Strings(controlIDs)
+
+	writeMetricHeader(buf, "kubescape_control_failed", "1 if the control failed, 0 otherwise")
+	for _, controlID := range controlIDs {
+		if control := controls.GetControl(reportsummary.EControlCriteriaID, controlID); control != nil {
+			failed := 0
+			if control.GetStatus().IsFailed() {
+				failed = 1
+			}
+			fmt.Fprintf(buf, "kubescape_control_failed{%s} %d\n", controlLabels(control), failed)
+		}
+	}
+
+	writeMetricHeader(buf, "kubescape_control_failed_resources", "Number of resources that failed the control")
+	for _, controlID := range controlIDs {
+		if control := controls.GetControl(reportsummary.EControlCriteriaID, controlID); control != nil {
+			fmt.Fprintf(buf, "kubescape_control_failed_resources{%s} %d\n", controlLabels(control), len(control.ListResourcesIDs().Failed()))
+		}
+	}
+
+	writeMetricHeader(buf, "kubescape_namespace_failed_resources", "Number of resources in the namespace that failed at least one control")
+	failedPerNamespace := map[string]int{}
+	for _, resourceID := range summaryDetails.ListResourcesIDs().Failed() {
+		if resource, ok := opaSessionObj.AllResources[resourceID]; ok {
+			failedPerNamespace[resource.GetNamespace()]++
+		}
+	}
+	namespaces := make([]string, 0, len(failedPerNamespace))
+	for namespace := range failedPerNamespace {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+	for _, namespace := range namespaces {
+		fmt.Fprintf(buf, "kubescape_namespace_failed_resources{namespace=\"%s\"} %d\n", escapeLabelValue(namespace), failedPerNamespace[namespace])
+	}
+
+	return buf.Bytes()
+}
+
+func writeMetricHeader(buf *bytes.Buffer, name, help string) {
+	fmt.Fprintf(buf, "# HELP %s %s\n# TYPE %s gauge\n", name, help, name)
+}
+
+func controlLabels(control reportsummary.IControlSummary) string {
+	return fmt.Sprintf("control=\"%s\",name=\"%s\",severity=\"%s\"", escapeLabelValue(control.GetID()), escapeLabelValue(control.GetName()), cautils.ControlSeverityToString(control.GetScoreFactor()))
+}
+
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabelValue(s string) string {
+	return labelValueReplacer.Replace(s)
+}
@@ -0,0 +1,76 @@
+package v2
+
+import (
+	"testing"
+	"time"
+
+	"github.com/armosec/k8s-interface/workloadinterface"
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/opa-utils/reporthandling/apis"
+	"github.com/armosec/opa-utils/reporthandling/results/v1/reportsummary"
+	"github.com/stretchr/testify/assert"
+)
+
+func mockMetricsSession() *cautils.OPASessionObj {
+	opaSessionObj := cautils.NewOPASessionObjMock()
+	opaSessionObj.Report.ReportGenerationTime = time.Date(2022, 3, 1, 12, 0, 0, 0, time.UTC)
+	for _, resource := range []map[string]interface{}{
+		{"apiVersion": "apps/v1", "kind": "Deployment", "metadata": map[string]interface{}{"name": "web", "namespace": "prod"}},
+		{"apiVersion": "apps/v1", "kind": "Deployment", "metadata": map[string]interface{}{"name": "api", "namespace": "prod"}},
+		{"apiVersion": "v1", "kind": "Pod", "metadata": map[string]interface{}{"name": "debug", "namespace": "dev"}},
+	} {
+		obj := workloadinterface.NewWorkloadObj(resource)
+		opaSessionObj.AllResources[obj.GetID()] = obj
+	}
+
+	privileged := reportsummary.ControlSummary{ControlID: "C-0057", Name: "Privileged \"container\"", Status: apis.StatusFailed, ScoreFactor: 8}
+	privileged.ResourceIDs.Append(apis.StatusFailed, "apps/v1/prod/Deployment/web")
+	privileged.ResourceIDs.Append(apis.StatusFailed, "/v1/dev/Pod/debug")
+	privileged.ResourceIDs.Append(apis.StatusPassed, "apps/v1/prod/Deployment/api")
+	nonRoot := reportsummary.ControlSummary{ControlID: "C-0013", Name: "Non-root containers", Status: apis.StatusFailed, ScoreFactor: 6}
+	nonRoot.ResourceIDs.Append(apis.StatusFailed, "apps/v1/prod/Deployment/web")
+	nonRoot.ResourceIDs.Append(apis.StatusFailed, "apps/v1/prod/Deployment/api")
+	escalation := reportsummary.ControlSummary{ControlID: "C-0016", Name: "Allow privilege escalation", Status: apis.StatusPassed, ScoreFactor: 6}
+	escalation.ResourceIDs.Append(apis.StatusPassed, "apps/v1/prod/Deployment/web")
+
+	opaSessionObj.Report.SummaryDetails.Score = 42.5
+	opaSessionObj.Report.SummaryDetails.Frameworks = []reportsummary.FrameworkSummary{{Name: "nsa", Score: 30}, {Name: "mitre", Score: 12.25}}
+	opaSessionObj.Report.SummaryDetails.Controls = reportsummary.ControlSummaries{"C-0057": privileged, "C-0013": nonRoot, "C-0016": escalation}
+	return opaSessionObj
+}
+
+func TestGenerateMetrics(t *testing.T) {
+	expected := `# HELP kubescape_score Overall risk-score (0- Excellent, 100- All failed)
+# TYPE kubescape_score gauge
+kubescape_score 42.5
+# HELP kubescape_last_scan_timestamp_seconds Unix time of the last completed scan
+# TYPE kubescape_last_scan_timestamp_seconds gauge
+kubescape_last_scan_timestamp_seconds 1646136000
+# HELP kubescape_expired_exceptions Number of exceptions which expired and were not applied
+# TYPE kubescape_expired_exceptions gauge
+kubescape_expired_exceptions 0
+# HELP kubescape_framework_score Risk-score of the framework (0- Excellent, 100- All failed)
+# TYPE kubescape_framework_score gauge
+kubescape_framework_score{framework="nsa"} 30
+kubescape_framework_score{framework="mitre"} 12.25
+# HELP kubescape_control_failed 1 if the control failed, 0 otherwise
+# TYPE kubescape_control_failed gauge
+kubescape_control_failed{control="C-0013",name="Non-root containers",severity="Medium"} 1
+kubescape_control_failed{control="C-0016",name="Allow privilege escalation",severity="Medium"} 0
+kubescape_control_failed{control="C-0057",name="Privileged \"container\"",severity="High"} 1
+# HELP kubescape_control_failed_resources Number of resources that failed the control
+# TYPE kubescape_control_failed_resources gauge
+kubescape_control_failed_resources{control="C-0013",name="Non-root containers",severity="Medium"} 2
+kubescape_control_failed_resources{control="C-0016",name="Allow privilege escalation",severity="Medium"} 0
+kubescape_control_failed_resources{control="C-0057",name="Privileged \"container\"",severity="High"} 2
+# HELP kubescape_namespace_failed_resources Number of resources in the namespace that failed at least one control
+# TYPE kubescape_namespace_failed_resources gauge
+kubescape_namespace_failed_resources{namespace="dev"} 1
+kubescape_namespace_failed_resources{namespace="prod"} 2
+`
+	assert.Equal(t, expected, string(GenerateMetrics(mockMetricsSession())))
+}
+
+func TestEscapeLabelValue(t *testing.T) {
+	assert.Equal(t, `a\\b \"c\" \nd`, escapeLabelValue("a\\b \"c\" \nd"))
+}
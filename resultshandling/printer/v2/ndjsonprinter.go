@@ -0,0 +1,187 @@
+package v2
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/armosec/armoapi-go/armotypes"
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/resultshandling/printer"
+	"github.com/armosec/opa-utils/reporthandling"
+	"github.com/armosec/opa-utils/reporthandling/results/v1/reportsummary"
+	"github.com/armosec/opa-utils/reporthandling/results/v1/resourcesresults"
+)
+
+// http://ndjson.org/
+
+const (
+	ndjsonResultType  = "result"
+	ndjsonSummaryType = "summary"
+)
+
+// NdjsonResult the result of a single (control, resource) pair
+type NdjsonResult struct {
+	Type        string                   `json:"type"`
+	ControlID   string                   `json:"controlID"`
+	ControlName string                   `json:"controlName"`
+	Severity    string                   `json:"severity"`
+	Status      string                   `json:"status"`
+	ResourceID  string                   `json:"resourceID"`
+	Kind        string                   `json:"kind,omitempty"`
+	Namespace   string                   `json:"namespace,omitempty"`
+	Name        string                   `json:"name,omitempty"`
+	File        string                   `json:"file,omitempty"`
+	Line        int                      `json:"line,omitempty"`
+	Paths       []armotypes.PosturePaths `json:"paths,omitempty"`
+}
+
+// NdjsonSummary the last line of the output, written once the scan is done
+type NdjsonSummary struct {
+	Type           string            `json:"type"`
+	GeneratedAt    time.Time         `json:"generatedAt"`
+	StartTime      string            `json:"startTime,omitempty"`
+	EndTime        string            `json:"endTime,omitempty"`
+	RiskScore      float32           `json:"riskScore"`
+	Frameworks     []NdjsonFramework `json:"frameworks,omitempty"`
+	FailedControls int               `json:"failedControls"`
+	PassedControls int               `json:"passedControls"`
+
+	ExpiredExceptions []ExpiredException `json:"expiredExceptions,omitempty"`
+}
+
+type NdjsonFramework struct {
+	Name      string  `json:"name"`
+	RiskScore float32 `json:"riskScore"`
+}
+
+// NdjsonPrinter writes a line per evaluated (control, resource) pair as soon as the control is evaluated, instead of buffering the results
+type NdjsonPrinter struct {
+	writer   *os.File
+	encoder  *json.Encoder
+	streamed bool
+	err      error // the first failure of writing a line, the next lines are not written
+}
+
+func NewNdjsonPrinter() *NdjsonPrinter {
+	return &NdjsonPrinter{}
+}
+
+func (ndjsonPrinter *NdjsonPrinter) SetWriter(outputFile string) {
+	ndjsonPrinter.writer = printer.GetWriter(outputFile)
+	ndjsonPrinter.encoder = json.NewEncoder(ndjsonPrinter.writer)
+}
+
+func (ndjsonPrinter *NdjsonPrinter) Score(score float32) {
+	printer.LogScore(score)
+}
+
+// StreamResult writes the result of the control as soon as the control is evaluated
+func (ndjsonPrinter *NdjsonPrinter) StreamResult(opaSessionObj *cautils.OPASessionObj, control *reporthandling.Control, resourceID string, result *resourcesresults.ResourceAssociatedControl) {
+	ndjsonPrinter.streamed = true
+	ndjsonPrinter.encode(ndjsonResult(opaSessionObj, cautils.ControlSeverityToString(control.BaseScore), resourceID, result))
+}
+
+func (ndjsonPrinter *NdjsonPrinter) ActionPrint(opaSessionObj *cautils.OPASessionObj) error {
+	// the results were not streamed (e.g. when uploading the output), write all of the results at once
+	if !ndjsonPrinter.streamed {
+		for _, result := range ndjsonResults(opaSessionObj) {
+			ndjsonPrinter.encode(result)
+		}
+	}
+
+	ndjsonPrinter.encode(ndjsonSummary(opaSessionObj))
+	if ndjsonPrinter.err != nil {
+		return fmt.Errorf("failed to write ndjson results: %w", ndjsonPrinter.err)
+	}
+	logOUtputFile(ndjsonPrinter.writer.Name())
+	return nil
+}
+
+func (ndjsonPrinter *NdjsonPrinter) encode(v interface{}) {
+	if ndjsonPrinter.err != nil {
+		return
+	}
+	ndjsonPrinter.err = ndjsonPrinter.encoder.Encode(v)
+}
+
+func encodeNdjson(writer io.Writer, opaSessionObj *cautils.OPASessionObj) error {
+	encoder := json.NewEncoder(writer)
+	for _, result := range ndjsonResults(opaSessionObj) {
+		if err := encoder.Encode(result); err != nil {
+			return err
+		}
+	}
+	return encoder.Encode(ndjsonSummary(opaSessionObj))
+}
+
+// ndjsonSummary returns the summary line of the scan, the last line of the output
+func ndjsonSummary(opaSessionObj *cautils.OPASessionObj) NdjsonSummary {
+	data := NewTemplateData(opaSessionObj)
+	summary := NdjsonSummary{
+		Type:           ndjsonSummaryType,
+		GeneratedAt:    data.GeneratedAt,
+		StartTime:      data.StartTime,
+		EndTime:        data.EndTime,
+		RiskScore:      data.Score,
+		FailedControls: data.FailedCount,
+		PassedControls: data.PassedCount,
+
+		ExpiredExceptions: data.ExpiredExceptions,
+	}
+	for _, framework := range data.Frameworks {
+		summary.Frameworks = append(summary.Frameworks, NdjsonFramework{Name: framework.Name, RiskScore: framework.Score})
+	}
+	return summary
+}
+
+// ndjsonResults returns the results of all of the resources, sorted by resource and control
+func ndjsonResults(opaSessionObj *cautils.OPASessionObj) []NdjsonResult {
+	resourceIDs := make([]string, 0, len(opaSessionObj.ResourcesResult))
+	for resourceID := range opaSessionObj.ResourcesResult {
+		resourceIDs = append(resourceIDs, resourceID)
+	}
+	sort.Strings(resourceIDs)
+
+	results := []NdjsonResult{}
+	for _, resourceID := range resourceIDs {
+		result := opaSessionObj.ResourcesResult[resourceID]
+		controls := append([]resourcesresults.ResourceAssociatedControl{}, result.ListControls()...)
+		sort.Slice(controls, func(i, j int) bool { return controls[i].GetID() < controls[j].GetID() })
+		for i := range controls {
+			severity := cautils.SeverityUnknown
+			if control := opaSessionObj.Report.SummaryDetails.Controls.GetControl(reportsummary.EControlCriteriaID, controls[i].GetID()); control != nil {
+				severity = cautils.ControlSeverityToString(control.GetScoreFactor())
+			}
+			results = append(results, ndjsonResult(opaSessionObj, severity, resourceID, &controls[i]))
+		}
+	}
+	return results
+}
+
+func ndjsonResult(opaSessionObj *cautils.OPASessionObj, severity, resourceID string, result *resourcesresults.ResourceAssociatedControl) NdjsonResult {
+	ndjsonResult := NdjsonResult{
+		Type:        ndjsonResultType,
+		ControlID:   result.GetID(),
+		ControlName: result.GetName(),
+		Severity:    severity,
+		Status:      string(result.GetStatus(nil).Status()),
+		ResourceID:  resourceID,
+	}
+	if resource, ok := opaSessionObj.AllResources[resourceID]; ok {
+		ndjsonResult.Kind = resource.GetKind()
+		ndjsonResult.Namespace = resource.GetNamespace()
+		ndjsonResult.Name = resource.GetName()
+	}
+	if source, ok := opaSessionObj.ResourceSource[resourceID]; ok {
+		ndjsonResult.File = relativeSourcePath(source.Path)
+		ndjsonResult.Line = source.Line
+	}
+	for _, rule := range result.ListRules() {
+		ndjsonResult.Paths = append(ndjsonResult.Paths, rule.Paths...)
+	}
+	return ndjsonResult
+}
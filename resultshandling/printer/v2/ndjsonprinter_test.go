@@ -0,0 +1,25 @@
+package v2
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNdjsonPrinterWriteError(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "results.ndjson"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ndjsonPrinter := &NdjsonPrinter{writer: f, encoder: json.NewEncoder(f)}
+	assert.NoError(t, ndjsonPrinter.ActionPrint(mockMetricsSession()))
+
+	// the first failure is returned, the next lines are not written
+	f.Close()
+	ndjsonPrinter = &NdjsonPrinter{writer: f, encoder: json.NewEncoder(f)}
+	err = ndjsonPrinter.ActionPrint(mockMetricsSession())
+	assert.ErrorIs(t, err, os.ErrClosed)
+}
@@ -0,0 +1,311 @@
+package v2
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/resultshandling/printer"
+	"github.com/armosec/opa-utils/reporthandling/apis"
+	"github.com/armosec/opa-utils/reporthandling/results/v1/reportsummary"
+	"github.com/google/uuid"
+)
+
+// https://pages.nist.gov/OSCAL/reference/latest/assessment-results/json-reference/
+
+const (
+	oscalVersion     = "1.0.4"
+	oscalKubescapeNS = "https://github.com/armosec/kubescape" // namespace of the kubescape properties
+)
+
+// uuid namespace of the OSCAL objects, used for generating stable UUIDs of the resources
+var oscalNamespace = uuid.NewSHA1(uuid.NameSpaceURL, []byte("https://github.com/armosec/kubescape"))
+
+type OscalDocument struct {
+	AssessmentResults OscalAssessmentResults `json:"assessment-results"`
+}
+
+type OscalAssessmentResults struct {
+	UUID     string        `json:"uuid"`
+	Metadata OscalMetadata `json:"metadata"`
+	ImportAP OscalImportAP `json:"import-ap"`
+	Results  []OscalResult `json:"results"`
+}
+
+type OscalMetadata struct {
+	Title        string `json:"title"`
+	LastModified string `json:"last-modified"`
+	Version      string `json:"version"`
+	OscalVersion string `json:"oscal-version"`
+}
+
+type OscalImportAP struct {
+	Href string `json:"href"`
+}
+
+// OscalResult represents the assessment of a single framework
+type OscalResult struct {
+	UUID             string                `json:"uuid"`
+	Title            string                `json:"title"`
+	Description      string                `json:"description"`
+	Start            string                `json:"start"`
+	End              string                `json:"end,omitempty"`
+	Props            []OscalProperty       `json:"props,omitempty"`
+	ReviewedControls OscalReviewedControls `json:"reviewed-controls"`
+	Observations     []OscalObservation    `json:"observations,omitempty"`
+	Findings         []OscalFinding        `json:"findings,omitempty"`
+}
+
+type OscalProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+	NS    string `json:"ns,omitempty"`
+}
+
+type OscalReviewedControls struct {
+	ControlSelections []OscalControlSelection `json:"control-selections"`
+}
+
+type OscalControlSelection struct {
+	Description     string              `json:"description,omitempty"`
+	IncludeControls []OscalControlIDRef `json:"include-controls,omitempty"`
+}
+
+type OscalControlIDRef struct {
+	ControlID string `json:"control-id"`
+}
+
+// OscalObservation the resources that were tested by a control
+type OscalObservation struct {
+	UUID        string                  `json:"uuid"`
+	Title       string                  `json:"title"`
+	Description string                  `json:"description"`
+	Methods     []string                `json:"methods"`
+	Props       []OscalProperty         `json:"props,omitempty"`
+	Subjects    []OscalSubjectReference `json:"subjects,omitempty"`
+	Collected   string                  `json:"collected"`
+}
+
+type OscalSubjectReference struct {
+	SubjectUUID string          `json:"subject-uuid"`
+	Type        string          `json:"type"`
+	Title       string          `json:"title"`
+	Props       []OscalProperty `json:"props,omitempty"`
+}
+
+// OscalFinding the status of a single control
+type OscalFinding struct {
+	UUID                string                    `json:"uuid"`
+	Title               string                    `json:"title"`
+	Description         string                    `json:"description"`
+	Target              OscalFindingTarget        `json:"target"`
+	RelatedObservations []OscalRelatedObservation `json:"related-observations,omitempty"`
+}
+
+type OscalFindingTarget struct {
+	Type     string            `json:"type"`
+	TargetID string            `json:"target-id"`
+	Status   OscalTargetStatus `json:"status"`
+}
+
+type OscalTargetStatus struct {
+	State  string `json:"state"`
+	Reason string `json:"reason,omitempty"`
+}
+
+type OscalRelatedObservation struct {
+	ObservationUUID string `json:"observation-uuid"`
+}
+
+type OscalPrinter struct {
+	writer *os.File
+}
+
+func NewOscalPrinter() *OscalPrinter {
+	return &OscalPrinter{}
+}
+
+func (oscalPrinter *OscalPrinter) SetWriter(outputFile string) {
+	oscalPrinter.writer = printer.GetWriter(outputFile)
+}
+
+func (oscalPrinter *OscalPrinter) Score(score float32) {
+	printer.LogScore(score)
+}
+
+func (oscalPrinter *OscalPrinter) ActionPrint(opaSessionObj *cautils.OPASessionObj) error {
+	if err := encodeOscal(oscalPrinter.writer, opaSessionObj); err != nil {
+		return err
+	}
+
+	logOUtputFile(oscalPrinter.writer.Name())
+	return nil
+}
+
+func encodeOscal(writer io.Writer, opaSessionObj *cautils.OPASessionObj) error {
+	r, err := json.MarshalIndent(oscalDocument(opaSessionObj), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to Marshal oscal result object: %w", err)
+	}
+	_, err = writer.Write(r)
+	return err
+}
+
+func oscalDocument(opaSessionObj *cautils.OPASessionObj) *OscalDocument {
+	generationTime := opaSessionObj.ScanTimes.Format(opaSessionObj.Report.ReportGenerationTime)
+	summaryDetails := &opaSessionObj.Report.SummaryDetails
+
+	results := []OscalResult{}
+	if len(summaryDetails.Frameworks) == 0 {
+		// control scan
+		results = append(results, oscalResult(opaSessionObj, "kubescape", summaryDetails.Score, &summaryDetails.Controls, generationTime))
+	}
+	for i := range summaryDetails.Frameworks {
+		framework := &summaryDetails.Frameworks[i]
+		results = append(results, oscalResult(opaSessionObj, framework.GetName(), framework.GetScore(), &framework.Controls, generationTime))
+	}
+	// the exceptions apply to all of the frameworks
+	expiredExceptions := oscalExpiredExceptionObservations(opaSessionObj, generationTime)
+	for i := range results {
+		results[i].Observations = append(results[i].Observations, expiredExceptions...)
+	}
+
+	return &OscalDocument{
+		AssessmentResults: OscalAssessmentResults{
+			UUID: uuid.New().String(),
+			Metadata: OscalMetadata{
+				Title:        "Kubescape assessment results",
+				LastModified: generationTime,
+				Version:      cautils.BuildNumber,
+				OscalVersion: oscalVersion,
+			},
+			ImportAP: OscalImportAP{Href: "#"},
+			Results:  results,
+		},
+	}
+}
+
+// scanStart returns the start of the scan, the time of the report for the results loaded from a file
+func scanStart(opaSessionObj *cautils.OPASessionObj, generationTime string) string {
+	if start := opaSessionObj.ScanTimes.Format(opaSessionObj.ScanTimes.Start); start != "" {
+		return start
+	}
+	return generationTime
+}
+
+// oscalResult converts a framework to an OSCAL result, each control is a finding and the tested resources of the control an observation
+func oscalResult(opaSessionObj *cautils.OPASessionObj, frameworkName string, score float32, frameworkControls *reportsummary.ControlSummaries, generationTime string) OscalResult {
+	result := OscalResult{
+		UUID:        uuid.New().String(),
+		Title:       fmt.Sprintf("Kubescape %s assessment", frameworkName),
+		Description: fmt.Sprintf("Assessment of the %s framework controls", frameworkName),
+		Start:       scanStart(opaSessionObj, generationTime),
+		End:         opaSessionObj.ScanTimes.Format(opaSessionObj.ScanTimes.End),
+		Props: []OscalProperty{
+			{Name: "risk-score", Value: fmt.Sprintf("%.2f", score), NS: oscalKubescapeNS},
+		},
+		ReviewedControls: OscalReviewedControls{
+			ControlSelections: []OscalControlSelection{{Description: frameworkName}},
+		},
+	}
+
+	controlIDs := frameworkControls.ListControlsIDs().All()
+	sort.Strings(controlIDs)
+	for _, controlID := range controlIDs {
+		// the control summary of the framework does not hold the description and the remediation
+		control := opaSessionObj.Report.SummaryDetails.Controls.GetControl(reportsummary.EControlCriteriaID, controlID)
+		if control == nil {
+			continue
+		}
+		result.ReviewedControls.ControlSelections[0].IncludeControls = append(result.ReviewedControls.ControlSelections[0].IncludeControls, OscalControlIDRef{ControlID: strings.ToLower(controlID)})
+
+		observation := OscalObservation{
+			UUID:        uuid.New().String(),
+			Title:       fmt.Sprintf("%s %s", control.GetID(), control.GetName()),
+			Description: control.GetDescription(),
+			Methods:     []string{"TEST"},
+			Props: []OscalProperty{
+				{Name: "severity", Value: cautils.ControlSeverityToString(control.GetScoreFactor()), NS: oscalKubescapeNS},
+			},
+			Subjects:  oscalSubjects(opaSessionObj, control),
+			Collected: generationTime,
+		}
+		result.Observations = append(result.Observations, observation)
+
+		result.Findings = append(result.Findings, OscalFinding{
+			UUID:        uuid.New().String(),
+			Title:       observation.Title,
+			Description: fmt.Sprintf("%d failed resources out of %d. Remediation: %s", control.NumberOfResources().Failed(), control.NumberOfResources().All(), control.GetRemediation()),
+			Target: OscalFindingTarget{
+				Type:     "objective-id",
+				TargetID: strings.ToLower(controlID),
+				Status:   oscalTargetStatus(control),
+			},
+			RelatedObservations: []OscalRelatedObservation{{ObservationUUID: observation.UUID}},
+		})
+	}
+	return result
+}
+
+// oscalSubjects returns the resources tested by the control, the result of the resource is set as a property
+func oscalSubjects(opaSessionObj *cautils.OPASessionObj, control reportsummary.IControlSummary) []OscalSubjectReference {
+	subjects := []OscalSubjectReference{}
+	resourcesIDs := control.ListResourcesIDs()
+	for _, status := range []apis.ScanningStatus{apis.StatusFailed, apis.StatusExcluded, apis.StatusPassed} {
+		ids := resourceIDsByStatus(resourcesIDs, status)
+		sort.Strings(ids)
+		for _, resourceID := range ids {
+			resource, ok := opaSessionObj.AllResources[resourceID]
+			if !ok {
+				continue
+			}
+			subjects = append(subjects, OscalSubjectReference{
+				SubjectUUID: uuid.NewSHA1(oscalNamespace, []byte(resourceID)).String(),
+				Type:        "resource",
+				Title:       resourceFullName(resource),
+				Props: []OscalProperty{
+					{Name: "result", Value: string(status), NS: oscalKubescapeNS},
+				},
+			})
+		}
+	}
+	return subjects
+}
+
+// oscalExpiredExceptionObservations returns an observation per expired exception, the exception was not applied on the results and should be reviewed
+func oscalExpiredExceptionObservations(opaSessionObj *cautils.OPASessionObj, generationTime string) []OscalObservation {
+	observations := []OscalObservation{}
+	for _, expiredException := range listExpiredExceptions(opaSessionObj) {
+		props := []OscalProperty{{Name: "expiration-date", Value: expiredException.ExpirationDate, NS: oscalKubescapeNS}}
+		if expiredException.Owner != "" {
+			props = append(props, OscalProperty{Name: "owner", Value: expiredException.Owner, NS: oscalKubescapeNS})
+		}
+		if expiredException.Reason != "" {
+			props = append(props, OscalProperty{Name: "reason", Value: expiredException.Reason, NS: oscalKubescapeNS})
+		}
+		observations = append(observations, OscalObservation{
+			UUID:        uuid.New().String(),
+			Title:       fmt.Sprintf("Expired exception %s", expiredException.Name),
+			Description: fmt.Sprintf("The %s, the exception is not applied on the results", expiredException.String()),
+			Methods:     []string{"EXAMINE"},
+			Props:       props,
+			Collected:   generationTime,
+		})
+	}
+	return observations
+}
+
+func oscalTargetStatus(control reportsummary.IControlSummary) OscalTargetStatus {
+	status := control.GetStatus()
+	switch {
+	case status.IsFailed():
+		return OscalTargetStatus{State: "not-satisfied", Reason: "fail"}
+	case status.IsSkipped():
+		return OscalTargetStatus{State: "not-satisfied", Reason: "other"}
+	}
+	return OscalTargetStatus{State: "satisfied", Reason: "pass"}
+}
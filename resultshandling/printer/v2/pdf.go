@@ -4,14 +4,22 @@ import (
 	_ "embed"
 	b64 "encoding/base64"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/armosec/k8s-interface/workloadinterface"
 	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/cautils/logger/helpers"
 	"github.com/armosec/kubescape/resultshandling/printer"
+	"github.com/armosec/opa-utils/reporthandling/apis"
 	"github.com/armosec/opa-utils/reporthandling/results/v1/reportsummary"
+	"github.com/armosec/opa-utils/reporthandling/results/v1/resourcesresults"
 	"github.com/johnfercher/maroto/pkg/color"
 	"github.com/johnfercher/maroto/pkg/consts"
 	"github.com/johnfercher/maroto/pkg/pdf"
@@ -31,52 +39,75 @@ var (
 type PdfPrinter struct {
 	writer             *os.File
 	sortedControlNames []string
+	verboseMode        bool
+	options            cautils.PdfOptions
 }
 
-func NewPdfPrinter() *PdfPrinter {
-	return &PdfPrinter{}
+func NewPdfPrinter(verboseMode bool, options *cautils.PdfOptions) *PdfPrinter {
+	pdfPrinter := &PdfPrinter{
+		verboseMode: verboseMode,
+	}
+	if options != nil {
+		pdfPrinter.options = *options
+	}
+	return pdfPrinter
 }
 
 func (pdfPrinter *PdfPrinter) SetWriter(outputFile string) {
-	// Ensure to have an available output file, otherwise create it.
-	if outputFile == "" {
-		outputFile = pdfOutputFile
-	}
-	// Ensure to have the right file extension.
-	if filepath.Ext(strings.TrimSpace(outputFile)) != pdfOutputExt {
-		outputFile = outputFile + pdfOutputExt
-	}
-	pdfPrinter.writer = printer.GetWriter(outputFile)
+	pdfPrinter.writer = printer.GetWriter(GetOutputFile(printer.PdfFormat, outputFile))
 }
 
 func (pdfPrinter *PdfPrinter) Score(score float32) {
-	fmt.Fprintf(os.Stderr, "\nOverall risk-score (0- Excellent, 100- All failed): %d\n", int(score))
+	printer.LogScore(score)
+}
+
+func (pdfPrinter *PdfPrinter) ActionPrint(opaSessionObj *cautils.OPASessionObj) error {
+	return pdfPrinter.encode(pdfPrinter.writer, opaSessionObj)
 }
 
-func (pdfPrinter *PdfPrinter) ActionPrint(opaSessionObj *cautils.OPASessionObj) {
+func encodePdf(writer io.Writer, opaSessionObj *cautils.OPASessionObj) error {
+	return NewPdfPrinter(false, &cautils.PdfOptions{}).encode(writer, opaSessionObj)
+}
+
+func (pdfPrinter *PdfPrinter) encode(writer io.Writer, opaSessionObj *cautils.OPASessionObj) error {
 	pdfPrinter.sortedControlNames = getSortedControlsNames(opaSessionObj.Report.SummaryDetails.Controls)
 
-	m := pdf.NewMaroto(consts.Portrait, consts.A4)
-	pdfPrinter.printHeader(m)
+	m := pdf.NewMaroto(pdfPrinter.getOrientation(), pdfPrinter.getPageSize())
+	pdfPrinter.printFooter(m)
+	pdfPrinter.printHeader(m, &opaSessionObj.ScanTimes, opaSessionObj.Report.ReportGenerationTime)
+	pdfPrinter.printExecutiveSummary(m, &opaSessionObj.Report.SummaryDetails)
+	m.AddPage()
 	pdfPrinter.printFramework(m, opaSessionObj.Report.SummaryDetails.ListFrameworks().All())
 	pdfPrinter.printTable(m, &opaSessionObj.Report.SummaryDetails)
 	pdfPrinter.printFinalResult(m, &opaSessionObj.Report.SummaryDetails)
+	pdfPrinter.printExpiredExceptions(m, listExpiredExceptions(opaSessionObj))
+	pdfPrinter.printDataGaps(m, listDataGaps(opaSessionObj))
+	pdfPrinter.printImagesVulnerabilities(m, listImagesVulnerabilities(opaSessionObj))
+	pdfPrinter.printImagesSignatures(m, listImagesSignatures(opaSessionObj))
+	if pdfPrinter.verboseMode || opaSessionObj.IncludePassed {
+		pdfPrinter.printControlsDetails(m, opaSessionObj)
+	}
+	if opaSessionObj.IncludePassed {
+		pdfPrinter.printCheckedResources(m, opaSessionObj)
+	}
 
 	// Extrat output buffer.
 	outBuff, err := m.Output()
 	if err != nil {
-		fmt.Println("Could not save PDF:", err)
-		os.Exit(1)
+		return fmt.Errorf("failed to save the PDF: %w", err)
 	}
-	pdfPrinter.writer.Write(outBuff.Bytes())
+	_, err = writer.Write(outBuff.Bytes())
+	return err
 }
 
-// Print Kubescape logo and report date.
-func (pdfPrinter *PdfPrinter) printHeader(m pdf.Maroto) {
-	// Retrieve current time (we need it for the report timestamp).
-	t := time.Now()
-	// Enconde PNG into Base64 to embed it into the pdf.
-	kubescapeLogoEnc := b64.StdEncoding.EncodeToString(kubescapeLogo)
+// Print Kubescape logo and report date, RFC3339 in the time zone of the reports
+func (pdfPrinter *PdfPrinter) printHeader(m pdf.Maroto, scanTimes *cautils.ScanTimes, reportTime time.Time) {
+	if reportTime.IsZero() {
+		reportTime = time.Now()
+	}
+	logo, logoExt := pdfPrinter.getLogo()
+	// Enconde the image into Base64 to embed it into the pdf.
+	logoEnc := b64.StdEncoding.EncodeToString(logo)
 
 	m.SetPageMargins(10, 15, 10)
 	m.Row(40, func() {
@@ -86,19 +117,27 @@ func (pdfPrinter *PdfPrinter) printHeader(m pdf.Maroto) {
 		//	Family: consts.Arial,
 		//	Style:  consts.Bold,
 		//})
-		_ = m.Base64Image(kubescapeLogoEnc, consts.Png, props.Rect{
+		_ = m.Base64Image(logoEnc, logoExt, props.Rect{
 			Center:  true,
 			Percent: 100,
 		})
 	})
+	if pdfPrinter.options.Title != "" {
+		m.Row(12, func() {
+			m.Text(pdfPrinter.options.Title, props.Text{
+				Align:  consts.Center,
+				Size:   18,
+				Family: consts.Arial,
+				Style:  consts.Bold,
+			})
+		})
+	}
 	m.Row(6, func() {
-		m.Text(fmt.Sprintf("Report date: %d-%02d-%02dT%02d:%02d:%02d",
-			t.Year(),
-			t.Month(),
-			t.Day(),
-			t.Hour(),
-			t.Minute(),
-			t.Second()), props.Text{
+		reportDate := fmt.Sprintf("Report date: %s", scanTimes.Format(reportTime))
+		if !scanTimes.Start.IsZero() {
+			reportDate += fmt.Sprintf(" (scanned from %s to %s)", scanTimes.Format(scanTimes.Start), scanTimes.Format(scanTimes.End))
+		}
+		m.Text(reportDate, props.Text{
 			Align:  consts.Left,
 			Size:   6.0,
 			Style:  consts.Bold,
@@ -108,6 +147,72 @@ func (pdfPrinter *PdfPrinter) printHeader(m pdf.Maroto) {
 	m.Line(1)
 }
 
+func (pdfPrinter *PdfPrinter) getOrientation() consts.Orientation {
+	if strings.EqualFold(pdfPrinter.options.Orientation, "landscape") {
+		return consts.Landscape
+	}
+	return consts.Portrait
+}
+
+func (pdfPrinter *PdfPrinter) getPageSize() consts.PageSize {
+	for _, pageSize := range []consts.PageSize{consts.A3, consts.A4, consts.A5, consts.Letter, consts.Legal} {
+		if strings.EqualFold(pdfPrinter.options.PageSize, string(pageSize)) {
+			return pageSize
+		}
+	}
+	return consts.A4
+}
+
+// getLogo returns the custom logo if set, otherwise the embedded kubescape logo
+func (pdfPrinter *PdfPrinter) getLogo() ([]byte, consts.Extension) {
+	if pdfPrinter.options.Logo == "" {
+		return kubescapeLogo, consts.Png
+	}
+	var ext consts.Extension
+	switch strings.ToLower(filepath.Ext(pdfPrinter.options.Logo)) {
+	case ".png":
+		ext = consts.Png
+	case ".jpg", ".jpeg":
+		ext = consts.Jpg
+	default:
+		logger.L().Warning("unsupported pdf logo format, using the default logo. Supported: png/jpg", helpers.String("path", pdfPrinter.options.Logo))
+		return kubescapeLogo, consts.Png
+	}
+	logo, err := os.ReadFile(pdfPrinter.options.Logo)
+	if err != nil {
+		logger.L().Warning("failed to read pdf logo, using the default logo", helpers.String("path", pdfPrinter.options.Logo), helpers.Error(err))
+		return kubescapeLogo, consts.Png
+	}
+	return logo, ext
+}
+
+// Print the footer text and page number on every page.
+func (pdfPrinter *PdfPrinter) printFooter(m pdf.Maroto) {
+	if pdfPrinter.options.Footer == "" {
+		return
+	}
+	m.RegisterFooter(func() {
+		m.Row(6, func() {
+			m.Col(10, func() {
+				m.Text(pdfPrinter.options.Footer, props.Text{
+					Top:    2,
+					Align:  consts.Left,
+					Size:   7.0,
+					Family: consts.Arial,
+				})
+			})
+			m.Col(2, func() {
+				m.Text(fmt.Sprintf("%d", m.GetCurrentPage()+1), props.Text{
+					Top:    2,
+					Align:  consts.Right,
+					Size:   7.0,
+					Family: consts.Arial,
+				})
+			})
+		})
+	})
+}
+
 // Print pdf frameworks after pdf header.
 func (pdfPrinter *PdfPrinter) printFramework(m pdf.Maroto, frameworks []reportsummary.IPolicies) {
 	m.Row(10, func() {
@@ -120,41 +225,84 @@ func (pdfPrinter *PdfPrinter) printFramework(m pdf.Maroto, frameworks []reportsu
 	})
 }
 
-// Create pdf table
+// Create pdf table, the severity cell is color-coded by the severity of failed controls
 func (pdfPrinter *PdfPrinter) printTable(m pdf.Maroto, summaryDetails *reportsummary.SummaryDetails) {
-	headers := getControlTableHeaders()
-	controls := make([][]string, len(pdfPrinter.sortedControlNames))
-	for i := range controls {
-		controls[i] = make([]string, len(headers))
+	headers := getPdfControlTableHeaders()
+	gridSizes := []uint{5, 2, 1, 1, 1, 2}
+
+	m.Row(8, func() {
+		for i := range headers {
+			m.Col(gridSizes[i], func() {
+				m.Text(headers[i], props.Text{
+					Align:  consts.Center,
+					Size:   7.0,
+					Style:  consts.Bold,
+					Family: consts.Arial,
+				})
+			})
+		}
+	})
+
+	alternatedBackground := color.Color{
+		Red:   224,
+		Green: 224,
+		Blue:  224,
 	}
 	for i := 0; i < len(pdfPrinter.sortedControlNames); i++ {
-		controls[i] = generateRow(summaryDetails.Controls.GetControl(reportsummary.EControlCriteriaName, pdfPrinter.sortedControlNames[i]))
+		control := summaryDetails.Controls.GetControl(reportsummary.EControlCriteriaName, pdfPrinter.sortedControlNames[i])
+		row := generatePdfRow(control)
+		rowBackground := color.NewWhite()
+		if i%2 == 0 {
+			rowBackground = alternatedBackground
+		}
+		// the name is the only column that may wrap - ~40 characters per line
+		m.Row(float64(4*(len(row[0])/40+1)+1), func() {
+			for j := range row {
+				cellBackground, textColor, style := rowBackground, color.NewBlack(), consts.Normal
+				if j == 1 {
+					cellBackground, textColor, style = controlStatusColor(control, rowBackground)
+				}
+				m.SetBackgroundColor(cellBackground)
+				m.Col(gridSizes[j], func() {
+					m.Text(row[j], props.Text{
+						Top:    1,
+						Align:  consts.Center,
+						Size:   8.0,
+						Style:  style,
+						Family: consts.Courier,
+						Color:  textColor,
+					})
+				})
+			}
+		})
+		m.SetBackgroundColor(color.NewWhite())
 	}
-
-	m.TableList(headers, controls, props.TableList{
-		HeaderProp: props.TableListContent{
-			Family: consts.Arial,
-			Style:  consts.Bold,
-			Size:   8.0,
-		},
-		ContentProp: props.TableListContent{
-			Family: consts.Courier,
-			Style:  consts.Normal,
-			Size:   8.0,
-		},
-		Align: consts.Center,
-		AlternatedBackground: &color.Color{
-			Red:   224,
-			Green: 224,
-			Blue:  224,
-		},
-		HeaderContentSpace: 2.0,
-		Line:               false,
-	})
 	m.Line(1)
 	m.Row(2, func() {})
 }
 
+func getPdfControlTableHeaders() []string {
+	return []string{"CONTROL NAME", "SEVERITY", "FAILED", "EXCLUDED", "ALL", "% RISK-SCORE"}
+}
+
+func generatePdfRow(controlSummary reportsummary.IControlSummary) []string {
+	row := generateRow(controlSummary)
+	return append([]string{row[0], cautils.ControlSeverityToString(controlSummary.GetScoreFactor())}, row[1:]...)
+}
+
+// controlStatusColor returns the background color, text color and style of the severity cell:
+// failed controls by the severity, passed controls in green and otherwise the row background
+func controlStatusColor(controlSummary reportsummary.IControlSummary, rowBackground color.Color) (color.Color, color.Color, consts.Style) {
+	status := controlSummary.GetStatus()
+	switch {
+	case status.IsFailed():
+		return severityColor(cautils.ControlSeverityToString(controlSummary.GetScoreFactor())), color.NewWhite(), consts.Bold
+	case status.IsPassed():
+		return color.Color{Red: 200, Green: 230, Blue: 201}, color.NewBlack(), consts.Normal
+	}
+	return rowBackground, color.NewBlack(), consts.Normal
+}
+
 // Add final results.
 func (pdfPrinter *PdfPrinter) printFinalResult(m pdf.Maroto, summaryDetails *reportsummary.SummaryDetails) {
 	m.Row(5, func() {
@@ -200,3 +348,323 @@ func (pdfPrinter *PdfPrinter) printFinalResult(m pdf.Maroto, summaryDetails *rep
 		})
 	})
 }
+
+// Print a detail section with the evidence (failed resources) of every failed control.
+func (pdfPrinter *PdfPrinter) printControlsDetails(m pdf.Maroto, opaSessionObj *cautils.OPASessionObj) {
+	controls := &opaSessionObj.Report.SummaryDetails.Controls
+	m.AddPage()
+	m.Row(10, func() {
+		m.Text("Failed controls details", props.Text{
+			Align:  consts.Left,
+			Size:   12.0,
+			Style:  consts.Bold,
+			Family: consts.Arial,
+		})
+	})
+
+	for _, controlName := range pdfPrinter.sortedControlNames {
+		control := controls.GetControl(reportsummary.EControlCriteriaName, controlName)
+		if control == nil || !control.GetStatus().IsFailed() {
+			continue
+		}
+		m.Line(1)
+		m.Row(7, func() {
+			m.Text(fmt.Sprintf("%s - %s (severity: %s)", control.GetID(), control.GetName(), cautils.ControlSeverityToString(control.GetScoreFactor())), props.Text{
+				Align:  consts.Left,
+				Size:   9.0,
+				Style:  consts.Bold,
+				Family: consts.Arial,
+			})
+		})
+		pdfPrinter.printDetailText(m, "Description", control.GetDescription())
+		pdfPrinter.printDetailText(m, "Remediation", control.GetRemediation())
+
+		m.TableList(getFailedResourcesTableHeaders(), generateFailedResourcesRows(control, opaSessionObj.AllResources, opaSessionObj.ResourcesResult), props.TableList{
+			HeaderProp: props.TableListContent{
+				Family:    consts.Arial,
+				Style:     consts.Bold,
+				Size:      7.0,
+				GridSizes: []uint{2, 2, 3, 5},
+			},
+			ContentProp: props.TableListContent{
+				Family:    consts.Courier,
+				Style:     consts.Normal,
+				Size:      7.0,
+				GridSizes: []uint{2, 2, 3, 5},
+			},
+			Align:              consts.Left,
+			HeaderContentSpace: 1.0,
+			Line:               false,
+		})
+		m.Row(3, func() {})
+	}
+}
+
+// Print the passed and the skipped resources of every control, the evidence of the checks (--include-passed).
+func (pdfPrinter *PdfPrinter) printCheckedResources(m pdf.Maroto, opaSessionObj *cautils.OPASessionObj) {
+	controls := &opaSessionObj.Report.SummaryDetails.Controls
+	m.AddPage()
+	m.Row(10, func() {
+		m.Text("Checked resources (passed and skipped)", props.Text{
+			Align:  consts.Left,
+			Size:   12.0,
+			Style:  consts.Bold,
+			Family: consts.Arial,
+		})
+	})
+
+	for _, controlName := range pdfPrinter.sortedControlNames {
+		control := controls.GetControl(reportsummary.EControlCriteriaName, controlName)
+		if control == nil {
+			continue
+		}
+		rows := generateCheckedResourcesRows(control, opaSessionObj.AllResources)
+		if len(rows) == 0 {
+			continue
+		}
+		m.Line(1)
+		m.Row(7, func() {
+			m.Text(fmt.Sprintf("%s - %s (status: %s)", control.GetID(), control.GetName(), control.GetStatus().Status()), props.Text{
+				Align:  consts.Left,
+				Size:   9.0,
+				Style:  consts.Bold,
+				Family: consts.Arial,
+			})
+		})
+		m.TableList(getCheckedResourcesTableHeaders(), rows, props.TableList{
+			HeaderProp: props.TableListContent{
+				Family:    consts.Arial,
+				Style:     consts.Bold,
+				Size:      7.0,
+				GridSizes: []uint{2, 3, 5, 2},
+			},
+			ContentProp: props.TableListContent{
+				Family:    consts.Courier,
+				Style:     consts.Normal,
+				Size:      7.0,
+				GridSizes: []uint{2, 3, 5, 2},
+			},
+			Align:              consts.Left,
+			HeaderContentSpace: 1.0,
+			Line:               false,
+		})
+		m.Row(3, func() {})
+	}
+}
+
+func (pdfPrinter *PdfPrinter) printDetailText(m pdf.Maroto, title, text string) {
+	if text == "" {
+		return
+	}
+	// the text is wrapped by maroto, the row height is an estimation of the number of lines
+	lines := len(text)/150 + 1
+	m.Row(float64(4*lines), func() {
+		m.Col(2, func() {
+			m.Text(title, props.Text{
+				Align:  consts.Left,
+				Size:   8.0,
+				Style:  consts.Bold,
+				Family: consts.Arial,
+			})
+		})
+		m.Col(10, func() {
+			m.Text(text, props.Text{
+				Align:  consts.Left,
+				Size:   8.0,
+				Family: consts.Arial,
+			})
+		})
+	})
+}
+
+// printExpiredExceptions lists the exceptions which were not applied since they expired
+func (pdfPrinter *PdfPrinter) printExpiredExceptions(m pdf.Maroto, expiredExceptions []ExpiredException) {
+	if len(expiredExceptions) == 0 {
+		return
+	}
+	m.Row(10, func() {
+		m.Text("Expired exceptions (not applied, review the risk acceptances)", props.Text{
+			Align:  consts.Left,
+			Size:   10.0,
+			Style:  consts.Bold,
+			Family: consts.Arial,
+		})
+	})
+	rows := [][]string{}
+	for i := range expiredExceptions {
+		rows = append(rows, []string{expiredExceptions[i].Name, expiredExceptions[i].Owner, expiredExceptions[i].Reason, expiredExceptions[i].ExpirationDate})
+	}
+	m.TableList([]string{"EXCEPTION", "OWNER", "REASON", "EXPIRATION DATE"}, rows, props.TableList{
+		HeaderProp: props.TableListContent{
+			Family:    consts.Arial,
+			Style:     consts.Bold,
+			Size:      7.0,
+			GridSizes: []uint{3, 2, 4, 3},
+		},
+		ContentProp: props.TableListContent{
+			Family:    consts.Arial,
+			Style:     consts.Normal,
+			Size:      7.0,
+			GridSizes: []uint{3, 2, 4, 3},
+		},
+		Align:              consts.Left,
+		HeaderContentSpace: 1.0,
+		Line:               false,
+	})
+}
+
+// printDataGaps lists the resources which could not be fetched, the controls requiring them are reported with missing data
+func (pdfPrinter *PdfPrinter) printDataGaps(m pdf.Maroto, dataGaps []DataGap) {
+	if len(dataGaps) == 0 {
+		return
+	}
+	m.Row(10, func() {
+		m.Text("Data gaps (the controls requiring the resources are reported with missing data)", props.Text{
+			Align:  consts.Left,
+			Size:   10.0,
+			Style:  consts.Bold,
+			Family: consts.Arial,
+		})
+	})
+	rows := [][]string{}
+	for i := range dataGaps {
+		rows = append(rows, []string{dataGaps[i].Resource, dataGaps[i].Reason, strings.Join(dataGaps[i].Controls, ", ")})
+	}
+	m.TableList([]string{"RESOURCE", "REASON", "CONTROLS"}, rows, props.TableList{
+		HeaderProp: props.TableListContent{
+			Family:    consts.Arial,
+			Style:     consts.Bold,
+			Size:      7.0,
+			GridSizes: []uint{4, 4, 4},
+		},
+		ContentProp: props.TableListContent{
+			Family:    consts.Arial,
+			Style:     consts.Normal,
+			Size:      7.0,
+			GridSizes: []uint{4, 4, 4},
+		},
+		Align:              consts.Left,
+		HeaderContentSpace: 1.0,
+		Line:               false,
+	})
+}
+
+// printImagesVulnerabilities lists the number of the vulnerabilities of the images by severity
+func (pdfPrinter *PdfPrinter) printImagesVulnerabilities(m pdf.Maroto, images []ImageVulnerabilities) {
+	if len(images) == 0 {
+		return
+	}
+	m.Row(10, func() {
+		m.Text("Image vulnerabilities", props.Text{
+			Align:  consts.Left,
+			Size:   10.0,
+			Style:  consts.Bold,
+			Family: consts.Arial,
+		})
+	})
+	rows := [][]string{}
+	for i := range images {
+		rows = append(rows, []string{images[i].Image, strconv.Itoa(len(images[i].Workloads)), strconv.Itoa(images[i].SeverityCount("Critical")),
+			strconv.Itoa(images[i].SeverityCount("High")), strconv.Itoa(images[i].SeverityCount("Medium")), strconv.Itoa(images[i].SeverityCount("Low"))})
+	}
+	m.TableList([]string{"IMAGE", "WORKLOADS", "CRITICAL", "HIGH", "MEDIUM", "LOW"}, rows, props.TableList{
+		HeaderProp: props.TableListContent{
+			Family:    consts.Arial,
+			Style:     consts.Bold,
+			Size:      7.0,
+			GridSizes: []uint{6, 2, 1, 1, 1, 1},
+		},
+		ContentProp: props.TableListContent{
+			Family:    consts.Arial,
+			Style:     consts.Normal,
+			Size:      7.0,
+			GridSizes: []uint{6, 2, 1, 1, 1, 1},
+		},
+		Align:              consts.Left,
+		HeaderContentSpace: 1.0,
+		Line:               false,
+	})
+}
+
+// printImagesSignatures lists the verifications of the signatures of the images
+func (pdfPrinter *PdfPrinter) printImagesSignatures(m pdf.Maroto, images []ImageSignature) {
+	if len(images) == 0 {
+		return
+	}
+	m.Row(10, func() {
+		m.Text("Image signatures", props.Text{
+			Align:  consts.Left,
+			Size:   10.0,
+			Style:  consts.Bold,
+			Family: consts.Arial,
+		})
+	})
+	rows := [][]string{}
+	for i := range images {
+		status := "Unsigned"
+		switch {
+		case images[i].Verified:
+			status = "Verified"
+		case images[i].Signed:
+			status = "Untrusted"
+		}
+		rows = append(rows, []string{images[i].Image, strconv.Itoa(len(images[i].Workloads)), status, strings.Join(images[i].Signers, ", ")})
+	}
+	m.TableList([]string{"IMAGE", "WORKLOADS", "SIGNATURE", "SIGNERS"}, rows, props.TableList{
+		HeaderProp: props.TableListContent{
+			Family:    consts.Arial,
+			Style:     consts.Bold,
+			Size:      7.0,
+			GridSizes: []uint{5, 2, 2, 3},
+		},
+		ContentProp: props.TableListContent{
+			Family:    consts.Arial,
+			Style:     consts.Normal,
+			Size:      7.0,
+			GridSizes: []uint{5, 2, 2, 3},
+		},
+		Align:              consts.Left,
+		HeaderContentSpace: 1.0,
+		Line:               false,
+	})
+}
+
+func getFailedResourcesTableHeaders() []string {
+	return []string{"KIND", "NAMESPACE", "NAME", "FIX"}
+}
+
+// generateFailedResourcesRows returns the rows of the failed resources of the control, with the fix suggestions of the resources
+func generateFailedResourcesRows(control reportsummary.IControlSummary, allResources map[string]workloadinterface.IMetadata, results map[string]resourcesresults.Result) [][]string {
+	rows := [][]string{}
+	for _, resourceID := range control.ListResourcesIDs().Failed() {
+		if resource, ok := allResources[resourceID]; ok {
+			fixes := strings.Join(fixSuggestionsToString(results, resourceID, control.GetID()), "; ")
+			rows = append(rows, []string{resource.GetKind(), resource.GetNamespace(), resource.GetName(), fixes})
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		return strings.Join(rows[i], "/") < strings.Join(rows[j], "/")
+	})
+	return rows
+}
+
+func getCheckedResourcesTableHeaders() []string {
+	return []string{"KIND", "NAMESPACE", "NAME", "STATUS"}
+}
+
+// generateCheckedResourcesRows returns the rows of the passed and the skipped resources of the control
+func generateCheckedResourcesRows(control reportsummary.IControlSummary, allResources map[string]workloadinterface.IMetadata) [][]string {
+	rows := [][]string{}
+	resourcesIDs := control.ListResourcesIDs()
+	for _, status := range []apis.ScanningStatus{apis.StatusPassed, apis.StatusSkipped} {
+		for _, resourceID := range resourceIDsByStatus(resourcesIDs, status) {
+			if resource, ok := allResources[resourceID]; ok {
+				rows = append(rows, []string{resource.GetKind(), resource.GetNamespace(), resource.GetName(), string(status)})
+			}
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		return strings.Join(rows[i], "/") < strings.Join(rows[j], "/")
+	})
+	return rows
+}
@@ -0,0 +1,227 @@
+package v2
+
+import (
+	"bytes"
+	b64 "encoding/base64"
+	"fmt"
+	"image"
+	imagecolor "image/color"
+	"image/png"
+	"sort"
+
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/opa-utils/reporthandling/results/v1/reportsummary"
+	"github.com/johnfercher/maroto/pkg/color"
+	"github.com/johnfercher/maroto/pkg/consts"
+	"github.com/johnfercher/maroto/pkg/pdf"
+	"github.com/johnfercher/maroto/pkg/props"
+)
+
+const (
+	topRiskiestControls = 10
+	barImageWidth       = 400
+	barImageHeight      = 18
+)
+
+var severitiesOrder = []string{cautils.SeverityCritical, cautils.SeverityHigh, cautils.SeverityMedium, cautils.SeverityLow}
+
+func severityColor(severity string) color.Color {
+	switch severity {
+	case cautils.SeverityCritical:
+		return color.Color{Red: 183, Green: 28, Blue: 28}
+	case cautils.SeverityHigh:
+		return color.Color{Red: 239, Green: 108, Blue: 0}
+	case cautils.SeverityMedium:
+		return color.Color{Red: 249, Green: 168, Blue: 37}
+	case cautils.SeverityLow:
+		return color.Color{Red: 67, Green: 160, Blue: 71}
+	}
+	return color.Color{Red: 158, Green: 158, Blue: 158}
+}
+
+// Print the executive summary page - overall score, failed controls by severity and the riskiest controls.
+func (pdfPrinter *PdfPrinter) printExecutiveSummary(m pdf.Maroto, summaryDetails *reportsummary.SummaryDetails) {
+	m.Row(12, func() {
+		m.Text("Executive summary", props.Text{
+			Align:  consts.Left,
+			Size:   14.0,
+			Style:  consts.Bold,
+			Family: consts.Arial,
+		})
+	})
+	m.Row(12, func() {
+		m.Text(fmt.Sprintf("Overall risk-score: %.2f%%", summaryDetails.Score), props.Text{
+			Align:  consts.Left,
+			Size:   12.0,
+			Family: consts.Arial,
+		})
+	})
+	m.Row(8, func() {
+		m.Text(fmt.Sprintf("Failed controls: %d out of %d, failed resources: %d out of %d",
+			summaryDetails.NumberOfControls().Failed(), summaryDetails.NumberOfControls().All(),
+			summaryDetails.NumberOfResources().Failed(), summaryDetails.NumberOfResources().All()), props.Text{
+			Align:  consts.Left,
+			Size:   9.0,
+			Family: consts.Arial,
+		})
+	})
+
+	pdfPrinter.printSeverityChart(m, failedControlsBySeverity(&summaryDetails.Controls))
+	pdfPrinter.printRiskiestControls(m, &summaryDetails.Controls)
+}
+
+// Print a bar per severity with the number of failed controls.
+func (pdfPrinter *PdfPrinter) printSeverityChart(m pdf.Maroto, counts map[string]int) {
+	m.Row(10, func() {
+		m.Text("Failed controls by severity", props.Text{
+			Top:    3,
+			Align:  consts.Left,
+			Size:   10.0,
+			Style:  consts.Bold,
+			Family: consts.Arial,
+		})
+	})
+	max := 0
+	for _, count := range counts {
+		if count > max {
+			max = count
+		}
+	}
+	for _, severity := range severitiesOrder {
+		severity := severity
+		m.Row(7, func() {
+			m.Col(2, func() {
+				m.Text(severity, props.Text{
+					Top:    1,
+					Align:  consts.Left,
+					Size:   9.0,
+					Family: consts.Arial,
+				})
+			})
+			m.Col(8, func() {
+				if bar, err := barImage(counts[severity], max, severityColor(severity)); err == nil {
+					_ = m.Base64Image(bar, consts.Png, props.Rect{
+						Percent: 100,
+					})
+				}
+			})
+			m.Col(2, func() {
+				m.Text(fmt.Sprintf("%d", counts[severity]), props.Text{
+					Top:    1,
+					Align:  consts.Right,
+					Size:   9.0,
+					Style:  consts.Bold,
+					Family: consts.Arial,
+				})
+			})
+		})
+	}
+}
+
+// Print the failed controls with the highest severity and risk-score.
+func (pdfPrinter *PdfPrinter) printRiskiestControls(m pdf.Maroto, controls *reportsummary.ControlSummaries) {
+	riskiest := riskiestControls(controls, topRiskiestControls)
+	if len(riskiest) == 0 {
+		return
+	}
+	m.Row(12, func() {
+		m.Text(fmt.Sprintf("Top %d riskiest controls", len(riskiest)), props.Text{
+			Top:    5,
+			Align:  consts.Left,
+			Size:   10.0,
+			Style:  consts.Bold,
+			Family: consts.Arial,
+		})
+	})
+	rows := make([][]string, 0, len(riskiest))
+	for _, control := range riskiest {
+		rows = append(rows, []string{
+			control.GetID(),
+			control.GetName(),
+			cautils.ControlSeverityToString(control.GetScoreFactor()),
+			fmt.Sprintf("%d", control.NumberOfResources().Failed()),
+			fmt.Sprintf("%d", int(control.GetScore())) + "%",
+		})
+	}
+	gridSizes := []uint{1, 6, 2, 1, 2}
+	m.TableList([]string{"ID", "CONTROL NAME", "SEVERITY", "FAILED", "% RISK-SCORE"}, rows, props.TableList{
+		HeaderProp: props.TableListContent{
+			Family:    consts.Arial,
+			Style:     consts.Bold,
+			Size:      8.0,
+			GridSizes: gridSizes,
+		},
+		ContentProp: props.TableListContent{
+			Family:    consts.Courier,
+			Style:     consts.Normal,
+			Size:      8.0,
+			GridSizes: gridSizes,
+		},
+		Align: consts.Left,
+		AlternatedBackground: &color.Color{
+			Red:   224,
+			Green: 224,
+			Blue:  224,
+		},
+		HeaderContentSpace: 2.0,
+		Line:               false,
+	})
+}
+
+func failedControlsBySeverity(controls *reportsummary.ControlSummaries) map[string]int {
+	counts := map[string]int{}
+	for _, controlID := range controls.ListControlsIDs().Failed() {
+		if control := controls.GetControl(reportsummary.EControlCriteriaID, controlID); control != nil {
+			counts[cautils.ControlSeverityToString(control.GetScoreFactor())]++
+		}
+	}
+	return counts
+}
+
+// riskiestControls returns up to n failed controls, sorted by the severity (score factor) and then by the risk-score
+func riskiestControls(controls *reportsummary.ControlSummaries, n int) []reportsummary.IControlSummary {
+	failed := []reportsummary.IControlSummary{}
+	for _, controlID := range controls.ListControlsIDs().Failed() {
+		if control := controls.GetControl(reportsummary.EControlCriteriaID, controlID); control != nil {
+			failed = append(failed, control)
+		}
+	}
+	sort.Slice(failed, func(i, j int) bool {
+		if failed[i].GetScoreFactor() != failed[j].GetScoreFactor() {
+			return failed[i].GetScoreFactor() > failed[j].GetScoreFactor()
+		}
+		if failed[i].GetScore() != failed[j].GetScore() {
+			return failed[i].GetScore() > failed[j].GetScore()
+		}
+		return failed[i].GetID() < failed[j].GetID()
+	})
+	if len(failed) > n {
+		failed = failed[:n]
+	}
+	return failed
+}
+
+// barImage returns a base64 encoded png of a horizontal bar, the colored part is value/max of the width
+func barImage(value, max int, c color.Color) (string, error) {
+	img := image.NewRGBA(image.Rect(0, 0, barImageWidth, barImageHeight))
+	filled := 0
+	if max > 0 {
+		filled = barImageWidth * value / max
+	}
+	fill := imagecolor.RGBA{R: uint8(c.Red), G: uint8(c.Green), B: uint8(c.Blue), A: 255}
+	background := imagecolor.RGBA{R: 238, G: 238, B: 238, A: 255}
+	for x := 0; x < barImageWidth; x++ {
+		for y := 0; y < barImageHeight; y++ {
+			if x < filled {
+				img.Set(x, y, fill)
+			} else {
+				img.Set(x, y, background)
+			}
+		}
+	}
+	buf := &bytes.Buffer{}
+	if err := png.Encode(buf, img); err != nil {
+		return "", err
+	}
+	return b64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
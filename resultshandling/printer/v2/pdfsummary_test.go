@@ -0,0 +1,47 @@
+package v2
+
+import (
+	"testing"
+
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/opa-utils/reporthandling/apis"
+	"github.com/armosec/opa-utils/reporthandling/results/v1/reportsummary"
+)
+
+func mockControlSummaries() reportsummary.ControlSummaries {
+	return reportsummary.ControlSummaries{
+		"C-0001": {ControlID: "C-0001", Name: "a", Status: apis.StatusFailed, ScoreFactor: 9, Score: 10},
+		"C-0002": {ControlID: "C-0002", Name: "b", Status: apis.StatusFailed, ScoreFactor: 7, Score: 90},
+		"C-0003": {ControlID: "C-0003", Name: "c", Status: apis.StatusFailed, ScoreFactor: 9, Score: 50},
+		"C-0004": {ControlID: "C-0004", Name: "d", Status: apis.StatusPassed, ScoreFactor: 10},
+		"C-0005": {ControlID: "C-0005", Name: "e", Status: apis.StatusFailed, ScoreFactor: 1, Score: 100},
+	}
+}
+
+func TestFailedControlsBySeverity(t *testing.T) {
+	controls := mockControlSummaries()
+	counts := failedControlsBySeverity(&controls)
+	expected := map[string]int{cautils.SeverityCritical: 2, cautils.SeverityHigh: 1, cautils.SeverityLow: 1}
+	if len(counts) != len(expected) {
+		t.Fatalf("expected %v, received %v", expected, counts)
+	}
+	for severity, count := range expected {
+		if counts[severity] != count {
+			t.Errorf("severity %s: expected %d, received %d", severity, count, counts[severity])
+		}
+	}
+}
+
+func TestRiskiestControls(t *testing.T) {
+	controls := mockControlSummaries()
+	riskiest := riskiestControls(&controls, 3)
+	expected := []string{"C-0003", "C-0001", "C-0002"}
+	if len(riskiest) != len(expected) {
+		t.Fatalf("expected %d controls, received %d", len(expected), len(riskiest))
+	}
+	for i := range expected {
+		if riskiest[i].GetID() != expected[i] {
+			t.Errorf("index %d: expected %s, received %s", i, expected[i], riskiest[i].GetID())
+		}
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/armosec/opa-utils/objectsenvelopes"
 	"github.com/armosec/opa-utils/reporthandling/apis"
 	"github.com/armosec/opa-utils/reporthandling/results/v1/reportsummary"
+	"github.com/armosec/opa-utils/reporthandling/results/v1/resourcesresults"
 	"github.com/enescakir/emoji"
 	"github.com/olekukonko/tablewriter"
 )
@@ -21,6 +22,8 @@ type PrettyPrinter struct {
 	writer             *os.File
 	verboseMode        bool
 	sortedControlNames []string
+	resourceSource     map[string]cautils.ResourceSource
+	resourcesResult    map[string]resourcesresults.Result
 }
 
 func NewPrettyPrinter(verboseMode bool, formatVersion string) *PrettyPrinter {
@@ -30,8 +33,10 @@ func NewPrettyPrinter(verboseMode bool, formatVersion string) *PrettyPrinter {
 	}
 }
 
-func (prettyPrinter *PrettyPrinter) ActionPrint(opaSessionObj *cautils.OPASessionObj) {
+func (prettyPrinter *PrettyPrinter) ActionPrint(opaSessionObj *cautils.OPASessionObj) error {
 	prettyPrinter.sortedControlNames = getSortedControlsNames(opaSessionObj.Report.SummaryDetails.Controls) // ListControls().All())
+	prettyPrinter.resourceSource = opaSessionObj.ResourceSource
+	prettyPrinter.resourcesResult = opaSessionObj.ResourcesResult
 
 	if prettyPrinter.formatVersion == "v1" {
 		prettyPrinter.printResults(&opaSessionObj.Report.SummaryDetails.Controls, opaSessionObj.AllResources)
@@ -39,7 +44,10 @@ func (prettyPrinter *PrettyPrinter) ActionPrint(opaSessionObj *cautils.OPASessio
 		prettyPrinter.resourceTable(opaSessionObj.ResourcesResult, opaSessionObj.AllResources)
 	}
 	prettyPrinter.printSummaryTable(&opaSessionObj.Report.SummaryDetails)
-
+	prettyPrinter.printScoresBreakdown(opaSessionObj.ScoresBreakdown)
+	prettyPrinter.printExpiredExceptions(listExpiredExceptions(opaSessionObj))
+	prettyPrinter.printDataGaps(listDataGaps(opaSessionObj))
+	return nil
 }
 
 func (prettyPrinter *PrettyPrinter) SetWriter(outputFile string) {
@@ -83,6 +91,8 @@ func (prettyPrinter *PrettyPrinter) printTitle(controlSummary reportsummary.ICon
 		cautils.FailureDisplay(prettyPrinter.writer, "failed %v\n", emoji.SadButRelievedFace)
 	case apis.StatusExcluded:
 		cautils.WarningDisplay(prettyPrinter.writer, "excluded %v\n", emoji.NeutralFace)
+	case cautils.StatusMissingData:
+		cautils.WarningDisplay(prettyPrinter.writer, "missing data %v\n", emoji.NeutralFace)
 	default:
 		cautils.SuccessDisplay(prettyPrinter.writer, "passed %v\n", emoji.ThumbsUp)
 	}
@@ -101,27 +111,33 @@ func (prettyPrinter *PrettyPrinter) printResources(controlSummary reportsummary.
 	}
 	if len(failedWorkloads) > 0 {
 		cautils.FailureDisplay(prettyPrinter.writer, "Failed:\n")
-		prettyPrinter.printGroupedResources(failedWorkloads)
+		prettyPrinter.printGroupedResources(controlSummary.GetID(), failedWorkloads)
 	}
 	if len(excludedWorkloads) > 0 {
 		cautils.WarningDisplay(prettyPrinter.writer, "Excluded:\n")
-		prettyPrinter.printGroupedResources(excludedWorkloads)
+		prettyPrinter.printGroupedResources(controlSummary.GetID(), excludedWorkloads)
 	}
 	if len(passedWorkloads) > 0 {
 		cautils.SuccessDisplay(prettyPrinter.writer, "Passed:\n")
-		prettyPrinter.printGroupedResources(passedWorkloads)
+		prettyPrinter.printGroupedResources(controlSummary.GetID(), passedWorkloads)
 	}
 
 }
 
-func (prettyPrinter *PrettyPrinter) printGroupedResources(workloads map[string][]WorkloadSummary) {
+func (prettyPrinter *PrettyPrinter) printGroupedResources(controlID string, workloads map[string][]WorkloadSummary) {
 	indent := "  "
-	for title, rsc := range workloads {
-		prettyPrinter.printGroupedResource(indent, title, rsc)
+	titles := make([]string, 0, len(workloads))
+	for title := range workloads {
+		titles = append(titles, title)
+	}
+	sort.Strings(titles)
+	for _, title := range titles {
+		prettyPrinter.printGroupedResource(indent, title, controlID, workloads[title])
 	}
 }
 
-func (prettyPrinter *PrettyPrinter) printGroupedResource(indent string, title string, rsc []WorkloadSummary) {
+// printGroupedResource prints the resources of a group, the failed resources are followed by the fix suggestions of the control
+func (prettyPrinter *PrettyPrinter) printGroupedResource(indent string, title string, controlID string, rsc []WorkloadSummary) {
 	preIndent := indent
 	if title != "" {
 		cautils.SimpleDisplay(prettyPrinter.writer, "%s%s\n", indent, title)
@@ -131,7 +147,13 @@ func (prettyPrinter *PrettyPrinter) printGroupedResource(indent string, title st
 	resources := []string{}
 	for r := range rsc {
 		relatedObjectsStr := generateRelatedObjectsStr(rsc[r]) // TODO -
-		resources = append(resources, fmt.Sprintf("%s%s - %s %s", indent, rsc[r].resource.GetKind(), rsc[r].resource.GetName(), relatedObjectsStr))
+		resource := fmt.Sprintf("%s%s - %s%s %s", indent, rsc[r].resource.GetKind(), rsc[r].resource.GetName(), prettyPrinter.sourceLocation(rsc[r].resource.GetID()), relatedObjectsStr)
+		if workloadSummaryFailed(&rsc[r]) {
+			for _, fix := range fixSuggestionsToString(prettyPrinter.resourcesResult, rsc[r].resource.GetID(), controlID) {
+				resource += fmt.Sprintf("\n%s  fix: %s", indent, fix)
+			}
+		}
+		resources = append(resources, resource)
 	}
 
 	sort.Strings(resources)
@@ -142,6 +164,18 @@ func (prettyPrinter *PrettyPrinter) printGroupedResource(indent string, title st
 	indent = preIndent
 }
 
+// sourceLocation returns " (<file>:<line>)" of the resources loaded from files, empty for cluster resources
+func (prettyPrinter *PrettyPrinter) sourceLocation(resourceID string) string {
+	source, ok := prettyPrinter.resourceSource[resourceID]
+	if !ok || source.Path == "" {
+		return ""
+	}
+	if source.Line > 0 {
+		return fmt.Sprintf(" (%s:%d)", relativeSourcePath(source.Path), source.Line)
+	}
+	return fmt.Sprintf(" (%s)", relativeSourcePath(source.Path))
+}
+
 func generateRelatedObjectsStr(workload WorkloadSummary) string {
 	relatedStr := ""
 	if workload.resource.GetObjectType() == workloadinterface.TypeWorkloadObject {
@@ -191,6 +225,54 @@ func (prettyPrinter *PrettyPrinter) printSummaryTable(summaryDetails *reportsumm
 	cautils.InfoTextDisplay(prettyPrinter.writer, frameworksScoresToString(summaryDetails.ListFrameworks().All()))
 }
 
+// printScoresBreakdown prints the risk-scores by namespace when several namespaces were scanned, and by owner
+func (prettyPrinter *PrettyPrinter) printScoresBreakdown(breakdown *cautils.ScoresBreakdown) {
+	if breakdown == nil {
+		return
+	}
+	if len(breakdown.Namespaces) > 1 {
+		prettyPrinter.printGroupsScores("NAMESPACE", breakdown.Namespaces)
+	}
+	if len(breakdown.Owners) > 0 {
+		prettyPrinter.printGroupsScores(strings.ToUpper(breakdown.OwnerLabel), breakdown.Owners)
+	}
+}
+
+func (prettyPrinter *PrettyPrinter) printGroupsScores(title string, scores []cautils.GroupScore) {
+	cautils.InfoTextDisplay(prettyPrinter.writer, "\n")
+	table := tablewriter.NewWriter(prettyPrinter.writer)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{title, "Failed Controls", "Failed Resources", "All Resources", "% risk-score"})
+	table.SetHeaderLine(true)
+	table.SetColumnAlignment([]int{tablewriter.ALIGN_LEFT, tablewriter.ALIGN_CENTER, tablewriter.ALIGN_CENTER, tablewriter.ALIGN_CENTER, tablewriter.ALIGN_CENTER})
+	for i := range scores {
+		table.Append([]string{scores[i].Name, fmt.Sprintf("%d", scores[i].FailedControls), fmt.Sprintf("%d", scores[i].FailedResources), fmt.Sprintf("%d", scores[i].Resources), fmt.Sprintf("%.2f%s", scores[i].Score, "%")})
+	}
+	table.Render()
+}
+
+// printExpiredExceptions lists the exceptions which were not applied since they expired, the exceptions should be reviewed
+func (prettyPrinter *PrettyPrinter) printExpiredExceptions(expiredExceptions []ExpiredException) {
+	if len(expiredExceptions) == 0 {
+		return
+	}
+	cautils.WarningDisplay(prettyPrinter.writer, "\nExpired exceptions (not applied, review the risk acceptances):\n")
+	for i := range expiredExceptions {
+		cautils.WarningDisplay(prettyPrinter.writer, "  * %s\n", expiredExceptions[i].String())
+	}
+}
+
+// printDataGaps lists the resources which could not be fetched, the controls requiring them are not counted as passed
+func (prettyPrinter *PrettyPrinter) printDataGaps(dataGaps []DataGap) {
+	if len(dataGaps) == 0 {
+		return
+	}
+	cautils.WarningDisplay(prettyPrinter.writer, "\nData gaps (the resources could not be fetched, the controls requiring them are reported with missing data):\n")
+	for i := range dataGaps {
+		cautils.WarningDisplay(prettyPrinter.writer, "  * %s\n", dataGaps[i].String())
+	}
+}
+
 func frameworksScoresToString(frameworks []reportsummary.IPolicies) string {
 	if len(frameworks) == 1 {
 		if frameworks[0].GetName() != "" {
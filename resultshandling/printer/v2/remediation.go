@@ -0,0 +1,102 @@
+package v2
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/opa-utils/reporthandling/results/v1/reportsummary"
+	"github.com/armosec/opa-utils/reporthandling/results/v1/resourcesresults"
+)
+
+// FixSuggestion an actionable fix of a failed resource - the field to change and its recommended value
+type FixSuggestion struct {
+	Path  string `json:"path"`            // e.g. spec.template.spec.containers[0].securityContext.runAsNonRoot
+	Value string `json:"value,omitempty"` // the recommended value, empty when the value of the field failed and it should be changed or removed
+}
+
+// Remediation the remediation of a failed control and the fix suggestions of its failed resources
+type Remediation struct {
+	ControlID   string                `json:"controlID"`
+	Name        string                `json:"name"`
+	Remediation string                `json:"remediation,omitempty"`
+	Resources   []ResourceRemediation `json:"resources"` // sorted by resource ID
+}
+
+// ResourceRemediation the fix suggestions of a failed resource of a control
+type ResourceRemediation struct {
+	ResourceID string          `json:"resourceID"`
+	Fixes      []FixSuggestion `json:"fixes"`
+}
+
+// String returns a one line description of the fix suggestion
+func (fixSuggestion *FixSuggestion) String() string {
+	if fixSuggestion.Value == "" {
+		return fmt.Sprintf("change or remove %s", fixSuggestion.Path)
+	}
+	return fmt.Sprintf("set %s to %s", fixSuggestion.Path, fixSuggestion.Value)
+}
+
+// listRemediations returns the remediations of the failed controls of the session, sorted by control ID
+func listRemediations(opaSessionObj *cautils.OPASessionObj) []Remediation {
+	controls := &opaSessionObj.Report.SummaryDetails.Controls
+	controlIDs := controls.ListControlsIDs().All()
+	sort.Strings(controlIDs)
+
+	remediations := []Remediation{}
+	for _, controlID := range controlIDs {
+		control := controls.GetControl(reportsummary.EControlCriteriaID, controlID)
+		if control == nil || !control.GetStatus().IsFailed() {
+			continue
+		}
+		remediation := Remediation{ControlID: controlID, Name: control.GetName(), Remediation: control.GetRemediation(), Resources: []ResourceRemediation{}}
+		resourcesIDs := control.ListResourcesIDs().Failed()
+		sort.Strings(resourcesIDs)
+		for _, resourceID := range resourcesIDs {
+			result, ok := opaSessionObj.ResourcesResult[resourceID]
+			if !ok {
+				continue
+			}
+			remediation.Resources = append(remediation.Resources, ResourceRemediation{ResourceID: resourceID, Fixes: listFixSuggestions(&result, controlID)})
+		}
+		remediations = append(remediations, remediation)
+	}
+	return remediations
+}
+
+// listFixSuggestions returns the fix suggestions of a control of a resource - the fix paths and the failed paths of its failed rules
+func listFixSuggestions(result *resourcesresults.Result, controlID string) []FixSuggestion {
+	fixSuggestions := []FixSuggestion{}
+	suggested := map[FixSuggestion]bool{}
+	for _, rule := range result.ListRulesOfControl(controlID, "") {
+		if !rule.GetStatus(nil).IsFailed() {
+			continue
+		}
+		for _, path := range rule.Paths {
+			fixSuggestion := FixSuggestion{Path: path.FixPath.Path, Value: path.FixPath.Value}
+			if fixSuggestion.Path == "" {
+				fixSuggestion = FixSuggestion{Path: path.FailedPath}
+			}
+			if fixSuggestion.Path == "" || suggested[fixSuggestion] {
+				continue
+			}
+			suggested[fixSuggestion] = true
+			fixSuggestions = append(fixSuggestions, fixSuggestion)
+		}
+	}
+	return fixSuggestions
+}
+
+// fixSuggestionsToString returns the descriptions of the fix suggestions of a control of a resource
+func fixSuggestionsToString(results map[string]resourcesresults.Result, resourceID, controlID string) []string {
+	result, ok := results[resourceID]
+	if !ok {
+		return nil
+	}
+	fixSuggestions := listFixSuggestions(&result, controlID)
+	descriptions := make([]string, 0, len(fixSuggestions))
+	for i := range fixSuggestions {
+		descriptions = append(descriptions, fixSuggestions[i].String())
+	}
+	return descriptions
+}
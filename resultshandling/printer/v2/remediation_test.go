@@ -0,0 +1,93 @@
+package v2
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/armosec/armoapi-go/armotypes"
+	"github.com/armosec/k8s-interface/workloadinterface"
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/opa-utils/reporthandling/apis"
+	"github.com/armosec/opa-utils/reporthandling/results/v1/reportsummary"
+	"github.com/armosec/opa-utils/reporthandling/results/v1/resourcesresults"
+)
+
+func mockRemediationsSession() *cautils.OPASessionObj {
+	opaSessionObj := cautils.NewOPASessionObjMock()
+	resourceID := "apps/v1/prod/Deployment/web"
+	opaSessionObj.AllResources[resourceID] = workloadinterface.NewWorkloadObj(map[string]interface{}{
+		"apiVersion": "apps/v1", "kind": "Deployment", "metadata": map[string]interface{}{"name": "web", "namespace": "prod"},
+	})
+	opaSessionObj.ResourcesResult[resourceID] = resourcesresults.Result{ResourceID: resourceID, AssociatedControls: []resourcesresults.ResourceAssociatedControl{
+		{ControlID: "C-0013", ResourceAssociatedRules: []resourcesresults.ResourceAssociatedRule{
+			{Name: "non-root-containers", Status: apis.StatusFailed, Paths: []armotypes.PosturePaths{
+				{FixPath: armotypes.FixPath{Path: "spec.template.spec.containers[0].securityContext.runAsNonRoot", Value: "true"}},
+				{FixPath: armotypes.FixPath{Path: "spec.template.spec.containers[0].securityContext.runAsNonRoot", Value: "true"}},
+			}},
+			{Name: "passed-rule", Status: apis.StatusPassed, Paths: []armotypes.PosturePaths{{FailedPath: "spec.template.spec.securityContext"}}},
+		}},
+		{ControlID: "C-0046", ResourceAssociatedRules: []resourcesresults.ResourceAssociatedRule{
+			{Name: "insecure-capabilities", Status: apis.StatusFailed, Paths: []armotypes.PosturePaths{{FailedPath: "spec.template.spec.containers[0].securityContext.capabilities.add[0]"}}},
+		}},
+	}}
+	control := reportsummary.ControlSummary{ControlID: "C-0013", Name: "Non-root containers", Status: apis.StatusFailed, Remediation: "Set runAsNonRoot to true"}
+	control.ResourceIDs.Append(apis.StatusFailed, resourceID)
+	opaSessionObj.Report.SummaryDetails.Controls = reportsummary.ControlSummaries{
+		"C-0013": control,
+		"C-0016": {ControlID: "C-0016", Name: "Allow privilege escalation", Status: apis.StatusPassed},
+	}
+	return opaSessionObj
+}
+
+func TestListRemediations(t *testing.T) {
+	remediations := listRemediations(mockRemediationsSession())
+	if len(remediations) != 1 || remediations[0].ControlID != "C-0013" || remediations[0].Remediation != "Set runAsNonRoot to true" {
+		t.Fatalf("expected the remediation of the failed control, received %+v", remediations)
+	}
+	resources := remediations[0].Resources
+	expected := FixSuggestion{Path: "spec.template.spec.containers[0].securityContext.runAsNonRoot", Value: "true"}
+	if len(resources) != 1 || len(resources[0].Fixes) != 1 || resources[0].Fixes[0] != expected {
+		t.Errorf("expected a fix suggestion of the failed rule, received %+v", resources)
+	}
+	if s := resources[0].Fixes[0].String(); s != "set spec.template.spec.containers[0].securityContext.runAsNonRoot to true" {
+		t.Errorf("unexpected description: %s", s)
+	}
+
+	fixes := fixSuggestionsToString(mockRemediationsSession().ResourcesResult, "apps/v1/prod/Deployment/web", "C-0046")
+	if len(fixes) != 1 || fixes[0] != "change or remove spec.template.spec.containers[0].securityContext.capabilities.add[0]" {
+		t.Errorf("unexpected fix suggestions: %v", fixes)
+	}
+}
+
+func TestRemediationsOutput(t *testing.T) {
+	opaSessionObj := mockRemediationsSession()
+
+	report, err := GenerateJson(opaSessionObj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jsonReport := struct {
+		Remediations []Remediation `json:"remediations"`
+	}{}
+	if err := json.Unmarshal(report, &jsonReport); err != nil {
+		t.Fatal(err)
+	}
+	if len(jsonReport.Remediations) != 1 || len(jsonReport.Remediations[0].Resources) != 1 {
+		t.Errorf("expected the remediations in the json report, got: %s", string(report))
+	}
+
+	data := NewTemplateData(opaSessionObj)
+	if len(data.Controls) != 2 || len(data.Controls[0].FailedResources) != 1 || len(data.Controls[0].FailedResources[0].Fixes) != 1 {
+		t.Errorf("expected the fix suggestions of the failed resources: %+v", data.Controls)
+	}
+
+	html := &bytes.Buffer{}
+	if err := GenerateHtml(html, opaSessionObj); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(html.String(), "set <code>spec.template.spec.containers[0].securityContext.runAsNonRoot</code> to <code>true</code>") {
+		t.Errorf("expected the fix suggestions in the html report: %s", html.String())
+	}
+}
@@ -27,7 +27,7 @@ func (prettyPrinter *PrettyPrinter) resourceTable(results map[string]resourcesre
 			continue
 		}
 		s := results[i]
-		if raw := generateResourceRows(resource, s.ListControls(), prettyPrinter.verboseMode); len(raw) > 0 {
+		if raw := generateResourceRows(resource, s.ListControls(), prettyPrinter.verboseMode, prettyPrinter.sourceLocation(i)); len(raw) > 0 {
 			data = append(data, raw...)
 		}
 	}
@@ -37,7 +37,7 @@ func (prettyPrinter *PrettyPrinter) resourceTable(results map[string]resourcesre
 	summaryTable.Render()
 }
 
-func generateResourceRows(resource workloadinterface.IMetadata, controls []resourcesresults.ResourceAssociatedControl, verboseMode bool) [][]string {
+func generateResourceRows(resource workloadinterface.IMetadata, controls []resourcesresults.ResourceAssociatedControl, verboseMode bool, sourceLocation string) [][]string {
 	rows := [][]string{}
 
 	for i := range controls {
@@ -55,7 +55,7 @@ func generateResourceRows(resource workloadinterface.IMetadata, controls []resou
 		row = append(row, resource.GetNamespace())
 		paths := failedPathsToString(&controls[i])
 
-		row = append(row, fmt.Sprintf("%s/%s\n%s", resource.GetKind(), resource.GetName(), strings.Join(paths, ";\n")))
+		row = append(row, fmt.Sprintf("%s/%s%s\n%s", resource.GetKind(), resource.GetName(), sourceLocation, strings.Join(paths, ";\n")))
 		row = append(row, string(controls[i].GetStatus(nil).Status()))
 		rows = append(rows, row)
 	}
@@ -83,17 +83,19 @@ func (a Matrix) Less(i, j int) bool {
 	return true
 }
 
+// failedPathsToString returns the fix suggestions of the failed and fix paths of the control, e.g. "set <path> to <value>"
 func failedPathsToString(control *resourcesresults.ResourceAssociatedControl) []string {
 	var paths []string
 
 	for j := range control.ResourceAssociatedRules {
 		for k := range control.ResourceAssociatedRules[j].Paths {
 			if p := control.ResourceAssociatedRules[j].Paths[k].FailedPath; p != "" {
-				paths = append(paths, p)
+				fixSuggestion := FixSuggestion{Path: p}
+				paths = append(paths, fixSuggestion.String())
 			}
 			if p := control.ResourceAssociatedRules[j].Paths[k].FixPath.Path; p != "" {
-				v := control.ResourceAssociatedRules[j].Paths[k].FixPath.Value
-				paths = append(paths, fmt.Sprintf("%s=%s", p, v))
+				fixSuggestion := FixSuggestion{Path: p, Value: control.ResourceAssociatedRules[j].Paths[k].FixPath.Value}
+				paths = append(paths, fixSuggestion.String())
 			}
 		}
 	}
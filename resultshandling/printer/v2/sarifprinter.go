@@ -0,0 +1,289 @@
+package v2
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/armosec/k8s-interface/workloadinterface"
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/resultshandling/printer"
+	"github.com/armosec/opa-utils/reporthandling/results/v1/reportsummary"
+)
+
+// https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
+
+const (
+	sarifSchemaURI = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion   = "2.1.0"
+)
+
+type SarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []SarifRun `json:"runs"`
+}
+
+type SarifRun struct {
+	Tool        SarifTool         `json:"tool"`
+	Invocations []SarifInvocation `json:"invocations,omitempty"`
+	Results     []SarifResult     `json:"results"`
+}
+
+// SarifInvocation reports the start and the end of the scan, and the expired exceptions of the scan as tool execution notifications
+type SarifInvocation struct {
+	ExecutionSuccessful        bool                `json:"executionSuccessful"`
+	StartTimeUtc               string              `json:"startTimeUtc,omitempty"`
+	EndTimeUtc                 string              `json:"endTimeUtc,omitempty"`
+	ToolExecutionNotifications []SarifNotification `json:"toolExecutionNotifications,omitempty"`
+}
+
+type SarifNotification struct {
+	Level   string       `json:"level"`
+	Message SarifMessage `json:"message"`
+}
+
+type SarifTool struct {
+	Driver SarifDriver `json:"driver"`
+}
+
+type SarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Version        string      `json:"version,omitempty"`
+	Rules          []SarifRule `json:"rules"`
+}
+
+// SarifRule represents a single control
+type SarifRule struct {
+	ID                   string                 `json:"id"`
+	Name                 string                 `json:"name"`
+	ShortDescription     SarifMessage           `json:"shortDescription"`
+	FullDescription      SarifMessage           `json:"fullDescription"`
+	Help                 SarifMessage           `json:"help"`
+	HelpURI              string                 `json:"helpUri"`
+	DefaultConfiguration SarifRuleConfiguration `json:"defaultConfiguration"`
+	Properties           SarifRuleProperties    `json:"properties"`
+}
+
+type SarifRuleConfiguration struct {
+	Level string `json:"level"`
+}
+
+type SarifRuleProperties struct {
+	SecuritySeverity string   `json:"security-severity"` // used by GitHub code scanning for the severity
+	Tags             []string `json:"tags"`
+}
+
+// SarifResult represents a single failed resource of a control
+type SarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   SarifMessage    `json:"message"`
+	Locations []SarifLocation `json:"locations"`
+}
+
+type SarifMessage struct {
+	Text string `json:"text"`
+}
+
+type SarifLocation struct {
+	PhysicalLocation *SarifPhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []SarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type SarifPhysicalLocation struct {
+	ArtifactLocation SarifArtifactLocation `json:"artifactLocation"`
+	Region           *SarifRegion          `json:"region,omitempty"`
+}
+
+type SarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type SarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+type SarifLogicalLocation struct {
+	Name               string `json:"name"`
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+type SarifPrinter struct {
+	writer *os.File
+}
+
+func NewSarifPrinter() *SarifPrinter {
+	return &SarifPrinter{}
+}
+
+func (sarifPrinter *SarifPrinter) SetWriter(outputFile string) {
+	sarifPrinter.writer = printer.GetWriter(outputFile)
+}
+
+func (sarifPrinter *SarifPrinter) Score(score float32) {
+	printer.LogScore(score)
+}
+
+func (sarifPrinter *SarifPrinter) ActionPrint(opaSessionObj *cautils.OPASessionObj) error {
+	if err := encodeSarif(sarifPrinter.writer, opaSessionObj); err != nil {
+		return err
+	}
+
+	logOUtputFile(sarifPrinter.writer.Name())
+	return nil
+}
+
+func encodeSarif(writer io.Writer, opaSessionObj *cautils.OPASessionObj) error {
+	r, err := json.MarshalIndent(sarifResults(opaSessionObj), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to Marshal sarif result object: %w", err)
+	}
+	_, err = writer.Write(r)
+	return err
+}
+
+func sarifResults(opaSessionObj *cautils.OPASessionObj) *SarifLog {
+	run := SarifRun{
+		Tool: SarifTool{
+			Driver: SarifDriver{
+				Name:           "kubescape",
+				InformationURI: "https://github.com/armosec/kubescape",
+				Version:        cautils.BuildNumber,
+				Rules:          []SarifRule{},
+			},
+		},
+		Results: []SarifResult{},
+	}
+
+	controls := opaSessionObj.Report.SummaryDetails.Controls
+	controlIDs := controls.ListControlsIDs().Failed()
+	sort.Strings(controlIDs)
+
+	for _, controlID := range controlIDs {
+		control := controls.GetControl(reportsummary.EControlCriteriaID, controlID)
+		if control == nil {
+			continue
+		}
+		ruleIndex := len(run.Tool.Driver.Rules)
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule(control))
+
+		resourceIDs := control.ListResourcesIDs().Failed()
+		sort.Strings(resourceIDs)
+		for _, resourceID := range resourceIDs {
+			resource, ok := opaSessionObj.AllResources[resourceID]
+			if !ok {
+				continue
+			}
+			run.Results = append(run.Results, SarifResult{
+				RuleID:    control.GetID(),
+				RuleIndex: ruleIndex,
+				Level:     severityToSarifLevel(cautils.ControlSeverityToString(control.GetScoreFactor())),
+				Message: SarifMessage{
+					Text: fmt.Sprintf("%s: %s failed control '%s'", control.GetID(), resourceFullName(resource), control.GetName()),
+				},
+				Locations: []SarifLocation{sarifLocation(resource, opaSessionObj.ResourceSource)},
+			})
+		}
+	}
+
+	expiredExceptions := listExpiredExceptions(opaSessionObj)
+	if !opaSessionObj.ScanTimes.Start.IsZero() || len(expiredExceptions) > 0 {
+		// the times of the invocations are in UTC by the SARIF specification
+		utc := cautils.ScanTimes{Location: time.UTC}
+		invocation := SarifInvocation{ExecutionSuccessful: true, StartTimeUtc: utc.Format(opaSessionObj.ScanTimes.Start), EndTimeUtc: utc.Format(opaSessionObj.ScanTimes.End)}
+		for i := range expiredExceptions {
+			invocation.ToolExecutionNotifications = append(invocation.ToolExecutionNotifications, SarifNotification{
+				Level:   "warning",
+				Message: SarifMessage{Text: fmt.Sprintf("The %s and is not applied, review the risk acceptance", expiredExceptions[i].String())},
+			})
+		}
+		run.Invocations = []SarifInvocation{invocation}
+	}
+
+	return &SarifLog{
+		Schema:  sarifSchemaURI,
+		Version: sarifVersion,
+		Runs:    []SarifRun{run},
+	}
+}
+
+func sarifRule(control reportsummary.IControlSummary) SarifRule {
+	severity := cautils.ControlSeverityToString(control.GetScoreFactor())
+	description := control.GetDescription()
+	if description == "" {
+		description = control.GetName()
+	}
+	return SarifRule{
+		ID:               control.GetID(),
+		Name:             control.GetName(),
+		ShortDescription: SarifMessage{Text: control.GetName()},
+		FullDescription:  SarifMessage{Text: description},
+		Help:             SarifMessage{Text: fmt.Sprintf("Remediation: %s", control.GetRemediation())},
+		HelpURI:          getControlURL(control.GetID()),
+		DefaultConfiguration: SarifRuleConfiguration{
+			Level: severityToSarifLevel(severity),
+		},
+		Properties: SarifRuleProperties{
+			SecuritySeverity: fmt.Sprintf("%.1f", control.GetScoreFactor()),
+			Tags:             []string{"security", "kubernetes", severity},
+		},
+	}
+}
+
+func sarifLocation(resource workloadinterface.IMetadata, resourceSource map[string]cautils.ResourceSource) SarifLocation {
+	location := SarifLocation{
+		LogicalLocations: []SarifLogicalLocation{
+			{
+				Name:               resource.GetName(),
+				FullyQualifiedName: resourceFullName(resource),
+				Kind:               resource.GetKind(),
+			},
+		},
+	}
+	if source, ok := resourceSource[resource.GetID()]; ok {
+		location.PhysicalLocation = &SarifPhysicalLocation{
+			ArtifactLocation: SarifArtifactLocation{URI: relativeSourcePath(source.Path)},
+		}
+		if source.Line > 0 {
+			location.PhysicalLocation.Region = &SarifRegion{StartLine: source.Line}
+		}
+	}
+	return location
+}
+
+func severityToSarifLevel(severity string) string {
+	switch severity {
+	case cautils.SeverityCritical, cautils.SeverityHigh:
+		return "error"
+	case cautils.SeverityMedium:
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// resourceFullName returns <namespace>/<kind>/<name>, the namespace is omitted for cluster scoped resources
+func resourceFullName(resource workloadinterface.IMetadata) string {
+	if ns := resource.GetNamespace(); ns != "" {
+		return fmt.Sprintf("%s/%s/%s", ns, resource.GetKind(), resource.GetName())
+	}
+	return fmt.Sprintf("%s/%s", resource.GetKind(), resource.GetName())
+}
+
+// relativeSourcePath returns the path relative to the working directory, code scanning tools expect paths relative to the repository root
+func relativeSourcePath(path string) string {
+	if wd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(wd, path); err == nil {
+			path = rel
+		}
+	}
+	return filepath.ToSlash(path)
+}
@@ -0,0 +1,81 @@
+package v2
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/armosec/kubescape/cautils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSarifResults(t *testing.T) {
+	wd, _ := os.Getwd()
+	opaSessionObj := mockMetricsSession()
+	opaSessionObj.ResourceSource = map[string]cautils.ResourceSource{
+		"apps/v1/prod/Deployment/web": {Path: filepath.Join(wd, "manifests", "web.yaml"), Line: 12},
+		"/v1/dev/Pod/debug":           {Path: filepath.Join(wd, "manifests", "debug.yaml")},
+	}
+
+	sarif := sarifResults(opaSessionObj)
+	assert.Equal(t, sarifSchemaURI, sarif.Schema)
+	assert.Equal(t, sarifVersion, sarif.Version)
+	assert.Equal(t, 1, len(sarif.Runs))
+	run := sarif.Runs[0]
+	assert.Equal(t, "kubescape", run.Tool.Driver.Name)
+	assert.Equal(t, 0, len(run.Invocations))
+
+	// only the failed controls are rules, sorted by ID
+	assert.Equal(t, 2, len(run.Tool.Driver.Rules))
+	assert.Equal(t, "C-0013", run.Tool.Driver.Rules[0].ID)
+	assert.Equal(t, "warning", run.Tool.Driver.Rules[0].DefaultConfiguration.Level)
+	assert.Equal(t, "6.0", run.Tool.Driver.Rules[0].Properties.SecuritySeverity)
+	assert.Equal(t, "C-0057", run.Tool.Driver.Rules[1].ID)
+	assert.Equal(t, "error", run.Tool.Driver.Rules[1].DefaultConfiguration.Level)
+
+	// one result per failed resource of a control
+	assert.Equal(t, 4, len(run.Results))
+	for _, result := range run.Results {
+		assert.Equal(t, run.Tool.Driver.Rules[result.RuleIndex].ID, result.RuleID)
+	}
+
+	web := run.Results[3]
+	assert.Equal(t, "C-0057", web.RuleID)
+	assert.Equal(t, "error", web.Level)
+	assert.Equal(t, "C-0057: prod/Deployment/web failed control 'Privileged \"container\"'", web.Message.Text)
+	assert.Equal(t, "manifests/web.yaml", web.Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	assert.Equal(t, &SarifRegion{StartLine: 12}, web.Locations[0].PhysicalLocation.Region)
+	assert.Equal(t, []SarifLogicalLocation{{Name: "web", FullyQualifiedName: "prod/Deployment/web", Kind: "Deployment"}}, web.Locations[0].LogicalLocations)
+
+	debug := run.Results[2]
+	assert.Equal(t, "dev/Pod/debug", debug.Locations[0].LogicalLocations[0].FullyQualifiedName)
+	assert.Equal(t, "manifests/debug.yaml", debug.Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	assert.Nil(t, debug.Locations[0].PhysicalLocation.Region)
+
+	// resources scanned in a cluster have no physical location
+	api := run.Results[0]
+	assert.Equal(t, "C-0013", api.RuleID)
+	assert.Equal(t, "warning", api.Level)
+	assert.Nil(t, api.Locations[0].PhysicalLocation)
+}
+
+func TestEncodeSarif(t *testing.T) {
+	buf := bytes.Buffer{}
+	assert.NoError(t, encodeSarif(&buf, mockMetricsSession()))
+
+	var sarif map[string]interface{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &sarif))
+	assert.Equal(t, sarifSchemaURI, sarif["$schema"])
+	assert.Equal(t, sarifVersion, sarif["version"])
+
+	run := sarif["runs"].([]interface{})[0].(map[string]interface{})
+	assert.NotContains(t, run, "invocations")
+	result := run["results"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "C-0013", result["ruleId"])
+	assert.Equal(t, float64(0), result["ruleIndex"])
+	location := result["locations"].([]interface{})[0].(map[string]interface{})
+	assert.NotContains(t, location, "physicalLocation")
+	assert.Contains(t, location, "logicalLocations")
+}
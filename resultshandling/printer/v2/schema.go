@@ -0,0 +1,40 @@
+package v2
+
+import (
+	_ "embed"
+	"fmt"
+
+	"github.com/armosec/kubescape/cautils"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// ResultsSchemaID the ID of the JSON schema of the json format (v2), the URL of the schema shipped in the repository
+const ResultsSchemaID = "https://raw.githubusercontent.com/armosec/kubescape/master/resultshandling/printer/v2/schemas/results-v2.json"
+
+var (
+	//go:embed schemas/results-v2.json
+	resultsSchema []byte
+)
+
+// GenerateResultsSchema generates the JSON schema of the json format (v2) from the types of the report
+func GenerateResultsSchema() ([]byte, error) {
+	return cautils.GenerateJSONSchema(jsonReport{}, ResultsSchemaID, "Kubescape scan results (--format json --format-version v2)")
+}
+
+// ResultsSchema returns the JSON schema of the json format (v2), shipped in schemas/results-v2.json
+func ResultsSchema() []byte {
+	return resultsSchema
+}
+
+// ValidateResults validates results of the json format (v2) against the JSON schema, and returns the violations of the schema
+func ValidateResults(results []byte) ([]string, error) {
+	result, err := gojsonschema.Validate(gojsonschema.NewBytesLoader(resultsSchema), gojsonschema.NewBytesLoader(results))
+	if err != nil {
+		return nil, fmt.Errorf("failed to validate the results: %w", err)
+	}
+	violations := []string{}
+	for _, violation := range result.Errors() {
+		violations = append(violations, violation.String())
+	}
+	return violations, nil
+}
@@ -0,0 +1,62 @@
+package v2
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/armosec/k8s-interface/workloadinterface"
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/opa-utils/reporthandling/apis"
+	"github.com/armosec/opa-utils/reporthandling/results/v1/reportsummary"
+	"github.com/armosec/opa-utils/reporthandling/results/v1/resourcesresults"
+)
+
+var updateSchema = flag.Bool("update-schema", false, "update schemas/results-v2.json from the types of the report")
+
+func TestResultsSchema(t *testing.T) {
+	schema, err := GenerateResultsSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema = append(schema, '\n')
+	if *updateSchema {
+		if err := os.WriteFile("schemas/results-v2.json", schema, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	if !bytes.Equal(schema, ResultsSchema()) {
+		t.Error("schemas/results-v2.json is outdated, run 'go test ./resultshandling/printer/v2/ -run TestResultsSchema -update-schema'")
+	}
+}
+
+func TestValidateResults(t *testing.T) {
+	opaSessionObj := cautils.NewOPASessionObj(nil, nil)
+	opaSessionObj.Report.ReportID = "report"
+	opaSessionObj.Report.SummaryDetails.Controls = reportsummary.ControlSummaries{
+		"C-0013": {ControlID: "C-0013", Name: "Non-root containers", Status: apis.StatusFailed},
+	}
+	opaSessionObj.ResourcesResult["apps/v1/prod/Deployment/web"] = resourcesresults.Result{ResourceID: "apps/v1/prod/Deployment/web"}
+	opaSessionObj.AllResources["apps/v1/prod/Deployment/web"] = workloadinterface.NewWorkloadObj(map[string]interface{}{"apiVersion": "apps/v1", "kind": "Deployment", "metadata": map[string]interface{}{"name": "web", "namespace": "prod"}})
+	results, err := GenerateJson(opaSessionObj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	violations, err := ValidateResults(results)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(violations) > 0 {
+		t.Errorf("expected valid results, received %v", violations)
+	}
+
+	violations, err = ValidateResults([]byte(`{"reportGUID": 5, "summaryDetails": []}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(violations) == 0 {
+		t.Error("expected violations of the schema")
+	}
+}
@@ -7,5 +7,6 @@ import (
 type SilentPrinter struct {
 }
 
-func (silentPrinter *SilentPrinter) ActionPrint(opaSessionObj *cautils.OPASessionObj) {
+func (silentPrinter *SilentPrinter) ActionPrint(opaSessionObj *cautils.OPASessionObj) error {
+	return nil
 }
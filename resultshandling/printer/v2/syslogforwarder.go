@@ -0,0 +1,106 @@
+package v2
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/cautils/logger/helpers"
+)
+
+// https://datatracker.ietf.org/doc/html/rfc5424
+
+const (
+	syslogDefaultPort  = "514"
+	syslogFacilityUser = 1
+	syslogDialTimeout  = 10 * time.Second
+)
+
+// SyslogForwarder is a printer that sends the CEF events of the scan to a syslog server
+type SyslogForwarder struct {
+	network string
+	address string
+}
+
+// NewSyslogForwarder creates a forwarder from an address in the form of syslog://host:port (UDP) or syslog+tcp://host:port
+func NewSyslogForwarder(forward string) (*SyslogForwarder, error) {
+	network, address, err := parseSyslogURL(forward)
+	if err != nil {
+		return nil, err
+	}
+	return &SyslogForwarder{network: network, address: address}, nil
+}
+
+func (syslogForwarder *SyslogForwarder) SetWriter(outputFile string) {}
+
+func (syslogForwarder *SyslogForwarder) Score(score float32) {}
+
+func (syslogForwarder *SyslogForwarder) ActionPrint(opaSessionObj *cautils.OPASessionObj) error {
+	conn, err := net.DialTimeout(syslogForwarder.network, syslogForwarder.address, syslogDialTimeout)
+	if err != nil {
+		return fmt.Errorf("failed to connect to syslog server '%s': %w", syslogForwarder.address, err)
+	}
+	defer conn.Close()
+
+	hostname, _ := os.Hostname()
+	if hostname == "" {
+		hostname = "-"
+	}
+	timestamp := time.Now().UTC().Format(time.RFC3339)
+
+	events := cefEvents(opaSessionObj)
+	for _, event := range events {
+		message := fmt.Sprintf("<%d>1 %s %s kubescape - - - %s", syslogFacilityUser*8+severityToSyslogSeverity(event.severity), timestamp, hostname, event.event)
+		if syslogForwarder.network == "tcp" {
+			// octet counting framing, https://datatracker.ietf.org/doc/html/rfc6587#section-3.4.1
+			message = fmt.Sprintf("%d %s", len(message), message)
+		}
+		if _, err := conn.Write([]byte(message)); err != nil {
+			return fmt.Errorf("failed to forward results to syslog server '%s': %w", syslogForwarder.address, err)
+		}
+	}
+	logger.L().Success("forwarded results to syslog server", helpers.String("address", syslogForwarder.address), helpers.Int("events", len(events)))
+	return nil
+}
+
+func parseSyslogURL(forward string) (string, string, error) {
+	u, err := url.Parse(forward)
+	if err != nil {
+		return "", "", fmt.Errorf("bad argument: invalid forward address '%s': %w", forward, err)
+	}
+	var network string
+	switch u.Scheme {
+	case "syslog", "syslog+udp":
+		network = "udp"
+	case "syslog+tcp":
+		network = "tcp"
+	default:
+		return "", "", fmt.Errorf("bad argument: unsupported forward address '%s'. Supported: syslog://host:port, syslog+tcp://host:port", forward)
+	}
+	if u.Hostname() == "" {
+		return "", "", fmt.Errorf("bad argument: missing host in forward address '%s'", forward)
+	}
+	port := u.Port()
+	if port == "" {
+		port = syslogDefaultPort
+	}
+	return network, net.JoinHostPort(u.Hostname(), port), nil
+}
+
+// severityToSyslogSeverity returns the syslog severity (crit/err/warning/notice) of the control severity
+func severityToSyslogSeverity(severity string) int {
+	switch severity {
+	case cautils.SeverityCritical:
+		return 2
+	case cautils.SeverityHigh:
+		return 3
+	case cautils.SeverityMedium:
+		return 4
+	default:
+		return 5
+	}
+}
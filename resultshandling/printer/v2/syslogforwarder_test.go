@@ -0,0 +1,38 @@
+package v2
+
+import "testing"
+
+func TestParseSyslogURL(t *testing.T) {
+	tests := []struct {
+		forward string
+		network string
+		address string
+		wantErr bool
+	}{
+		{forward: "syslog://siem.example.com:1514", network: "udp", address: "siem.example.com:1514"},
+		{forward: "syslog+udp://siem.example.com", network: "udp", address: "siem.example.com:514"},
+		{forward: "syslog+tcp://10.0.0.1:6514", network: "tcp", address: "10.0.0.1:6514"},
+		{forward: "http://siem.example.com:514", wantErr: true},
+		{forward: "siem.example.com:514", wantErr: true},
+		{forward: "syslog://:514", wantErr: true},
+	}
+	for _, test := range tests {
+		network, address, err := parseSyslogURL(test.forward)
+		if (err != nil) != test.wantErr {
+			t.Errorf("parseSyslogURL(%s) error = %v, wantErr %v", test.forward, err, test.wantErr)
+			continue
+		}
+		if network != test.network || address != test.address {
+			t.Errorf("parseSyslogURL(%s) = %s %s, expected %s %s", test.forward, network, address, test.network, test.address)
+		}
+	}
+}
+
+func TestEscapeCef(t *testing.T) {
+	if s := escapeCefHeader(`a|b\c`); s != `a\|b\\c` {
+		t.Errorf("unexpected header escaping: %s", s)
+	}
+	if s := escapeCefExtension("a=b\\c\nd|e"); s != `a\=b\\c\nd|e` {
+		t.Errorf("unexpected extension escaping: %s", s)
+	}
+}
@@ -0,0 +1,222 @@
+package v2
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"text/template"
+	"time"
+
+	"github.com/armosec/k8s-interface/workloadinterface"
+	"github.com/armosec/kubescape/attacktracks"
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/resultshandling/printer"
+	"github.com/armosec/opa-utils/reporthandling/apis"
+	"github.com/armosec/opa-utils/reporthandling/results/v1/reportsummary"
+	reporthandlingv2 "github.com/armosec/opa-utils/reporthandling/v2"
+	sprig "github.com/go-task/slim-sprig"
+)
+
+// TemplateData is the object the go templates are rendered with
+type TemplateData struct {
+	ClusterName  string
+	GeneratedAt  time.Time // in the time zone of the reports (--timezone)
+	StartTime    string    // RFC3339 timestamp of the start of the scan, in the time zone of the reports
+	EndTime      string    // RFC3339 timestamp of the end of the scan, in the time zone of the reports
+	Score        float32
+	Frameworks   []TemplateFramework
+	Controls     []TemplateControl // sorted by control ID
+	FailedCount  int               // number of failed controls
+	PassedCount  int               // number of passed controls
+	SkippedCount int               // number of skipped controls
+	Report       *reporthandlingv2.PostureReport
+
+	ExpiredExceptions     []ExpiredException       // exceptions which were not applied since they expired, sorted by name
+	DataGaps              []DataGap                // resources which could not be fetched, sorted by resource
+	ImagesVulnerabilities []ImageVulnerabilities   // vulnerabilities of the images of the workloads (--enable-image-scan), the most severe first
+	ImagesSignatures      []ImageSignature         // verifications of the signatures of the images (--verify-image-signatures), the unverified first
+	SBOMs                 []cautils.SBOMReference  // SBOMs of the images (--sbom)
+	AttackTracks          []TemplateAttackTrack    // attack tracks of the workloads, the most exploitable first
+	ScoresBreakdown       *cautils.ScoresBreakdown // risk-scores by namespace and by owner (--owner-label), nil for the results files
+	IncludePassed         bool                     // list the passed and the skipped resources of the controls (--include-passed)
+}
+
+// TemplateAttackTrack the attack track of a workload, with the steps grouped by stage
+type TemplateAttackTrack struct {
+	attacktracks.AttackTrack
+	Title      string
+	StageSteps []TemplateStage // in the order of the stages of the track
+}
+
+type TemplateStage struct {
+	Stage    string
+	Severity string // the highest severity of the steps
+	Steps    []attacktracks.Step
+}
+
+type TemplateFramework struct {
+	Name  string
+	Score float32
+}
+
+type TemplateControl struct {
+	ID                string
+	Name              string
+	Severity          string
+	Status            string
+	Score             float32
+	Description       string
+	Remediation       string
+	URL               string
+	FailedResources   []TemplateResource
+	ExcludedResources []TemplateResource
+	PassedResources   []TemplateResource
+	SkippedResources  []TemplateResource // skipped and irrelevant to the control
+}
+
+type TemplateResource struct {
+	ID         string
+	ApiVersion string
+	Kind       string
+	Namespace  string
+	Name       string
+	File       string          // source file, when scanning files
+	Line       int             // line in the source file
+	Fixes      []FixSuggestion // the fix suggestions of the control, set for the failed resources
+}
+
+type TemplatePrinter struct {
+	writer       *os.File
+	templatePath string
+}
+
+func NewTemplatePrinter(templatePath string) *TemplatePrinter {
+	return &TemplatePrinter{
+		templatePath: templatePath,
+	}
+}
+
+func (templatePrinter *TemplatePrinter) SetWriter(outputFile string) {
+	templatePrinter.writer = printer.GetWriter(outputFile)
+}
+
+func (templatePrinter *TemplatePrinter) Score(score float32) {
+	printer.LogScore(score)
+}
+
+func (templatePrinter *TemplatePrinter) ActionPrint(opaSessionObj *cautils.OPASessionObj) error {
+	tmpl, err := template.New(filepath.Base(templatePrinter.templatePath)).Funcs(sprig.TxtFuncMap()).ParseFiles(templatePrinter.templatePath)
+	if err != nil {
+		return fmt.Errorf("failed to parse output template '%s': %w", templatePrinter.templatePath, err)
+	}
+
+	finalizeJson(opaSessionObj)
+	if err := tmpl.Execute(templatePrinter.writer, NewTemplateData(opaSessionObj)); err != nil {
+		return fmt.Errorf("failed to execute output template '%s': %w", templatePrinter.templatePath, err)
+	}
+
+	logOUtputFile(templatePrinter.writer.Name())
+	return nil
+}
+
+// NewTemplateData converts the scan results to the object the templates are rendered with
+func NewTemplateData(opaSessionObj *cautils.OPASessionObj) *TemplateData {
+	summaryDetails := &opaSessionObj.Report.SummaryDetails
+	data := &TemplateData{
+		ClusterName:  opaSessionObj.Report.ClusterName,
+		GeneratedAt:  opaSessionObj.ScanTimes.In(opaSessionObj.Report.ReportGenerationTime),
+		StartTime:    opaSessionObj.ScanTimes.Format(opaSessionObj.ScanTimes.Start),
+		EndTime:      opaSessionObj.ScanTimes.Format(opaSessionObj.ScanTimes.End),
+		Score:        summaryDetails.Score,
+		Frameworks:   []TemplateFramework{},
+		Controls:     []TemplateControl{},
+		FailedCount:  summaryDetails.NumberOfControls().Failed(),
+		PassedCount:  summaryDetails.NumberOfControls().Passed(),
+		SkippedCount: summaryDetails.NumberOfControls().Skipped(),
+		Report:       opaSessionObj.Report,
+
+		ExpiredExceptions:     listExpiredExceptions(opaSessionObj),
+		DataGaps:              listDataGaps(opaSessionObj),
+		ImagesVulnerabilities: listImagesVulnerabilities(opaSessionObj),
+		ImagesSignatures:      listImagesSignatures(opaSessionObj),
+		SBOMs:                 opaSessionObj.SBOMs,
+		AttackTracks:          listTemplateAttackTracks(opaSessionObj),
+		ScoresBreakdown:       opaSessionObj.ScoresBreakdown,
+		IncludePassed:         opaSessionObj.IncludePassed,
+	}
+
+	for _, framework := range summaryDetails.Frameworks {
+		data.Frameworks = append(data.Frameworks, TemplateFramework{Name: framework.GetName(), Score: framework.GetScore()})
+	}
+
+	controlIDs := summaryDetails.Controls.ListControlsIDs().All()
+	sort.Strings(controlIDs)
+	for _, controlID := range controlIDs {
+		control := summaryDetails.Controls.GetControl(reportsummary.EControlCriteriaID, controlID)
+		if control == nil {
+			continue
+		}
+		resourcesIDs := control.ListResourcesIDs()
+		failedResources := templateResources(resourcesIDs.Failed(), opaSessionObj.AllResources, opaSessionObj.ResourceSource)
+		for i := range failedResources {
+			if result, ok := opaSessionObj.ResourcesResult[failedResources[i].ID]; ok {
+				failedResources[i].Fixes = listFixSuggestions(&result, controlID)
+			}
+		}
+		data.Controls = append(data.Controls, TemplateControl{
+			ID:                control.GetID(),
+			Name:              control.GetName(),
+			Severity:          cautils.ControlSeverityToString(control.GetScoreFactor()),
+			Status:            string(control.GetStatus().Status()),
+			Score:             control.GetScore(),
+			Description:       control.GetDescription(),
+			Remediation:       control.GetRemediation(),
+			URL:               getControlURL(control.GetID()),
+			FailedResources:   failedResources,
+			ExcludedResources: templateResources(resourcesIDs.Excluded(), opaSessionObj.AllResources, opaSessionObj.ResourceSource),
+			PassedResources:   templateResources(resourcesIDs.Passed(), opaSessionObj.AllResources, opaSessionObj.ResourceSource),
+			SkippedResources:  templateResources(resourceIDsByStatus(resourcesIDs, apis.StatusSkipped), opaSessionObj.AllResources, opaSessionObj.ResourceSource),
+		})
+	}
+	return data
+}
+
+func templateResources(resourcesIDs []string, allResources map[string]workloadinterface.IMetadata, resourceSource map[string]cautils.ResourceSource) []TemplateResource {
+	resources := []TemplateResource{}
+	for _, resourceID := range resourcesIDs {
+		resource, ok := allResources[resourceID]
+		if !ok {
+			continue
+		}
+		r := TemplateResource{
+			ID:         resourceID,
+			ApiVersion: resource.GetApiVersion(),
+			Kind:       resource.GetKind(),
+			Namespace:  resource.GetNamespace(),
+			Name:       resource.GetName(),
+		}
+		if source, ok := resourceSource[resourceID]; ok {
+			r.File = relativeSourcePath(source.Path)
+			r.Line = source.Line
+		}
+		resources = append(resources, r)
+	}
+	sort.Slice(resources, func(i, j int) bool {
+		return resources[i].ID < resources[j].ID
+	})
+	return resources
+}
+
+func listTemplateAttackTracks(opaSessionObj *cautils.OPASessionObj) []TemplateAttackTrack {
+	tracks := []TemplateAttackTrack{}
+	for _, track := range attacktracks.ListAttackTracks(opaSessionObj) {
+		templateTrack := TemplateAttackTrack{AttackTrack: track, Title: trackTitle(&track), StageSteps: []TemplateStage{}}
+		for _, stage := range track.Stages {
+			steps, severity := stageSteps(&track, stage)
+			templateTrack.StageSteps = append(templateTrack.StageSteps, TemplateStage{Stage: stage, Severity: severity, Steps: steps})
+		}
+		tracks = append(tracks, templateTrack)
+	}
+	return tracks
+}
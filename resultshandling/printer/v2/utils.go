@@ -1,10 +1,15 @@
 package v2
 
 import (
+	"path/filepath"
+	"sort"
+	"strings"
+
 	"github.com/armosec/k8s-interface/workloadinterface"
 	"github.com/armosec/kubescape/cautils"
 	"github.com/armosec/kubescape/cautils/logger"
 	"github.com/armosec/kubescape/cautils/logger/helpers"
+	"github.com/armosec/kubescape/resultshandling/printer"
 	"github.com/armosec/opa-utils/reporthandling"
 	"github.com/armosec/opa-utils/reporthandling/results/v1/resourcesresults"
 )
@@ -18,23 +23,28 @@ func finalizeJson(opaSessionObj *cautils.OPASessionObj) {
 
 	if len(opaSessionObj.Report.Resources) == 0 {
 		opaSessionObj.Report.Resources = make([]reporthandling.Resource, len(opaSessionObj.AllResources))
-		finalizeResources(opaSessionObj.Report.Resources, opaSessionObj.Report.Results, opaSessionObj.AllResources)
+		finalizeResources(opaSessionObj.Report.Resources, opaSessionObj.Report.Results, opaSessionObj.AllResources, opaSessionObj.Redact)
 	}
 
 }
+
+// finalizeResults lists the results sorted by resource ID, the outputs are stable across scans
 func finalizeResults(results []resourcesresults.Result, resourcesResult map[string]resourcesresults.Result) {
-	index := 0
+	resourceIDs := make([]string, 0, len(resourcesResult))
 	for resourceID := range resourcesResult {
+		resourceIDs = append(resourceIDs, resourceID)
+	}
+	sort.Strings(resourceIDs)
+	for index, resourceID := range resourceIDs {
 		results[index] = resourcesResult[resourceID]
-		index++
 	}
 }
 
-func finalizeResources(resources []reporthandling.Resource, results []resourcesresults.Result, allResources map[string]workloadinterface.IMetadata) {
+func finalizeResources(resources []reporthandling.Resource, results []resourcesresults.Result, allResources map[string]workloadinterface.IMetadata, redact *cautils.RedactOptions) {
 	index := 0
 	for i := range results {
 		if obj, ok := allResources[results[i].ResourceID]; ok {
-			r := *reporthandling.NewResource(obj.GetObject())
+			r := *reporthandling.NewResource(redact.RedactObject(obj.GetObject()))
 			r.ResourceID = results[i].ResourceID
 			resources[index] = r
 		}
@@ -43,6 +53,27 @@ func finalizeResources(resources []reporthandling.Resource, results []resourcesr
 	}
 }
 
+// GetOutputFile returns the file the printer of the format writes to, "" when writing to stdout.
+// Binary formats (pdf/xlsx) are always written to a file, with the right file extension
+func GetOutputFile(format, outputFile string) string {
+	var defaultFile, ext string
+	switch format {
+	case printer.PdfFormat:
+		defaultFile, ext = pdfOutputFile, pdfOutputExt
+	case printer.XLSXFormat:
+		defaultFile, ext = xlsxOutputFile, xlsxOutputExt
+	default:
+		return outputFile
+	}
+	if outputFile == "" {
+		outputFile = defaultFile
+	}
+	if filepath.Ext(strings.TrimSpace(outputFile)) != ext {
+		outputFile = outputFile + ext
+	}
+	return outputFile
+}
+
 func logOUtputFile(fileName string) {
 	if fileName != "/dev/stdout" && fileName != "/dev/stderr" {
 		logger.L().Success("Scan results saved", helpers.String("filename", fileName))
@@ -0,0 +1,23 @@
+package v2
+
+import (
+	"testing"
+
+	"github.com/armosec/opa-utils/reporthandling/results/v1/resourcesresults"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFinalizeResults(t *testing.T) {
+	resourcesResult := map[string]resourcesresults.Result{}
+	for _, resourceID := range []string{"apps/v1/default/Deployment/web", "/v1/default/Pod/web", "/v1//Namespace/default", "apps/v1/default/Deployment/api"} {
+		resourcesResult[resourceID] = resourcesresults.Result{ResourceID: resourceID}
+	}
+	results := make([]resourcesresults.Result, len(resourcesResult))
+	finalizeResults(results, resourcesResult)
+
+	resourceIDs := []string{}
+	for i := range results {
+		resourceIDs = append(resourceIDs, results[i].ResourceID)
+	}
+	assert.Equal(t, []string{"/v1//Namespace/default", "/v1/default/Pod/web", "apps/v1/default/Deployment/api", "apps/v1/default/Deployment/web"}, resourceIDs)
+}
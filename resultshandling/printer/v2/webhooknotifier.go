@@ -0,0 +1,233 @@
+package v2
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/cautils/getter"
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/cautils/logger/helpers"
+	"github.com/armosec/opa-utils/reporthandling/apis"
+)
+
+const (
+	SlackWebhook = "slack"
+	TeamsWebhook = "teams"
+
+	notifyMaxControls = 10 // maximum number of critical controls listed in the notification
+)
+
+// WebhookNotifier is a printer that posts a summary of the scan to a Slack/Microsoft Teams incoming webhook
+type WebhookNotifier struct {
+	kind       string
+	webhookURL string
+	reportURL  string
+	httpClient *http.Client
+}
+
+// NewWebhookNotifier creates a notifier of a webhook URL. The kind of the webhook is detected from the host, or set explicitly
+// with a "slack+" / "teams+" prefix, e.g. teams+https://example.com/webhook. The report URL is linked from the notification when set
+func NewWebhookNotifier(webhookURL, reportURL string) (*WebhookNotifier, error) {
+	kind, webhookURL, err := parseWebhookURL(webhookURL)
+	if err != nil {
+		return nil, err
+	}
+	return &WebhookNotifier{
+		kind:       kind,
+		webhookURL: webhookURL,
+		reportURL:  reportURL,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+func (webhookNotifier *WebhookNotifier) SetWriter(outputFile string) {}
+
+func (webhookNotifier *WebhookNotifier) Score(score float32) {}
+
+func (webhookNotifier *WebhookNotifier) ActionPrint(opaSessionObj *cautils.OPASessionObj) error {
+	data := NewTemplateData(opaSessionObj)
+
+	var message interface{}
+	switch webhookNotifier.kind {
+	case SlackWebhook:
+		message = slackMessage(data, webhookNotifier.reportURL)
+	case TeamsWebhook:
+		message = teamsMessage(data, webhookNotifier.reportURL)
+	}
+	body, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to Marshal notification: %w", err)
+	}
+	if _, err := getter.HttpPost(webhookNotifier.httpClient, webhookNotifier.webhookURL, map[string]string{"Content-Type": "application/json"}, body); err != nil {
+		// the error may contain the webhook URL, which is a secret
+		return fmt.Errorf("failed to send %s notification: %s", webhookNotifier.kind, strings.ReplaceAll(err.Error(), webhookNotifier.webhookURL, "***"))
+	}
+	logger.L().Success("notification sent", helpers.String("webhook", webhookNotifier.kind))
+	return nil
+}
+
+func parseWebhookURL(webhookURL string) (string, string, error) {
+	kind := ""
+	for _, k := range []string{SlackWebhook, TeamsWebhook} {
+		if strings.HasPrefix(webhookURL, k+"+") {
+			kind, webhookURL = k, strings.TrimPrefix(webhookURL, k+"+")
+		}
+	}
+	u, err := url.Parse(webhookURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return "", "", fmt.Errorf("bad argument: invalid notification webhook, expecting an http(s) URL")
+	}
+	if kind != "" {
+		return kind, webhookURL, nil
+	}
+	switch host := u.Hostname(); {
+	case host == "hooks.slack.com":
+		return SlackWebhook, webhookURL, nil
+	case strings.HasSuffix(host, ".webhook.office.com"), host == "outlook.office.com", strings.HasSuffix(host, ".logic.azure.com"):
+		return TeamsWebhook, webhookURL, nil
+	}
+	return "", "", fmt.Errorf("bad argument: unknown notification webhook '%s', use a 'slack+' or 'teams+' prefix to set the webhook kind", u.Hostname())
+}
+
+// notificationTitle the title of the notification card
+func notificationTitle(data *TemplateData) string {
+	if data.ClusterName != "" {
+		return fmt.Sprintf("Kubescape scan results - %s", data.ClusterName)
+	}
+	return "Kubescape scan results"
+}
+
+// notificationSummary a single line summary of the scan
+func notificationSummary(data *TemplateData) string {
+	return fmt.Sprintf("Risk-score: %.2f%% (%d failed, %d passed controls)", data.Score, data.FailedCount, data.PassedCount)
+}
+
+// criticalControls returns the failed critical controls, limited to notifyMaxControls. The number of the controls that were left out is returned as well
+func criticalControls(data *TemplateData) ([]TemplateControl, int) {
+	controls := []TemplateControl{}
+	for _, control := range data.Controls {
+		if control.Status == string(apis.StatusFailed) && control.Severity == cautils.SeverityCritical {
+			controls = append(controls, control)
+		}
+	}
+	if len(controls) > notifyMaxControls {
+		return controls[:notifyMaxControls], len(controls) - notifyMaxControls
+	}
+	return controls, 0
+}
+
+// expiredExceptions returns the expired exceptions, limited to notifyMaxControls. The number of the exceptions that were left out is returned as well
+func expiredExceptions(data *TemplateData) ([]ExpiredException, int) {
+	if len(data.ExpiredExceptions) > notifyMaxControls {
+		return data.ExpiredExceptions[:notifyMaxControls], len(data.ExpiredExceptions) - notifyMaxControls
+	}
+	return data.ExpiredExceptions, 0
+}
+
+// https://api.slack.com/messaging/webhooks, https://api.slack.com/reference/block-kit/blocks
+func slackMessage(data *TemplateData, reportURL string) map[string]interface{} {
+	text := func(s string) map[string]interface{} {
+		return map[string]interface{}{"type": "mrkdwn", "text": s}
+	}
+
+	controls, more := criticalControls(data)
+	lines := []string{}
+	for _, control := range controls {
+		lines = append(lines, fmt.Sprintf("• <%s|%s> %s - %d failed resources", control.URL, control.ID, slackEscape(control.Name), len(control.FailedResources)))
+	}
+	if more > 0 {
+		lines = append(lines, fmt.Sprintf("and %d more", more))
+	}
+	if len(lines) == 0 {
+		lines = append(lines, "No critical controls failed :white_check_mark:")
+	}
+
+	blocks := []interface{}{
+		map[string]interface{}{"type": "header", "text": map[string]interface{}{"type": "plain_text", "text": notificationTitle(data)}},
+		map[string]interface{}{"type": "section", "text": text(fmt.Sprintf("*%s*", notificationSummary(data)))},
+		map[string]interface{}{"type": "section", "text": text("*Failed critical controls*\n" + strings.Join(lines, "\n"))},
+	}
+	if exceptions, more := expiredExceptions(data); len(exceptions) > 0 {
+		lines := []string{}
+		for i := range exceptions {
+			lines = append(lines, "• "+slackEscape(exceptions[i].String()))
+		}
+		if more > 0 {
+			lines = append(lines, fmt.Sprintf("and %d more", more))
+		}
+		blocks = append(blocks, map[string]interface{}{"type": "section", "text": text("*Expired exceptions*\n" + strings.Join(lines, "\n"))})
+	}
+	if reportURL != "" {
+		blocks = append(blocks, map[string]interface{}{"type": "section", "text": text(fmt.Sprintf("<%s|View full report>", reportURL))})
+	}
+	return map[string]interface{}{
+		"text":   fmt.Sprintf("%s: %s", notificationTitle(data), notificationSummary(data)),
+		"blocks": blocks,
+	}
+}
+
+var slackReplacer = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// slackEscape escapes the control characters of the slack mrkdwn text
+func slackEscape(s string) string {
+	return slackReplacer.Replace(s)
+}
+
+// https://learn.microsoft.com/en-us/microsoftteams/platform/webhooks-and-connectors/how-to/connectors-using
+func teamsMessage(data *TemplateData, reportURL string) map[string]interface{} {
+	textBlock := func(s string, extra map[string]interface{}) map[string]interface{} {
+		block := map[string]interface{}{"type": "TextBlock", "text": s, "wrap": true}
+		for k, v := range extra {
+			block[k] = v
+		}
+		return block
+	}
+
+	body := []interface{}{
+		textBlock(notificationTitle(data), map[string]interface{}{"size": "Large", "weight": "Bolder"}),
+		textBlock(notificationSummary(data), map[string]interface{}{"weight": "Bolder"}),
+		textBlock("Failed critical controls", map[string]interface{}{"weight": "Bolder", "spacing": "Medium"}),
+	}
+	controls, more := criticalControls(data)
+	for _, control := range controls {
+		body = append(body, textBlock(fmt.Sprintf("- [%s](%s) %s - %d failed resources", control.ID, control.URL, control.Name, len(control.FailedResources)), nil))
+	}
+	if more > 0 {
+		body = append(body, textBlock(fmt.Sprintf("and %d more", more), nil))
+	}
+	if len(controls) == 0 {
+		body = append(body, textBlock("No critical controls failed", nil))
+	}
+	if exceptions, more := expiredExceptions(data); len(exceptions) > 0 {
+		body = append(body, textBlock("Expired exceptions", map[string]interface{}{"weight": "Bolder", "spacing": "Medium"}))
+		for i := range exceptions {
+			body = append(body, textBlock("- "+exceptions[i].String(), nil))
+		}
+		if more > 0 {
+			body = append(body, textBlock(fmt.Sprintf("and %d more", more), nil))
+		}
+	}
+
+	card := map[string]interface{}{
+		"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+		"type":    "AdaptiveCard",
+		"version": "1.4",
+		"body":    body,
+	}
+	if reportURL != "" {
+		card["actions"] = []interface{}{
+			map[string]interface{}{"type": "Action.OpenUrl", "title": "View full report", "url": reportURL},
+		}
+	}
+	return map[string]interface{}{
+		"type": "message",
+		"attachments": []interface{}{
+			map[string]interface{}{"contentType": "application/vnd.microsoft.card.adaptive", "content": card},
+		},
+	}
+}
@@ -0,0 +1,103 @@
+package v2
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/opa-utils/reporthandling/apis"
+)
+
+func TestParseWebhookURL(t *testing.T) {
+	tests := []struct {
+		webhook string
+		kind    string
+		url     string
+		wantErr bool
+	}{
+		{webhook: "https://hooks.slack.com/services/T0/B0/X", kind: SlackWebhook, url: "https://hooks.slack.com/services/T0/B0/X"},
+		{webhook: "https://contoso.webhook.office.com/webhookb2/x", kind: TeamsWebhook, url: "https://contoso.webhook.office.com/webhookb2/x"},
+		{webhook: "https://prod-00.westus.logic.azure.com:443/workflows/x", kind: TeamsWebhook, url: "https://prod-00.westus.logic.azure.com:443/workflows/x"},
+		{webhook: "slack+http://localhost:8080/hook", kind: SlackWebhook, url: "http://localhost:8080/hook"},
+		{webhook: "teams+https://example.com/hook", kind: TeamsWebhook, url: "https://example.com/hook"},
+		{webhook: "https://example.com/hook", wantErr: true},
+		{webhook: "slack+ftp://example.com/hook", wantErr: true},
+		{webhook: "hooks.slack.com/services/T0/B0/X", wantErr: true},
+	}
+	for _, test := range tests {
+		kind, url, err := parseWebhookURL(test.webhook)
+		if (err != nil) != test.wantErr {
+			t.Errorf("parseWebhookURL(%s) error = %v, wantErr %v", test.webhook, err, test.wantErr)
+			continue
+		}
+		if kind != test.kind || url != test.url {
+			t.Errorf("parseWebhookURL(%s) = %s %s, expected %s %s", test.webhook, kind, url, test.kind, test.url)
+		}
+	}
+}
+
+func TestNotificationMessages(t *testing.T) {
+	data := &TemplateData{
+		ClusterName: "prod",
+		Score:       42,
+		FailedCount: 2,
+		PassedCount: 1,
+		Controls: []TemplateControl{
+			{ID: "C-0001", Name: "Privileged <container> & co", Severity: cautils.SeverityCritical, Status: string(apis.StatusFailed), FailedResources: []TemplateResource{{}, {}}},
+			{ID: "C-0002", Name: "High control", Severity: cautils.SeverityHigh, Status: string(apis.StatusFailed)},
+			{ID: "C-0003", Name: "Passed critical control", Severity: cautils.SeverityCritical, Status: string(apis.StatusPassed)},
+		},
+	}
+
+	slack, _ := json.Marshal(slackMessage(data, "https://example.com/report"))
+	for _, expected := range []string{"Kubescape scan results - prod", "Risk-score: 42.00%", "Privileged \\u0026lt;container\\u0026gt; \\u0026amp; co - 2 failed resources", "View full report"} {
+		if !strings.Contains(string(slack), expected) {
+			t.Errorf("slack message does not contain '%s': %s", expected, slack)
+		}
+	}
+	if strings.Contains(string(slack), "C-0002") || strings.Contains(string(slack), "C-0003") {
+		t.Errorf("slack message should list only the failed critical controls: %s", slack)
+	}
+
+	teams, _ := json.Marshal(teamsMessage(data, ""))
+	for _, expected := range []string{"AdaptiveCard", "[C-0001]", "Risk-score: 42.00%"} {
+		if !strings.Contains(string(teams), expected) {
+			t.Errorf("teams message does not contain '%s': %s", expected, teams)
+		}
+	}
+	if strings.Contains(string(teams), "Action.OpenUrl") {
+		t.Errorf("teams message should not link a report: %s", teams)
+	}
+}
+
+func TestCriticalControlsLimit(t *testing.T) {
+	data := &TemplateData{}
+	for i := 0; i < notifyMaxControls+3; i++ {
+		data.Controls = append(data.Controls, TemplateControl{Severity: cautils.SeverityCritical, Status: string(apis.StatusFailed)})
+	}
+	controls, more := criticalControls(data)
+	if len(controls) != notifyMaxControls || more != 3 {
+		t.Errorf("criticalControls() = %d controls and %d more, expected %d and 3", len(controls), more, notifyMaxControls)
+	}
+}
+
+func TestWebhookNotifierError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	webhook := "slack+" + server.URL + "/secret"
+	server.Close()
+
+	webhookNotifier, err := NewWebhookNotifier(webhook, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = webhookNotifier.ActionPrint(mockMetricsSession())
+	if err == nil {
+		t.Fatal("expected an error sending to a closed server")
+	}
+	if strings.Contains(err.Error(), "secret") {
+		t.Errorf("error should not contain the webhook URL: %v", err)
+	}
+}
@@ -0,0 +1,128 @@
+package v2
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/resultshandling/printer"
+)
+
+const (
+	xlsxOutputFile = "report"
+	xlsxOutputExt  = ".xlsx"
+	xlsxSheetName  = "Results"
+)
+
+// minimal SpreadsheetML (Office Open XML) package with a single worksheet, cells are written as inline strings
+var xlsxStaticParts = map[string]string{
+	"[Content_Types].xml": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/><Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/></Types>`,
+	"_rels/.rels": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`,
+	"xl/workbook.xml": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="` + xlsxSheetName + `" sheetId="1" r:id="rId1"/></sheets></workbook>`,
+	"xl/_rels/workbook.xml.rels": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/><Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/></Relationships>`,
+	"xl/styles.xml": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts><fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills><borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders><cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs><cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/><xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs></styleSheet>`,
+}
+
+type XlsxPrinter struct {
+	writer *os.File
+}
+
+func NewXlsxPrinter() *XlsxPrinter {
+	return &XlsxPrinter{}
+}
+
+func (xlsxPrinter *XlsxPrinter) SetWriter(outputFile string) {
+	xlsxPrinter.writer = printer.GetWriter(GetOutputFile(printer.XLSXFormat, outputFile))
+}
+
+func (xlsxPrinter *XlsxPrinter) Score(score float32) {
+	printer.LogScore(score)
+}
+
+func (xlsxPrinter *XlsxPrinter) ActionPrint(opaSessionObj *cautils.OPASessionObj) error {
+	if err := encodeXlsx(xlsxPrinter.writer, opaSessionObj); err != nil {
+		return err
+	}
+
+	logOUtputFile(xlsxPrinter.writer.Name())
+	return nil
+}
+
+func encodeXlsx(writer io.Writer, opaSessionObj *cautils.OPASessionObj) error {
+	rows := append([][]string{getControlResourceHeaders()}, generateControlResourceRows(opaSessionObj)...)
+	rows = append(rows, expiredExceptionRows(opaSessionObj)...)
+
+	buf := &bytes.Buffer{}
+	if err := writeXlsx(buf, rows); err != nil {
+		return fmt.Errorf("failed to generate xlsx results: %w", err)
+	}
+	_, err := writer.Write(buf.Bytes())
+	return err
+}
+
+// writeXlsx writes a workbook with a single sheet, the first row is styled as the header
+func writeXlsx(w io.Writer, rows [][]string) error {
+	zw := zip.NewWriter(w)
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/styles.xml"} {
+		f, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, xlsxStaticParts[name]); err != nil {
+			return err
+		}
+	}
+
+	f, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return err
+	}
+	if err := writeXlsxSheet(f, rows); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+func writeXlsxSheet(w io.Writer, rows [][]string) error {
+	sb := &strings.Builder{}
+	sb.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>`)
+	sb.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	for i, row := range rows {
+		fmt.Fprintf(sb, `<row r="%d">`, i+1)
+		style := ""
+		if i == 0 {
+			style = ` s="1"`
+		}
+		for j, cell := range row {
+			fmt.Fprintf(sb, `<c r="%s%d" t="inlineStr"%s><is><t xml:space="preserve">`, xlsxColumnName(j), i+1, style)
+			if err := xml.EscapeText(sb, []byte(cell)); err != nil {
+				return err
+			}
+			sb.WriteString(`</t></is></c>`)
+		}
+		sb.WriteString(`</row>`)
+	}
+	sb.WriteString(`</sheetData></worksheet>`)
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// xlsxColumnName converts a zero based column index to the spreadsheet column name (0 -> A, 26 -> AA)
+func xlsxColumnName(i int) string {
+	name := ""
+	for i >= 0 {
+		name = string(rune('A'+i%26)) + name
+		i = i/26 - 1
+	}
+	return name
+}
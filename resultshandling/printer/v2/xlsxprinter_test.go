@@ -0,0 +1,43 @@
+package v2
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestXlsxColumnName(t *testing.T) {
+	tests := map[int]string{0: "A", 8: "I", 25: "Z", 26: "AA", 27: "AB", 701: "ZZ", 702: "AAA"}
+	for i, expected := range tests {
+		if name := xlsxColumnName(i); name != expected {
+			t.Errorf("xlsxColumnName(%d) = %s, expected %s", i, name, expected)
+		}
+	}
+}
+
+func TestWriteXlsx(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := writeXlsx(buf, [][]string{getControlResourceHeaders(), {"C-0001", "a < b & c"}}); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sheet string
+	for _, f := range zr.File {
+		if f.Name == "xl/worksheets/sheet1.xml" {
+			r, _ := f.Open()
+			b, _ := io.ReadAll(r)
+			sheet = string(b)
+		}
+	}
+	if !strings.Contains(sheet, `<c r="B2" t="inlineStr"><is><t xml:space="preserve">a &lt; b &amp; c</t></is></c>`) {
+		t.Errorf("unexpected sheet content: %s", sheet)
+	}
+	if len(zr.File) != 6 {
+		t.Errorf("expected 6 parts, received %d", len(zr.File))
+	}
+}
@@ -0,0 +1,134 @@
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/opa-utils/reporthandling/apis"
+	reporthandlingv2 "github.com/armosec/opa-utils/reporthandling/v2"
+	"github.com/itchyny/gojq"
+)
+
+// Control a control of the results file, as queried by the 'controls' field of the document
+type Control struct {
+	ControlID         string   `json:"controlID"`
+	Name              string   `json:"name"`
+	Severity          string   `json:"severity"`
+	Status            string   `json:"status"`
+	Score             float32  `json:"score"`
+	ScoreFactor       float32  `json:"scoreFactor"`
+	Frameworks        []string `json:"frameworks"`
+	FailedResources   []string `json:"failedResources"`
+	ExcludedResources []string `json:"excludedResources"`
+	PassedResources   []string `json:"passedResources"`
+}
+
+// NewDocument returns the queried document of a results file generated by 'kubescape scan --format json --format-version v2'.
+// The document is the results file, with a 'controls' list added for convenience - a control per item, with its severity, status and resources
+func NewDocument(data []byte) (map[string]interface{}, error) {
+	report := &reporthandlingv2.PostureReport{}
+	if err := json.Unmarshal(data, report); err != nil {
+		return nil, fmt.Errorf("failed to load results file: %w", err)
+	}
+	if report.SummaryDetails.Controls == nil && len(report.SummaryDetails.Frameworks) == 0 {
+		return nil, fmt.Errorf("the results file is not in the v2 format, run the scan with '--format json --format-version v2'")
+	}
+
+	document := map[string]interface{}{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("failed to load results file: %w", err)
+	}
+	// gojq supports only the types of encoding/json, so the controls are converted by marshaling
+	controls, err := toInterface(listControls(report))
+	if err != nil {
+		return nil, err
+	}
+	document["controls"] = controls
+	return document, nil
+}
+
+// Query runs the jq filter on the document and returns the results
+func Query(document map[string]interface{}, filter string) ([]interface{}, error) {
+	q, err := gojq.Parse(filter)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter '%s': %w", filter, err)
+	}
+	code, err := gojq.Compile(q)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter '%s': %w", filter, err)
+	}
+
+	results := []interface{}{}
+	iter := code.Run(document)
+	for {
+		v, ok := iter.Next()
+		if !ok {
+			break
+		}
+		if err, ok := v.(error); ok {
+			return results, err
+		}
+		results = append(results, v)
+	}
+	return results, nil
+}
+
+// listControls returns the controls of the report, sorted by ID
+func listControls(report *reporthandlingv2.PostureReport) []Control {
+	resources := map[string]map[apis.ScanningStatus][]string{}
+	for i := range report.Results {
+		for _, control := range report.Results[i].ListControls() {
+			if _, ok := resources[control.GetID()]; !ok {
+				resources[control.GetID()] = map[apis.ScanningStatus][]string{}
+			}
+			status := control.GetStatus(nil).Status()
+			resources[control.GetID()][status] = append(resources[control.GetID()][status], report.Results[i].GetResourceID())
+		}
+	}
+
+	frameworks := map[string][]string{}
+	for _, framework := range report.SummaryDetails.Frameworks {
+		for controlID := range framework.Controls {
+			frameworks[controlID] = append(frameworks[controlID], framework.Name)
+		}
+	}
+
+	controlIDs := report.SummaryDetails.Controls.GetIDs()
+	sort.Strings(controlIDs)
+	controls := make([]Control, 0, len(controlIDs))
+	for _, controlID := range controlIDs {
+		control := report.SummaryDetails.Controls[controlID]
+		controls = append(controls, Control{
+			ControlID:         controlID,
+			Name:              control.GetName(),
+			Severity:          cautils.ControlSeverityToString(control.GetScoreFactor()),
+			Status:            string(control.GetStatus().Status()),
+			Score:             control.GetScore(),
+			ScoreFactor:       control.GetScoreFactor(),
+			Frameworks:        sorted(frameworks[controlID]),
+			FailedResources:   sorted(resources[controlID][apis.StatusFailed]),
+			ExcludedResources: sorted(resources[controlID][apis.StatusExcluded]),
+			PassedResources:   sorted(resources[controlID][apis.StatusPassed]),
+		})
+	}
+	return controls
+}
+
+func sorted(l []string) []string {
+	if l == nil {
+		return []string{}
+	}
+	sort.Strings(l)
+	return l
+}
+
+func toInterface(v interface{}) (interface{}, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var i interface{}
+	return i, json.Unmarshal(b, &i)
+}
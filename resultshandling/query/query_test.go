@@ -0,0 +1,54 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var resultsMock = []byte(`{
+	"summaryDetails": {
+		"score": 50,
+		"frameworks": [{"name": "nsa", "score": 50, "controls": {"C-0001": {}, "C-0002": {}}}],
+		"controls": {
+			"C-0001": {"controlID": "C-0001", "name": "high control", "status": "failed", "score": 50, "scoreFactor": 7},
+			"C-0002": {"controlID": "C-0002", "name": "low control", "status": "passed", "score": 0, "scoreFactor": 1}
+		}
+	},
+	"results": [
+		{"resourceID": "a", "controls": [{"controlID": "C-0001", "rules": [{"name": "r", "status": "failed"}]}, {"controlID": "C-0002", "rules": [{"name": "r", "status": "passed"}]}]},
+		{"resourceID": "b", "controls": [{"controlID": "C-0001", "rules": [{"name": "r", "status": "passed"}]}]}
+	]
+}`)
+
+func TestQuery(t *testing.T) {
+	document, err := NewDocument(resultsMock)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := Query(document, `.controls[] | select(.severity=="High" and .status=="failed") | .controlID`)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"C-0001"}, results)
+
+	results, err = Query(document, `.controls[0] | [.failedResources, .passedResources, .frameworks]`)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{[]interface{}{[]interface{}{"a"}, []interface{}{"b"}, []interface{}{"nsa"}}}, results)
+
+	// the fields of the results file
+	results, err = Query(document, `.summaryDetails.score`)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{float64(50)}, results)
+
+	_, err = Query(document, `.controls[`)
+	assert.Error(t, err)
+}
+
+func TestNewDocument(t *testing.T) {
+	if _, err := NewDocument([]byte(`[{"name": "nsa", "controlReports": []}]`)); err == nil {
+		t.Errorf("expected an error for a v1 results file")
+	}
+	if _, err := NewDocument([]byte(`not json`)); err == nil {
+		t.Errorf("expected an error for an invalid results file")
+	}
+}
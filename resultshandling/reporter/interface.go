@@ -1,6 +1,10 @@
 package reporter
 
-import "github.com/armosec/kubescape/cautils"
+import (
+	"context"
+
+	"github.com/armosec/kubescape/cautils"
+)
 
 type IReport interface {
 	ActionSendReport(opaSessionObj *cautils.OPASessionObj) error
@@ -8,3 +12,8 @@ type IReport interface {
 	SetClusterName(clusterName string)
 	DisplayReportURL()
 }
+
+// IContextReport a reporter whose submission is stopped when the context is done
+type IContextReport interface {
+	SetContext(ctx context.Context)
+}
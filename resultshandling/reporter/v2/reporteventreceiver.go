@@ -1,6 +1,7 @@
 package v2
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -18,9 +19,10 @@ import (
 	reporthandlingv2 "github.com/armosec/opa-utils/reporthandling/v2"
 )
 
-const MAX_REPORT_SIZE = 2097152 // 2 MB
+const MAX_REPORT_SIZE = 2097152 // 2 MB, the uncompressed size of a page of the report
 
 type ReportEventReceiver struct {
+	ctx                context.Context // the submission and its retries are stopped when the context is done
 	httpClient         *http.Client
 	clusterName        string
 	customerGUID       string
@@ -28,15 +30,20 @@ type ReportEventReceiver struct {
 	token              string
 	customerAdminEMail string
 	message            string
+	spoolDir           string // the pages of the reports which could not be submitted are spooled to the directory, when set
+	spooling           bool   // a page of the report was spooled, the next pages are spooled in order
+	compress           bool   // gzip the pages of the report
 }
 
 func NewReportEventReceiver(tenantConfig *cautils.ConfigObj) *ReportEventReceiver {
 	return &ReportEventReceiver{
+		ctx:                context.Background(),
 		httpClient:         &http.Client{},
 		clusterName:        tenantConfig.ClusterName,
 		customerGUID:       tenantConfig.AccountID,
 		token:              tenantConfig.Token,
 		customerAdminEMail: tenantConfig.CustomerAdminEMail,
+		compress:           true,
 	}
 }
 
@@ -58,7 +65,7 @@ func (report *ReportEventReceiver) ActionSendReport(opaSessionObj *cautils.OPASe
 
 	if err := report.prepareReport(opaSessionObj.Report); err != nil {
 		logger.L().Error("failed to publish results", helpers.Error(err))
-	} else {
+	} else if !report.spooling {
 		report.generateMessage()
 	}
 	logger.L().Debug("", helpers.String("account ID", report.customerGUID))
@@ -70,6 +77,22 @@ func (report *ReportEventReceiver) SetCustomerGUID(customerGUID string) {
 	report.customerGUID = customerGUID
 }
 
+// SetSpoolDir sets the spool directory of the submissions - the pages which could not be submitted after the retries are written
+// to the directory, and submitted by the next runs
+func (report *ReportEventReceiver) SetSpoolDir(spoolDir string) {
+	report.spoolDir = spoolDir
+}
+
+// SetContext sets the context of the submissions, the retries of a page are stopped when the context is done
+func (report *ReportEventReceiver) SetContext(ctx context.Context) {
+	report.ctx = ctx
+}
+
+// SetCompression sets the gzip compression of the pages of the reports, compressed by default
+func (report *ReportEventReceiver) SetCompression(compress bool) {
+	report.compress = compress
+}
+
 func (report *ReportEventReceiver) SetClusterName(clusterName string) {
 	report.clusterName = cautils.AdoptClusterName(clusterName) // clean cluster name
 }
@@ -78,6 +101,10 @@ func (report *ReportEventReceiver) prepareReport(postureReport *reporthandlingv2
 	report.initEventReceiverURL()
 	host := hostToString(report.eventReceiverURL, postureReport.ReportID)
 
+	if report.spoolDir != "" && !report.flushSpool() {
+		report.spooling = true // the report receiver is unavailable, the report is spooled after the previous reports
+	}
+
 	cautils.StartSpinner()
 	defer cautils.StopSpinner()
 
@@ -93,7 +120,14 @@ func (report *ReportEventReceiver) prepareReport(postureReport *reporthandlingv2
 
 func (report *ReportEventReceiver) sendResources(host string, postureReport *reporthandlingv2.PostureReport, reportCounter *int, isLastReport bool) error {
 	splittedPostureReport := setSubReport(postureReport)
-	counter := 0
+
+	// the size of a page without resources and results - the summary is part of every page
+	page, err := json.Marshal(splittedPostureReport)
+	if err != nil {
+		return fmt.Errorf("failed to marshal the report, reason: %v", err)
+	}
+	pageSize := len(page)
+	counter := pageSize
 
 	for _, v := range postureReport.Resources {
 		r, err := json.Marshal(v)
@@ -114,7 +148,7 @@ func (report *ReportEventReceiver) sendResources(host string, postureReport *rep
 			splittedPostureReport.Results = []resourcesresults.Result{}
 
 			// restart counter
-			counter = 0
+			counter = pageSize
 		}
 
 		counter += len(r)
@@ -140,7 +174,7 @@ func (report *ReportEventReceiver) sendResources(host string, postureReport *rep
 			splittedPostureReport.Resources = []reporthandling.Resource{}
 
 			// restart counter
-			counter = 0
+			counter = pageSize
 		}
 
 		counter += len(r)
@@ -159,9 +193,16 @@ func (report *ReportEventReceiver) sendReport(host string, postureReport *report
 	if err != nil {
 		return fmt.Errorf("in 'sendReport' failed to json.Marshal, reason: %v", err)
 	}
-	msg, err := getter.HttpPost(report.httpClient, host, nil, reqBody)
-	if err != nil {
-		return fmt.Errorf("%s, %v:%s", host, err, msg)
+	key := idempotencyKey(postureReport.ReportID, counter)
+	fileName := spoolFileName(postureReport.ReportGenerationTime, postureReport.ReportID, counter)
+	if report.spooling {
+		return report.spool(fileName, host, key, reqBody)
+	}
+	err = report.postReportWithRetry(report.ctx, host, key, reqBody)
+	if err != nil && report.spoolDir != "" && isRetryable(err) {
+		logger.L().Warning("failed to submit the report, spooled for the next run", helpers.String("spool", report.spoolDir), helpers.Error(err))
+		report.spooling = true
+		return report.spool(fileName, host, key, reqBody)
 	}
 	return err
 }
@@ -0,0 +1,66 @@
+package v2
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/armosec/opa-utils/reporthandling"
+	reporthandlingv2 "github.com/armosec/opa-utils/reporthandling/v2"
+)
+
+func TestSendResourcesCompressedPages(t *testing.T) {
+	pages := []reporthandlingv2.PostureReport{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Encoding") != "gzip" {
+			t.Error("expected a gzipped page")
+		}
+		reader, err := gzip.NewReader(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := ioutil.ReadAll(reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(body) > MAX_REPORT_SIZE {
+			t.Errorf("page of %d bytes, above the maximum size", len(body))
+		}
+		page := reporthandlingv2.PostureReport{}
+		if err := json.Unmarshal(body, &page); err != nil {
+			t.Fatal(err)
+		}
+		pages = append(pages, page)
+	}))
+	defer server.Close()
+
+	postureReport := &reporthandlingv2.PostureReport{ReportID: "report"}
+	for i := 0; i < 10; i++ {
+		postureReport.Resources = append(postureReport.Resources, reporthandling.Resource{
+			ResourceID: fmt.Sprintf("resource-%d", i),
+			Object:     map[string]interface{}{"data": strings.Repeat("a", MAX_REPORT_SIZE/4)},
+		})
+	}
+	report := &ReportEventReceiver{ctx: context.Background(), httpClient: server.Client(), compress: true}
+	reportCounter := 0
+	if err := report.sendResources(server.URL, postureReport, &reportCounter, false); err != nil {
+		t.Fatal(err)
+	}
+
+	resources := 0
+	for i := range pages {
+		resources += len(pages[i].Resources)
+		if pages[i].PaginationInfo.ReportNumber != i || pages[i].PaginationInfo.IsLastReport != (i == len(pages)-1) {
+			t.Errorf("unexpected pagination of page %d: %+v", i, pages[i].PaginationInfo)
+		}
+	}
+	if len(pages) < 3 || resources != 10 {
+		t.Errorf("expected the resources split to pages, received %d resources in %d pages", resources, len(pages))
+	}
+}
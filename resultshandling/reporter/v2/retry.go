@@ -0,0 +1,112 @@
+package v2
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/cautils/logger/helpers"
+)
+
+// IdempotencyKeyHeader the header of the idempotency key of a page of a report, the same key when the page is submitted again
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+var (
+	retryAttempts  = 5
+	retryBaseDelay = time.Second
+	retryMaxDelay  = 30 * time.Second
+)
+
+// retryableError an error of a submission which may succeed later - a network error, a 429 or a 5xx response
+type retryableError struct {
+	err error
+}
+
+func (e *retryableError) Error() string { return e.err.Error() }
+func (e *retryableError) Unwrap() error { return e.err }
+
+func isRetryable(err error) bool {
+	var retryable *retryableError
+	return errors.As(err, &retryable)
+}
+
+// idempotencyKey returns the idempotency key of a page of the report
+func idempotencyKey(reportID string, reportNumber int) string {
+	return fmt.Sprintf("%s-%d", reportID, reportNumber)
+}
+
+// postReport posts a page of the report
+func (report *ReportEventReceiver) postReport(ctx context.Context, host, key string, body []byte) error {
+	if report.compress {
+		compressed, err := gzipBody(body)
+		if err != nil {
+			return fmt.Errorf("failed to compress the report: %w", err)
+		}
+		body = compressed
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, host, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if report.compress {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	req.Header.Set(IdempotencyKeyHeader, key)
+	resp, err := report.httpClient.Do(req)
+	if err != nil {
+		return &retryableError{err: err}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		io.Copy(ioutil.Discard, resp.Body)
+		return nil
+	}
+	msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+	err = fmt.Errorf("%s, http-error: '%s', reason: '%s'", host, resp.Status, msg)
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		return &retryableError{err: err}
+	}
+	return err
+}
+
+// gzipBody compresses the body of a page of the report
+func gzipBody(body []byte) ([]byte, error) {
+	buf := bytes.Buffer{}
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(body); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// postReportWithRetry posts a page of the report, retrying the retryable errors with exponential backoff. The retries stop when the
+// context is done, the last error is returned - the page is spooled when a spool directory is set
+func (report *ReportEventReceiver) postReportWithRetry(ctx context.Context, host, key string, body []byte) error {
+	delay := retryBaseDelay
+	for attempt := 1; ; attempt++ {
+		err := report.postReport(ctx, host, key, body)
+		if err == nil || !isRetryable(err) || attempt >= retryAttempts || ctx.Err() != nil {
+			return err
+		}
+		logger.L().Debug("retrying the submission of the report", helpers.Int("attempt", attempt), helpers.Error(err))
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+		if delay *= 2; delay > retryMaxDelay {
+			delay = retryMaxDelay
+		}
+	}
+}
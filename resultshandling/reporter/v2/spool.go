@@ -0,0 +1,83 @@
+package v2
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/cautils/logger/helpers"
+)
+
+const (
+	spoolFileExt   = ".json"
+	rejectedSuffix = ".rejected" // the spooled pages rejected by the report receiver, kept but not submitted again
+)
+
+// spooledReport a page of a report which could not be submitted, submitted by the next runs with the same spool directory
+type spooledReport struct {
+	URL            string          `json:"url"`
+	IdempotencyKey string          `json:"idempotencyKey"`
+	Report         json.RawMessage `json:"report"`
+}
+
+// spoolFileName returns the file of a spooled page, the names are sorted by the generation time of the reports and by the page
+func spoolFileName(generationTime time.Time, reportID string, reportNumber int) string {
+	return fmt.Sprintf("%s-%s-%04d%s", generationTime.UTC().Format("20060102T150405Z"), reportID, reportNumber, spoolFileExt)
+}
+
+// spool writes a page of a report to the spool directory
+func (report *ReportEventReceiver) spool(fileName, host, key string, body []byte) error {
+	if err := os.MkdirAll(report.spoolDir, 0700); err != nil {
+		return fmt.Errorf("failed to create the spool directory: %w", err)
+	}
+	data, err := json.Marshal(&spooledReport{URL: host, IdempotencyKey: key, Report: body})
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(filepath.Join(report.spoolDir, fileName), data, 0600); err != nil {
+		return fmt.Errorf("failed to spool the report: %w", err)
+	}
+	return nil
+}
+
+// flushSpool submits the spooled pages in order, and removes the submitted pages.
+// Returns false when a page could not be submitted, the later pages are left in the spool directory
+func (report *ReportEventReceiver) flushSpool() bool {
+	files, err := filepath.Glob(filepath.Join(report.spoolDir, "*"+spoolFileExt))
+	if err != nil || len(files) == 0 {
+		return true
+	}
+	sort.Strings(files)
+	logger.L().Info("submitting the spooled reports", helpers.Int("pages", len(files)), helpers.String("spool", report.spoolDir))
+
+	for i, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			logger.L().Error("failed to read a spooled report", helpers.String("file", file), helpers.Error(err))
+			continue
+		}
+		spooled := spooledReport{}
+		if err := json.Unmarshal(data, &spooled); err != nil {
+			logger.L().Error("failed to parse a spooled report", helpers.String("file", file), helpers.Error(err))
+			os.Rename(file, strings.TrimSuffix(file, spoolFileExt)+rejectedSuffix)
+			continue
+		}
+		if err := report.postReportWithRetry(report.ctx, spooled.URL, spooled.IdempotencyKey, spooled.Report); err != nil {
+			if isRetryable(err) {
+				logger.L().Warning("failed to submit the spooled reports, submitting them by the next run", helpers.Int("pages", len(files)-i), helpers.Error(err))
+				return false
+			}
+			logger.L().Error("a spooled report was rejected", helpers.String("file", file), helpers.Error(err))
+			os.Rename(file, strings.TrimSuffix(file, spoolFileExt)+rejectedSuffix)
+			continue
+		}
+		os.Remove(file)
+	}
+	return true
+}
@@ -0,0 +1,87 @@
+package v2
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	reporthandlingv2 "github.com/armosec/opa-utils/reporthandling/v2"
+)
+
+func TestSendReportRetrySpool(t *testing.T) {
+	retryBaseDelay = time.Millisecond
+	defer func() { retryBaseDelay = time.Second }()
+
+	var mutex sync.Mutex
+	status := http.StatusServiceUnavailable
+	keys := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		keys = append(keys, r.Header.Get(IdempotencyKeyHeader))
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	spoolDir := t.TempDir()
+	report := &ReportEventReceiver{ctx: context.Background(), httpClient: server.Client(), spoolDir: spoolDir}
+	postureReport := &reporthandlingv2.PostureReport{ReportID: "report", ReportGenerationTime: time.Now()}
+
+	// retried, then spooled
+	if err := report.sendReport(server.URL, postureReport, 0, true); err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != retryAttempts || keys[0] != "report-0" || keys[retryAttempts-1] != "report-0" {
+		t.Errorf("expected %d attempts with the same idempotency key, received %v", retryAttempts, keys)
+	}
+	if files, _ := filepath.Glob(filepath.Join(spoolDir, "*.json")); len(files) != 1 {
+		t.Fatalf("expected a spooled page, found %v", files)
+	}
+
+	// submitted by the next run
+	status = http.StatusOK
+	keys = []string{}
+	if !(&ReportEventReceiver{ctx: context.Background(), httpClient: server.Client(), spoolDir: spoolDir}).flushSpool() {
+		t.Error("expected the spooled pages submitted")
+	}
+	if len(keys) != 1 || keys[0] != "report-0" {
+		t.Errorf("expected the spooled page submitted once, received %v", keys)
+	}
+	if files, _ := filepath.Glob(filepath.Join(spoolDir, "*")); len(files) != 0 {
+		t.Errorf("expected the submitted pages removed, found %v", files)
+	}
+
+	// not retried nor spooled
+	status = http.StatusBadRequest
+	keys = []string{}
+	if err := (&ReportEventReceiver{ctx: context.Background(), httpClient: server.Client(), spoolDir: spoolDir}).sendReport(server.URL, postureReport, 0, true); err == nil {
+		t.Error("expected an error of a rejected report")
+	}
+	if len(keys) != 1 {
+		t.Errorf("expected a single attempt, received %d", len(keys))
+	}
+}
+
+func TestPostReportWithRetryCancelled(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+	start := time.Now()
+	err := (&ReportEventReceiver{ctx: ctx, httpClient: server.Client()}).postReportWithRetry(ctx, server.URL, "report-0", []byte("{}"))
+	if err == nil || !isRetryable(err) {
+		t.Errorf("expected the retryable error of the last attempt, received %v", err)
+	}
+	if attempts != 1 || time.Since(start) > retryBaseDelay {
+		t.Errorf("expected the backoff stopped by the context, %d attempts in %s", attempts, time.Since(start))
+	}
+}
@@ -20,7 +20,7 @@ func finalizeReport(opaSessionObj *cautils.OPASessionObj) {
 
 	if len(opaSessionObj.Report.Resources) == 0 {
 		opaSessionObj.Report.Resources = make([]reporthandling.Resource, len(opaSessionObj.AllResources))
-		finalizeResources(opaSessionObj.Report.Resources, opaSessionObj.AllResources)
+		finalizeResources(opaSessionObj.Report.Resources, opaSessionObj.AllResources, opaSessionObj.Redact)
 		opaSessionObj.AllResources = nil
 	}
 
@@ -33,11 +33,11 @@ func finalizeResults(results []resourcesresults.Result, resourcesResult map[stri
 	}
 }
 
-func finalizeResources(resources []reporthandling.Resource, allResources map[string]workloadinterface.IMetadata) {
+func finalizeResources(resources []reporthandling.Resource, allResources map[string]workloadinterface.IMetadata, redact *cautils.RedactOptions) {
 	index := 0
 	for resourceID := range allResources {
 		if obj, ok := allResources[resourceID]; ok {
-			r := *reporthandling.NewResource(obj.GetObject())
+			r := *reporthandling.NewResource(redact.RedactObject(obj.GetObject()))
 			r.ResourceID = resourceID
 			resources[index] = r
 		}
@@ -1,6 +1,12 @@
 package resultshandling
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
 	"github.com/armosec/kubescape/cautils"
 	"github.com/armosec/kubescape/cautils/logger"
 	"github.com/armosec/kubescape/cautils/logger/helpers"
@@ -10,36 +16,64 @@ import (
 
 	"github.com/armosec/kubescape/resultshandling/reporter"
 	"github.com/armosec/opa-utils/reporthandling"
+	"github.com/armosec/opa-utils/reporthandling/results/v1/reportsummary"
 )
 
 type ResultsHandler struct {
 	opaSessionObj *chan *cautils.OPASessionObj
 	reporterObj   reporter.IReport
-	printerObj    printer.IPrinter
+	printerObjs   []printer.IPrinter
 }
 
-func NewResultsHandler(opaSessionObj *chan *cautils.OPASessionObj, reporterObj reporter.IReport, printerObj printer.IPrinter) *ResultsHandler {
+func NewResultsHandler(opaSessionObj *chan *cautils.OPASessionObj, reporterObj reporter.IReport, printerObjs []printer.IPrinter) *ResultsHandler {
 	return &ResultsHandler{
 		opaSessionObj: opaSessionObj,
 		reporterObj:   reporterObj,
-		printerObj:    printerObj,
+		printerObjs:   printerObjs,
 	}
 }
 
-func (resultsHandler *ResultsHandler) HandleResults(scanInfo *cautils.ScanInfo) float32 {
+// HandleResults prints and reports the results of the scan, and returns the summary of the results.
+// A failure of a printer does not stop the other printers nor the report, the failures are returned with the summary.
+// The context error is returned when the context is done before the results are received, e.g. the scan failed
+func (resultsHandler *ResultsHandler) HandleResults(ctx context.Context, scanInfo *cautils.ScanInfo) (*reportsummary.SummaryDetails, error) {
 
-	opaSessionObj := <-*resultsHandler.opaSessionObj
+	var opaSessionObj *cautils.OPASessionObj
+	select {
+	case opaSessionObj = <-*resultsHandler.opaSessionObj:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
 
-	resultsHandler.printerObj.ActionPrint(opaSessionObj)
+	// all printers print the same session object
+	start := time.Now()
+	printErrs := []string{}
+	for _, printerObj := range resultsHandler.printerObjs {
+		if err := printerObj.ActionPrint(opaSessionObj); err != nil {
+			printErrs = append(printErrs, err.Error())
+		}
+	}
+	opaSessionObj.Profile.AddPhase(cautils.PhasePrinting, start)
 
+	start = time.Now()
 	if err := resultsHandler.reporterObj.ActionSendReport(opaSessionObj); err != nil {
 		logger.L().Error(err.Error())
 	}
+	opaSessionObj.Profile.AddPhase(cautils.PhaseResultsSubmission, start)
 
 	score := opaSessionObj.Report.SummaryDetails.Score
-	resultsHandler.printerObj.Score(score)
+	// print the score once, by the first printer
+	if len(resultsHandler.printerObjs) > 0 {
+		resultsHandler.printerObjs[0].Score(score)
+	}
+	if scanInfo.Profile {
+		opaSessionObj.Profile.Print(os.Stderr, opaSessionObj.EvaluationTime)
+	}
 
-	return score
+	if len(printErrs) > 0 {
+		return &opaSessionObj.Report.SummaryDetails, fmt.Errorf("failed to print the results: %s", strings.Join(printErrs, "; "))
+	}
+	return &opaSessionObj.Report.SummaryDetails, nil
 }
 
 // CalculatePostureScore calculate final score
@@ -54,7 +88,8 @@ func CalculatePostureScore(postureReport *reporthandling.PostureReport) float32
 	return (float32(len(allResources)) - float32(len(failedResources))) / float32(len(allResources))
 }
 
-func NewPrinter(printFormat, formatVersion string, verboseMode bool) printer.IPrinter {
+func NewPrinter(printFormat string, scanInfo *cautils.ScanInfo) printer.IPrinter {
+	formatVersion, verboseMode := scanInfo.FormatVersion, scanInfo.VerboseMode
 
 	switch printFormat {
 	case printer.JsonFormat:
@@ -70,7 +105,31 @@ func NewPrinter(printFormat, formatVersion string, verboseMode bool) printer.IPr
 	case printer.PrometheusFormat:
 		return printerv1.NewPrometheusPrinter(verboseMode)
 	case printer.PdfFormat:
-		return printerv2.NewPdfPrinter()
+		return printerv2.NewPdfPrinter(verboseMode, &scanInfo.PdfOptions)
+	case printer.SARIFFormat:
+		return printerv2.NewSarifPrinter()
+	case printer.CSVFormat:
+		return printerv2.NewCsvPrinter()
+	case printer.XLSXFormat:
+		return printerv2.NewXlsxPrinter()
+	case printer.NDJSONFormat:
+		return printerv2.NewNdjsonPrinter()
+	case printer.DotFormat:
+		return printerv2.NewDotPrinter()
+	case printer.CEFFormat:
+		return printerv2.NewCefPrinter()
+	case printer.OSCALFormat:
+		return printerv2.NewOscalPrinter()
+	case printer.GithubFormat:
+		return printerv2.NewGithubAnnotationsPrinter()
+	case printer.CodeQualityFormat:
+		return printerv2.NewCodeQualityPrinter()
+	case printer.MarkdownFormat:
+		return printerv2.NewMarkdownPrinter()
+	case printer.HTMLFormat:
+		return printerv2.NewHtmlPrinter()
+	case printer.GoTemplateFormat:
+		return printerv2.NewTemplatePrinter(scanInfo.OutputTemplate)
 	default:
 		return printerv2.NewPrettyPrinter(verboseMode, formatVersion)
 	}
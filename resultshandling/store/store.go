@@ -0,0 +1,227 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/armosec/kubescape/cautils/getter"
+
+	_ "modernc.org/sqlite" // pure go sqlite driver, kubescape is built without cgo
+)
+
+const defaultStoreFile = "results.db"
+
+var schema = []string{
+	`CREATE TABLE IF NOT EXISTS scans (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		generated_at TIMESTAMP NOT NULL,
+		cluster_name TEXT NOT NULL,
+		score REAL NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS framework_scores (
+		scan_id INTEGER NOT NULL REFERENCES scans(id) ON DELETE CASCADE,
+		name TEXT NOT NULL,
+		score REAL NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS control_results (
+		scan_id INTEGER NOT NULL REFERENCES scans(id) ON DELETE CASCADE,
+		control_id TEXT NOT NULL,
+		name TEXT NOT NULL,
+		status TEXT NOT NULL,
+		score REAL NOT NULL,
+		failed_resources INTEGER NOT NULL,
+		total_resources INTEGER NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS control_results_control_id ON control_results(control_id)`,
+}
+
+// ScanSummary the summary of a single scan, as persisted in the store
+type ScanSummary struct {
+	ID          int64            `json:"id"`
+	GeneratedAt time.Time        `json:"generatedAt"`
+	ClusterName string           `json:"clusterName"`
+	Score       float32          `json:"score"`
+	Frameworks  []FrameworkScore `json:"frameworks,omitempty"`
+	Controls    []ControlResult  `json:"controls,omitempty"`
+}
+
+type FrameworkScore struct {
+	Name  string  `json:"name"`
+	Score float32 `json:"score"`
+}
+
+type ControlResult struct {
+	ControlID       string  `json:"controlID"`
+	Name            string  `json:"name"`
+	Status          string  `json:"status"`
+	Score           float32 `json:"score"`
+	FailedResources int     `json:"failedResources"`
+	TotalResources  int     `json:"totalResources"`
+}
+
+// ControlTrend the result of a control in a single scan
+type ControlTrend struct {
+	ScanID      int64     `json:"scanID"`
+	GeneratedAt time.Time `json:"generatedAt"`
+	ClusterName string    `json:"clusterName"`
+	ControlResult
+}
+
+// ResultsStore persists the scan summaries in a local SQLite database
+type ResultsStore struct {
+	db *sql.DB
+}
+
+// DefaultStorePath returns the path of the database in the kubescape cache directory
+func DefaultStorePath() string {
+	return filepath.Join(getter.DefaultLocalStore, defaultStoreFile)
+}
+
+// NewResultsStore opens the database, creating it when missing. The default path is used when the path is empty
+func NewResultsStore(path string) (*ResultsStore, error) {
+	if path == "" {
+		path = DefaultStorePath()
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("sqlite", fmt.Sprintf("file:%s?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)", path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open results store '%s': %w", path, err)
+	}
+	for _, statement := range schema {
+		if _, err := db.Exec(statement); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to initialize results store '%s': %w", path, err)
+		}
+	}
+	return &ResultsStore{db: db}, nil
+}
+
+func (resultsStore *ResultsStore) Close() error {
+	return resultsStore.db.Close()
+}
+
+// Save persists the scan summary and returns its ID
+func (resultsStore *ResultsStore) Save(scan *ScanSummary) (int64, error) {
+	tx, err := resultsStore.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`INSERT INTO scans (generated_at, cluster_name, score) VALUES (?, ?, ?)`, scan.GeneratedAt.UTC(), scan.ClusterName, scan.Score)
+	if err != nil {
+		return 0, err
+	}
+	scanID, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	for _, framework := range scan.Frameworks {
+		if _, err := tx.Exec(`INSERT INTO framework_scores (scan_id, name, score) VALUES (?, ?, ?)`, scanID, framework.Name, framework.Score); err != nil {
+			return 0, err
+		}
+	}
+	for _, control := range scan.Controls {
+		if _, err := tx.Exec(`INSERT INTO control_results (scan_id, control_id, name, status, score, failed_resources, total_resources) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			scanID, control.ControlID, control.Name, control.Status, control.Score, control.FailedResources, control.TotalResources); err != nil {
+			return 0, err
+		}
+	}
+	return scanID, tx.Commit()
+}
+
+// ListScans returns the latest scans with the framework scores, oldest first. All of the scans are returned when the limit is 0
+func (resultsStore *ResultsStore) ListScans(clusterName string, limit int) ([]ScanSummary, error) {
+	query := `SELECT id, generated_at, cluster_name, score FROM scans WHERE (? = '' OR cluster_name = ?) ORDER BY id DESC`
+	args := []interface{}{clusterName, clusterName}
+	if limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, limit)
+	}
+	rows, err := resultsStore.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	scans := []ScanSummary{}
+	for rows.Next() {
+		scan := ScanSummary{}
+		if err := rows.Scan(&scan.ID, &scan.GeneratedAt, &scan.ClusterName, &scan.Score); err != nil {
+			return nil, err
+		}
+		scans = append(scans, scan)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// oldest first
+	for i, j := 0, len(scans)-1; i < j; i, j = i+1, j-1 {
+		scans[i], scans[j] = scans[j], scans[i]
+	}
+	for i := range scans {
+		if scans[i].Frameworks, err = resultsStore.listFrameworkScores(scans[i].ID); err != nil {
+			return nil, err
+		}
+	}
+	return scans, nil
+}
+
+func (resultsStore *ResultsStore) listFrameworkScores(scanID int64) ([]FrameworkScore, error) {
+	rows, err := resultsStore.db.Query(`SELECT name, score FROM framework_scores WHERE scan_id = ? ORDER BY name`, scanID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	frameworks := []FrameworkScore{}
+	for rows.Next() {
+		framework := FrameworkScore{}
+		if err := rows.Scan(&framework.Name, &framework.Score); err != nil {
+			return nil, err
+		}
+		frameworks = append(frameworks, framework)
+	}
+	return frameworks, rows.Err()
+}
+
+// ControlTrend returns the results of the control in the latest scans, oldest first
+func (resultsStore *ResultsStore) ControlTrend(controlID, clusterName string, limit int) ([]ControlTrend, error) {
+	query := `SELECT s.id, s.generated_at, s.cluster_name, c.control_id, c.name, c.status, c.score, c.failed_resources, c.total_resources
+		FROM control_results c JOIN scans s ON s.id = c.scan_id
+		WHERE c.control_id = ? AND (? = '' OR s.cluster_name = ?) ORDER BY s.id DESC`
+	args := []interface{}{controlID, clusterName, clusterName}
+	if limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, limit)
+	}
+	rows, err := resultsStore.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	trend := []ControlTrend{}
+	for rows.Next() {
+		t := ControlTrend{}
+		if err := rows.Scan(&t.ScanID, &t.GeneratedAt, &t.ClusterName, &t.ControlID, &t.Name, &t.Status, &t.Score, &t.FailedResources, &t.TotalResources); err != nil {
+			return nil, err
+		}
+		trend = append(trend, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// oldest first
+	for i, j := 0, len(trend)-1; i < j; i, j = i+1, j-1 {
+		trend[i], trend[j] = trend[j], trend[i]
+	}
+	return trend, nil
+}
@@ -0,0 +1,62 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResultsStore(t *testing.T) {
+	resultsStore, err := NewResultsStore(filepath.Join(t.TempDir(), "store", "results.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resultsStore.Close()
+
+	generatedAt := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, score := range []float32{60, 40, 20} {
+		clusterName := "prod"
+		if i == 1 {
+			clusterName = "dev"
+		}
+		_, err := resultsStore.Save(&ScanSummary{
+			GeneratedAt: generatedAt.Add(time.Duration(i) * time.Hour),
+			ClusterName: clusterName,
+			Score:       score,
+			Frameworks:  []FrameworkScore{{Name: "nsa", Score: score}, {Name: "mitre", Score: score / 2}},
+			Controls:    []ControlResult{{ControlID: "C-0001", Name: "control", Status: "failed", Score: score, FailedResources: i, TotalResources: 3}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	scans, err := resultsStore.ListScans("", 0)
+	assert.NoError(t, err)
+	if assert.Len(t, scans, 3) {
+		assert.Equal(t, []float32{60, 40, 20}, []float32{scans[0].Score, scans[1].Score, scans[2].Score})
+		assert.Equal(t, generatedAt, scans[0].GeneratedAt.UTC())
+		assert.Equal(t, []FrameworkScore{{Name: "mitre", Score: 10}, {Name: "nsa", Score: 20}}, scans[2].Frameworks)
+	}
+
+	// latest scans of a cluster
+	scans, err = resultsStore.ListScans("prod", 1)
+	assert.NoError(t, err)
+	if assert.Len(t, scans, 1) {
+		assert.Equal(t, float32(20), scans[0].Score)
+	}
+
+	trend, err := resultsStore.ControlTrend("C-0001", "prod", 0)
+	assert.NoError(t, err)
+	if assert.Len(t, trend, 2) {
+		assert.Equal(t, 0, trend[0].FailedResources)
+		assert.Equal(t, 2, trend[1].FailedResources)
+		assert.Equal(t, "prod", trend[1].ClusterName)
+	}
+
+	trend, err = resultsStore.ControlTrend("C-0002", "", 0)
+	assert.NoError(t, err)
+	assert.Empty(t, trend)
+}
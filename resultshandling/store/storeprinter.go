@@ -0,0 +1,68 @@
+package store
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/cautils/logger/helpers"
+)
+
+// StorePrinter is a printer that persists the summary of the scan in the results store
+type StorePrinter struct {
+	path string
+}
+
+func NewStorePrinter(path string) *StorePrinter {
+	return &StorePrinter{path: path}
+}
+
+func (storePrinter *StorePrinter) SetWriter(outputFile string) {}
+
+func (storePrinter *StorePrinter) Score(score float32) {}
+
+func (storePrinter *StorePrinter) ActionPrint(opaSessionObj *cautils.OPASessionObj) error {
+	resultsStore, err := NewResultsStore(storePrinter.path)
+	if err != nil {
+		return fmt.Errorf("failed to open results store '%s': %w", storePrinter.path, err)
+	}
+	defer resultsStore.Close()
+
+	scanID, err := resultsStore.Save(NewScanSummary(opaSessionObj))
+	if err != nil {
+		return fmt.Errorf("failed to store scan results: %w", err)
+	}
+	logger.L().Success("Scan results stored", helpers.Int("scan ID", int(scanID)))
+	return nil
+}
+
+// NewScanSummary returns the summary of the scan to persist
+func NewScanSummary(opaSessionObj *cautils.OPASessionObj) *ScanSummary {
+	summaryDetails := &opaSessionObj.Report.SummaryDetails
+	scan := &ScanSummary{
+		GeneratedAt: opaSessionObj.Report.ReportGenerationTime,
+		ClusterName: opaSessionObj.Report.ClusterName,
+		Score:       summaryDetails.Score,
+		Frameworks:  []FrameworkScore{},
+		Controls:    []ControlResult{},
+	}
+	for _, framework := range summaryDetails.Frameworks {
+		scan.Frameworks = append(scan.Frameworks, FrameworkScore{Name: framework.GetName(), Score: framework.GetScore()})
+	}
+
+	controlIDs := summaryDetails.Controls.GetIDs()
+	sort.Strings(controlIDs)
+	for _, controlID := range controlIDs {
+		control := summaryDetails.Controls[controlID]
+		scan.Controls = append(scan.Controls, ControlResult{
+			ControlID:       controlID,
+			Name:            control.GetName(),
+			Status:          string(control.GetStatus().Status()),
+			Score:           control.GetScore(),
+			FailedResources: control.NumberOfResources().Failed(),
+			TotalResources:  control.NumberOfResources().All(),
+		})
+	}
+	return scan
+}
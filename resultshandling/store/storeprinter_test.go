@@ -0,0 +1,20 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/armosec/kubescape/cautils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStorePrinter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.db")
+	assert.NoError(t, NewStorePrinter(path).ActionPrint(cautils.NewOPASessionObjMock()))
+
+	// the store can not be opened under a file
+	file := filepath.Join(t.TempDir(), "file")
+	assert.NoError(t, os.WriteFile(file, []byte{}, 0644))
+	assert.Error(t, NewStorePrinter(filepath.Join(file, "results.db")).ActionPrint(cautils.NewOPASessionObjMock()))
+}
@@ -0,0 +1,156 @@
+package uploader
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest/adal"
+)
+
+// https://docs.microsoft.com/en-us/rest/api/storageservices/put-blob
+
+const (
+	azureStorageVersion  = "2020-10-02"
+	azureStorageResource = "https://storage.azure.com/"
+	azureADEndpoint      = "https://login.microsoftonline.com/"
+)
+
+// AzureUploader uploads to Azure Blob Storage. The storage account is set by AZURE_STORAGE_ACCOUNT, the credentials are taken from
+// AZURE_STORAGE_SAS_TOKEN, AZURE_STORAGE_KEY, a service principal (AZURE_TENANT_ID, AZURE_CLIENT_ID, AZURE_CLIENT_SECRET) or the managed identity, in this order
+type AzureUploader struct {
+	account   string
+	container string
+	blob      string
+}
+
+func NewAzureUploader(container, blob string) (*AzureUploader, error) {
+	account := os.Getenv("AZURE_STORAGE_ACCOUNT")
+	if account == "" {
+		return nil, fmt.Errorf("bad argument: AZURE_STORAGE_ACCOUNT must be set when uploading to Azure Blob Storage")
+	}
+	return &AzureUploader{account: account, container: container, blob: blob}, nil
+}
+
+func (azureUploader *AzureUploader) Upload(localFile string) error {
+	blobURL := fmt.Sprintf("https://%s.blob.core.windows.net/%s/%s", azureUploader.account, azureUploader.container, (&url.URL{Path: azureUploader.blob}).EscapedPath())
+	if sasToken := os.Getenv("AZURE_STORAGE_SAS_TOKEN"); sasToken != "" {
+		blobURL += "?" + strings.TrimPrefix(sasToken, "?")
+	}
+
+	req, _, err := newRequest("PUT", blobURL, localFile)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Ms-Blob-Type", "BlockBlob")
+	req.Header.Set("X-Ms-Version", azureStorageVersion)
+	req.Header.Set("X-Ms-Date", time.Now().UTC().Format(http.TimeFormat))
+
+	switch {
+	case os.Getenv("AZURE_STORAGE_SAS_TOKEN") != "":
+		// the request is authorized by the token
+	case os.Getenv("AZURE_STORAGE_KEY") != "":
+		authorization, err := azureSharedKey(req, azureUploader.account, os.Getenv("AZURE_STORAGE_KEY"))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", authorization)
+	default:
+		token, err := azureADToken()
+		if err != nil {
+			return fmt.Errorf("failed to retrieve Azure credentials: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return doRequest(req)
+}
+
+// azureADToken returns a token of a service principal when set in the environment, otherwise of the managed identity
+func azureADToken() (string, error) {
+	var spt *adal.ServicePrincipalToken
+	tenantID, clientID, clientSecret := os.Getenv("AZURE_TENANT_ID"), os.Getenv("AZURE_CLIENT_ID"), os.Getenv("AZURE_CLIENT_SECRET")
+	if tenantID != "" && clientID != "" && clientSecret != "" {
+		oauthConfig, err := adal.NewOAuthConfig(azureADEndpoint, tenantID)
+		if err != nil {
+			return "", err
+		}
+		if spt, err = adal.NewServicePrincipalToken(*oauthConfig, clientID, clientSecret, azureStorageResource); err != nil {
+			return "", err
+		}
+	} else {
+		var err error
+		if spt, err = adal.NewServicePrincipalTokenFromManagedIdentity(azureStorageResource, &adal.ManagedIdentityOptions{ClientID: clientID}); err != nil {
+			return "", err
+		}
+	}
+	if err := spt.EnsureFresh(); err != nil {
+		return "", err
+	}
+	return spt.OAuthToken(), nil
+}
+
+// azureSharedKey returns the shared key authorization header of the request
+// https://docs.microsoft.com/en-us/rest/api/storageservices/authorize-with-shared-key
+func azureSharedKey(req *http.Request, account, key string) (string, error) {
+	decodedKey, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return "", fmt.Errorf("invalid AZURE_STORAGE_KEY: %w", err)
+	}
+
+	contentLength := ""
+	if req.ContentLength > 0 {
+		contentLength = strconv.FormatInt(req.ContentLength, 10)
+	}
+
+	msHeaders := []string{}
+	for name := range req.Header {
+		if name = strings.ToLower(name); strings.HasPrefix(name, "x-ms-") {
+			msHeaders = append(msHeaders, name)
+		}
+	}
+	sort.Strings(msHeaders)
+	canonicalizedHeaders := ""
+	for _, name := range msHeaders {
+		canonicalizedHeaders += fmt.Sprintf("%s:%s\n", name, strings.TrimSpace(req.Header.Get(name)))
+	}
+
+	canonicalizedResource := fmt.Sprintf("/%s%s", account, req.URL.EscapedPath())
+	query := req.URL.Query()
+	params := []string{}
+	for name := range query {
+		params = append(params, name)
+	}
+	sort.Strings(params)
+	for _, name := range params {
+		values := query[name]
+		sort.Strings(values)
+		canonicalizedResource += fmt.Sprintf("\n%s:%s", strings.ToLower(name), strings.Join(values, ","))
+	}
+
+	stringToSign := strings.Join([]string{
+		req.Method,
+		req.Header.Get("Content-Encoding"),
+		req.Header.Get("Content-Language"),
+		contentLength,
+		req.Header.Get("Content-Md5"),
+		req.Header.Get("Content-Type"),
+		"", // Date, x-ms-date is used instead
+		req.Header.Get("If-Modified-Since"),
+		req.Header.Get("If-Match"),
+		req.Header.Get("If-None-Match"),
+		req.Header.Get("If-Unmodified-Since"),
+		req.Header.Get("Range"),
+	}, "\n") + "\n" + canonicalizedHeaders + canonicalizedResource
+
+	mac := hmac.New(sha256.New, decodedKey)
+	mac.Write([]byte(stringToSign))
+	return fmt.Sprintf("SharedKey %s:%s", account, base64.StdEncoding.EncodeToString(mac.Sum(nil))), nil
+}
@@ -0,0 +1,43 @@
+package uploader
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"golang.org/x/oauth2/google"
+)
+
+// https://cloud.google.com/storage/docs/uploading-objects#rest-upload-objects
+
+const gcsScope = "https://www.googleapis.com/auth/devstorage.read_write"
+
+// GCSUploader uploads to Google Cloud Storage, the credentials are loaded by the application default credentials chain
+// (GOOGLE_APPLICATION_CREDENTIALS, gcloud config, metadata server)
+type GCSUploader struct {
+	bucket string
+	object string
+}
+
+func NewGCSUploader(bucket, object string) *GCSUploader {
+	return &GCSUploader{bucket: bucket, object: object}
+}
+
+func (gcsUploader *GCSUploader) Upload(localFile string) error {
+	tokenSource, err := google.DefaultTokenSource(context.Background(), gcsScope)
+	if err != nil {
+		return fmt.Errorf("failed to load Google credentials: %w", err)
+	}
+	token, err := tokenSource.Token()
+	if err != nil {
+		return fmt.Errorf("failed to retrieve Google credentials: %w", err)
+	}
+
+	uploadURL := fmt.Sprintf("https://storage.googleapis.com/upload/storage/v1/b/%s/o?uploadType=media&name=%s", url.PathEscape(gcsUploader.bucket), url.QueryEscape(gcsUploader.object))
+	req, _, err := newRequest("POST", uploadURL, localFile)
+	if err != nil {
+		return err
+	}
+	token.SetAuthHeader(req)
+	return doRequest(req)
+}
@@ -0,0 +1,65 @@
+package uploader
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/config"
+)
+
+// https://docs.aws.amazon.com/AmazonS3/latest/API/API_PutObject.html
+
+const s3DefaultRegion = "us-east-1"
+
+// S3Uploader uploads to AWS S3, the credentials and the region are loaded by the AWS default chain (environment, shared config, IAM role)
+type S3Uploader struct {
+	bucket string
+	key    string
+}
+
+func NewS3Uploader(bucket, key string) *S3Uploader {
+	return &S3Uploader{bucket: bucket, key: key}
+}
+
+func (s3Uploader *S3Uploader) Upload(localFile string) error {
+	ctx := context.Background()
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	credentials, err := cfg.Credentials.Retrieve(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve AWS credentials: %w", err)
+	}
+	region := cfg.Region
+	if region == "" {
+		region = s3DefaultRegion
+	}
+
+	req, content, err := newRequest("PUT", s3Uploader.objectURL(region), localFile)
+	if err != nil {
+		return err
+	}
+	payloadHash := sha256.Sum256(content)
+	req.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(payloadHash[:]))
+	if err := v4.NewSigner().SignHTTP(ctx, credentials, req, hex.EncodeToString(payloadHash[:]), "s3", region, time.Now()); err != nil {
+		return fmt.Errorf("failed to sign request: %w", err)
+	}
+	return doRequest(req)
+}
+
+// objectURL returns the virtual-hosted style URL of the object, or a path style URL of a custom endpoint (AWS_ENDPOINT_URL, e.g. MinIO)
+func (s3Uploader *S3Uploader) objectURL(region string) string {
+	key := (&url.URL{Path: s3Uploader.key}).EscapedPath()
+	if endpoint := os.Getenv("AWS_ENDPOINT_URL"); endpoint != "" {
+		return fmt.Sprintf("%s/%s/%s", strings.TrimSuffix(endpoint, "/"), s3Uploader.bucket, key)
+	}
+	return fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", s3Uploader.bucket, region, key)
+}
@@ -0,0 +1,140 @@
+package uploader
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/cautils/logger/helpers"
+	"github.com/armosec/kubescape/resultshandling/printer"
+)
+
+// IUploader uploads a local file to object storage
+type IUploader interface {
+	Upload(localFile string) error
+}
+
+// schemes of the supported object storage URLs
+var remoteSchemes = []string{"s3", "gs", "az"}
+
+// IsRemoteOutput returns true when the output is an object storage URL, e.g. s3://bucket/path/report.json
+func IsRemoteOutput(output string) bool {
+	u, err := url.Parse(output)
+	if err != nil {
+		return false
+	}
+	return cautils.StringInSlice(remoteSchemes, u.Scheme) != cautils.ValueNotFound
+}
+
+// NewUploader returns the uploader of the object storage URL. The credentials are taken from the environment, same as the cloud SDKs
+func NewUploader(destination string) (IUploader, error) {
+	u, err := url.Parse(destination)
+	if err != nil {
+		return nil, fmt.Errorf("bad argument: invalid output '%s': %w", destination, err)
+	}
+	bucket, key := u.Host, strings.TrimPrefix(u.Path, "/")
+	if bucket == "" || key == "" {
+		return nil, fmt.Errorf("bad argument: invalid output '%s', expecting %s://<bucket>/<path>", destination, u.Scheme)
+	}
+	switch u.Scheme {
+	case "s3":
+		return NewS3Uploader(bucket, key), nil
+	case "gs":
+		return NewGCSUploader(bucket, key), nil
+	case "az":
+		return NewAzureUploader(bucket, key)
+	}
+	return nil, fmt.Errorf("bad argument: unsupported output '%s'. Supported: %s", destination, strings.Join(remoteSchemes, "://, ")+"://")
+}
+
+// UploadPrinter writes the results of the printer to a local file and uploads the file to object storage
+type UploadPrinter struct {
+	printer.IPrinter
+	uploader    IUploader
+	destination string
+	localFile   string
+	err         error // the error of setting the writer, returned by ActionPrint
+}
+
+func NewUploadPrinter(p printer.IPrinter, uploader IUploader) *UploadPrinter {
+	return &UploadPrinter{
+		IPrinter: p,
+		uploader: uploader,
+	}
+}
+
+// SetWriter sets the writer of the printer to a local temporary file with the same name as the remote file.
+// Failing to create the temporary directory fails the print, see ActionPrint
+func (uploadPrinter *UploadPrinter) SetWriter(outputFile string) {
+	uploadPrinter.destination = outputFile
+	dir, err := os.MkdirTemp("", "kubescape-output")
+	if err != nil {
+		uploadPrinter.err = fmt.Errorf("failed to create output directory: %w", err)
+		return
+	}
+	uploadPrinter.localFile = filepath.Join(dir, filepath.Base(outputFile))
+	uploadPrinter.IPrinter.SetWriter(uploadPrinter.localFile)
+}
+
+func (uploadPrinter *UploadPrinter) ActionPrint(opaSessionObj *cautils.OPASessionObj) error {
+	if uploadPrinter.err != nil {
+		return uploadPrinter.err
+	}
+	// the output is written to a temporary directory, removed also when the output failed
+	defer os.RemoveAll(filepath.Dir(uploadPrinter.localFile))
+	if err := uploadPrinter.IPrinter.ActionPrint(opaSessionObj); err != nil {
+		return err
+	}
+
+	if err := uploadPrinter.uploader.Upload(uploadPrinter.localFile); err != nil {
+		return fmt.Errorf("failed to upload scan results to '%s': %w", uploadPrinter.destination, err)
+	}
+	logger.L().Success("Scan results uploaded", helpers.String("destination", uploadPrinter.destination))
+	return nil
+}
+
+var httpClient = &http.Client{Timeout: 5 * time.Minute}
+
+// newRequest returns a request with the content of the file as the body
+func newRequest(method, url, localFile string) (*http.Request, []byte, error) {
+	content, err := os.ReadFile(localFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	req, err := http.NewRequest(method, url, bytes.NewReader(content))
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("Content-Type", contentType(localFile))
+	return req, content, nil
+}
+
+// doRequest sends the request and checks the response status
+func doRequest(req *http.Request) error {
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("upload to %s failed, status: %s, response: %s", req.URL.Host, resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+func contentType(file string) string {
+	if t := mime.TypeByExtension(filepath.Ext(file)); t != "" {
+		return t
+	}
+	return "application/octet-stream"
+}
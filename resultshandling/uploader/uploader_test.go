@@ -0,0 +1,141 @@
+package uploader
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/armosec/kubescape/cautils"
+)
+
+func TestIsRemoteOutput(t *testing.T) {
+	tests := map[string]bool{
+		"s3://bucket/report.json":  true,
+		"gs://bucket/a/report.pdf": true,
+		"az://container/report":    true,
+		"report.json":              false,
+		"/tmp/report.json":         false,
+		"https://example.com/x":    false,
+		"":                         false,
+	}
+	for output, expected := range tests {
+		if IsRemoteOutput(output) != expected {
+			t.Errorf("IsRemoteOutput(%s) = %v, expected %v", output, !expected, expected)
+		}
+	}
+}
+
+func TestNewUploader(t *testing.T) {
+	if _, err := NewUploader("s3://bucket"); err == nil {
+		t.Errorf("expected an error for a missing object path")
+	}
+	u, err := NewUploader("gs://bucket/scans/report.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gcs, ok := u.(*GCSUploader); !ok || gcs.bucket != "bucket" || gcs.object != "scans/report.json" {
+		t.Errorf("unexpected uploader: %#v", u)
+	}
+
+	t.Setenv("AZURE_STORAGE_ACCOUNT", "")
+	if _, err := NewUploader("az://container/report.json"); err == nil {
+		t.Errorf("expected an error for a missing storage account")
+	}
+}
+
+func TestS3Upload(t *testing.T) {
+	var path, authorization, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, authorization = r.URL.Path, r.Header.Get("Authorization")
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+	}))
+	defer server.Close()
+
+	for k, v := range map[string]string{
+		"AWS_ENDPOINT_URL":      server.URL,
+		"AWS_ACCESS_KEY_ID":     "AKIDEXAMPLE",
+		"AWS_SECRET_ACCESS_KEY": "secret",
+		"AWS_REGION":            "eu-west-1",
+		"AWS_CONFIG_FILE":       filepath.Join(t.TempDir(), "config"),
+	} {
+		t.Setenv(k, v)
+	}
+
+	localFile := filepath.Join(t.TempDir(), "report.json")
+	os.WriteFile(localFile, []byte(`{"score": 42}`), 0644)
+	if err := NewS3Uploader("bucket", "scans/report.json").Upload(localFile); err != nil {
+		t.Fatal(err)
+	}
+	if path != "/bucket/scans/report.json" || body != `{"score": 42}` {
+		t.Errorf("unexpected upload: %s %s", path, body)
+	}
+	if !strings.HasPrefix(authorization, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/") || !strings.Contains(authorization, "/eu-west-1/s3/aws4_request") {
+		t.Errorf("unexpected authorization: %s", authorization)
+	}
+}
+
+func TestAzureSharedKey(t *testing.T) {
+	req, _ := http.NewRequest("PUT", "https://account.blob.core.windows.net/container/report.json?comp=block&blockid=1", strings.NewReader("content"))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Ms-Version", azureStorageVersion)
+	req.Header.Set("X-Ms-Date", "Fri, 01 Jan 2021 00:00:00 GMT")
+	req.Header.Set("X-Ms-Blob-Type", "BlockBlob")
+
+	authorization, err := azureSharedKey(req, "account", "c2VjcmV0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// HMAC-SHA256 of the canonical request, signed with the key "secret"
+	if expected := "SharedKey account:oV+lvId1PPVNndMX6uBaNLOJXcKPJ2vfbk+k9a+9sqU="; authorization != expected {
+		t.Errorf("azureSharedKey() = %s, expected %s", authorization, expected)
+	}
+	if _, err := azureSharedKey(req, "account", "not base64"); err == nil {
+		t.Errorf("expected an error for an invalid key")
+	}
+}
+
+type uploaderMock struct {
+	err error
+}
+
+func (uploader *uploaderMock) Upload(localFile string) error {
+	return uploader.err
+}
+
+type printerMock struct {
+	outputFile string
+}
+
+func (p *printerMock) ActionPrint(opaSessionObj *cautils.OPASessionObj) error {
+	return os.WriteFile(p.outputFile, []byte("{}"), 0644)
+}
+func (p *printerMock) SetWriter(outputFile string) { p.outputFile = outputFile }
+func (p *printerMock) Score(score float32)         {}
+
+func TestUploadPrinterError(t *testing.T) {
+	uploadPrinter := NewUploadPrinter(&printerMock{}, &uploaderMock{err: fmt.Errorf("access denied")})
+	uploadPrinter.SetWriter("s3://bucket/report.json")
+	if err := uploadPrinter.ActionPrint(nil); err == nil || !strings.Contains(err.Error(), "s3://bucket/report.json") {
+		t.Errorf("expected the upload error, got: %v", err)
+	}
+
+	uploadPrinter = NewUploadPrinter(&printerMock{}, &uploaderMock{})
+	uploadPrinter.SetWriter("s3://bucket/report.json")
+	if err := uploadPrinter.ActionPrint(nil); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// the temporary directory could not be created
+	t.Setenv("TMPDIR", filepath.Join(t.TempDir(), "missing"))
+	uploadPrinter = NewUploadPrinter(&printerMock{}, &uploaderMock{})
+	uploadPrinter.SetWriter("s3://bucket/report.json")
+	if err := uploadPrinter.ActionPrint(nil); err == nil {
+		t.Errorf("expected an error for the missing temporary directory")
+	}
+}
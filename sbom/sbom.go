@@ -0,0 +1,101 @@
+package sbom
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/cautils/logger/helpers"
+)
+
+// The supported formats of the SBOMs
+const (
+	FormatSPDX      = "spdx"
+	FormatCycloneDX = "cyclonedx"
+)
+
+// DefaultOutputDir the directory of the generated SBOMs
+const DefaultOutputDir = "sboms"
+
+var unsafeFileNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+// IGenerator generates the SBOM of an image
+type IGenerator interface {
+	// Login verifies the generator can run, e.g. its binary is installed
+	Login() error
+	Generate(image, format, outputPath string) error
+}
+
+// ValidateFormat returns an error for the unsupported formats
+func ValidateFormat(format string) error {
+	switch format {
+	case FormatSPDX, FormatCycloneDX:
+		return nil
+	}
+	return fmt.Errorf("unsupported SBOM format '%s', supported: %s, %s", format, FormatSPDX, FormatCycloneDX)
+}
+
+// GenerateSBOMs generates the SBOMs of the images in the output directory, map[<image>][]<workload ID>. The images failing to generate
+// are skipped, the references are sorted by the images
+func GenerateSBOMs(generator IGenerator, imagesWorkloads map[string][]string, format, outputDir string) ([]cautils.SBOMReference, error) {
+	if err := ValidateFormat(format); err != nil {
+		return nil, err
+	}
+	if err := generator.Login(); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return nil, err
+	}
+
+	images := make([]string, 0, len(imagesWorkloads))
+	for image := range imagesWorkloads {
+		images = append(images, image)
+	}
+	sort.Strings(images)
+
+	references := []cautils.SBOMReference{}
+	for _, image := range images {
+		path := filepath.Join(outputDir, fileName(image, format))
+		logger.L().Debug("Generating SBOM", helpers.String("image", image), helpers.String("path", path))
+		if err := generator.Generate(image, format, path); err != nil {
+			logger.L().Warning("failed to generate SBOM", helpers.String("image", image), helpers.Error(err))
+			continue
+		}
+		digest, err := fileSHA256(path)
+		if err != nil {
+			logger.L().Warning("failed to read SBOM", helpers.String("path", path), helpers.Error(err))
+			continue
+		}
+		references = append(references, cautils.SBOMReference{Image: image, Format: format, Path: path, SHA256: digest, Workloads: imagesWorkloads[image]})
+	}
+	return references, nil
+}
+
+// fileName returns the file of the SBOM of an image, e.g. registry.io-app_1.0.spdx.json
+func fileName(image, format string) string {
+	name := strings.ReplaceAll(image, ":", "_")
+	name = unsafeFileNameChars.ReplaceAllString(strings.ReplaceAll(name, "/", "-"), "-")
+	return fmt.Sprintf("%s.%s.json", name, format)
+}
+
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
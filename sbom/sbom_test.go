@@ -0,0 +1,52 @@
+package sbom
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeTrivy writes the SBOM of the images, failing for the private images
+const fakeTrivy = `#!/bin/sh
+echo "$@" >> "$(dirname "$0")/args.txt"
+for image; do :; done
+case "$image" in
+private/*) echo 'failed to pull the image' >&2; exit 1;;
+esac
+while [ "$1" != "--output" ]; do shift; done
+echo "{\"image\": \"$image\"}" > "$2"
+`
+
+func TestGenerateSBOMs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trivy")
+	assert.NoError(t, os.WriteFile(path, []byte(fakeTrivy), 0755))
+	outputDir := filepath.Join(t.TempDir(), DefaultOutputDir)
+
+	images := map[string][]string{
+		"registry.io/app:1.0": {"apps/v1/default/Deployment/app"},
+		"nginx:1.21":          {"apps/v1/default/Deployment/web", "/v1/default/Pod/debug"},
+		"private/app":         {"apps/v1/default/Deployment/private"},
+	}
+	references, err := GenerateSBOMs(NewTrivyGenerator(path, true), images, FormatCycloneDX, outputDir)
+	assert.NoError(t, err)
+	if assert.Len(t, references, 2, "the images failing to generate are skipped") {
+		assert.Equal(t, "nginx:1.21", references[0].Image)
+		assert.Equal(t, filepath.Join(outputDir, "nginx_1.21.cyclonedx.json"), references[0].Path)
+		assert.Equal(t, images["nginx:1.21"], references[0].Workloads)
+		assert.Equal(t, FormatCycloneDX, references[0].Format)
+		assert.Len(t, references[0].SHA256, 64)
+
+		assert.Equal(t, filepath.Join(outputDir, "registry.io-app_1.0.cyclonedx.json"), references[1].Path)
+		data, _ := os.ReadFile(references[1].Path)
+		assert.Equal(t, "{\"image\": \"registry.io/app:1.0\"}\n", string(data))
+	}
+	args, _ := os.ReadFile(filepath.Join(filepath.Dir(path), "args.txt"))
+	assert.Contains(t, string(args), "image --quiet --format cyclonedx --output "+filepath.Join(outputDir, "nginx_1.21.cyclonedx.json")+" --skip-db-update --offline-scan nginx:1.21\n")
+
+	_, err = GenerateSBOMs(NewTrivyGenerator(path, false), images, "syft", outputDir)
+	assert.Error(t, err)
+	_, err = GenerateSBOMs(NewTrivyGenerator(filepath.Join(t.TempDir(), "missing"), false), images, FormatSPDX, outputDir)
+	assert.Error(t, err)
+}
@@ -0,0 +1,66 @@
+package sbom
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	trivyv1 "github.com/armosec/kubescape/registryadaptors/trivy/v1"
+)
+
+const defaultTimeout = 5 * time.Minute
+
+// trivyFormats the formats of trivy by the formats of the SBOMs
+var trivyFormats = map[string]string{
+	FormatSPDX:      "spdx-json",
+	FormatCycloneDX: "cyclonedx",
+}
+
+// TrivyGenerator generates the SBOMs by the trivy CLI (https://github.com/aquasecurity/trivy), the images are pulled by trivy
+// with the credentials of the docker config of the host
+type TrivyGenerator struct {
+	path    string        // path of the trivy binary
+	offline bool          // the vulnerabilities database of trivy is not updated
+	timeout time.Duration // timeout of the generation of an SBOM
+}
+
+func NewTrivyGenerator(path string, offline bool) *TrivyGenerator {
+	if path == "" {
+		path = trivyv1.DefaultTrivyPath
+	}
+	return &TrivyGenerator{
+		path:    path,
+		offline: offline,
+		timeout: defaultTimeout,
+	}
+}
+
+// Login verifies trivy is installed
+func (trivyGenerator *TrivyGenerator) Login() error {
+	path, err := exec.LookPath(trivyGenerator.path)
+	if err != nil {
+		return fmt.Errorf("trivy was not found (%s), install it from https://aquasecurity.github.io/trivy for generating the SBOMs", err.Error())
+	}
+	trivyGenerator.path = path
+	return nil
+}
+
+func (trivyGenerator *TrivyGenerator) Generate(image, format, outputPath string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), trivyGenerator.timeout)
+	defer cancel()
+
+	args := []string{"image", "--quiet", "--format", trivyFormats[format], "--output", outputPath}
+	if trivyGenerator.offline {
+		args = append(args, "--skip-db-update", "--offline-scan")
+	}
+	stderr := bytes.Buffer{}
+	cmd := exec.CommandContext(ctx, trivyGenerator.path, append(args, image)...)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("trivy failed: %s %s", err.Error(), strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
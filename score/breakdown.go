@@ -0,0 +1,129 @@
+package score
+
+import (
+	"sort"
+
+	"github.com/armosec/k8s-interface/workloadinterface"
+	"github.com/armosec/kubescape/cautils"
+)
+
+// CalculateBreakdown sets the risk-scores of the resources of each namespace and of each owner of the session. The owner of a resource is
+// the value of the owner label of the resource, or of its namespace. The score of a group is the total score calculated on the resources
+// of the group only - the failed resources of the group weighted by their replicas and by the score factor of the failed controls
+func (su *ScoreWrapper) CalculateBreakdown() {
+	if su.opaSessionObj.Report == nil {
+		return
+	}
+	restore := su.applyWeights()
+	defer restore()
+
+	namespaces := map[string][]string{} // map[<namespace>][]<resource ID>
+	owners := map[string][]string{}     // map[<owner>][]<resource ID>
+	namespacesOwners := map[string]string{}
+	if su.opaSessionObj.OwnerLabel != "" {
+		for _, resource := range su.opaSessionObj.AllResources {
+			if resource.GetKind() == "Namespace" {
+				if owner := resourceLabel(resource, su.opaSessionObj.OwnerLabel); owner != "" {
+					namespacesOwners[resource.GetName()] = owner
+				}
+			}
+		}
+	}
+	for resourceID, resource := range su.opaSessionObj.AllResources {
+		namespace := resourceNamespace(resource)
+		if namespace != "" {
+			namespaces[namespace] = append(namespaces[namespace], resourceID)
+		}
+		if su.opaSessionObj.OwnerLabel == "" {
+			continue
+		}
+		owner := resourceLabel(resource, su.opaSessionObj.OwnerLabel)
+		if owner == "" {
+			owner = namespacesOwners[namespace]
+		}
+		if owner != "" {
+			owners[owner] = append(owners[owner], resourceID)
+		}
+	}
+
+	breakdown := &cautils.ScoresBreakdown{OwnerLabel: su.opaSessionObj.OwnerLabel, Namespaces: su.groupsScores(namespaces)}
+	if len(owners) > 0 {
+		breakdown.Owners = su.groupsScores(owners)
+	}
+	su.opaSessionObj.ScoresBreakdown = breakdown
+}
+
+// groupsScores returns the scores of the groups of resources evaluated by the controls, the riskiest first
+func (su *ScoreWrapper) groupsScores(groups map[string][]string) []cautils.GroupScore {
+	scores := []cautils.GroupScore{}
+	for name, resourceIDs := range groups {
+		if score, ok := su.groupScore(name, resourceIDs); ok {
+			scores = append(scores, score)
+		}
+	}
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].Score != scores[j].Score {
+			return scores[i].Score > scores[j].Score
+		}
+		return scores[i].Name < scores[j].Name
+	})
+	return scores
+}
+
+// groupScore returns the score of the resources, false when no control evaluated the resources
+func (su *ScoreWrapper) groupScore(name string, resourceIDs []string) (cautils.GroupScore, bool) {
+	group := make(map[string]bool, len(resourceIDs))
+	for _, resourceID := range resourceIDs {
+		group[resourceID] = true
+	}
+	score := cautils.GroupScore{Name: name}
+	evaluated := map[string]bool{}
+	failed := map[string]bool{}
+	var unormalizedScore, wcsScore float32
+	for _, control := range su.opaSessionObj.Report.SummaryDetails.Controls {
+		var controlFailed, controlAll float32
+		for _, resourceID := range control.ListResourcesIDs().Failed() {
+			if group[resourceID] {
+				controlFailed += su.scoreUtil.GetScore(su.opaSessionObj.AllResources[resourceID].GetObject())
+				failed[resourceID] = true
+			}
+		}
+		for _, resourceID := range control.ListResourcesIDs().All() {
+			if group[resourceID] {
+				controlAll += su.scoreUtil.GetScore(su.opaSessionObj.AllResources[resourceID].GetObject())
+				evaluated[resourceID] = true
+			}
+		}
+		if controlFailed > 0 {
+			score.FailedControls++
+		}
+		unormalizedScore += controlFailed * control.GetScoreFactor()
+		wcsScore += controlAll * control.GetScoreFactor()
+	}
+	if len(evaluated) == 0 {
+		return score, false
+	}
+	if wcsScore > 0 {
+		score.Score = (unormalizedScore * 100) / wcsScore
+	}
+	score.FailedResources = len(failed)
+	score.Resources = len(evaluated)
+	return score, true
+}
+
+// resourceNamespace returns the namespace of the resource, the name of the Namespace objects
+func resourceNamespace(resource workloadinterface.IMetadata) string {
+	if resource.GetKind() == "Namespace" {
+		return resource.GetName()
+	}
+	return resource.GetNamespace()
+}
+
+func resourceLabel(resource workloadinterface.IMetadata, label string) string {
+	if value, ok := workloadinterface.InspectMap(resource.GetObject(), "metadata", "labels", label); ok {
+		if s, ok := value.(string); ok {
+			return s
+		}
+	}
+	return ""
+}
@@ -3,6 +3,7 @@ package score
 import (
 	"fmt"
 
+	"github.com/armosec/opa-utils/reporthandling/results/v1/reportsummary"
 	"github.com/armosec/opa-utils/score"
 
 	"github.com/armosec/kubescape/cautils"
@@ -29,6 +30,8 @@ func (su *ScoreWrapper) Calculate(reportVersion PostureReportVersion) error {
 	case EPostureReportV1:
 		return su.scoreUtil.Calculate(su.opaSessionObj.PostureReport.FrameworkReports)
 	case EPostureReportV2:
+		restore := su.applyWeights()
+		defer restore()
 		return su.scoreUtil.CalculatePostureReportV2(su.opaSessionObj.Report)
 	}
 
@@ -41,3 +44,32 @@ func NewScoreWrapper(opaSessionObj *cautils.OPASessionObj) *ScoreWrapper {
 		opaSessionObj: opaSessionObj,
 	}
 }
+
+// applyWeights multiplies the score factor of the controls by their configured weight, for the frameworks and the total scores.
+// Returns a function restoring the score factors, the severities of the controls are derived from the score factor
+func (su *ScoreWrapper) applyWeights() func() {
+	if su.opaSessionObj.ScoringConfig == nil || su.opaSessionObj.Report == nil {
+		return func() {}
+	}
+	summaries := []reportsummary.ControlSummaries{su.opaSessionObj.Report.SummaryDetails.Controls}
+	for i := range su.opaSessionObj.Report.SummaryDetails.Frameworks {
+		summaries = append(summaries, su.opaSessionObj.Report.SummaryDetails.Frameworks[i].Controls)
+	}
+	scoreFactors := make([]map[string]float32, len(summaries))
+	for i := range summaries {
+		scoreFactors[i] = map[string]float32{}
+		for controlID, control := range summaries[i] {
+			scoreFactors[i][controlID] = control.ScoreFactor
+			control.ScoreFactor *= su.opaSessionObj.ScoringConfig.Weight(control.GetID(), control.GetName())
+			summaries[i][controlID] = control
+		}
+	}
+	return func() {
+		for i := range summaries {
+			for controlID, control := range summaries[i] {
+				control.ScoreFactor = scoreFactors[i][controlID]
+				summaries[i][controlID] = control
+			}
+		}
+	}
+}
@@ -1 +1,79 @@
 package score
+
+import (
+	"testing"
+
+	"github.com/armosec/k8s-interface/workloadinterface"
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/opa-utils/objectsenvelopes"
+	"github.com/armosec/opa-utils/reporthandling/apis"
+	"github.com/armosec/opa-utils/reporthandling/results/v1/reportsummary"
+)
+
+func TestCalculateWeights(t *testing.T) {
+	opaSessionObj := cautils.NewOPASessionObj(nil, nil)
+	opaSessionObj.AllResources = map[string]workloadinterface.IMetadata{
+		"/v1/default/ConfigMap/a": objectsenvelopes.NewObject(map[string]interface{}{"apiVersion": "v1", "kind": "ConfigMap", "metadata": map[string]interface{}{"name": "a", "namespace": "default"}}),
+	}
+	failed := reportsummary.ControlSummary{ControlID: "C-0078", Name: "Images from allowed registry", ScoreFactor: 5}
+	failed.ResourceIDs.Append(apis.StatusFailed, "/v1/default/ConfigMap/a")
+	passed := reportsummary.ControlSummary{ControlID: "C-0016", Name: "Allow privilege escalation", ScoreFactor: 5}
+	passed.ResourceIDs.Append(apis.StatusPassed, "/v1/default/ConfigMap/a")
+	opaSessionObj.Report.SummaryDetails.Controls = reportsummary.ControlSummaries{"C-0078": failed, "C-0016": passed}
+
+	if err := NewScoreWrapper(opaSessionObj).Calculate(EPostureReportV2); err != nil {
+		t.Fatal(err)
+	}
+	if score := opaSessionObj.Report.SummaryDetails.Score; score != 50 {
+		t.Errorf("expected a score of 50, received %v", score)
+	}
+
+	// the image control weighs 3 times the other control
+	opaSessionObj.ScoringConfig = &cautils.ScoringConfig{Controls: []cautils.ControlScoring{{ControlName: "(?i)image", Weight: 3}}}
+	if err := NewScoreWrapper(opaSessionObj).Calculate(EPostureReportV2); err != nil {
+		t.Fatal(err)
+	}
+	if score := opaSessionObj.Report.SummaryDetails.Score; score != 75 {
+		t.Errorf("expected a weighted score of 75, received %v", score)
+	}
+	// the score factors, the severities of the controls, are not changed by the weights
+	if scoreFactor := opaSessionObj.Report.SummaryDetails.Controls["C-0078"].ScoreFactor; scoreFactor != 5 {
+		t.Errorf("expected the score factor to be restored, received %v", scoreFactor)
+	}
+}
+
+func TestCalculateBreakdown(t *testing.T) {
+	opaSessionObj := cautils.NewOPASessionObj(nil, nil)
+	opaSessionObj.OwnerLabel = "team"
+	opaSessionObj.AllResources = map[string]workloadinterface.IMetadata{
+		"/v1//Namespace/payments":  objectsenvelopes.NewObject(map[string]interface{}{"apiVersion": "v1", "kind": "Namespace", "metadata": map[string]interface{}{"name": "payments", "labels": map[string]interface{}{"team": "billing"}}}),
+		"/v1/payments/ConfigMap/a": objectsenvelopes.NewObject(map[string]interface{}{"apiVersion": "v1", "kind": "ConfigMap", "metadata": map[string]interface{}{"name": "a", "namespace": "payments"}}),
+		"/v1/default/ConfigMap/b":  objectsenvelopes.NewObject(map[string]interface{}{"apiVersion": "v1", "kind": "ConfigMap", "metadata": map[string]interface{}{"name": "b", "namespace": "default", "labels": map[string]interface{}{"team": "web"}}}),
+	}
+	control := reportsummary.ControlSummary{ControlID: "C-0078", Name: "Images from allowed registry", ScoreFactor: 5}
+	control.ResourceIDs.Append(apis.StatusFailed, "/v1/payments/ConfigMap/a")
+	control.ResourceIDs.Append(apis.StatusPassed, "/v1/default/ConfigMap/b")
+	opaSessionObj.Report.SummaryDetails.Controls = reportsummary.ControlSummaries{"C-0078": control}
+
+	NewScoreWrapper(opaSessionObj).CalculateBreakdown()
+	breakdown := opaSessionObj.ScoresBreakdown
+	if breakdown == nil {
+		t.Fatal("expected a scores breakdown")
+	}
+
+	// the namespace not evaluated by any control is not listed
+	if len(breakdown.Namespaces) != 2 {
+		t.Fatalf("expected 2 namespaces, received %v", breakdown.Namespaces)
+	}
+	if ns := breakdown.Namespaces[0]; ns.Name != "payments" || ns.Score != 100 || ns.FailedControls != 1 || ns.FailedResources != 1 {
+		t.Errorf("expected payments to be the riskiest namespace, received %+v", ns)
+	}
+	if ns := breakdown.Namespaces[1]; ns.Name != "default" || ns.Score != 0 || ns.Resources != 1 {
+		t.Errorf("expected default to pass, received %+v", ns)
+	}
+
+	// the owner of the ConfigMap a is the owner of its namespace
+	if len(breakdown.Owners) != 2 || breakdown.Owners[0].Name != "billing" || breakdown.Owners[1].Name != "web" {
+		t.Errorf("expected the owners billing and web, received %v", breakdown.Owners)
+	}
+}
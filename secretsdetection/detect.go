@@ -0,0 +1,274 @@
+package secretsdetection
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/armosec/k8s-interface/workloadinterface"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// The types of the detected credentials
+const (
+	TypeAWSAccessKey   = "aws-access-key"
+	TypePrivateKey     = "private-key"
+	TypeGitHubToken    = "github-token"
+	TypeSlackToken     = "slack-token"
+	TypeGoogleAPIKey   = "google-api-key"
+	TypeJWT            = "jwt"
+	TypeURLCredentials = "url-credentials"
+	TypePassword       = "password"     // the value of a key, a variable or a flag named as a credential
+	TypeHighEntropy    = "high-entropy" // a random looking value
+)
+
+// minEntropyLength, minEntropy the shortest values, and the lowest entropy (bits per character) of the values detected as random - the
+// hexadecimal hashes (at most 4 bits per character) are not detected
+const (
+	minEntropyLength = 32
+	minEntropy       = 4.5
+)
+
+// minPasswordLength the shortest values of the credentials detected by their names
+const minPasswordLength = 4
+
+// patterns the credentials detected by their format
+var patterns = []struct {
+	credentialType string
+	re             *regexp.Regexp
+}{
+	{TypePrivateKey, regexp.MustCompile(`-----BEGIN ((RSA|EC|DSA|OPENSSH|PGP|ENCRYPTED) )?PRIVATE KEY( BLOCK)?-----`)},
+	{TypeAWSAccessKey, regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{TypeGitHubToken, regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}\b`)},
+	{TypeSlackToken, regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`)},
+	{TypeGoogleAPIKey, regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`)},
+	{TypeJWT, regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`)},
+	{TypeURLCredentials, regexp.MustCompile(`[a-zA-Z][a-zA-Z0-9+.-]*://[^/\s:@]+:[^/\s:@]+@`)},
+}
+
+// sensitiveName the names of the keys, of the variables and of the flags holding credentials
+var sensitiveName = regexp.MustCompile(`(?i)(passw(or)?d|passwd|secret|token|api[_-]?key|access[_-]?key|private[_-]?key|credential|auth[_-]?key)`)
+
+// placeholder the values referring to a credential, or not set yet
+var placeholder = regexp.MustCompile(`^(\$\{[^}]*\}|\$\([^)]*\)|\$[A-Za-z_][A-Za-z0-9_]*|<[^>]*>|\{\{.*\}\}|true|false|\*+|x+|null|none|)$`)
+
+// keyValueLine a line of a properties/env/YAML file, name=value or name: value
+var keyValueLine = regexp.MustCompile(`^\s*(?:export\s+)?["']?([A-Za-z0-9_.-]+)["']?\s*[=:]\s*["']?([^"'\s]*)["']?\s*$`)
+
+// Finding a credential embedded in an object
+type Finding struct {
+	Path     string `json:"path"` // the path of the value in the object, e.g. data.password or spec.containers[0].env[1].value
+	Type     string `json:"type"`
+	Evidence string `json:"evidence"` // the redacted value
+}
+
+// ObjectFindings the credentials embedded in a ConfigMap or in the containers of a workload
+type ObjectFindings struct {
+	Kind      string    `json:"kind"`
+	Name      string    `json:"name"`
+	Namespace string    `json:"namespace,omitempty"`
+	Findings  []Finding `json:"findings"`
+}
+
+// Detect returns the credentials of the data of the ConfigMaps, and of the environment variables, the commands and the args of the containers
+// of the workloads. The objects without findings, and the objects created by their controllers (e.g. the pods of a deployment) are skipped
+func Detect(objects []workloadinterface.IMetadata) []ObjectFindings {
+	results := []ObjectFindings{}
+	for _, obj := range objects {
+		var findings []Finding
+		switch obj.GetKind() {
+		case "ConfigMap":
+			findings = configMapFindings(obj.GetObject())
+		case "Pod", "Deployment", "ReplicaSet", "StatefulSet", "DaemonSet", "Job", "CronJob":
+			workload := workloadinterface.NewWorkloadObj(obj.GetObject())
+			if isControlled(workload) {
+				continue
+			}
+			findings = workloadFindings(workload)
+		}
+		if len(findings) > 0 {
+			results = append(results, ObjectFindings{Kind: obj.GetKind(), Name: obj.GetName(), Namespace: obj.GetNamespace(), Findings: findings})
+		}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		a, b := &results[i], &results[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Name < b.Name
+	})
+	return results
+}
+
+func configMapFindings(configMap map[string]interface{}) []Finding {
+	data, _ := configMap["data"].(map[string]interface{})
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	findings := []Finding{}
+	for _, key := range keys {
+		value, ok := data[key].(string)
+		if !ok {
+			continue
+		}
+		path := "data." + key
+		if strings.Contains(value, "\n") { // a file, its lines
+			if finding := detectPattern(path, value); finding != nil {
+				findings = append(findings, *finding)
+				continue
+			}
+			for _, line := range strings.Split(value, "\n") {
+				if match := keyValueLine.FindStringSubmatch(line); match != nil {
+					if finding := detectNamed(path, match[1], match[2]); finding != nil {
+						findings = append(findings, *finding)
+						break
+					}
+				}
+			}
+			continue
+		}
+		if finding := detect(path, key, value); finding != nil {
+			findings = append(findings, *finding)
+		}
+	}
+	return findings
+}
+
+func workloadFindings(workload *workloadinterface.Workload) []Finding {
+	findings := []Finding{}
+	podSpec := strings.Join(workloadinterface.PodSpec(workload.GetKind()), ".")
+	if containers, err := workload.GetInitContainers(); err == nil {
+		findings = append(findings, containersFindings(podSpec+".initContainers", containers)...)
+	}
+	if containers, err := workload.GetContainers(); err == nil {
+		findings = append(findings, containersFindings(podSpec+".containers", containers)...)
+	}
+	return findings
+}
+
+func containersFindings(path string, containers []corev1.Container) []Finding {
+	findings := []Finding{}
+	for i := range containers {
+		containerPath := fmt.Sprintf("%s[%d]", path, i)
+		for j, env := range containers[i].Env {
+			if env.ValueFrom != nil {
+				continue
+			}
+			if finding := detect(fmt.Sprintf("%s.env[%d].value", containerPath, j), env.Name, env.Value); finding != nil {
+				findings = append(findings, *finding)
+			}
+		}
+		findings = append(findings, argsFindings(containerPath+".command", containers[i].Command)...)
+		findings = append(findings, argsFindings(containerPath+".args", containers[i].Args)...)
+	}
+	return findings
+}
+
+// argsFindings detects the credentials of the args, and of the values of the flags named as credentials, --name=value and --name value
+func argsFindings(path string, args []string) []Finding {
+	findings := []Finding{}
+	for i := 0; i < len(args); i++ {
+		argPath := fmt.Sprintf("%s[%d]", path, i)
+		if !strings.HasPrefix(args[i], "-") {
+			if finding := detectPattern(argPath, args[i]); finding != nil {
+				findings = append(findings, *finding)
+			}
+			continue
+		}
+		name := strings.TrimLeft(args[i], "-")
+		if n := strings.Index(name, "="); n >= 0 {
+			if finding := detect(argPath, name[:n], name[n+1:]); finding != nil {
+				findings = append(findings, *finding)
+			}
+			continue
+		}
+		if sensitiveName.MatchString(name) && i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
+			i++
+			if finding := detect(fmt.Sprintf("%s[%d]", path, i), name, args[i]); finding != nil {
+				findings = append(findings, *finding)
+			}
+		}
+	}
+	return findings
+}
+
+// detect returns the credential of the value, by its format, by its name or by its entropy
+func detect(path, name, value string) *Finding {
+	if finding := detectPattern(path, value); finding != nil {
+		return finding
+	}
+	if finding := detectNamed(path, name, value); finding != nil {
+		return finding
+	}
+	if len(value) >= minEntropyLength && !strings.ContainsAny(value, " \t") && entropy(value) >= minEntropy {
+		return &Finding{Path: path, Type: TypeHighEntropy, Evidence: Redact(value)}
+	}
+	return nil
+}
+
+func detectPattern(path, value string) *Finding {
+	for _, pattern := range patterns {
+		if match := pattern.re.FindString(value); match != "" {
+			evidence := Redact(match)
+			if pattern.credentialType == TypePrivateKey {
+				evidence = match // the header of the key
+			}
+			return &Finding{Path: path, Type: pattern.credentialType, Evidence: evidence}
+		}
+	}
+	return nil
+}
+
+func detectNamed(path, name, value string) *Finding {
+	if !sensitiveName.MatchString(name) || len(value) < minPasswordLength || placeholder.MatchString(strings.ToLower(value)) {
+		return nil
+	}
+	return &Finding{Path: path, Type: TypePassword, Evidence: Redact(value)}
+}
+
+// Redact keeps the first characters of the long values, the short values are masked
+func Redact(value string) string {
+	runes := []rune(value)
+	if len(runes) < 12 {
+		return "****"
+	}
+	return string(runes[:4]) + "****"
+}
+
+// entropy returns the Shannon entropy of the value, in bits per character
+func entropy(value string) float64 {
+	counts := map[rune]int{}
+	total := 0
+	for _, r := range value {
+		counts[r]++
+		total++
+	}
+	e := 0.0
+	for _, count := range counts {
+		p := float64(count) / float64(total)
+		e -= p * math.Log2(p)
+	}
+	return e
+}
+
+// isControlled returns true for the objects created by a controller, their findings are the findings of the controller
+func isControlled(workload *workloadinterface.Workload) bool {
+	owners, err := workload.GetOwnerReferences()
+	if err != nil {
+		return false
+	}
+	for _, owner := range owners {
+		if owner.Controller != nil && *owner.Controller {
+			return true
+		}
+	}
+	return false
+}
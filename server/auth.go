@@ -0,0 +1,68 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var errUnauthenticated = fmt.Errorf("unauthenticated: missing or invalid bearer token")
+
+// SetToken requires the requests of the APIs to be authenticated by the token, as 'Authorization: Bearer <token>'
+// (the authorization metadata of the gRPC calls). The health checks are not authenticated
+func (server *Server) SetToken(token string) {
+	server.token = token
+}
+
+func (server *Server) authenticated(authorization string) bool {
+	if server.token == "" {
+		return true
+	}
+	token := strings.TrimPrefix(authorization, "Bearer ")
+	return token != authorization && subtle.ConstantTimeCompare([]byte(token), []byte(server.token)) == 1
+}
+
+// GRPCServerOptions returns the interceptors authenticating the gRPC calls by the token of the server
+func (server *Server) GRPCServerOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			if err := server.authenticateGRPC(ctx); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.StreamInterceptor(func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := server.authenticateGRPC(stream.Context()); err != nil {
+				return err
+			}
+			return handler(srv, stream)
+		}),
+	}
+}
+
+func (server *Server) authenticateGRPC(ctx context.Context) error {
+	authorization := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok && len(md.Get("authorization")) > 0 {
+		authorization = md.Get("authorization")[0]
+	}
+	if !server.authenticated(authorization) {
+		return status.Error(codes.Unauthenticated, errUnauthenticated.Error())
+	}
+	return nil
+}
+
+func (server *Server) authenticateHTTP(w http.ResponseWriter, r *http.Request) bool {
+	if r.URL.Path == "/healthz" || server.authenticated(r.Header.Get("Authorization")) {
+		return true
+	}
+	w.Header().Set("WWW-Authenticate", "Bearer")
+	writeError(w, http.StatusUnauthorized, errUnauthenticated)
+	return false
+}
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/armosec/kubescape/server/scannerpb"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestServerToken(t *testing.T) {
+	server := NewServer(&scannerMock{}, 10)
+	server.SetToken("secret")
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	get := func(path, authorization string) int {
+		req, _ := http.NewRequest(http.MethodGet, ts.URL+path, nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	assert.Equal(t, http.StatusUnauthorized, get("/v1/frameworks", ""))
+	assert.Equal(t, http.StatusUnauthorized, get("/v1/frameworks", "Bearer wrong"))
+	assert.Equal(t, http.StatusUnauthorized, get("/v1/frameworks", "secret"))
+	assert.Equal(t, http.StatusOK, get("/v1/frameworks", "Bearer secret"))
+	assert.Equal(t, http.StatusOK, get("/healthz", ""))
+}
+
+func TestGRPCToken(t *testing.T) {
+	server := NewServer(&scannerMock{}, 10)
+	server.SetToken("secret")
+	listener := bufconn.Listen(1024 * 1024)
+	grpcServer := grpc.NewServer(server.GRPCServerOptions()...)
+	server.RegisterGRPC(grpcServer)
+	go grpcServer.Serve(listener)
+	defer grpcServer.Stop()
+
+	conn, err := grpc.Dial("bufnet", grpc.WithInsecure(), grpc.WithContextDialer(func(ctx context.Context, s string) (net.Conn, error) {
+		return listener.Dial()
+	}))
+	assert.NoError(t, err)
+	defer conn.Close()
+	client := scannerpb.NewScannerClient(conn)
+
+	_, err = client.ListFrameworks(context.Background(), &scannerpb.ListRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	stream, err := client.Scan(context.Background(), &scannerpb.ScanRequest{})
+	assert.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer secret")
+	response, err := client.ListFrameworks(ctx, &scannerpb.ListRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"nsa", "mitre"}, response.GetNames())
+}
+
+func TestScanRequestRedact(t *testing.T) {
+	assert.NoError(t, (&ScanRequest{Redact: []string{"secrets", "env"}}).Validate())
+	assert.Error(t, (&ScanRequest{Redact: []string{"passwords"}}).Validate())
+}
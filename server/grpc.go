@@ -0,0 +1,110 @@
+package server
+
+import (
+	"context"
+
+	"github.com/armosec/kubescape/server/scannerpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var scanStatuses = map[ScanStatus]scannerpb.ScanStatus{
+	ScanQueued:    scannerpb.ScanStatus_SCAN_STATUS_QUEUED,
+	ScanRunning:   scannerpb.ScanStatus_SCAN_STATUS_RUNNING,
+	ScanCompleted: scannerpb.ScanStatus_SCAN_STATUS_COMPLETED,
+	ScanFailed:    scannerpb.ScanStatus_SCAN_STATUS_FAILED,
+}
+
+// grpcScanner serves the gRPC API, the scans are queued together with the scans of the REST API
+type grpcScanner struct {
+	scannerpb.UnimplementedScannerServer
+	server *Server
+}
+
+// RegisterGRPC registers the Scanner service of scanner.proto
+func (server *Server) RegisterGRPC(registrar grpc.ServiceRegistrar) {
+	scannerpb.RegisterScannerServer(registrar, &grpcScanner{server: server})
+}
+
+// Scan streams the events of the scan until the scan is done, a failed scan ends the stream with an error
+func (scanner *grpcScanner) Scan(pbRequest *scannerpb.ScanRequest, stream scannerpb.Scanner_ScanServer) error {
+	request := &ScanRequest{
+		Frameworks:        pbRequest.GetFrameworks(),
+		Controls:          pbRequest.GetControls(),
+		IncludeNamespaces: pbRequest.GetIncludeNamespaces(),
+		ExcludeNamespaces: pbRequest.GetExcludeNamespaces(),
+		Submit:            pbRequest.GetSubmit(),
+	}
+	if err := request.Validate(); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	scan, err := scanner.server.submit(request)
+	if err != nil {
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+
+	for index := 0; ; {
+		events, done, updated := scan.eventsFrom(index)
+		for i := range events {
+			if err := stream.Send(scanner.scanEvent(scan, &events[i])); err != nil {
+				return err
+			}
+		}
+		index += len(events)
+		if done {
+			break
+		}
+		select {
+		case <-updated:
+		case <-stream.Context().Done():
+			return status.FromContextError(stream.Context().Err()).Err()
+		}
+	}
+
+	scan.mutex.RLock()
+	defer scan.mutex.RUnlock()
+	if scan.Status == ScanFailed {
+		return status.Errorf(codes.Internal, "scan failed: %s", scan.Error)
+	}
+	return nil
+}
+
+func (scanner *grpcScanner) ListFrameworks(ctx context.Context, request *scannerpb.ListRequest) (*scannerpb.ListResponse, error) {
+	names, err := scanner.server.scanner.ListFrameworks()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &scannerpb.ListResponse{Names: names}, nil
+}
+
+func (scanner *grpcScanner) ListControls(ctx context.Context, request *scannerpb.ListRequest) (*scannerpb.ListResponse, error) {
+	names, err := scanner.server.scanner.ListControls()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &scannerpb.ListResponse{Names: names}, nil
+}
+
+// scanEvent converts the event, the event of the completed status holds the results of the scan
+func (scanner *grpcScanner) scanEvent(scan *Scan, event *ProgressEvent) *scannerpb.ScanEvent {
+	pbEvent := &scannerpb.ScanEvent{
+		ScanId:     scan.ID,
+		Time:       timestamppb.New(event.Time),
+		Status:     scanStatuses[event.Status],
+		ControlId:  event.ControlID,
+		ResourceId: event.ResourceID,
+		Result:     event.Result,
+		Error:      event.Error,
+	}
+	if event.Status == ScanCompleted {
+		scan.mutex.RLock()
+		if scan.RiskScore != nil {
+			pbEvent.RiskScore = *scan.RiskScore
+		}
+		pbEvent.Report = scan.report
+		scan.mutex.RUnlock()
+	}
+	return pbEvent
+}
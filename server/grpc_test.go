@@ -0,0 +1,101 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/armosec/kubescape/server/scannerpb"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func newGRPCClient(t *testing.T, scanner IScanner) (scannerpb.ScannerClient, func()) {
+	listener := bufconn.Listen(1024 * 1024)
+	grpcServer := grpc.NewServer()
+	NewServer(scanner, 10).RegisterGRPC(grpcServer)
+	go grpcServer.Serve(listener)
+
+	conn, err := grpc.Dial("bufnet", grpc.WithInsecure(), grpc.WithContextDialer(func(ctx context.Context, s string) (net.Conn, error) {
+		return listener.Dial()
+	}))
+	assert.NoError(t, err)
+	return scannerpb.NewScannerClient(conn), func() {
+		conn.Close()
+		grpcServer.Stop()
+	}
+}
+
+func receiveEvents(stream scannerpb.Scanner_ScanClient) ([]*scannerpb.ScanEvent, error) {
+	events := []*scannerpb.ScanEvent{}
+	for {
+		event, err := stream.Recv()
+		if err == io.EOF {
+			return events, nil
+		}
+		if err != nil {
+			return events, err
+		}
+		events = append(events, event)
+	}
+}
+
+func TestGRPCScan(t *testing.T) {
+	scanner := &scannerMock{}
+	client, stop := newGRPCClient(t, scanner)
+	defer stop()
+
+	stream, err := client.Scan(context.Background(), &scannerpb.ScanRequest{Frameworks: []string{"nsa"}, ExcludeNamespaces: []string{"kube-system"}})
+	assert.NoError(t, err)
+	events, err := receiveEvents(stream)
+	assert.NoError(t, err)
+	if assert.Len(t, events, 4) {
+		assert.NotEmpty(t, events[0].GetScanId())
+		assert.Equal(t, scannerpb.ScanStatus_SCAN_STATUS_QUEUED, events[0].GetStatus())
+		assert.Equal(t, scannerpb.ScanStatus_SCAN_STATUS_RUNNING, events[1].GetStatus())
+		assert.Equal(t, "C-0013", events[2].GetControlId())
+		assert.Equal(t, "apps/v1/default/Deployment/nginx", events[2].GetResourceId())
+		assert.Equal(t, "failed", events[2].GetResult())
+		assert.Equal(t, scannerpb.ScanStatus_SCAN_STATUS_COMPLETED, events[3].GetStatus())
+		assert.Equal(t, float32(42), events[3].GetRiskScore())
+		assert.Equal(t, `{"summaryDetails":{}}`, string(events[3].GetReport()))
+	}
+	assert.Equal(t, []string{"nsa"}, scanner.request.Frameworks)
+	assert.Equal(t, []string{"kube-system"}, scanner.request.ExcludeNamespaces)
+}
+
+func TestGRPCScanFailed(t *testing.T) {
+	client, stop := newGRPCClient(t, &scannerMock{err: fmt.Errorf("failed connecting to Kubernetes cluster")})
+	defer stop()
+
+	stream, err := client.Scan(context.Background(), &scannerpb.ScanRequest{})
+	assert.NoError(t, err)
+	events, err := receiveEvents(stream)
+	assert.Equal(t, codes.Internal, status.Code(err))
+	if assert.NotEmpty(t, events) {
+		assert.Equal(t, scannerpb.ScanStatus_SCAN_STATUS_FAILED, events[len(events)-1].GetStatus())
+		assert.Equal(t, "failed connecting to Kubernetes cluster", events[len(events)-1].GetError())
+	}
+
+	stream, err = client.Scan(context.Background(), &scannerpb.ScanRequest{Frameworks: []string{"nsa"}, Controls: []string{"C-0013"}})
+	assert.NoError(t, err)
+	_, err = receiveEvents(stream)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestGRPCList(t *testing.T) {
+	client, stop := newGRPCClient(t, &scannerMock{})
+	defer stop()
+
+	frameworks, err := client.ListFrameworks(context.Background(), &scannerpb.ListRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"nsa", "mitre"}, frameworks.GetNames())
+
+	_, err = client.ListControls(context.Background(), &scannerpb.ListRequest{})
+	assert.Equal(t, codes.Internal, status.Code(err))
+}
@@ -0,0 +1,156 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/armosec/kubescape/cautils"
+)
+
+type ScanStatus string
+
+const (
+	ScanQueued    ScanStatus = "queued"
+	ScanRunning   ScanStatus = "running"
+	ScanCompleted ScanStatus = "completed"
+	ScanFailed    ScanStatus = "failed"
+)
+
+// ScanRequest the body of a POST /v1/scan request. All of the frameworks are scanned when no frameworks/controls are set
+type ScanRequest struct {
+	Frameworks        []string `json:"frameworks,omitempty"`
+	Controls          []string `json:"controls,omitempty"`
+	IncludeNamespaces []string `json:"includeNamespaces,omitempty"`
+	ExcludeNamespaces []string `json:"excludeNamespaces,omitempty"`
+	Submit            bool     `json:"submit,omitempty"`
+	Redact            []string `json:"redact,omitempty"`           // redacted in addition to the redaction of the server - secrets/env/annotations
+	OmitRawResources  bool     `json:"omitRawResources,omitempty"` // the raw resources are omitted when omitted by the server as well
+}
+
+func (request *ScanRequest) Validate() error {
+	if len(request.Frameworks) > 0 && len(request.Controls) > 0 {
+		return fmt.Errorf("bad request: scan 'frameworks' or 'controls', but not both")
+	}
+	if len(request.IncludeNamespaces) > 0 && len(request.ExcludeNamespaces) > 0 {
+		return fmt.Errorf("bad request: set 'includeNamespaces' or 'excludeNamespaces', but not both")
+	}
+	for _, redact := range request.Redact {
+		if cautils.StringInSliceCaseInsensitive(cautils.RedactSupported, redact) == cautils.ValueNotFound {
+			return fmt.Errorf("bad request: unsupported redaction '%s'. Supported: %s", redact, strings.Join(cautils.RedactSupported, "/"))
+		}
+	}
+	return nil
+}
+
+// ProgressEvent an event of a scan - a change of the status of the scan, or the result of a control on a resource
+type ProgressEvent struct {
+	Time       time.Time  `json:"time"`
+	Status     ScanStatus `json:"status,omitempty"`
+	ControlID  string     `json:"controlID,omitempty"`
+	ResourceID string     `json:"resourceID,omitempty"`
+	Result     string     `json:"result,omitempty"` // the status of the control on the resource, e.g. failed
+	Error      string     `json:"error,omitempty"`
+}
+
+// ScanResults the results of a completed scan
+type ScanResults struct {
+	RiskScore float32
+	Report    []byte // the results in the json format
+}
+
+// Scan a scan of the server, the events of the scan are kept for the progress streams
+type Scan struct {
+	ID          string      `json:"id"`
+	Request     ScanRequest `json:"request"`
+	Status      ScanStatus  `json:"status"`
+	CreatedAt   time.Time   `json:"createdAt"`
+	StartedAt   *time.Time  `json:"startedAt,omitempty"`
+	CompletedAt *time.Time  `json:"completedAt,omitempty"`
+	RiskScore   *float32    `json:"riskScore,omitempty"`
+	Error       string      `json:"error,omitempty"`
+
+	mutex   sync.RWMutex
+	report  []byte
+	events  []ProgressEvent
+	updated chan struct{} // closed and replaced on every event
+}
+
+func newScan(id string, request *ScanRequest) *Scan {
+	scan := &Scan{
+		ID:        id,
+		Request:   *request,
+		Status:    ScanQueued,
+		CreatedAt: time.Now().UTC(),
+		updated:   make(chan struct{}),
+	}
+	scan.events = []ProgressEvent{{Time: scan.CreatedAt, Status: ScanQueued}}
+	return scan
+}
+
+// addEvent appends the event and wakes up the progress streams
+func (scan *Scan) addEvent(event ProgressEvent) {
+	scan.mutex.Lock()
+	defer scan.mutex.Unlock()
+	scan.addEventLocked(event)
+}
+
+func (scan *Scan) addEventLocked(event ProgressEvent) {
+	if event.Time.IsZero() {
+		event.Time = time.Now().UTC()
+	}
+	scan.events = append(scan.events, event)
+	close(scan.updated)
+	scan.updated = make(chan struct{})
+}
+
+func (scan *Scan) setStatus(status ScanStatus, results *ScanResults, err error) {
+	scan.mutex.Lock()
+	defer scan.mutex.Unlock()
+
+	now := time.Now().UTC()
+	scan.Status = status
+	event := ProgressEvent{Time: now, Status: status}
+	switch status {
+	case ScanRunning:
+		scan.StartedAt = &now
+	case ScanCompleted:
+		scan.CompletedAt = &now
+		scan.RiskScore = &results.RiskScore
+		scan.report = results.Report
+	case ScanFailed:
+		scan.CompletedAt = &now
+		scan.Error = err.Error()
+		event.Error = scan.Error
+	}
+	scan.addEventLocked(event)
+}
+
+// eventsFrom returns the events starting at the index, whether the scan is done and a channel closed on the next event
+func (scan *Scan) eventsFrom(index int) ([]ProgressEvent, bool, <-chan struct{}) {
+	scan.mutex.RLock()
+	defer scan.mutex.RUnlock()
+	events := append([]ProgressEvent{}, scan.events[index:]...)
+	return events, scan.done(), scan.updated
+}
+
+func (scan *Scan) done() bool {
+	return scan.Status == ScanCompleted || scan.Status == ScanFailed
+}
+
+// snapshot returns a copy of the exported fields, for encoding while the scan is updated
+func (scan *Scan) snapshot() *Scan {
+	scan.mutex.RLock()
+	defer scan.mutex.RUnlock()
+	return &Scan{
+		ID:          scan.ID,
+		Request:     scan.Request,
+		Status:      scan.Status,
+		CreatedAt:   scan.CreatedAt,
+		StartedAt:   scan.StartedAt,
+		CompletedAt: scan.CompletedAt,
+		RiskScore:   scan.RiskScore,
+		Error:       scan.Error,
+	}
+}
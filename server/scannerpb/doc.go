@@ -0,0 +1,4 @@
+// Package scannerpb the gRPC API of the kubescape server, generated from scanner.proto
+package scannerpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative scanner.proto
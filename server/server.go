@@ -0,0 +1,277 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/cautils/logger/helpers"
+	"github.com/google/uuid"
+)
+
+const (
+	// queueSize the number of queued scans, the scans run one at a time
+	queueSize = 10
+
+	maxRequestSize = 1024 * 1024
+)
+
+var errQueueFull = fmt.Errorf("too many queued scans, try again later")
+
+// IScanner runs the scans of the server
+type IScanner interface {
+	// Scan runs the scan of the request, the progress function is called with the results of the controls while scanning
+	Scan(request *ScanRequest, progress func(event ProgressEvent)) (*ScanResults, error)
+	ListFrameworks() ([]string, error)
+	ListControls() ([]string, error)
+}
+
+// Server the REST API of kubescape
+//
+//	POST /v1/scan                 trigger a scan, returns the scan (202)
+//	GET  /v1/scans                list the scans
+//	GET  /v1/scans/<id>           the status of a scan
+//	GET  /v1/scans/<id>/results   the results of a completed scan, in the json format
+//	GET  /v1/scans/<id>/progress  stream the events of a scan (server-sent events)
+//	GET  /v1/frameworks           list the frameworks
+//	GET  /v1/controls             list the controls
+type Server struct {
+	scanner      IScanner
+	historyLimit int
+	queue        chan *Scan
+
+	mutex sync.RWMutex
+	scans []*Scan // ordered by creation
+	mux   *http.ServeMux
+	token string // the requests are not authenticated when empty
+}
+
+// NewServer starts the worker of the scans. The completed scans beyond the history limit are removed, the oldest first
+func NewServer(scanner IScanner, historyLimit int) *Server {
+	server := &Server{
+		scanner:      scanner,
+		historyLimit: historyLimit,
+		queue:        make(chan *Scan, queueSize),
+		mux:          http.NewServeMux(),
+	}
+	server.mux.HandleFunc("/v1/scan", server.handleScan)
+	server.mux.HandleFunc("/v1/scans", server.handleListScans)
+	server.mux.HandleFunc("/v1/scans/", server.handleGetScan)
+	server.mux.HandleFunc("/v1/frameworks", server.handleList(scanner.ListFrameworks))
+	server.mux.HandleFunc("/v1/controls", server.handleList(scanner.ListControls))
+	server.mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	go server.worker()
+	return server
+}
+
+func (server *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !server.authenticateHTTP(w, r) {
+		return
+	}
+	server.mux.ServeHTTP(w, r)
+}
+
+func (server *Server) worker() {
+	for scan := range server.queue {
+		scan.setStatus(ScanRunning, nil, nil)
+		logger.L().Info("scan started", helpers.String("id", scan.ID))
+		results, err := server.scanner.Scan(&scan.Request, scan.addEvent)
+		if err != nil {
+			logger.L().Error("scan failed", helpers.String("id", scan.ID), helpers.Error(err))
+			scan.setStatus(ScanFailed, nil, err)
+			continue
+		}
+		scan.setStatus(ScanCompleted, results, nil)
+		logger.L().Info("scan completed", helpers.String("id", scan.ID), helpers.String("risk-score", fmt.Sprintf("%.2f", results.RiskScore)))
+	}
+}
+
+func (server *Server) handleScan(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method not allowed"))
+		return
+	}
+	request := &ScanRequest{}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxRequestSize))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, request); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("failed to decode the scan request: %w", err))
+			return
+		}
+	}
+	if err := request.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	scan, err := server.submit(request)
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, scan.snapshot())
+}
+
+// submit queues the scan of the request, fails if the queue is full
+func (server *Server) submit(request *ScanRequest) (*Scan, error) {
+	scan := newScan(uuid.NewString(), request)
+	select {
+	case server.queue <- scan:
+	default:
+		return nil, errQueueFull
+	}
+	server.addScan(scan)
+	return scan, nil
+}
+
+func (server *Server) handleListScans(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method not allowed"))
+		return
+	}
+	server.mutex.RLock()
+	scans := make([]*Scan, 0, len(server.scans))
+	for i := range server.scans {
+		scans = append(scans, server.scans[i].snapshot())
+	}
+	server.mutex.RUnlock()
+	writeJSON(w, http.StatusOK, scans)
+}
+
+// handleGetScan handles /v1/scans/<id>[/results|/progress]
+func (server *Server) handleGetScan(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method not allowed"))
+		return
+	}
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/v1/scans/"), "/")
+	if len(parts) > 2 || (len(parts) == 2 && parts[1] != "results" && parts[1] != "progress") {
+		writeError(w, http.StatusNotFound, fmt.Errorf("not found"))
+		return
+	}
+	scan := server.getScan(parts[0])
+	if scan == nil {
+		writeError(w, http.StatusNotFound, fmt.Errorf("scan '%s' not found", parts[0]))
+		return
+	}
+	if len(parts) == 1 {
+		writeJSON(w, http.StatusOK, scan.snapshot())
+		return
+	}
+	if parts[1] == "progress" {
+		streamProgress(w, r, scan)
+		return
+	}
+
+	scan.mutex.RLock()
+	status, report := scan.Status, scan.report
+	scan.mutex.RUnlock()
+	if status != ScanCompleted {
+		writeError(w, http.StatusConflict, fmt.Errorf("scan '%s' is %s", scan.ID, status))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(report)
+}
+
+func (server *Server) handleList(list func() ([]string, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method not allowed"))
+			return
+		}
+		names, err := list()
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, names)
+	}
+}
+
+// streamProgress writes the events of the scan as server-sent events, until the scan is done or the client disconnects
+func streamProgress(w http.ResponseWriter, r *http.Request, scan *Scan) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("streaming is not supported"))
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	for index := 0; ; {
+		events, done, updated := scan.eventsFrom(index)
+		for i := range events {
+			data, _ := json.Marshal(events[i])
+			fmt.Fprintf(w, "data: %s\n\n", data)
+		}
+		flusher.Flush()
+		index += len(events)
+		if done {
+			return
+		}
+		select {
+		case <-updated:
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func (server *Server) addScan(scan *Scan) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	server.scans = append(server.scans, scan)
+
+	// remove the oldest completed scans beyond the history limit
+	for excess := len(server.scans) - server.historyLimit; excess > 0; excess-- {
+		removed := false
+		for i := range server.scans {
+			server.scans[i].mutex.RLock()
+			done := server.scans[i].done()
+			server.scans[i].mutex.RUnlock()
+			if done {
+				server.scans = append(server.scans[:i], server.scans[i+1:]...)
+				removed = true
+				break
+			}
+		}
+		if !removed {
+			break
+		}
+	}
+}
+
+func (server *Server) getScan(id string) *Scan {
+	server.mutex.RLock()
+	defer server.mutex.RUnlock()
+	for i := range server.scans {
+		if server.scans[i].ID == id {
+			return server.scans[i]
+		}
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logger.L().Error("failed to write response", helpers.Error(err))
+	}
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	writeJSON(w, code, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,200 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type scannerMock struct {
+	start   chan struct{} // the scan waits for the channel, for testing the queued/running scans
+	request *ScanRequest
+	err     error
+}
+
+func (scanner *scannerMock) Scan(request *ScanRequest, progress func(event ProgressEvent)) (*ScanResults, error) {
+	if scanner.start != nil {
+		<-scanner.start
+	}
+	scanner.request = request
+	progress(ProgressEvent{ControlID: "C-0013", ResourceID: "apps/v1/default/Deployment/nginx", Result: "failed"})
+	if scanner.err != nil {
+		return nil, scanner.err
+	}
+	return &ScanResults{RiskScore: 42, Report: []byte(`{"summaryDetails":{}}`)}, nil
+}
+
+func (scanner *scannerMock) ListFrameworks() ([]string, error) {
+	return []string{"nsa", "mitre"}, nil
+}
+
+func (scanner *scannerMock) ListControls() ([]string, error) {
+	return nil, fmt.Errorf("failed to list the controls")
+}
+
+func postScan(t *testing.T, url, body string) *Scan {
+	resp, err := http.Post(url+"/v1/scan", "application/json", strings.NewReader(body))
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+	scan := &Scan{}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(scan))
+	return scan
+}
+
+func getScan(t *testing.T, url, id string) *Scan {
+	resp, err := http.Get(url + "/v1/scans/" + id)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	scan := &Scan{}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(scan))
+	return scan
+}
+
+func waitForScan(t *testing.T, url, id string) *Scan {
+	for i := 0; i < 100; i++ {
+		if scan := getScan(t, url, id); scan.done() {
+			return scan
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("scan '%s' did not complete", id)
+	return nil
+}
+
+func TestScan(t *testing.T) {
+	scanner := &scannerMock{}
+	ts := httptest.NewServer(NewServer(scanner, 10))
+	defer ts.Close()
+
+	scan := postScan(t, ts.URL, `{"frameworks": ["nsa"], "excludeNamespaces": ["kube-system"]}`)
+	assert.NotEmpty(t, scan.ID)
+	assert.Equal(t, []string{"nsa"}, scan.Request.Frameworks)
+
+	scan = waitForScan(t, ts.URL, scan.ID)
+	assert.Equal(t, ScanCompleted, scan.Status)
+	assert.Equal(t, float32(42), *scan.RiskScore)
+	assert.NotNil(t, scan.StartedAt)
+	assert.Equal(t, []string{"kube-system"}, scanner.request.ExcludeNamespaces)
+
+	resp, err := http.Get(ts.URL + "/v1/scans/" + scan.ID + "/results")
+	assert.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, `{"summaryDetails":{}}`, string(body))
+
+	resp, err = http.Get(ts.URL + "/v1/scans")
+	assert.NoError(t, err)
+	scans := []Scan{}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&scans))
+	resp.Body.Close()
+	assert.Len(t, scans, 1)
+}
+
+func TestScanFailed(t *testing.T) {
+	ts := httptest.NewServer(NewServer(&scannerMock{err: fmt.Errorf("failed connecting to Kubernetes cluster")}, 10))
+	defer ts.Close()
+
+	scan := waitForScan(t, ts.URL, postScan(t, ts.URL, "").ID)
+	assert.Equal(t, ScanFailed, scan.Status)
+	assert.Equal(t, "failed connecting to Kubernetes cluster", scan.Error)
+
+	resp, err := http.Get(ts.URL + "/v1/scans/" + scan.ID + "/results")
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+}
+
+func TestBadRequests(t *testing.T) {
+	ts := httptest.NewServer(NewServer(&scannerMock{}, 10))
+	defer ts.Close()
+
+	tests := []struct {
+		method string
+		path   string
+		body   string
+		code   int
+	}{
+		{method: http.MethodPost, path: "/v1/scan", body: `{"frameworks": ["nsa"], "controls": ["C-0013"]}`, code: http.StatusBadRequest},
+		{method: http.MethodPost, path: "/v1/scan", body: `{"frameworks": "nsa"}`, code: http.StatusBadRequest},
+		{method: http.MethodGet, path: "/v1/scan", code: http.StatusMethodNotAllowed},
+		{method: http.MethodGet, path: "/v1/scans/unknown", code: http.StatusNotFound},
+		{method: http.MethodGet, path: "/v1/scans/unknown/logs", code: http.StatusNotFound},
+		{method: http.MethodGet, path: "/v1/controls", code: http.StatusInternalServerError},
+	}
+	for _, test := range tests {
+		req, _ := http.NewRequest(test.method, ts.URL+test.path, strings.NewReader(test.body))
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, test.code, resp.StatusCode, test.path)
+	}
+}
+
+func TestListFrameworks(t *testing.T) {
+	ts := httptest.NewServer(NewServer(&scannerMock{}, 10))
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/v1/frameworks")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	frameworks := []string{}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&frameworks))
+	assert.Equal(t, []string{"nsa", "mitre"}, frameworks)
+}
+
+func TestProgress(t *testing.T) {
+	scanner := &scannerMock{start: make(chan struct{})}
+	ts := httptest.NewServer(NewServer(scanner, 10))
+	defer ts.Close()
+
+	scan := postScan(t, ts.URL, "")
+	resp, err := http.Get(ts.URL + "/v1/scans/" + scan.ID + "/progress")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	close(scanner.start)
+
+	// the stream ends when the scan is done
+	events := []ProgressEvent{}
+	lines := bufio.NewScanner(resp.Body)
+	for lines.Scan() {
+		if data := strings.TrimPrefix(lines.Text(), "data: "); data != lines.Text() {
+			event := ProgressEvent{}
+			assert.NoError(t, json.Unmarshal([]byte(data), &event))
+			events = append(events, event)
+		}
+	}
+	if assert.Len(t, events, 4) {
+		assert.Equal(t, ScanQueued, events[0].Status)
+		assert.Equal(t, ScanRunning, events[1].Status)
+		assert.Equal(t, "C-0013", events[2].ControlID)
+		assert.Equal(t, "failed", events[2].Result)
+		assert.Equal(t, ScanCompleted, events[3].Status)
+	}
+}
+
+func TestHistoryLimit(t *testing.T) {
+	ts := httptest.NewServer(NewServer(&scannerMock{}, 2))
+	defer ts.Close()
+
+	ids := []string{}
+	for i := 0; i < 3; i++ {
+		ids = append(ids, waitForScan(t, ts.URL, postScan(t, ts.URL, "").ID).ID)
+	}
+	resp, err := http.Get(ts.URL + "/v1/scans/" + ids[0])
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	getScan(t, ts.URL, ids[2])
+}
@@ -0,0 +1,505 @@
+package tuihandler
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/armosec/armoapi-go/armotypes"
+	"github.com/armosec/k8s-interface/workloadinterface"
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/kubescape/exceptionshandler"
+	"github.com/armosec/opa-utils/reporthandling/apis"
+	"github.com/armosec/opa-utils/reporthandling/results/v1/reportsummary"
+	"github.com/armosec/opa-utils/reporthandling/results/v1/resourcesresults"
+	reporthandlingv2 "github.com/armosec/opa-utils/reporthandling/v2"
+)
+
+// exceptionReason the reason of the exceptions of the resources marked in the browser
+const exceptionReason = "marked in the interactive results browser"
+
+type key int
+
+const (
+	keyNone key = iota
+	keyUp
+	keyDown
+	keyPageUp
+	keyPageDown
+	keyHome
+	keyEnd
+	keyEnter
+	keyBack
+	keyMark
+	keyQuit
+)
+
+type viewKind int
+
+const (
+	frameworksView viewKind = iota
+	controlsView
+	resourcesView
+	resourceView
+)
+
+// Browser the state of the interactive results browser - frameworks -> controls -> resources -> the resource object
+type Browser struct {
+	frameworks []framework
+	controls   map[string]*control
+	resources  map[string]workloadinterface.IMetadata
+	sources    map[string]cautils.ResourceSource
+	views      []*view // the opened views, the last is displayed
+	marked     map[mark]bool
+	message    string
+	done       bool
+}
+
+type framework struct {
+	name       string
+	score      float32
+	controlIDs []string
+}
+
+type control struct {
+	id          string
+	name        string
+	severity    string
+	status      apis.ScanningStatus
+	score       float32
+	description string
+	remediation string
+	resources   []controlResource // the failed resources first
+}
+
+type controlResource struct {
+	id     string
+	status apis.ScanningStatus
+	paths  []string // the failed and fix paths of the failed rules
+}
+
+// mark a resource marked for an exception of a control
+type mark struct {
+	controlID  string
+	resourceID string
+}
+
+type view struct {
+	kind      viewKind
+	title     string
+	controlID string // the control of the resources and the resource views
+	rows      []row
+	cursor    int
+	offset    int
+}
+
+type row struct {
+	text        string
+	key         string // the framework name, control ID or resource ID opened by the row
+	highlighted bool   // a line of an offending path of the resource object
+}
+
+// NewReportBrowser returns a browser of the results of a results file
+func NewReportBrowser(report *reporthandlingv2.PostureReport, sources map[string]cautils.ResourceSource) *Browser {
+	resources := map[string]workloadinterface.IMetadata{}
+	for i := range report.Resources {
+		if object, ok := report.Resources[i].Object.(map[string]interface{}); ok {
+			resources[report.Resources[i].ResourceID] = workloadinterface.NewWorkloadObj(object)
+		}
+	}
+	return newBrowser(&report.SummaryDetails, report.Results, resources, sources)
+}
+
+// NewSessionBrowser returns a browser of the results of a scan
+func NewSessionBrowser(opaSessionObj *cautils.OPASessionObj) *Browser {
+	results := make([]resourcesresults.Result, 0, len(opaSessionObj.ResourcesResult))
+	for resourceID := range opaSessionObj.ResourcesResult {
+		results = append(results, opaSessionObj.ResourcesResult[resourceID])
+	}
+	return newBrowser(&opaSessionObj.Report.SummaryDetails, results, opaSessionObj.AllResources, opaSessionObj.ResourceSource)
+}
+
+func newBrowser(summaryDetails *reportsummary.SummaryDetails, results []resourcesresults.Result, resources map[string]workloadinterface.IMetadata, sources map[string]cautils.ResourceSource) *Browser {
+	browser := &Browser{
+		frameworks: []framework{},
+		controls:   map[string]*control{},
+		resources:  resources,
+		sources:    sources,
+		marked:     map[mark]bool{},
+	}
+	for controlID := range summaryDetails.Controls {
+		c := summaryDetails.Controls.GetControl(reportsummary.EControlCriteriaID, controlID)
+		browser.controls[controlID] = &control{
+			id:          controlID,
+			name:        c.GetName(),
+			severity:    cautils.ControlSeverityToString(c.GetScoreFactor()),
+			status:      c.GetStatus().Status(),
+			score:       c.GetScore(),
+			description: c.GetDescription(),
+			remediation: c.GetRemediation(),
+		}
+	}
+	// the resources of the controls are listed by the results, the summary files do not list them
+	for i := range results {
+		for _, associatedControl := range results[i].AssociatedControls {
+			c, ok := browser.controls[associatedControl.GetID()]
+			if !ok {
+				continue
+			}
+			c.resources = append(c.resources, controlResource{id: results[i].ResourceID, status: associatedControl.GetStatus(nil).Status(), paths: failedPaths(&associatedControl)})
+		}
+	}
+	for _, c := range browser.controls {
+		resources := c.resources
+		sort.Slice(resources, func(i, j int) bool {
+			if statusOrder(resources[i].status) != statusOrder(resources[j].status) {
+				return statusOrder(resources[i].status) < statusOrder(resources[j].status)
+			}
+			return resources[i].id < resources[j].id
+		})
+	}
+
+	for i := range summaryDetails.Frameworks {
+		f := framework{name: summaryDetails.Frameworks[i].GetName(), score: summaryDetails.Frameworks[i].GetScore()}
+		for controlID := range summaryDetails.Frameworks[i].Controls {
+			f.controlIDs = append(f.controlIDs, controlID)
+		}
+		browser.frameworks = append(browser.frameworks, f)
+	}
+	sort.Slice(browser.frameworks, func(i, j int) bool { return browser.frameworks[i].name < browser.frameworks[j].name })
+
+	if len(browser.frameworks) == 0 {
+		// scanning controls, there are no frameworks
+		controlIDs := make([]string, 0, len(browser.controls))
+		for controlID := range browser.controls {
+			controlIDs = append(controlIDs, controlID)
+		}
+		browser.views = []*view{browser.newControlsView("Controls", controlIDs)}
+	} else {
+		browser.views = []*view{browser.newFrameworksView()}
+	}
+	return browser
+}
+
+// failedPaths returns the failed and fix paths of the failed rules of the control
+func failedPaths(associatedControl *resourcesresults.ResourceAssociatedControl) []string {
+	paths := []string{}
+	for _, rule := range associatedControl.ListRules() {
+		if !rule.GetStatus(nil).IsFailed() {
+			continue
+		}
+		for _, path := range rule.Paths {
+			if path.FailedPath != "" {
+				paths = append(paths, path.FailedPath)
+			}
+			if path.FixPath.Path != "" {
+				paths = append(paths, path.FixPath.Path)
+			}
+		}
+	}
+	return paths
+}
+
+// statusOrder orders the failed results first
+func statusOrder(status apis.ScanningStatus) int {
+	switch status {
+	case apis.StatusFailed:
+		return 0
+	case apis.StatusExcluded:
+		return 1
+	case apis.StatusPassed:
+		return 2
+	default:
+		return 3
+	}
+}
+
+func (browser *Browser) newFrameworksView() *view {
+	v := &view{kind: frameworksView, title: "Frameworks"}
+	for _, f := range browser.frameworks {
+		failed := 0
+		for _, controlID := range f.controlIDs {
+			if c, ok := browser.controls[controlID]; ok && c.status == apis.StatusFailed {
+				failed++
+			}
+		}
+		v.rows = append(v.rows, row{key: f.name, text: fmt.Sprintf("%-30s risk-score %6.2f%%   failed controls: %d/%d", f.name, f.score, failed, len(f.controlIDs))})
+	}
+	return v
+}
+
+func (browser *Browser) newControlsView(title string, controlIDs []string) *view {
+	controls := []*control{}
+	for _, controlID := range controlIDs {
+		if c, ok := browser.controls[controlID]; ok {
+			controls = append(controls, c)
+		}
+	}
+	sort.Slice(controls, func(i, j int) bool {
+		if statusOrder(controls[i].status) != statusOrder(controls[j].status) {
+			return statusOrder(controls[i].status) < statusOrder(controls[j].status)
+		}
+		return controls[i].id < controls[j].id
+	})
+
+	v := &view{kind: controlsView, title: title}
+	for _, c := range controls {
+		failed := 0
+		for _, resource := range c.resources {
+			if resource.status == apis.StatusFailed {
+				failed++
+			}
+		}
+		v.rows = append(v.rows, row{key: c.id, text: fmt.Sprintf("%-8s %-8s %-8s %s (%d failed resources)", c.status, c.severity, c.id, c.name, failed)})
+	}
+	return v
+}
+
+func (browser *Browser) newResourcesView(c *control) *view {
+	v := &view{kind: resourcesView, title: fmt.Sprintf("%s - %s", c.id, c.name), controlID: c.id}
+	for _, resource := range c.resources {
+		v.rows = append(v.rows, row{key: resource.id, text: fmt.Sprintf("%-8s %s%s", resource.status, browser.resourceName(resource.id), browser.sourceLocation(resource.id))})
+	}
+	return v
+}
+
+func (browser *Browser) newResourceView(c *control, resourceID string) *view {
+	v := &view{kind: resourceView, title: browser.resourceName(resourceID), controlID: c.id}
+	var resource controlResource
+	for i := range c.resources {
+		if c.resources[i].id == resourceID {
+			resource = c.resources[i]
+		}
+	}
+	v.rows = append(v.rows, row{text: fmt.Sprintf("Status: %s%s", resource.status, browser.sourceLocation(resourceID))})
+	if c.description != "" {
+		v.rows = append(v.rows, row{text: fmt.Sprintf("Description: %s", c.description)})
+	}
+	if c.remediation != "" {
+		v.rows = append(v.rows, row{text: fmt.Sprintf("Remediation: %s", c.remediation)})
+	}
+	if len(resource.paths) > 0 {
+		v.rows = append(v.rows, row{text: "Failed paths:"})
+		for _, path := range resource.paths {
+			v.rows = append(v.rows, row{text: "  - " + path})
+		}
+	}
+	v.rows = append(v.rows, row{})
+
+	object, ok := browser.resources[resourceID]
+	if !ok {
+		v.rows = append(v.rows, row{text: "The resource object is not part of the results"})
+		return v
+	}
+	lines, highlighted, err := resourceYAML(object.GetObject(), resource.paths)
+	if err != nil {
+		v.rows = append(v.rows, row{text: fmt.Sprintf("Failed to render the resource object: %s", err.Error())})
+		return v
+	}
+	first := -1
+	for i := range lines {
+		if highlighted[i] && first < 0 {
+			first = len(v.rows)
+		}
+		v.rows = append(v.rows, row{text: lines[i], highlighted: highlighted[i]})
+	}
+	if first >= 0 {
+		v.cursor = first // start at the offending path
+	}
+	return v
+}
+
+// resourceName returns <kind>/<namespace>/<name> of the resource, the resource ID when the object is not part of the results
+func (browser *Browser) resourceName(resourceID string) string {
+	resource, ok := browser.resources[resourceID]
+	if !ok {
+		return resourceID
+	}
+	if namespace := resource.GetNamespace(); namespace != "" {
+		return fmt.Sprintf("%s/%s/%s", resource.GetKind(), namespace, resource.GetName())
+	}
+	return fmt.Sprintf("%s/%s", resource.GetKind(), resource.GetName())
+}
+
+// sourceLocation returns " (<file>:<line>)" of the resources loaded from files
+func (browser *Browser) sourceLocation(resourceID string) string {
+	source, ok := browser.sources[resourceID]
+	if !ok || source.Path == "" {
+		return ""
+	}
+	if source.Line > 0 {
+		return fmt.Sprintf(" (%s:%d)", source.Path, source.Line)
+	}
+	return fmt.Sprintf(" (%s)", source.Path)
+}
+
+func (browser *Browser) currentView() *view {
+	return browser.views[len(browser.views)-1]
+}
+
+// handleKey updates the state of the browser by a key pressed by the user
+func (browser *Browser) handleKey(k key) {
+	browser.message = ""
+	v := browser.currentView()
+	switch k {
+	case keyUp:
+		v.moveCursor(-1)
+	case keyDown:
+		v.moveCursor(1)
+	case keyPageUp:
+		v.moveCursor(-10)
+	case keyPageDown:
+		v.moveCursor(10)
+	case keyHome:
+		v.moveCursor(-len(v.rows))
+	case keyEnd:
+		v.moveCursor(len(v.rows))
+	case keyEnter:
+		browser.open()
+	case keyBack:
+		if len(browser.views) > 1 {
+			browser.views = browser.views[:len(browser.views)-1]
+		}
+	case keyMark:
+		browser.toggleMark()
+	case keyQuit:
+		browser.done = true
+	}
+}
+
+func (v *view) moveCursor(n int) {
+	v.cursor += n
+	if v.cursor >= len(v.rows) {
+		v.cursor = len(v.rows) - 1
+	}
+	if v.cursor < 0 {
+		v.cursor = 0
+	}
+}
+
+// open opens the framework/control/resource of the selected row
+func (browser *Browser) open() {
+	v := browser.currentView()
+	if len(v.rows) == 0 || v.rows[v.cursor].key == "" {
+		return
+	}
+	selected := v.rows[v.cursor].key
+	switch v.kind {
+	case frameworksView:
+		for _, f := range browser.frameworks {
+			if f.name == selected {
+				browser.views = append(browser.views, browser.newControlsView(fmt.Sprintf("Framework %s", f.name), f.controlIDs))
+			}
+		}
+	case controlsView:
+		browser.views = append(browser.views, browser.newResourcesView(browser.controls[selected]))
+	case resourcesView:
+		browser.views = append(browser.views, browser.newResourceView(browser.controls[v.controlID], selected))
+	}
+}
+
+// toggleMark marks the failed resource for an exception of the control, or unmarks it
+func (browser *Browser) toggleMark() {
+	v := browser.currentView()
+	m := mark{controlID: v.controlID}
+	switch v.kind {
+	case resourcesView:
+		if len(v.rows) == 0 {
+			return
+		}
+		m.resourceID = v.rows[v.cursor].key
+	case resourceView:
+		m.resourceID = browser.views[len(browser.views)-2].rows[browser.views[len(browser.views)-2].cursor].key
+	default:
+		browser.message = "Open a control to mark its failed resources for exceptions"
+		return
+	}
+	if !browser.isFailed(m) {
+		browser.message = "Only the failed resources are marked for exceptions"
+		return
+	}
+	if browser.marked[m] {
+		delete(browser.marked, m)
+	} else {
+		browser.marked[m] = true
+	}
+}
+
+func (browser *Browser) isFailed(m mark) bool {
+	c, ok := browser.controls[m.controlID]
+	if !ok {
+		return false
+	}
+	for _, resource := range c.resources {
+		if resource.id == m.resourceID {
+			return resource.status == apis.StatusFailed
+		}
+	}
+	return false
+}
+
+// render returns the lines of the screen
+func (browser *Browser) render(width, height int) []string {
+	v := browser.currentView()
+	titles := []string{}
+	for _, opened := range browser.views {
+		titles = append(titles, opened.title)
+	}
+	lines := []string{"\x1b[1m" + truncate(strings.Join(titles, " > "), width) + "\x1b[0m", strings.Repeat("─", width)}
+
+	listHeight := height - 4
+	if listHeight < 1 {
+		listHeight = 1
+	}
+	if v.cursor < v.offset {
+		v.offset = v.cursor
+	}
+	if v.cursor >= v.offset+listHeight {
+		v.offset = v.cursor - listHeight + 1
+	}
+	for i := v.offset; i < len(v.rows) && i < v.offset+listHeight; i++ {
+		text := v.rows[i].text
+		if v.kind == resourcesView {
+			if browser.marked[mark{controlID: v.controlID, resourceID: v.rows[i].key}] {
+				text = "[x] " + text
+			} else {
+				text = "[ ] " + text
+			}
+		}
+		text = truncate(text, width)
+		switch {
+		case i == v.cursor:
+			text = "\x1b[7m" + text + "\x1b[0m"
+		case v.rows[i].highlighted:
+			text = "\x1b[1;31m" + text + "\x1b[0m"
+		}
+		lines = append(lines, text)
+	}
+	for len(lines) < listHeight+2 {
+		lines = append(lines, "")
+	}
+
+	lines = append(lines, strings.Repeat("─", width))
+	footer := fmt.Sprintf("↑/↓ move  enter open  esc back  space mark for exception  q quit   %d marked", len(browser.marked))
+	if browser.message != "" {
+		footer = browser.message
+	}
+	return append(lines, truncate(footer, width))
+}
+
+func truncate(s string, width int) string {
+	if runes := []rune(s); len(runes) > width && width > 0 {
+		return string(runes[:width])
+	}
+	return s
+}
+
+// exceptions returns an exception per marked resource, covering its marked controls
+func (browser *Browser) exceptions(now time.Time) []armotypes.PostureExceptionPolicy {
+	controls := map[string][]string{}
+	for m := range browser.marked {
+		controls[m.resourceID] = append(controls[m.resourceID], m.controlID)
+	}
+	return exceptionshandler.NewResourcesExceptions(controls, browser.resources, exceptionReason, now)
+}
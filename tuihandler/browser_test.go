@@ -0,0 +1,135 @@
+package tuihandler
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/armosec/armoapi-go/armotypes"
+	"github.com/armosec/kubescape/cautils"
+	"github.com/armosec/opa-utils/reporthandling"
+	"github.com/armosec/opa-utils/reporthandling/apis"
+	"github.com/armosec/opa-utils/reporthandling/results/v1/reportsummary"
+	"github.com/armosec/opa-utils/reporthandling/results/v1/resourcesresults"
+	reporthandlingv2 "github.com/armosec/opa-utils/reporthandling/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func deployment(name string) map[string]interface{} {
+	return map[string]interface{}{
+		"apiVersion": "apps/v1", "kind": "Deployment", "metadata": map[string]interface{}{"name": name, "namespace": "prod"},
+		"spec": map[string]interface{}{"template": map[string]interface{}{"spec": map[string]interface{}{"containers": []interface{}{
+			map[string]interface{}{"name": name, "image": "nginx", "securityContext": map[string]interface{}{"privileged": true}},
+		}}}},
+	}
+}
+
+func reportMock() *reporthandlingv2.PostureReport {
+	controls := reportsummary.ControlSummaries{
+		"C-0057": {ControlID: "C-0057", Name: "Privileged container", Status: apis.StatusFailed, ScoreFactor: 8},
+		"C-0013": {ControlID: "C-0013", Name: "Non-root containers", Status: apis.StatusPassed, ScoreFactor: 6},
+	}
+	failed := resourcesresults.ResourceAssociatedControl{ControlID: "C-0057", ResourceAssociatedRules: []resourcesresults.ResourceAssociatedRule{
+		{Name: "rule-privilege-escalation", Status: apis.StatusFailed, Paths: []armotypes.PosturePaths{{FailedPath: "spec.template.spec.containers[0].securityContext.privileged"}}},
+	}}
+	passed := resourcesresults.ResourceAssociatedControl{ControlID: "C-0057", ResourceAssociatedRules: []resourcesresults.ResourceAssociatedRule{{Name: "rule-privilege-escalation", Status: apis.StatusPassed}}}
+	return &reporthandlingv2.PostureReport{
+		SummaryDetails: reportsummary.SummaryDetails{
+			Frameworks: []reportsummary.FrameworkSummary{{Name: "NSA", Score: 50, Controls: controls}},
+			Controls:   controls,
+		},
+		Results: []resourcesresults.Result{
+			{ResourceID: "apps/v1/prod/Deployment/web", AssociatedControls: []resourcesresults.ResourceAssociatedControl{passed}},
+			{ResourceID: "apps/v1/prod/Deployment/api", AssociatedControls: []resourcesresults.ResourceAssociatedControl{failed}},
+		},
+		Resources: []reporthandling.Resource{
+			{ResourceID: "apps/v1/prod/Deployment/web", Object: deployment("web")},
+			{ResourceID: "apps/v1/prod/Deployment/api", Object: deployment("api")},
+		},
+	}
+}
+
+func TestBrowser(t *testing.T) {
+	browser := NewReportBrowser(reportMock(), map[string]cautils.ResourceSource{"apps/v1/prod/Deployment/api": {Path: "api.yaml", Line: 3}})
+	screen := strings.Join(browser.render(120, 20), "\n")
+	assert.Contains(t, screen, "NSA")
+	assert.Contains(t, screen, "failed controls: 1/2")
+
+	// frameworks -> controls, the failed controls first
+	browser.handleKey(keyEnter)
+	assert.Equal(t, controlsView, browser.currentView().kind)
+	assert.Equal(t, "C-0057", browser.currentView().rows[0].key)
+
+	// controls -> resources, the failed resources first
+	browser.handleKey(keyEnter)
+	v := browser.currentView()
+	assert.Equal(t, resourcesView, v.kind)
+	assert.Equal(t, []string{"apps/v1/prod/Deployment/api", "apps/v1/prod/Deployment/web"}, []string{v.rows[0].key, v.rows[1].key})
+	assert.Contains(t, v.rows[0].text, "Deployment/prod/api (api.yaml:3)")
+
+	// only the failed resources are marked
+	browser.handleKey(keyDown)
+	browser.handleKey(keyMark)
+	assert.Empty(t, browser.marked)
+	assert.NotEmpty(t, browser.message)
+	browser.handleKey(keyUp)
+	browser.handleKey(keyMark)
+	assert.Len(t, browser.marked, 1)
+	assert.Contains(t, strings.Join(browser.render(120, 20), "\n"), "[x] failed")
+
+	// resources -> the resource object, starting at the offending path
+	browser.handleKey(keyEnter)
+	v = browser.currentView()
+	assert.Equal(t, resourceView, v.kind)
+	assert.True(t, v.rows[v.cursor].highlighted)
+	assert.Equal(t, "privileged: true", strings.TrimSpace(v.rows[v.cursor].text))
+
+	// unmark from the resource view
+	browser.handleKey(keyMark)
+	assert.Empty(t, browser.marked)
+	browser.handleKey(keyMark)
+
+	browser.handleKey(keyBack)
+	browser.handleKey(keyBack)
+	browser.handleKey(keyBack)
+	browser.handleKey(keyBack)
+	assert.Len(t, browser.views, 1)
+	browser.handleKey(keyQuit)
+	assert.True(t, browser.done)
+
+	exceptions := browser.exceptions(time.Date(2022, 1, 10, 0, 0, 0, 0, time.UTC))
+	if assert.Len(t, exceptions, 1) {
+		assert.Equal(t, "exception-deployment-prod-api", exceptions[0].Name)
+		assert.Equal(t, []armotypes.PosturePolicy{{ControlID: "C-0057"}}, exceptions[0].PosturePolicies)
+	}
+}
+
+func TestResourceYAML(t *testing.T) {
+	lines, highlighted, err := resourceYAML(deployment("web"), []string{
+		"spec.template.spec.containers[0].securityContext",      // an existing object, all of its lines
+		"spec.template.spec.containers[0].resources.limits.cpu", // a missing path, the closest existing parent
+	})
+	assert.NoError(t, err)
+	marked := []string{}
+	for i := range lines {
+		if highlighted[i] {
+			marked = append(marked, strings.TrimSpace(lines[i]))
+		}
+	}
+	assert.Equal(t, []string{"- image: nginx", "securityContext:", "privileged: true"}, marked)
+
+	_, highlighted, err = resourceYAML(deployment("web"), []string{"status.conditions"})
+	assert.NoError(t, err)
+	assert.Empty(t, highlighted)
+}
+
+func TestReadKey(t *testing.T) {
+	reader := bufio.NewReader(strings.NewReader("\x1b[A\x1b[B\x1b[6~\x1bOCqjx\r"))
+	expected := []key{keyUp, keyDown, keyPageDown, keyEnter, keyQuit, keyDown, keyMark, keyEnter}
+	for i := range expected {
+		k, err := readKey(reader)
+		assert.NoError(t, err)
+		assert.Equal(t, expected[i], k, i)
+	}
+}
@@ -0,0 +1,27 @@
+package tuihandler
+
+import (
+	"fmt"
+
+	"github.com/armosec/kubescape/cautils"
+)
+
+// InteractivePrinter browses the results of the scan in the terminal, the exceptions of the marked resources are added to the exceptions file
+type InteractivePrinter struct {
+	exceptionsOutput string
+}
+
+func NewInteractivePrinter(exceptionsOutput string) *InteractivePrinter {
+	return &InteractivePrinter{exceptionsOutput: exceptionsOutput}
+}
+
+func (interactivePrinter *InteractivePrinter) SetWriter(outputFile string) {}
+
+func (interactivePrinter *InteractivePrinter) Score(score float32) {}
+
+func (interactivePrinter *InteractivePrinter) ActionPrint(opaSessionObj *cautils.OPASessionObj) error {
+	if err := Browse(NewSessionBrowser(opaSessionObj), interactivePrinter.exceptionsOutput); err != nil {
+		return fmt.Errorf("failed to browse the results: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package tuihandler
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/armosec/armoapi-go/armotypes"
+	"github.com/armosec/kubescape/cautils/logger"
+	"github.com/armosec/kubescape/cautils/logger/helpers"
+	"github.com/armosec/kubescape/exceptionshandler"
+	"golang.org/x/term"
+)
+
+// DefaultExceptionsFile the file the exceptions of the marked resources are added to
+const DefaultExceptionsFile = "exceptions.json"
+
+// Browse runs the browser in the terminal until the user quits. The exceptions of the marked resources are added to the exceptions file
+func Browse(browser *Browser, exceptionsOutput string) error {
+	if err := browseTerminal(browser, os.Stdin, os.Stdout); err != nil {
+		return err
+	}
+	exceptions := browser.exceptions(time.Now())
+	if len(exceptions) == 0 {
+		return nil
+	}
+
+	existing := []armotypes.PostureExceptionPolicy{}
+	if _, err := os.Stat(exceptionsOutput); err == nil {
+		if existing, err = exceptionshandler.LoadExceptions(exceptionsOutput); err != nil {
+			return err
+		}
+	}
+	added := 0
+	for i := range exceptions {
+		updated, err := exceptionshandler.AddException(existing, &exceptions[i])
+		if err != nil {
+			logger.L().Warning(err.Error(), helpers.String("path", exceptionsOutput))
+			continue
+		}
+		existing = updated
+		added++
+	}
+	if err := exceptionshandler.SaveExceptions(exceptionsOutput, existing); err != nil {
+		return err
+	}
+	logger.L().Success("Exceptions of the marked resources generated", helpers.String("path", exceptionsOutput), helpers.Int("exceptions", added))
+	return nil
+}
+
+func browseTerminal(browser *Browser, in, out *os.File) error {
+	if !term.IsTerminal(int(in.Fd())) || !term.IsTerminal(int(out.Fd())) {
+		return fmt.Errorf("the interactive mode requires a terminal")
+	}
+	state, err := term.MakeRaw(int(in.Fd()))
+	if err != nil {
+		return err
+	}
+	defer term.Restore(int(in.Fd()), state)
+
+	fmt.Fprint(out, "\x1b[?1049h\x1b[?25l") // the alternate screen, without the cursor
+	defer fmt.Fprint(out, "\x1b[?25h\x1b[?1049l")
+
+	reader := bufio.NewReader(in)
+	for !browser.done {
+		width, height, err := term.GetSize(int(out.Fd()))
+		if err != nil {
+			width, height = 80, 24
+		}
+		fmt.Fprint(out, "\x1b[H\x1b[2J"+strings.Join(browser.render(width, height), "\r\n"))
+		k, err := readKey(reader)
+		if err != nil {
+			return err
+		}
+		browser.handleKey(k)
+	}
+	return nil
+}
+
+// readKey reads a key of the terminal in raw mode, the arrows are escape sequences
+func readKey(reader *bufio.Reader) (key, error) {
+	b, err := reader.ReadByte()
+	if err != nil {
+		return keyNone, err
+	}
+	switch b {
+	case 'q', 3: // ctrl+c
+		return keyQuit, nil
+	case 'k':
+		return keyUp, nil
+	case 'j':
+		return keyDown, nil
+	case 'g':
+		return keyHome, nil
+	case 'G':
+		return keyEnd, nil
+	case '\r', '\n', 'l':
+		return keyEnter, nil
+	case 'h', 127, 8: // backspace
+		return keyBack, nil
+	case ' ', 'x':
+		return keyMark, nil
+	case 27:
+		if reader.Buffered() == 0 {
+			return keyBack, nil // escape
+		}
+	default:
+		return keyNone, nil
+	}
+
+	sequence := []byte{}
+	for reader.Buffered() > 0 {
+		b, _ := reader.ReadByte()
+		sequence = append(sequence, b)
+		if len(sequence) > 1 && ((b >= 'A' && b <= 'Z') || b == '~') { // the sequences start by '[' or 'O'
+			break
+		}
+	}
+	switch strings.TrimLeft(string(sequence), "[O") {
+	case "A":
+		return keyUp, nil
+	case "B":
+		return keyDown, nil
+	case "C":
+		return keyEnter, nil
+	case "D":
+		return keyBack, nil
+	case "H", "1~":
+		return keyHome, nil
+	case "F", "4~":
+		return keyEnd, nil
+	case "5~":
+		return keyPageUp, nil
+	case "6~":
+		return keyPageDown, nil
+	}
+	return keyNone, nil
+}
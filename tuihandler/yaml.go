@@ -0,0 +1,75 @@
+package tuihandler
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+// pathTokens the keys and the indexes of a path of the rules, e.g. spec.containers[0].securityContext
+var pathTokens = regexp.MustCompile(`[^.\[\]]+`)
+
+// resourceYAML returns the lines of the YAML of the object and the lines of the paths - the lines of the value of an existing path,
+// the line of the closest existing parent of a missing path (e.g. the container of a missing securityContext)
+func resourceYAML(object map[string]interface{}, paths []string) ([]string, map[int]bool, error) {
+	data, err := sigsyaml.Marshal(object)
+	if err != nil {
+		return nil, nil, err
+	}
+	node, err := yaml.Parse(string(data))
+	if err != nil {
+		return nil, nil, err
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	highlighted := map[int]bool{}
+	for _, path := range paths {
+		first, last := pathLines(node.YNode(), path)
+		for line := first; line > 0 && line <= last; line++ {
+			highlighted[line-1] = true
+		}
+	}
+	return lines, highlighted, nil
+}
+
+// pathLines returns the first and the last lines (1-based) of the path in the node, 0 when no key of the path exists
+func pathLines(node *yaml.Node, path string) (int, int) {
+	first := 0
+	for _, token := range pathTokens.FindAllString(path, -1) {
+		child, line := pathChild(node, token)
+		if child == nil {
+			return first, first
+		}
+		node, first = child, line
+	}
+	return first, lastLine(node)
+}
+
+// pathChild returns the child of the key of a mapping node or of the index of a sequence node, and the line of its key
+func pathChild(node *yaml.Node, token string) (*yaml.Node, int) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == token {
+				return node.Content[i+1], node.Content[i].Line
+			}
+		}
+	case yaml.SequenceNode:
+		if index, err := strconv.Atoi(token); err == nil && index >= 0 && index < len(node.Content) {
+			return node.Content[index], node.Content[index].Line
+		}
+	}
+	return nil, 0
+}
+
+func lastLine(node *yaml.Node) int {
+	line := node.Line
+	for _, child := range node.Content {
+		if l := lastLine(child); l > line {
+			line = l
+		}
+	}
+	return line
+}